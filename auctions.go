@@ -0,0 +1,336 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// AuctionKind classifies the venue-specific `auction_type` of an ImbalanceMsg.
+type AuctionKind uint8
+
+const (
+	AuctionKind_Other   AuctionKind = 0 // An auction of any other type
+	AuctionKind_Opening AuctionKind = 1 // An opening auction, `auction_type` 'O'
+	AuctionKind_Closing AuctionKind = 2 // A closing auction, `auction_type` 'C'
+	AuctionKind_Halt    AuctionKind = 3 // A halt, pause, or IPO reopening auction, `auction_type` 'H'
+)
+
+// AuctionKindOf returns the AuctionKind of an ImbalanceMsg's `auction_type`.
+func AuctionKindOf(auctionType uint8) AuctionKind {
+	switch auctionType {
+	case 'O':
+		return AuctionKind_Opening
+	case 'C':
+		return AuctionKind_Closing
+	case 'H':
+		return AuctionKind_Halt
+	default:
+		return AuctionKind_Other
+	}
+}
+
+// String returns the name of the AuctionKind, such as "opening".
+func (k AuctionKind) String() string {
+	switch k {
+	case AuctionKind_Opening:
+		return "opening"
+	case AuctionKind_Closing:
+		return "closing"
+	case AuctionKind_Halt:
+		return "halt"
+	default:
+		return "other"
+	}
+}
+
+// AuctionSnapshot is the state of an auction disseminated by an ImbalanceMsg.
+type AuctionSnapshot struct {
+	Ts                uint64 // The ImbalanceMsg's `ts_recv`, or `ts_event` if unset
+	RefPrice          int64  // The price at which paired and imbalance quantities are calculated
+	IndicativePrice   int64  // The hypothetical clearing price of auction and continuous orders, `cont_book_clr_price`
+	AuctionPrice      int64  // The hypothetical clearing price of auction orders only, `auct_interest_clr_price`
+	PairedQty         uint32 // The quantity matched at RefPrice
+	TotalImbalanceQty uint32 // The quantity not matched at RefPrice
+	Side              Side   // The side of TotalImbalanceQty
+}
+
+// SignedImbalance returns the TotalImbalanceQty, negative if it is to sell.
+func (s AuctionSnapshot) SignedImbalance() int64 {
+	if s.Side == Side_Ask {
+		return -int64(s.TotalImbalanceQty)
+	}
+	if s.Side == Side_Bid {
+		return int64(s.TotalImbalanceQty)
+	}
+	return 0
+}
+
+// Auction is the imbalance messages of an instrument's auction, and the trade which uncrossed it.
+type Auction struct {
+	InstrumentID uint32
+	AuctionType  uint8 // The venue-specific `auction_type`
+	Kind         AuctionKind
+	Date         uint32 // UTC date of the first snapshot, as YYYYMMDD
+	AuctionTime  uint64 // The latest non-zero `auction_time`, or 0
+	Snapshots    []AuctionSnapshot
+	UncrossTs    uint64 // Timestamp of the uncross trade, or UNDEF_TIMESTAMP if there is none
+	UncrossPrice int64  // Price of the uncross trade, or UNDEF_PRICE if there is none
+	UncrossSize  uint32 // Size of the uncross trade
+}
+
+// IsUncrossed returns true if the auction has an uncross trade.
+func (a *Auction) IsUncrossed() bool {
+	return a.UncrossPrice != UNDEF_PRICE
+}
+
+// AuctionSummary is the final state of an Auction, as a row of a table of auctions.
+type AuctionSummary struct {
+	InstrumentID      uint32
+	AuctionType       uint8
+	Kind              AuctionKind
+	Date              uint32 // UTC date of the first snapshot, as YYYYMMDD
+	FirstTs           uint64 // Timestamp of the first snapshot
+	LastTs            uint64 // Timestamp of the last snapshot
+	NumSnapshots      int
+	RefPrice          int64  // The last snapshot's RefPrice
+	IndicativePrice   int64  // The last snapshot's IndicativePrice
+	AuctionPrice      int64  // The last snapshot's AuctionPrice
+	PairedQty         uint32 // The last snapshot's PairedQty
+	TotalImbalanceQty uint32 // The last snapshot's TotalImbalanceQty
+	Side              Side   // The last snapshot's Side
+	MaxImbalanceQty   uint32 // The largest TotalImbalanceQty of any snapshot
+	MaxPairedQty      uint32 // The largest PairedQty of any snapshot
+	UncrossTs         uint64 // Timestamp of the uncross trade, or UNDEF_TIMESTAMP if there is none
+	UncrossPrice      int64  // Price of the uncross trade, or UNDEF_PRICE if there is none
+	UncrossSize       uint32 // Size of the uncross trade
+}
+
+// Summary returns the final state of the auction.
+func (a *Auction) Summary() AuctionSummary {
+	summary := AuctionSummary{
+		InstrumentID: a.InstrumentID,
+		AuctionType:  a.AuctionType,
+		Kind:         a.Kind,
+		Date:         a.Date,
+		NumSnapshots: len(a.Snapshots),
+		UncrossTs:    a.UncrossTs,
+		UncrossPrice: a.UncrossPrice,
+		UncrossSize:  a.UncrossSize,
+	}
+	if len(a.Snapshots) == 0 {
+		return summary
+	}
+	first, last := a.Snapshots[0], a.Snapshots[len(a.Snapshots)-1]
+	summary.FirstTs = first.Ts
+	summary.LastTs = last.Ts
+	summary.RefPrice = last.RefPrice
+	summary.IndicativePrice = last.IndicativePrice
+	summary.AuctionPrice = last.AuctionPrice
+	summary.PairedQty = last.PairedQty
+	summary.TotalImbalanceQty = last.TotalImbalanceQty
+	summary.Side = last.Side
+	for _, snapshot := range a.Snapshots {
+		summary.MaxImbalanceQty = max(summary.MaxImbalanceQty, snapshot.TotalImbalanceQty)
+		summary.MaxPairedQty = max(summary.MaxPairedQty, snapshot.PairedQty)
+	}
+	return summary
+}
+
+// auctionImbalance is an AuctionSnapshot with the fields of its ImbalanceMsg which identify its auction.
+type auctionImbalance struct {
+	AuctionSnapshot
+	auctionType uint8
+	auctionTime uint64
+}
+
+// auctionTrade is a trade which may uncross an auction.
+type auctionTrade struct {
+	ts    uint64
+	price int64
+	size  uint32
+}
+
+// AuctionAnalyzer gathers ImbalanceMsg records and trades, from the trades, TBBO, or MBP-1
+// schemas, and groups the imbalances into Auctions of an instrument, each uncrossed by the trade
+// after it.  It is a Visitor, so it may be fed records from several files, in any order.
+//
+// An instrument has an opening and a closing auction per UTC date.  Other auctions, such as halt
+// reopenings, end at their uncross, so an imbalance after a trade begins a new one.  An auction's
+// uncross is its instrument's first trade after its last imbalance, or at or after its
+// `auction_time` if that is later.
+type AuctionAnalyzer struct {
+	NullVisitor
+	imbalances map[uint32][]auctionImbalance
+	trades     map[uint32][]auctionTrade
+}
+
+// NewAuctionAnalyzer returns an empty AuctionAnalyzer.
+func NewAuctionAnalyzer() *AuctionAnalyzer {
+	return &AuctionAnalyzer{
+		imbalances: make(map[uint32][]auctionImbalance),
+		trades:     make(map[uint32][]auctionTrade),
+	}
+}
+
+// AddImbalance adds an imbalance message to its instrument's auctions.
+func (a *AuctionAnalyzer) AddImbalance(imbalance *ImbalanceMsg) {
+	ts := imbalance.TsRecv
+	if ts == 0 || ts == UNDEF_TIMESTAMP {
+		ts = imbalance.Header.TsEvent
+	}
+	id := imbalance.Header.InstrumentID
+	a.imbalances[id] = append(a.imbalances[id], auctionImbalance{
+		AuctionSnapshot: AuctionSnapshot{
+			Ts:                ts,
+			RefPrice:          imbalance.RefPrice,
+			IndicativePrice:   imbalance.ContBookClrPrice,
+			AuctionPrice:      imbalance.AuctInterestClrPrice,
+			PairedQty:         imbalance.PairedQty,
+			TotalImbalanceQty: imbalance.TotalImbalanceQty,
+			Side:              Side(imbalance.Side),
+		},
+		auctionType: imbalance.AuctionType,
+		auctionTime: imbalance.AuctionTime,
+	})
+}
+
+// AddTrade adds a trade of an instrument, received at `ts`, which may uncross its auctions.
+func (a *AuctionAnalyzer) AddTrade(instrumentID uint32, ts uint64, price int64, size uint32) {
+	a.trades[instrumentID] = append(a.trades[instrumentID], auctionTrade{ts: ts, price: price, size: size})
+}
+
+// OnImbalance adds the imbalance message; see AddImbalance.
+func (a *AuctionAnalyzer) OnImbalance(record *ImbalanceMsg) error {
+	a.AddImbalance(record)
+	return nil
+}
+
+// OnMbp0 adds the trade; see AddTrade.
+func (a *AuctionAnalyzer) OnMbp0(record *Mbp0Msg) error {
+	a.AddTrade(record.Header.InstrumentID, record.TsRecv, record.Price, record.Size)
+	return nil
+}
+
+// OnMbp1 adds the record if it is a trade, as in the TBBO schema; see AddTrade.
+func (a *AuctionAnalyzer) OnMbp1(record *Mbp1Msg) error {
+	if Action(record.Action) == Action_Trade {
+		a.AddTrade(record.Header.InstrumentID, record.TsRecv, record.Price, record.Size)
+	}
+	return nil
+}
+
+// ReadDbn adds all the imbalance and trade records of a DBN stream, skipping other records.
+func (a *AuctionAnalyzer) ReadDbn(scanner *DbnScanner) error {
+	if _, err := scanner.Metadata(); err != nil {
+		return err
+	}
+	for scanner.Next() {
+		switch RType(scanner.GetLastRecord()[1]) {
+		case RType_Imbalance, RType_Mbp0, RType_Mbp1:
+		default:
+			continue
+		}
+		if err := scanner.Visit(a); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// ReadDbnFile adds all the imbalance and trade records of a DBN file; see ReadDbn.
+// Compression is detected from the file's contents; if useZstd is true, the file is always zstd-decompressed.
+func (a *AuctionAnalyzer) ReadDbnFile(filename string, useZstd bool) error {
+	reader, closer, err := MakeCompressedReader(filename, useZstd)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	if err := a.ReadDbn(NewDbnScanner(reader)); err != nil {
+		return fmt.Errorf("failed to read auctions from '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// auctionKey identifies an instrument's current auction of a type while grouping its imbalances.
+type auctionKey struct {
+	auctionType uint8
+	date        uint32
+}
+
+// Auctions groups the imbalances into Auctions, and returns them sorted by their first snapshot
+// and then instrument ID.
+func (a *AuctionAnalyzer) Auctions() []*Auction {
+	var auctions []*Auction
+	for id, imbalances := range a.imbalances {
+		slices.SortStableFunc(imbalances, func(x, y auctionImbalance) int { return cmp.Compare(x.Ts, y.Ts) })
+		trades := a.trades[id]
+		slices.SortStableFunc(trades, func(x, y auctionTrade) int { return cmp.Compare(x.ts, y.ts) })
+
+		current := make(map[auctionKey]*Auction)
+		for _, imbalance := range imbalances {
+			kind := AuctionKindOf(imbalance.auctionType)
+			key := auctionKey{auctionType: imbalance.auctionType}
+			if kind == AuctionKind_Opening || kind == AuctionKind_Closing {
+				key.date = TimeToYMD(TimestampToTime(imbalance.Ts))
+			}
+			auction := current[key]
+			if auction != nil && key.date == 0 {
+				// Other auctions end at a trade
+				i := firstTradeAfter(trades, auction.Snapshots[len(auction.Snapshots)-1].Ts)
+				if i < len(trades) && trades[i].ts < imbalance.Ts {
+					auction = nil
+				}
+			}
+			if auction == nil {
+				auction = &Auction{
+					InstrumentID: id,
+					AuctionType:  imbalance.auctionType,
+					Kind:         kind,
+					Date:         TimeToYMD(TimestampToTime(imbalance.Ts)),
+				}
+				current[key] = auction
+				auctions = append(auctions, auction)
+			}
+			auction.Snapshots = append(auction.Snapshots, imbalance.AuctionSnapshot)
+			if imbalance.auctionTime != 0 && imbalance.auctionTime != UNDEF_TIMESTAMP {
+				auction.AuctionTime = imbalance.auctionTime
+			}
+		}
+	}
+
+	for _, auction := range auctions {
+		auction.UncrossTs, auction.UncrossPrice = UNDEF_TIMESTAMP, UNDEF_PRICE
+		trades := a.trades[auction.InstrumentID]
+		lastTs := auction.Snapshots[len(auction.Snapshots)-1].Ts
+		i := firstTradeAfter(trades, lastTs)
+		if auction.AuctionTime > lastTs {
+			i = sort.Search(len(trades), func(i int) bool { return trades[i].ts >= auction.AuctionTime })
+		}
+		if i < len(trades) {
+			auction.UncrossTs, auction.UncrossPrice, auction.UncrossSize = trades[i].ts, trades[i].price, trades[i].size
+		}
+	}
+
+	slices.SortFunc(auctions, func(x, y *Auction) int {
+		if c := cmp.Compare(x.Snapshots[0].Ts, y.Snapshots[0].Ts); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.InstrumentID, y.InstrumentID)
+	})
+	return auctions
+}
+
+// firstTradeAfter returns the index of the first of the sorted trades after the timestamp.
+func firstTradeAfter(trades []auctionTrade, ts uint64) int {
+	return sort.Search(len(trades), func(i int) bool { return trades[i].ts > ts })
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// auctionsTestTs returns a timestamp on 2024-01-02, in UTC.
+func auctionsTestTs(hour, minute, second int) uint64 {
+	return uint64(time.Date(2024, 1, 2, hour, minute, second, 0, time.UTC).UnixNano())
+}
+
+// newTestImbalance returns an imbalance of an instrument's auction received at `tsRecv`.
+func newTestImbalance(instrumentID uint32, tsRecv uint64, auctionType uint8, refPrice int64, paired uint32, imbalance uint32, side dbn.Side) *dbn.ImbalanceMsg {
+	return &dbn.ImbalanceMsg{
+		Header: dbn.RHeader{
+			Length:       uint8(dbn.ImbalanceMsg_Size / 4),
+			RType:        dbn.RType_Imbalance,
+			PublisherID:  2,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		TsRecv:            tsRecv,
+		RefPrice:          refPrice,
+		ContBookClrPrice:  refPrice + 1,
+		PairedQty:         paired,
+		TotalImbalanceQty: imbalance,
+		AuctionType:       auctionType,
+		Side:              uint8(side),
+	}
+}
+
+// newTestTrade returns a trade of an instrument received at `tsRecv`.
+func newTestTrade(instrumentID uint32, tsRecv uint64, price int64, size uint32) *dbn.Mbp0Msg {
+	return &dbn.Mbp0Msg{
+		Header: dbn.RHeader{
+			Length:       uint8(dbn.Mbp0Msg_Size / 4),
+			RType:        dbn.RType_Mbp0,
+			PublisherID:  2,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		Price:  price,
+		Size:   size,
+		Action: uint8(dbn.Action_Trade),
+		Side:   uint8(dbn.Side_None),
+		TsRecv: tsRecv,
+	}
+}
+
+var _ = Describe("AuctionAnalyzer", func() {
+	It("should classify auction types", func() {
+		Expect(dbn.AuctionKindOf('O')).To(Equal(dbn.AuctionKind_Opening))
+		Expect(dbn.AuctionKindOf('C').String()).To(Equal("closing"))
+		Expect(dbn.AuctionKindOf('H').String()).To(Equal("halt"))
+		Expect(dbn.AuctionKindOf('X').String()).To(Equal("other"))
+	})
+
+	It("should group imbalances into auctions uncrossed by the next trade", func() {
+		analyzer := dbn.NewAuctionAnalyzer()
+		var visitor dbn.Visitor = analyzer
+		records := []dbn.Record{
+			// Opening auction, with pre-market trades between its imbalances
+			newTestImbalance(1, auctionsTestTs(14, 25, 0), 'O', 100, 500, 300, dbn.Side_Bid),
+			newTestTrade(1, auctionsTestTs(14, 26, 0), 99, 10),
+			newTestImbalance(1, auctionsTestTs(14, 29, 59), 'O', 101, 900, 100, dbn.Side_Ask),
+			newTestTrade(1, auctionsTestTs(14, 30, 0), 102, 900),
+			// Two halt auctions, each uncrossed by a trade
+			newTestImbalance(1, auctionsTestTs(16, 0, 0), 'H', 110, 0, 50, dbn.Side_Ask),
+			newTestImbalance(1, auctionsTestTs(16, 1, 0), 'H', 108, 200, 0, dbn.Side_None),
+			newTestTrade(1, auctionsTestTs(16, 5, 0), 107, 200),
+			newTestImbalance(1, auctionsTestTs(18, 0, 0), 'H', 120, 10, 5, dbn.Side_Bid),
+			newTestTrade(1, auctionsTestTs(18, 5, 0), 121, 15),
+		}
+		// Records may arrive out of order, as from separate files
+		for i := len(records) - 1; i >= 0; i-- {
+			Expect(dbn.VisitRecord(visitor, records[i])).To(Succeed())
+		}
+
+		auctions := analyzer.Auctions()
+		Expect(auctions).To(HaveLen(3))
+
+		opening := auctions[0].Summary()
+		Expect(opening.Kind).To(Equal(dbn.AuctionKind_Opening))
+		Expect(opening.Date).To(Equal(uint32(20240102)))
+		Expect(opening.NumSnapshots).To(Equal(2))
+		Expect(opening.RefPrice).To(Equal(int64(101)))
+		Expect(opening.IndicativePrice).To(Equal(int64(102)))
+		Expect(opening.Side).To(Equal(dbn.Side_Ask))
+		Expect(opening.MaxImbalanceQty).To(Equal(uint32(300)))
+		Expect(opening.MaxPairedQty).To(Equal(uint32(900)))
+		Expect(opening.UncrossTs).To(Equal(auctionsTestTs(14, 30, 0)))
+		Expect(opening.UncrossPrice).To(Equal(int64(102)))
+		Expect(opening.UncrossSize).To(Equal(uint32(900)))
+		Expect(auctions[0].Snapshots[0].SignedImbalance()).To(Equal(int64(300)))
+		Expect(auctions[0].Snapshots[1].SignedImbalance()).To(Equal(int64(-100)))
+
+		Expect(auctions[1].Kind).To(Equal(dbn.AuctionKind_Halt))
+		Expect(auctions[1].Snapshots).To(HaveLen(2))
+		Expect(auctions[1].UncrossPrice).To(Equal(int64(107)))
+		Expect(auctions[2].Snapshots).To(HaveLen(1))
+		Expect(auctions[2].UncrossPrice).To(Equal(int64(121)))
+	})
+
+	It("should uncross at the auction time", func() {
+		analyzer := dbn.NewAuctionAnalyzer()
+		closing := newTestImbalance(1, auctionsTestTs(20, 59, 50), 'C', 100, 1000, 0, dbn.Side_None)
+		closing.AuctionTime = auctionsTestTs(21, 0, 0)
+		Expect(analyzer.OnImbalance(closing)).To(Succeed())
+		Expect(analyzer.OnMbp0(newTestTrade(1, auctionsTestTs(20, 59, 55), 99, 1))).To(Succeed())
+		Expect(analyzer.OnMbp0(newTestTrade(1, auctionsTestTs(21, 0, 0), 100, 1000))).To(Succeed())
+		Expect(analyzer.OnImbalance(newTestImbalance(2, auctionsTestTs(20, 59, 50), 'C', 50, 10, 0, dbn.Side_None))).To(Succeed())
+
+		auctions := analyzer.Auctions()
+		Expect(auctions).To(HaveLen(2))
+		Expect(auctions[0].AuctionTime).To(Equal(auctionsTestTs(21, 0, 0)))
+		Expect(auctions[0].UncrossPrice).To(Equal(int64(100)))
+		Expect(auctions[1].IsUncrossed()).To(BeFalse())
+		Expect(auctions[1].Summary().UncrossTs).To(Equal(dbn.UNDEF_TIMESTAMP))
+	})
+
+	It("should read imbalance and trades files", func() {
+		analyzer := dbn.NewAuctionAnalyzer()
+		Expect(analyzer.ReadDbnFile("./tests/data/test_data.imbalance.v3.dbn.zst", false)).To(Succeed())
+		Expect(analyzer.ReadDbnFile("./tests/data/test_data.trades.v3.dbn.zst", false)).To(Succeed())
+		auctions := analyzer.Auctions()
+		Expect(auctions).To(HaveLen(1))
+		Expect(auctions[0].InstrumentID).To(Equal(uint32(9439)))
+		Expect(auctions[0].Kind).To(Equal(dbn.AuctionKind_Opening))
+		Expect(auctions[0].Snapshots).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,264 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Test Launcher
+func TestDbnBacktest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dbn-go backtest suite")
+}
+
+// newTestScanner returns a DbnScanner of the records, encoded as DBN.
+func newTestScanner(records ...dbn.RecordEncoder) *dbn.DbnScanner {
+	var buf bytes.Buffer
+	Expect(writeTestDbn(&buf, records...)).To(Succeed())
+	return dbn.NewDbnScanner(&buf)
+}
+
+// writeTestDbn writes the metadata and records of a test stream.
+func writeTestDbn(writer io.Writer, records ...dbn.RecordEncoder) error {
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Mbp1,
+		Dataset:       "XNAS.ITCH",
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+	}
+	dbnWriter, err := dbn.NewDbnWriter(writer, metadata)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := dbnWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newTestMbp1 returns an MBP-1 record of instrument 1 with the timestamps.
+func newTestMbp1(tsEvent, tsRecv uint64) *dbn.Mbp1Msg {
+	return &dbn.Mbp1Msg{
+		Header: dbn.RHeader{RType: dbn.RType_Mbp1, InstrumentID: 1, TsEvent: tsEvent},
+		Price:  dbn.UNDEF_PRICE,
+		Action: uint8(dbn.Action_Add),
+		Side:   uint8(dbn.Side_Bid),
+		TsRecv: tsRecv,
+		Level:  dbn.BidAskPair{BidPx: dbn.UNDEF_PRICE, AskPx: dbn.UNDEF_PRICE},
+	}
+}
+
+// newTestOhlcv returns a 1-second bar of instrument 1, which has no `ts_recv`.
+func newTestOhlcv(tsEvent uint64) *dbn.OhlcvMsg {
+	return &dbn.OhlcvMsg{Header: dbn.RHeader{RType: dbn.RType_Ohlcv1S, InstrumentID: 1, TsEvent: tsEvent}}
+}
+
+// eventVisitor logs the records it visits with the time of its Clock.
+type eventVisitor struct {
+	dbn.NullVisitor
+	clock  Clock
+	events []string
+	onMbp1 func(record *dbn.Mbp1Msg) error
+}
+
+func (v *eventVisitor) log(event string) {
+	v.events = append(v.events, fmt.Sprintf("%s@%d", event, v.clock.Now().UnixNano()))
+}
+
+func (v *eventVisitor) OnMbp1(record *dbn.Mbp1Msg) error {
+	v.log("mbp1")
+	if v.onMbp1 != nil {
+		return v.onMbp1(record)
+	}
+	return nil
+}
+
+func (v *eventVisitor) OnOhlcv(record *dbn.OhlcvMsg) error {
+	v.log("ohlcv")
+	return nil
+}
+
+func (v *eventVisitor) OnStreamEnd() error {
+	v.events = append(v.events, "end")
+	return nil
+}
+
+var _ = Describe("Replay", func() {
+	It("should merge streams and fire timers between records", func() {
+		replay := NewReplay(Options{Latency: 10})
+		visitor := &eventVisitor{clock: replay}
+		visitor.onMbp1 = func(record *dbn.Mbp1Msg) error {
+			if record.TsRecv == 100 {
+				replay.After(150, func(now time.Time) error {
+					visitor.log("timer")
+					return nil
+				})
+				replay.After(5, func(now time.Time) error {
+					visitor.log("stopped")
+					return nil
+				}).Stop()
+				// Timers of the same time fire in the order scheduled
+				replay.Schedule(time.Unix(0, 260), func(now time.Time) error {
+					visitor.log("second")
+					return nil
+				})
+				replay.After(1000, func(now time.Time) error {
+					visitor.log("after end")
+					return nil
+				})
+			}
+			return nil
+		}
+		err := replay.Run(context.Background(), visitor,
+			newTestScanner(newTestMbp1(90, 100), newTestMbp1(290, 300)),
+			newTestScanner(newTestOhlcv(200)))
+		Expect(err).To(BeNil())
+		Expect(visitor.events).To(Equal([]string{"mbp1@110", "ohlcv@210", "timer@260", "second@260", "mbp1@310", "end"}))
+	})
+
+	It("should drive the clock by ts_event and never go backwards", func() {
+		replay := NewReplay(Options{Timestamp: TimestampField_TsEvent})
+		visitor := &eventVisitor{clock: replay}
+		err := replay.Run(context.Background(), visitor,
+			newTestScanner(newTestMbp1(90, 100), newTestMbp1(50, 200), newTestMbp1(150, 300)))
+		Expect(err).To(BeNil())
+		Expect(visitor.events).To(Equal([]string{"mbp1@90", "mbp1@90", "mbp1@150", "end"}))
+	})
+
+	It("should pace records in real time", func() {
+		replay := NewReplay(Options{Pacing: Pacing_RealTime, Speed: 2})
+		visitor := &eventVisitor{clock: replay}
+		start := time.Now()
+		err := replay.Run(context.Background(), visitor,
+			newTestScanner(newTestMbp1(0, 0), newTestMbp1(0, uint64(100*time.Millisecond))))
+		Expect(err).To(BeNil())
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		replay = NewReplay(Options{Pacing: Pacing_RealTime})
+		err = replay.Run(ctx, &eventVisitor{clock: replay},
+			newTestScanner(newTestMbp1(0, 0), newTestMbp1(0, uint64(time.Hour))))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	})
+
+	It("should end the run at a timer's error", func() {
+		replay := NewReplay(Options{})
+		visitor := &eventVisitor{clock: replay}
+		timerErr := errors.New("stop")
+		visitor.onMbp1 = func(record *dbn.Mbp1Msg) error {
+			replay.After(1, func(now time.Time) error { return timerErr })
+			return nil
+		}
+		err := replay.Run(context.Background(), visitor, newTestScanner(newTestMbp1(0, 100), newTestMbp1(0, 200)))
+		Expect(err).To(Equal(timerErr))
+		Expect(visitor.events).To(Equal([]string{"mbp1@100"}))
+	})
+
+	It("should replay definitions, then mbo, then trades files", func() {
+		replay := NewReplay(Options{})
+		var lastNow time.Time
+		counter := &countingVisitor{check: func() {
+			Expect(replay.Now().Before(lastNow)).To(BeFalse())
+			lastNow = replay.Now()
+		}}
+		err := replay.RunFiles(context.Background(), counter,
+			"../tests/data/test_data.definition.v3.dbn.zst",
+			"../tests/data/test_data.mbo.v3.dbn.zst",
+			"../tests/data/test_data.trades.v3.dbn.zst")
+		Expect(err).To(BeNil())
+		Expect([]int{counter.definitions, counter.mbo, counter.trades}).NotTo(ContainElement(0))
+	})
+})
+
+// countingVisitor counts the definitions, MBO, and trades it visits.
+type countingVisitor struct {
+	dbn.NullVisitor
+	check       func()
+	definitions int
+	mbo         int
+	trades      int
+}
+
+func (v *countingVisitor) OnInstrumentDefMsg(record *dbn.InstrumentDefMsg) error {
+	v.check()
+	v.definitions++
+	return nil
+}
+
+func (v *countingVisitor) OnMbo(record *dbn.MboMsg) error {
+	v.check()
+	v.mbo++
+	return nil
+}
+
+func (v *countingVisitor) OnMbp0(record *dbn.Mbp0Msg) error {
+	v.check()
+	v.trades++
+	return nil
+}
+
+var _ = Describe("LiveClock", func() {
+	It("should fire timers while waiting for records", func() {
+		clock := NewLiveClock()
+		visitor := &eventVisitor{clock: clock}
+		reader, writer := io.Pipe()
+		next := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer writer.Close()
+			Expect(writeTestDbn(writer, newTestMbp1(0, 100))).To(Succeed())
+			<-next
+			record := newTestMbp1(0, 200)
+			var buf bytes.Buffer
+			Expect(writeTestDbn(&buf, record)).To(Succeed())
+			// Only the record, not a second metadata
+			single := buf.Bytes()[len(buf.Bytes())-int(dbn.Mbp1Msg_Size):]
+			_, err := writer.Write(single)
+			Expect(err).To(BeNil())
+		}()
+
+		var timerFired bool
+		visitor.onMbp1 = func(record *dbn.Mbp1Msg) error {
+			if record.TsRecv == 100 {
+				clock.After(5*time.Millisecond, func(now time.Time) error {
+					timerFired = true
+					close(next)
+					return nil
+				})
+			} else {
+				Expect(timerFired).To(BeTrue())
+			}
+			return nil
+		}
+		Expect(clock.Run(context.Background(), visitor, dbn.NewDbnScanner(reader))).To(Succeed())
+		Expect(timerFired).To(BeTrue())
+		Expect(visitor.events).To(HaveLen(3))
+		Expect(visitor.events[2]).To(Equal("end"))
+	})
+
+	It("should stop when the context is done", func() {
+		clock := NewLiveClock()
+		reader, writer := io.Pipe()
+		defer writer.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := clock.Run(ctx, &eventVisitor{clock: clock}, dbn.NewDbnScanner(reader))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	})
+})
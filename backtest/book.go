@@ -0,0 +1,246 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"slices"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// BookLevel is the resting size at a price of one side of a Book.
+type BookLevel struct {
+	Price int64
+	Size  uint64
+	Count int // Number of orders, or as reported by MBP-10
+}
+
+// BookOrder is an order resting in a Book reconstructed from MBO.
+type BookOrder struct {
+	OrderID uint64
+	Side    dbn.Side
+	Price   int64
+	Size    uint32
+}
+
+// bookLevel is a price level of a bookSide, with its MBO orders in priority order.
+type bookLevel struct {
+	BookLevel
+	orderIDs []uint64
+}
+
+// bookSide is the levels of one side of a Book.
+type bookSide struct {
+	levels map[int64]*bookLevel
+	prices []int64 // ascending
+}
+
+func (s *bookSide) level(price int64) *bookLevel {
+	if level := s.levels[price]; level != nil {
+		return level
+	}
+	if s.levels == nil {
+		s.levels = make(map[int64]*bookLevel)
+	}
+	level := &bookLevel{BookLevel: BookLevel{Price: price}}
+	s.levels[price] = level
+	i, _ := slices.BinarySearch(s.prices, price)
+	s.prices = slices.Insert(s.prices, i, price)
+	return level
+}
+
+func (s *bookSide) removeIfEmpty(level *bookLevel) {
+	if level.Size > 0 || len(level.orderIDs) > 0 {
+		return
+	}
+	delete(s.levels, level.Price)
+	if i, found := slices.BinarySearch(s.prices, level.Price); found {
+		s.prices = slices.Delete(s.prices, i, i+1)
+	}
+}
+
+// Book is an instrument's order book, reconstructed from MBO records, or from the ten levels of
+// MBP-10 records.  Trades and fills do not change it; venues follow them with cancels.
+type Book struct {
+	bids    bookSide
+	asks    bookSide
+	orders  map[uint64]*BookOrder
+	isMbp10 bool
+	depth   int // number of levels of MBP-10 records
+}
+
+// NewBook returns an empty Book.
+func NewBook() *Book {
+	return &Book{orders: make(map[uint64]*BookOrder)}
+}
+
+func (b *Book) side(side dbn.Side) *bookSide {
+	if side == dbn.Side_Bid {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+// Clear removes all levels and orders.
+func (b *Book) Clear() {
+	b.bids, b.asks = bookSide{}, bookSide{}
+	clear(b.orders)
+}
+
+// ApplyMbo applies an MBO record's add, cancel, modify, or clear.  A cancel removes the record's
+// size from the order.  A modify which changes an order's price or increases its size moves it
+// to the back of its level's queue.
+func (b *Book) ApplyMbo(record *dbn.MboMsg) {
+	b.isMbp10 = false
+	switch dbn.Action(record.Action) {
+	case dbn.Action_Clear:
+		b.Clear()
+	case dbn.Action_Add:
+		b.remove(record.OrderID)
+		b.add(record.OrderID, dbn.Side(record.Side), record.Price, record.Size)
+	case dbn.Action_Cancel:
+		order := b.orders[record.OrderID]
+		if order == nil {
+			return
+		}
+		reduction := min(record.Size, order.Size)
+		order.Size -= reduction
+		level := b.side(order.Side).levels[order.Price]
+		level.Size -= uint64(reduction)
+		if order.Size == 0 {
+			b.remove(order.OrderID)
+		}
+	case dbn.Action_Modify:
+		order := b.orders[record.OrderID]
+		if order == nil || order.Side != dbn.Side(record.Side) || order.Price != record.Price || record.Size > order.Size {
+			b.remove(record.OrderID)
+			b.add(record.OrderID, dbn.Side(record.Side), record.Price, record.Size)
+			return
+		}
+		level := b.side(order.Side).levels[order.Price]
+		level.Size -= uint64(order.Size - record.Size)
+		order.Size = record.Size
+		if order.Size == 0 {
+			b.remove(order.OrderID)
+		}
+	}
+}
+
+// add appends an order to the back of its level.
+func (b *Book) add(orderID uint64, side dbn.Side, price int64, size uint32) {
+	if side != dbn.Side_Bid && side != dbn.Side_Ask {
+		return
+	}
+	b.orders[orderID] = &BookOrder{OrderID: orderID, Side: side, Price: price, Size: size}
+	level := b.side(side).level(price)
+	level.Size += uint64(size)
+	level.Count++
+	level.orderIDs = append(level.orderIDs, orderID)
+}
+
+// remove removes an order from its level, if it is in the Book.
+func (b *Book) remove(orderID uint64) {
+	order := b.orders[orderID]
+	if order == nil {
+		return
+	}
+	delete(b.orders, orderID)
+	side := b.side(order.Side)
+	level := side.levels[order.Price]
+	level.Size -= uint64(order.Size)
+	level.Count--
+	if i := slices.Index(level.orderIDs, orderID); i >= 0 {
+		level.orderIDs = slices.Delete(level.orderIDs, i, i+1)
+	}
+	side.removeIfEmpty(level)
+}
+
+// ApplyMbp10 replaces the Book's levels with those of an MBP-10 record.
+func (b *Book) ApplyMbp10(record *dbn.Mbp10Msg) {
+	b.Clear()
+	b.isMbp10, b.depth = true, len(record.Levels)
+	for _, pair := range record.Levels {
+		if pair.BidPx != dbn.UNDEF_PRICE && pair.BidSz > 0 {
+			b.bids.level(pair.BidPx).BookLevel = BookLevel{Price: pair.BidPx, Size: uint64(pair.BidSz), Count: int(pair.BidCt)}
+		}
+		if pair.AskPx != dbn.UNDEF_PRICE && pair.AskSz > 0 {
+			b.asks.level(pair.AskPx).BookLevel = BookLevel{Price: pair.AskPx, Size: uint64(pair.AskSz), Count: int(pair.AskCt)}
+		}
+	}
+}
+
+// Level returns the level of a side at a price, whose Size is 0 if there is none.
+func (b *Book) Level(side dbn.Side, price int64) BookLevel {
+	if level := b.side(side).levels[price]; level != nil {
+		return level.BookLevel
+	}
+	return BookLevel{Price: price}
+}
+
+// Levels returns up to `n` levels of a side, best first; all of them if `n` is negative.
+func (b *Book) Levels(side dbn.Side, n int) []BookLevel {
+	s := b.side(side)
+	if n < 0 || n > len(s.prices) {
+		n = len(s.prices)
+	}
+	levels := make([]BookLevel, 0, n)
+	for i := range n {
+		price := s.prices[i]
+		if side == dbn.Side_Bid {
+			price = s.prices[len(s.prices)-1-i]
+		}
+		levels = append(levels, s.levels[price].BookLevel)
+	}
+	return levels
+}
+
+// BestBid returns the best bid level.  Returns false if there are no bids.
+func (b *Book) BestBid() (BookLevel, bool) {
+	if len(b.bids.prices) == 0 {
+		return BookLevel{Price: dbn.UNDEF_PRICE}, false
+	}
+	return b.bids.levels[b.bids.prices[len(b.bids.prices)-1]].BookLevel, true
+}
+
+// BestAsk returns the best ask level.  Returns false if there are no asks.
+func (b *Book) BestAsk() (BookLevel, bool) {
+	if len(b.asks.prices) == 0 {
+		return BookLevel{Price: dbn.UNDEF_PRICE}, false
+	}
+	return b.asks.levels[b.asks.prices[0]].BookLevel, true
+}
+
+// Order returns an order resting in a Book reconstructed from MBO.  Returns false if there is none.
+func (b *Book) Order(orderID uint64) (BookOrder, bool) {
+	if order := b.orders[orderID]; order != nil {
+		return *order, true
+	}
+	return BookOrder{}, false
+}
+
+// QueueOrderIDs returns the IDs of the orders at a price of a side, in priority order.
+// It is empty for a Book reconstructed from MBP-10.
+func (b *Book) QueueOrderIDs(side dbn.Side, price int64) []uint64 {
+	if level := b.side(side).levels[price]; level != nil {
+		return slices.Clone(level.orderIDs)
+	}
+	return nil
+}
+
+// IsMbp10 returns true if the Book was last reconstructed from MBP-10, so has no orders.
+func (b *Book) IsMbp10() bool {
+	return b.isMbp10
+}
+
+// showsPrice returns true if the Book's levels of a side include any at the price:
+// always from MBO, but from MBP-10 only within its ten levels.
+func (b *Book) showsPrice(side dbn.Side, price int64) bool {
+	s := b.side(side)
+	if !b.isMbp10 || len(s.prices) < b.depth {
+		return true
+	}
+	if side == dbn.Side_Bid {
+		return price >= s.prices[0]
+	}
+	return price <= s.prices[len(s.prices)-1]
+}
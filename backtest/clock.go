@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Neomantra Corp
+
+// Package dbn_backtest drives strategy code, written as a dbn.Visitor, from DBN files with a
+// simulated Clock, or from a live stream with the wall clock.  A strategy which takes its time
+// and timers from the Clock it is given runs unchanged in either, so research and production
+// share handlers:
+//
+//	replay := dbn_backtest.NewReplay(dbn_backtest.Options{Latency: time.Millisecond})
+//	strategy := NewMyStrategy(replay)
+//	err := replay.RunFiles(ctx, strategy, "definition.dbn.zst", "mbo.dbn.zst", "trades.dbn.zst")
+//
+//	clock := dbn_backtest.NewLiveClock()
+//	strategy := NewMyStrategy(clock)
+//	err := clock.Run(ctx, strategy, liveClient.GetDbnScanner())
+package dbn_backtest
+
+import (
+	"container/heap"
+	"time"
+)
+
+// TimerFunc is called when a Timer fires, with the Clock's time.  Returning an error ends the run.
+type TimerFunc func(now time.Time) error
+
+// Clock is the time of a replay or live run, and schedules Timers in it.  Timers fire on the
+// run's goroutine, between records, so a strategy needs no locking.  A Clock is not safe for
+// concurrent use; call it from the Visitor and TimerFuncs it runs.
+type Clock interface {
+	// Now returns the current time of the run.
+	Now() time.Time
+	// Schedule returns a Timer which calls `fn` at `at`, or as soon as possible if it has passed.
+	Schedule(at time.Time, fn TimerFunc) *Timer
+	// After returns a Timer which calls `fn` after the duration `d` from Now.
+	After(d time.Duration, fn TimerFunc) *Timer
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Timer is a function scheduled on a Clock.
+type Timer struct {
+	at    time.Time
+	fn    TimerFunc
+	seq   uint64 // order of scheduling, firing Timers of the same time in that order
+	index int    // in the timerQueue's heap, or -1 if not scheduled
+	queue *timerQueue
+}
+
+// When returns the time the Timer is scheduled for.
+func (t *Timer) When() time.Time {
+	return t.at
+}
+
+// Stop cancels the Timer.  Returns false if it had already fired or been stopped.
+func (t *Timer) Stop() bool {
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&t.queue.timers, t.index)
+	return true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// timerQueue is the Timers of a Clock, ordered by time.
+type timerQueue struct {
+	timers  timerHeap
+	nextSeq uint64
+}
+
+// schedule adds a Timer calling `fn` at `at`.
+func (q *timerQueue) schedule(at time.Time, fn TimerFunc) *Timer {
+	timer := &Timer{at: at, fn: fn, seq: q.nextSeq, queue: q}
+	q.nextSeq++
+	heap.Push(&q.timers, timer)
+	return timer
+}
+
+// peek returns the earliest Timer, or nil if there are none.
+func (q *timerQueue) peek() *Timer {
+	if len(q.timers) == 0 {
+		return nil
+	}
+	return q.timers[0]
+}
+
+// fireDue fires the Timers scheduled at or before `until`, in order, including those they schedule.
+// Before each fires, `advance` is called with its time and returns the Clock's time to fire it with.
+func (q *timerQueue) fireDue(until time.Time, advance func(at time.Time) (time.Time, error)) error {
+	for {
+		timer := q.peek()
+		if timer == nil || timer.at.After(until) {
+			return nil
+		}
+		heap.Pop(&q.timers)
+		now, err := advance(timer.at)
+		if err != nil {
+			return err
+		}
+		if err := timer.fn(now); err != nil {
+			return err
+		}
+	}
+}
+
+// timerHeap implements heap.Interface of Timers by time, then by order of scheduling.
+type timerHeap []*Timer
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x any) {
+	timer := x.(*Timer)
+	timer.index = len(*h)
+	*h = append(*h, timer)
+}
+
+func (h *timerHeap) Pop() any {
+	old := *h
+	timer := old[len(old)-1]
+	old[len(old)-1] = nil
+	timer.index = -1
+	*h = old[:len(old)-1]
+	return timer
+}
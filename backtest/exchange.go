@@ -0,0 +1,661 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// OrderType is the type of a simulated Order.
+type OrderType uint8
+
+const (
+	// OrderType_Limit rests at its price until it is filled or cancelled.
+	OrderType_Limit OrderType = 0
+	// OrderType_Market fills against the book on arrival, and its unfilled size is cancelled.
+	OrderType_Market OrderType = 1
+)
+
+// String returns the name of the OrderType, such as "limit".
+func (t OrderType) String() string {
+	if t == OrderType_Market {
+		return "market"
+	}
+	return "limit"
+}
+
+// OrderStatus is the state of a simulated Order.
+type OrderStatus uint8
+
+const (
+	OrderStatus_Pending   OrderStatus = 0 // Sent, but not yet at the exchange
+	OrderStatus_Open      OrderStatus = 1 // Resting at the exchange
+	OrderStatus_Filled    OrderStatus = 2
+	OrderStatus_Cancelled OrderStatus = 3
+)
+
+// String returns the name of the OrderStatus, such as "open".
+func (s OrderStatus) String() string {
+	switch s {
+	case OrderStatus_Pending:
+		return "pending"
+	case OrderStatus_Open:
+		return "open"
+	case OrderStatus_Filled:
+		return "filled"
+	case OrderStatus_Cancelled:
+		return "cancelled"
+	default:
+		return ""
+	}
+}
+
+// Order is a strategy's order at a simulated Exchange.
+type Order struct {
+	ID           uint64
+	InstrumentID uint32
+	Side         dbn.Side // Side_Bid to buy or Side_Ask to sell
+	Type         OrderType
+	Price        int64 // Limit price, or UNDEF_PRICE for a market order
+	Size         uint32
+	FilledSize   uint32
+	Status       OrderStatus
+	SentTs       uint64 // When the strategy sent it
+	ArrivalTs    uint64 // When it reached the exchange, or UNDEF_TIMESTAMP while pending
+	QueueAhead   uint64 // Estimated size ahead of it at its price while open
+}
+
+// LeavesSize returns the size which is not yet filled.
+func (o *Order) LeavesSize() uint32 {
+	return o.Size - o.FilledSize
+}
+
+// IsDone returns true if the Order is filled or cancelled.
+func (o *Order) IsDone() bool {
+	return o.Status == OrderStatus_Filled || o.Status == OrderStatus_Cancelled
+}
+
+// Liquidity is whether a Fill added or removed liquidity.
+type Liquidity byte
+
+const (
+	Liquidity_Maker Liquidity = 'M' // A resting order was filled
+	Liquidity_Taker Liquidity = 'T' // An order filled against the book on arrival
+)
+
+// String returns the name of the Liquidity, such as "maker".
+func (l Liquidity) String() string {
+	if l == Liquidity_Taker {
+		return "taker"
+	}
+	return "maker"
+}
+
+// Fill is a simulated execution of an Order.
+type Fill struct {
+	Ts           uint64 // Exchange time of the fill
+	OrderID      uint64
+	InstrumentID uint32
+	Side         dbn.Side
+	Price        int64
+	Size         uint32
+	LeavesSize   uint32 // The Order's size not yet filled after this Fill
+	Liquidity    Liquidity
+	Fee          float64 // Per the FeeModel; negative for a rebate
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// LatencyModel is the latency of a strategy's orders and cancels to a simulated Exchange.
+type LatencyModel interface {
+	// Latency returns the delay of a request sent at `now`.
+	Latency(now time.Time) time.Duration
+}
+
+// FixedLatency is a LatencyModel of a constant delay.
+type FixedLatency time.Duration
+
+// Latency returns the FixedLatency.
+func (l FixedLatency) Latency(now time.Time) time.Duration {
+	return time.Duration(l)
+}
+
+// LatencyFunc adapts a function to a LatencyModel.
+type LatencyFunc func(now time.Time) time.Duration
+
+// Latency returns f(now).
+func (f LatencyFunc) Latency(now time.Time) time.Duration {
+	return f(now)
+}
+
+// FeeModel is the fees of a simulated Exchange's Fills.
+type FeeModel interface {
+	// Fee returns the fee of a Fill, whose Fee is not yet set; negative for a rebate.
+	Fee(fill *Fill) float64
+}
+
+// PerUnitFee is a FeeModel of a fee per unit of size, by Liquidity.
+type PerUnitFee struct {
+	Maker float64
+	Taker float64
+}
+
+// Fee returns the fill's size times the fee of its Liquidity.
+func (f PerUnitFee) Fee(fill *Fill) float64 {
+	if fill.Liquidity == Liquidity_Taker {
+		return f.Taker * float64(fill.Size)
+	}
+	return f.Maker * float64(fill.Size)
+}
+
+// FeeFunc adapts a function to a FeeModel.
+type FeeFunc func(fill *Fill) float64
+
+// Fee returns f(fill).
+func (f FeeFunc) Fee(fill *Fill) float64 {
+	return f(fill)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// OrderHandler may be implemented by an Exchange's strategy to receive the updates of its orders'
+// statuses, which are copies as of the update.
+type OrderHandler interface {
+	OnOrderUpdate(order Order) error
+}
+
+// FillHandler may be implemented by an Exchange's strategy to receive its orders' fills.
+type FillHandler interface {
+	OnFill(fill Fill) error
+}
+
+// ExchangeOptions controls a simulated Exchange.
+type ExchangeOptions struct {
+	OrderLatency LatencyModel  // Latency of orders and cancels to the exchange; none if nil
+	FeedLatency  time.Duration // Latency of market data, order updates, and fills to the strategy
+	Fees         FeeModel      // Fees of fills; none if nil
+}
+
+// restingOrder is an Order at the Exchange with its queue position.
+type restingOrder struct {
+	order           *Order
+	aheadIDs        map[uint64]struct{} // MBO orders ahead of it; nil from MBP-10
+	ahead           uint64              // size of the orders ahead of it
+	consumed        uint64              // size of `ahead` traded, but not yet cancelled from the book
+	cancelRequested bool
+}
+
+// queueAhead returns the size estimated to be ahead of the order.
+func (r *restingOrder) queueAhead() uint64 {
+	if r.consumed >= r.ahead {
+		return 0
+	}
+	return r.ahead - r.consumed
+}
+
+// reduceAhead removes size which left the queue ahead of the order.
+func (r *restingOrder) reduceAhead(size uint64) {
+	r.ahead -= min(size, r.ahead)
+	r.consumed -= min(size, r.consumed)
+	r.order.QueueAhead = r.queueAhead()
+}
+
+// Exchange is a paper-trading exchange which simulates a strategy's orders against the books
+// reconstructed from MBO or MBP-10 records, without affecting them.  It is a Visitor, driven by
+// a Replay without Options.Latency, which passes records on to the strategy after FeedLatency.
+//
+// Orders arrive after the OrderLatency.  Those which cross the book fill against its levels as
+// takers; limit orders rest, behind the size at their price.  With MBO, the queue ahead is the
+// orders at the price on arrival, by order ID, as they are cancelled or modified.  With MBP-10,
+// it is the level's size on arrival, reduced by trades and to the level's size.  A resting order
+// fills as a maker when trades at its price exceed its queue ahead, when a trade is through its
+// price, or, with MBO, when an opposite order is added at or through its price.
+type Exchange struct {
+	clock    Clock
+	strategy dbn.Visitor
+	opts     ExchangeOptions
+	books    map[uint32]*Book
+	orders   map[uint64]*Order
+	resting  map[uint32][]*restingOrder // by instrument, in arrival order
+	pending  map[uint64]*restingOrder   // orders sent but not yet arrived
+	fills    []Fill
+	nextID   uint64
+
+	deliveries []delivery // to the strategy, after FeedLatency
+}
+
+// delivery is a call to the strategy at a time.
+type delivery struct {
+	at time.Time
+	fn func() error
+}
+
+// NewExchange returns an Exchange on the Clock of its Replay, with the options.
+// Set its strategy with SetStrategy before running it.
+func NewExchange(clock Clock, opts ExchangeOptions) *Exchange {
+	return &Exchange{
+		clock:    clock,
+		strategy: &dbn.NullVisitor{},
+		opts:     opts,
+		books:    make(map[uint32]*Book),
+		orders:   make(map[uint64]*Order),
+		resting:  make(map[uint32][]*restingOrder),
+		pending:  make(map[uint64]*restingOrder),
+		nextID:   1,
+	}
+}
+
+// SetStrategy sets the Visitor which receives the records, and if it implements them,
+// is an OrderHandler and FillHandler.
+func (e *Exchange) SetStrategy(strategy dbn.Visitor) {
+	e.strategy = strategy
+}
+
+// Book returns the book of an instrument, or nil if it has had no MBO or MBP-10 records.
+func (e *Exchange) Book(instrumentID uint32) *Book {
+	return e.books[instrumentID]
+}
+
+// Order returns the exchange's current state of an order, or nil if there is none.
+// The strategy learns of it after FeedLatency, through OrderHandler.
+func (e *Exchange) Order(orderID uint64) *Order {
+	return e.orders[orderID]
+}
+
+// Fills returns the log of fills, in order.
+func (e *Exchange) Fills() []Fill {
+	return e.fills
+}
+
+// SubmitLimit sends a limit order to buy (Side_Bid) or sell (Side_Ask).
+func (e *Exchange) SubmitLimit(instrumentID uint32, side dbn.Side, price int64, size uint32) (*Order, error) {
+	if price == dbn.UNDEF_PRICE {
+		return nil, fmt.Errorf("limit order must have a price")
+	}
+	return e.submit(instrumentID, side, OrderType_Limit, price, size)
+}
+
+// SubmitMarket sends a market order to buy (Side_Bid) or sell (Side_Ask).
+func (e *Exchange) SubmitMarket(instrumentID uint32, side dbn.Side, size uint32) (*Order, error) {
+	return e.submit(instrumentID, side, OrderType_Market, dbn.UNDEF_PRICE, size)
+}
+
+func (e *Exchange) submit(instrumentID uint32, side dbn.Side, orderType OrderType, price int64, size uint32) (*Order, error) {
+	if side != dbn.Side_Bid && side != dbn.Side_Ask {
+		return nil, fmt.Errorf("order side must be bid or ask, got '%c'", side)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("order size must be positive")
+	}
+	order := &Order{
+		ID:           e.nextID,
+		InstrumentID: instrumentID,
+		Side:         side,
+		Type:         orderType,
+		Price:        price,
+		Size:         size,
+		Status:       OrderStatus_Pending,
+		SentTs:       uint64(e.clock.Now().UnixNano()),
+		ArrivalTs:    dbn.UNDEF_TIMESTAMP,
+	}
+	e.nextID++
+	e.orders[order.ID] = order
+	resting := &restingOrder{order: order}
+	e.pending[order.ID] = resting
+	e.clock.After(e.latency(), func(now time.Time) error {
+		delete(e.pending, order.ID)
+		return e.arrive(resting)
+	})
+	return order, nil
+}
+
+// Cancel sends a cancel of an order.  Returns an error if the order is unknown or done.
+func (e *Exchange) Cancel(orderID uint64) error {
+	order := e.orders[orderID]
+	if order == nil {
+		return fmt.Errorf("unknown order %d", orderID)
+	}
+	if order.IsDone() {
+		return fmt.Errorf("order %d is %s", orderID, order.Status)
+	}
+	e.clock.After(e.latency(), func(now time.Time) error {
+		if resting := e.pending[orderID]; resting != nil {
+			resting.cancelRequested = true
+			return nil
+		}
+		if order.IsDone() {
+			return nil
+		}
+		return e.setStatus(order, OrderStatus_Cancelled)
+	})
+	return nil
+}
+
+func (e *Exchange) latency() time.Duration {
+	if e.opts.OrderLatency == nil {
+		return 0
+	}
+	return e.opts.OrderLatency.Latency(e.clock.Now())
+}
+
+// arrive processes an order reaching the exchange.
+func (e *Exchange) arrive(resting *restingOrder) error {
+	order := resting.order
+	order.ArrivalTs = uint64(e.clock.Now().UnixNano())
+	if resting.cancelRequested {
+		return e.setStatus(order, OrderStatus_Cancelled)
+	}
+
+	// Take the opposite side's levels which cross the order
+	book := e.books[order.InstrumentID]
+	if book != nil {
+		opposite := dbn.Side_Ask
+		if order.Side == dbn.Side_Ask {
+			opposite = dbn.Side_Bid
+		}
+		for _, level := range book.Levels(opposite, -1) {
+			if order.LeavesSize() == 0 || (order.Type == OrderType_Limit && !crosses(order.Side, order.Price, level.Price)) {
+				break
+			}
+			if err := e.fill(order, level.Price, uint32(min(level.Size, uint64(order.LeavesSize()))), Liquidity_Taker); err != nil {
+				return err
+			}
+		}
+	}
+	if order.LeavesSize() == 0 {
+		return nil
+	}
+	if order.Type == OrderType_Market {
+		return e.setStatus(order, OrderStatus_Cancelled)
+	}
+
+	// Rest behind the size at its price
+	if book != nil {
+		resting.ahead = book.Level(order.Side, order.Price).Size
+		if !book.IsMbp10() {
+			resting.aheadIDs = make(map[uint64]struct{})
+			for _, id := range book.QueueOrderIDs(order.Side, order.Price) {
+				resting.aheadIDs[id] = struct{}{}
+			}
+		}
+	}
+	order.QueueAhead = resting.queueAhead()
+	e.resting[order.InstrumentID] = append(e.resting[order.InstrumentID], resting)
+	return e.setStatus(order, OrderStatus_Open)
+}
+
+// crosses returns true if an order of the side at `price` would trade with one at `otherPrice`.
+func crosses(side dbn.Side, price int64, otherPrice int64) bool {
+	if side == dbn.Side_Bid {
+		return otherPrice <= price
+	}
+	return otherPrice >= price
+}
+
+// fill fills size of an order at a price.
+func (e *Exchange) fill(order *Order, price int64, size uint32, liquidity Liquidity) error {
+	if size == 0 {
+		return nil
+	}
+	order.FilledSize += size
+	fill := Fill{
+		Ts:           uint64(e.clock.Now().UnixNano()),
+		OrderID:      order.ID,
+		InstrumentID: order.InstrumentID,
+		Side:         order.Side,
+		Price:        price,
+		Size:         size,
+		LeavesSize:   order.LeavesSize(),
+		Liquidity:    liquidity,
+	}
+	if e.opts.Fees != nil {
+		fill.Fee = e.opts.Fees.Fee(&fill)
+	}
+	e.fills = append(e.fills, fill)
+	if handler, ok := e.strategy.(FillHandler); ok {
+		if err := e.deliver(func() error { return handler.OnFill(fill) }); err != nil {
+			return err
+		}
+	}
+	if order.LeavesSize() == 0 {
+		return e.setStatus(order, OrderStatus_Filled)
+	}
+	return nil
+}
+
+// setStatus sets an order's status and tells the strategy.
+func (e *Exchange) setStatus(order *Order, status OrderStatus) error {
+	order.Status = status
+	if order.IsDone() {
+		order.QueueAhead = 0
+	}
+	if handler, ok := e.strategy.(OrderHandler); ok {
+		update := *order
+		return e.deliver(func() error { return handler.OnOrderUpdate(update) })
+	}
+	return nil
+}
+
+// restingOrders returns the open orders of an instrument, dropping those which are done.
+func (e *Exchange) restingOrders(instrumentID uint32) []*restingOrder {
+	orders := e.resting[instrumentID]
+	open := orders[:0]
+	for _, resting := range orders {
+		if !resting.order.IsDone() {
+			open = append(open, resting)
+		}
+	}
+	clear(orders[len(open):])
+	e.resting[instrumentID] = open
+	return open
+}
+
+// matchTrade fills the instrument's resting orders against a trade: those at its price after
+// their queue ahead, and those it is through.
+func (e *Exchange) matchTrade(instrumentID uint32, price int64, size uint32, aggressor dbn.Side) error {
+	simFilled := make(map[int64]uint64) // size of this trade filled by orders at each price
+	for _, resting := range e.restingOrders(instrumentID) {
+		order := resting.order
+		if order.IsDone() {
+			continue
+		}
+		if order.Price != price {
+			if crosses(order.Side, order.Price, price) {
+				if err := e.fill(order, order.Price, order.LeavesSize(), Liquidity_Maker); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if aggressor == order.Side {
+			continue
+		}
+		taken := min(uint64(size), resting.queueAhead())
+		if resting.aheadIDs != nil {
+			resting.consumed += taken
+		} else {
+			resting.ahead -= taken
+		}
+		order.QueueAhead = resting.queueAhead()
+		available := uint64(size) - taken
+		available -= min(available, simFilled[price])
+		fillSize := uint32(min(available, uint64(order.LeavesSize())))
+		simFilled[price] += uint64(fillSize)
+		if err := e.fill(order, order.Price, fillSize, Liquidity_Maker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnMbo updates the instrument's book and fills orders, then passes the record to the strategy.
+func (e *Exchange) OnMbo(record *dbn.MboMsg) error {
+	instrumentID := record.Header.InstrumentID
+	book := e.books[instrumentID]
+	if book == nil {
+		book = NewBook()
+		e.books[instrumentID] = book
+	}
+	var err error
+	switch dbn.Action(record.Action) {
+	case dbn.Action_Trade:
+		err = e.matchTrade(instrumentID, record.Price, record.Size, dbn.Side(record.Side))
+	case dbn.Action_Clear:
+		book.ApplyMbo(record)
+		for _, resting := range e.restingOrders(instrumentID) {
+			resting.aheadIDs, resting.ahead, resting.consumed, resting.order.QueueAhead = nil, 0, 0, 0
+		}
+	case dbn.Action_Add, dbn.Action_Cancel, dbn.Action_Modify:
+		before, existed := book.Order(record.OrderID)
+		book.ApplyMbo(record)
+		after, exists := book.Order(record.OrderID)
+		// Size added to the book, which trades with resting orders it is at or through
+		var addedSize uint32
+		if exists && (!existed || before.Side != after.Side || before.Price != after.Price) {
+			addedSize = after.Size
+		} else if exists && after.Size > before.Size {
+			addedSize = after.Size - before.Size
+		}
+		for _, resting := range e.restingOrders(instrumentID) {
+			if existed {
+				if _, ahead := resting.aheadIDs[record.OrderID]; ahead {
+					if exists && after.Price == before.Price && after.Size <= before.Size {
+						resting.reduceAhead(uint64(before.Size - after.Size))
+					} else {
+						resting.reduceAhead(uint64(before.Size))
+						delete(resting.aheadIDs, record.OrderID)
+					}
+				}
+			}
+			order := resting.order
+			if addedSize > 0 && after.Side != order.Side && crosses(order.Side, order.Price, after.Price) {
+				fillSize := min(addedSize, order.LeavesSize())
+				addedSize -= fillSize
+				if err = e.fill(order, order.Price, fillSize, Liquidity_Maker); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return e.deliver(func() error { return e.strategy.OnMbo(record) })
+}
+
+// OnMbp10 fills orders against a trade, or updates the instrument's book, then passes the record to the strategy.
+func (e *Exchange) OnMbp10(record *dbn.Mbp10Msg) error {
+	instrumentID := record.Header.InstrumentID
+	book := e.books[instrumentID]
+	if book == nil {
+		book = NewBook()
+		e.books[instrumentID] = book
+	}
+	if dbn.Action(record.Action) == dbn.Action_Trade {
+		if err := e.matchTrade(instrumentID, record.Price, record.Size, dbn.Side(record.Side)); err != nil {
+			return err
+		}
+	}
+	book.ApplyMbp10(record)
+	for _, resting := range e.restingOrders(instrumentID) {
+		order := resting.order
+		if resting.aheadIDs == nil && book.showsPrice(order.Side, order.Price) {
+			resting.ahead = min(resting.ahead, book.Level(order.Side, order.Price).Size)
+			order.QueueAhead = resting.queueAhead()
+		}
+	}
+	return e.deliver(func() error { return e.strategy.OnMbp10(record) })
+}
+
+// deliver calls `fn` after FeedLatency, in order.
+func (e *Exchange) deliver(fn func() error) error {
+	if e.opts.FeedLatency == 0 {
+		return fn()
+	}
+	at := e.clock.Now().Add(e.opts.FeedLatency)
+	e.deliveries = append(e.deliveries, delivery{at: at, fn: fn})
+	if len(e.deliveries) == 1 {
+		e.clock.Schedule(at, e.deliverDue)
+	}
+	return nil
+}
+
+// deliverDue makes the deliveries which are due, and schedules the next.
+func (e *Exchange) deliverDue(now time.Time) error {
+	for len(e.deliveries) > 0 && !e.deliveries[0].at.After(now) {
+		d := e.deliveries[0]
+		e.deliveries[0] = delivery{}
+		e.deliveries = e.deliveries[1:]
+		if err := d.fn(); err != nil {
+			return err
+		}
+	}
+	if len(e.deliveries) > 0 {
+		e.clock.Schedule(e.deliveries[0].at, e.deliverDue)
+	}
+	return nil
+}
+
+// OnStreamEnd makes the remaining deliveries, then passes the end to the strategy.
+// As a Replay drops the timers left at the end, those deliveries are made at the end's time.
+func (e *Exchange) OnStreamEnd() error {
+	for len(e.deliveries) > 0 {
+		d := e.deliveries[0]
+		e.deliveries = e.deliveries[1:]
+		if err := d.fn(); err != nil {
+			return err
+		}
+	}
+	return e.strategy.OnStreamEnd()
+}
+
+func (e *Exchange) OnMbp0(record *dbn.Mbp0Msg) error {
+	return e.deliver(func() error { return e.strategy.OnMbp0(record) })
+}
+
+func (e *Exchange) OnMbp1(record *dbn.Mbp1Msg) error {
+	return e.deliver(func() error { return e.strategy.OnMbp1(record) })
+}
+
+func (e *Exchange) OnOhlcv(record *dbn.OhlcvMsg) error {
+	return e.deliver(func() error { return e.strategy.OnOhlcv(record) })
+}
+
+func (e *Exchange) OnCmbp1(record *dbn.Cmbp1Msg) error {
+	return e.deliver(func() error { return e.strategy.OnCmbp1(record) })
+}
+
+func (e *Exchange) OnBbo(record *dbn.BboMsg) error {
+	return e.deliver(func() error { return e.strategy.OnBbo(record) })
+}
+
+func (e *Exchange) OnImbalance(record *dbn.ImbalanceMsg) error {
+	return e.deliver(func() error { return e.strategy.OnImbalance(record) })
+}
+
+func (e *Exchange) OnStatMsg(record *dbn.StatMsg) error {
+	return e.deliver(func() error { return e.strategy.OnStatMsg(record) })
+}
+
+func (e *Exchange) OnStatusMsg(record *dbn.StatusMsg) error {
+	return e.deliver(func() error { return e.strategy.OnStatusMsg(record) })
+}
+
+func (e *Exchange) OnInstrumentDefMsg(record *dbn.InstrumentDefMsg) error {
+	return e.deliver(func() error { return e.strategy.OnInstrumentDefMsg(record) })
+}
+
+func (e *Exchange) OnErrorMsg(record *dbn.ErrorMsg) error {
+	return e.deliver(func() error { return e.strategy.OnErrorMsg(record) })
+}
+
+func (e *Exchange) OnSystemMsg(record *dbn.SystemMsg) error {
+	return e.deliver(func() error { return e.strategy.OnSystemMsg(record) })
+}
+
+func (e *Exchange) OnSymbolMappingMsg(record *dbn.SymbolMappingMsg) error {
+	return e.deliver(func() error { return e.strategy.OnSymbolMappingMsg(record) })
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestMbo returns an MBO record of instrument 1 at `ts`.
+func newTestMbo(ts uint64, action dbn.Action, side dbn.Side, orderID uint64, price int64, size uint32) *dbn.MboMsg {
+	return &dbn.MboMsg{
+		Header:  dbn.RHeader{RType: dbn.RType_Mbo, InstrumentID: 1, TsEvent: ts},
+		OrderID: orderID,
+		Price:   price,
+		Size:    size,
+		Action:  uint8(action),
+		Side:    uint8(side),
+		TsRecv:  ts,
+	}
+}
+
+// newTestMbp10 returns an MBP-10 record of instrument 1 at `ts` with a bid level and an ask level.
+func newTestMbp10(ts uint64, action dbn.Action, price int64, size uint32, bidPx int64, bidSz uint32, askPx int64, askSz uint32) *dbn.Mbp10Msg {
+	record := &dbn.Mbp10Msg{
+		Header: dbn.RHeader{RType: dbn.RType_Mbp10, InstrumentID: 1, TsEvent: ts},
+		Price:  price,
+		Size:   size,
+		Action: uint8(action),
+		Side:   uint8(dbn.Side_Ask),
+		TsRecv: ts,
+	}
+	for i := range record.Levels {
+		record.Levels[i] = dbn.BidAskPair{BidPx: dbn.UNDEF_PRICE, AskPx: dbn.UNDEF_PRICE}
+	}
+	record.Levels[0] = dbn.BidAskPair{BidPx: bidPx, AskPx: askPx, BidSz: bidSz, AskSz: askSz, BidCt: 1, AskCt: 1}
+	return record
+}
+
+// tradingStrategy calls its hooks on MBO and MBP-10 records, and logs its orders' updates and fills.
+type tradingStrategy struct {
+	dbn.NullVisitor
+	clock    Clock
+	exchange *Exchange
+	onMbo    func(record *dbn.MboMsg) error
+	onMbp10  func(record *dbn.Mbp10Msg) error
+	updates  []Order
+	fills    []Fill
+	fillAt   []time.Time
+}
+
+func (s *tradingStrategy) OnMbo(record *dbn.MboMsg) error {
+	if s.onMbo != nil {
+		return s.onMbo(record)
+	}
+	return nil
+}
+
+func (s *tradingStrategy) OnMbp10(record *dbn.Mbp10Msg) error {
+	if s.onMbp10 != nil {
+		return s.onMbp10(record)
+	}
+	return nil
+}
+
+func (s *tradingStrategy) OnOrderUpdate(order Order) error {
+	s.updates = append(s.updates, order)
+	return nil
+}
+
+func (s *tradingStrategy) OnFill(fill Fill) error {
+	s.fills = append(s.fills, fill)
+	s.fillAt = append(s.fillAt, s.clock.Now())
+	return nil
+}
+
+// newTestExchange returns a Replay and an Exchange on it, whose strategy is `strategy`.
+func newTestExchange(opts ExchangeOptions, strategy *tradingStrategy) (*Replay, *Exchange) {
+	replay := NewReplay(Options{})
+	exchange := NewExchange(replay, opts)
+	strategy.clock, strategy.exchange = replay, exchange
+	exchange.SetStrategy(strategy)
+	return replay, exchange
+}
+
+var _ = Describe("Book", func() {
+	It("should reconstruct MBO with queue priority", func() {
+		book := NewBook()
+		book.ApplyMbo(newTestMbo(1, dbn.Action_Add, dbn.Side_Bid, 1, 100, 10))
+		book.ApplyMbo(newTestMbo(2, dbn.Action_Add, dbn.Side_Bid, 2, 100, 5))
+		book.ApplyMbo(newTestMbo(3, dbn.Action_Add, dbn.Side_Bid, 3, 99, 7))
+		book.ApplyMbo(newTestMbo(4, dbn.Action_Add, dbn.Side_Ask, 4, 102, 4))
+		Expect(book.Levels(dbn.Side_Bid, -1)).To(Equal([]BookLevel{{Price: 100, Size: 15, Count: 2}, {Price: 99, Size: 7, Count: 1}}))
+		Expect(book.QueueOrderIDs(dbn.Side_Bid, 100)).To(Equal([]uint64{1, 2}))
+
+		// A size increase loses priority, and a decrease keeps it
+		book.ApplyMbo(newTestMbo(5, dbn.Action_Modify, dbn.Side_Bid, 1, 100, 12))
+		Expect(book.QueueOrderIDs(dbn.Side_Bid, 100)).To(Equal([]uint64{2, 1}))
+		book.ApplyMbo(newTestMbo(6, dbn.Action_Modify, dbn.Side_Bid, 2, 100, 1))
+		Expect(book.QueueOrderIDs(dbn.Side_Bid, 100)).To(Equal([]uint64{2, 1}))
+		book.ApplyMbo(newTestMbo(7, dbn.Action_Cancel, dbn.Side_Bid, 1, 100, 12))
+		bid, ok := book.BestBid()
+		Expect(ok).To(BeTrue())
+		Expect(bid).To(Equal(BookLevel{Price: 100, Size: 1, Count: 1}))
+		_, ok = book.Order(1)
+		Expect(ok).To(BeFalse())
+
+		book.ApplyMbo(newTestMbo(8, dbn.Action_Modify, dbn.Side_Bid, 2, 98, 1))
+		Expect(book.Levels(dbn.Side_Bid, 1)).To(Equal([]BookLevel{{Price: 99, Size: 7, Count: 1}}))
+		ask, _ := book.BestAsk()
+		Expect(ask.Price).To(Equal(int64(102)))
+
+		book.ApplyMbo(newTestMbo(9, dbn.Action_Clear, dbn.Side_None, 0, dbn.UNDEF_PRICE, 0))
+		_, ok = book.BestBid()
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Exchange", func() {
+	It("should fill a resting order behind its queue and when traded through", func() {
+		var orderID uint64
+		var queueAhead []uint64
+		strategy := &tradingStrategy{}
+		strategy.onMbo = func(record *dbn.MboMsg) error {
+			switch record.TsRecv {
+			case 120:
+				order, err := strategy.exchange.SubmitLimit(1, dbn.Side_Bid, 100, 3)
+				orderID = order.ID
+				return err
+			case 130, 140, 142:
+				queueAhead = append(queueAhead, strategy.exchange.Order(orderID).QueueAhead)
+			}
+			return nil
+		}
+		replay, exchange := newTestExchange(ExchangeOptions{Fees: PerUnitFee{Maker: -0.002, Taker: 0.003}}, strategy)
+		err := replay.Run(context.Background(), exchange, newTestScanner(
+			newTestMbo(100, dbn.Action_Add, dbn.Side_Bid, 1, 100, 10),
+			newTestMbo(110, dbn.Action_Add, dbn.Side_Bid, 2, 100, 5),
+			newTestMbo(120, dbn.Action_Add, dbn.Side_Ask, 3, 102, 4),
+			newTestMbo(130, dbn.Action_Cancel, dbn.Side_Bid, 2, 100, 5),
+			newTestMbo(140, dbn.Action_Trade, dbn.Side_Ask, 0, 100, 12),
+			newTestMbo(141, dbn.Action_Fill, dbn.Side_Bid, 1, 100, 10),
+			newTestMbo(142, dbn.Action_Cancel, dbn.Side_Bid, 1, 100, 10),
+			newTestMbo(150, dbn.Action_Trade, dbn.Side_Ask, 0, 99, 1),
+		))
+		Expect(err).To(BeNil())
+
+		Expect(queueAhead).To(Equal([]uint64{10, 0, 0}))
+		Expect(strategy.fills).To(Equal([]Fill{
+			{Ts: 140, OrderID: orderID, InstrumentID: 1, Side: dbn.Side_Bid, Price: 100, Size: 2, LeavesSize: 1, Liquidity: Liquidity_Maker, Fee: -0.004},
+			{Ts: 150, OrderID: orderID, InstrumentID: 1, Side: dbn.Side_Bid, Price: 100, Size: 1, LeavesSize: 0, Liquidity: Liquidity_Maker, Fee: -0.002},
+		}))
+		Expect(exchange.Fills()).To(Equal(strategy.fills))
+		Expect(strategy.updates).To(HaveLen(2))
+		Expect(strategy.updates[0].Status).To(Equal(OrderStatus_Open))
+		Expect(strategy.updates[0].QueueAhead).To(Equal(uint64(15)))
+		Expect(strategy.updates[1].Status).To(Equal(OrderStatus_Filled))
+
+		dst := filepath.Join(GinkgoT().TempDir(), "fills.parquet")
+		file, err := os.Create(dst)
+		Expect(err).To(BeNil())
+		Expect(WriteFillsParquet(exchange.Fills(), file)).To(Succeed())
+		reader, err := pqfile.OpenParquetFile(dst, false)
+		Expect(err).To(BeNil())
+		defer reader.Close()
+		Expect(reader.NumRows()).To(Equal(int64(2)))
+		Expect(reader.MetaData().Schema.NumColumns()).To(Equal(9))
+	})
+
+	It("should take crossed levels after the order latency and cancel the rest", func() {
+		var orderID uint64
+		strategy := &tradingStrategy{}
+		strategy.onMbo = func(record *dbn.MboMsg) error {
+			switch record.TsRecv {
+			case 102:
+				order, err := strategy.exchange.SubmitLimit(1, dbn.Side_Bid, 102, 10)
+				orderID = order.ID
+				Expect(order.Status).To(Equal(OrderStatus_Pending))
+				return err
+			case 200:
+				return strategy.exchange.Cancel(orderID)
+			}
+			return nil
+		}
+		replay, exchange := newTestExchange(ExchangeOptions{OrderLatency: FixedLatency(50), Fees: PerUnitFee{Taker: 0.01}}, strategy)
+		err := replay.Run(context.Background(), exchange, newTestScanner(
+			newTestMbo(100, dbn.Action_Add, dbn.Side_Ask, 1, 101, 2),
+			newTestMbo(101, dbn.Action_Add, dbn.Side_Ask, 2, 102, 5),
+			newTestMbo(102, dbn.Action_Add, dbn.Side_Bid, 3, 99, 1),
+			newTestMbo(120, dbn.Action_Cancel, dbn.Side_Ask, 1, 101, 2),
+			newTestMbo(200, dbn.Action_Add, dbn.Side_Bid, 4, 98, 1),
+			newTestMbo(300, dbn.Action_Add, dbn.Side_Bid, 5, 98, 1),
+		))
+		Expect(err).To(BeNil())
+
+		Expect(strategy.fills).To(Equal([]Fill{
+			{Ts: 152, OrderID: orderID, InstrumentID: 1, Side: dbn.Side_Bid, Price: 102, Size: 5, LeavesSize: 5, Liquidity: Liquidity_Taker, Fee: 0.05},
+		}))
+		order := exchange.Order(orderID)
+		Expect(order.Status).To(Equal(OrderStatus_Cancelled))
+		Expect([]uint64{order.SentTs, order.ArrivalTs}).To(Equal([]uint64{102, 152}))
+		Expect(exchange.Cancel(orderID)).NotTo(Succeed())
+
+		// Market orders without liquidity are cancelled
+		market, err := exchange.SubmitMarket(2, dbn.Side_Ask, 1)
+		Expect(err).To(BeNil())
+		Expect(market.Type.String()).To(Equal("market"))
+		_, err = exchange.SubmitLimit(1, dbn.Side_None, 100, 1)
+		Expect(err).NotTo(BeNil())
+		_, err = exchange.SubmitMarket(1, dbn.Side_Bid, 0)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should fill a resting order when an opposite order is added through it", func() {
+		strategy := &tradingStrategy{}
+		strategy.onMbo = func(record *dbn.MboMsg) error {
+			if record.TsRecv == 100 {
+				_, err := strategy.exchange.SubmitLimit(1, dbn.Side_Bid, 100, 5)
+				return err
+			}
+			return nil
+		}
+		replay, exchange := newTestExchange(ExchangeOptions{}, strategy)
+		err := replay.Run(context.Background(), exchange, newTestScanner(
+			newTestMbo(100, dbn.Action_Add, dbn.Side_Ask, 1, 101, 2),
+			newTestMbo(200, dbn.Action_Add, dbn.Side_Ask, 2, 100, 3),
+		))
+		Expect(err).To(BeNil())
+		Expect(strategy.fills).To(HaveLen(1))
+		Expect([]any{strategy.fills[0].Price, strategy.fills[0].Size, strategy.fills[0].Liquidity}).To(Equal([]any{int64(100), uint32(3), Liquidity_Maker}))
+	})
+
+	It("should estimate the queue from MBP-10 levels", func() {
+		var orderID uint64
+		var queueAhead []uint64
+		strategy := &tradingStrategy{}
+		strategy.onMbp10 = func(record *dbn.Mbp10Msg) error {
+			if record.TsRecv == 100 {
+				order, err := strategy.exchange.SubmitLimit(1, dbn.Side_Bid, 100, 5)
+				orderID = order.ID
+				return err
+			}
+			queueAhead = append(queueAhead, strategy.exchange.Order(orderID).QueueAhead)
+			return nil
+		}
+		replay, exchange := newTestExchange(ExchangeOptions{}, strategy)
+		err := replay.Run(context.Background(), exchange, newTestScanner(
+			newTestMbp10(100, dbn.Action_Add, 100, 8, 100, 8, 101, 3),
+			newTestMbp10(200, dbn.Action_Cancel, 100, 2, 100, 6, 101, 3),
+			newTestMbp10(300, dbn.Action_Trade, 100, 7, 100, 6, 101, 3),
+		))
+		Expect(err).To(BeNil())
+		Expect(queueAhead).To(Equal([]uint64{6, 0}))
+		Expect(strategy.fills).To(HaveLen(1))
+		Expect(strategy.fills[0].Size).To(Equal(uint32(1)))
+		Expect(exchange.Book(1).IsMbp10()).To(BeTrue())
+	})
+
+	It("should delay market data and fills by the feed latency", func() {
+		var seenAt []int64
+		strategy := &tradingStrategy{}
+		strategy.onMbo = func(record *dbn.MboMsg) error {
+			seenAt = append(seenAt, strategy.clock.Now().UnixNano())
+			if record.TsRecv == 100 {
+				_, err := strategy.exchange.SubmitMarket(1, dbn.Side_Bid, 1)
+				return err
+			}
+			return nil
+		}
+		replay, exchange := newTestExchange(ExchangeOptions{FeedLatency: 10}, strategy)
+		err := replay.Run(context.Background(), exchange, newTestScanner(
+			newTestMbo(100, dbn.Action_Add, dbn.Side_Ask, 1, 101, 2),
+			newTestMbo(150, dbn.Action_Add, dbn.Side_Ask, 2, 102, 2),
+		))
+		Expect(err).To(BeNil())
+		// The last record is delivered at the end of the stream
+		Expect(seenAt).To(Equal([]int64{110, 150}))
+		// Sent at 110, filled at 110, and seen at 120
+		Expect(strategy.fills).To(HaveLen(1))
+		Expect(strategy.fills[0].Ts).To(Equal(uint64(110)))
+		Expect(strategy.fillAt[0].UnixNano()).To(Equal(int64(120)))
+		Expect(exchange.Fills()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/NimbleMarkets/dbn-go"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	pqschema "github.com/apache/arrow-go/v18/parquet/schema"
+)
+
+// ParquetGroupNode_Fills returns the Parquet Schema's Group Node for the fill log of WriteFillsParquet.
+//
+//	required int64 field_id=-1 ts (Timestamp(isAdjustedToUTC=true, timeUnit=nanoseconds, is_from_converted_type=false, force_set_converted_type=false));
+//	required int64 field_id=-1 order_id (Int(bitWidth=64, isSigned=false));
+//	required int32 field_id=-1 instrument_id (Int(bitWidth=32, isSigned=false));
+//	required binary field_id=-1 side (String);
+//	required double field_id=-1 price;
+//	required int64 field_id=-1 size (Int(bitWidth=64, isSigned=true));
+//	required int64 field_id=-1 leaves_size (Int(bitWidth=64, isSigned=true));
+//	required binary field_id=-1 liquidity (String);
+//	required double field_id=-1 fee;
+func ParquetGroupNode_Fills() *pqschema.GroupNode {
+	stringNode := func(name string) pqschema.Node {
+		return pqschema.MustPrimitive(pqschema.NewPrimitiveNodeConverted(name, parquet.Repetitions.Required, parquet.Types.ByteArray, pqschema.ConvertedTypes.UTF8, 0, 0, 0, -1))
+	}
+	int64Node := func(name string) pqschema.Node {
+		return pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical(name, parquet.Repetitions.Required, pqschema.NewIntLogicalType(64, true), parquet.Types.Int64, 0, -1))
+	}
+	return pqschema.MustGroup(pqschema.NewGroupNode("schema", parquet.Repetitions.Required, pqschema.FieldList{
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("ts", parquet.Repetitions.Required, pqschema.NewTimestampLogicalType(true, pqschema.TimeUnitNanos), parquet.Types.Int64, 0, -1)),
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("order_id", parquet.Repetitions.Required, pqschema.NewIntLogicalType(64, false), parquet.Types.Int64, 0, -1)),
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("instrument_id", parquet.Repetitions.Required, pqschema.NewIntLogicalType(32, false), parquet.Types.Int32, 0, -1)),
+		stringNode("side"),
+		pqschema.NewFloat64Node("price", parquet.Repetitions.Required, -1),
+		int64Node("size"),
+		int64Node("leaves_size"),
+		stringNode("liquidity"),
+		pqschema.NewFloat64Node("fee", parquet.Repetitions.Required, -1),
+	}, -1))
+}
+
+// WriteFillsParquet writes a fill log, such as Exchange.Fills, to `writer` as Parquet, per ParquetGroupNode_Fills.
+// Sides are "B" or "A", and liquidity is "maker" or "taker".
+// If `writer` is an io.Closer, such as an os.File, it is closed.
+func WriteFillsParquet(fills []Fill, writer io.Writer) error {
+	ts := make([]int64, len(fills))
+	orderIDs := make([]int64, len(fills))
+	instrumentIDs := make([]int32, len(fills))
+	sides := make([]parquet.ByteArray, len(fills))
+	prices := make([]float64, len(fills))
+	sizes := make([]int64, len(fills))
+	leavesSizes := make([]int64, len(fills))
+	liquidities := make([]parquet.ByteArray, len(fills))
+	fees := make([]float64, len(fills))
+	for i, fill := range fills {
+		ts[i] = int64(fill.Ts)
+		orderIDs[i] = int64(fill.OrderID)
+		instrumentIDs[i] = int32(fill.InstrumentID)
+		sides[i] = parquet.ByteArray{byte(fill.Side)}
+		prices[i] = dbn.Fixed9ToFloat64(fill.Price)
+		sizes[i] = int64(fill.Size)
+		leavesSizes[i] = int64(fill.LeavesSize)
+		liquidities[i] = parquet.ByteArray(fill.Liquidity.String())
+		fees[i] = fill.Fee
+	}
+
+	pwProperties := parquet.NewWriterProperties(
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithCompression(compress.Codecs.Snappy))
+	pw := pqfile.NewParquetWriter(writer, ParquetGroupNode_Fills(), pqfile.WithWriterProps(pwProperties))
+	defer pw.Close()
+	rgw := pw.AppendBufferedRowGroup()
+
+	errWrite := errors.Join(
+		writeFillsColumn[*pqfile.Int64ColumnChunkWriter](rgw, 0, ts),
+		writeFillsColumn[*pqfile.Int64ColumnChunkWriter](rgw, 1, orderIDs),
+		writeFillsColumn[*pqfile.Int32ColumnChunkWriter](rgw, 2, instrumentIDs),
+		writeFillsColumn[*pqfile.ByteArrayColumnChunkWriter](rgw, 3, sides),
+		writeFillsColumn[*pqfile.Float64ColumnChunkWriter](rgw, 4, prices),
+		writeFillsColumn[*pqfile.Int64ColumnChunkWriter](rgw, 5, sizes),
+		writeFillsColumn[*pqfile.Int64ColumnChunkWriter](rgw, 6, leavesSizes),
+		writeFillsColumn[*pqfile.ByteArrayColumnChunkWriter](rgw, 7, liquidities),
+		writeFillsColumn[*pqfile.Float64ColumnChunkWriter](rgw, 8, fees),
+	)
+	errClose := rgw.Close()
+	errFlush := pw.FlushWithFooter()
+	return errors.Join(errWrite, errClose, errFlush)
+}
+
+// writeFillsColumn writes the values of a required column with a column writer of type W.
+func writeFillsColumn[W interface {
+	WriteBatch(values []T, defLevels, repLevels []int16) (int64, error)
+}, T any](rgw pqfile.BufferedRowGroupWriter, idx int, values []T) error {
+	cw, err := rgw.Column(idx)
+	if err != nil {
+		return fmt.Errorf("failed to get column %d: %w", idx, err)
+	}
+	writer, ok := cw.(W)
+	if !ok {
+		return fmt.Errorf("column %d has unexpected writer type %T", idx, cw)
+	}
+	if _, err := writer.WriteBatch(values, nil, nil); err != nil {
+		return fmt.Errorf("failed writing column %d: %w", idx, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// LiveClock is a Clock of wall time which drives a Visitor from a live stream, such as
+// that of a LiveClient's DbnScanner.  Records and Timers are delivered on Run's goroutine.
+type LiveClock struct {
+	timers timerQueue
+}
+
+// NewLiveClock returns a LiveClock.
+func NewLiveClock() *LiveClock {
+	return &LiveClock{}
+}
+
+// Now returns the wall time.
+func (c *LiveClock) Now() time.Time {
+	return time.Now()
+}
+
+// Schedule returns a Timer which calls `fn` at the wall time `at`, or as soon as possible if it has passed.
+func (c *LiveClock) Schedule(at time.Time, fn TimerFunc) *Timer {
+	return c.timers.schedule(at, fn)
+}
+
+// After returns a Timer which calls `fn` after the duration `d` from Now.
+func (c *LiveClock) After(d time.Duration, fn TimerFunc) *Timer {
+	return c.timers.schedule(time.Now().Add(d), fn)
+}
+
+// Run delivers the scanner's records to the visitor as they arrive, and fires Timers when they are
+// due, between records.  At the end of the stream, it calls the visitor's OnStreamEnd.  Returns the
+// first error of the scanner, visitor, or Timers, or the context's if it is done.  A scanner which
+// is blocked reading is left to its goroutine; stop the LiveClient to end it.
+func (c *LiveClock) Run(ctx context.Context, visitor dbn.Visitor, scanner *dbn.DbnScanner) error {
+	// The scanner reads on its own goroutine, so Timers can fire while it waits for a record,
+	// and waits for each record to be visited before reading the next, which reuses its buffer.
+	done := make(chan struct{})
+	defer close(done)
+	nexts := make(chan bool)
+	resume := make(chan struct{})
+	go func() {
+		for {
+			ok := scanner.Next()
+			select {
+			case nexts <- ok:
+			case <-done:
+				return
+			}
+			if !ok {
+				return
+			}
+			select {
+			case <-resume:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	advance := func(at time.Time) (time.Time, error) {
+		return time.Now(), nil
+	}
+	for {
+		var timerC <-chan time.Time
+		var wallTimer *time.Timer
+		if next := c.timers.peek(); next != nil {
+			wallTimer = time.NewTimer(time.Until(next.at))
+			timerC = wallTimer.C
+		}
+
+		var err error
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-timerC:
+			err = c.timers.fireDue(time.Now(), advance)
+		case ok := <-nexts:
+			if !ok {
+				if err = scanner.Error(); err != nil && err != io.EOF {
+					err = fmt.Errorf("scanner error: %w", err)
+				} else {
+					err = visitor.OnStreamEnd()
+				}
+				if wallTimer != nil {
+					wallTimer.Stop()
+				}
+				return err
+			}
+			if err = c.timers.fireDue(time.Now(), advance); err == nil {
+				err = scanner.Visit(visitor)
+			}
+			if err == nil {
+				resume <- struct{}{}
+			}
+		}
+		if wallTimer != nil {
+			wallTimer.Stop()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
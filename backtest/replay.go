@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_backtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// TimestampField is the record timestamp which drives a Replay's Clock.
+type TimestampField uint8
+
+const (
+	// TimestampField_TsRecv is `ts_recv`, or `ts_event` for records without one, such as OHLCV.
+	TimestampField_TsRecv TimestampField = 0
+	// TimestampField_TsEvent is `ts_event`.
+	TimestampField_TsEvent TimestampField = 1
+)
+
+// Pacing is how fast a Replay delivers records.
+type Pacing uint8
+
+const (
+	// Pacing_AsFastAsPossible delivers records without waiting.
+	Pacing_AsFastAsPossible Pacing = 0
+	// Pacing_RealTime delivers records as far apart in wall time as in the Clock, divided by Options.Speed.
+	Pacing_RealTime Pacing = 1
+)
+
+// Options controls a Replay.
+type Options struct {
+	Timestamp TimestampField // Record timestamp of the Clock
+	Latency   time.Duration  // Feed latency, added to each record's timestamp before it is delivered
+	Pacing    Pacing         // How fast records are delivered
+	Speed     float64        // Multiple of real time for Pacing_RealTime; 1 if 0
+}
+
+// Replay is a Clock which drives a Visitor from DBN streams.  Records are delivered when the
+// Clock reaches their timestamp plus Options.Latency, and Timers fire between them.  The Clock
+// never goes backwards, so a record whose time is before an earlier one's is delivered at the
+// earlier time.  Before the first record, Now is the zero time.
+type Replay struct {
+	opts      Options
+	now       time.Time
+	timers    timerQueue
+	wallStart time.Time // wall time of the first record, for Pacing_RealTime
+	simStart  time.Time // Clock time of the first record, for Pacing_RealTime
+}
+
+// NewReplay returns a Replay with the options.
+func NewReplay(opts Options) *Replay {
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+	return &Replay{opts: opts}
+}
+
+// Now returns the Clock's time: that of the record or Timer being delivered.
+func (r *Replay) Now() time.Time {
+	return r.now
+}
+
+// Schedule returns a Timer which calls `fn` when the Clock reaches `at`, before any record of the
+// same time.  Timers which have not fired by the end of the streams do not fire.
+func (r *Replay) Schedule(at time.Time, fn TimerFunc) *Timer {
+	return r.timers.schedule(at, fn)
+}
+
+// After returns a Timer which calls `fn` after the duration `d` from Now.
+func (r *Replay) After(d time.Duration, fn TimerFunc) *Timer {
+	return r.timers.schedule(r.now.Add(d), fn)
+}
+
+// Run merges the scanners' records in `ts_recv` order, as by dbn.DbnMergeScanner, and delivers
+// them to the visitor.  Records with equal timestamps are taken from the scanners in the order
+// given, so pass definitions before the data which refers to them, as in definitions, then MBO,
+// then trades.  At the end of the streams, it calls the visitor's OnStreamEnd.  Returns the
+// first error of the scanners, visitor, or Timers, or the context's if it is done.
+func (r *Replay) Run(ctx context.Context, visitor dbn.Visitor, scanners ...*dbn.DbnScanner) error {
+	merger := dbn.NewDbnMergeScanner(scanners...)
+	for merger.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		scanner, _ := merger.Current()
+		at := dbn.TimestampToTime(r.recordTs(scanner)).Add(r.opts.Latency)
+		if at.Before(r.now) {
+			at = r.now
+		}
+		if err := r.advance(ctx, at); err != nil {
+			return err
+		}
+		if err := merger.Visit(visitor); err != nil {
+			return err
+		}
+	}
+	if err := merger.Error(); err != nil && err != io.EOF {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+	return visitor.OnStreamEnd()
+}
+
+// RunFiles runs the visitor over DBN files, as Run.
+func (r *Replay) RunFiles(ctx context.Context, visitor dbn.Visitor, sourceFiles ...string) error {
+	scanners := make([]*dbn.DbnScanner, 0, len(sourceFiles))
+	for _, sourceFile := range sourceFiles {
+		reader, closer, err := dbn.MakeCompressedReader(sourceFile, false)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+		defer closer.Close()
+		scanners = append(scanners, dbn.NewDbnScanner(reader))
+	}
+	return r.Run(ctx, visitor, scanners...)
+}
+
+// recordTs returns the timestamp of the scanner's last record which drives the Clock.
+func (r *Replay) recordTs(scanner *dbn.DbnScanner) uint64 {
+	if r.opts.Timestamp == TimestampField_TsRecv {
+		if ts, ok := scanner.GetLastTsRecv(); ok && ts != dbn.UNDEF_TIMESTAMP {
+			return ts
+		}
+	}
+	header, _ := scanner.GetLastHeader()
+	return header.TsEvent
+}
+
+// advance fires the Timers up to `at`, pacing each, and sets the Clock to `at`.
+func (r *Replay) advance(ctx context.Context, at time.Time) error {
+	err := r.timers.fireDue(at, func(timerAt time.Time) (time.Time, error) {
+		if timerAt.After(r.now) {
+			r.now = timerAt
+		}
+		return r.now, r.pace(ctx, r.now)
+	})
+	if err != nil {
+		return err
+	}
+	r.now = at
+	return r.pace(ctx, at)
+}
+
+// pace waits until the wall time of Clock time `at`, for Pacing_RealTime.
+func (r *Replay) pace(ctx context.Context, at time.Time) error {
+	if r.opts.Pacing != Pacing_RealTime {
+		return nil
+	}
+	if r.wallStart.IsZero() {
+		r.wallStart, r.simStart = time.Now(), at
+		return nil
+	}
+	wait := time.Until(r.wallStart.Add(time.Duration(float64(at.Sub(r.simStart)) / r.opts.Speed)))
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
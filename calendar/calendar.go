@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Neomantra Corp
+
+// Package dbn_calendar maps timestamps to exchange-local trading sessions,
+// so records can be grouped by an exchange's trading day rather than by UTC date.
+// For example, CME Globex's session for Tuesday opens at 17:00 Chicago time on Monday.
+package dbn_calendar
+
+import (
+	"slices"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// Calendar defines the trading sessions of a venue.
+//
+// Each session is named by its date (YYYYMMDD) in the Calendar's Location.
+// If Open is after Close, the session is overnight and opens on the prior day;
+// if Open equals Close, the session spans the whole local day.
+type Calendar struct {
+	Name        string                   // Name of the calendar, e.g. "CME"
+	Location    *time.Location           // Time zone of the venue
+	Open        time.Duration            // Local time of day the session opens
+	Close       time.Duration            // Local time of day the session closes
+	Weekdays    []time.Weekday           // Weekdays of session dates
+	Holidays    map[uint32]bool          // Session dates, as YYYYMMDD, with no session
+	EarlyCloses map[uint32]time.Duration // Session dates, as YYYYMMDD, with an early Close
+}
+
+// NewCalendar returns a Calendar for Monday through Friday sessions with no holidays.
+func NewCalendar(name string, loc *time.Location, open time.Duration, close time.Duration) *Calendar {
+	return &Calendar{
+		Name:        name,
+		Location:    loc,
+		Open:        open,
+		Close:       close,
+		Weekdays:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Holidays:    make(map[uint32]bool),
+		EarlyCloses: make(map[uint32]time.Duration),
+	}
+}
+
+// IsOvernight returns true if sessions open on the prior day.
+func (c *Calendar) IsOvernight() bool {
+	return c.Open > c.Close
+}
+
+// IsTradingDay returns true if there is a session on the date, as YYYYMMDD.
+func (c *Calendar) IsTradingDay(ymd uint32) bool {
+	if c.Holidays[ymd] {
+		return false
+	}
+	return slices.Contains(c.Weekdays, dbn.YMDToTime(int(ymd), c.Location).Weekday())
+}
+
+// NextTradingDay returns the first trading day after the date, as YYYYMMDD.
+// Returns 0 if there is none within a year.
+func (c *Calendar) NextTradingDay(ymd uint32) uint32 {
+	return c.stepTradingDay(ymd, 1)
+}
+
+// PrevTradingDay returns the last trading day before the date, as YYYYMMDD.
+// Returns 0 if there is none within a year.
+func (c *Calendar) PrevTradingDay(ymd uint32) uint32 {
+	return c.stepTradingDay(ymd, -1)
+}
+
+func (c *Calendar) stepTradingDay(ymd uint32, step int) uint32 {
+	date := dbn.YMDToTime(int(ymd), c.Location)
+	for i := 0; i < 366; i++ {
+		date = date.AddDate(0, 0, step)
+		if next := dbn.TimeToYMD(date); c.IsTradingDay(next) {
+			return next
+		}
+	}
+	return 0
+}
+
+// SessionDate returns the date, as YYYYMMDD, of the session containing or following `t`.
+// Times at or after an overnight session's Open belong to the next day's session,
+// and times on non-trading days belong to the next trading day's session.
+func (c *Calendar) SessionDate(t time.Time) uint32 {
+	local := t.In(c.Location)
+	ymd := dbn.TimeToYMD(local)
+	if c.IsOvernight() && timeOfDay(local) >= c.Open {
+		ymd = dbn.TimeToYMD(local.AddDate(0, 0, 1))
+	}
+	if !c.IsTradingDay(ymd) {
+		if next := c.NextTradingDay(ymd); next != 0 {
+			return next
+		}
+	}
+	return ymd
+}
+
+// SessionDateOf returns the session date, as YYYYMMDD, of a UNIX nanosecond timestamp such as ts_event.
+func (c *Calendar) SessionDateOf(timestamp uint64) uint32 {
+	return c.SessionDate(dbn.TimestampToTime(timestamp))
+}
+
+// SessionBounds returns the open and close times of the session on the date, as YYYYMMDD.
+// It does not check whether the date is a trading day.
+func (c *Calendar) SessionBounds(ymd uint32) (time.Time, time.Time) {
+	date := dbn.YMDToTime(int(ymd), c.Location)
+	openDate := date
+	if c.IsOvernight() {
+		openDate = date.AddDate(0, 0, -1)
+	}
+	closeDate, closeTime := date, c.Close
+	if early, ok := c.EarlyCloses[ymd]; ok {
+		closeTime = early
+	} else if c.Open == c.Close {
+		closeDate = date.AddDate(0, 0, 1)
+	}
+	return atTimeOfDay(openDate, c.Open), atTimeOfDay(closeDate, closeTime)
+}
+
+// IsOpen returns true if `t` is within a trading day's session.
+func (c *Calendar) IsOpen(t time.Time) bool {
+	ymd := c.SessionDate(t)
+	if !c.IsTradingDay(ymd) {
+		return false
+	}
+	open, close := c.SessionBounds(ymd)
+	return !t.Before(open) && t.Before(close)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// timeOfDay returns the wall-clock duration since midnight of `t` in its location.
+func timeOfDay(t time.Time) time.Duration {
+	hour, min, sec := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atTimeOfDay returns the wall-clock time `tod` on the date of `date`, honoring daylight saving.
+func atTimeOfDay(date time.Time, tod time.Duration) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day,
+		int(tod/time.Hour), int(tod%time.Hour/time.Minute), int(tod%time.Minute/time.Second), int(tod%time.Second),
+		date.Location())
+}
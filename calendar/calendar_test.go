@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Test Launcher
+func TestDbnCalendar(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dbn-go calendar suite")
+}
+
+var _ = Describe("DbnCalendar", func() {
+	var registry *Registry
+	BeforeEach(func() {
+		registry = NewRegistry()
+	})
+
+	Context("CME sessions", func() {
+		It("should assign Sunday evening to Monday's session", func() {
+			cme := registry.Get(Calendar_CME)
+			chicago := cme.Location
+			// Sunday 2024-03-03 17:00 CST opens Monday's session
+			Expect(cme.SessionDate(time.Date(2024, 3, 3, 17, 0, 0, 0, chicago))).To(Equal(uint32(20240304)))
+			Expect(cme.SessionDate(time.Date(2024, 3, 4, 15, 59, 0, 0, chicago))).To(Equal(uint32(20240304)))
+			Expect(cme.SessionDate(time.Date(2024, 3, 4, 17, 0, 0, 0, chicago))).To(Equal(uint32(20240305)))
+			// Friday evening rolls to Monday
+			Expect(cme.SessionDate(time.Date(2024, 3, 8, 17, 30, 0, 0, chicago))).To(Equal(uint32(20240311)))
+			// UTC midnight falls mid-session
+			Expect(cme.SessionDateOf(uint64(time.Date(2024, 3, 5, 0, 30, 0, 0, time.UTC).UnixNano()))).To(Equal(uint32(20240305)))
+		})
+		It("should handle daylight saving", func() {
+			cme := registry.Get(Calendar_CME)
+			// US DST began 2024-03-10; the session for 2024-03-11 opens 17:00 CDT on Sunday
+			open, close := cme.SessionBounds(20240311)
+			Expect(open.UTC()).To(Equal(time.Date(2024, 3, 10, 22, 0, 0, 0, time.UTC)))
+			Expect(close.UTC()).To(Equal(time.Date(2024, 3, 11, 21, 0, 0, 0, time.UTC)))
+			open, _ = cme.SessionBounds(20240308)
+			Expect(open.UTC()).To(Equal(time.Date(2024, 3, 7, 23, 0, 0, 0, time.UTC)))
+		})
+		It("should know when it is open", func() {
+			cme := registry.Get(Calendar_CME)
+			chicago := cme.Location
+			Expect(cme.IsOpen(time.Date(2024, 3, 4, 10, 0, 0, 0, chicago))).To(BeTrue())
+			Expect(cme.IsOpen(time.Date(2024, 3, 4, 16, 30, 0, 0, chicago))).To(BeFalse())
+			Expect(cme.IsOpen(time.Date(2024, 3, 9, 10, 0, 0, 0, chicago))).To(BeFalse())
+		})
+	})
+
+	Context("holidays", func() {
+		It("should load holidays and early closes", func() {
+			err := registry.LoadHolidays(strings.NewReader(`{"calendars": [{
+				"name": "cme",
+				"holidays": ["2024-12-25", 20250101],
+				"early_closes": {"2024-12-24": "12:15"}
+			}]}`))
+			Expect(err).To(BeNil())
+			cme := registry.Get(Calendar_CME)
+			Expect(cme.IsTradingDay(20241225)).To(BeFalse())
+			Expect(cme.IsTradingDay(20250101)).To(BeFalse())
+			Expect(cme.NextTradingDay(20241224)).To(Equal(uint32(20241226)))
+			Expect(cme.PrevTradingDay(20241226)).To(Equal(uint32(20241224)))
+			// Christmas Eve evening belongs to the 26th
+			Expect(cme.SessionDate(time.Date(2024, 12, 24, 17, 0, 0, 0, cme.Location))).To(Equal(uint32(20241226)))
+			_, close := cme.SessionBounds(20241224)
+			Expect(close).To(Equal(time.Date(2024, 12, 24, 12, 15, 0, 0, cme.Location)))
+		})
+		It("should define new calendars", func() {
+			err := registry.LoadHolidays(strings.NewReader(`{"calendars": [{
+				"name": "TOKYO",
+				"timezone": "Asia/Tokyo",
+				"open": "09:00",
+				"close": "15:00",
+				"venues": ["XNAS"]
+			}]}`))
+			Expect(err).To(BeNil())
+			tokyo := registry.Get("tokyo")
+			Expect(tokyo).NotTo(BeNil())
+			Expect(registry.ForVenue(dbn.Venue_Xnas)).To(Equal(tokyo))
+			Expect(tokyo.SessionDate(time.Date(2024, 3, 4, 23, 0, 0, 0, time.UTC))).To(Equal(uint32(20240305)))
+		})
+		It("should reject bad definitions", func() {
+			Expect(registry.LoadHolidays(strings.NewReader(`{"calendars": [{"name": "NEW"}]}`))).NotTo(BeNil())
+			Expect(registry.LoadHolidays(strings.NewReader(`{"calendars": [{"name": "CME", "holidays": ["12/25/2024"]}]}`))).NotTo(BeNil())
+			Expect(registry.LoadHolidays(strings.NewReader(`{"calendars": [{"name": "CME", "open": "25:00"}]}`))).NotTo(BeNil())
+		})
+	})
+
+	Context("lookup", func() {
+		It("should find calendars by venue and dataset", func() {
+			Expect(registry.ForDataset(dbn.Dataset_GlbxMdp3).Name).To(Equal(Calendar_CME))
+			Expect(registry.ForDatasetName("XNAS.ITCH").Name).To(Equal(Calendar_UsEquity))
+			Expect(registry.ForDatasetName("OPRA.PILLAR").Name).To(Equal(Calendar_UsOptions))
+			Expect(registry.ForVenue(dbn.Venue_Ifeu).Name).To(Equal(Calendar_IceEurope))
+			Expect(registry.ForDatasetName("NOT.A.DATASET").Name).To(Equal(Calendar_UTC))
+		})
+		It("should treat UTC as every day", func() {
+			utc := registry.Get(Calendar_UTC)
+			Expect(utc.SessionDate(time.Date(2024, 3, 9, 23, 59, 0, 0, time.UTC))).To(Equal(uint32(20240309)))
+			open, close := utc.SessionBounds(20240309)
+			Expect(close.Sub(open)).To(Equal(24 * time.Hour))
+		})
+	})
+
+	Context("parsing", func() {
+		It("should parse times of day and dates", func() {
+			tod, err := ParseTimeOfDay("16:30")
+			Expect(err).To(BeNil())
+			Expect(tod).To(Equal(16*time.Hour + 30*time.Minute))
+			_, err = ParseTimeOfDay("1630")
+			Expect(err).NotTo(BeNil())
+			ymd, err := ParseDate("20241225")
+			Expect(err).To(BeNil())
+			Expect(ymd).To(Equal(uint32(20241225)))
+		})
+	})
+})
@@ -0,0 +1,333 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // built-in calendars need their time zones, even on hosts without zoneinfo
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// Names of the built-in calendars
+const (
+	Calendar_UTC       = "UTC"
+	Calendar_CME       = "CME"
+	Calendar_CFE       = "CFE"
+	Calendar_UsEquity  = "US_EQUITY"
+	Calendar_UsOptions = "US_OPTIONS"
+	Calendar_IceUs     = "ICE_US"
+	Calendar_IceEurope = "ICE_EUROPE"
+	Calendar_IceEndex  = "ICE_ENDEX"
+	Calendar_Eurex     = "EUREX"
+	Calendar_Eex       = "EEX"
+	Calendar_BlueOcean = "BLUE_OCEAN"
+)
+
+// Registry holds Calendars by name and by Venue.
+type Registry struct {
+	calendars map[string]*Calendar
+	venues    map[dbn.Venue]string
+}
+
+// NewRegistry returns a Registry of the built-in calendars.
+// The built-ins have no holidays; load them with LoadHolidays.
+func NewRegistry() *Registry {
+	r := &Registry{
+		calendars: make(map[string]*Calendar),
+		venues:    make(map[dbn.Venue]string),
+	}
+	utc := NewCalendar(Calendar_UTC, time.UTC, 0, 0)
+	utc.Weekdays = append(utc.Weekdays, time.Saturday, time.Sunday)
+	r.Add(utc)
+
+	chicago := mustLoadLocation("America/Chicago")
+	newYork := mustLoadLocation("America/New_York")
+	r.Add(NewCalendar(Calendar_CME, chicago, 17*time.Hour, 16*time.Hour), dbn.Venue_Glbx)
+	r.Add(NewCalendar(Calendar_CFE, chicago, 17*time.Hour, 16*time.Hour), dbn.Venue_Xcbf)
+	r.Add(NewCalendar(Calendar_UsEquity, newYork, 4*time.Hour, 20*time.Hour),
+		dbn.Venue_Xnas, dbn.Venue_Xbos, dbn.Venue_Xpsx, dbn.Venue_Bats, dbn.Venue_Baty, dbn.Venue_Edga,
+		dbn.Venue_Edgx, dbn.Venue_Xnys, dbn.Venue_Xcis, dbn.Venue_Xase, dbn.Venue_Arcx, dbn.Venue_Xchi,
+		dbn.Venue_Iexg, dbn.Venue_Finn, dbn.Venue_Finc, dbn.Venue_Finy, dbn.Venue_Memx, dbn.Venue_Eprl,
+		dbn.Venue_Dbeq, dbn.Venue_Sphr, dbn.Venue_Ltse, dbn.Venue_Xoff, dbn.Venue_Aspn, dbn.Venue_Asmt,
+		dbn.Venue_Aspi, dbn.Venue_Equs)
+	r.Add(NewCalendar(Calendar_UsOptions, newYork, 9*time.Hour+30*time.Minute, 16*time.Hour+15*time.Minute),
+		dbn.Venue_Amxo, dbn.Venue_Xbox, dbn.Venue_Xcbo, dbn.Venue_Emld, dbn.Venue_Edgo, dbn.Venue_Gmni,
+		dbn.Venue_Xisx, dbn.Venue_Mcry, dbn.Venue_Xmio, dbn.Venue_Arco, dbn.Venue_Opra, dbn.Venue_Mprl,
+		dbn.Venue_Xndq, dbn.Venue_Xbxo, dbn.Venue_C2Ox, dbn.Venue_Xphl, dbn.Venue_Bato, dbn.Venue_Mxop)
+	r.Add(NewCalendar(Calendar_IceUs, newYork, 20*time.Hour, 18*time.Hour), dbn.Venue_Ifus)
+	r.Add(NewCalendar(Calendar_IceEurope, mustLoadLocation("Europe/London"), 1*time.Hour, 23*time.Hour),
+		dbn.Venue_Ifeu, dbn.Venue_Ifll)
+	r.Add(NewCalendar(Calendar_IceEndex, mustLoadLocation("Europe/Amsterdam"), 8*time.Hour, 18*time.Hour),
+		dbn.Venue_Ndex)
+	berlin := mustLoadLocation("Europe/Berlin")
+	r.Add(NewCalendar(Calendar_Eurex, berlin, 1*time.Hour+10*time.Minute, 22*time.Hour), dbn.Venue_Xeur)
+	r.Add(NewCalendar(Calendar_Eex, berlin, 8*time.Hour, 18*time.Hour), dbn.Venue_Xeee)
+	r.Add(NewCalendar(Calendar_BlueOcean, newYork, 20*time.Hour, 4*time.Hour), dbn.Venue_Ocea)
+	return r
+}
+
+// Add adds the Calendar to the Registry, replacing any of the same name,
+// and makes it the Calendar for each of `venues`.
+func (r *Registry) Add(cal *Calendar, venues ...dbn.Venue) {
+	r.calendars[strings.ToUpper(cal.Name)] = cal
+	for _, venue := range venues {
+		r.venues[venue] = strings.ToUpper(cal.Name)
+	}
+}
+
+// Get returns the Calendar with the case-insensitive name, or nil if there is none.
+func (r *Registry) Get(name string) *Calendar {
+	return r.calendars[strings.ToUpper(name)]
+}
+
+// Names returns the sorted names of all the Calendars in the Registry.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.calendars))
+	for _, cal := range r.calendars {
+		names = append(names, cal.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ForVenue returns the Calendar of the Venue, or the UTC Calendar if there is none.
+func (r *Registry) ForVenue(venue dbn.Venue) *Calendar {
+	if name, ok := r.venues[venue]; ok {
+		if cal := r.calendars[name]; cal != nil {
+			return cal
+		}
+	}
+	return r.Get(Calendar_UTC)
+}
+
+// ForPublisher returns the Calendar of the Publisher's Venue, or the UTC Calendar if there is none.
+func (r *Registry) ForPublisher(publisher dbn.Publisher) *Calendar {
+	return r.ForVenue(publisher.Venue())
+}
+
+// ForDataset returns the Calendar of the Dataset's primary Venue, or the UTC Calendar if there is none.
+// Consolidated datasets, such as DBEQ.BASIC, use the Venue of their first Publisher.
+func (r *Registry) ForDataset(dataset dbn.Dataset) *Calendar {
+	publishers := dataset.Publishers()
+	if len(publishers) == 0 {
+		return r.Get(Calendar_UTC)
+	}
+	return r.ForVenue(publishers[0].Venue())
+}
+
+// ForDatasetName returns the Calendar of the named Dataset, such as "GLBX.MDP3".
+// Returns the UTC Calendar if the Dataset is unknown.
+func (r *Registry) ForDatasetName(dataset string) *Calendar {
+	d, err := dbn.DatasetFromString(dataset)
+	if err != nil {
+		return r.Get(Calendar_UTC)
+	}
+	return r.ForDataset(d)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// calendarFile is the JSON layout read by LoadHolidays.
+type calendarFile struct {
+	Calendars []calendarFileEntry `json:"calendars"`
+}
+
+type calendarFileEntry struct {
+	Name        string            `json:"name"`
+	Timezone    string            `json:"timezone,omitempty"`
+	Open        string            `json:"open,omitempty"`
+	Close       string            `json:"close,omitempty"`
+	Weekdays    []string          `json:"weekdays,omitempty"`
+	Venues      []string          `json:"venues,omitempty"`
+	Datasets    []string          `json:"datasets,omitempty"`
+	Holidays    []json.RawMessage `json:"holidays,omitempty"`
+	EarlyCloses map[string]string `json:"early_closes,omitempty"`
+}
+
+// LoadHolidaysFile reads calendar definitions from a JSON file; see LoadHolidays.
+func (r *Registry) LoadHolidaysFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := r.LoadHolidays(file); err != nil {
+		return fmt.Errorf("failed to load '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// LoadHolidays reads calendar definitions from JSON, such as:
+//
+//	{"calendars": [{
+//	    "name": "CME",
+//	    "holidays": ["2024-12-25", 20250101],
+//	    "early_closes": {"2024-12-24": "12:15"}
+//	}]}
+//
+// Entries naming an existing Calendar add to its holidays and may override its
+// "timezone", "open", "close", and "weekdays".  Entries with a new name define a new
+// Calendar and must include a "timezone", "open", and "close".  Either may list the
+// "venues" (e.g. "XNAS") and "datasets" (e.g. "XNAS.ITCH") which use it.
+func (r *Registry) LoadHolidays(reader io.Reader) error {
+	var file calendarFile
+	if err := json.NewDecoder(reader).Decode(&file); err != nil {
+		return err
+	}
+	for _, entry := range file.Calendars {
+		if err := r.loadEntry(&entry); err != nil {
+			return fmt.Errorf("calendar '%s': %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) loadEntry(entry *calendarFileEntry) error {
+	if entry.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	cal := r.Get(entry.Name)
+	if cal == nil {
+		if entry.Timezone == "" || entry.Open == "" || entry.Close == "" {
+			return fmt.Errorf("new calendar requires timezone, open, and close")
+		}
+		cal = NewCalendar(entry.Name, time.UTC, 0, 0)
+	}
+	if entry.Timezone != "" {
+		loc, err := time.LoadLocation(entry.Timezone)
+		if err != nil {
+			return err
+		}
+		cal.Location = loc
+	}
+	var err error
+	if entry.Open != "" {
+		if cal.Open, err = ParseTimeOfDay(entry.Open); err != nil {
+			return err
+		}
+	}
+	if entry.Close != "" {
+		if cal.Close, err = ParseTimeOfDay(entry.Close); err != nil {
+			return err
+		}
+	}
+	if len(entry.Weekdays) != 0 {
+		cal.Weekdays = cal.Weekdays[:0:0]
+		for _, str := range entry.Weekdays {
+			weekday, err := parseWeekday(str)
+			if err != nil {
+				return err
+			}
+			cal.Weekdays = append(cal.Weekdays, weekday)
+		}
+	}
+	for _, raw := range entry.Holidays {
+		ymd, err := parseHolidayDate(raw)
+		if err != nil {
+			return err
+		}
+		cal.Holidays[ymd] = true
+	}
+	for dateStr, closeStr := range entry.EarlyCloses {
+		ymd, err := ParseDate(dateStr)
+		if err != nil {
+			return err
+		}
+		if cal.EarlyCloses[ymd], err = ParseTimeOfDay(closeStr); err != nil {
+			return err
+		}
+	}
+
+	venues := make([]dbn.Venue, 0, len(entry.Venues))
+	for _, str := range entry.Venues {
+		venue, err := dbn.VenueFromString(str)
+		if err != nil {
+			return err
+		}
+		venues = append(venues, venue)
+	}
+	for _, str := range entry.Datasets {
+		dataset, err := dbn.DatasetFromString(str)
+		if err != nil {
+			return err
+		}
+		for _, publisher := range dataset.Publishers() {
+			venues = append(venues, publisher.Venue())
+		}
+	}
+	r.Add(cal, venues...)
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ParseTimeOfDay parses a local time of day as "HH:MM" or "HH:MM:SS".
+func ParseTimeOfDay(str string) (time.Duration, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", str)
+	}
+	limits := []int{24, 59, 59}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var tod time.Duration
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n > limits[i] {
+			return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", str)
+		}
+		tod += time.Duration(n) * units[i]
+	}
+	if tod > 24*time.Hour {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", str)
+	}
+	return tod, nil
+}
+
+// ParseDate parses a date as "YYYY-MM-DD" or "YYYYMMDD", returning it as YYYYMMDD.
+func ParseDate(str string) (uint32, error) {
+	for _, layout := range []string{time.DateOnly, "20060102"} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return dbn.TimeToYMD(t), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", str)
+}
+
+// parseHolidayDate parses a holiday given as a date string or a YYYYMMDD number.
+func parseHolidayDate(raw json.RawMessage) (uint32, error) {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return ParseDate(str)
+	}
+	var ymd uint32
+	if err := json.Unmarshal(raw, &ymd); err != nil {
+		return 0, fmt.Errorf("invalid holiday %s", string(raw))
+	}
+	return ParseDate(strconv.FormatUint(uint64(ymd), 10))
+}
+
+func parseWeekday(str string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := day.String()
+		if strings.EqualFold(str, name) || strings.EqualFold(str, name[:3]) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday '%s'", str)
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("dbn_calendar: failed to load time zone %s: %v", name, err))
+	}
+	return loc
+}
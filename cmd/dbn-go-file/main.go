@@ -5,12 +5,19 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/NimbleMarkets/dbn-go"
+	dbn_calendar "github.com/NimbleMarkets/dbn-go/calendar"
 	dbn_file "github.com/NimbleMarkets/dbn-go/internal/file"
 	"github.com/NimbleMarkets/dbn-go/internal/version"
+	dbn_synth "github.com/NimbleMarkets/dbn-go/synth"
+	"github.com/relvacode/iso8601"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +28,49 @@ var (
 
 	destDir string // destination directory
 
-	forceZstdInput = false // force input to be zstd, irrespective of filename suffix
+	forceZstdInput = false // force input to be zstd, rather than detecting its compression
+
+	compressOpts dbn.CompressionOptions // --compression, --compression-level, and --threads for outputs
+
+	jsonWriteOpts dbn_file.JsonWriteOptions // options for json
+	jsonPretty    bool                      // sets both --pretty-px and --pretty-ts
+
+	dbnWriteOpts dbn_file.DbnWriteOptions // options for from-json and from-parquet
+	dbnOutFile   string                   // destination file for from-json and from-parquet
+
+	parquetPartitionBy string                           // comma-separated partition keys for parquet
+	parquetPartOpts    dbn_file.ParquetPartitionOptions // options for partitioned parquet
+	parquetMaxFileMB   int64                            // roll partitioned parquet files after this many MB
+
+	splitOpts         = dbn_file.SplitOptions{MaxOpenFiles: dbn_file.DefaultSplitMaxOpenFiles} // options for split
+	splitCalendar     string                                                                   // calendar name for split's {session_date}
+	splitHolidaysFile string                                                                   // calendar definitions file for split's {session_date}
+
+	continuousOpts    = dbn_file.ContinuousOptions{InstrumentID: 1} // options for continuous
+	continuousOutFile string                                        // destination file for continuous
+
+	statsTableOpts         dbn_file.StatsTableOptions // options for stats-table
+	statsTableOutFile      string                     // destination file for stats-table
+	statsTableCalendar     string                     // calendar name for stats-table's trading dates
+	statsTableHolidaysFile string                     // calendar definitions file for stats-table's trading dates
+
+	haltsOpts    dbn_file.HaltsTableOptions // options for halts
+	haltsOutFile string                     // destination file for halts
+
+	auctionsOpts    dbn_file.AuctionsOptions // options for auctions
+	auctionsOutFile string                   // destination file for auctions
+
+	nbboOpts    dbn_file.NbboOptions // options for nbbo
+	nbboOutFile string               // destination file for nbbo
+
+	microstructureOpts    dbn_file.MicrostructureOptions // options for microstructure
+	microstructureOutFile string                         // destination file for microstructure
+
+	synthOpts        = dbn_synth.Options{Dataset: dbn.Dataset_XnasItch} // options for synth
+	synthOutFile     string                                             // destination file for synth
+	synthSchema      string                                             // schema name for synth
+	synthStart       string                                             // ISO 8601 start time for synth
+	synthCorruptions []string                                           // corruption names for synth
 )
 
 func requireNoErrorWithoutPrint(err error) {
@@ -36,19 +85,115 @@ func main() {
 	cobra.OnInitialize()
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().Var(&compressOpts.Compression, "compression", "Output compression: none, zstd, gzip, bzip2, xz, or lz4; by the output's suffix if unset")
+	rootCmd.PersistentFlags().IntVar(&compressOpts.Level, "compression-level", 0, "Output compression level; 0 is the codec's default")
+	rootCmd.PersistentFlags().IntVar(&compressOpts.Concurrency, "threads", 0, "Threads for zstd and lz4 output compression; 0 is all cores")
 
 	rootCmd.AddCommand(printMetadataCmd)
-	printMetadataCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Input is zstd (useful for handling zstd on stdin)")
+	printMetadataCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
 
 	rootCmd.AddCommand(writeParquetCmd)
-	writeParquetCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Input is zstd (useful for handling zstd on stdin)")
+	writeParquetCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	writeParquetCmd.Flags().StringVarP(&parquetPartitionBy, "partition-by", "p", "", "Write a Hive-partitioned dataset to --dest, partitioned by these comma-separated keys: date, symbol")
+	writeParquetCmd.Flags().StringVarP(&destDir, "dest", "d", "", "Destination directory, with --partition-by")
+	writeParquetCmd.Flags().Int64Var(&parquetMaxFileMB, "max-file-mb", 0, "Roll to a new part file after about this many MB, with --partition-by; 0 is unlimited")
+	writeParquetCmd.Flags().BoolVar(&parquetPartOpts.Append, "append", false, "Add part files to existing partitions rather than replacing them, with --partition-by")
 
 	rootCmd.AddCommand(splitFilesCmd)
-	splitFilesCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Input is zstd (useful for handling zstd on stdin)")
+	splitFilesCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
 	splitFilesCmd.Flags().StringVarP(&destDir, "dest", "d", "", "Destination directory")
+	splitFilesCmd.Flags().StringVarP(&splitOpts.PathTemplate, "template", "t", dbn_file.DefaultSplitPathTemplate, "Destination path template, relative to --dest; see help for placeholders")
+	splitFilesCmd.Flags().StringVarP(&splitOpts.Format, "format", "f", dbn_file.SplitFormat_Dbn, "Output format: dbn, json, or parquet")
+	splitFilesCmd.Flags().IntVar(&splitOpts.MaxOpenFiles, "max-open", dbn_file.DefaultSplitMaxOpenFiles, "Maximum open files, closing the least recently used; 0 is unlimited")
+	splitFilesCmd.Flags().StringVar(&splitCalendar, "calendar", "", "Trading calendar for {session_date}, e.g. CME; defaults to the source dataset's")
+	splitFilesCmd.Flags().StringVar(&splitHolidaysFile, "holidays", "", "JSON file of calendar holidays and definitions for {session_date}")
 	splitFilesCmd.MarkFlagRequired("dest")
 
 	rootCmd.AddCommand(jsonPrintCmd)
+	jsonPrintCmd.Flags().BoolVarP(&forceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	jsonPrintCmd.Flags().BoolVar(&jsonWriteOpts.PrettyPx, "pretty-px", false, "Write prices as decimal strings, like Databento's pretty_px")
+	jsonPrintCmd.Flags().BoolVar(&jsonWriteOpts.PrettyTs, "pretty-ts", false, "Write timestamps as ISO 8601 strings, like Databento's pretty_ts")
+	jsonPrintCmd.Flags().BoolVarP(&jsonPretty, "pretty", "p", false, "Same as --pretty-px --pretty-ts")
+	jsonPrintCmd.Flags().BoolVarP(&jsonWriteOpts.MapSymbols, "map-symbols", "m", false, "Add each record's symbol from the file's metadata, like Databento's map_symbols")
+	jsonPrintCmd.Flags().BoolVar(&jsonWriteOpts.Sentinels, "sentinels", false, "Write undefined prices and timestamps as sentinel integers rather than null, exactly as Databento does")
+
+	rootCmd.AddCommand(fromJsonCmd)
+	fromJsonCmd.Flags().BoolVarP(&dbnWriteOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	rootCmd.AddCommand(fromParquetCmd)
+	for _, cmd := range []*cobra.Command{fromJsonCmd, fromParquetCmd} {
+		cmd.Flags().StringVarP(&dbnOutFile, "output", "o", "", "Output file, only with a single input; '-' is stdout, a suffix such as '.zst' compresses")
+		cmd.Flags().StringVarP(&dbnWriteOpts.MetadataFile, "metadata", "m", "", "JSON metadata file, as from the 'metadata' command; inferred from records if empty")
+		cmd.Flags().StringVar(&dbnWriteOpts.Dataset, "dataset", "", "Dataset to use in the metadata, overriding any inferred one")
+		cmd.Flags().StringVar(&dbnWriteOpts.Schema, "schema", "", "Schema to use in the metadata, overriding any inferred one (e.g. 'tbbo')")
+	}
+
+	rootCmd.AddCommand(continuousCmd)
+	continuousCmd.Flags().BoolVarP(&continuousOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	continuousCmd.Flags().StringVarP(&continuousOutFile, "output", "o", "", "Output file; '-' is stdout, a suffix such as '.zst' compresses")
+	continuousCmd.Flags().StringVarP(&continuousOpts.Symbol, "symbol", "s", "", "Continuous symbol to build, such as ES.c.0 (calendar), ES.v.0 (volume), or ES.n.0 (open interest)")
+	continuousCmd.Flags().StringVarP(&continuousOpts.Adjustment, "adjust", "a", "none", "Price adjustment before each roll: none, difference, or ratio")
+	continuousCmd.Flags().Uint32Var(&continuousOpts.InstrumentID, "instrument-id", 1, "Synthetic instrument ID of the output records")
+	continuousCmd.Flags().DurationVar(&continuousOpts.BarInterval, "interval", time.Minute, "Interval of bars aggregated from trades: 1s, 1m, 1h, or 24h")
+	continuousCmd.Flags().DurationVar(&continuousOpts.RollBefore, "roll-before", 0, "With the calendar rule, roll this long before each contract's expiration")
+	continuousCmd.Flags().StringVarP(&continuousOpts.ResolutionFile, "resolution", "r", "", "JSON symbology resolution of --symbol, from 'dbn-go-hist resolve --json', to roll by instead of definitions")
+	continuousCmd.Flags().StringVar(&continuousOpts.Dataset, "dataset", "", "Dataset to use in the metadata; the sources' if empty")
+	continuousCmd.MarkFlagRequired("symbol")
+	continuousCmd.MarkFlagRequired("output")
+
+	rootCmd.AddCommand(statsTableCmd)
+	statsTableCmd.Flags().BoolVarP(&statsTableOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	statsTableCmd.Flags().StringVarP(&statsTableOutFile, "output", "o", "", "Output file; '-' is stdout, a suffix such as '.gz' compresses CSV")
+	statsTableCmd.Flags().StringVarP(&statsTableOpts.Format, "format", "f", "", "Output format: csv or parquet; by the output's suffix if unset, else csv")
+	statsTableCmd.Flags().StringVar(&statsTableCalendar, "calendar", "", "Trading calendar for the dates of statistics without ts_ref, e.g. CME; defaults to the source dataset's")
+	statsTableCmd.Flags().StringVar(&statsTableHolidaysFile, "holidays", "", "JSON file of calendar holidays and definitions for trading dates")
+	statsTableCmd.MarkFlagRequired("output")
+
+	rootCmd.AddCommand(haltsCmd)
+	haltsCmd.Flags().BoolVarP(&haltsOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	haltsCmd.Flags().StringVarP(&haltsOutFile, "output", "o", "-", "Output CSV file; '-' is stdout, a suffix such as '.gz' compresses it")
+
+	rootCmd.AddCommand(auctionsCmd)
+	auctionsCmd.Flags().BoolVarP(&auctionsOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	auctionsCmd.Flags().StringVarP(&auctionsOutFile, "output", "o", "", "Output file; '-' is stdout, a suffix such as '.gz' compresses CSV")
+	auctionsCmd.Flags().StringVarP(&auctionsOpts.Format, "format", "f", "", "Output format: csv or parquet; by the output's suffix if unset, else csv")
+	auctionsCmd.MarkFlagRequired("output")
+
+	rootCmd.AddCommand(nbboCmd)
+	nbboCmd.Flags().BoolVarP(&nbboOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	nbboCmd.Flags().StringVarP(&nbboOutFile, "output", "o", "", "Output file; '-' is stdout, a suffix such as '.zst' compresses")
+	nbboCmd.Flags().StringVar(&nbboOpts.Dataset, "dataset", "", "Dataset to use in the metadata; the first source's if empty")
+	nbboCmd.MarkFlagRequired("output")
+
+	rootCmd.AddCommand(microstructureCmd)
+	microstructureCmd.Flags().BoolVarP(&microstructureOpts.ForceZstdInput, "zstd", "z", false, "Force input to be zstd; otherwise its compression is detected")
+	microstructureCmd.Flags().StringVarP(&microstructureOutFile, "output", "o", "-", "Output CSV file of intervals; '-' is stdout, a suffix such as '.gz' compresses it")
+	microstructureCmd.Flags().StringVar(&microstructureOpts.TradesFile, "trades", "", "Output CSV file of trades, if set; a suffix such as '.gz' compresses it")
+	microstructureCmd.Flags().DurationSliceVar(&microstructureOpts.Horizons, "horizon", []time.Duration{time.Second, 5 * time.Second}, "Horizons of the trades' realized spreads and price impacts")
+	microstructureCmd.Flags().DurationVar(&microstructureOpts.Interval, "interval", time.Minute, "Length of each interval")
+
+	rootCmd.AddCommand(synthCmd)
+	synthCmd.Flags().StringVarP(&synthOutFile, "output", "o", "", "Output file; '-' is stdout, a suffix such as '.zst' compresses")
+	synthCmd.Flags().StringVarP(&synthSchema, "schema", "s", "mbo", "Schema of the records, such as 'mbo', 'mbp-10', or 'ohlcv-1m'")
+	synthCmd.Flags().Uint8Var(&synthOpts.Version, "dbn-version", dbn.HeaderVersion3, "DBN version: 1, 2, or 3")
+	synthCmd.Flags().Var(&synthOpts.Dataset, "dataset", "Dataset of the metadata, whose first publisher is the records'")
+	synthCmd.Flags().Uint64Var(&synthOpts.Seed, "seed", 0, "Seed of the random walks and events; the same flags generate the same file")
+	synthCmd.Flags().StringVar(&synthStart, "start", dbn_synth.DefaultStart.Format(time.RFC3339), "ISO 8601 time of the first records")
+	synthCmd.Flags().DurationVarP(&synthOpts.Duration, "duration", "d", time.Minute, "Length of simulated time")
+	synthCmd.Flags().IntVarP(&synthOpts.Count, "count", "n", 0, "Maximum number of records; 0 is unlimited")
+	synthCmd.Flags().IntVarP(&synthOpts.NumInstruments, "instruments", "i", 0, "Number of instruments, named SYN1, SYN2, and so on; 1 if no --symbols")
+	synthCmd.Flags().StringSliceVar(&synthOpts.Symbols, "symbols", nil, "Comma-separated raw symbols of the instruments")
+	synthCmd.Flags().Uint32Var(&synthOpts.FirstInstrumentID, "first-instrument-id", 1, "Instrument ID of the first instrument, incrementing for the others")
+	synthCmd.Flags().BoolVar(&synthOpts.SymbolMappings, "symbol-mappings", false, "Start with a symbol mapping record per instrument, as live streams do")
+	synthCmd.Flags().Float64Var(&synthOpts.Rate, "rate", 100, "Mean MBO events per second per instrument")
+	synthCmd.Flags().Float64Var(&synthOpts.Price, "price", 100, "Starting price of each instrument")
+	synthCmd.Flags().Float64Var(&synthOpts.TickSize, "tick-size", 0.01, "Minimum price increment")
+	synthCmd.Flags().Float64Var(&synthOpts.Volatility, "volatility", 0.3, "Standard deviation of the random walk per event, in ticks")
+	synthCmd.Flags().IntVar(&synthOpts.Depth, "depth", 10, "Price levels on each side of the starting book")
+	synthCmd.Flags().Uint32Var(&synthOpts.LotSize, "lot-size", 100, "Order sizes are multiples of this")
+	synthCmd.Flags().IntVar(&synthOpts.MaxLots, "max-lots", 10, "Orders are of 1 to this many lots")
+	synthCmd.Flags().Float64Var(&synthOpts.CorruptionRate, "corrupt-rate", 0, "Probability that each record is corrupted, from 0 to 1")
+	synthCmd.Flags().StringSliceVar(&synthCorruptions, "corrupt", nil, "Comma-separated kinds of corruption: truncate, length, rtype, bytes, timestamp; all if empty")
+	synthCmd.MarkFlagRequired("output")
 
 	docsCmd.AddCommand(docsMarkdownCmd)
 	docsCmd.AddCommand(docsManCmd)
@@ -114,13 +259,26 @@ func printMetadata(sourceFile string, forceZstd bool) error {
 var jsonPrintCmd = &cobra.Command{
 	Use:   "json file...",
 	Short: `Prints the specified files' records as JSON`,
-	Long:  `Prints the specified files' records as JSON`,
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Prints the specified files' records as JSON lines, in Databento's JSON encoding.
+
+64-bit integers, prices, and timestamps are quoted strings, and undefined prices and
+timestamps are null.  The --pretty-px, --pretty-ts, and --map-symbols flags select Databento's
+variants, as with a historical request, and --sentinels writes undefined values as Databento's
+sentinel integers.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Run the split on the files
+		if jsonPretty {
+			jsonWriteOpts.PrettyPx, jsonWriteOpts.PrettyTs = true, true
+		}
+		writer, err := dbn.NewCompressedWriter(os.Stdout, compressOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+			os.Exit(1)
+		}
+		defer writer.Close()
 		for _, sourceFile := range args {
-			if err := dbn_file.WriteDbnFileAsJson(sourceFile, forceZstdInput, os.Stdout); err != nil {
-				fmt.Fprintf(os.Stderr, "error: splitting %s: %s\n", sourceFile, err.Error())
+			if err := dbn_file.WriteDbnFileAsJsonWithOptions(sourceFile, forceZstdInput, jsonWriteOpts, writer); err != nil {
+				fmt.Fprintf(os.Stderr, "error: printing %s: %s\n", sourceFile, err.Error())
 			}
 		}
 	},
@@ -131,19 +289,23 @@ var jsonPrintCmd = &cobra.Command{
 var writeParquetCmd = &cobra.Command{
 	Use:   "parquet file...",
 	Short: `Writes the specified files' records as parquet`,
-	Long:  `Writes the specified files' records as parquet`,
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Writes the specified files' records as parquet.
+With --partition-by, writes a Hive-partitioned dataset to --dest, for example:
+  <dest>/schema=trades/date=2024-01-02/symbol=AAPL/part-0.parquet`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if parquetPartitionBy != "" {
+			writePartitionedParquet(args)
+			return
+		}
+
 		// Convert all the files to Parquet
 		for _, sourceFile := range args {
-			var destFile string
-			if strings.HasSuffix(sourceFile, ".zst") {
-				destFile = sourceFile[:len(sourceFile)-4] + ".parquet"
-			} else if strings.HasSuffix(sourceFile, ".zstd") {
-				destFile = sourceFile[:len(sourceFile)-5] + ".parquet"
-			} else {
-				destFile = sourceFile + ".parquet"
+			base := localSourceName(sourceFile)
+			if compression := dbn.CompressionFromFilename(base); compression != dbn.Compress_None {
+				base = strings.TrimSuffix(strings.TrimSuffix(base, compression.FileSuffix()), ".zstd")
 			}
+			destFile := base + ".parquet"
 
 			if verbose {
 				fmt.Fprintf(os.Stderr, "Converting %s to %s\n", sourceFile, destFile)
@@ -155,6 +317,365 @@ var writeParquetCmd = &cobra.Command{
 	},
 }
 
+// writePartitionedParquet writes all the files into a Hive-partitioned dataset under destDir.
+func writePartitionedParquet(sourceFiles []string) {
+	partitionBy, err := dbn_file.ParseParquetPartitionKeys(parquetPartitionBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --partition-by: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if destDir == "" {
+		fmt.Fprintf(os.Stderr, "error: --partition-by requires --dest.  Use '.' for current directory.\n")
+		os.Exit(1)
+	}
+	parquetPartOpts.PartitionBy = partitionBy
+	parquetPartOpts.MaxFileBytes = parquetMaxFileMB * 1024 * 1024
+	parquetPartOpts.Verbose = verbose
+
+	partitionWriter := dbn_file.NewParquetPartitionWriter(destDir, parquetPartOpts)
+	for _, sourceFile := range sourceFiles {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Converting %s to %s\n", sourceFile, destDir)
+		}
+		if err := partitionWriter.WriteDbnFile(sourceFile, forceZstdInput); err != nil {
+			fmt.Fprintf(os.Stderr, "error: parquet converting %s: %s\n", sourceFile, err.Error())
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var fromJsonCmd = &cobra.Command{
+	Use:   "from-json file...",
+	Short: `Writes the specified JSON files' records as DBN`,
+	Long: `Writes the specified JSON files' records as DBN.
+Each line of input is a DBN JSON record, as from the 'json' command or Databento.
+If no --metadata is given, it is inferred from the records and any "symbol" fields.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDbnConversion(args, []string{".json", ".jsonl"}, "json", dbn_file.WriteJsonFileAsDbn)
+	},
+}
+
+var fromParquetCmd = &cobra.Command{
+	Use:   "from-parquet file...",
+	Short: `Writes the specified parquet files' records as DBN`,
+	Long: `Writes the specified parquet files' records as DBN.
+The parquet must have the layout written by the 'parquet' command.
+If no --metadata is given, it is inferred from the records and the "symbol" column.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDbnConversion(args, []string{".parquet"}, "parquet", dbn_file.WriteParquetFileAsDbn)
+	},
+}
+
+// runDbnConversion converts each source file to DBN with `convert`.
+// The destination is --output, or else the source file with its `suffixes` replaced by ".dbn".
+func runDbnConversion(sourceFiles []string, suffixes []string, format string, convert func(string, dbn_file.DbnWriteOptions, io.Writer) error) {
+	if dbnOutFile != "" && len(sourceFiles) != 1 {
+		fmt.Fprintf(os.Stderr, "error: --output requires a single input file\n")
+		os.Exit(1)
+	}
+	for _, sourceFile := range sourceFiles {
+		destFile := dbnOutFile
+		if destFile == "" {
+			base := localSourceName(sourceFile)
+			if compression := dbn.CompressionFromFilename(base); compression != dbn.Compress_None {
+				base = strings.TrimSuffix(strings.TrimSuffix(base, compression.FileSuffix()), ".zstd")
+			}
+			for _, suffix := range suffixes {
+				base = strings.TrimSuffix(base, suffix)
+			}
+			destFile = strings.TrimSuffix(base, ".dbn") + ".dbn" + compressOpts.Compression.FileSuffix()
+		}
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Converting %s to %s\n", sourceFile, destFile)
+		}
+		if err := writeDbnFile(sourceFile, destFile, convert); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s converting %s: %s\n", format, sourceFile, err.Error())
+		}
+	}
+}
+
+func writeDbnFile(sourceFile string, destFile string, convert func(string, dbn_file.DbnWriteOptions, io.Writer) error) error {
+	writer, err := dbn.CreateCompressedWriter(destFile, outputCompression(destFile))
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	if err := convert(sourceFile, dbnWriteOpts, writer); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// localSourceName returns the filename of a source for naming outputs: the filename of a URL's path,
+// written in the current directory, or else the source itself.
+func localSourceName(sourceFile string) string {
+	if dbn.SourceScheme(sourceFile) == "" {
+		return sourceFile
+	}
+	if sourceURL, err := url.Parse(sourceFile); err == nil {
+		return path.Base(sourceURL.Path)
+	}
+	return path.Base(sourceFile)
+}
+
+// outputCompression returns the --compression options for an output file, with the codec of its suffix if unset.
+func outputCompression(destFile string) dbn.CompressionOptions {
+	opts := compressOpts
+	if opts.Compression == dbn.Compress_None {
+		opts.Compression = dbn.CompressionFromFilename(destFile)
+	}
+	return opts
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var continuousCmd = &cobra.Command{
+	Use:   "continuous file...",
+	Short: `Builds a back-adjusted continuous futures series from OHLCV or trades across contract months`,
+	Long: `Builds a back-adjusted continuous futures series from OHLCV or trades across contract months.
+The files hold the bars or trades of the product's contracts, along with their definitions
+for the calendar rule, or their statistics for the open interest rule.  The rule and rank are
+those of --symbol, such as ES.c.0 for the front contract by expiration:
+  c: roll at each contract's expiration, less --roll-before, or per --resolution
+  v: hold the contract with the highest volume on the prior day
+  n: hold the contract with the highest open interest known at the start of the day
+Prices before each roll are adjusted per --adjust, leaving the latest contract's as they are.
+The output is DBN OHLCV with the synthetic --instrument-id, mapped from --symbol in its metadata.
+For example:
+  dbn-go-file continuous -s ES.c.0 -a difference -o es.c.0.dbn.zst es-ohlcv-1d.dbn.zst es-definition.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		continuousOpts.Verbose = verbose
+		if err := writeContinuousFile(args, continuousOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: continuous: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func writeContinuousFile(sourceFiles []string, destFile string) error {
+	writer, err := dbn.CreateCompressedWriter(destFile, outputCompression(destFile))
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	if err := dbn_file.WriteContinuousDbn(sourceFiles, continuousOpts, writer); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var statsTableCmd = &cobra.Command{
+	Use:   "stats-table file...",
+	Short: `Writes statistics files as a table of each instrument's statistics per trading date`,
+	Long: `Writes statistics files as a table of each instrument's statistics per trading date, as CSV or Parquet.
+Statistics records interleave many stat types; they are folded into a row per instrument and
+trading date, with date, instrument_id, and symbol columns and then a column per stat type present,
+such as settlement_price, open_interest, and trading_session_high_price.  The latest statistic
+of each type is kept, and deleted ones are removed.  Statistics with a ts_ref, such as settlements
+and open interest, are of its date; others are of the trading date of their ts_recv, per --calendar.
+For example:
+  dbn-go-file stats-table -o glbx-stats.csv glbx-mdp3.statistics.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		statsTableOpts.Calendars = dbn_calendar.NewRegistry()
+		if statsTableHolidaysFile != "" {
+			if err := statsTableOpts.Calendars.LoadHolidaysFile(statsTableHolidaysFile); err != nil {
+				fmt.Fprintf(os.Stderr, "error: --holidays: %s\n", err.Error())
+				os.Exit(1)
+			}
+		}
+		if statsTableCalendar != "" {
+			if statsTableOpts.Calendar = statsTableOpts.Calendars.Get(statsTableCalendar); statsTableOpts.Calendar == nil {
+				fmt.Fprintf(os.Stderr, "error: unknown --calendar '%s', expected one of: %s\n",
+					statsTableCalendar, strings.Join(statsTableOpts.Calendars.Names(), ", "))
+				os.Exit(1)
+			}
+		}
+		if err := dbn_file.WriteStatsTable(args, statsTableOpts, statsTableOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: stats-table: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var haltsCmd = &cobra.Command{
+	Use:   "halts file...",
+	Short: `Writes the intervals instruments were halted in status files as CSV`,
+	Long: `Writes the intervals instruments were halted, paused, or suspended in status files as CSV.
+The trading state of each instrument is tracked through its status records, such as LULD pauses
+and news halts, and each halt is a row of its start, end, duration in seconds, instrument_id,
+symbol, and the action and reason which began it.  Halts which had not ended have no end.
+For example:
+  dbn-go-file halts -o xnas-halts.csv xnas-itch.status.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dbn_file.WriteHaltsTable(args, haltsOpts, haltsOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: halts: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var auctionsCmd = &cobra.Command{
+	Use:   "auctions file...",
+	Short: `Writes a summary of each auction in imbalance and trades files`,
+	Long: `Writes a summary of each auction in imbalance and trades files, as CSV or Parquet.
+Imbalance records are grouped into auctions per instrument: an opening and a closing auction
+per date, and halt and other auctions which end at a trade.  Each auction is a row of its
+date, instrument_id, symbol, auction_type, and kind, its first and last imbalance and their
+count, the last imbalance's reference, indicative, and auction prices, paired and imbalance
+quantities, and side, its largest paired and imbalance quantities, and its uncross trade:
+the first trade after its last imbalance, or at its auction_time, from the trades, tbbo, or
+mbp-1 files given.
+For example:
+  dbn-go-file auctions -o xnas-auctions.csv xnas-itch.imbalance.dbn.zst xnas-itch.trades.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dbn_file.WriteAuctions(args, auctionsOpts, auctionsOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: auctions: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var nbboCmd = &cobra.Command{
+	Use:   "nbbo file...",
+	Short: `Consolidates per-venue MBP-1 or BBO files into the best bid and offer across venues as CMBP-1`,
+	Long: `Consolidates per-venue MBP-1 or BBO files into the best bid and offer across venues as CMBP-1.
+The files are merged in ts_recv order and each venue's top of book is kept per symbol, per the
+symbol mappings of its dataset's files; the output numbers the symbols from 1, in order.  Files
+without symbol mappings must share instrument IDs.  Each source record becomes a CMBP-1 record
+with the consolidated bid and offer after it and the publishers of each side, and the
+publisher-specific flag set if the market is locked or crossed.  With --verbose, the number
+of updates which left the market locked or crossed is printed.
+For example:
+  dbn-go-file nbbo -o acme.cmbp-1.dbn.zst xnas-itch.mbp-1.dbn.zst arcx-pillar.mbp-1.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nbboOpts.Verbose = verbose
+		if err := writeNbboFile(args, nbboOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: nbbo: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func writeNbboFile(sourceFiles []string, destFile string) error {
+	writer, err := dbn.CreateCompressedWriter(destFile, outputCompression(destFile))
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	if err := dbn_file.WriteNbboDbn(sourceFiles, nbboOpts, writer); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var microstructureCmd = &cobra.Command{
+	Use:   "microstructure file...",
+	Short: `Writes trade classification, spreads, and order flow metrics of TBBO or TCBBO files as CSV`,
+	Long: `Writes trade classification, spreads, and order flow metrics of TBBO or TCBBO files as CSV.
+Each trade is classified by the tick rule and the Lee-Ready algorithm against the best bid and
+offer before it, as in the TBBO and TCBBO schemas; MBP-1 and CMBP-1 files also update the quote
+between trades.  Each instrument and --interval is a row of its start, instrument_id, symbol,
+trade count, volume, buy and sell volume, trade imbalance, order-flow imbalance, VWAP, TWAP,
+and volume-weighted effective spread in basis points.  With --trades, each trade is a row there
+of its quote, classifications, effective spread, and realized spread and price impact at each
+--horizon.  Files of the same instruments must be given in time order.
+For example:
+  dbn-go-file microstructure --interval 5m --trades acme-trades.csv -o acme-5m.csv xnas-itch.tbbo.dbn.zst
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dbn_file.WriteMicrostructure(args, microstructureOpts, microstructureOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: microstructure: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+var synthCmd = &cobra.Command{
+	Use:   "synth",
+	Short: `Generates a synthetic DBN file of any schema and version, optionally corrupted`,
+	Long: `Generates a synthetic DBN file of any schema and version, optionally corrupted.
+Each instrument's market is simulated as MBO events at --rate on an order book around a
+random-walk price, and the other schemas are derived from it, such as MBP-10 from the book's
+levels and OHLCV from its trades.  The same flags, including --seed, generate the same file.
+With --corrupt-rate, records are corrupted as they are written, by the kinds of --corrupt:
+truncate ends the file within a record, length and rtype change the record's header, bytes
+flips bits of its body, and timestamp moves its ts_event backwards.  With --verbose, the
+number of records and of those corrupted are printed.
+For example:
+  dbn-go-file synth -s mbp-10 -i 5 -d 1h --seed 7 -o synth.mbp-10.dbn.zst
+  dbn-go-file synth -s trades --corrupt-rate 0.01 --corrupt length,rtype -o corrupt.trades.dbn
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := writeSynthFile(synthOutFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: synth: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func writeSynthFile(destFile string) error {
+	schema, err := dbn.SchemaFromString(synthSchema)
+	if err != nil {
+		return err
+	}
+	synthOpts.Schema = schema
+	if synthOpts.Start, err = iso8601.ParseString(synthStart); err != nil {
+		return fmt.Errorf("failed to parse start %w", err)
+	}
+	for _, name := range synthCorruptions {
+		corruption, err := dbn_synth.CorruptionFromString(name)
+		if err != nil {
+			return err
+		}
+		synthOpts.Corruptions = append(synthOpts.Corruptions, corruption)
+	}
+	generator, err := dbn_synth.NewGenerator(synthOpts)
+	if err != nil {
+		return err
+	}
+
+	writer, err := dbn.CreateCompressedWriter(destFile, outputCompression(destFile))
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	if err := generator.Write(writer); err != nil {
+		writer.Close()
+		return err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "records: %d corrupted: %d\n", generator.NumRecords(), generator.NumCorrupted())
+	}
+	return writer.Close()
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 var splitFilesCmd = &cobra.Command{
@@ -162,6 +683,13 @@ var splitFilesCmd = &cobra.Command{
 	Short: `Splits Databento download folders into "<feed>/<instrument_id>/Y/M/D/feed-YMD.type.dbn.zst"`,
 	Long: `Splits Databento download folders into "<feed>/<instrument_id>/Y/M/D/feed-YMD.type.dbn.zst"
 For example, underlying symbols for front month for a given date
+
+The layout is set with --template, whose placeholders are the split keys:
+  {dataset} {schema} {ext} {symbol} {instrument_id} {publisher_id}
+  {yyyy} {mm} {dd} {ymd} {hh} (UTC of ts_event)
+  {session_date} (exchange trading day YYYYMMDD, per --calendar)
+For example, to split MBO by instrument and hour into JSON:
+  dbn-go-file split -d out -f json -t '{instrument_id}/{ymd}/{hh}.{ext}' mbo.dbn.zst
 `,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -175,9 +703,26 @@ For example, underlying symbols for front month for a given date
 			os.Exit(1)
 		}
 
+		splitOpts.Calendars = dbn_calendar.NewRegistry()
+		if splitHolidaysFile != "" {
+			if err := splitOpts.Calendars.LoadHolidaysFile(splitHolidaysFile); err != nil {
+				fmt.Fprintf(os.Stderr, "error: --holidays: %s\n", err.Error())
+				os.Exit(1)
+			}
+		}
+		if splitCalendar != "" {
+			if splitOpts.Calendar = splitOpts.Calendars.Get(splitCalendar); splitOpts.Calendar == nil {
+				fmt.Fprintf(os.Stderr, "error: unknown --calendar '%s', expected one of: %s\n",
+					splitCalendar, strings.Join(splitOpts.Calendars.Names(), ", "))
+				os.Exit(1)
+			}
+		}
+		splitOpts.Verbose = verbose
+		splitOpts.Compression = compressOpts
+
 		// Run the split of the files
 		for _, sourceFile := range args {
-			if err := dbn_file.SplitFile(sourceFile, destDir, false, verbose); err != nil {
+			if err := dbn_file.SplitFileWithOptions(sourceFile, destDir, forceZstdInput, splitOpts); err != nil {
 				fmt.Fprintf(os.Stderr, "error: splitting %s: %s\n", sourceFile, err.Error())
 			}
 		}
@@ -45,8 +45,10 @@ var (
 	outputFile string
 	emitJSON   bool // emit json from responses
 
-	encoding    dbn.Encoding    = dbn.Encoding_Dbn
-	compression dbn.Compression = dbn.Compress_ZStd
+	encoding         dbn.Encoding    = dbn.Encoding_Dbn
+	compression      dbn.Compression = dbn.Compress_ZStd
+	compressionLevel int             // local compression level for get-range
+	compressThreads  int             // local compression threads for get-range
 
 	maxActiveDownloads int = defaultMaxActiveDownloads
 
@@ -275,7 +277,7 @@ func main() {
 	submitJobCmd.Flags().StringVarP(&dataset, "dataset", "d", "", "Dataset to request")
 	submitJobCmd.Flags().StringVarP(&schemaStr, "schema", "s", "", "Schema to request")
 	submitJobCmd.Flags().VarP(&encoding, "encoding", "", "Encoding to use ('dbn', 'csv', 'json')")
-	submitJobCmd.Flags().VarP(&compression, "compression", "", "Compression to use ('none', 'zstd')")
+	submitJobCmd.Flags().VarP(&compression, "compression", "", "Compression to use ('none', 'zstd'); batch jobs support no others")
 	submitJobCmd.Flags().StringVarP(&symbolsFile, "file", "f", "", "Newline delimited file to read symbols from (# is comment)")
 	submitJobCmd.Flags().BoolVarP(&allSymbols, "all", "", false, "Request data for all symbols")
 	submitJobCmd.Flags().BoolVarP(&useForce, "force", "", false, "Do not warn about all symbols or cost")
@@ -291,7 +293,9 @@ func main() {
 	getRangeCmd.Flags().StringVarP(&dataset, "dataset", "d", "", "Dataset to request")
 	getRangeCmd.Flags().StringVarP(&schemaStr, "schema", "s", "", "Schema to request")
 	getRangeCmd.Flags().VarP(&encoding, "encoding", "", "Encoding to use ('dbn', 'csv', 'json')")
-	getRangeCmd.Flags().VarP(&compression, "compression", "", "Compression to use ('none', 'zstd')")
+	getRangeCmd.Flags().VarP(&compression, "compression", "", "Compression to use ('none', 'zstd', 'gzip', 'bzip2', 'xz', 'lz4'); all but 'none' and 'zstd' are compressed locally")
+	getRangeCmd.Flags().IntVar(&compressionLevel, "compression-level", 0, "Compression level, compressing locally; 0 is the codec's default")
+	getRangeCmd.Flags().IntVar(&compressThreads, "threads", 0, "Threads for local zstd and lz4 compression; 0 is all cores")
 	getRangeCmd.Flags().StringVarP(&symbolsFile, "file", "f", "", "Newline delimited file to read symbols from (# is comment)")
 	getRangeCmd.Flags().BoolVarP(&allSymbols, "all", "", false, "Request data for all symbols")
 	getRangeCmd.Flags().BoolVarP(&useForce, "force", "", false, "Do not warn about all symbols or cost")
@@ -575,6 +579,10 @@ var submitJobCmd = &cobra.Command{
 	Short:   "Submit a data request job to the Hist API",
 	Args:    cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		if !compression.IsApiCompression() {
+			fmt.Fprintf(os.Stderr, "batch jobs only support 'none' and 'zstd' compression, not '%s'\n", compression)
+			os.Exit(1)
+		}
 		apiKey := requireDatabentoApiKey()
 		symbols := requireSymbolArgs(args)
 		jobParams := getSubmitJobParams(symbols)
@@ -605,7 +613,17 @@ var getRangeCmd = &cobra.Command{
 				closer.Close()
 			}
 		}
-		if outputFile != "-" {
+		// The API compresses with zstd or not at all; other codecs and levels are applied locally
+		localCompression := !compression.IsApiCompression() || compressionLevel != 0
+		if localCompression {
+			compressedWriter, err := dbn.CreateCompressedWriter(outputFile, dbn.CompressionOptions{
+				Compression: compression,
+				Level:       compressionLevel,
+				Concurrency: compressThreads,
+			})
+			requireNoErrorMsg(err, "error creating output file")
+			writer, closer = compressedWriter, compressedWriter
+		} else if outputFile != "-" {
 			file, err := os.Create(outputFile)
 			requireNoErrorMsg(err, "error creating output file")
 			writer, closer = file, file
@@ -618,6 +636,9 @@ var getRangeCmd = &cobra.Command{
 		apiKey := requireDatabentoApiKey()
 		symbols := requireSymbolArgs(args)
 		jobParams := getSubmitJobParams(symbols)
+		if localCompression {
+			jobParams.Compression = dbn.Compress_None
+		}
 
 		requireBudgetApproval(apiKey, symbols, &jobParams)
 
@@ -6,9 +6,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	dbn "github.com/NimbleMarkets/dbn-go"
@@ -30,6 +34,8 @@ type Config struct {
 	Symbols     []string
 	StartTime   time.Time
 	Snapshot    bool
+	TsOut       bool
+	Latency     time.Duration
 	Verbose     bool
 }
 
@@ -52,6 +58,8 @@ func main() {
 	pflag.VarP(&config.Encoding, "encoding", "e", "Encoding of the output ('dbn', 'csv', 'json')")
 	pflag.StringVarP(&startTimeArg, "start", "t", "", "Start time to request as ISO 8601 format (default: now)")
 	pflag.BoolVarP(&config.Snapshot, "snapshot", "n", false, "Enable snapshot on subscription request")
+	pflag.BoolVar(&config.TsOut, "ts-out", false, "Request each record's gateway send timestamp (ts_out)")
+	pflag.DurationVar(&config.Latency, "latency", 0, "Report latency percentiles to stderr at this interval, and at exit; implies --ts-out (DBN encoding only)")
 	pflag.BoolVarP(&config.Verbose, "verbose", "v", false, "Verbose logging")
 	pflag.BoolVarP(&showHelp, "help", "h", false, "Show help")
 	pflag.BoolVar(&showVersion, "version", false, "Show version")
@@ -93,6 +101,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	if config.Latency < 0 {
+		fmt.Fprintf(os.Stderr, "--latency must be positive\n")
+		os.Exit(1)
+	}
+	if config.Latency != 0 {
+		if config.Encoding != dbn.Encoding_Dbn {
+			fmt.Fprintf(os.Stderr, "--latency requires DBN encoding\n")
+			os.Exit(1)
+		}
+		config.TsOut = true
+	}
+
 	requireValOrExit(config.Dataset, "missing required --dataset")
 	requireValOrExit(config.OutFilename, "missing required --out")
 
@@ -125,7 +145,7 @@ func run(config Config) error {
 		ApiKey:               config.ApiKey,
 		Dataset:              config.Dataset,
 		Encoding:             config.Encoding,
-		SendTsOut:            false,
+		SendTsOut:            config.TsOut,
 		VersionUpgradePolicy: dbn.VersionUpgradePolicy_AsIs,
 		Verbose:              config.Verbose,
 	})
@@ -159,13 +179,26 @@ func run(config Config) error {
 	}
 
 	if config.Encoding == dbn.Encoding_Dbn {
-		return followStreamDBN(client, outWriter)
+		if config.Latency != 0 {
+			// Stop on interrupt, so the final latency report is written
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(sigCh)
+			go func() {
+				if _, ok := <-sigCh; ok {
+					client.Stop()
+				}
+			}()
+		}
+		return followStreamDBN(client, outWriter, config.Latency)
 	} else {
 		return followStreamJSON(client, outWriter)
 	}
 }
 
-func followStreamDBN(client *dbn_live.LiveClient, outWriter io.Writer) error {
+// followStreamDBN writes the client's DBN stream to outWriter.
+// If `latencyInterval` is non-zero, latency percentiles are reported to stderr at that interval and at the end.
+func followStreamDBN(client *dbn_live.LiveClient, outWriter io.Writer, latencyInterval time.Duration) error {
 	// Write metadata to file
 	dbnScanner := client.GetDbnScanner()
 	if dbnScanner == nil {
@@ -179,8 +212,28 @@ func followStreamDBN(client *dbn_live.LiveClient, outWriter io.Writer) error {
 		return fmt.Errorf("failed to write metadata from LiveClient: %w", err)
 	}
 
+	var latencyStats *dbn_live.LatencyStats
+	var lastReport time.Time
+	if latencyInterval != 0 {
+		if !dbnScanner.HasTsOut() {
+			return fmt.Errorf("the stream has no ts_out for measuring latency")
+		}
+		latencyStats = dbn_live.NewLatencyStats()
+		lastReport = time.Now()
+		defer func() { reportLatency(latencyStats) }()
+	}
+
 	// Follow the DBN stream, writing DBN messages to the file
 	for dbnScanner.Next() {
+		if latencyStats != nil {
+			now := time.Now()
+			latencyStats.ObserveScanner(dbnScanner, now)
+			if now.Sub(lastReport) >= latencyInterval {
+				reportLatency(latencyStats)
+				latencyStats.Reset()
+				lastReport = now
+			}
+		}
 		recordBytes := dbnScanner.GetLastRecord()[:dbnScanner.GetLastSize()]
 		_, err := outWriter.Write(recordBytes)
 		if err != nil {
@@ -189,12 +242,24 @@ func followStreamDBN(client *dbn_live.LiveClient, outWriter io.Writer) error {
 		}
 	}
 	if err := dbnScanner.Error(); err != nil && err != io.EOF {
+		if latencyStats != nil && errors.Is(err, net.ErrClosed) {
+			return nil // stopped by interrupt
+		}
 		fmt.Fprintf(os.Stderr, "scanner err: %s\n", err.Error())
 		return err
 	}
 	return nil
 }
 
+// reportLatency writes the latency percentiles to stderr, if there are any samples.
+func reportLatency(latencyStats *dbn_live.LatencyStats) {
+	if latencyStats.Count() == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "latency exchange->gateway (ts_out - ts_recv): %s\n", latencyStats.ExchangeToGateway())
+	fmt.Fprintf(os.Stderr, "latency gateway->client (local - ts_out):     %s\n", latencyStats.GatewayToClient())
+}
+
 func followStreamJSON(client *dbn_live.LiveClient, outWriter io.Writer) error {
 	// Get the JSON scanner
 	jsonScanner := client.GetJsonScanner()
@@ -1,7 +1,7 @@
 // Copyright (c) 2025 Neomantra Corp
 // Reader/Writer Compression helpers
 //
-// Adapted from Neomantra's Gist, but simplified to only support zstd.:
+// Adapted from Neomantra's Gist:
 //
 // https://gist.github.com/neomantra/691a6028cdf2ac3fc6ec97d00e8ea802
 //
@@ -9,80 +9,328 @@
 package dbn
 
 import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 
+	dsnet_bzip2 "github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
 
-// Returns an io.Writer for the given filename, or os.Stdout if filename is "-".  Also returns a closing function to defer and any error.
-// If the filename ends in ".zst" or ".zstd", or if useZstd is true, the writer will zstd-compress the output.
-func MakeCompressedWriter(filename string, useZstd bool) (io.Writer, func(), error) {
-	var writer io.Writer
-	var closer io.Closer
-	fileCloser := func() {
-		if closer != nil {
-			closer.Close()
-		}
+// Magic bytes at the start of each compressed stream.
+var (
+	magicZstd  = []byte{0x28, 0xB5, 0x2F, 0xFD}
+	magicGzip  = []byte{0x1F, 0x8B}
+	magicBzip2 = []byte{'B', 'Z', 'h'}
+	magicXz    = []byte{0xFD, '7', 'z', 'X', 'Z', 0x00}
+	magicLz4   = []byte{0x04, 0x22, 0x4D, 0x18}
+)
+
+// compressionMagicLen is the number of bytes needed to detect any compression.
+const compressionMagicLen = 6
+
+// DetectCompression returns the Compression of a stream from its first bytes, or Compress_None if
+// they do not start a zstd, gzip, bzip2, xz, or lz4 stream.  At least 6 bytes should be passed.
+func DetectCompression(header []byte) Compression {
+	switch {
+	case bytes.HasPrefix(header, magicZstd):
+		return Compress_ZStd
+	case bytes.HasPrefix(header, magicGzip):
+		return Compress_Gzip
+	case bytes.HasPrefix(header, magicBzip2):
+		return Compress_Bzip2
+	case bytes.HasPrefix(header, magicXz):
+		return Compress_Xz
+	case bytes.HasPrefix(header, magicLz4):
+		return Compress_Lz4
+	default:
+		return Compress_None
 	}
-	if filename != "-" {
-		if file, err := os.Create(filename); err == nil {
-			writer, closer = file, file
-		} else {
-			return nil, nil, err
-		}
-	} else {
-		writer, closer = os.Stdout, nil
+}
+
+// CompressionFromFilename returns the Compression of a filename's suffix, such as ".zst" or ".gz",
+// or Compress_None if it has none.
+func CompressionFromFilename(filename string) Compression {
+	switch {
+	case strings.HasSuffix(filename, ".zst"), strings.HasSuffix(filename, ".zstd"):
+		return Compress_ZStd
+	case strings.HasSuffix(filename, ".gz"):
+		return Compress_Gzip
+	case strings.HasSuffix(filename, ".bz2"):
+		return Compress_Bzip2
+	case strings.HasSuffix(filename, ".xz"):
+		return Compress_Xz
+	case strings.HasSuffix(filename, ".lz4"):
+		return Compress_Lz4
+	default:
+		return Compress_None
 	}
+}
 
-	if useZstd || strings.HasSuffix(filename, ".zst") || strings.HasSuffix(filename, ".zstd") {
-		zstdWriter, err := zstd.NewWriter(writer)
-		if err != nil {
-			fileCloser()
-			return nil, nil, err
+// FileSuffix returns the filename suffix of the Compression, such as ".zst" or ".gz", or "" for none.
+func (c Compression) FileSuffix() string {
+	switch c {
+	case Compress_ZStd:
+		return ".zst"
+	case Compress_Gzip:
+		return ".gz"
+	case Compress_Bzip2:
+		return ".bz2"
+	case Compress_Xz:
+		return ".xz"
+	case Compress_Lz4:
+		return ".lz4"
+	default:
+		return ""
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// CompressionOptions are the options of a compressed writer.
+type CompressionOptions struct {
+	// Compression is the codec to write.
+	Compression Compression
+	// Level is the codec's compression level, such as 1-22 for zstd or 1-9 for the others.
+	// Zero uses the codec's default level.
+	Level int
+	// Concurrency is the number of threads used by zstd and lz4.
+	// Zero uses all of GOMAXPROCS, 1 encodes in the calling goroutine.
+	Concurrency int
+}
+
+// NewCompressedWriter returns an io.WriteCloser compressing to w with the options' codec.
+// Closing it flushes the compressed stream, but does not close w.
+func NewCompressedWriter(w io.Writer, opts CompressionOptions) (io.WriteCloser, error) {
+	switch opts.Compression {
+	case Compress_None:
+		return nopWriteCloser{w}, nil
+	case Compress_ZStd:
+		zstdOpts := []zstd.EOption{}
+		if opts.Level != 0 {
+			zstdOpts = append(zstdOpts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(opts.Level)))
 		}
-		zstdCloser := func() {
-			zstdWriter.Close()
-			fileCloser()
+		if opts.Concurrency != 0 {
+			zstdOpts = append(zstdOpts, zstd.WithEncoderConcurrency(opts.Concurrency))
 		}
-		return zstdWriter, zstdCloser, nil
-	} else {
-		return writer, fileCloser, nil
+		return zstd.NewWriter(w, zstdOpts...)
+	case Compress_Gzip:
+		level := gzip.DefaultCompression
+		if opts.Level != 0 {
+			level = opts.Level
+		}
+		return gzip.NewWriterLevel(w, level)
+	case Compress_Bzip2:
+		return dsnet_bzip2.NewWriter(w, &dsnet_bzip2.WriterConfig{Level: opts.Level})
+	case Compress_Xz:
+		config := xz.WriterConfig{}
+		if opts.Level != 0 {
+			config.DictCap = xzDictCap(opts.Level)
+		}
+		return config.NewWriter(w)
+	case Compress_Lz4:
+		lz4Writer := lz4.NewWriter(w)
+		lz4Opts := []lz4.Option{}
+		if opts.Level != 0 {
+			lz4Opts = append(lz4Opts, lz4.CompressionLevelOption(lz4Level(opts.Level)))
+		}
+		if opts.Concurrency != 1 {
+			lz4Opts = append(lz4Opts, lz4.ConcurrencyOption(opts.Concurrency)) // 0 is GOMAXPROCS
+		}
+		if err := lz4Writer.Apply(lz4Opts...); err != nil {
+			return nil, err
+		}
+		return lz4Writer, nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %d", opts.Compression)
+	}
+}
+
+// xzDictCap returns the xz dictionary size of a compression level, as the xz tool's presets.
+func xzDictCap(level int) int {
+	switch {
+	case level <= 0:
+		return 256 << 10
+	case level <= 2:
+		return 1 << (19 + level)
+	case level <= 6:
+		return (4 << 20) << ((level - 3) / 2)
+	case level >= 9:
+		return 64 << 20
+	default:
+		return (16 << 20) << (level - 7)
+	}
+}
+
+// lz4Level returns the lz4 compression level of a level from 1 to 9.
+func lz4Level(level int) lz4.CompressionLevel {
+	if level > 9 {
+		level = 9
 	}
+	return lz4.CompressionLevel(1 << (8 + level))
 }
 
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 ///////////////////////////////////////////////////////////////////////////////
 
-// Returns a io.Reader for the given filename, or os.Stdout if filename is "-". Also returns a closing function to defer.
-// If the filename ends in ".zst" or ".zstd", or if useZstd is true, the reader will zstd-decompress the input.
-func MakeCompressedReader(filename string, useZstd bool) (io.Reader, io.Closer, error) {
-	var reader io.Reader
-	var closer io.Closer
+// NewDecompressedReader returns an io.ReadCloser decompressing r, with its Compression detected from its magic bytes.
+// Closing it releases the decompressor, but does not close r.
+func NewDecompressedReader(r io.Reader) (io.ReadCloser, Compression, error) {
+	buffReader := bufio.NewReaderSize(r, DEFAULT_DECODE_BUFFER_SIZE)
+	header, err := buffReader.Peek(compressionMagicLen)
+	if err != nil && err != io.EOF {
+		return nil, Compress_None, err
+	}
+	compression := DetectCompression(header)
+	reader, err := newDecompressor(buffReader, compression)
+	return reader, compression, err
+}
 
+// newDecompressor returns an io.ReadCloser decompressing r with the given Compression.
+func newDecompressor(r io.Reader, compression Compression) (io.ReadCloser, error) {
+	switch compression {
+	case Compress_None:
+		return io.NopCloser(r), nil
+	case Compress_ZStd:
+		zstdReader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zstdReader.IOReadCloser(), nil
+	case Compress_Gzip:
+		return gzip.NewReader(r)
+	case Compress_Bzip2:
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case Compress_Xz:
+		xzReader, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xzReader), nil
+	case Compress_Lz4:
+		return io.NopCloser(lz4.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %d", compression)
+	}
+}
+
+// OpenCompressedReader opens the given filename, URL, or os.Stdin if filename is "-", with OpenSource,
+// and returns an io.ReadCloser of its decompressed contents.  The compression is detected from the magic bytes
+// of the contents, regardless of the filename's suffix.  Closing it also closes the file.
+func OpenCompressedReader(filename string) (io.ReadCloser, error) {
+	file, err := OpenSource(filename)
+	if err != nil {
+		return nil, err
+	}
+	reader, _, err := NewDecompressedReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &chainedReadCloser{reader, file}, nil
+}
+
+// CreateCompressedWriter creates the given filename, or uses os.Stdout if filename is "-", and
+// returns an io.WriteCloser compressing to it with the given options.  Closing it flushes the
+// compressed stream and closes the file.
+func CreateCompressedWriter(filename string, opts CompressionOptions) (io.WriteCloser, error) {
+	var file io.WriteCloser = nopWriteCloser{os.Stdout}
 	if filename != "-" {
-		if file, err := os.Open(filename); err == nil {
-			reader, closer = file, file
-		} else {
-			return nil, nil, err
+		var err error
+		if file, err = os.Create(filename); err != nil {
+			return nil, err
 		}
-	} else {
-		reader, closer = os.Stdin, nil
 	}
+	writer, err := NewCompressedWriter(file, opts)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &chainedWriteCloser{writer, file}, nil
+}
+
+// chainedReadCloser reads from a decompressor, and closes it and then its source.
+type chainedReadCloser struct {
+	io.ReadCloser
+	source io.Closer
+}
+
+func (c *chainedReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	if sourceErr := c.source.Close(); err == nil {
+		err = sourceErr
+	}
+	return err
+}
 
-	var err error
-	if useZstd || strings.HasSuffix(filename, ".zst") || strings.HasSuffix(filename, ".zstd") {
-		reader, err = zstd.NewReader(reader)
+// chainedWriteCloser writes to a compressor, and closes it and then its destination.
+type chainedWriteCloser struct {
+	io.WriteCloser
+	dest io.Closer
+}
+
+func (c *chainedWriteCloser) Close() error {
+	err := c.WriteCloser.Close()
+	if destErr := c.dest.Close(); err == nil {
+		err = destErr
 	}
+	return err
+}
 
+///////////////////////////////////////////////////////////////////////////////
+
+// Returns an io.Writer for the given filename, or os.Stdout if filename is "-".  Also returns a closing function to defer and any error.
+// The writer compresses with the codec of the filename's suffix, such as ".zst" or ".gz", or with zstd if useZstd is true.
+// See CreateCompressedWriter to choose the codec and level.
+func MakeCompressedWriter(filename string, useZstd bool) (io.Writer, func(), error) {
+	opts := CompressionOptions{Compression: CompressionFromFilename(filename)}
+	if useZstd {
+		opts.Compression = Compress_ZStd
+	}
+	writer, err := CreateCompressedWriter(filename, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return writer, func() { writer.Close() }, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Returns a io.Reader for the given filename, URL, or os.Stdin if filename is "-", opened with OpenSource.
+// Also returns a closer to defer.
+// The reader decompresses zstd, gzip, bzip2, xz, or lz4 input, detected by its magic bytes.
+// If useZstd is true, the input is always zstd-decompressed.
+// See OpenCompressedReader for a single io.ReadCloser.
+func MakeCompressedReader(filename string, useZstd bool) (io.Reader, io.Closer, error) {
+	file, err := OpenSource(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reader io.ReadCloser
+	if useZstd {
+		reader, err = newDecompressor(file, Compress_ZStd)
+	} else {
+		reader, _, err = NewDecompressedReader(file)
+	}
 	if err != nil {
 		// clean up file
-		if closer != nil {
-			closer.Close()
-		}
+		file.Close()
 		return nil, nil, err
 	}
-	return reader, closer, nil
+	return reader, &chainedReadCloser{reader, file}, nil
 }
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var allCompressions = []dbn.Compression{
+	dbn.Compress_None, dbn.Compress_ZStd, dbn.Compress_Gzip, dbn.Compress_Bzip2, dbn.Compress_Xz, dbn.Compress_Lz4,
+}
+
+var _ = Describe("Compression", func() {
+	It("should parse and format every codec", func() {
+		for _, compression := range allCompressions {
+			parsed, err := dbn.CompressionFromString(compression.String())
+			Expect(err).To(BeNil())
+			Expect(parsed).To(Equal(compression))
+			Expect(dbn.CompressionFromFilename("x.dbn" + compression.FileSuffix())).To(Equal(compression))
+		}
+		Expect(dbn.CompressionFromFilename("x.dbn.zstd")).To(Equal(dbn.Compress_ZStd))
+		Expect(dbn.Compress_ZStd.IsApiCompression()).To(BeTrue())
+		Expect(dbn.Compress_Gzip.IsApiCompression()).To(BeFalse())
+	})
+
+	It("should round-trip and detect every codec", func() {
+		raw, err := os.ReadFile("./tests/data/test_data.ohlcv-1s.dbn")
+		Expect(err).To(BeNil())
+		for _, compression := range allCompressions {
+			for _, opts := range []dbn.CompressionOptions{
+				{Compression: compression},
+				{Compression: compression, Level: 9, Concurrency: 1},
+			} {
+				var buf bytes.Buffer
+				writer, err := dbn.NewCompressedWriter(&buf, opts)
+				Expect(err).To(BeNil())
+				_, err = writer.Write(raw)
+				Expect(err).To(BeNil())
+				Expect(writer.Close()).To(Succeed())
+				Expect(dbn.DetectCompression(buf.Bytes())).To(Equal(compression), compression.String())
+
+				reader, detected, err := dbn.NewDecompressedReader(&buf)
+				Expect(err).To(BeNil())
+				Expect(detected).To(Equal(compression))
+				got, err := io.ReadAll(reader)
+				Expect(err).To(BeNil())
+				Expect(reader.Close()).To(Succeed())
+				Expect(got).To(Equal(raw), compression.String())
+			}
+		}
+	})
+
+	It("should detect compression regardless of the filename", func() {
+		dir := GinkgoT().TempDir()
+		compressed, err := os.ReadFile("./tests/data/test_data.ohlcv-1s.v3.dbn.zst")
+		Expect(err).To(BeNil())
+		mislabeled := filepath.Join(dir, "ohlcv.dbn")
+		Expect(os.WriteFile(mislabeled, compressed, 0644)).To(Succeed())
+
+		reader, closer, err := dbn.MakeCompressedReader(mislabeled, false)
+		Expect(err).To(BeNil())
+		records, _, err := dbn.ReadDBNToSlice[dbn.OhlcvMsg](reader)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(closer.Close()).To(Succeed())
+
+		readCloser, err := dbn.OpenCompressedReader(mislabeled)
+		Expect(err).To(BeNil())
+		metadata, err := dbn.ReadMetadata(readCloser)
+		Expect(err).To(BeNil())
+		Expect(metadata.Schema).To(Equal(dbn.Schema_Ohlcv1S))
+		Expect(readCloser.Close()).To(Succeed())
+	})
+
+	It("should write the codec of the filename's suffix", func() {
+		dir := GinkgoT().TempDir()
+		raw, err := os.ReadFile("./tests/data/test_data.ohlcv-1s.dbn")
+		Expect(err).To(BeNil())
+		for _, compression := range allCompressions {
+			filename := filepath.Join(dir, "out.dbn"+compression.FileSuffix())
+			writer, closer, err := dbn.MakeCompressedWriter(filename, false)
+			Expect(err).To(BeNil())
+			_, err = writer.Write(raw)
+			Expect(err).To(BeNil())
+			closer()
+
+			written, err := os.ReadFile(filename)
+			Expect(err).To(BeNil())
+			Expect(dbn.DetectCompression(written)).To(Equal(compression))
+
+			reader, err := dbn.OpenCompressedReader(filename)
+			Expect(err).To(BeNil())
+			got, err := io.ReadAll(reader)
+			Expect(err).To(BeNil())
+			Expect(reader.Close()).To(Succeed())
+			Expect(got).To(Equal(raw))
+		}
+	})
+})
@@ -18,6 +18,101 @@ import (
 // The sentinel value for an unset or null timestamp.
 const UNDEF_TIMESTAMP uint64 = math.MaxUint64
 
+// The sentinel value for an unset or null price.
+// It is untyped, so it compares with both int64 price fields and Price.
+const UNDEF_PRICE = math.MaxInt64
+
+// The sentinel value for an unset or null order quantity.
+const UNDEF_ORDER_SIZE = math.MaxUint32
+
+// The size of the gateway send timestamp, `ts_out`, appended to each record when Metadata.TsOut is set.
+const TsOut_Size = 8
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Flags is the bit field of a record's `flags`, indicating event end, message characteristics, and data quality.
+// Records keep their raw `uint8` field; convert it with `dbn.Flags(record.Flags)`.
+type Flags uint8
+
+const (
+	// Indicates it's the last record in the event from the venue for a given `instrument_id`.
+	F_LAST Flags = 1 << 7
+	// Indicates a top-of-book message, not an individual order.
+	F_TOB Flags = 1 << 6
+	// Indicates the message was sourced from a replay, such as a snapshot server.
+	F_SNAPSHOT Flags = 1 << 5
+	// Indicates an aggregated price level message, not an individual order.
+	F_MBP Flags = 1 << 4
+	// Indicates the `ts_recv` value is inaccurate due to clock issues or packet reordering.
+	F_BAD_TS_RECV Flags = 1 << 3
+	// Indicates an unrecoverable gap was detected in the channel.
+	F_MAYBE_BAD_BOOK Flags = 1 << 2
+)
+
+// Has returns true if all the bits of `f` are set.
+func (flags Flags) Has(f Flags) bool {
+	return flags&f == f
+}
+
+// IsLast returns true if F_LAST is set.
+func (flags Flags) IsLast() bool {
+	return flags&F_LAST != 0
+}
+
+// IsTob returns true if F_TOB is set.
+func (flags Flags) IsTob() bool {
+	return flags&F_TOB != 0
+}
+
+// IsSnapshot returns true if F_SNAPSHOT is set.
+func (flags Flags) IsSnapshot() bool {
+	return flags&F_SNAPSHOT != 0
+}
+
+// IsMbp returns true if F_MBP is set.
+func (flags Flags) IsMbp() bool {
+	return flags&F_MBP != 0
+}
+
+// IsBadTsRecv returns true if F_BAD_TS_RECV is set.
+func (flags Flags) IsBadTsRecv() bool {
+	return flags&F_BAD_TS_RECV != 0
+}
+
+// IsMaybeBadBook returns true if F_MAYBE_BAD_BOOK is set.
+func (flags Flags) IsMaybeBadBook() bool {
+	return flags&F_MAYBE_BAD_BOOK != 0
+}
+
+// String returns the set flags joined by "|", such as "LAST|SNAPSHOT", or "0" if none are set.
+// Unknown bits are included as hex.
+func (flags Flags) String() string {
+	if flags == 0 {
+		return "0"
+	}
+	names := make([]string, 0, 2)
+	for _, f := range []struct {
+		flag Flags
+		name string
+	}{
+		{F_LAST, "LAST"},
+		{F_TOB, "TOB"},
+		{F_SNAPSHOT, "SNAPSHOT"},
+		{F_MBP, "MBP"},
+		{F_BAD_TS_RECV, "BAD_TS_RECV"},
+		{F_MAYBE_BAD_BOOK, "MAYBE_BAD_BOOK"},
+	} {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%02x", uint8(flags)))
+	}
+	return strings.Join(names, "|")
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 // Side is a side of the market. The side of the market for resting orders, or the side of the aggressor for trades.
@@ -614,20 +709,41 @@ const (
 	Compress_None Compression = 0
 	/// Zstandard compressed.
 	Compress_ZStd Compression = 1
+	/// gzip compressed.  Local files only; not supported by the Databento API.
+	Compress_Gzip Compression = 2
+	/// bzip2 compressed.  Local files only; not supported by the Databento API.
+	Compress_Bzip2 Compression = 3
+	/// xz compressed.  Local files only; not supported by the Databento API.
+	Compress_Xz Compression = 4
+	/// LZ4 frame compressed.  Local files only; not supported by the Databento API.
+	Compress_Lz4 Compression = 5
 )
 
-// Returns the string representation of the Compression ('zstd' or 'none'), or empty string if unknown.
+// Returns the string representation of the Compression ('zstd', 'gzip', 'bzip2', 'xz', 'lz4', or 'none'), or empty string if unknown.
 func (c Compression) String() string {
 	switch c {
 	case Compress_None:
 		return "none"
 	case Compress_ZStd:
 		return "zstd"
+	case Compress_Gzip:
+		return "gzip"
+	case Compress_Bzip2:
+		return "bzip2"
+	case Compress_Xz:
+		return "xz"
+	case Compress_Lz4:
+		return "lz4"
 	default:
 		return ""
 	}
 }
 
+// IsApiCompression returns true if the Compression is supported by the Databento API, 'zstd' or 'none'.
+func (c Compression) IsApiCompression() bool {
+	return c == Compress_None || c == Compress_ZStd
+}
+
 // CompressionFromString converts a string to a Compression.
 // Returns an error if the string is unknown.
 func CompressionFromString(str string) (Compression, error) {
@@ -640,8 +756,16 @@ func CompressionFromString(str string) (Compression, error) {
 		return Compress_None, nil
 	case "none":
 		return Compress_None, nil
-	case "zstd":
+	case "zstd", "zst":
 		return Compress_ZStd, nil
+	case "gzip", "gz":
+		return Compress_Gzip, nil
+	case "bzip2", "bz2":
+		return Compress_Bzip2, nil
+	case "xz":
+		return Compress_Xz, nil
+	case "lz4":
+		return Compress_Lz4, nil
 	default:
 		return Compress_None, fmt.Errorf("unknown compression: '%s'", str)
 	}
@@ -781,6 +905,65 @@ const (
 	StatType_VenueSpecificVolume1 StatType = 10001
 )
 
+// Returns the snake_case name of the StatType, such as "settlement_price", or empty string if unknown.
+func (s StatType) String() string {
+	switch s {
+	case StatType_OpeningPrice:
+		return "opening_price"
+	case StatType_IndicativeOpeningPrice:
+		return "indicative_opening_price"
+	case StatType_SettlementPrice:
+		return "settlement_price"
+	case StatType_TradingSessionLowPrice:
+		return "trading_session_low_price"
+	case StatType_TradingSessionHighPrice:
+		return "trading_session_high_price"
+	case StatType_ClearedVolume:
+		return "cleared_volume"
+	case StatType_LowestOffer:
+		return "lowest_offer"
+	case StatType_HighestBid:
+		return "highest_bid"
+	case StatType_OpenInterest:
+		return "open_interest"
+	case StatType_FixingPrice:
+		return "fixing_price"
+	case StatType_ClosePrice:
+		return "close_price"
+	case StatType_NetChange:
+		return "net_change"
+	case StatType_Vwap:
+		return "vwap"
+	case StatType_Volatility:
+		return "volatility"
+	case StatType_Delta:
+		return "delta"
+	case StatType_UncrossingPrice:
+		return "uncrossing_price"
+	case StatType_UpperPriceLimit:
+		return "upper_price_limit"
+	case StatType_LowerPriceLimit:
+		return "lower_price_limit"
+	case StatType_BlockVolume:
+		return "block_volume"
+	case StatType_VenueSpecificVolume1:
+		return "venue_specific_volume_1"
+	default:
+		return ""
+	}
+}
+
+// IsQuantity returns true if the StatType's value is the StatMsg's `quantity` rather than its `price`,
+// as with volumes and open interest.
+func (s StatType) IsQuantity() bool {
+	switch s {
+	case StatType_ClearedVolume, StatType_OpenInterest, StatType_BlockVolume, StatType_VenueSpecificVolume1:
+		return true
+	default:
+		return false
+	}
+}
+
 // / The type of [`StatMsg`](crate::record::StatMsg) update.
 type StatUpdateAction uint8
 
@@ -830,6 +1013,46 @@ const (
 	StatusAction_NotAvailableForTrading StatusAction = 15
 )
 
+// String returns the snake_case name of the StatusAction, such as "halt", or empty string if unknown.
+func (a StatusAction) String() string {
+	switch a {
+	case StatusAction_None:
+		return "none"
+	case StatusAction_PreOpen:
+		return "pre_open"
+	case StatusAction_PreCross:
+		return "pre_cross"
+	case StatusAction_Quoting:
+		return "quoting"
+	case StatusAction_Cross:
+		return "cross"
+	case StatusAction_Rotation:
+		return "rotation"
+	case StatusAction_NewPriceIndication:
+		return "new_price_indication"
+	case StatusAction_Trading:
+		return "trading"
+	case StatusAction_Halt:
+		return "halt"
+	case StatusAction_Pause:
+		return "pause"
+	case StatusAction_Suspend:
+		return "suspend"
+	case StatusAction_PreClose:
+		return "pre_close"
+	case StatusAction_Close:
+		return "close"
+	case StatusAction_PostClose:
+		return "post_close"
+	case StatusAction_SsrChange:
+		return "ssr_change"
+	case StatusAction_NotAvailableForTrading:
+		return "not_available_for_trading"
+	default:
+		return ""
+	}
+}
+
 // / The secondary enum for a [`StatusMsg`](crate::record::StatusMsg) update, explains
 // / the cause of a halt or other change in `action`.
 type StatusReason uint8
@@ -908,6 +1131,83 @@ const (
 	StatusReason_QuotationNotAvailable StatusAction = 130
 )
 
+// String returns the snake_case name of the StatusReason, such as "luld_pause", or empty string if unknown.
+func (r StatusReason) String() string {
+	// StatusReason constants are typed as StatusAction
+	switch StatusAction(r) {
+	case StatusReason_None:
+		return "none"
+	case StatusReason_Scheduled:
+		return "scheduled"
+	case StatusReason_SurveillanceIntervention:
+		return "surveillance_intervention"
+	case StatusReason_MarketEvent:
+		return "market_event"
+	case StatusReason_InstrumentActivation:
+		return "instrument_activation"
+	case StatusReason_InstrumentExpiration:
+		return "instrument_expiration"
+	case StatusReason_RecoveryInProcess:
+		return "recovery_in_process"
+	case StatusReason_Regulatory:
+		return "regulatory"
+	case StatusReason_Administrative:
+		return "administrative"
+	case StatusReason_NonCompliance:
+		return "non_compliance"
+	case StatusReason_FilingsNotCurrent:
+		return "filings_not_current"
+	case StatusReason_SecTradingSuspension:
+		return "sec_trading_suspension"
+	case StatusReason_NewIssue:
+		return "new_issue"
+	case StatusReason_IssueAvailable:
+		return "issue_available"
+	case StatusReason_IssuesReviewed:
+		return "issues_reviewed"
+	case StatusReason_FilingReqsSatisfied:
+		return "filing_reqs_satisfied"
+	case StatusReason_NewsPending:
+		return "news_pending"
+	case StatusReason_NewsReleased:
+		return "news_released"
+	case StatusReason_NewsAndResumptionTimes:
+		return "news_and_resumption_times"
+	case StatusReason_NewsNotForthcoming:
+		return "news_not_forthcoming"
+	case StatusReason_OrderImbalance:
+		return "order_imbalance"
+	case StatusReason_LuldPause:
+		return "luld_pause"
+	case StatusReason_Operational:
+		return "operational"
+	case StatusReason_AdditionalInformationRequested:
+		return "additional_information_requested"
+	case StatusReason_MergerEffective:
+		return "merger_effective"
+	case StatusReason_Etf:
+		return "etf"
+	case StatusReason_CorporateAction:
+		return "corporate_action"
+	case StatusReason_NewSecurityOffering:
+		return "new_security_offering"
+	case StatusReason_MarketWideHaltLevel1:
+		return "market_wide_halt_level1"
+	case StatusReason_MarketWideHaltLevel2:
+		return "market_wide_halt_level2"
+	case StatusReason_MarketWideHaltLevel3:
+		return "market_wide_halt_level3"
+	case StatusReason_MarketWideHaltCarryover:
+		return "market_wide_halt_carryover"
+	case StatusReason_MarketWideHaltResumption:
+		return "market_wide_halt_resumption"
+	case StatusReason_QuotationNotAvailable:
+		return "quotation_not_available"
+	default:
+		return ""
+	}
+}
+
 // / Further information about a status update.
 type TradingEvent uint8
 
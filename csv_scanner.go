@@ -0,0 +1,251 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// CsvScanner scans a series of DBN records from Databento CSV output.
+// The first row must be the header of column names.  Columns are matched by name,
+// so optional columns such as `symbol` are ignored, and values may be raw integers
+// or written with `pretty_px` and `pretty_ts`.
+type CsvScanner struct {
+	reader     *csv.Reader
+	columns    []string // column names of the header row
+	lastRecord []string // last row read, waiting for decode
+	lastError  error    // the last error encountered
+	arena      fastjson.Arena
+}
+
+// NewCsvScanner creates a new dbn.CsvScanner from a reader of CSV text.
+func NewCsvScanner(r io.Reader) *CsvScanner {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	return &CsvScanner{
+		reader: reader,
+	}
+}
+
+// Next reads the next CSV row from the data.  Returns true on success.
+// Returns false either on error or on the end of data. Call Error() in order to determine the cause of the returned false.
+// The header row is read by the first call to Next.
+// Access the row with GetLastRecord, CsvScannerDecode, DecodeAny, or Visit.
+func (s *CsvScanner) Next() bool {
+	if s.columns == nil {
+		header, err := s.reader.Read()
+		if err != nil {
+			s.lastError = err
+			return false
+		}
+		s.columns = make([]string, len(header))
+		for i, column := range header {
+			s.columns[i] = strings.TrimSpace(column)
+		}
+	}
+	s.lastRecord, s.lastError = s.reader.Read()
+	return s.lastError == nil
+}
+
+// Error returns the last error from Next().  May be io.EOF.
+func (s *CsvScanner) Error() error {
+	return s.lastError
+}
+
+// Columns returns the column names of the header row, or nil if it has not been read.
+func (s *CsvScanner) Columns() []string {
+	return s.columns
+}
+
+// GetLastRecord returns the cells of the last row read.
+// This data may be overwritten by the next call to Next().
+func (s *CsvScanner) GetLastRecord() []string {
+	return s.lastRecord
+}
+
+// Parses the Scanner's current row as a `Record`.
+// This a plain function (not a method) because methods cannot be generic.
+func CsvScannerDecode[R Record, RP RecordPtr[R]](s *CsvScanner) (*R, error) {
+	val, header, err := s.parseWithHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	var rp RP = new(R)
+
+	if !header.RType.IsCompatibleWith(rp.RType()) {
+		return nil, unexpectedRTypeError(header.RType, rp.RType())
+	}
+
+	if err := rp.Fill_Json(val, header); err != nil {
+		return nil, err
+	} else {
+		return rp, nil
+	}
+}
+
+// DecodeAny parses the Scanner's current row as the concrete type of its RType, such as *Mbp0Msg,
+// returned as a Record.  Returns ErrUnknownRType if there is no type for the RType.
+func (s *CsvScanner) DecodeAny() (Record, error) {
+	val, header, err := s.parseWithHeader()
+	if err != nil {
+		return nil, err
+	}
+	return decodeJsonRecord(val, header)
+}
+
+// Parses the current row and passes it to the Visitor.
+// If the record has an unknown RType or fails to decode, it is passed to the Visitor's
+// OnUnknownRType or OnDecodeError, as the row re-joined with commas, if it implements
+// UnknownRTypeHandler or DecodeErrorHandler; otherwise the error is returned.
+func (s *CsvScanner) Visit(visitor Visitor) error {
+	val, header, err := s.parseWithHeader()
+	if err != nil {
+		return handleVisitError(visitor, nil, s.lastRaw(), err)
+	}
+	record, err := decodeJsonRecord(val, header)
+	if err != nil {
+		return handleVisitError(visitor, header, s.lastRaw(), err)
+	}
+	return VisitRecord(visitor, record)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// lastRaw returns the last row re-joined with commas.
+func (s *CsvScanner) lastRaw() []byte {
+	return []byte(strings.Join(s.lastRecord, ","))
+}
+
+// parseWithHeader converts the current row into the JSON value of the same record,
+// so that the records' Fill_Json decode it.  The header columns are moved into "hd" and
+// suffixed level columns, such as "bid_px_00", are moved into the "levels" array.
+func (s *CsvScanner) parseWithHeader() (*fastjson.Value, *RHeader, error) {
+	if s.lastRecord == nil {
+		return nil, nil, fmt.Errorf("no CSV row to decode")
+	}
+	if len(s.lastRecord) != len(s.columns) {
+		return nil, nil, fmt.Errorf("CSV row has %d cells but header has %d columns", len(s.lastRecord), len(s.columns))
+	}
+
+	s.arena.Reset()
+	a := &s.arena
+	val, hd, levels := a.NewObject(), a.NewObject(), a.NewArray()
+	numLevels := 0
+	for i, column := range s.columns {
+		cell := s.lastRecord[i]
+		name, level, isLevel := splitCsvLevelColumn(column)
+		cellVal := csvCellValue(a, name, cell)
+		switch {
+		case isLevel:
+			for numLevels <= level {
+				levels.SetArrayItem(numLevels, a.NewObject())
+				numLevels++
+			}
+			levels.GetArray()[level].Set(name, cellVal)
+		case name == "ts_event" || name == "rtype" || name == "publisher_id" || name == "instrument_id":
+			hd.Set(name, cellVal)
+		default:
+			val.Set(name, cellVal)
+		}
+	}
+	val.Set("hd", hd)
+	if numLevels != 0 {
+		val.Set("levels", levels)
+	}
+
+	var header RHeader
+	if err := header.Fill_Json(hd); err != nil {
+		return nil, nil, err
+	}
+	return val, &header, nil
+}
+
+// splitCsvLevelColumn splits a level column, such as "bid_px_00", into its name and level.
+func splitCsvLevelColumn(column string) (string, int, bool) {
+	n := len(column)
+	if n < 4 || column[n-3] != '_' || !isDigit(column[n-2]) || !isDigit(column[n-1]) {
+		return column, 0, false
+	}
+	return column[:n-3], int(column[n-2]-'0')*10 + int(column[n-1]-'0'), true
+}
+
+// csvStringColumns are the columns which are always text, even if they look like numbers.
+var csvStringColumns = map[string]bool{
+	"symbol": true, "raw_symbol": true, "stype_in_symbol": true, "stype_out_symbol": true,
+	"err": true, "msg": true, "side": true, "unpaired_side": true, "auction_type": true,
+	"significant_imbalance": true, "is_trading": true, "is_quoting": true,
+	"is_short_sell_restricted": true, "security_update_action": true, "instrument_class": true,
+	"currency": true, "settl_currency": true, "secsubtype": true, "group": true,
+	"exchange": true, "asset": true, "cfi": true, "security_type": true,
+	"unit_of_measure": true, "underlying": true, "strike_price_currency": true,
+	"match_algorithm": true, "user_defined_instrument": true, "leg_raw_symbol": true,
+	"leg_instrument_class": true, "leg_side": true,
+}
+
+// csvCellValue returns the JSON value of a CSV cell: a number for integers, a string for text
+// and pretty values, and null for an empty value, as written for undefined pretty values.
+func csvCellValue(a *fastjson.Arena, column string, cell string) *fastjson.Value {
+	if csvStringColumns[column] {
+		return a.NewString(cell)
+	}
+	if cell == "" {
+		return a.NewNull()
+	}
+	if isCsvInteger(cell) {
+		return a.NewNumberString(cell)
+	}
+	return a.NewString(cell)
+}
+
+// isCsvInteger returns true if the cell is an optionally-signed decimal integer.
+func isCsvInteger(cell string) bool {
+	if cell[0] == '-' {
+		cell = cell[1:]
+	}
+	if len(cell) == 0 {
+		return false
+	}
+	for i := 0; i < len(cell); i++ {
+		if !isDigit(cell[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ReadCsvToSlice reads the entire stream from Databento CSV output of DBN records.
+// It will scan for type R (for example Mbp0) and decode it into a slice of R.
+// Returns the slice and any error.
+// Example:
+//
+//	fileReader, err := os.Open(csvFilename)
+//	records, err := dbn.ReadCsvToSlice[dbn.Mbp0Msg](fileReader)
+func ReadCsvToSlice[R Record, RP RecordPtr[R]](reader io.Reader) ([]R, error) {
+	records := make([]R, 0)
+	scanner := NewCsvScanner(reader)
+	for scanner.Next() {
+		r, err := CsvScannerDecode[R, RP](scanner)
+		if err != nil {
+			return records, err
+		}
+		records = append(records, *r)
+	}
+	if err := scanner.Error(); err != io.EOF {
+		return records, err
+	}
+	return records, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// firstMbp1 returns the first record of the v3 MBP-1 test file.
+func firstMbp1() dbn.Mbp1Msg {
+	reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.mbp-1.v3.dbn.zst", false)
+	Expect(err).To(BeNil())
+	defer closer.Close()
+	records, _, err := dbn.ReadDBNToSlice[dbn.Mbp1Msg](reader)
+	Expect(err).To(BeNil())
+	record := records[0]
+	record.Header.Length = 0 // not part of CSV
+	return record
+}
+
+const mbp1CsvHeader = "ts_recv,ts_event,rtype,publisher_id,instrument_id,action,side,depth,price,size,flags,ts_in_delta,sequence,bid_px_00,ask_px_00,bid_sz_00,ask_sz_00,bid_ct_00,ask_ct_00,symbol\n"
+
+var _ = Describe("CsvScanner", func() {
+	Context("Databento CSV", func() {
+		It("should read raw values", func() {
+			want := firstMbp1()
+			l := want.Level
+			csv := mbp1CsvHeader + fmt.Sprintf("%d,%d,%d,%d,%d,%c,%c,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,ESH1\n",
+				want.TsRecv, want.Header.TsEvent, want.Header.RType, want.Header.PublisherID, want.Header.InstrumentID,
+				want.Action, want.Side, want.Depth, want.Price, want.Size, want.Flags, want.TsInDelta, want.Sequence,
+				l.BidPx, l.AskPx, l.BidSz, l.AskSz, l.BidCt, l.AskCt)
+
+			records, err := dbn.ReadCsvToSlice[dbn.Mbp1Msg](strings.NewReader(csv))
+			Expect(err).To(BeNil())
+			Expect(records).To(Equal([]dbn.Mbp1Msg{want}))
+		})
+
+		It("should read pretty values", func() {
+			want := firstMbp1()
+			l := want.Level
+			csv := mbp1CsvHeader + fmt.Sprintf("%s,%s,%d,%d,%d,%c,%c,%d,%s,%d,%d,%d,%d,%s,%s,%d,%d,%d,%d,ESH1\n",
+				dbn.TimestampToTime(want.TsRecv).UTC().Format("2006-01-02T15:04:05.000000000Z"),
+				dbn.TimestampToTime(want.Header.TsEvent).UTC().Format("2006-01-02T15:04:05.000000000Z"),
+				want.Header.RType, want.Header.PublisherID, want.Header.InstrumentID,
+				want.Action, want.Side, want.Depth, dbn.Price(want.Price).StringFixed(9), want.Size, want.Flags, want.TsInDelta, want.Sequence,
+				dbn.Price(l.BidPx).StringFixed(9), dbn.Price(l.AskPx).StringFixed(9), l.BidSz, l.AskSz, l.BidCt, l.AskCt)
+
+			scanner := dbn.NewCsvScanner(strings.NewReader(csv))
+			Expect(scanner.Next()).To(BeTrue())
+			Expect(scanner.Columns()).To(HaveLen(20))
+			Expect(scanner.GetLastRecord()[19]).To(Equal("ESH1"))
+			record, err := scanner.DecodeAny()
+			Expect(err).To(BeNil())
+			Expect(record).To(Equal(&want))
+			Expect(scanner.Next()).To(BeFalse())
+			Expect(scanner.Error()).To(Equal(io.EOF))
+		})
+
+		It("should read Databento's pretty OHLCV and empty undefined values", func() {
+			csv := `ts_event,rtype,publisher_id,instrument_id,open,high,low,close,volume,symbol
+2020-12-28T13:00:00.000000000Z,32,1,5482,372025.000000000,372050.000000000,372025.000000000,372050.000000000,57,ESH1
+,32,1,5482,,372050.000000000,372050.000000000,372050.000000000,13,ESH1
+`
+			records, err := dbn.ReadCsvToSlice[dbn.OhlcvMsg](strings.NewReader(csv))
+			Expect(err).To(BeNil())
+			Expect(records).To(HaveLen(2))
+			Expect(records[0].Header.TsEvent).To(Equal(uint64(1609160400000000000)))
+			Expect(records[0].Header.InstrumentID).To(Equal(uint32(5482)))
+			Expect(records[0].Open).To(Equal(int64(372025000000000)))
+			Expect(records[0].Volume).To(Equal(uint64(57)))
+			Expect(records[1].Header.TsEvent).To(Equal(uint64(dbn.UNDEF_TIMESTAMP)))
+			Expect(records[1].Open).To(Equal(int64(dbn.UNDEF_PRICE)))
+		})
+
+		It("should visit records and report decode errors", func() {
+			csv := `ts_event,rtype,publisher_id,instrument_id,open,high,low,close,volume
+1609160400000000000,32,1,5482,372025000000000,372050000000000,372025000000000,372050000000000,57
+1609160401000000000,153,1,5482,372050000000000,372050000000000,372050000000000,372050000000000,13
+`
+			var ohlcvs, unknowns int
+			visitor := &dbn.VisitorFuncs{
+				Ohlcv: func(r *dbn.OhlcvMsg) error {
+					ohlcvs++
+					return nil
+				},
+				UnknownRType: func(header *dbn.RHeader, raw []byte) error {
+					Expect(header.RType).To(Equal(dbn.RType(153)))
+					Expect(string(raw)).To(HavePrefix("1609160401000000000,153,"))
+					unknowns++
+					return nil
+				},
+			}
+			scanner := dbn.NewCsvScanner(strings.NewReader(csv))
+			for scanner.Next() {
+				Expect(scanner.Visit(visitor)).To(Succeed())
+			}
+			Expect(ohlcvs).To(Equal(1))
+			Expect(unknowns).To(Equal(1))
+		})
+	})
+})
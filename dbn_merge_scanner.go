@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"io"
+)
+
+// DbnMergeScanner scans the records of several DbnScanners as one stream, in the order of their
+// `ts_recv`, or `ts_event` for records without one, such as when merging the per-venue files of
+// a dataset.  Each scanner's records must already be in that order.  Records with equal
+// timestamps are taken from the scanners in the order given.
+type DbnMergeScanner struct {
+	scanners []*DbnScanner
+	pending  []bool // whether each scanner has a record read but not yet returned
+	started  bool
+	current  int
+	err      error
+}
+
+// NewDbnMergeScanner returns a DbnMergeScanner of the scanners.
+func NewDbnMergeScanner(scanners ...*DbnScanner) *DbnMergeScanner {
+	return &DbnMergeScanner{
+		scanners: scanners,
+		pending:  make([]bool, len(scanners)),
+		current:  -1,
+	}
+}
+
+// Next advances to the next record of all the scanners.  Returns false at the end of all of
+// them, or if one of them fails, whose error is then returned by Error.
+func (m *DbnMergeScanner) Next() bool {
+	if !m.started {
+		m.started = true
+		for i := range m.scanners {
+			if !m.advance(i) {
+				return false
+			}
+		}
+	} else if m.current >= 0 {
+		if !m.advance(m.current) {
+			return false
+		}
+	}
+
+	m.current = -1
+	var currentTs uint64
+	for i, scanner := range m.scanners {
+		if !m.pending[i] {
+			continue
+		}
+		ts := lastIndexTs(scanner)
+		if m.current < 0 || ts < currentTs {
+			m.current, currentTs = i, ts
+		}
+	}
+	if m.current < 0 {
+		m.err = io.EOF
+		return false
+	}
+	m.pending[m.current] = false
+	return true
+}
+
+// advance reads the next record of scanner `i`.  Returns false if it failed other than at its end.
+func (m *DbnMergeScanner) advance(i int) bool {
+	if m.scanners[i].Next() {
+		m.pending[i] = true
+		return true
+	}
+	if err := m.scanners[i].Error(); err != nil && err != io.EOF {
+		m.err = err
+		m.current = -1
+		return false
+	}
+	return true
+}
+
+// lastIndexTs returns the `ts_recv` of the scanner's last record, or its `ts_event` if it has none.
+func lastIndexTs(scanner *DbnScanner) uint64 {
+	if ts, ok := scanner.GetLastTsRecv(); ok && ts != UNDEF_TIMESTAMP {
+		return ts
+	}
+	header, _ := scanner.GetLastHeader()
+	return header.TsEvent
+}
+
+// Error returns the error which ended Next.  It is io.EOF at the end of all the scanners.
+func (m *DbnMergeScanner) Error() error {
+	return m.err
+}
+
+// Current returns the scanner of the current record, and its index in the scanners given.
+// Returns nil and -1 if there is no current record.
+func (m *DbnMergeScanner) Current() (*DbnScanner, int) {
+	if m.current < 0 {
+		return nil, -1
+	}
+	return m.scanners[m.current], m.current
+}
+
+// DecodeAny parses the current record, as DbnScanner.DecodeAny, in the layout of its source's version.
+func (m *DbnMergeScanner) DecodeAny() (Record, error) {
+	if m.current < 0 {
+		return nil, ErrNoRecord
+	}
+	return m.scanners[m.current].DecodeAny()
+}
+
+// Visit parses the current record and passes it to the Visitor, as DbnScanner.Visit.
+func (m *DbnMergeScanner) Visit(visitor Visitor) error {
+	if m.current < 0 {
+		return ErrNoRecord
+	}
+	return m.scanners[m.current].Visit(visitor)
+}
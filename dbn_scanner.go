@@ -4,6 +4,7 @@ package dbn
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
 	"io"
 )
@@ -70,6 +71,46 @@ func (s *DbnScanner) GetLastSize() int {
 	return s.lastSize
 }
 
+// HasTsOut returns true if the stream's records have an appended gateway send timestamp, `ts_out`,
+// as when a live session is started with `SendTsOut`.  Returns false if the metadata is unread.
+func (s *DbnScanner) HasTsOut() bool {
+	return s.metadata != nil && s.metadata.TsOut != 0
+}
+
+// GetLastTsOut returns the gateway send timestamp, `ts_out`, of the last record read.
+// Returns false if the stream has no `ts_out` or there is no record.
+//
+// The `ts_out` is the last 8 bytes of each record and is not part of the decoded structs.
+func (s *DbnScanner) GetLastTsOut() (uint64, bool) {
+	if !s.HasTsOut() || s.lastSize < RHeader_Size+TsOut_Size {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(s.lastRecord[s.lastSize-TsOut_Size : s.lastSize]), true
+}
+
+// GetLastTsRecv returns the capture-server receive timestamp, `ts_recv`, of the last record read,
+// without decoding it.  Returns false if there is no record or its RType has no `ts_recv`, such as OHLCV.
+func (s *DbnScanner) GetLastTsRecv() (uint64, bool) {
+	if s.lastSize <= RHeader_Size {
+		return 0, false
+	}
+	var offset int
+	switch RType(s.lastRecord[1]) {
+	case RType_Imbalance, RType_Statistics, RType_Status, RType_InstrumentDef:
+		offset = RHeader_Size
+	case RType_Mbp0, RType_Mbp1, RType_Mbp10, RType_Cmbp1, RType_Cbbo1S, RType_Cbbo1M, RType_Tcbbo, RType_Bbo1S, RType_Bbo1M:
+		offset = RHeader_Size + 16
+	case RType_Mbo:
+		offset = RHeader_Size + 24
+	default:
+		return 0, false
+	}
+	if s.lastSize < offset+8 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(s.lastRecord[offset : offset+8]), true
+}
+
 /////////////////////////////////////////////////////////////////////////////
 
 // readMetadata is an internal method to read metadata from the stream.
@@ -110,6 +151,12 @@ func (s *DbnScanner) Next() bool {
 	}
 	s.lastRecord[0] = recordLen
 	mustRead := 4 * int(recordLen)
+	if mustRead < RHeader_Size {
+		// too short to hold even its header, as in a corrupted stream
+		s.lastError = ErrMalformedRecord
+		s.lastSize = 1
+		return false
+	}
 
 	// Read the header and record
 	// 1: because we already got the first size byte
@@ -226,142 +273,118 @@ func (s *DbnScanner) DecodeInstrumentDefMsg() (*InstrumentDefMsgV3, error) {
 	return s.decodeInstrumentDefMsg()
 }
 
-// Parses the current Record and passes it to the Visitor.
-func (s *DbnScanner) Visit(visitor Visitor) error {
+// DecodeAny parses the Scanner's current record as the concrete type of its RType, such as *Mbp0Msg,
+// returned as a Record.  Records whose layout differs by DBN version are returned as the type of
+// the stream's version, such as *StatMsgV2 in V1/V2 streams; use UpgradeRecord to convert them to
+// their current types, as Visit does.  V1 instrument definitions are not supported.
+// Returns ErrUnknownRType if there is no type for the RType.
+func (s *DbnScanner) DecodeAny() (Record, error) {
 	// Ensure there's a record to decode
 	if s.lastSize <= RHeader_Size {
-		return ErrNoRecord
+		return nil, ErrNoRecord
 	}
 	recordLen := 4 * int(s.lastRecord[0])
 	if s.lastSize < recordLen {
-		return ErrMalformedRecord
+		return nil, ErrMalformedRecord
 	}
 
-	// Dispatch based on RType Make sure it's the right record type
+	var record rawRecord
 	switch rtype := RType(s.lastRecord[1]); rtype {
-	// Trade
 	case RType_Mbp0:
-		record := Mbp0Msg{}
-		if err := record.Fill_Raw(s.lastRecord[:Mbp0Msg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp0(&record)
-		}
-	// Market-by-price, 1 depth
+		record = &Mbp0Msg{}
 	case RType_Mbp1:
-		record := Mbp1Msg{}
-		if err := record.Fill_Raw(s.lastRecord[:Mbp1Msg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp1(&record)
-		}
-	// Market-by-price, 10 depth
+		record = &Mbp1Msg{}
 	case RType_Mbp10:
-		record := Mbp10Msg{}
-		if err := record.Fill_Raw(s.lastRecord[:Mbp10Msg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp10(&record)
-		}
-	// Market-by-Order
+		record = &Mbp10Msg{}
 	case RType_Mbo:
-		record := MboMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:MboMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbo(&record)
-		}
-	// Candlestick schemas
+		record = &MboMsg{}
 	case RType_Ohlcv1S, RType_Ohlcv1M, RType_Ohlcv1H, RType_Ohlcv1D, RType_OhlcvEod, RType_OhlcvDeprecated:
-		record := OhlcvMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:OhlcvMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnOhlcv(&record)
-		}
-	// CBBO schemas
+		record = &OhlcvMsg{}
 	case RType_Cmbp1, RType_Cbbo1S, RType_Cbbo1M, RType_Tcbbo:
-		record := Cmbp1Msg{}
-		if err := record.Fill_Raw(s.lastRecord[:Cmbp1Msg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnCmbp1(&record)
-		}
-	// Imbalance
+		record = &Cmbp1Msg{}
+	case RType_Bbo1S, RType_Bbo1M:
+		record = &BboMsg{}
 	case RType_Imbalance:
-		record := ImbalanceMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:ImbalanceMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnImbalance(&record)
-		}
-	// Error
-	case RType_Error:
-		record := ErrorMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:ErrorMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnErrorMsg(&record)
-		}
-	// SymbolMapping
-	case RType_SymbolMapping:
+		record = &ImbalanceMsg{}
+	case RType_Status:
+		record = &StatusMsg{}
+	// Version-aware records
+	case RType_Error, RType_System, RType_SymbolMapping, RType_Statistics, RType_InstrumentDef:
 		if s.metadata == nil {
-			return ErrNoMetadata
+			return nil, ErrNoMetadata
 		}
-		record := SymbolMappingMsg{}
-		if err := SymbolMappingMsgFillRaw(&record, s.lastRecord[:s.lastSize], s.metadata.SymbolCstrLen); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnSymbolMappingMsg(&record)
+		versioned, err := s.versionedRecord(rtype)
+		if err != nil {
+			return nil, err
 		}
-	// System
-	case RType_System:
-		record := SystemMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:SystemMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnSystemMsg(&record)
+		record = versioned
+	default:
+		return nil, ErrUnknownRType
+	}
+	if err := record.Fill_Raw(s.lastRecord[:min(int(record.RSize()), s.lastSize)]); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Parses the current Record and passes it to the Visitor.
+// If the record has an unknown RType or fails to decode, it is passed to the Visitor's
+// OnUnknownRType or OnDecodeError, if it implements UnknownRTypeHandler or DecodeErrorHandler;
+// otherwise the error is returned.
+func (s *DbnScanner) Visit(visitor Visitor) error {
+	record, err := s.DecodeAny()
+	if err != nil {
+		if err == ErrNoRecord || err == ErrMalformedRecord || err == ErrNoMetadata {
+			return err
 		}
-	// Statistics (version-aware: V1/V2 = 64 bytes, V3 = 80 bytes)
-	case RType_Statistics:
-		if s.metadata == nil {
-			return ErrNoMetadata
+		header, _ := s.GetLastHeader()
+		return handleVisitError(visitor, &header, s.lastRecord[:s.lastSize], err)
+	}
+	return VisitRecord(visitor, record)
+}
+
+// versionedRecord returns an empty record of the RType in the layout of the stream's version.
+func (s *DbnScanner) versionedRecord(rtype RType) (rawRecord, error) {
+	version := s.metadata.VersionNum
+	if version < HeaderVersion1 || version > HeaderVersion3 {
+		return nil, ErrInvalidDBNVersion
+	}
+	switch rtype {
+	case RType_Error:
+		if version == HeaderVersion1 {
+			return &ErrorMsgV1{}, nil
 		}
-		record, err := s.decodeStatMsg()
-		if err != nil {
-			return err
+		return &ErrorMsg{}, nil
+	case RType_System:
+		if version == HeaderVersion1 {
+			return &SystemMsgV1{}, nil
 		}
-		return visitor.OnStatMsg(record)
-	// Status
-	case RType_Status:
-		record := StatusMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:StatusMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnStatusMsg(&record)
+		return &SystemMsg{}, nil
+	case RType_SymbolMapping:
+		switch s.metadata.SymbolCstrLen {
+		case MetadataV1_SymbolCstrLen:
+			return &SymbolMappingMsgV1{}, nil
+		case MetadataV2_SymbolCstrLen:
+			return &SymbolMappingMsgV2{}, nil
+		default:
+			return nil, unexpectedCStrLenError(s.metadata.SymbolCstrLen)
 		}
-	// BBO schemas
-	case RType_Bbo1S, RType_Bbo1M:
-		record := BboMsg{}
-		if err := record.Fill_Raw(s.lastRecord[:BboMsg_Size]); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnBbo(&record)
+	case RType_Statistics:
+		if version < HeaderVersion3 {
+			return &StatMsgV2{}, nil
 		}
-
-	// InstrumentDef (version-aware: V2 and V3 have different layouts)
+		return &StatMsgV3{}, nil
 	case RType_InstrumentDef:
-		if s.metadata == nil {
-			return ErrNoMetadata
+		switch version {
+		case HeaderVersion1:
+			return nil, errInstrumentDefMsgV1
+		case HeaderVersion2:
+			return &InstrumentDefMsgV2{}, nil
+		default:
+			return &InstrumentDefMsgV3{}, nil
 		}
-		record, err := s.decodeInstrumentDefMsg()
-		if err != nil {
-			return err
-		}
-		return visitor.OnInstrumentDefMsg(record)
-
 	default:
-		return ErrUnknownRType
+		return nil, ErrUnknownRType
 	}
 }
 
@@ -379,20 +402,7 @@ func (s *DbnScanner) decodeStatMsg() (*StatMsgV3, error) {
 		if err := v2.Fill_Raw(s.lastRecord[:StatMsgV2_Size]); err != nil {
 			return nil, err
 		}
-		// Upgrade V2 → V3: sign-extend Quantity from int32 to int64
-		return &StatMsgV3{
-			Header:       v2.Header,
-			TsRecv:       v2.TsRecv,
-			TsRef:        v2.TsRef,
-			Price:        v2.Price,
-			Quantity:     int64(v2.Quantity),
-			Sequence:     v2.Sequence,
-			TsInDelta:    v2.TsInDelta,
-			StatType:     v2.StatType,
-			ChannelID:    v2.ChannelID,
-			UpdateAction: v2.UpdateAction,
-			StatFlags:    v2.StatFlags,
-		}, nil
+		return statMsgV2ToV3(&v2), nil
 	case HeaderVersion3:
 		var v3 StatMsgV3
 		if err := v3.Fill_Raw(s.lastRecord[:StatMsgV3_Size]); err != nil {
@@ -411,76 +421,13 @@ func (s *DbnScanner) decodeStatMsg() (*StatMsgV3, error) {
 func (s *DbnScanner) decodeInstrumentDefMsg() (*InstrumentDefMsgV3, error) {
 	switch s.metadata.VersionNum {
 	case HeaderVersion1:
-		return nil, fmt.Errorf("InstrumentDefMsg V1 (22-byte symbols) is not supported")
+		return nil, errInstrumentDefMsgV1
 	case HeaderVersion2:
 		var v2 InstrumentDefMsgV2
 		if err := v2.Fill_Raw(s.lastRecord[:s.lastSize]); err != nil {
 			return nil, err
 		}
-		// Upgrade V2 → V3: zero-extend RawInstrumentID, drop removed fields, zero-fill leg fields
-		v3 := InstrumentDefMsgV3{
-			Header:                  v2.Header,
-			TsRecv:                  v2.TsRecv,
-			MinPriceIncrement:       v2.MinPriceIncrement,
-			DisplayFactor:           v2.DisplayFactor,
-			Expiration:              v2.Expiration,
-			Activation:              v2.Activation,
-			HighLimitPrice:          v2.HighLimitPrice,
-			LowLimitPrice:           v2.LowLimitPrice,
-			MaxPriceVariation:       v2.MaxPriceVariation,
-			UnitOfMeasureQty:        v2.UnitOfMeasureQty,
-			MinPriceIncrementAmount: v2.MinPriceIncrementAmount,
-			PriceRatio:              v2.PriceRatio,
-			StrikePrice:             v2.StrikePrice,
-			RawInstrumentID:         uint64(v2.RawInstrumentID),
-			InstAttribValue:         v2.InstAttribValue,
-			UnderlyingID:            v2.UnderlyingID,
-			MarketDepthImplied:      v2.MarketDepthImplied,
-			MarketDepth:             v2.MarketDepth,
-			MarketSegmentID:         v2.MarketSegmentID,
-			MaxTradeVol:             v2.MaxTradeVol,
-			MinLotSize:              v2.MinLotSize,
-			MinLotSizeBlock:         v2.MinLotSizeBlock,
-			MinLotSizeRoundLot:      v2.MinLotSizeRoundLot,
-			MinTradeVol:             v2.MinTradeVol,
-			ContractMultiplier:      v2.ContractMultiplier,
-			DecayQuantity:           v2.DecayQuantity,
-			OriginalContractSize:    v2.OriginalContractSize,
-			ApplID:                  v2.ApplID,
-			MaturityYear:            v2.MaturityYear,
-			DecayStartDate:          v2.DecayStartDate,
-			ChannelID:               v2.ChannelID,
-			Currency:                v2.Currency,
-			SettlCurrency:           v2.SettlCurrency,
-			Secsubtype:              v2.Secsubtype,
-			Group:                   v2.Group,
-			Exchange:                v2.Exchange,
-			Cfi:                     v2.Cfi,
-			SecurityType:            v2.SecurityType,
-			UnitOfMeasure:           v2.UnitOfMeasure,
-			Underlying:              v2.Underlying,
-			StrikePriceCurrency:     v2.StrikePriceCurrency,
-			InstrumentClass:         v2.InstrumentClass,
-			MatchAlgorithm:          v2.MatchAlgorithm,
-			MainFraction:            v2.MainFraction,
-			PriceDisplayFormat:      v2.PriceDisplayFormat,
-			SubFraction:             v2.SubFraction,
-			UnderlyingProduct:       v2.UnderlyingProduct,
-			SecurityUpdateAction:    v2.SecurityUpdateAction,
-			MaturityMonth:           v2.MaturityMonth,
-			MaturityDay:             v2.MaturityDay,
-			MaturityWeek:            v2.MaturityWeek,
-			UserDefinedInstrument:   v2.UserDefinedInstrument,
-			ContractMultiplierUnit:  v2.ContractMultiplierUnit,
-			FlowScheduleType:        v2.FlowScheduleType,
-			TickRule:                v2.TickRule,
-			// Leg fields are zero-valued (not present in V2)
-		}
-		// RawSymbol is the same size in V2 and V3 (71 bytes)
-		v3.RawSymbol = v2.RawSymbol
-		// Asset: V2 is [7]byte, V3 is [11]byte — copy the smaller into the larger
-		copy(v3.Asset[:], v2.Asset[:])
-		return &v3, nil
+		return instrumentDefMsgV2ToV3(&v2), nil
 	case HeaderVersion3:
 		var v3 InstrumentDefMsgV3
 		if err := v3.Fill_Raw(s.lastRecord[:s.lastSize]); err != nil {
@@ -492,6 +439,148 @@ func (s *DbnScanner) decodeInstrumentDefMsg() (*InstrumentDefMsgV3, error) {
 	}
 }
 
+// errInstrumentDefMsgV1 is returned when decoding or encoding V1 instrument definitions.
+var errInstrumentDefMsgV1 = fmt.Errorf("InstrumentDefMsg V1 (22-byte symbols) is not supported")
+
+///////////////////////////////////////////////////////////////////////////////
+// These convert V1/V2 records up to the current layout, the inverse of the DbnWriter downgrades.
+
+// UpgradeRecord returns a V1/V2 record, as from DecodeAny on an older stream, converted to its
+// current type, such as *StatMsgV2 to *StatMsg.  Other records are returned as they are.
+func UpgradeRecord(record Record) Record {
+	switch r := record.(type) {
+	case *StatMsgV2:
+		return statMsgV2ToV3(r)
+	case *InstrumentDefMsgV2:
+		return instrumentDefMsgV2ToV3(r)
+	case *ErrorMsgV1:
+		return errorMsgV1ToV2(r)
+	case *SystemMsgV1:
+		return systemMsgV1ToV2(r)
+	case *SymbolMappingMsgV1:
+		return symbolMappingMsgV1ToV2(r)
+	default:
+		return record
+	}
+}
+
+// statMsgV2ToV3 converts a V1/V2 StatMsg to the V3 layout, sign-extending Quantity from int32 to int64.
+// The V2 undefined quantity becomes the V3 one.
+func statMsgV2ToV3(v2 *StatMsgV2) *StatMsgV3 {
+	quantity := int64(v2.Quantity)
+	if v2.Quantity == StatMsgV2_UNDEF_STAT_QUANTITY {
+		quantity = StatMsgV3_UNDEF_STAT_QUANTITY
+	}
+	return &StatMsgV3{
+		Header:       v2.Header,
+		TsRecv:       v2.TsRecv,
+		TsRef:        v2.TsRef,
+		Price:        v2.Price,
+		Quantity:     quantity,
+		Sequence:     v2.Sequence,
+		TsInDelta:    v2.TsInDelta,
+		StatType:     v2.StatType,
+		ChannelID:    v2.ChannelID,
+		UpdateAction: v2.UpdateAction,
+		StatFlags:    v2.StatFlags,
+	}
+}
+
+// instrumentDefMsgV2ToV3 converts a V2 InstrumentDefMsg to the V3 layout.
+// RawInstrumentID is zero-extended, fields removed in V3 are dropped, and leg fields are zero.
+func instrumentDefMsgV2ToV3(v2 *InstrumentDefMsgV2) *InstrumentDefMsgV3 {
+	v3 := InstrumentDefMsgV3{
+		Header:                  v2.Header,
+		TsRecv:                  v2.TsRecv,
+		MinPriceIncrement:       v2.MinPriceIncrement,
+		DisplayFactor:           v2.DisplayFactor,
+		Expiration:              v2.Expiration,
+		Activation:              v2.Activation,
+		HighLimitPrice:          v2.HighLimitPrice,
+		LowLimitPrice:           v2.LowLimitPrice,
+		MaxPriceVariation:       v2.MaxPriceVariation,
+		UnitOfMeasureQty:        v2.UnitOfMeasureQty,
+		MinPriceIncrementAmount: v2.MinPriceIncrementAmount,
+		PriceRatio:              v2.PriceRatio,
+		StrikePrice:             v2.StrikePrice,
+		RawInstrumentID:         uint64(v2.RawInstrumentID),
+		InstAttribValue:         v2.InstAttribValue,
+		UnderlyingID:            v2.UnderlyingID,
+		MarketDepthImplied:      v2.MarketDepthImplied,
+		MarketDepth:             v2.MarketDepth,
+		MarketSegmentID:         v2.MarketSegmentID,
+		MaxTradeVol:             v2.MaxTradeVol,
+		MinLotSize:              v2.MinLotSize,
+		MinLotSizeBlock:         v2.MinLotSizeBlock,
+		MinLotSizeRoundLot:      v2.MinLotSizeRoundLot,
+		MinTradeVol:             v2.MinTradeVol,
+		ContractMultiplier:      v2.ContractMultiplier,
+		DecayQuantity:           v2.DecayQuantity,
+		OriginalContractSize:    v2.OriginalContractSize,
+		ApplID:                  v2.ApplID,
+		MaturityYear:            v2.MaturityYear,
+		DecayStartDate:          v2.DecayStartDate,
+		ChannelID:               v2.ChannelID,
+		Currency:                v2.Currency,
+		SettlCurrency:           v2.SettlCurrency,
+		Secsubtype:              v2.Secsubtype,
+		Group:                   v2.Group,
+		Exchange:                v2.Exchange,
+		Cfi:                     v2.Cfi,
+		SecurityType:            v2.SecurityType,
+		UnitOfMeasure:           v2.UnitOfMeasure,
+		Underlying:              v2.Underlying,
+		StrikePriceCurrency:     v2.StrikePriceCurrency,
+		InstrumentClass:         v2.InstrumentClass,
+		MatchAlgorithm:          v2.MatchAlgorithm,
+		MainFraction:            v2.MainFraction,
+		PriceDisplayFormat:      v2.PriceDisplayFormat,
+		SubFraction:             v2.SubFraction,
+		UnderlyingProduct:       v2.UnderlyingProduct,
+		SecurityUpdateAction:    v2.SecurityUpdateAction,
+		MaturityMonth:           v2.MaturityMonth,
+		MaturityDay:             v2.MaturityDay,
+		MaturityWeek:            v2.MaturityWeek,
+		UserDefinedInstrument:   v2.UserDefinedInstrument,
+		ContractMultiplierUnit:  v2.ContractMultiplierUnit,
+		FlowScheduleType:        v2.FlowScheduleType,
+		TickRule:                v2.TickRule,
+		// Leg fields are zero-valued (not present in V2)
+	}
+	// RawSymbol is the same size in V2 and V3 (71 bytes)
+	v3.RawSymbol = v2.RawSymbol
+	// Asset: V2 is [7]byte, V3 is [11]byte — copy the smaller into the larger
+	copy(v3.Asset[:], v2.Asset[:])
+	return &v3
+}
+
+// errorMsgV1ToV2 converts a V1 ErrorMsg to the current layout.
+func errorMsgV1ToV2(v1 *ErrorMsgV1) *ErrorMsg {
+	r := ErrorMsg{Header: v1.Header}
+	copy(r.Error[:], v1.Error[:])
+	return &r
+}
+
+// systemMsgV1ToV2 converts a V1 SystemMsg to the current layout.
+func systemMsgV1ToV2(v1 *SystemMsgV1) *SystemMsg {
+	r := SystemMsg{Header: v1.Header}
+	copy(r.Message[:], v1.Message[:])
+	return &r
+}
+
+// symbolMappingMsgV1ToV2 converts a V1 SymbolMappingMsg to the current layout.
+func symbolMappingMsgV1ToV2(v1 *SymbolMappingMsgV1) *SymbolMappingMsgV2 {
+	return &SymbolMappingMsgV2{
+		Header:         v1.Header,
+		StypeIn:        v1.StypeIn,
+		StypeInSymbol:  v1.StypeInSymbol,
+		StypeOut:       v1.StypeOut,
+		StypeOutSymbol: v1.StypeOutSymbol,
+		StartTs:        v1.StartTs,
+		EndTs:          v1.EndTs,
+	}
+}
+
 /////////////////////////////////////////////////////////////////////////////
 
 // ReadDBNToSlice reads the entire raw DBN stream from an io.Reader.
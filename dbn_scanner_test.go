@@ -1,6 +1,7 @@
 package dbn_test
 
 import (
+	"bytes"
 	"io"
 	"math"
 	"os"
@@ -110,18 +111,18 @@ var _ = Describe("DbnScanner", func() {
 			Expect(metadata.VersionNum).To(Equal(uint8(dbn.HeaderVersion1)))
 			Expect(visitor.Stats).To(HaveLen(2))
 
-			// V1 Quantity was int32 max (2147483647), should be sign-extended to int64
+			// V1 Quantity was the V2 undefined quantity, int32 max, so is the V3 undefined quantity
 			r0 := visitor.Stats[0]
 			Expect(r0.Header.RType).To(Equal(dbn.RType_Statistics))
 			Expect(r0.TsRecv).To(Equal(uint64(1682269536040124325)))
 			Expect(r0.Price).To(Equal(int64(100000000000)))
-			Expect(r0.Quantity).To(Equal(int64(math.MaxInt32)))
+			Expect(r0.Quantity).To(Equal(int64(dbn.StatMsgV3_UNDEF_STAT_QUANTITY)))
 			Expect(r0.Sequence).To(Equal(uint32(2)))
 			Expect(r0.StatType).To(Equal(uint16(7)))
 
 			r1 := visitor.Stats[1]
 			Expect(r1.TsRecv).To(Equal(uint64(1682269536121890092)))
-			Expect(r1.Quantity).To(Equal(int64(math.MaxInt32)))
+			Expect(r1.Quantity).To(Equal(int64(dbn.StatMsgV3_UNDEF_STAT_QUANTITY)))
 			Expect(r1.Sequence).To(Equal(uint32(7)))
 			Expect(r1.StatType).To(Equal(uint16(5)))
 		})
@@ -142,7 +143,7 @@ var _ = Describe("DbnScanner", func() {
 			r0 := visitor.Stats[0]
 			Expect(r0.TsRecv).To(Equal(uint64(1682269536040124325)))
 			Expect(r0.Price).To(Equal(int64(100000000000)))
-			Expect(r0.Quantity).To(Equal(int64(math.MaxInt32)))
+			Expect(r0.Quantity).To(Equal(int64(dbn.StatMsgV3_UNDEF_STAT_QUANTITY)))
 		})
 
 		It("should read V3 statistics natively via Visit", func() {
@@ -256,7 +257,7 @@ var _ = Describe("DbnScanner", func() {
 
 			r, err := scanner.DecodeStatMsg()
 			Expect(err).To(BeNil())
-			Expect(r.Quantity).To(Equal(int64(math.MaxInt32)))
+			Expect(r.Quantity).To(Equal(int64(dbn.StatMsgV3_UNDEF_STAT_QUANTITY)))
 			Expect(r.TsRecv).To(Equal(uint64(1682269536040124325)))
 		})
 
@@ -302,4 +303,146 @@ var _ = Describe("DbnScanner", func() {
 			Expect(r.LegPrice).To(Equal(int64(math.MaxInt64)))
 		})
 	})
+
+	Context("malformed", func() {
+		It("should report a record whose length is shorter than its header", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.trades.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			var buf bytes.Buffer
+			_, err = buf.ReadFrom(reader)
+			Expect(err).To(BeNil())
+
+			scanner := dbn.NewDbnScanner(bytes.NewReader(buf.Bytes()))
+			numRecords := 0
+			for scanner.Next() {
+				numRecords++
+			}
+			Expect(numRecords).To(BeNumerically(">", 1))
+
+			// Zero the length of the second record
+			data := buf.Bytes()
+			data[len(data)-(numRecords-1)*dbn.Mbp0Msg_Size] = 0
+			scanner = dbn.NewDbnScanner(bytes.NewReader(data))
+			Expect(scanner.Next()).To(BeTrue())
+			Expect(scanner.Next()).To(BeFalse())
+			Expect(scanner.Error()).To(Equal(dbn.ErrMalformedRecord))
+		})
+	})
+
+	Context("ts_out", func() {
+		It("should read the gateway send timestamp appended to records", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.mbo.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			records, metadata, err := dbn.ReadDBNToSlice[dbn.MboMsg](reader)
+			Expect(err).To(BeNil())
+			Expect(records).NotTo(BeEmpty())
+
+			// Rewrite the records with a ts_out 1ms after their ts_recv
+			tsOutMetadata := *metadata
+			tsOutMetadata.TsOut = 1
+			var buf bytes.Buffer
+			writer, err := dbn.NewDbnWriter(&buf, &tsOutMetadata)
+			Expect(err).To(BeNil())
+			for i := range records {
+				Expect(writer.WriteWithTsOut(&records[i], records[i].TsRecv+1_000_000)).To(Succeed())
+			}
+
+			scanner := dbn.NewDbnScanner(&buf)
+			_, err = scanner.Metadata()
+			Expect(err).To(BeNil())
+			Expect(scanner.HasTsOut()).To(BeTrue())
+			for i := range records {
+				Expect(scanner.Next()).To(BeTrue())
+				Expect(scanner.GetLastSize()).To(Equal(dbn.MboMsg_Size + dbn.TsOut_Size))
+				tsOut, ok := scanner.GetLastTsOut()
+				Expect(ok).To(BeTrue())
+				Expect(tsOut).To(Equal(records[i].TsRecv + 1_000_000))
+				tsRecv, ok := scanner.GetLastTsRecv()
+				Expect(ok).To(BeTrue())
+				Expect(tsRecv).To(Equal(records[i].TsRecv))
+				// The struct decodes as usual, with the ts_out in its length
+				r, err := dbn.DbnScannerDecode[dbn.MboMsg](scanner)
+				Expect(err).To(BeNil())
+				Expect(r.OrderID).To(Equal(records[i].OrderID))
+				Expect(r.Header.Length).To(Equal(uint8((dbn.MboMsg_Size + dbn.TsOut_Size) / 4)))
+			}
+			Expect(scanner.Next()).To(BeFalse())
+		})
+
+		It("should report no ts_out when the stream has none", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.ohlcv-1s.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			scanner := dbn.NewDbnScanner(reader)
+			Expect(scanner.Next()).To(BeTrue())
+			Expect(scanner.HasTsOut()).To(BeFalse())
+			_, ok := scanner.GetLastTsOut()
+			Expect(ok).To(BeFalse())
+			_, ok = scanner.GetLastTsRecv() // OHLCV has no ts_recv
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should read ts_recv without decoding", func() {
+			for _, filename := range []string{"test_data.trades.v3.dbn.zst", "test_data.mbp-10.v3.dbn.zst", "test_data.bbo-1s.v3.dbn.zst", "test_data.cbbo-1s.v3.dbn.zst", "test_data.statistics.v3.dbn.zst", "test_data.imbalance.v3.dbn.zst", "test_data.status.v3.dbn.zst", "test_data.definition.v3.dbn.zst"} {
+				reader, closer, err := dbn.MakeCompressedReader("./tests/data/"+filename, false)
+				Expect(err).To(BeNil())
+				scanner := dbn.NewDbnScanner(reader)
+				Expect(scanner.Next()).To(BeTrue(), filename)
+				tsRecv, ok := scanner.GetLastTsRecv()
+				Expect(ok).To(BeTrue(), filename)
+				visitor := &tsRecvVisitor{}
+				Expect(scanner.Visit(visitor)).To(Succeed(), filename)
+				Expect(tsRecv).To(Equal(visitor.tsRecv), filename)
+				closer.Close()
+			}
+		})
+	})
 })
+
+// tsRecvVisitor captures the ts_recv of the visited record.
+type tsRecvVisitor struct {
+	dbn.NullVisitor
+	tsRecv uint64
+}
+
+func (v *tsRecvVisitor) OnMbp0(r *dbn.Mbp0Msg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnMbp10(r *dbn.Mbp10Msg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnBbo(r *dbn.BboMsg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnCmbp1(r *dbn.Cmbp1Msg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnStatMsg(r *dbn.StatMsg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnImbalance(r *dbn.ImbalanceMsg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnStatusMsg(r *dbn.StatusMsg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
+
+func (v *tsRecvVisitor) OnInstrumentDefMsg(r *dbn.InstrumentDefMsg) error {
+	v.tsRecv = r.TsRecv
+	return nil
+}
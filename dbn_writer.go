@@ -0,0 +1,328 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// RecordEncoder is a Record which can encode itself in the raw DBN layout.
+type RecordEncoder interface {
+	RSize() uint16
+	Encode_Raw([]byte) error
+}
+
+// DbnWriter writes a raw DBN stream; it is the inverse of DbnScanner.
+// The Metadata is written upon creation, followed by each written record.
+// Records are encoded in the layout of the Metadata's version, so V3 StatMsg
+// and InstrumentDefMsg are downgraded when writing V1/V2 streams, and V1/V2
+// records, as from DecodeAny, are upgraded when writing newer streams.
+// DbnWriter implements Visitor, so scanners may Visit directly into it.
+type DbnWriter struct {
+	writer   io.Writer // the destination we push data to
+	metadata *Metadata // the metadata for the stream
+	buffer   []byte    // scratch buffer for encoding
+}
+
+// NewDbnWriter creates a new dbn.DbnWriter, writing `metadata` to `writer`.
+// Returns any error writing the metadata.
+func NewDbnWriter(writer io.Writer, metadata *Metadata) (*DbnWriter, error) {
+	if metadata == nil {
+		return nil, ErrNoMetadata
+	}
+	if err := metadata.Write(writer); err != nil {
+		return nil, err
+	}
+	return &DbnWriter{
+		writer:   writer,
+		metadata: metadata,
+		buffer:   make([]byte, DEFAULT_SCRATCH_BUFFER_SIZE),
+	}, nil
+}
+
+// Metadata returns the metadata for the stream.
+func (w *DbnWriter) Metadata() *Metadata {
+	return w.metadata
+}
+
+// Write encodes and writes a record to the stream.
+// If the Metadata has TsOut set, the record is followed by an undefined `ts_out`, so the
+// stream stays well-formed; use WriteWithTsOut to write its actual `ts_out`.
+// Returns any error.
+func (w *DbnWriter) Write(record RecordEncoder) error {
+	if w.metadata.TsOut != 0 {
+		return w.WriteWithTsOut(record, UNDEF_TIMESTAMP)
+	}
+	b, err := w.encode(record)
+	if err != nil {
+		return err
+	}
+	return w.WriteRaw(b)
+}
+
+// WriteWithTsOut encodes and writes a record followed by its gateway send timestamp, `ts_out`,
+// as in streams whose Metadata has TsOut set.  The record's length includes the `ts_out`.
+// Returns any error.
+func (w *DbnWriter) WriteWithTsOut(record RecordEncoder, tsOut uint64) error {
+	b, err := w.encode(record)
+	if err != nil {
+		return err
+	}
+	size := len(b)
+	b = w.buffer[:size+TsOut_Size]
+	binary.LittleEndian.PutUint64(b[size:], tsOut)
+	b[0] = uint8(len(b) / 4)
+	return w.WriteRaw(b)
+}
+
+// encode encodes the record into the scratch buffer in the layout of the Metadata's version,
+// converting records of other versions' layouts, and returns the encoded bytes.
+func (w *DbnWriter) encode(record RecordEncoder) ([]byte, error) {
+	switch r := record.(type) {
+	case *SymbolMappingMsgV1:
+		record = symbolMappingMsgV1ToV2(r)
+	case *StatMsgV2:
+		if w.metadata.VersionNum >= HeaderVersion3 {
+			record = statMsgV2ToV3(r)
+		}
+	case *InstrumentDefMsgV2:
+		if w.metadata.VersionNum >= HeaderVersion3 {
+			record = instrumentDefMsgV2ToV3(r)
+		}
+	case *ErrorMsgV1:
+		if w.metadata.VersionNum >= HeaderVersion2 {
+			record = errorMsgV1ToV2(r)
+		}
+	case *SystemMsgV1:
+		if w.metadata.VersionNum >= HeaderVersion2 {
+			record = systemMsgV1ToV2(r)
+		}
+	}
+	switch r := record.(type) {
+	case *SymbolMappingMsgV2:
+		n, err := SymbolMappingMsgEncodeRaw(r, w.buffer, w.metadata.SymbolCstrLen)
+		if err != nil {
+			return nil, err
+		}
+		return w.buffer[:n], nil
+	case *StatMsgV3:
+		if w.metadata.VersionNum < HeaderVersion3 {
+			record = statMsgV3ToV2(r)
+		}
+	case *InstrumentDefMsgV3:
+		switch w.metadata.VersionNum {
+		case HeaderVersion1:
+			return nil, errInstrumentDefMsgV1
+		case HeaderVersion2:
+			record = instrumentDefMsgV3ToV2(r)
+		}
+	case *InstrumentDefMsgV2:
+		if w.metadata.VersionNum == HeaderVersion1 {
+			return nil, errInstrumentDefMsgV1
+		}
+	case *ErrorMsg:
+		if w.metadata.VersionNum == HeaderVersion1 {
+			record = errorMsgToV1(r)
+		}
+	case *SystemMsg:
+		if w.metadata.VersionNum == HeaderVersion1 {
+			record = systemMsgToV1(r)
+		}
+	}
+	size := int(record.RSize())
+	if err := record.Encode_Raw(w.buffer[:size]); err != nil {
+		return nil, err
+	}
+	return w.buffer[:size], nil
+}
+
+// WriteRaw writes an already-encoded record, such as from DbnScanner.GetLastRecord, to the stream.
+// The record must match the layout of the stream's Metadata version.
+func (w *DbnWriter) WriteRaw(b []byte) error {
+	if len(b) < RHeader_Size {
+		return ErrNoRecord
+	}
+	if recordLen := 4 * int(b[0]); len(b) < recordLen {
+		return ErrMalformedRecord
+	} else {
+		b = b[:recordLen]
+	}
+	_, err := w.writer.Write(b)
+	return err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Visitor interface
+
+func (w *DbnWriter) OnMbp0(record *Mbp0Msg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnMbp1(record *Mbp1Msg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnMbp10(record *Mbp10Msg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnMbo(record *MboMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnOhlcv(record *OhlcvMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnCmbp1(record *Cmbp1Msg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnBbo(record *BboMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnImbalance(record *ImbalanceMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnStatMsg(record *StatMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnStatusMsg(record *StatusMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnInstrumentDefMsg(record *InstrumentDefMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnErrorMsg(record *ErrorMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnSystemMsg(record *SystemMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnSymbolMappingMsg(record *SymbolMappingMsg) error {
+	return w.Write(record)
+}
+
+func (w *DbnWriter) OnStreamEnd() error {
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// These convert V3 records down to the V2 layout, the inverse of the DbnScanner upgrades.
+
+// statMsgV3ToV2 converts a StatMsg to the V1/V2 layout, truncating Quantity to int32.
+func statMsgV3ToV2(v3 *StatMsgV3) *StatMsgV2 {
+	quantity := int32(v3.Quantity)
+	if v3.Quantity == StatMsgV3_UNDEF_STAT_QUANTITY {
+		quantity = StatMsgV2_UNDEF_STAT_QUANTITY
+	}
+	return &StatMsgV2{
+		Header:       v3.Header,
+		TsRecv:       v3.TsRecv,
+		TsRef:        v3.TsRef,
+		Price:        v3.Price,
+		Quantity:     quantity,
+		Sequence:     v3.Sequence,
+		TsInDelta:    v3.TsInDelta,
+		StatType:     v3.StatType,
+		ChannelID:    v3.ChannelID,
+		UpdateAction: v3.UpdateAction,
+		StatFlags:    v3.StatFlags,
+	}
+}
+
+// instrumentDefMsgV3ToV2 converts an InstrumentDefMsg to the V2 layout.
+// Leg fields are dropped, RawInstrumentID is truncated to uint32, and Asset to 7 bytes.
+// Fields removed in V3 are not recoverable and are set to their null values.
+func instrumentDefMsgV3ToV2(v3 *InstrumentDefMsgV3) *InstrumentDefMsgV2 {
+	v2 := InstrumentDefMsgV2{
+		Header:                  v3.Header,
+		TsRecv:                  v3.TsRecv,
+		MinPriceIncrement:       v3.MinPriceIncrement,
+		DisplayFactor:           v3.DisplayFactor,
+		Expiration:              v3.Expiration,
+		Activation:              v3.Activation,
+		HighLimitPrice:          v3.HighLimitPrice,
+		LowLimitPrice:           v3.LowLimitPrice,
+		MaxPriceVariation:       v3.MaxPriceVariation,
+		TradingReferencePrice:   UNDEF_PRICE,
+		UnitOfMeasureQty:        v3.UnitOfMeasureQty,
+		MinPriceIncrementAmount: v3.MinPriceIncrementAmount,
+		PriceRatio:              v3.PriceRatio,
+		StrikePrice:             v3.StrikePrice,
+		InstAttribValue:         v3.InstAttribValue,
+		UnderlyingID:            v3.UnderlyingID,
+		RawInstrumentID:         uint32(v3.RawInstrumentID),
+		MarketDepthImplied:      v3.MarketDepthImplied,
+		MarketDepth:             v3.MarketDepth,
+		MarketSegmentID:         v3.MarketSegmentID,
+		MaxTradeVol:             v3.MaxTradeVol,
+		MinLotSize:              v3.MinLotSize,
+		MinLotSizeBlock:         v3.MinLotSizeBlock,
+		MinLotSizeRoundLot:      v3.MinLotSizeRoundLot,
+		MinTradeVol:             v3.MinTradeVol,
+		ContractMultiplier:      v3.ContractMultiplier,
+		DecayQuantity:           v3.DecayQuantity,
+		OriginalContractSize:    v3.OriginalContractSize,
+		TradingReferenceDate:    math.MaxUint16,
+		ApplID:                  v3.ApplID,
+		MaturityYear:            v3.MaturityYear,
+		DecayStartDate:          v3.DecayStartDate,
+		ChannelID:               v3.ChannelID,
+		Currency:                v3.Currency,
+		SettlCurrency:           v3.SettlCurrency,
+		Secsubtype:              v3.Secsubtype,
+		RawSymbol:               v3.RawSymbol,
+		Group:                   v3.Group,
+		Exchange:                v3.Exchange,
+		Cfi:                     v3.Cfi,
+		SecurityType:            v3.SecurityType,
+		UnitOfMeasure:           v3.UnitOfMeasure,
+		Underlying:              v3.Underlying,
+		StrikePriceCurrency:     v3.StrikePriceCurrency,
+		InstrumentClass:         v3.InstrumentClass,
+		MatchAlgorithm:          v3.MatchAlgorithm,
+		MdSecurityTradingStatus: math.MaxUint8,
+		MainFraction:            v3.MainFraction,
+		PriceDisplayFormat:      v3.PriceDisplayFormat,
+		SettlPrice_type:         math.MaxUint8,
+		SubFraction:             v3.SubFraction,
+		UnderlyingProduct:       v3.UnderlyingProduct,
+		SecurityUpdateAction:    v3.SecurityUpdateAction,
+		MaturityMonth:           v3.MaturityMonth,
+		MaturityDay:             v3.MaturityDay,
+		MaturityWeek:            v3.MaturityWeek,
+		UserDefinedInstrument:   v3.UserDefinedInstrument,
+		ContractMultiplierUnit:  v3.ContractMultiplierUnit,
+		FlowScheduleType:        v3.FlowScheduleType,
+		TickRule:                v3.TickRule,
+	}
+	// Asset: V3 is [11]byte, V2 is [7]byte — copy the larger into the smaller
+	copy(v2.Asset[:], v3.Asset[:])
+	return &v2
+}
+
+// errorMsgToV1 converts an ErrorMsg to the V1 layout, truncating its message and dropping its code.
+func errorMsgToV1(r *ErrorMsg) *ErrorMsgV1 {
+	v1 := ErrorMsgV1{Header: r.Header}
+	copy(v1.Error[:len(v1.Error)-1], r.Error[:])
+	return &v1
+}
+
+// systemMsgToV1 converts a SystemMsg to the V1 layout, truncating its message and dropping its code.
+func systemMsgToV1(r *SystemMsg) *SystemMsgV1 {
+	v1 := SystemMsgV1{Header: r.Header}
+	copy(v1.Message[:len(v1.Message)-1], r.Message[:])
+	return &v1
+}
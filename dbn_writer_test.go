@@ -0,0 +1,139 @@
+package dbn_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// readRawRecords returns the metadata and copies of each raw record of a DBN stream.
+func readRawRecords(reader io.Reader) (*dbn.Metadata, [][]byte, error) {
+	scanner := dbn.NewDbnScanner(reader)
+	records := make([][]byte, 0)
+	for scanner.Next() {
+		records = append(records, bytes.Clone(scanner.GetLastRecord()[:scanner.GetLastSize()]))
+	}
+	if err := scanner.Error(); err != io.EOF {
+		return nil, nil, err
+	}
+	metadata, err := scanner.Metadata()
+	return metadata, records, err
+}
+
+var _ = Describe("DbnWriter", func() {
+	Context("round trips", func() {
+		DescribeTable("should re-encode visited records byte-for-byte",
+			func(filename string) {
+				reader, closer, err := dbn.MakeCompressedReader("./tests/data/"+filename, false)
+				Expect(err).To(BeNil())
+				defer closer.Close()
+
+				// Visit every record into a DbnWriter
+				var buf bytes.Buffer
+				scanner := dbn.NewDbnScanner(reader)
+				metadata, err := scanner.Metadata()
+				Expect(err).To(BeNil())
+				writer, err := dbn.NewDbnWriter(&buf, metadata)
+				Expect(err).To(BeNil())
+				Expect(visitAll(scanner, writer)).To(Succeed())
+
+				// Compare against the original raw records
+				origReader, origCloser, err := dbn.MakeCompressedReader("./tests/data/"+filename, false)
+				Expect(err).To(BeNil())
+				defer origCloser.Close()
+				origMetadata, origRecords, err := readRawRecords(origReader)
+				Expect(err).To(BeNil())
+				Expect(origRecords).ToNot(BeEmpty())
+
+				newMetadata, newRecords, err := readRawRecords(&buf)
+				Expect(err).To(BeNil())
+				Expect(newMetadata).To(Equal(origMetadata))
+				Expect(newRecords).To(Equal(origRecords))
+			},
+			Entry("v1 ohlcv-1s", "test_data.ohlcv-1s.v1.dbn"),
+			Entry("v1 mbp-10", "test_data.mbp-10.v1.dbn.zst"),
+			Entry("v1 statistics", "test_data.statistics.v1.dbn.zst"),
+			Entry("v2 mbo", "test_data.mbo.v2.dbn.zst"),
+			Entry("v2 statistics", "test_data.statistics.v2.dbn.zst"),
+			Entry("v2 imbalance", "test_data.imbalance.v2.dbn.zst"),
+			Entry("v3 trades", "test_data.trades.v3.dbn.zst"),
+			Entry("v3 mbp-1", "test_data.mbp-1.v3.dbn.zst"),
+			Entry("v3 mbp-10", "test_data.mbp-10.v3.dbn.zst"),
+			Entry("v3 cmbp-1", "test_data.cmbp-1.v3.dbn.zst"),
+			Entry("v3 bbo-1s", "test_data.bbo-1s.v3.dbn.zst"),
+			Entry("v3 statistics", "test_data.statistics.v3.dbn.zst"),
+			Entry("v3 status", "test_data.status.v3.dbn.zst"),
+			Entry("v3 definition", "test_data.definition.v3.dbn.zst"),
+			// V2 definitions are lossy through Visit, as V3 dropped some fields
+		)
+
+		It("should preserve the metadata version", func() {
+			for _, version := range []uint8{dbn.HeaderVersion1, dbn.HeaderVersion2, dbn.HeaderVersion3} {
+				var buf bytes.Buffer
+				_, err := dbn.NewDbnWriter(&buf, &dbn.Metadata{VersionNum: version, Dataset: "XNAS.ITCH"})
+				Expect(err).To(BeNil())
+				metadata, err := dbn.ReadMetadata(&buf)
+				Expect(err).To(BeNil())
+				Expect(metadata.VersionNum).To(Equal(version))
+				Expect(metadata.Dataset).To(Equal("XNAS.ITCH"))
+			}
+		})
+
+		It("should write an undefined ts_out when the metadata has TsOut", func() {
+			var buf bytes.Buffer
+			writer, err := dbn.NewDbnWriter(&buf, &dbn.Metadata{VersionNum: dbn.HeaderVersion3, Dataset: "XNAS.ITCH", TsOut: 1})
+			Expect(err).To(BeNil())
+			trade := dbn.Mbp0Msg{Header: dbn.RHeader{RType: dbn.RType_Mbp0, InstrumentID: 7}, Price: 100, Size: 2}
+			Expect(writer.Write(&trade)).To(Succeed())
+			Expect(writer.WriteWithTsOut(&trade, 1234)).To(Succeed())
+
+			scanner := dbn.NewDbnScanner(&buf)
+			for _, want := range []uint64{dbn.UNDEF_TIMESTAMP, 1234} {
+				Expect(scanner.Next()).To(BeTrue())
+				tsOut, ok := scanner.GetLastTsOut()
+				Expect(ok).To(BeTrue())
+				Expect(tsOut).To(Equal(want))
+				record, err := dbn.DbnScannerDecode[dbn.Mbp0Msg](scanner)
+				Expect(err).To(BeNil())
+				Expect(record.Price).To(Equal(trade.Price))
+				Expect(record.Header.InstrumentID).To(Equal(trade.Header.InstrumentID))
+			}
+			Expect(scanner.Next()).To(BeFalse())
+			Expect(scanner.Error()).To(Equal(io.EOF))
+		})
+
+		It("should convert JSON to DBN", func() {
+			metaFile, err := os.Open("./tests/data/test_data.ohlcv-1s.meta.json")
+			Expect(err).To(BeNil())
+			defer metaFile.Close()
+			metadata, err := dbn.ReadJsonMetadata(metaFile)
+			Expect(err).To(BeNil())
+
+			jsonFile, err := os.Open("./tests/data/test_data.ohlcv-1s.json")
+			Expect(err).To(BeNil())
+			defer jsonFile.Close()
+
+			var buf bytes.Buffer
+			writer, err := dbn.NewDbnWriter(&buf, metadata)
+			Expect(err).To(BeNil())
+			scanner := dbn.NewJsonScanner(jsonFile)
+			for scanner.Next() {
+				Expect(scanner.Visit(writer)).To(Succeed())
+			}
+			Expect(scanner.Error()).To(BeNil())
+
+			dbnFile, err := os.Open("./tests/data/test_data.ohlcv-1s.dbn")
+			Expect(err).To(BeNil())
+			defer dbnFile.Close()
+			_, origRecords, err := readRawRecords(dbnFile)
+			Expect(err).To(BeNil())
+			_, newRecords, err := readRawRecords(&buf)
+			Expect(err).To(BeNil())
+			Expect(newRecords).To(Equal(origRecords))
+		})
+	})
+})
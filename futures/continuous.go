@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_futures
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// RollRule is the rule by which a continuous symbol ranks contracts.
+type RollRule byte
+
+const (
+	// RollRule_Calendar ranks contracts by expiration.
+	RollRule_Calendar RollRule = 'c'
+	// RollRule_OpenInterest ranks contracts by open interest.
+	RollRule_OpenInterest RollRule = 'n'
+	// RollRule_Volume ranks contracts by volume.
+	RollRule_Volume RollRule = 'v'
+)
+
+// String returns the name of the RollRule.
+func (r RollRule) String() string {
+	switch r {
+	case RollRule_Calendar:
+		return "calendar"
+	case RollRule_OpenInterest:
+		return "open_interest"
+	case RollRule_Volume:
+		return "volume"
+	default:
+		return fmt.Sprintf("RollRule(%d)", byte(r))
+	}
+}
+
+// ContinuousSymbol is a Databento continuous contract symbol, such as "ES.c.0" for the front
+// ES contract by expiration, or "CL.v.1" for the second CL contract by volume.
+// These are resolved with SType_Continuous.
+type ContinuousSymbol struct {
+	Root string   // Root symbol, such as "ES"
+	Rule RollRule // Rule ranking the contracts
+	Rank int      // Rank of the contract, 0 for the front
+}
+
+// ParseContinuous parses a continuous symbol, such as "ES.c.0".
+func ParseContinuous(symbol string) (ContinuousSymbol, error) {
+	parts := strings.Split(symbol, ".")
+	if len(parts) != 3 || parts[0] == "" || len(parts[1]) != 1 {
+		return ContinuousSymbol{}, fmt.Errorf("invalid continuous symbol '%s': expected ROOT.RULE.RANK", symbol)
+	}
+	rule := RollRule(parts[1][0])
+	if rule != RollRule_Calendar && rule != RollRule_OpenInterest && rule != RollRule_Volume {
+		return ContinuousSymbol{}, fmt.Errorf("invalid continuous symbol '%s': rule must be c, n, or v", symbol)
+	}
+	rank, err := strconv.Atoi(parts[2])
+	if err != nil || rank < 0 || strings.HasPrefix(parts[2], "+") {
+		return ContinuousSymbol{}, fmt.Errorf("invalid continuous symbol '%s': rank must be a non-negative integer", symbol)
+	}
+	return ContinuousSymbol{Root: parts[0], Rule: rule, Rank: rank}, nil
+}
+
+// String returns the continuous symbol, such as "ES.c.0".
+func (c ContinuousSymbol) String() string {
+	return fmt.Sprintf("%s.%c.%d", c.Root, byte(c.Rule), c.Rank)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ParentSymbol is a Databento parent symbol, such as "ES.FUT" for all the futures of the ES
+// product, or "ES.OPT" for all its options.  These are resolved with SType_Parent.
+type ParentSymbol struct {
+	Root    string // Root symbol, such as "ES"
+	Options bool   // True for "OPT", false for "FUT"
+}
+
+// ParseParent parses a parent symbol, such as "ES.FUT" or "ES.OPT".
+func ParseParent(symbol string) (ParentSymbol, error) {
+	root, kind, found := strings.Cut(symbol, ".")
+	if !found || root == "" || (kind != "FUT" && kind != "OPT") {
+		return ParentSymbol{}, fmt.Errorf("invalid parent symbol '%s': expected ROOT.FUT or ROOT.OPT", symbol)
+	}
+	return ParentSymbol{Root: root, Options: kind == "OPT"}, nil
+}
+
+// String returns the parent symbol, such as "ES.FUT".
+func (p ParentSymbol) String() string {
+	if p.Options {
+		return p.Root + ".OPT"
+	}
+	return p.Root + ".FUT"
+}
+
+// Matches returns true if the definition is a future, or option, of the parent's product, including spreads.
+func (p ParentSymbol) Matches(def *dbn.InstrumentDefMsgV3) bool {
+	if dbn.TrimNullBytes(def.Asset[:]) != p.Root {
+		return false
+	}
+	class := dbn.InstrumentClass(def.InstrumentClass)
+	if p.Options {
+		return class.IsOption()
+	}
+	return class.IsFuture()
+}
+
+// ListContracts returns the outright contracts of the parent in effect at `timestamp`, excluding
+// spreads, sorted by expiration and then raw symbol.
+func ListContracts(im *dbn.InstrumentMaster, parent ParentSymbol, timestamp uint64) []*dbn.InstrumentDefMsgV3 {
+	defs := im.Select(timestamp, func(def *dbn.InstrumentDefMsgV3) bool {
+		return parent.Matches(def) && !dbn.InstrumentClass(def.InstrumentClass).IsSpread()
+	})
+	slices.SortStableFunc(defs, func(a, b *dbn.InstrumentDefMsgV3) int {
+		if c := cmp.Compare(a.Expiration, b.Expiration); c != 0 {
+			return c
+		}
+		return cmp.Compare(dbn.TrimNullBytes(a.RawSymbol[:]), dbn.TrimNullBytes(b.RawSymbol[:]))
+	})
+	return defs
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_futures
+
+import (
+	"slices"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Cycle is the sorted delivery months listed for a product.
+// An empty Cycle lists every month, as CycleMonthly.
+type Cycle []time.Month
+
+var (
+	// CycleMonthly lists every month.
+	CycleMonthly = Cycle{time.January, time.February, time.March, time.April, time.May, time.June,
+		time.July, time.August, time.September, time.October, time.November, time.December}
+	// CycleQuarterly lists March, June, September, and December (HMUZ), as equity index and rates futures.
+	CycleQuarterly = Cycle{time.March, time.June, time.September, time.December}
+	// CycleGold lists February, April, June, August, October, and December (GJMQVZ).
+	CycleGold = Cycle{time.February, time.April, time.June, time.August, time.October, time.December}
+)
+
+// ParseCycle returns the Cycle of a string of month codes, such as "HMUZ".
+// Returns false if any character is not a month code.
+func ParseCycle(codes string) (Cycle, bool) {
+	cycle := make(Cycle, 0, len(codes))
+	for i := 0; i < len(codes); i++ {
+		month, ok := MonthFromCode(codes[i])
+		if !ok {
+			return nil, false
+		}
+		cycle = append(cycle, month)
+	}
+	slices.Sort(cycle)
+	return slices.Compact(cycle), true
+}
+
+// String returns the cycle's month codes, such as "HMUZ".
+func (c Cycle) String() string {
+	codes := make([]byte, len(c))
+	for i, month := range c {
+		codes[i] = MonthCode(month)
+	}
+	return string(codes)
+}
+
+// Contains returns true if the cycle lists the month.
+func (c Cycle) Contains(month time.Month) bool {
+	return len(c) == 0 || slices.Contains(c, month)
+}
+
+// Next returns the first year and month of the cycle after the given one.
+func (c Cycle) Next(year int, month time.Month) (int, time.Month) {
+	if len(c) == 0 {
+		c = CycleMonthly
+	}
+	for _, m := range c {
+		if m > month {
+			return year, m
+		}
+	}
+	return year + 1, c[0]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ExpiryRule returns the last trading date, as YYYYMMDD, of the contract of a delivery month.
+// Business days are weekdays; exchange holidays are not considered, so definitions'
+// `expiration` should be preferred when available.
+type ExpiryRule func(year int, month time.Month) uint32
+
+// NthWeekday returns an ExpiryRule of the nth weekday of the delivery month, such as the third Friday.
+// Business days before it are subtracted, such as 2 for two business days before the third Wednesday.
+func NthWeekday(n int, weekday time.Weekday, businessDaysBefore int) ExpiryRule {
+	return func(year int, month time.Month) uint32 {
+		day := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		day = day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7+7*(n-1))
+		return dbn.TimeToYMD(addBusinessDays(day, -businessDaysBefore))
+	}
+}
+
+// LastBusinessDay returns an ExpiryRule of the last business day of the delivery month, less some business days.
+func LastBusinessDay(businessDaysBefore int) ExpiryRule {
+	return func(year int, month time.Month) uint32 {
+		day := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		for !isBusinessDay(day) {
+			day = day.AddDate(0, 0, -1)
+		}
+		return dbn.TimeToYMD(addBusinessDays(day, -businessDaysBefore))
+	}
+}
+
+// BusinessDaysBeforeDayOfPriorMonth returns an ExpiryRule of some business days before a calendar day
+// of the month before delivery, or before the business day preceding it, as CME's crude oil:
+// 3 business days before the 25th calendar day of the prior month.
+func BusinessDaysBeforeDayOfPriorMonth(businessDaysBefore int, dayOfMonth int) ExpiryRule {
+	return func(year int, month time.Month) uint32 {
+		day := time.Date(year, month-1, dayOfMonth, 0, 0, 0, 0, time.UTC)
+		for !isBusinessDay(day) {
+			day = day.AddDate(0, 0, -1)
+		}
+		return dbn.TimeToYMD(addBusinessDays(day, -businessDaysBefore))
+	}
+}
+
+func isBusinessDay(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// addBusinessDays returns the day moved by n business days, backward if n is negative.
+func addBusinessDays(day time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		day = day.AddDate(0, 0, step)
+		if isBusinessDay(day) {
+			n--
+		}
+	}
+	return day
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Product describes the listing and expiry of a futures product.
+type Product struct {
+	Root   string     // Root symbol, such as "ES"
+	Cycle  Cycle      // Delivery months listed
+	Expiry ExpiryRule // Last trading date of a delivery month
+}
+
+// Products are the listings of common CME Group futures.
+var Products = map[string]Product{
+	"ES":  {Root: "ES", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"MES": {Root: "MES", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"NQ":  {Root: "NQ", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"MNQ": {Root: "MNQ", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"RTY": {Root: "RTY", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"YM":  {Root: "YM", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Friday, 0)},
+	"ZN":  {Root: "ZN", Cycle: CycleQuarterly, Expiry: LastBusinessDay(7)},
+	"ZF":  {Root: "ZF", Cycle: CycleQuarterly, Expiry: LastBusinessDay(0)},
+	"ZB":  {Root: "ZB", Cycle: CycleQuarterly, Expiry: LastBusinessDay(7)},
+	"6E":  {Root: "6E", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Wednesday, 2)},
+	"6J":  {Root: "6J", Cycle: CycleQuarterly, Expiry: NthWeekday(3, time.Wednesday, 2)},
+	"CL":  {Root: "CL", Cycle: CycleMonthly, Expiry: BusinessDaysBeforeDayOfPriorMonth(3, 25)},
+	"GC":  {Root: "GC", Cycle: CycleGold, Expiry: LastBusinessDay(2)},
+}
+
+// Expiration returns the product's last trading date, as YYYYMMDD, of a contract.
+func (p Product) Expiration(c Contract) uint32 {
+	return p.Expiry(c.Year, c.Month)
+}
+
+// FrontContract returns the first listed contract trading on the date, as YYYYMMDD,
+// which is the first whose expiration is on or after it.
+func (p Product) FrontContract(ymd uint32) Contract {
+	year, month := int(ymd/10000), time.Month((ymd/100)%100)
+	if !p.Cycle.Contains(month) {
+		year, month = p.Cycle.Next(year, month)
+	}
+	c := Contract{Root: p.Root, Year: year, Month: month}
+	for p.Expiration(c) < ymd {
+		c.Year, c.Month = p.Cycle.Next(c.Year, c.Month)
+	}
+	return c
+}
+
+// Contracts returns the `count` listed contracts trading on the date, as YYYYMMDD, starting with the front.
+func (p Product) Contracts(ymd uint32, count int) []Contract {
+	contracts := make([]Contract, 0, count)
+	c := p.FrontContract(ymd)
+	for len(contracts) < count {
+		contracts = append(contracts, c)
+		c.Year, c.Month = p.Cycle.Next(c.Year, c.Month)
+	}
+	return contracts
+}
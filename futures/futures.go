@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Neomantra Corp
+
+// Package dbn_futures handles futures symbology: month codes, contract symbols such as "ESZ4",
+// expiry cycles, Databento's continuous symbols such as "ES.c.0", parent symbols such as "ES.FUT",
+// roll calendars derived from definitions or symbology resolutions, and back-adjusted continuous series.
+package dbn_futures
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// monthCodes are the futures month codes, indexed by month - 1.
+const monthCodes = "FGHJKMNQUVXZ"
+
+// MonthCode returns the futures month code of a month, such as 'Z' for December, or 0 if invalid.
+func MonthCode(month time.Month) byte {
+	if month < time.January || month > time.December {
+		return 0
+	}
+	return monthCodes[month-1]
+}
+
+// MonthFromCode returns the month of a futures month code, such as December for 'Z'.
+// Returns false if it is not a month code.
+func MonthFromCode(code byte) (time.Month, bool) {
+	i := strings.IndexByte(monthCodes, code)
+	if i < 0 {
+		return 0, false
+	}
+	return time.Month(i + 1), true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Contract is a futures contract of a root symbol and delivery month, such as "ESZ4".
+type Contract struct {
+	Root  string     // Root symbol, such as "ES"
+	Year  int        // Delivery year, such as 2024
+	Month time.Month // Delivery month
+}
+
+// ParseContract parses a futures contract symbol of a root, a month code, and a year of 1, 2,
+// or 4 digits, such as "ESZ4", "ESZ24", or "ESZ2024".  Years of 1 or 2 digits are resolved to
+// the nearest such year to `refYear`, such as the year of the data, preferring later years.
+func ParseContract(symbol string, refYear int) (Contract, error) {
+	n := len(symbol)
+	digits := 0
+	for digits < n && isDigit(symbol[n-1-digits]) {
+		digits++
+	}
+	if digits != 1 && digits != 2 && digits != 4 {
+		return Contract{}, fmt.Errorf("invalid futures contract '%s': year must have 1, 2, or 4 digits", symbol)
+	}
+	if n < digits+2 {
+		return Contract{}, fmt.Errorf("invalid futures contract '%s': missing root or month", symbol)
+	}
+	month, ok := MonthFromCode(symbol[n-digits-1])
+	if !ok {
+		return Contract{}, fmt.Errorf("invalid futures contract '%s': unknown month code '%c'", symbol, symbol[n-digits-1])
+	}
+	root := symbol[:n-digits-1]
+	if strings.ContainsAny(root, " -:.") {
+		return Contract{}, fmt.Errorf("invalid futures contract '%s': not an outright", symbol)
+	}
+	year, _ := strconv.Atoi(symbol[n-digits:])
+	if digits < 4 {
+		year = resolveYear(year, digits, refYear)
+	}
+	return Contract{Root: root, Year: year, Month: month}, nil
+}
+
+// resolveYear returns the year ending in `year`'s `digits` digits which is nearest to `refYear`,
+// preferring later years.
+func resolveYear(year int, digits int, refYear int) int {
+	period := 10
+	if digits == 2 {
+		period = 100
+	}
+	candidate := refYear - refYear%period + year
+	switch {
+	case candidate-refYear > period/2:
+		candidate -= period
+	case refYear-candidate >= period/2:
+		candidate += period
+	}
+	return candidate
+}
+
+// String returns the contract's symbol with a one-digit year, as CME raw symbols, such as "ESZ4".
+func (c Contract) String() string {
+	return c.Symbol(1)
+}
+
+// Symbol returns the contract's symbol with a year of 1, 2, or 4 digits, such as "ESZ24".
+func (c Contract) Symbol(yearDigits int) string {
+	switch yearDigits {
+	case 1:
+		return fmt.Sprintf("%s%c%d", c.Root, MonthCode(c.Month), c.Year%10)
+	case 2:
+		return fmt.Sprintf("%s%c%02d", c.Root, MonthCode(c.Month), c.Year%100)
+	default:
+		return fmt.Sprintf("%s%c%04d", c.Root, MonthCode(c.Month), c.Year)
+	}
+}
+
+// MonthCode returns the contract's month code.
+func (c Contract) MonthCode() byte {
+	return MonthCode(c.Month)
+}
+
+// Compare returns -1, 0, or 1 as the contract delivers before, with, or after `o`, ignoring the root.
+func (c Contract) Compare(o Contract) int {
+	a, b := c.Year*12+int(c.Month), o.Year*12+int(o.Month)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
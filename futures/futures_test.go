@@ -0,0 +1,515 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_futures
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_hist "github.com/NimbleMarkets/dbn-go/hist"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Test Launcher
+func TestDbnFutures(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dbn-go futures suite")
+}
+
+// newFutureDefinition returns the definition of an outright future expiring at 13:30 UTC on the date.
+func newFutureDefinition(instrumentID uint32, rawSymbol string, expirationYMD int) *dbn.InstrumentDefMsgV3 {
+	def := &dbn.InstrumentDefMsgV3{
+		Header:          dbn.RHeader{RType: dbn.RType_InstrumentDef, InstrumentID: instrumentID, TsEvent: 100},
+		TsRecv:          100,
+		Expiration:      ymdNanos(expirationYMD) + uint64(13*time.Hour+30*time.Minute),
+		InstrumentClass: byte(dbn.InstrumentClass_Future),
+		StrikePrice:     dbn.UNDEF_PRICE,
+	}
+	copy(def.RawSymbol[:], rawSymbol)
+	copy(def.Asset[:], "ES")
+	return def
+}
+
+func ymdNanos(ymd int) uint64 {
+	return uint64(dbn.YMDToTime(ymd, time.UTC).UnixNano())
+}
+
+// newDailyBar returns a daily bar whose prices are all `px`.
+func newDailyBar(instrumentID uint32, ymd int, px int64, volume uint64) *dbn.OhlcvMsg {
+	return &dbn.OhlcvMsg{
+		Header: dbn.RHeader{RType: dbn.RType_Ohlcv1D, PublisherID: 1, InstrumentID: instrumentID, TsEvent: ymdNanos(ymd)},
+		Open:   px, High: px, Low: px, Close: px, Volume: volume,
+	}
+}
+
+// buildSeries visits the records with a SeriesBuilder of the options and builds its series.
+func buildSeries(opts SeriesOptions, records ...dbn.Record) *Series {
+	builder, err := NewSeriesBuilder(opts)
+	Expect(err).To(BeNil())
+	for _, record := range records {
+		switch r := record.(type) {
+		case *dbn.OhlcvMsg:
+			Expect(builder.OnOhlcv(r)).To(Succeed())
+		case *dbn.Mbp0Msg:
+			Expect(builder.OnMbp0(r)).To(Succeed())
+		case *dbn.StatMsg:
+			Expect(builder.OnStatMsg(r)).To(Succeed())
+		case *dbn.InstrumentDefMsg:
+			Expect(builder.OnInstrumentDefMsg(r)).To(Succeed())
+		}
+	}
+	series, err := builder.Build()
+	Expect(err).To(BeNil())
+	return series
+}
+
+func seriesCloses(series *Series) []int64 {
+	closes := make([]int64, len(series.Bars))
+	for i, bar := range series.Bars {
+		closes[i] = bar.Close
+	}
+	return closes
+}
+
+var _ = Describe("DbnFutures", func() {
+	Context("month codes", func() {
+		It("should map months to codes and back", func() {
+			Expect(MonthCode(time.January)).To(Equal(byte('F')))
+			Expect(MonthCode(time.December)).To(Equal(byte('Z')))
+			Expect(MonthCode(0)).To(Equal(byte(0)))
+			for month := time.January; month <= time.December; month++ {
+				m, ok := MonthFromCode(MonthCode(month))
+				Expect(ok).To(BeTrue())
+				Expect(m).To(Equal(month))
+			}
+			_, ok := MonthFromCode('A')
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("contracts", func() {
+		It("should parse contract symbols", func() {
+			c, err := ParseContract("ESZ4", 2024)
+			Expect(err).To(BeNil())
+			Expect(c).To(Equal(Contract{Root: "ES", Year: 2024, Month: time.December}))
+			Expect(c.String()).To(Equal("ESZ4"))
+			Expect(c.Symbol(2)).To(Equal("ESZ24"))
+			Expect(c.Symbol(4)).To(Equal("ESZ2024"))
+
+			c, err = ParseContract("6EH25", 2024)
+			Expect(err).To(BeNil())
+			Expect(c).To(Equal(Contract{Root: "6E", Year: 2025, Month: time.March}))
+			c, err = ParseContract("CLF2030", 2024)
+			Expect(err).To(BeNil())
+			Expect(c.Year).To(Equal(2030))
+		})
+
+		It("should resolve short years to the nearest year", func() {
+			for _, tc := range []struct {
+				symbol  string
+				refYear int
+				year    int
+			}{
+				{"ESZ9", 2024, 2029},
+				{"ESZ0", 2024, 2020},
+				{"ESZ0", 2025, 2030},
+				{"ESZ8", 2031, 2028},
+				{"ESZ99", 2024, 1999},
+				{"ESZ50", 2024, 2050},
+			} {
+				c, err := ParseContract(tc.symbol, tc.refYear)
+				Expect(err).To(BeNil())
+				Expect(c.Year).To(Equal(tc.year), tc.symbol)
+			}
+		})
+
+		It("should reject invalid symbols", func() {
+			for _, symbol := range []string{"", "ES", "Z4", "ESA4", "ESZ", "ESZ123", "ESZ4-ESH5", "ES.c.0"} {
+				_, err := ParseContract(symbol, 2024)
+				Expect(err).NotTo(BeNil(), symbol)
+			}
+		})
+
+		It("should compare delivery months", func() {
+			z4, _ := ParseContract("ESZ4", 2024)
+			h5, _ := ParseContract("ESH5", 2024)
+			Expect(z4.Compare(h5)).To(Equal(-1))
+			Expect(h5.Compare(z4)).To(Equal(1))
+			Expect(z4.Compare(z4)).To(Equal(0))
+		})
+	})
+
+	Context("cycles and expiries", func() {
+		It("should parse and step cycles", func() {
+			cycle, ok := ParseCycle("ZHUM")
+			Expect(ok).To(BeTrue())
+			Expect(cycle).To(Equal(CycleQuarterly))
+			Expect(cycle.String()).To(Equal("HMUZ"))
+			year, month := cycle.Next(2024, time.December)
+			Expect(year).To(Equal(2025))
+			Expect(month).To(Equal(time.March))
+			_, ok = ParseCycle("HMUA")
+			Expect(ok).To(BeFalse())
+
+			// An empty cycle lists every month
+			year, month = Cycle{}.Next(2024, time.December)
+			Expect(year).To(Equal(2025))
+			Expect(month).To(Equal(time.January))
+			Expect(Cycle{}.Contains(time.May)).To(BeTrue())
+			front := Product{Root: "XX", Expiry: LastBusinessDay(0)}.FrontContract(20240515)
+			Expect(front.Month).To(Equal(time.May))
+		})
+
+		It("should compute CME expirations", func() {
+			for _, tc := range []struct {
+				symbol     string
+				expiration uint32
+			}{
+				{"ESZ4", 20241220}, // third Friday
+				{"ESH5", 20250321}, // third Friday
+				{"CLN4", 20240620}, // 3 business days before June 25
+				{"ZNH5", 20250320}, // 7 business days before the last business day
+				{"6EZ4", 20241216}, // 2 business days before the third Wednesday
+				{"GCQ4", 20240828}, // third-last business day
+			} {
+				c, err := ParseContract(tc.symbol, 2024)
+				Expect(err).To(BeNil())
+				Expect(Products[c.Root].Expiration(c)).To(Equal(tc.expiration), tc.symbol)
+			}
+		})
+
+		It("should list the contracts trading on a date", func() {
+			es := Products["ES"]
+			Expect(es.FrontContract(20241220).String()).To(Equal("ESZ4"))
+			Expect(es.FrontContract(20241221).String()).To(Equal("ESH5"))
+			Expect(es.FrontContract(20241105).String()).To(Equal("ESZ4"))
+
+			// Crude oil expires in the month before delivery
+			Expect(Products["CL"].FrontContract(20241125).String()).To(Equal("CLF5"))
+			contracts := Products["CL"].Contracts(20241125, 3)
+			Expect([]string{contracts[0].String(), contracts[1].String(), contracts[2].String()}).To(Equal([]string{"CLF5", "CLG5", "CLH5"}))
+		})
+	})
+
+	Context("continuous and parent symbols", func() {
+		It("should parse and format continuous symbols", func() {
+			c, err := ParseContinuous("ES.c.0")
+			Expect(err).To(BeNil())
+			Expect(c).To(Equal(ContinuousSymbol{Root: "ES", Rule: RollRule_Calendar, Rank: 0}))
+			Expect(c.String()).To(Equal("ES.c.0"))
+
+			c, err = ParseContinuous("CL.v.12")
+			Expect(err).To(BeNil())
+			Expect(c.Rule).To(Equal(RollRule_Volume))
+			Expect(c.Rank).To(Equal(12))
+			Expect(ContinuousSymbol{Root: "NQ", Rule: RollRule_OpenInterest, Rank: 1}.String()).To(Equal("NQ.n.1"))
+
+			for _, symbol := range []string{"ES", "ES.c", "ES.x.0", "ES.c.-1", "ES.c.a", ".c.0", "ES.c.0.1"} {
+				_, err := ParseContinuous(symbol)
+				Expect(err).NotTo(BeNil(), symbol)
+			}
+		})
+
+		It("should parse parent symbols and list their contracts", func() {
+			parent, err := ParseParent("ES.FUT")
+			Expect(err).To(BeNil())
+			Expect(parent).To(Equal(ParentSymbol{Root: "ES"}))
+			Expect(parent.String()).To(Equal("ES.FUT"))
+			_, err = ParseParent("ES.SPOT")
+			Expect(err).NotTo(BeNil())
+
+			im := dbn.NewInstrumentMaster()
+			im.Insert(newFutureDefinition(2, "ESH5", 20250321))
+			im.Insert(newFutureDefinition(1, "ESZ4", 20241220))
+			spread := newFutureDefinition(3, "ESZ4-ESH5", 20241220)
+			spread.InstrumentClass = byte(dbn.InstrumentClass_FutureSpread)
+			im.Insert(spread)
+			other := newFutureDefinition(4, "NQZ4", 20241220)
+			copy(other.Asset[:], "NQ")
+			im.Insert(other)
+
+			Expect(parent.Matches(spread)).To(BeTrue())
+			contracts := ListContracts(im, parent, dbn.UNDEF_TIMESTAMP)
+			Expect(contracts).To(HaveLen(2))
+			Expect(dbn.TrimNullBytes(contracts[0].RawSymbol[:])).To(Equal("ESZ4"))
+			Expect(dbn.TrimNullBytes(contracts[1].RawSymbol[:])).To(Equal("ESH5"))
+		})
+	})
+
+	Context("roll calendars", func() {
+		var defs []*dbn.InstrumentDefMsgV3
+		BeforeEach(func() {
+			defs = []*dbn.InstrumentDefMsgV3{
+				newFutureDefinition(3, "ESM5", 20250620),
+				newFutureDefinition(1, "ESZ4", 20241220),
+				newFutureDefinition(2, "ESH5", 20250321),
+			}
+		})
+
+		It("should roll the front contract at expiration", func() {
+			rc := RollCalendarFromDefinitions(defs, 0, 0)
+			Expect(rc.Periods).To(HaveLen(3))
+			Expect(rc.Periods[0].Start).To(BeZero())
+			Expect(rc.Periods[0].Symbol).To(Equal("ESZ4"))
+			Expect(rc.Periods[1].Start).To(Equal(defs[1].Expiration))
+
+			period, ok := rc.At(ymdNanos(20250101))
+			Expect(ok).To(BeTrue())
+			Expect(period.Symbol).To(Equal("ESH5"))
+			Expect(period.InstrumentID).To(Equal(uint32(2)))
+			_, ok = rc.At(ymdNanos(20250701))
+			Expect(ok).To(BeFalse())
+
+			rolls := rc.Rolls()
+			Expect(rolls).To(HaveLen(2))
+			Expect(rolls[0].From.Symbol).To(Equal("ESZ4"))
+			Expect(rolls[0].To.Symbol).To(Equal("ESH5"))
+			Expect(rolls[0].Ts).To(Equal(defs[1].Expiration))
+		})
+
+		It("should use the latest definition of each contract", func() {
+			// ESZ4's expiration was corrected by a later definition
+			corrected := newFutureDefinition(1, "ESZ4", 20241213)
+			corrected.TsRecv = 200
+			stale := newFutureDefinition(1, "ESZ4", 20241220)
+			stale.TsRecv = 50
+			rc := RollCalendarFromDefinitions(append(defs, corrected, stale), 0, 0)
+			Expect(rc.Periods).To(HaveLen(3))
+			Expect(rc.Periods[0].Symbol).To(Equal("ESZ4"))
+			Expect(rc.Periods[0].End).To(Equal(corrected.Expiration))
+			Expect(rc.Periods[1].Start).To(Equal(corrected.Expiration))
+		})
+
+		It("should roll early and by rank", func() {
+			rc := RollCalendarFromDefinitions(defs, 0, 8*24*time.Hour)
+			period, ok := rc.At(ymdNanos(20241215))
+			Expect(ok).To(BeTrue())
+			Expect(period.Symbol).To(Equal("ESH5"))
+
+			rc = RollCalendarFromDefinitions(defs, 1, 0)
+			Expect(rc.Periods).To(HaveLen(2))
+			period, ok = rc.At(ymdNanos(20241101))
+			Expect(ok).To(BeTrue())
+			Expect(period.Symbol).To(Equal("ESH5"))
+			period, ok = rc.At(ymdNanos(20250101))
+			Expect(ok).To(BeTrue())
+			Expect(period.Symbol).To(Equal("ESM5"))
+		})
+
+		It("should build from a symbology resolution", func() {
+			resolution := &dbn_hist.Resolution{
+				Mappings: map[string][]dbn_hist.MappingInterval{
+					"ES.c.0": {
+						{StartDate: "2024-12-23", EndDate: "2025-03-24", Symbol: "2"},
+						{StartDate: "2024-12-01", EndDate: "2024-12-23", Symbol: "1"},
+					},
+				},
+				StypeIn:  dbn.SType_Continuous,
+				StypeOut: dbn.SType_InstrumentId,
+			}
+			rc, err := RollCalendarFromResolution(resolution, "ES.c.0")
+			Expect(err).To(BeNil())
+			Expect(rc.Periods).To(HaveLen(2))
+			Expect(rc.Periods[0].InstrumentID).To(Equal(uint32(1)))
+			rolls := rc.Rolls()
+			Expect(rolls).To(HaveLen(1))
+			Expect(rolls[0].Ts).To(Equal(ymdNanos(20241223)))
+
+			_, err = RollCalendarFromResolution(resolution, "NQ.c.0")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Context("continuous series", func() {
+		var esz4, esh5 *dbn.InstrumentDefMsgV3
+		var bars []dbn.Record
+		BeforeEach(func() {
+			esz4 = newFutureDefinition(1, "ESZ4", 20241220)
+			esh5 = newFutureDefinition(2, "ESH5", 20250321)
+			bars = []dbn.Record{
+				newDailyBar(1, 20241219, 100, 1000), newDailyBar(2, 20241219, 150, 500),
+				newDailyBar(1, 20241220, 200, 100), newDailyBar(2, 20241220, 300, 2000),
+				newDailyBar(2, 20241221, 310, 3000),
+			}
+		})
+
+		It("should parse adjustments", func() {
+			for _, name := range []string{"none", "difference", "ratio"} {
+				adjustment, err := ParseAdjustment(name)
+				Expect(err).To(BeNil())
+				Expect(adjustment.String()).To(Equal(name))
+			}
+			_, err := ParseAdjustment("log")
+			Expect(err).NotTo(BeNil())
+			_, err = NewSeriesBuilder(SeriesOptions{BarInterval: 5 * time.Minute})
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should stitch by the calendar rule", func() {
+			symbol, _ := ParseContinuous("ES.c.0")
+			records := append([]dbn.Record{esz4, esh5}, bars...)
+			series := buildSeries(SeriesOptions{Symbol: symbol, InstrumentID: 1000}, records...)
+			Expect(seriesCloses(series)).To(Equal([]int64{100, 200, 310}))
+			Expect(series.Rolls).To(Equal([]SeriesRoll{{Ts: ymdNanos(20241221), FromID: 1, ToID: 2, FromPrice: 200, ToPrice: 300}}))
+			for _, bar := range series.Bars {
+				Expect(bar.Header.InstrumentID).To(Equal(uint32(1000)))
+				Expect(bar.Header.RType).To(Equal(dbn.RType_Ohlcv1D))
+			}
+
+			series = buildSeries(SeriesOptions{Symbol: symbol, Adjustment: Adjustment_Difference}, records...)
+			Expect(seriesCloses(series)).To(Equal([]int64{200, 300, 310}))
+			series = buildSeries(SeriesOptions{Symbol: symbol, Adjustment: Adjustment_Ratio}, records...)
+			Expect(seriesCloses(series)).To(Equal([]int64{150, 300, 310}))
+			Expect(series.Bars[0].Open).To(Equal(int64(150)))
+
+			_, err := func() (*Series, error) {
+				builder, _ := NewSeriesBuilder(SeriesOptions{Symbol: symbol})
+				return builder.Build()
+			}()
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should not adjust across a roll missing a price", func() {
+			symbol, _ := ParseContinuous("ES.c.0")
+			bars[3] = newDailyBar(2, 20241220, 0, 2000)
+			records := append([]dbn.Record{esz4, esh5}, bars...)
+			for _, adjustment := range []Adjustment{Adjustment_Difference, Adjustment_Ratio} {
+				series := buildSeries(SeriesOptions{Symbol: symbol, Adjustment: adjustment}, records...)
+				Expect(series.Rolls).To(HaveLen(1))
+				Expect(series.Rolls[0].ToPrice).To(BeZero())
+				Expect(seriesCloses(series)).To(Equal([]int64{100, 200, 310}))
+			}
+		})
+
+		It("should stitch by a roll calendar of raw symbols", func() {
+			symbol, _ := ParseContinuous("ES.c.0")
+			calendar := &RollCalendar{Periods: []RollPeriod{
+				{Start: 0, End: ymdNanos(20241220), Symbol: "ESZ4"},
+				{Start: ymdNanos(20241220), End: dbn.UNDEF_TIMESTAMP, Symbol: "ESH5"},
+			}}
+			records := append([]dbn.Record{esz4, esh5}, bars...)
+			series := buildSeries(SeriesOptions{Symbol: symbol, Calendar: calendar}, records...)
+			Expect(seriesCloses(series)).To(Equal([]int64{100, 300, 310}))
+			Expect(series.Rolls[0].Ts).To(Equal(ymdNanos(20241220)))
+			Expect(series.Rolls[0].FromPrice).To(Equal(int64(100)))
+			Expect(series.Rolls[0].ToPrice).To(Equal(int64(150)))
+		})
+
+		It("should stitch by the volume rule", func() {
+			symbol, _ := ParseContinuous("ES.v.0")
+			series := buildSeries(SeriesOptions{Symbol: symbol, Adjustment: Adjustment_Difference}, bars...)
+			Expect(seriesCloses(series)).To(Equal([]int64{200, 300, 310}))
+			Expect(series.Rolls).To(HaveLen(1))
+			Expect(series.Rolls[0].Ts).To(Equal(ymdNanos(20241221)))
+
+			symbol.Rank = 1
+			series = buildSeries(SeriesOptions{Symbol: symbol}, bars...)
+			Expect(seriesCloses(series)).To(Equal([]int64{150, 300}))
+		})
+
+		It("should stitch by the open interest rule", func() {
+			newOpenInterest := func(instrumentID uint32, ts uint64, quantity int64) *dbn.StatMsg {
+				return &dbn.StatMsg{
+					Header:       dbn.RHeader{RType: dbn.RType_Statistics, InstrumentID: instrumentID, TsEvent: ts},
+					TsRecv:       ts,
+					Quantity:     quantity,
+					StatType:     uint16(dbn.StatType_OpenInterest),
+					UpdateAction: uint8(dbn.StatUpdateAction_New),
+				}
+			}
+			symbol, _ := ParseContinuous("ES.n.0")
+			records := append([]dbn.Record{
+				newOpenInterest(1, ymdNanos(20241219)+1, 500),
+				newOpenInterest(2, ymdNanos(20241220)+1, 900),
+			}, bars...)
+			series := buildSeries(SeriesOptions{Symbol: symbol}, records...)
+			Expect(seriesCloses(series)).To(Equal([]int64{100, 200, 310}))
+			Expect(series.Rolls[0].Ts).To(Equal(ymdNanos(20241221)))
+		})
+
+		It("should ignore undefined open interest of V2 statistics", func() {
+			// A V2 stream's undefined quantity is int32 max, which is not the largest open interest
+			var buf bytes.Buffer
+			writer, err := dbn.NewDbnWriter(&buf, &dbn.Metadata{VersionNum: dbn.HeaderVersion2, Schema: dbn.Schema_Statistics, Dataset: "GLBX.MDP3"})
+			Expect(err).To(BeNil())
+			for _, oi := range []struct {
+				instrumentID uint32
+				ts           uint64
+				quantity     int64
+			}{
+				{1, ymdNanos(20241219) + 1, 500},
+				{2, ymdNanos(20241219) + 2, dbn.StatMsgV3_UNDEF_STAT_QUANTITY},
+			} {
+				Expect(writer.Write(&dbn.StatMsg{
+					Header:       dbn.RHeader{RType: dbn.RType_Statistics, InstrumentID: oi.instrumentID, TsEvent: oi.ts},
+					TsRecv:       oi.ts,
+					Price:        dbn.UNDEF_PRICE,
+					Quantity:     oi.quantity,
+					StatType:     uint16(dbn.StatType_OpenInterest),
+					UpdateAction: uint8(dbn.StatUpdateAction_New),
+				})).To(Succeed())
+			}
+			for _, bar := range bars {
+				Expect(writer.Write(bar.(*dbn.OhlcvMsg))).To(Succeed())
+			}
+
+			symbol, _ := ParseContinuous("ES.n.0")
+			builder, err := NewSeriesBuilder(SeriesOptions{Symbol: symbol})
+			Expect(err).To(BeNil())
+			scanner := dbn.NewDbnScanner(&buf)
+			for scanner.Next() {
+				Expect(scanner.Visit(builder)).To(Succeed())
+			}
+			series, err := builder.Build()
+			Expect(err).To(BeNil())
+			Expect(series.Rolls).To(BeEmpty())
+			Expect(seriesCloses(series)).To(Equal([]int64{100, 200}))
+		})
+
+		It("should aggregate trades into bars", func() {
+			newTrade := func(instrumentID uint32, ts uint64, px int64, size uint32) *dbn.Mbp0Msg {
+				return &dbn.Mbp0Msg{
+					Header: dbn.RHeader{RType: dbn.RType_Mbp0, PublisherID: 1, InstrumentID: instrumentID, TsEvent: ts},
+					Price:  px, Size: size, Action: byte(dbn.Action_Trade),
+				}
+			}
+			start := ymdNanos(20241219)
+			symbol, _ := ParseContinuous("ES.v.0")
+			series := buildSeries(SeriesOptions{Symbol: symbol, InstrumentID: 7},
+				newTrade(1, start+uint64(time.Second), 100, 2),
+				newTrade(1, start+uint64(30*time.Second), 104, 1),
+				newTrade(1, start+uint64(45*time.Second), 99, 3),
+				newTrade(1, start+uint64(61*time.Second), 101, 1),
+			)
+			Expect(series.RType).To(Equal(dbn.RType_Ohlcv1M))
+			Expect(series.Bars).To(HaveLen(2))
+			Expect(series.Bars[0]).To(Equal(dbn.OhlcvMsg{
+				Header: dbn.RHeader{RType: dbn.RType_Ohlcv1M, PublisherID: 1, InstrumentID: 7, TsEvent: start},
+				Open:   100, High: 104, Low: 99, Close: 99, Volume: 6,
+			}))
+			Expect(series.Bars[1].Header.TsEvent).To(Equal(start + uint64(time.Minute)))
+		})
+
+		It("should describe the series in its metadata", func() {
+			symbol, _ := ParseContinuous("ES.v.0")
+			series := buildSeries(SeriesOptions{Symbol: symbol, InstrumentID: 1000}, bars...)
+			metadata := series.Metadata("GLBX.MDP3")
+			Expect(metadata.Dataset).To(Equal("GLBX.MDP3"))
+			Expect(metadata.Schema).To(Equal(dbn.Schema_Ohlcv1D))
+			Expect(metadata.StypeIn).To(Equal(dbn.SType_Continuous))
+			Expect(metadata.Start).To(Equal(ymdNanos(20241219)))
+			Expect(metadata.End).To(Equal(ymdNanos(20241222)))
+			Expect(metadata.Mappings).To(Equal([]dbn.SymbolMapping{{
+				RawSymbol: "ES.v.0",
+				Intervals: []dbn.MappingInterval{{StartDate: 20241219, EndDate: 20241222, Symbol: "1000"}},
+			}}))
+
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.FillFromMetadata(metadata)).To(Succeed())
+			Expect(tsm.GetAt(series.Bars[2].Header.TsEvent, 1000)).To(Equal("ES.v.0"))
+		})
+	})
+})
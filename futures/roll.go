@@ -0,0 +1,152 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_futures
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_hist "github.com/NimbleMarkets/dbn-go/hist"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// RollPeriod is the contract held by a continuous series over the half-open interval [Start, End)
+// of UNIX nanosecond timestamps.
+type RollPeriod struct {
+	Start        uint64 // Start of the period (inclusive)
+	End          uint64 // End of the period (exclusive)
+	Symbol       string // Raw symbol of the contract, if known
+	InstrumentID uint32 // Instrument ID of the contract, if known
+}
+
+// Contains returns true if the UNIX nanosecond timestamp is within the period.
+func (p RollPeriod) Contains(ts uint64) bool {
+	return p.Start <= ts && ts < p.End
+}
+
+// Roll is a change of a continuous series from one contract to the next.
+type Roll struct {
+	Ts   uint64     // Timestamp of the roll, the start of To
+	From RollPeriod // Period rolled from
+	To   RollPeriod // Period rolled to
+}
+
+// RollCalendar is the sequence of contracts held by a continuous series, sorted by time.
+type RollCalendar struct {
+	Periods []RollPeriod
+}
+
+// At returns the period in effect at the UNIX nanosecond timestamp.  Returns false if there is none.
+func (rc *RollCalendar) At(ts uint64) (RollPeriod, bool) {
+	i := sort.Search(len(rc.Periods), func(i int) bool { return rc.Periods[i].Start > ts }) - 1
+	if i < 0 || !rc.Periods[i].Contains(ts) {
+		return RollPeriod{}, false
+	}
+	return rc.Periods[i], true
+}
+
+// Rolls returns the changes of contract between consecutive periods.
+// Periods of the same contract which abut are not rolls.
+func (rc *RollCalendar) Rolls() []Roll {
+	rolls := make([]Roll, 0, len(rc.Periods))
+	for i := 1; i < len(rc.Periods); i++ {
+		from, to := rc.Periods[i-1], rc.Periods[i]
+		if from.Symbol == to.Symbol && from.InstrumentID == to.InstrumentID {
+			continue
+		}
+		rolls = append(rolls, Roll{Ts: to.Start, From: from, To: to})
+	}
+	return rolls
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// RollCalendarFromDefinitions derives the roll calendar of the calendar rule, as "ROOT.c.RANK", from
+// the definitions of a product's outright contracts, such as those of ListContracts.
+// The contract of rank 0 is held until its expiration less `rollBefore`, and rank N is the
+// Nth contract after it.  The first period starts at 0.  The latest definition of each instrument,
+// by `ts_recv`, is used, and those without an expiration are skipped.
+func RollCalendarFromDefinitions(defs []*dbn.InstrumentDefMsgV3, rank int, rollBefore time.Duration) *RollCalendar {
+	latest := make(map[uint32]*dbn.InstrumentDefMsgV3, len(defs))
+	for _, def := range defs {
+		if prev, ok := latest[def.Header.InstrumentID]; !ok || prev.TsRecv <= def.TsRecv {
+			latest[def.Header.InstrumentID] = def
+		}
+	}
+	contracts := make([]*dbn.InstrumentDefMsgV3, 0, len(latest))
+	for _, def := range defs {
+		if latest[def.Header.InstrumentID] != def {
+			continue
+		}
+		if def.Expiration == dbn.UNDEF_TIMESTAMP || dbn.InstrumentClass(def.InstrumentClass).IsSpread() {
+			continue
+		}
+		contracts = append(contracts, def)
+	}
+	slices.SortStableFunc(contracts, func(a, b *dbn.InstrumentDefMsgV3) int { return cmp.Compare(a.Expiration, b.Expiration) })
+
+	rollTs := func(def *dbn.InstrumentDefMsgV3) uint64 {
+		if before := uint64(rollBefore); before < def.Expiration {
+			return def.Expiration - before
+		}
+		return 0
+	}
+	rc := &RollCalendar{}
+	for i := rank; i < len(contracts); i++ {
+		period := RollPeriod{
+			End:          rollTs(contracts[i-rank]),
+			Symbol:       dbn.TrimNullBytes(contracts[i].RawSymbol[:]),
+			InstrumentID: contracts[i].Header.InstrumentID,
+		}
+		if i-rank > 0 {
+			period.Start = rollTs(contracts[i-rank-1])
+		}
+		if period.Start < period.End {
+			rc.Periods = append(rc.Periods, period)
+		}
+	}
+	return rc
+}
+
+// RollCalendarFromResolution returns the roll calendar of a continuous symbol from a
+// SymbologyResolve of it, such as of "ES.c.0" with SType_Continuous.  The periods' InstrumentID
+// is set if the resolution's StypeOut is SType_InstrumentId, otherwise their Symbol.
+func RollCalendarFromResolution(resolution *dbn_hist.Resolution, continuous string) (*RollCalendar, error) {
+	intervals, ok := resolution.Mappings[continuous]
+	if !ok {
+		return nil, fmt.Errorf("resolution has no mappings of '%s'", continuous)
+	}
+	rc := &RollCalendar{Periods: make([]RollPeriod, 0, len(intervals))}
+	for _, interval := range intervals {
+		if interval.Symbol == "" {
+			continue
+		}
+		start, err := time.Parse(time.DateOnly, interval.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.Parse(time.DateOnly, interval.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		period := RollPeriod{Start: uint64(start.UnixNano()), End: uint64(end.UnixNano())}
+		if resolution.StypeOut == dbn.SType_InstrumentId {
+			id, err := strconv.ParseUint(interval.Symbol, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid instrument ID '%s': %w", interval.Symbol, err)
+			}
+			period.InstrumentID = uint32(id)
+		} else {
+			period.Symbol = interval.Symbol
+		}
+		rc.Periods = append(rc.Periods, period)
+	}
+	slices.SortFunc(rc.Periods, func(a, b RollPeriod) int { return cmp.Compare(a.Start, b.Start) })
+	return rc, nil
+}
@@ -0,0 +1,583 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_futures
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Adjustment is how the prices of a continuous series are adjusted before each roll,
+// so the series does not jump by the spread between the contracts.  A roll missing either
+// contract's price, whether undefined or zero, does not adjust prices.
+type Adjustment byte
+
+const (
+	// Adjustment_None stitches the contracts' prices as they are.
+	Adjustment_None Adjustment = 'n'
+	// Adjustment_Difference adds the difference between the new and old contracts' prices at a roll to all prior prices.
+	Adjustment_Difference Adjustment = 'd'
+	// Adjustment_Ratio multiplies all prior prices by the ratio of the new and old contracts' prices at a roll.
+	Adjustment_Ratio Adjustment = 'r'
+)
+
+// ParseAdjustment parses the name of an Adjustment: "none", "difference", or "ratio".
+func ParseAdjustment(name string) (Adjustment, error) {
+	switch name {
+	case "none", "":
+		return Adjustment_None, nil
+	case "difference", "diff":
+		return Adjustment_Difference, nil
+	case "ratio":
+		return Adjustment_Ratio, nil
+	default:
+		return 0, fmt.Errorf("unknown adjustment '%s': expected none, difference, or ratio", name)
+	}
+}
+
+// String returns the name of the Adjustment.
+func (a Adjustment) String() string {
+	switch a {
+	case Adjustment_None:
+		return "none"
+	case Adjustment_Difference:
+		return "difference"
+	case Adjustment_Ratio:
+		return "ratio"
+	default:
+		return fmt.Sprintf("Adjustment(%d)", byte(a))
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SeriesOptions configures a SeriesBuilder.
+type SeriesOptions struct {
+	Symbol       ContinuousSymbol // Continuous symbol built, whose Rule and Rank select the contracts
+	Adjustment   Adjustment       // Adjustment of prices before each roll; Adjustment_None if zero
+	InstrumentID uint32           // Synthetic instrument ID of the series' records
+	BarInterval  time.Duration    // Interval of bars aggregated from trades: 1s, 1m, 1h, or 24h; 1m if zero
+	RollBefore   time.Duration    // With the calendar rule and definitions, roll this long before expiration
+	Calendar     *RollCalendar    // Roll schedule of the calendar rule, such as from RollCalendarFromResolution; from the definitions if nil
+}
+
+// SeriesRoll is a roll of a built continuous series.
+type SeriesRoll struct {
+	Ts        uint64 // Timestamp of the first bar of the new contract
+	FromID    uint32 // Instrument ID of the old contract
+	ToID      uint32 // Instrument ID of the new contract
+	FromPrice int64  // Close of the old contract before the roll
+	ToPrice   int64  // Close of the new contract before the roll, or else its open at the roll
+}
+
+// Series is a continuous series of OHLCV bars stitched across contracts.
+type Series struct {
+	Symbol       ContinuousSymbol
+	InstrumentID uint32         // Synthetic instrument ID of the bars
+	RType        dbn.RType      // RType of the bars, such as RType_Ohlcv1M
+	Bars         []dbn.OhlcvMsg // Adjusted bars, sorted by time
+	Rolls        []SeriesRoll   // Rolls between the contracts, sorted by time
+	PublisherID  uint16         // Publisher of the first source bar
+}
+
+// Metadata returns DBN Metadata for the series' bars, mapping its continuous symbol to its
+// synthetic instrument ID over the dates of the bars.  The dataset is inferred from the
+// series' publisher if empty.
+func (s *Series) Metadata(dataset string) *dbn.Metadata {
+	if dataset == "" {
+		dataset = dbn.Publisher(s.PublisherID).Dataset().String()
+	}
+	symbol := s.Symbol.String()
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        s.RType.Schema(),
+		Dataset:       dataset,
+		End:           dbn.UNDEF_TIMESTAMP,
+		StypeIn:       dbn.SType_Continuous,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Symbols:       []string{symbol},
+		Partial:       []string{},
+		NotFound:      []string{},
+	}
+	if len(s.Bars) == 0 {
+		metadata.NotFound = []string{symbol}
+		return metadata
+	}
+	metadata.Start = s.Bars[0].Header.TsEvent
+	metadata.End = s.Bars[len(s.Bars)-1].Header.TsEvent + uint64(rtypeInterval(s.RType))
+	lastDay := time.Unix(0, int64(metadata.End-1)).UTC().AddDate(0, 0, 1)
+	metadata.Mappings = []dbn.SymbolMapping{{
+		RawSymbol: symbol,
+		Intervals: []dbn.MappingInterval{{
+			StartDate: dbn.TimeToYMD(time.Unix(0, int64(metadata.Start)).UTC()),
+			EndDate:   dbn.TimeToYMD(lastDay),
+			Symbol:    strconv.FormatUint(uint64(s.InstrumentID), 10),
+		}},
+	}}
+	return metadata
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SeriesBuilder is a dbn.Visitor which gathers the OHLCV bars or trades of a product's contracts,
+// along with their definitions and open interest statistics, and then builds a continuous series of them.
+//
+// The contract held at each bar is chosen by the symbol's Rule:
+//   - RollRule_Calendar holds the contracts of SeriesOptions.Calendar, or else of RollCalendarFromDefinitions.
+//   - RollRule_Volume holds, each UTC day, the contract of the Rank-th highest volume on the prior day with volume.
+//   - RollRule_OpenInterest holds, each UTC day, the contract of the Rank-th highest open interest known
+//     at the start of the day, from StatType_OpenInterest statistics.
+//
+// With the volume and open interest rules, expired contracts are skipped and, where definitions are known,
+// the series never rolls back to a contract expiring before the one held.
+// Bars of the held contract are copied to the series, so bars missing for it are missing from the series.
+type SeriesBuilder struct {
+	dbn.NullVisitor
+	opts         SeriesOptions
+	bars         map[uint32]map[uint64]*dbn.OhlcvMsg
+	defs         map[uint32]*dbn.InstrumentDefMsgV3
+	openInterest map[uint32][]seriesStat
+	symbolMaps   []*dbn.TsSymbolMap
+	ohlcvRType   dbn.RType
+	tradeRType   dbn.RType
+	hasTrades    bool
+	publisherID  uint16
+	hasPublisher bool
+}
+
+// seriesStat is a statistic's value and the time it became known.
+type seriesStat struct {
+	ts    uint64
+	value int64
+}
+
+// NewSeriesBuilder returns a SeriesBuilder for the options.
+// Returns an error if the BarInterval or Adjustment is invalid.
+func NewSeriesBuilder(opts SeriesOptions) (*SeriesBuilder, error) {
+	if opts.Adjustment == 0 {
+		opts.Adjustment = Adjustment_None
+	}
+	if opts.Adjustment != Adjustment_None && opts.Adjustment != Adjustment_Difference && opts.Adjustment != Adjustment_Ratio {
+		return nil, fmt.Errorf("invalid adjustment %s", opts.Adjustment)
+	}
+	if opts.BarInterval == 0 {
+		opts.BarInterval = time.Minute
+	}
+	tradeRType := intervalRType(opts.BarInterval)
+	if tradeRType == dbn.RType_Unknown {
+		return nil, fmt.Errorf("invalid bar interval %s: expected 1s, 1m, 1h, or 24h", opts.BarInterval)
+	}
+	return &SeriesBuilder{
+		opts:         opts,
+		bars:         make(map[uint32]map[uint64]*dbn.OhlcvMsg),
+		defs:         make(map[uint32]*dbn.InstrumentDefMsgV3),
+		openInterest: make(map[uint32][]seriesStat),
+		tradeRType:   tradeRType,
+	}, nil
+}
+
+// FillFromMetadata adds the symbol mappings of a source's Metadata, which resolve the raw
+// symbols of a roll calendar to instrument IDs when there are no definitions of them.
+func (b *SeriesBuilder) FillFromMetadata(metadata *dbn.Metadata) error {
+	tsm := dbn.NewTsSymbolMap()
+	if err := tsm.FillFromMetadata(metadata); err != nil {
+		return err
+	}
+	if !tsm.IsEmpty() {
+		b.symbolMaps = append(b.symbolMaps, tsm)
+	}
+	return nil
+}
+
+// AddDefinition adds a contract's definition, replacing any earlier one of its instrument ID.
+func (b *SeriesBuilder) AddDefinition(def *dbn.InstrumentDefMsgV3) {
+	if prev, ok := b.defs[def.Header.InstrumentID]; !ok || prev.TsRecv <= def.TsRecv {
+		b.defs[def.Header.InstrumentID] = def
+	}
+}
+
+func (b *SeriesBuilder) OnOhlcv(record *dbn.OhlcvMsg) error {
+	if b.ohlcvRType == 0 {
+		b.ohlcvRType = record.Header.RType
+	} else if b.ohlcvRType != record.Header.RType {
+		return fmt.Errorf("mixed OHLCV intervals: rtype %d and %d", b.ohlcvRType, record.Header.RType)
+	}
+	b.observePublisher(record.Header.PublisherID)
+	bar := *record
+	b.instrumentBars(record.Header.InstrumentID)[record.Header.TsEvent] = &bar
+	return nil
+}
+
+func (b *SeriesBuilder) OnMbp0(record *dbn.Mbp0Msg) error {
+	if record.Action != byte(dbn.Action_Trade) || record.Price == dbn.UNDEF_PRICE {
+		return nil
+	}
+	b.observePublisher(record.Header.PublisherID)
+	b.hasTrades = true
+	interval := uint64(b.opts.BarInterval)
+	barTs := record.Header.TsEvent - record.Header.TsEvent%interval
+	bars := b.instrumentBars(record.Header.InstrumentID)
+	bar, ok := bars[barTs]
+	if !ok {
+		bar = &dbn.OhlcvMsg{
+			Header: dbn.RHeader{RType: b.tradeRType, PublisherID: record.Header.PublisherID, InstrumentID: record.Header.InstrumentID, TsEvent: barTs},
+			Open:   record.Price,
+			High:   record.Price,
+			Low:    record.Price,
+		}
+		bars[barTs] = bar
+	}
+	bar.High = max(bar.High, record.Price)
+	bar.Low = min(bar.Low, record.Price)
+	bar.Close = record.Price
+	bar.Volume += uint64(record.Size)
+	return nil
+}
+
+func (b *SeriesBuilder) OnStatMsg(record *dbn.StatMsg) error {
+	if dbn.StatType(record.StatType) != dbn.StatType_OpenInterest ||
+		record.UpdateAction == byte(dbn.StatUpdateAction_Delete) ||
+		record.Quantity == dbn.StatMsgV3_UNDEF_STAT_QUANTITY {
+		return nil
+	}
+	ts := record.TsRecv
+	if ts == 0 || ts == dbn.UNDEF_TIMESTAMP {
+		ts = record.Header.TsEvent
+	}
+	id := record.Header.InstrumentID
+	b.openInterest[id] = append(b.openInterest[id], seriesStat{ts: ts, value: record.Quantity})
+	return nil
+}
+
+func (b *SeriesBuilder) OnInstrumentDefMsg(record *dbn.InstrumentDefMsg) error {
+	if dbn.SecurityUpdateAction(record.SecurityUpdateAction) == dbn.Delete {
+		return nil
+	}
+	def := *record
+	b.AddDefinition(&def)
+	return nil
+}
+
+func (b *SeriesBuilder) instrumentBars(id uint32) map[uint64]*dbn.OhlcvMsg {
+	bars, ok := b.bars[id]
+	if !ok {
+		bars = make(map[uint64]*dbn.OhlcvMsg)
+		b.bars[id] = bars
+	}
+	return bars
+}
+
+func (b *SeriesBuilder) observePublisher(publisherID uint16) {
+	if !b.hasPublisher {
+		b.publisherID, b.hasPublisher = publisherID, true
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Build returns the continuous series of the gathered bars.  Returns an error if both OHLCV
+// and trades were gathered, if the calendar rule has neither a Calendar nor definitions,
+// or if the open interest rule has no statistics.
+func (b *SeriesBuilder) Build() (*Series, error) {
+	if b.ohlcvRType != 0 && b.hasTrades {
+		return nil, fmt.Errorf("cannot build from both OHLCV and trades")
+	}
+	rtype := b.tradeRType
+	if b.ohlcvRType != 0 {
+		rtype = b.ohlcvRType
+	}
+
+	// Sort each contract's bars, and the times of all of them
+	sorted := make(map[uint32][]*dbn.OhlcvMsg, len(b.bars))
+	timeline := make([]uint64, 0)
+	for id, bars := range b.bars {
+		list := make([]*dbn.OhlcvMsg, 0, len(bars))
+		for ts, bar := range bars {
+			list = append(list, bar)
+			timeline = append(timeline, ts)
+		}
+		slices.SortFunc(list, func(x, y *dbn.OhlcvMsg) int { return cmp.Compare(x.Header.TsEvent, y.Header.TsEvent) })
+		sorted[id] = list
+	}
+	slices.Sort(timeline)
+	timeline = slices.Compact(timeline)
+
+	held, err := b.holdings(sorted, timeline)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &Series{
+		Symbol:       b.opts.Symbol,
+		InstrumentID: b.opts.InstrumentID,
+		RType:        rtype,
+		Bars:         make([]dbn.OhlcvMsg, 0, len(timeline)),
+		PublisherID:  b.publisherID,
+	}
+	rollIndexes := make([]int, 0)
+	var prevID uint32
+	for i, ts := range timeline {
+		id := held[i]
+		if id == 0 {
+			continue
+		}
+		bar, ok := b.bars[id][ts]
+		if !ok {
+			continue
+		}
+		if prevID != 0 && id != prevID {
+			roll := SeriesRoll{Ts: ts, FromID: prevID, ToID: id, ToPrice: bar.Open}
+			if prev := barBefore(sorted[prevID], ts); prev != nil {
+				roll.FromPrice = prev.Close
+			}
+			if prev := barBefore(sorted[id], ts); prev != nil {
+				roll.ToPrice = prev.Close
+			}
+			series.Rolls = append(series.Rolls, roll)
+			rollIndexes = append(rollIndexes, len(series.Bars))
+		}
+		prevID = id
+
+		out := *bar
+		out.Header.InstrumentID = b.opts.InstrumentID
+		series.Bars = append(series.Bars, out)
+	}
+
+	b.adjust(series, rollIndexes)
+	return series, nil
+}
+
+// adjust back-adjusts the bars before each roll, leaving the latest contract's prices as they are.
+func (b *SeriesBuilder) adjust(series *Series, rollIndexes []int) {
+	if b.opts.Adjustment == Adjustment_None {
+		return
+	}
+	offset, factor := int64(0), 1.0
+	r := len(series.Rolls) - 1
+	for i := len(series.Bars) - 1; i >= 0; i-- {
+		// Accumulate the rolls after this bar
+		for ; r >= 0 && i < rollIndexes[r]; r-- {
+			roll := series.Rolls[r]
+			if roll.FromPrice == 0 || roll.FromPrice == dbn.UNDEF_PRICE || roll.ToPrice == 0 || roll.ToPrice == dbn.UNDEF_PRICE {
+				continue
+			}
+			offset += roll.ToPrice - roll.FromPrice
+			factor *= float64(roll.ToPrice) / float64(roll.FromPrice)
+		}
+		bar := &series.Bars[i]
+		for _, px := range []*int64{&bar.Open, &bar.High, &bar.Low, &bar.Close} {
+			if *px == dbn.UNDEF_PRICE {
+				continue
+			}
+			if b.opts.Adjustment == Adjustment_Difference {
+				*px += offset
+			} else {
+				*px = int64(math.Round(float64(*px) * factor))
+			}
+		}
+	}
+}
+
+// holdings returns the instrument ID held at each time of the timeline, 0 if none.
+func (b *SeriesBuilder) holdings(sorted map[uint32][]*dbn.OhlcvMsg, timeline []uint64) ([]uint32, error) {
+	held := make([]uint32, len(timeline))
+	switch b.opts.Symbol.Rule {
+	case RollRule_Calendar:
+		calendar := b.opts.Calendar
+		if calendar == nil {
+			if len(b.defs) == 0 {
+				return nil, fmt.Errorf("the calendar rule requires definitions or a roll calendar")
+			}
+			defs := make([]*dbn.InstrumentDefMsgV3, 0, len(b.defs))
+			for _, def := range b.defs {
+				defs = append(defs, def)
+			}
+			slices.SortFunc(defs, func(x, y *dbn.InstrumentDefMsgV3) int {
+				return cmp.Compare(x.Header.InstrumentID, y.Header.InstrumentID)
+			})
+			calendar = RollCalendarFromDefinitions(defs, b.opts.Symbol.Rank, b.opts.RollBefore)
+		}
+		for i, ts := range timeline {
+			if period, ok := calendar.At(ts); ok {
+				held[i] = b.periodInstrument(period, ts)
+			}
+		}
+	case RollRule_Volume, RollRule_OpenInterest:
+		if b.opts.Symbol.Rule == RollRule_OpenInterest && len(b.openInterest) == 0 {
+			return nil, fmt.Errorf("the open interest rule requires open interest statistics")
+		}
+		var current uint32
+		day, dayID := uint64(math.MaxUint64), uint32(0)
+		for i, ts := range timeline {
+			if d := ts / uint64(24*time.Hour); d != day {
+				day = d
+				dayID = b.rankContracts(sorted, day, current)
+				if dayID != 0 {
+					current = dayID
+				}
+			}
+			held[i] = dayID
+		}
+	default:
+		return nil, fmt.Errorf("unknown roll rule %s", b.opts.Symbol.Rule)
+	}
+	return held, nil
+}
+
+// periodInstrument returns the instrument ID of a roll period, resolving its raw symbol at `ts` if needed.
+func (b *SeriesBuilder) periodInstrument(period RollPeriod, ts uint64) uint32 {
+	if period.InstrumentID != 0 || period.Symbol == "" {
+		return period.InstrumentID
+	}
+	var id uint32
+	var idTs uint64
+	for _, def := range b.defs {
+		if dbn.TrimNullBytes(def.RawSymbol[:]) == period.Symbol && (id == 0 || def.TsRecv > idTs) {
+			id, idTs = def.Header.InstrumentID, def.TsRecv
+		}
+	}
+	if id != 0 {
+		return id
+	}
+	for _, tsm := range b.symbolMaps {
+		if id, ok := tsm.InstrumentIDAt(period.Symbol, ts); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// rankContracts returns the contract of the symbol's Rank by volume or open interest for
+// the UTC day, or 0 if there is none.  `current` is the contract held before the day.
+func (b *SeriesBuilder) rankContracts(sorted map[uint32][]*dbn.OhlcvMsg, day uint64, current uint32) uint32 {
+	dayStart := day * uint64(24*time.Hour)
+	metrics := make(map[uint32]int64)
+	if b.opts.Symbol.Rule == RollRule_Volume {
+		// Volume of the latest prior day with any, or else of the day itself
+		var prevDay uint64
+		found := false
+		for _, bars := range sorted {
+			if prev := barBefore(bars, dayStart); prev != nil {
+				if d := prev.Header.TsEvent / uint64(24*time.Hour); !found || d > prevDay {
+					prevDay, found = d, true
+				}
+			}
+		}
+		if !found {
+			prevDay = day
+		}
+		from, to := prevDay*uint64(24*time.Hour), (prevDay+1)*uint64(24*time.Hour)
+		for id, bars := range sorted {
+			i := sort.Search(len(bars), func(i int) bool { return bars[i].Header.TsEvent >= from })
+			for ; i < len(bars) && bars[i].Header.TsEvent < to; i++ {
+				metrics[id] += int64(bars[i].Volume)
+			}
+		}
+	} else {
+		// Latest open interest known at the start of the day, or else, if none is, the first during it
+		for id, stats := range b.openInterest {
+			if stat, ok := latestStatBefore(stats, dayStart); ok {
+				metrics[id] = stat.value
+			}
+		}
+		if len(metrics) == 0 {
+			dayEnd := dayStart + uint64(24*time.Hour)
+			for id, stats := range b.openInterest {
+				for _, stat := range stats {
+					if dayStart <= stat.ts && stat.ts < dayEnd {
+						metrics[id] = stat.value
+						break
+					}
+				}
+			}
+		}
+	}
+
+	currentDef := b.defs[current]
+	candidates := make([]uint32, 0, len(metrics))
+	for id, metric := range metrics {
+		if metric <= 0 {
+			continue
+		}
+		if def, ok := b.defs[id]; ok {
+			if def.Expiration != dbn.UNDEF_TIMESTAMP && def.Expiration <= dayStart {
+				continue
+			}
+			if currentDef != nil && def.Expiration < currentDef.Expiration {
+				continue
+			}
+		}
+		candidates = append(candidates, id)
+	}
+	slices.SortFunc(candidates, func(x, y uint32) int {
+		if c := cmp.Compare(metrics[y], metrics[x]); c != 0 {
+			return c
+		}
+		return cmp.Compare(x, y)
+	})
+	if b.opts.Symbol.Rank >= len(candidates) {
+		return 0
+	}
+	return candidates[b.opts.Symbol.Rank]
+}
+
+// latestStatBefore returns the latest of the stats before `ts`.  Returns false if there is none.
+func latestStatBefore(stats []seriesStat, ts uint64) (seriesStat, bool) {
+	var latest seriesStat
+	found := false
+	for _, stat := range stats {
+		if stat.ts < ts && (!found || stat.ts >= latest.ts) {
+			latest, found = stat, true
+		}
+	}
+	return latest, found
+}
+
+// barBefore returns the last of the sorted bars before `ts`, or nil if there is none.
+func barBefore(bars []*dbn.OhlcvMsg, ts uint64) *dbn.OhlcvMsg {
+	i := sort.Search(len(bars), func(i int) bool { return bars[i].Header.TsEvent >= ts })
+	if i == 0 {
+		return nil
+	}
+	return bars[i-1]
+}
+
+// intervalRType returns the OHLCV RType of a bar interval, or RType_Unknown if there is none.
+func intervalRType(interval time.Duration) dbn.RType {
+	switch interval {
+	case time.Second:
+		return dbn.RType_Ohlcv1S
+	case time.Minute:
+		return dbn.RType_Ohlcv1M
+	case time.Hour:
+		return dbn.RType_Ohlcv1H
+	case 24 * time.Hour:
+		return dbn.RType_Ohlcv1D
+	default:
+		return dbn.RType_Unknown
+	}
+}
+
+// rtypeInterval returns the bar interval of an OHLCV RType, a day for end-of-day bars.
+func rtypeInterval(rtype dbn.RType) time.Duration {
+	switch rtype {
+	case dbn.RType_Ohlcv1S:
+		return time.Second
+	case dbn.RType_Ohlcv1M:
+		return time.Minute
+	case dbn.RType_Ohlcv1H:
+		return time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
@@ -10,6 +10,7 @@ require (
 	github.com/76creates/stickers v1.5.0
 	github.com/apache/arrow-go/v18 v18.5.2
 	github.com/chromedp/chromedp v0.14.2
+	github.com/dsnet/compress v0.0.1
 	github.com/duckdb/duckdb-go/v2 v2.10501.0
 	github.com/dustin/go-humanize v1.0.1
 	github.com/hashicorp/go-retryablehttp v0.7.8
@@ -18,10 +19,12 @@ require (
 	github.com/neomantra/ymdflag v0.2.0
 	github.com/onsi/ginkgo/v2 v2.28.1
 	github.com/onsi/gomega v1.39.1
+	github.com/pierrec/lz4/v4 v4.1.25
 	github.com/relvacode/iso8601 v1.7.0
 	github.com/segmentio/encoding v0.5.4
 	github.com/spf13/cobra v1.10.2
 	github.com/spf13/pflag v1.0.10
+	github.com/ulikunitz/xz v0.5.15
 	github.com/valyala/fastjson v1.6.10
 	golang.org/x/sync v0.20.0
 )
@@ -81,7 +84,6 @@ require (
 	github.com/mitchellh/hashstructure/v2 v2.0.2 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
 	github.com/muesli/termenv v0.15.2 // indirect
-	github.com/pierrec/lz4/v4 v4.1.25 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/segmentio/asm v1.1.3 // indirect
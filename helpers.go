@@ -11,8 +11,10 @@ import (
 // / The denominator of fixed prices in DBN.
 const FIXED_PRICE_SCALE float64 = 1000000000.0
 
+// Fixed9ToFloat64 converts a fixed-point value in units of 1e-9 to a float64.
+// UNDEF_PRICE returns NaN; see Price for exact decimal handling.
 func Fixed9ToFloat64(fixed int64) float64 {
-	return float64(fixed) / FIXED_PRICE_SCALE
+	return Price(fixed).Float64()
 }
 
 // TrimNullBytes removes trailing nulls from a byte slice and returns a string.
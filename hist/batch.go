@@ -245,6 +245,9 @@ func (jobParams *SubmitJobParams) ApplyToURLValues(params *url.Values) error {
 	} else {
 		params.Add("encoding", encodingStr)
 	}
+	if !jobParams.Compression.IsApiCompression() {
+		return fmt.Errorf("unsupported API compression '%s'", jobParams.Compression)
+	}
 	if compressStr := jobParams.Compression.String(); compressStr != "" {
 		params.Add("compression", compressStr)
 	}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -88,3 +89,53 @@ func SymbologyResolve(apiKey string, params ResolveParams) (*Resolution, error)
 	}
 	return &resp, nil
 }
+
+///////////////////////////////////////////////////////////////////////////////
+
+// TsSymbolMap returns a dbn.TsSymbolMap of the Resolution's mappings, for looking up symbols
+// by instrument ID and time, and instrument IDs by symbol and time.
+// Either StypeIn or StypeOut must be SType_InstrumentId.
+func (r *Resolution) TsSymbolMap() (*dbn.TsSymbolMap, error) {
+	tsm := dbn.NewTsSymbolMap()
+	if err := r.FillTsSymbolMap(tsm); err != nil {
+		return nil, err
+	}
+	return tsm, nil
+}
+
+// FillTsSymbolMap adds the Resolution's mappings to a dbn.TsSymbolMap.
+// Either StypeIn or StypeOut must be SType_InstrumentId.
+func (r *Resolution) FillTsSymbolMap(tsm *dbn.TsSymbolMap) error {
+	isInverse := r.StypeIn == dbn.SType_InstrumentId
+	if !isInverse && r.StypeOut != dbn.SType_InstrumentId {
+		return dbn.ErrWrongStypesForMapping
+	}
+	for inputSymbol, intervals := range r.Mappings {
+		for _, interval := range intervals {
+			if interval.Symbol == "" {
+				continue
+			}
+			idStr, symbol := interval.Symbol, inputSymbol
+			if isInverse {
+				idStr, symbol = inputSymbol, interval.Symbol
+			}
+			instrID, err := strconv.ParseUint(idStr, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid instrument ID '%s': %w", idStr, err)
+			}
+			startDate, err := time.Parse(time.DateOnly, interval.StartDate)
+			if err != nil {
+				return err
+			}
+			endDate, err := time.Parse(time.DateOnly, interval.EndDate)
+			if err != nil {
+				return err
+			}
+			err = tsm.InsertTs(uint32(instrID), uint64(startDate.UnixNano()), uint64(endDate.UnixNano()), symbol)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// InstrumentMaster is a point-in-time store of instrument definitions, built from
+// definition records.  Each instrument keeps its history of definitions, so queries
+// answer with the definition in effect at a given timestamp.
+//
+// Definitions take effect at their `ts_recv`, which is the index timestamp of
+// Databento's definition schema.  A SecurityUpdateAction of Delete removes the
+// instrument from that time on, until it is added again.
+type InstrumentMaster struct {
+	dataset   string
+	histories map[uint32][]instrumentVersion // instrument ID -> versions in effective order
+	bySymbol  map[string][]uint32            // raw symbol -> instrument IDs which have used it
+}
+
+// instrumentVersion is one definition of an instrument's history.
+type instrumentVersion struct {
+	ts  uint64 // effective timestamp
+	def *InstrumentDefMsgV3
+}
+
+// isDeleted returns true if the version removes the instrument.
+func (v *instrumentVersion) isDeleted() bool {
+	return SecurityUpdateAction(v.def.SecurityUpdateAction) == Delete
+}
+
+// NewInstrumentMaster returns an empty InstrumentMaster.
+func NewInstrumentMaster() *InstrumentMaster {
+	return &InstrumentMaster{
+		histories: make(map[uint32][]instrumentVersion),
+		bySymbol:  make(map[string][]uint32),
+	}
+}
+
+// IsEmpty returns true if there are no instruments.
+func (im *InstrumentMaster) IsEmpty() bool {
+	return len(im.histories) == 0
+}
+
+// Len returns the number of instruments, including deleted ones.
+func (im *InstrumentMaster) Len() int {
+	return len(im.histories)
+}
+
+// Dataset returns the dataset of the ingested definitions, if known.
+func (im *InstrumentMaster) Dataset() string {
+	return im.dataset
+}
+
+// InstrumentIDs returns the sorted IDs of all the instruments, including deleted ones.
+func (im *InstrumentMaster) InstrumentIDs() []uint32 {
+	ids := make([]uint32, 0, len(im.histories))
+	for id := range im.histories {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Insert adds a definition to its instrument's history, per its SecurityUpdateAction.
+// Definitions may be inserted in any order; a definition with the same timestamp
+// as an existing one replaces it.
+func (im *InstrumentMaster) Insert(def *InstrumentDefMsgV3) {
+	ts := def.TsRecv
+	if ts == 0 || ts == UNDEF_TIMESTAMP {
+		ts = def.Header.TsEvent
+	}
+	id := def.Header.InstrumentID
+	history := im.histories[id]
+	i := sort.Search(len(history), func(i int) bool { return history[i].ts > ts })
+	if i > 0 && history[i-1].ts == ts {
+		history[i-1].def = def
+	} else {
+		history = slices.Insert(history, i, instrumentVersion{ts: ts, def: def})
+	}
+	im.histories[id] = history
+
+	symbol := TrimNullBytes(def.RawSymbol[:])
+	if ids := im.bySymbol[symbol]; !slices.Contains(ids, id) {
+		im.bySymbol[symbol] = append(ids, id)
+	}
+}
+
+// OnInstrumentDefMsg inserts the definition, so an InstrumentMaster may be fed by a Visitor.
+func (im *InstrumentMaster) OnInstrumentDefMsg(def *InstrumentDefMsg) error {
+	im.Insert(def)
+	return nil
+}
+
+// ReadDbn inserts all the definition records of a DBN stream, skipping other records.
+// V2 definitions are upgraded to V3; V1 is not supported.
+func (im *InstrumentMaster) ReadDbn(scanner *DbnScanner) error {
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		return err
+	}
+	if im.dataset == "" {
+		im.dataset = metadata.Dataset
+	}
+	for scanner.Next() {
+		if RType(scanner.GetLastRecord()[1]) != RType_InstrumentDef {
+			continue
+		}
+		def, err := scanner.DecodeInstrumentDefMsg()
+		if err != nil {
+			return err
+		}
+		im.Insert(def)
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// ReadDbnFile inserts all the definition records of a DBN file; see ReadDbn.
+// Compression is detected from the file's contents; if useZstd is true, the file is always zstd-decompressed.
+func (im *InstrumentMaster) ReadDbnFile(filename string, useZstd bool) error {
+	reader, closer, err := MakeCompressedReader(filename, useZstd)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	if err := im.ReadDbn(NewDbnScanner(reader)); err != nil {
+		return fmt.Errorf("failed to read definitions from '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// WriteDbn writes every definition of every instrument's history as a DBN V3 definition stream,
+// in timestamp order.  Reading it with ReadDbn restores the InstrumentMaster.
+func (im *InstrumentMaster) WriteDbn(writer io.Writer) error {
+	versions := make([]instrumentVersion, 0, len(im.histories))
+	for _, history := range im.histories {
+		versions = append(versions, history...)
+	}
+	slices.SortStableFunc(versions, func(a, b instrumentVersion) int {
+		if a.ts != b.ts {
+			if a.ts < b.ts {
+				return -1
+			}
+			return 1
+		}
+		return int(a.def.Header.InstrumentID) - int(b.def.Header.InstrumentID)
+	})
+
+	metadata := &Metadata{
+		VersionNum:    HeaderVersion3,
+		Dataset:       im.dataset,
+		Schema:        Schema_Definition,
+		End:           UNDEF_TIMESTAMP,
+		StypeIn:       SType_InstrumentId,
+		StypeOut:      SType_InstrumentId,
+		SymbolCstrLen: MetadataV3_SymbolCstrLen,
+		Symbols:       []string{},
+		Partial:       []string{},
+		NotFound:      []string{},
+		Mappings:      []SymbolMapping{},
+	}
+	if len(versions) != 0 {
+		metadata.Start = versions[0].ts
+		metadata.End = versions[len(versions)-1].ts + 1
+	}
+	dbnWriter, err := NewDbnWriter(writer, metadata)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := dbnWriter.Write(version.def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDbnFile writes the InstrumentMaster to a DBN file; see WriteDbn.
+// The file is compressed with the codec of the filename's suffix, such as ".zst" or ".gz", or with zstd if useZstd is true.
+// Returns any error writing or closing the file.
+func (im *InstrumentMaster) WriteDbnFile(filename string, useZstd bool) error {
+	opts := CompressionOptions{Compression: CompressionFromFilename(filename)}
+	if useZstd {
+		opts.Compression = Compress_ZStd
+	}
+	writer, err := CreateCompressedWriter(filename, opts)
+	if err != nil {
+		return err
+	}
+	errWrite := im.WriteDbn(writer)
+	return errors.Join(errWrite, writer.Close())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Get returns the definition of the instrument in effect at `timestamp`, or nil if there is
+// none or it was deleted.  Use UNDEF_TIMESTAMP for the latest definition.
+func (im *InstrumentMaster) Get(instrumentID uint32, timestamp uint64) *InstrumentDefMsgV3 {
+	history := im.histories[instrumentID]
+	i := sort.Search(len(history), func(i int) bool { return history[i].ts > timestamp })
+	if i == 0 || history[i-1].isDeleted() {
+		return nil
+	}
+	return history[i-1].def
+}
+
+// History returns every definition of the instrument, in effective order, including deletions.
+func (im *InstrumentMaster) History(instrumentID uint32) []*InstrumentDefMsgV3 {
+	history := im.histories[instrumentID]
+	defs := make([]*InstrumentDefMsgV3, len(history))
+	for i := range history {
+		defs[i] = history[i].def
+	}
+	return defs
+}
+
+// GetByRawSymbol returns the definition with the raw symbol in effect at `timestamp`, or nil if there is none.
+// If several instruments have the symbol at once, the most recently defined is returned.
+func (im *InstrumentMaster) GetByRawSymbol(rawSymbol string, timestamp uint64) *InstrumentDefMsgV3 {
+	var found *InstrumentDefMsgV3
+	var foundTs uint64
+	for _, id := range im.bySymbol[rawSymbol] {
+		history := im.histories[id]
+		i := sort.Search(len(history), func(i int) bool { return history[i].ts > timestamp })
+		if i == 0 || history[i-1].isDeleted() {
+			continue
+		}
+		version := history[i-1]
+		if TrimNullBytes(version.def.RawSymbol[:]) == rawSymbol && (found == nil || version.ts > foundTs) {
+			found, foundTs = version.def, version.ts
+		}
+	}
+	return found
+}
+
+// Select returns the definitions in effect at `timestamp` for which `pred` returns true, ordered by instrument ID.
+func (im *InstrumentMaster) Select(timestamp uint64, pred func(def *InstrumentDefMsgV3) bool) []*InstrumentDefMsgV3 {
+	defs := make([]*InstrumentDefMsgV3, 0)
+	for _, id := range im.InstrumentIDs() {
+		if def := im.Get(id, timestamp); def != nil && pred(def) {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// ByUnderlying returns the definitions in effect at `timestamp` whose underlying symbol
+// or asset is `underlying`, such as the options and futures on "ES".
+func (im *InstrumentMaster) ByUnderlying(underlying string, timestamp uint64) []*InstrumentDefMsgV3 {
+	return im.Select(timestamp, func(def *InstrumentDefMsgV3) bool {
+		return TrimNullBytes(def.Underlying[:]) == underlying || TrimNullBytes(def.Asset[:]) == underlying
+	})
+}
+
+// ByUnderlyingID returns the definitions in effect at `timestamp` whose underlying has the instrument ID.
+func (im *InstrumentMaster) ByUnderlyingID(underlyingID uint32, timestamp uint64) []*InstrumentDefMsgV3 {
+	return im.Select(timestamp, func(def *InstrumentDefMsgV3) bool {
+		return def.UnderlyingID == underlyingID
+	})
+}
+
+// ByExpiration returns the definitions in effect at `timestamp` which expire in [`start`, `end`).
+// Instruments without an expiration, such as equities, are excluded.
+func (im *InstrumentMaster) ByExpiration(start uint64, end uint64, timestamp uint64) []*InstrumentDefMsgV3 {
+	return im.Select(timestamp, func(def *InstrumentDefMsgV3) bool {
+		return def.Expiration != UNDEF_TIMESTAMP && def.Expiration >= start && def.Expiration < end
+	})
+}
+
+// TickSize returns the instrument's minimum price increment at `timestamp`.
+// Returns false if there is no definition or it has no increment.
+func (im *InstrumentMaster) TickSize(instrumentID uint32, timestamp uint64) (float64, bool) {
+	def := im.Get(instrumentID, timestamp)
+	if def == nil || def.MinPriceIncrement == UNDEF_PRICE {
+		return 0, false
+	}
+	return Fixed9ToFloat64(def.MinPriceIncrement), true
+}
+
+// Multiplier returns the instrument's contract size, its `unit_of_measure_qty`, at `timestamp`.
+// Returns false if there is no definition or it has no contract size, such as for equities.
+func (im *InstrumentMaster) Multiplier(instrumentID uint32, timestamp uint64) (float64, bool) {
+	def := im.Get(instrumentID, timestamp)
+	if def == nil || def.UnitOfMeasureQty == UNDEF_PRICE {
+		return 0, false
+	}
+	return Fixed9ToFloat64(def.UnitOfMeasureQty), true
+}
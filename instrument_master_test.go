@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestDefinition returns a definition for an instrument at `tsRecv`.
+func newTestDefinition(instrumentID uint32, tsRecv uint64, rawSymbol string, action dbn.SecurityUpdateAction) *dbn.InstrumentDefMsgV3 {
+	def := &dbn.InstrumentDefMsgV3{
+		Header: dbn.RHeader{
+			Length:       dbn.InstrumentDefMsgV3_Size / 4,
+			RType:        dbn.RType_InstrumentDef,
+			PublisherID:  1,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		TsRecv:               tsRecv,
+		MinPriceIncrement:    250_000_000,
+		UnitOfMeasureQty:     50_000_000_000,
+		Expiration:           dbn.UNDEF_TIMESTAMP,
+		SecurityUpdateAction: byte(action),
+	}
+	copy(def.RawSymbol[:], rawSymbol)
+	return def
+}
+
+var _ = Describe("InstrumentMaster", func() {
+	Context("definition files", func() {
+		It("should read V2 and V3 definitions", func() {
+			for _, filename := range []string{"test_data.definition.v2.dbn.zst", "test_data.definition.v3.dbn.zst"} {
+				im := dbn.NewInstrumentMaster()
+				Expect(im.ReadDbnFile("./tests/data/"+filename, false)).To(Succeed())
+				Expect(im.InstrumentIDs()).To(Equal([]uint32{6819, 6830}))
+				Expect(im.Dataset()).To(Equal("XNAS.ITCH"))
+
+				// MSFT was instrument 6819 on 2021-10-04 and 6830 on 2021-10-05
+				Expect(im.GetByRawSymbol("MSFT", 1633331241618029518)).To(BeNil())
+				Expect(im.GetByRawSymbol("MSFT", 1633331241618029519).Header.InstrumentID).To(Equal(uint32(6819)))
+				Expect(im.GetByRawSymbol("MSFT", 1633417621703120931).Header.InstrumentID).To(Equal(uint32(6830)))
+				Expect(im.Get(6830, 1633331241618029519)).To(BeNil())
+				Expect(im.Get(6830, dbn.UNDEF_TIMESTAMP)).NotTo(BeNil())
+			}
+		})
+	})
+
+	Context("security update actions", func() {
+		It("should apply adds, modifies, and deletes in time order", func() {
+			im := dbn.NewInstrumentMaster()
+			modified := newTestDefinition(42, 200, "ESH4", dbn.Modify)
+			modified.MinPriceIncrement = 500_000_000
+			// Insert out of order
+			im.Insert(newTestDefinition(42, 300, "ESH4", dbn.Delete))
+			im.Insert(modified)
+			im.Insert(newTestDefinition(42, 100, "ESH4", dbn.Add))
+
+			Expect(im.Get(42, 99)).To(BeNil())
+			tick, ok := im.TickSize(42, 150)
+			Expect(ok).To(BeTrue())
+			Expect(tick).To(Equal(0.25))
+			tick, _ = im.TickSize(42, 250)
+			Expect(tick).To(Equal(0.5))
+			multiplier, ok := im.Multiplier(42, 250)
+			Expect(ok).To(BeTrue())
+			Expect(multiplier).To(Equal(50.0))
+			Expect(im.Get(42, 300)).To(BeNil())
+			Expect(im.GetByRawSymbol("ESH4", 300)).To(BeNil())
+			_, ok = im.TickSize(42, 300)
+			Expect(ok).To(BeFalse())
+			Expect(im.History(42)).To(HaveLen(3))
+		})
+	})
+
+	Context("queries", func() {
+		It("should select by underlying and expiration", func() {
+			im := dbn.NewInstrumentMaster()
+			for i, symbol := range []string{"ESH4 C5000", "ESH4 P5000", "NQH4 C18000"} {
+				def := newTestDefinition(uint32(10+i), 100, symbol, dbn.Add)
+				copy(def.Underlying[:], symbol[:4])
+				def.UnderlyingID = 1
+				def.Expiration = uint64(1000 + 100*i)
+				im.Insert(def)
+			}
+			im.Insert(newTestDefinition(1, 100, "ESH4", dbn.Add))
+
+			Expect(im.ByUnderlying("ESH4", 100)).To(HaveLen(2))
+			Expect(im.ByUnderlying("ESH4", 50)).To(BeEmpty())
+			Expect(im.ByUnderlyingID(1, 100)).To(HaveLen(3))
+			byExpiration := im.ByExpiration(1100, 1300, 100)
+			Expect(byExpiration).To(HaveLen(2))
+			Expect(byExpiration[0].Header.InstrumentID).To(Equal(uint32(11)))
+		})
+	})
+
+	Context("persistence", func() {
+		It("should round trip through DBN", func() {
+			im := dbn.NewInstrumentMaster()
+			Expect(im.ReadDbnFile("./tests/data/test_data.definition.v3.dbn.zst", false)).To(Succeed())
+			im.Insert(newTestDefinition(6819, 1633500000000000000, "MSFT", dbn.Delete))
+
+			filename := filepath.Join(GinkgoT().TempDir(), "instruments.dbn.zst")
+			Expect(im.WriteDbnFile(filename, false)).To(Succeed())
+			loaded := dbn.NewInstrumentMaster()
+			Expect(loaded.ReadDbnFile(filename, false)).To(Succeed())
+
+			Expect(loaded.InstrumentIDs()).To(Equal(im.InstrumentIDs()))
+			Expect(loaded.History(6819)).To(Equal(im.History(6819)))
+			Expect(loaded.Get(6819, dbn.UNDEF_TIMESTAMP)).To(BeNil())
+			Expect(loaded.Get(6830, dbn.UNDEF_TIMESTAMP)).To(Equal(im.Get(6830, dbn.UNDEF_TIMESTAMP)))
+
+			var buf bytes.Buffer
+			Expect(dbn.NewInstrumentMaster().WriteDbn(&buf)).To(Succeed())
+		})
+
+		It("should return the error of closing the file", func() {
+			if _, err := os.Stat("/dev/full"); err != nil {
+				Skip("no /dev/full")
+			}
+			im := dbn.NewInstrumentMaster()
+			im.Insert(newTestDefinition(6819, 1633500000000000000, "MSFT", dbn.Add))
+			// The compressed stream is buffered until closed, so only closing fails
+			Expect(im.WriteDbnFile("/dev/full", true)).NotTo(Succeed())
+		})
+	})
+})
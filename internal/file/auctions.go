@@ -0,0 +1,290 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	pqschema "github.com/apache/arrow-go/v18/parquet/schema"
+)
+
+const (
+	AuctionsFormat_Csv     = "csv"
+	AuctionsFormat_Parquet = "parquet"
+)
+
+// AuctionsOptions controls how imbalance and trades files are analyzed and written by WriteAuctions.
+type AuctionsOptions struct {
+	Format         string // Output format, one of AuctionsFormat_*; by the destination's suffix if empty
+	ForceZstdInput bool   // Force input to be zstd, irrespective of filename suffix
+}
+
+// auctionsColumns are the columns of WriteAuctions, one per field of a dbn.AuctionSummary.
+var auctionsColumns = []string{
+	"date", "instrument_id", "symbol", "auction_type", "kind",
+	"first_ts", "last_ts", "num_updates",
+	"ref_price", "indicative_price", "auction_price",
+	"paired_qty", "total_imbalance_qty", "side", "max_paired_qty", "max_imbalance_qty",
+	"uncross_ts", "uncross_price", "uncross_size",
+}
+
+// auctionsSource is an AuctionAnalyzer with the symbol maps of the files it was read from.
+type auctionsSource struct {
+	analyzer   *dbn.AuctionAnalyzer
+	symbolMaps []*dbn.TsSymbolMap
+}
+
+// symbol returns the symbol of an auction's instrument on its date, or empty string if unmapped.
+func (s *auctionsSource) symbol(summary *dbn.AuctionSummary) string {
+	date := dbn.YMDToTime(int(summary.Date), time.UTC)
+	for _, tsm := range s.symbolMaps {
+		if symbol := tsm.Get(date, summary.InstrumentID); symbol != "" {
+			return symbol
+		}
+	}
+	return ""
+}
+
+// auctionsPrice returns the price, or UNDEF_PRICE if it is zero, as venues leave unset prices.
+func auctionsPrice(price int64) int64 {
+	if price == 0 {
+		return dbn.UNDEF_PRICE
+	}
+	return price
+}
+
+// WriteAuctions groups the imbalance records of DBN source files into auctions with a
+// dbn.AuctionAnalyzer, uncrossed by the trades of the source files, and writes a summary
+// row per auction to `destFile` as CSV or Parquet, sorted by the auction's first imbalance.
+// Rows have the auction's date, instrument, symbol, venue `auction_type`, and kind, its first
+// and last imbalance timestamps and number of them, the last imbalance's prices, quantities, and
+// side, the largest paired and imbalance quantities, and its uncross trade, if any.
+// Unset or zero prices and missing trades are empty, or null in Parquet.
+func WriteAuctions(sourceFiles []string, opts AuctionsOptions, destFile string) error {
+	format := opts.Format
+	if format == "" {
+		format = AuctionsFormat_Csv
+		if strings.HasSuffix(destFile, ".parquet") {
+			format = AuctionsFormat_Parquet
+		}
+	}
+	if format != AuctionsFormat_Csv && format != AuctionsFormat_Parquet {
+		return fmt.Errorf("unknown auctions format '%s'", format)
+	}
+
+	source := &auctionsSource{analyzer: dbn.NewAuctionAnalyzer()}
+	for _, sourceFile := range sourceFiles {
+		if err := source.readDbnFile(sourceFile, opts.ForceZstdInput); err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+	}
+
+	writer, writerCloser, err := dbn.MakeCompressedWriter(destFile, false)
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	defer writerCloser()
+	if format == AuctionsFormat_Parquet {
+		return writeAuctionsParquet(source, writer)
+	}
+	return writeAuctionsCsv(source, writer)
+}
+
+// readDbnFile adds the imbalances and trades of a DBN file to the analyzer.
+func (s *auctionsSource) readDbnFile(sourceFile string, forceZstd bool) error {
+	reader, closer, err := dbn.MakeCompressedReader(sourceFile, forceZstd)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	scanner := dbn.NewDbnScanner(reader)
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	tsm := dbn.NewTsSymbolMap()
+	if err := tsm.FillFromMetadata(metadata); err != nil {
+		return fmt.Errorf("failed to fill symbol map: %w", err)
+	}
+	s.symbolMaps = append(s.symbolMaps, tsm)
+	return s.analyzer.ReadDbn(scanner)
+}
+
+// summaries returns the summaries of the analyzer's auctions.
+func (s *auctionsSource) summaries() []dbn.AuctionSummary {
+	auctions := s.analyzer.Auctions()
+	summaries := make([]dbn.AuctionSummary, len(auctions))
+	for i, auction := range auctions {
+		summaries[i] = auction.Summary()
+	}
+	return summaries
+}
+
+// writeAuctionsCsv writes the auction summaries as CSV, with a header row.
+func writeAuctionsCsv(source *auctionsSource, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(auctionsColumns); err != nil {
+		return err
+	}
+
+	formatTs := func(ts uint64) string {
+		if ts == dbn.UNDEF_TIMESTAMP {
+			return ""
+		}
+		return dbn.TimestampToTime(ts).UTC().Format(time.RFC3339Nano)
+	}
+	formatPrice := func(price int64) string {
+		if price = auctionsPrice(price); price == dbn.UNDEF_PRICE {
+			return ""
+		}
+		return dbn.Price(price).String()
+	}
+	formatUint := func(value uint32) string { return strconv.FormatUint(uint64(value), 10) }
+
+	for _, summary := range source.summaries() {
+		uncrossSize := ""
+		if summary.UncrossPrice != dbn.UNDEF_PRICE {
+			uncrossSize = formatUint(summary.UncrossSize)
+		}
+		fields := []string{
+			dbn.YMDToTime(int(summary.Date), time.UTC).Format(time.DateOnly),
+			formatUint(summary.InstrumentID),
+			source.symbol(&summary),
+			string(rune(summary.AuctionType)),
+			summary.Kind.String(),
+			formatTs(summary.FirstTs),
+			formatTs(summary.LastTs),
+			strconv.Itoa(summary.NumSnapshots),
+			formatPrice(summary.RefPrice),
+			formatPrice(summary.IndicativePrice),
+			formatPrice(summary.AuctionPrice),
+			formatUint(summary.PairedQty),
+			formatUint(summary.TotalImbalanceQty),
+			string(rune(summary.Side)),
+			formatUint(summary.MaxPairedQty),
+			formatUint(summary.MaxImbalanceQty),
+			formatTs(summary.UncrossTs),
+			formatPrice(summary.UncrossPrice),
+			uncrossSize,
+		}
+		if err := csvWriter.Write(fields); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ParquetGroupNode_Auctions returns the Parquet Schema's Group Node for the auction summaries of WriteAuctions.
+//
+//	optional int32 field_id=-1 date (Date);
+//	optional int32 field_id=-1 instrument_id (Int(bitWidth=32, isSigned=false));
+//	optional binary field_id=-1 symbol (String);
+//	optional binary field_id=-1 auction_type (String);
+//	optional binary field_id=-1 kind (String);
+//	optional int64 field_id=-1 first_ts (Timestamp(isAdjustedToUTC=true, timeUnit=nanoseconds, is_from_converted_type=false, force_set_converted_type=false));
+//	optional int64 field_id=-1 last_ts (Timestamp(isAdjustedToUTC=true, timeUnit=nanoseconds, is_from_converted_type=false, force_set_converted_type=false));
+//	optional int64 field_id=-1 num_updates (Int(bitWidth=64, isSigned=true));
+//	optional double field_id=-1 ref_price;
+//	optional double field_id=-1 indicative_price;
+//	optional double field_id=-1 auction_price;
+//	optional int64 field_id=-1 paired_qty (Int(bitWidth=64, isSigned=true));
+//	optional int64 field_id=-1 total_imbalance_qty (Int(bitWidth=64, isSigned=true));
+//	optional binary field_id=-1 side (String);
+//	optional int64 field_id=-1 max_paired_qty (Int(bitWidth=64, isSigned=true));
+//	optional int64 field_id=-1 max_imbalance_qty (Int(bitWidth=64, isSigned=true));
+//	optional int64 field_id=-1 uncross_ts (Timestamp(isAdjustedToUTC=true, timeUnit=nanoseconds, is_from_converted_type=false, force_set_converted_type=false));
+//	optional double field_id=-1 uncross_price;
+//	optional int64 field_id=-1 uncross_size (Int(bitWidth=64, isSigned=true));
+func ParquetGroupNode_Auctions() *pqschema.GroupNode {
+	stringNode := func(name string) pqschema.Node {
+		return pqschema.MustPrimitive(pqschema.NewPrimitiveNodeConverted(name, parquet.Repetitions.Optional, parquet.Types.ByteArray, pqschema.ConvertedTypes.UTF8, 0, 0, 0, -1))
+	}
+	timestampNode := func(name string) pqschema.Node {
+		return pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical(name, parquet.Repetitions.Optional, pqschema.NewTimestampLogicalType(true, pqschema.TimeUnitNanos), parquet.Types.Int64, 0, -1))
+	}
+	int64Node := func(name string) pqschema.Node {
+		return pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical(name, parquet.Repetitions.Optional, pqschema.NewIntLogicalType(64, true), parquet.Types.Int64, 0, -1))
+	}
+	priceNode := func(name string) pqschema.Node {
+		return pqschema.NewFloat64Node(name, parquet.Repetitions.Optional, -1)
+	}
+	return pqschema.MustGroup(pqschema.NewGroupNode("schema", parquet.Repetitions.Required, pqschema.FieldList{
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("date", parquet.Repetitions.Optional, pqschema.DateLogicalType{}, parquet.Types.Int32, 0, -1)),
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("instrument_id", parquet.Repetitions.Optional, pqschema.NewIntLogicalType(32, false), parquet.Types.Int32, 0, -1)),
+		stringNode("symbol"),
+		stringNode("auction_type"),
+		stringNode("kind"),
+		timestampNode("first_ts"),
+		timestampNode("last_ts"),
+		int64Node("num_updates"),
+		priceNode("ref_price"),
+		priceNode("indicative_price"),
+		priceNode("auction_price"),
+		int64Node("paired_qty"),
+		int64Node("total_imbalance_qty"),
+		stringNode("side"),
+		int64Node("max_paired_qty"),
+		int64Node("max_imbalance_qty"),
+		timestampNode("uncross_ts"),
+		priceNode("uncross_price"),
+		int64Node("uncross_size"),
+	}, -1))
+}
+
+// writeAuctionsParquet writes the auction summaries as Parquet, per ParquetGroupNode_Auctions.
+func writeAuctionsParquet(source *auctionsSource, writer io.Writer) error {
+	pwProperties := parquet.NewWriterProperties(
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithCompression(compress.Codecs.Snappy))
+	pw := pqfile.NewParquetWriter(writer, ParquetGroupNode_Auctions(), pqfile.WithWriterProps(pwProperties))
+	defer pw.Close()
+	rgw := pw.AppendBufferedRowGroup()
+
+	errWrite := func() error {
+		for _, summary := range source.summaries() {
+			days := dbn.YMDToTime(int(summary.Date), time.UTC).Unix() / 86400
+			uncrossed := summary.UncrossPrice != dbn.UNDEF_PRICE
+			errs := []error{
+				writeInt32Column(rgw, 0, int32(days)),
+				writeInt32Column(rgw, 1, int32(summary.InstrumentID)),
+				writeByteArrayColumn(rgw, 2, parquet.ByteArray(source.symbol(&summary))),
+				writeByteArrayColumn(rgw, 3, parquet.ByteArray{summary.AuctionType}),
+				writeByteArrayColumn(rgw, 4, parquet.ByteArray(summary.Kind.String())),
+				writeTimestampColumn(rgw, 5, summary.FirstTs),
+				writeTimestampColumn(rgw, 6, summary.LastTs),
+				writeInt64Column(rgw, 7, int64(summary.NumSnapshots)),
+				writePriceColumn(rgw, 8, auctionsPrice(summary.RefPrice)),
+				writePriceColumn(rgw, 9, auctionsPrice(summary.IndicativePrice)),
+				writePriceColumn(rgw, 10, auctionsPrice(summary.AuctionPrice)),
+				writeInt64Column(rgw, 11, int64(summary.PairedQty)),
+				writeInt64Column(rgw, 12, int64(summary.TotalImbalanceQty)),
+				writeByteArrayColumn(rgw, 13, parquet.ByteArray{byte(summary.Side)}),
+				writeInt64Column(rgw, 14, int64(summary.MaxPairedQty)),
+				writeInt64Column(rgw, 15, int64(summary.MaxImbalanceQty)),
+				writeTimestampColumn(rgw, 16, summary.UncrossTs),
+				writePriceColumn(rgw, 17, auctionsPrice(summary.UncrossPrice)),
+				writeNullableInt64Column(rgw, 18, int64(summary.UncrossSize), uncrossed),
+			}
+			if err := errors.Join(errs...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+
+	errClose := rgw.Close()
+	errFlush := pw.FlushWithFooter()
+	return errors.Join(errWrite, errClose, errFlush)
+}
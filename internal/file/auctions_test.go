@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+)
+
+// writeAuctionsTestFiles writes XNAS.ITCH imbalances and trades of 2024-01-02 to separate files, returning their names.
+// ACME (7) has an opening auction without an indicative price, uncrossed by the trade after its last
+// imbalance rather than the one between its imbalances.  WIDG (8) has an earlier halt auction without
+// any trade, and is only mapped by the trades file.
+func writeAuctionsTestFiles(t *testing.T) []string {
+	t.Helper()
+
+	ts := func(hour, minute int) uint64 {
+		return uint64(time.Date(2024, 1, 2, hour, minute, 0, 0, time.UTC).UnixNano())
+	}
+	metadata := func(schema dbn.Schema, symbols ...string) *dbn.Metadata {
+		m := &dbn.Metadata{
+			VersionNum:    dbn.HeaderVersion3,
+			Schema:        schema,
+			Dataset:       "XNAS.ITCH",
+			StypeIn:       dbn.SType_RawSymbol,
+			StypeOut:      dbn.SType_InstrumentId,
+			SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		}
+		for i := 0; i < len(symbols); i += 2 {
+			m.Mappings = append(m.Mappings, dbn.SymbolMapping{
+				RawSymbol: symbols[i],
+				Intervals: []dbn.MappingInterval{{StartDate: 20240102, EndDate: 20240103, Symbol: symbols[i+1]}},
+			})
+		}
+		return m
+	}
+	imbalance := func(instrumentID uint32, ts uint64, auctionType byte, refPrice int64, pairedQty, imbalanceQty uint32) *dbn.ImbalanceMsg {
+		return &dbn.ImbalanceMsg{
+			Header:            dbn.RHeader{RType: dbn.RType_Imbalance, PublisherID: 2, InstrumentID: instrumentID, TsEvent: ts},
+			TsRecv:            ts,
+			RefPrice:          refPrice,
+			PairedQty:         pairedQty,
+			TotalImbalanceQty: imbalanceQty,
+			AuctionType:       auctionType,
+			Side:              byte(dbn.Side_Bid),
+		}
+	}
+	trade := func(instrumentID uint32, ts uint64, price int64, size uint32) *dbn.Mbp0Msg {
+		return &dbn.Mbp0Msg{
+			Header: dbn.RHeader{RType: dbn.RType_Mbp0, PublisherID: 2, InstrumentID: instrumentID, TsEvent: ts},
+			TsRecv: ts,
+			Price:  price,
+			Size:   size,
+			Action: byte(dbn.Action_Trade),
+		}
+	}
+
+	dir := t.TempDir()
+	imbalances := filepath.Join(dir, "imbalance.dbn")
+	writeDbnTestFile(t, imbalances, metadata(dbn.Schema_Imbalance, "ACME", "7"),
+		imbalance(7, ts(14, 25), 'O', 10_000000000, 100, 500),
+		imbalance(8, ts(14, 10), 'H', 20_000000000, 50, 50),
+		imbalance(7, ts(14, 28), 'O', 10_050000000, 300, 200),
+	)
+	trades := filepath.Join(dir, "trades.dbn")
+	writeDbnTestFile(t, trades, metadata(dbn.Schema_Trades, "WIDG", "8"),
+		trade(7, ts(14, 26), 9_990000000, 10),
+		trade(7, ts(14, 30), 10_050000000, 400),
+	)
+	return []string{imbalances, trades}
+}
+
+func TestWriteAuctions(t *testing.T) {
+	imbalance := filepath.Join("..", "..", "tests", "data", "test_data.imbalance.v3.dbn.zst")
+	dst := filepath.Join(t.TempDir(), "auctions.csv")
+	if err := WriteAuctions([]string{imbalance}, AuctionsOptions{}, dst); err != nil {
+		t.Fatalf("WriteAuctions returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "date,instrument_id,symbol,auction_type,kind,first_ts,last_ts,num_updates," +
+		"ref_price,indicative_price,auction_price,paired_qty,total_imbalance_qty,side,max_paired_qty,max_imbalance_qty," +
+		"uncross_ts,uncross_price,uncross_size\n" +
+		"2021-10-04,9439,SPOT,O,opening,2021-10-04T13:25:00.63386435Z,2021-10-04T13:25:10.208124734Z,2," +
+		"229.99,,,1719,281,B,1719,2000,,,\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := WriteAuctions([]string{imbalance}, AuctionsOptions{Format: "xlsx"}, dst); err == nil {
+		t.Fatalf("expected error for unknown format, got nil")
+	}
+}
+
+func TestWriteAuctions_UncrossAcrossFiles(t *testing.T) {
+	sources := writeAuctionsTestFiles(t)
+	dst := filepath.Join(t.TempDir(), "auctions.csv")
+	if err := WriteAuctions(sources, AuctionsOptions{}, dst); err != nil {
+		t.Fatalf("WriteAuctions returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "date,instrument_id,symbol,auction_type,kind,first_ts,last_ts,num_updates," +
+		"ref_price,indicative_price,auction_price,paired_qty,total_imbalance_qty,side,max_paired_qty,max_imbalance_qty," +
+		"uncross_ts,uncross_price,uncross_size\n" +
+		"2024-01-02,8,WIDG,H,halt,2024-01-02T14:10:00Z,2024-01-02T14:10:00Z,1," +
+		"20,,,50,50,B,50,50,,,\n" +
+		"2024-01-02,7,ACME,O,opening,2024-01-02T14:25:00Z,2024-01-02T14:28:00Z,2," +
+		"10.05,,,300,200,B,300,500,2024-01-02T14:30:00Z,10.05,400\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteAuctions_ParquetNulls(t *testing.T) {
+	sources := writeAuctionsTestFiles(t)
+	dst := filepath.Join(t.TempDir(), "auctions.parquet")
+	if err := WriteAuctions(sources, AuctionsOptions{}, dst); err != nil {
+		t.Fatalf("WriteAuctions returned error: %v", err)
+	}
+
+	reader, err := pqfile.OpenParquetFile(dst, false)
+	if err != nil {
+		t.Fatalf("OpenParquetFile: %v", err)
+	}
+	defer reader.Close()
+	if got := reader.MetaData().Schema.NumColumns(); got != len(auctionsColumns) {
+		t.Fatalf("column count mismatch: got %d want %d", got, len(auctionsColumns))
+	}
+	table, err := readParquetRowGroup(reader.RowGroup(0))
+	if err != nil {
+		t.Fatalf("readParquetRowGroup returned error: %v", err)
+	}
+	if table.numRows != 2 {
+		t.Fatalf("row count mismatch: got %d want 2", table.numRows)
+	}
+	// The zero indicative prices and WIDG's missing uncross are null
+	for _, column := range []string{"indicative_price", "uncross_ts", "uncross_price", "uncross_size"} {
+		if valid := table.columns[column].valid; valid[0] || (column != "indicative_price") != valid[1] {
+			t.Fatalf("column %s validity mismatch: %v", column, valid)
+		}
+	}
+	if got := table.columns["uncross_price"].floats[1]; got != 10.05 {
+		t.Fatalf("uncross_price mismatch: got %v want 10.05", got)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_futures "github.com/NimbleMarkets/dbn-go/futures"
+	dbn_hist "github.com/NimbleMarkets/dbn-go/hist"
+)
+
+// ContinuousOptions controls how a continuous futures series is built by WriteContinuousDbn.
+type ContinuousOptions struct {
+	Symbol         string        // Continuous symbol, such as "ES.c.0", whose rule and rank select the contracts
+	Adjustment     string        // Price adjustment before each roll: none, difference, or ratio
+	InstrumentID   uint32        // Synthetic instrument ID of the output records
+	BarInterval    time.Duration // Interval of bars aggregated from trades: 1s, 1m, 1h, or 24h
+	RollBefore     time.Duration // With the calendar rule and definitions, roll this long before expiration
+	ResolutionFile string        // Optional JSON symbology resolution of Symbol, from 'dbn-go-hist resolve --json', for the calendar rule
+	Dataset        string        // Dataset of the output Metadata; the sources' if empty
+	ForceZstdInput bool          // Force input to be zstd, irrespective of filename suffix
+	Verbose        bool          // Print each roll to stderr
+}
+
+// WriteContinuousDbn builds a back-adjusted continuous futures series from DBN source files of
+// OHLCV bars or trades across contract months, together with any definitions and statistics,
+// and writes it to `writer` as DBN OHLCV records with the synthetic instrument ID.
+func WriteContinuousDbn(sourceFiles []string, opts ContinuousOptions, writer io.Writer) error {
+	symbol, err := dbn_futures.ParseContinuous(opts.Symbol)
+	if err != nil {
+		return err
+	}
+	adjustment, err := dbn_futures.ParseAdjustment(opts.Adjustment)
+	if err != nil {
+		return err
+	}
+	seriesOpts := dbn_futures.SeriesOptions{
+		Symbol:       symbol,
+		Adjustment:   adjustment,
+		InstrumentID: opts.InstrumentID,
+		BarInterval:  opts.BarInterval,
+		RollBefore:   opts.RollBefore,
+	}
+	if opts.ResolutionFile != "" {
+		if seriesOpts.Calendar, err = readRollCalendar(opts.ResolutionFile, opts.Symbol); err != nil {
+			return err
+		}
+	}
+	builder, err := dbn_futures.NewSeriesBuilder(seriesOpts)
+	if err != nil {
+		return err
+	}
+
+	dataset := opts.Dataset
+	for _, sourceFile := range sourceFiles {
+		sourceDataset, err := visitContinuousSource(sourceFile, opts.ForceZstdInput, builder)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+		if dataset == "" {
+			dataset = sourceDataset
+		}
+	}
+
+	series, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		for _, roll := range series.Rolls {
+			fmt.Fprintf(os.Stderr, "%s rolled from %d to %d at %s\n", opts.Symbol, roll.FromID, roll.ToID,
+				time.Unix(0, int64(roll.Ts)).UTC().Format(time.RFC3339))
+		}
+	}
+
+	dbnWriter, err := dbn.NewDbnWriter(writer, series.Metadata(dataset))
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	for i := range series.Bars {
+		if err := dbnWriter.Write(&series.Bars[i]); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return nil
+}
+
+// visitContinuousSource visits the records of a DBN source file with the builder, returning its dataset.
+func visitContinuousSource(sourceFile string, forceZstdInput bool, builder *dbn_futures.SeriesBuilder) (string, error) {
+	sourceReader, sourceCloser, err := dbn.MakeCompressedReader(sourceFile, forceZstdInput)
+	if err != nil {
+		return "", err
+	}
+	defer sourceCloser.Close()
+
+	dbnScanner := dbn.NewDbnScanner(sourceReader)
+	metadata, err := dbnScanner.Metadata()
+	if err != nil {
+		return "", fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if err := builder.FillFromMetadata(metadata); err != nil {
+		return "", err
+	}
+	for dbnScanner.Next() {
+		if err := dbnScanner.Visit(builder); err != nil {
+			return "", err
+		}
+	}
+	if err := dbnScanner.Error(); err != nil && err != io.EOF {
+		return "", fmt.Errorf("scanner error: %w", err)
+	}
+	return metadata.Dataset, nil
+}
+
+// readRollCalendar reads the roll calendar of a continuous symbol from a JSON symbology resolution file.
+func readRollCalendar(resolutionFile string, symbol string) (*dbn_futures.RollCalendar, error) {
+	data, err := os.ReadFile(resolutionFile)
+	if err != nil {
+		return nil, err
+	}
+	var resolution dbn_hist.Resolution
+	if err := json.Unmarshal(data, &resolution); err != nil {
+		return nil, fmt.Errorf("failed to parse resolution %s: %w", resolutionFile, err)
+	}
+	return dbn_futures.RollCalendarFromResolution(&resolution, symbol)
+}
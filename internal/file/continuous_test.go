@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// writeContractOhlcv writes a DBN file of daily candles of ESZ4 (1) and ESH5 (2), whose closes are
+// 100 and 150 on 2024-12-19, 200 and 300 on 2024-12-20, and ESH5's 310 on 2024-12-21.
+func writeContractOhlcv(t *testing.T, filename string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Ohlcv1D,
+		Dataset:       "GLBX.MDP3",
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Mappings: []dbn.SymbolMapping{
+			{RawSymbol: "ESZ4", Intervals: []dbn.MappingInterval{{StartDate: 20241219, EndDate: 20241221, Symbol: "1"}}},
+			{RawSymbol: "ESH5", Intervals: []dbn.MappingInterval{{StartDate: 20241219, EndDate: 20241222, Symbol: "2"}}},
+		},
+	}
+	writer, err := dbn.NewDbnWriter(&buf, metadata)
+	if err != nil {
+		t.Fatalf("NewDbnWriter: %v", err)
+	}
+	for _, bar := range []struct {
+		id  uint32
+		day int
+		px  int64
+	}{{1, 19, 100}, {2, 19, 150}, {1, 20, 200}, {2, 20, 300}, {2, 21, 310}} {
+		record := dbn.OhlcvMsg{
+			Header: dbn.RHeader{
+				RType:        dbn.RType_Ohlcv1D,
+				PublisherID:  1,
+				InstrumentID: bar.id,
+				TsEvent:      uint64(time.Date(2024, 12, bar.day, 0, 0, 0, 0, time.UTC).UnixNano()),
+			},
+			Open: bar.px, High: bar.px, Low: bar.px, Close: bar.px, Volume: 10,
+		}
+		if err := writer.Write(&record); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestWriteContinuousDbn_Resolution(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeContractOhlcv(t, src)
+	resolution := filepath.Join(dir, "resolution.json")
+	resolutionJson := `{"result":{"ES.c.0":[{"d0":"2024-12-01","d1":"2024-12-20","s":"ESZ4"},{"d0":"2024-12-20","d1":"2025-03-21","s":"ESH5"}]},` +
+		`"partial":[],"not_found":[],"stype_in":"continuous","stype_out":"raw_symbol"}`
+	if err := os.WriteFile(resolution, []byte(resolutionJson), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var buf bytes.Buffer
+	opts := ContinuousOptions{Symbol: "ES.c.0", Adjustment: "difference", InstrumentID: 42, ResolutionFile: resolution}
+	if err := WriteContinuousDbn([]string{src}, opts, &buf); err != nil {
+		t.Fatalf("WriteContinuousDbn: %v", err)
+	}
+
+	records, metadata, err := dbn.ReadDBNToSlice[dbn.OhlcvMsg](&buf)
+	if err != nil {
+		t.Fatalf("ReadDBNToSlice: %v", err)
+	}
+	if metadata.Dataset != "GLBX.MDP3" || metadata.Schema != dbn.Schema_Ohlcv1D || metadata.StypeIn != dbn.SType_Continuous {
+		t.Fatalf("metadata mismatch: %+v", metadata)
+	}
+	if len(metadata.Mappings) != 1 || metadata.Mappings[0].RawSymbol != "ES.c.0" || metadata.Mappings[0].Intervals[0].Symbol != "42" {
+		t.Fatalf("mappings mismatch: %+v", metadata.Mappings)
+	}
+
+	// ESH5 from 2024-12-20, with ESZ4's close raised by the 150 - 100 spread before it
+	want := []int64{150, 300, 310}
+	if len(records) != len(want) {
+		t.Fatalf("record count mismatch: got %d want %d", len(records), len(want))
+	}
+	for i, record := range records {
+		if record.Close != want[i] || record.Header.InstrumentID != 42 {
+			t.Fatalf("record %d mismatch: got close %d id %d, want close %d id 42", i, record.Close, record.Header.InstrumentID, want[i])
+		}
+	}
+}
+
+func TestWriteContinuousDbn_Errors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeContractOhlcv(t, src)
+
+	for _, opts := range []ContinuousOptions{
+		{Symbol: "ES"},
+		{Symbol: "ES.c.0", Adjustment: "log"},
+		{Symbol: "ES.c.0"}, // no definitions nor resolution
+		{Symbol: "ES.n.0"}, // no open interest statistics
+	} {
+		var buf bytes.Buffer
+		if err := WriteContinuousDbn([]string{src}, opts, &buf); err == nil {
+			t.Fatalf("WriteContinuousDbn(%+v) expected an error", opts)
+		}
+	}
+}
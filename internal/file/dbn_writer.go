@@ -0,0 +1,276 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/NimbleMarkets/dbn-go"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/valyala/fastjson"
+)
+
+// DbnWriteOptions controls how DBN Metadata is reconstructed when converting to DBN.
+type DbnWriteOptions struct {
+	MetadataFile   string // Optional JSON Metadata file; if empty, the Metadata is inferred from the records
+	Dataset        string // Overrides the inferred Dataset, if non-empty
+	Schema         string // Overrides the inferred Schema, if non-empty
+	ForceZstdInput bool   // Force input to be zstd, irrespective of filename suffix
+}
+
+// WriteJsonFileAsDbn converts a file of DBN JSON records, one per line, to DBN, writing to `writer`.
+// Records with a "symbol" field, as with Databento's `map_symbols`, populate the Metadata's mappings.
+// Records with a "ts_out" field keep it, and set the inferred Metadata's TsOut.
+func WriteJsonFileAsDbn(sourceFile string, opts DbnWriteOptions, writer io.Writer) error {
+	jsonFile, jsonCloser, err := dbn.MakeCompressedReader(sourceFile, opts.ForceZstdInput)
+	if err != nil {
+		return err
+	}
+	defer jsonCloser.Close()
+
+	collector := newDbnRecordCollector()
+	jsonScanner := dbn.NewJsonScanner(jsonFile)
+	for jsonScanner.Next() {
+		if err := jsonScanner.Visit(collector); err != nil {
+			return fmt.Errorf("json decode failed: %w", err)
+		}
+		if symbol := fastjson.GetString(jsonScanner.GetLastRecord(), "symbol"); symbol != "" {
+			collector.observeSymbol(symbol)
+		}
+		if tsOut, ok := jsonScanner.GetLastTsOut(); ok {
+			collector.observeTsOut(tsOut)
+		}
+	}
+	if err := jsonScanner.Error(); err != nil && err != io.EOF {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+
+	return collector.writeDbn(opts, writer)
+}
+
+// WriteParquetFileAsDbn converts a Parquet file, with a layout from ParquetGroupNodeForDbnSchema, to DBN, writing to `writer`.
+// The `symbol` column populates the Metadata's mappings, and a `ts_out` column is kept and sets
+// the inferred Metadata's TsOut.
+// Prices are rounded to the nearest nanounit; NaN or out-of-range prices become UNDEF_PRICE.
+func WriteParquetFileAsDbn(sourceFile string, opts DbnWriteOptions, writer io.Writer) error {
+	pqReader, err := pqfile.OpenParquetFile(sourceFile, false)
+	if err != nil {
+		return err
+	}
+	defer pqReader.Close()
+
+	collector := newDbnRecordCollector()
+	for i := 0; i < pqReader.NumRowGroups(); i++ {
+		table, err := readParquetRowGroup(pqReader.RowGroup(i))
+		if err != nil {
+			return fmt.Errorf("failed to read row group %d: %w", i, err)
+		}
+		if err := parquetTableToRecords(table, collector); err != nil {
+			return fmt.Errorf("failed to convert row group %d: %w", i, err)
+		}
+	}
+	return collector.writeDbn(opts, writer)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// dbnRecordCollector is a dbn.Visitor which gathers records and what is
+// needed to reconstruct their Metadata.
+type dbnRecordCollector struct {
+	records    []dbn.RecordEncoder
+	tsOuts     []uint64 // the `ts_out` of each record, or UNDEF_TIMESTAMP
+	hasTsOut   bool     // true if any record has a `ts_out`
+	lastHeader dbn.RHeader
+	minTs      uint64
+	maxTs      uint64
+	rtypes     map[dbn.RType]int
+	publishers map[uint16]int
+	mappings   *dbn.SymbolMappingBuilder
+}
+
+func newDbnRecordCollector() *dbnRecordCollector {
+	return &dbnRecordCollector{
+		minTs:      math.MaxUint64,
+		rtypes:     make(map[dbn.RType]int),
+		publishers: make(map[uint16]int),
+		mappings:   dbn.NewSymbolMappingBuilder(),
+	}
+}
+
+// collect appends the record and tracks its header.
+func (c *dbnRecordCollector) collect(record dbn.RecordEncoder, header *dbn.RHeader) error {
+	c.records = append(c.records, record)
+	c.tsOuts = append(c.tsOuts, dbn.UNDEF_TIMESTAMP)
+	c.lastHeader = *header
+	c.minTs = min(c.minTs, header.TsEvent)
+	c.maxTs = max(c.maxTs, header.TsEvent)
+	c.rtypes[header.RType]++
+	c.publishers[header.PublisherID]++
+	return nil
+}
+
+// observeSymbol records the symbol of the last collected record.
+func (c *dbnRecordCollector) observeSymbol(symbol string) {
+	c.mappings.Observe(c.lastHeader.TsEvent, c.lastHeader.InstrumentID, symbol)
+}
+
+// observeTsOut records the `ts_out` of the last collected record.
+func (c *dbnRecordCollector) observeTsOut(tsOut uint64) {
+	if len(c.tsOuts) != 0 {
+		c.tsOuts[len(c.tsOuts)-1] = tsOut
+		c.hasTsOut = true
+	}
+}
+
+// metadata returns the Metadata from `opts.MetadataFile`, or else one inferred from the collected records.
+func (c *dbnRecordCollector) metadata(opts DbnWriteOptions) (*dbn.Metadata, error) {
+	var metadata *dbn.Metadata
+	if opts.MetadataFile != "" {
+		metaFile, err := dbn.OpenSource(opts.MetadataFile)
+		if err != nil {
+			return nil, err
+		}
+		defer metaFile.Close()
+		if metadata, err = dbn.ReadJsonMetadata(metaFile); err != nil {
+			return nil, fmt.Errorf("failed to read metadata %s: %w", opts.MetadataFile, err)
+		}
+	} else {
+		metadata = &dbn.Metadata{
+			VersionNum:    dbn.HeaderVersion3,
+			Schema:        mostCommonKey(c.rtypes, dbn.RType_Unknown).Schema(),
+			Dataset:       dbn.Publisher(mostCommonKey(c.publishers, 0)).Dataset().String(),
+			End:           dbn.UNDEF_TIMESTAMP,
+			StypeIn:       dbn.SType_RawSymbol,
+			StypeOut:      dbn.SType_InstrumentId,
+			SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+			Symbols:       c.mappings.Symbols(),
+			Partial:       []string{},
+			NotFound:      []string{},
+			Mappings:      c.mappings.Mappings(),
+		}
+		if len(c.rtypes) > 1 {
+			metadata.Schema = dbn.Schema_Mixed
+		}
+		if len(c.records) != 0 {
+			metadata.Start = c.minTs
+			metadata.End = c.maxTs + 1
+		}
+		if c.hasTsOut {
+			metadata.TsOut = 1
+		}
+	}
+	if opts.Dataset != "" {
+		metadata.Dataset = opts.Dataset
+	}
+	if opts.Schema != "" {
+		schema, err := dbn.SchemaFromString(opts.Schema)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Schema = schema
+	}
+	return metadata, nil
+}
+
+// writeDbn writes the Metadata and the collected records as DBN, with their `ts_out` if the Metadata has TsOut set.
+func (c *dbnRecordCollector) writeDbn(opts DbnWriteOptions, writer io.Writer) error {
+	metadata, err := c.metadata(opts)
+	if err != nil {
+		return err
+	}
+	dbnWriter, err := dbn.NewDbnWriter(writer, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	for i, record := range c.records {
+		if metadata.TsOut != 0 {
+			err = dbnWriter.WriteWithTsOut(record, c.tsOuts[i])
+		} else {
+			err = dbnWriter.Write(record)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return nil
+}
+
+// mostCommonKey returns the key with the highest count, or `dflt` if empty.
+// Ties go to the lowest key, so the result is deterministic.
+func mostCommonKey[K uint16 | dbn.RType](counts map[K]int, dflt K) K {
+	keys := make([]K, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	best, bestCount := dflt, 0
+	for _, k := range keys {
+		if counts[k] > bestCount {
+			best, bestCount = k, counts[k]
+		}
+	}
+	return best
+}
+
+func (c *dbnRecordCollector) OnMbp0(record *dbn.Mbp0Msg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnMbp1(record *dbn.Mbp1Msg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnMbp10(record *dbn.Mbp10Msg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnMbo(record *dbn.MboMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnOhlcv(record *dbn.OhlcvMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnCmbp1(record *dbn.Cmbp1Msg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnBbo(record *dbn.BboMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnImbalance(record *dbn.ImbalanceMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnStatMsg(record *dbn.StatMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnStatusMsg(record *dbn.StatusMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnInstrumentDefMsg(record *dbn.InstrumentDefMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnErrorMsg(record *dbn.ErrorMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnSystemMsg(record *dbn.SystemMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnSymbolMappingMsg(record *dbn.SymbolMappingMsg) error {
+	return c.collect(record, &record.Header)
+}
+
+func (c *dbnRecordCollector) OnStreamEnd() error {
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+func TestWriteParquetFileAsDbn_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		schema string
+	}{
+		{name: "ohlcv-1s", src: "test_data.ohlcv-1s.v3.dbn.zst"},
+		{name: "trades", src: "test_data.trades.v3.dbn.zst"},
+		{name: "mbp1", src: "test_data.mbp-1.v3.dbn.zst"},
+		{name: "tbbo", src: "test_data.tbbo.v3.dbn.zst", schema: "tbbo"},
+		{name: "imbalance", src: "test_data.imbalance.v3.dbn.zst"},
+		{name: "statistics", src: "test_data.statistics.v3.dbn.zst"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join("..", "..", "tests", "data", tt.src)
+			pqPath := filepath.Join(t.TempDir(), "out.parquet")
+			if err := WriteDbnFileAsParquet(src, false, pqPath); err != nil {
+				t.Fatalf("WriteDbnFileAsParquet(%s) returned error: %v", tt.name, err)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteParquetFileAsDbn(pqPath, DbnWriteOptions{Schema: tt.schema}, &buf); err != nil {
+				t.Fatalf("WriteParquetFileAsDbn(%s) returned error: %v", tt.name, err)
+			}
+
+			origMetadata, origRecords := collectDbnRecords(t, src)
+			newMetadata, newRecords := collectDbnReader(t, &buf)
+			if len(newRecords) != len(origRecords) {
+				t.Fatalf("record count mismatch: got %d want %d", len(newRecords), len(origRecords))
+			}
+			for i := range origRecords {
+				if !reflect.DeepEqual(newRecords[i], origRecords[i]) {
+					t.Fatalf("record %d mismatch:\n got  %+v\n want %+v", i, newRecords[i], origRecords[i])
+				}
+			}
+			if newMetadata.Schema != origMetadata.Schema {
+				t.Fatalf("schema mismatch: got %s want %s", newMetadata.Schema, origMetadata.Schema)
+			}
+			if newMetadata.Dataset != origMetadata.Dataset {
+				t.Fatalf("dataset mismatch: got %s want %s", newMetadata.Dataset, origMetadata.Dataset)
+			}
+			if !reflect.DeepEqual(newMetadata.Symbols, origMetadata.Symbols) {
+				t.Fatalf("symbols mismatch: got %v want %v", newMetadata.Symbols, origMetadata.Symbols)
+			}
+		})
+	}
+}
+
+func TestWriteJsonFileAsDbn_WithMetadataFile(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.ohlcv-1s.json")
+	opts := DbnWriteOptions{
+		MetadataFile: filepath.Join("..", "..", "tests", "data", "test_data.ohlcv-1s.meta.json"),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJsonFileAsDbn(src, opts, &buf); err != nil {
+		t.Fatalf("WriteJsonFileAsDbn returned error: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("..", "..", "tests", "data", "test_data.ohlcv-1s.dbn"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("converted DBN does not match fixture")
+	}
+}
+
+func TestWriteJsonFileAsDbn_InfersMetadata(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.ohlcv-1s.json")
+
+	var buf bytes.Buffer
+	if err := WriteJsonFileAsDbn(src, DbnWriteOptions{}, &buf); err != nil {
+		t.Fatalf("WriteJsonFileAsDbn returned error: %v", err)
+	}
+
+	metadata, records := collectDbnReader(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("record count mismatch: got %d want 2", len(records))
+	}
+	if metadata.Schema != dbn.Schema_Ohlcv1S {
+		t.Fatalf("schema mismatch: got %s want %s", metadata.Schema, dbn.Schema_Ohlcv1S)
+	}
+	if metadata.Dataset != "GLBX.MDP3" {
+		t.Fatalf("dataset mismatch: got %s want GLBX.MDP3", metadata.Dataset)
+	}
+	if metadata.VersionNum != dbn.HeaderVersion3 {
+		t.Fatalf("version mismatch: got %d want %d", metadata.VersionNum, dbn.HeaderVersion3)
+	}
+}
+
+func TestWriteJsonFileAsDbn_UndefRoundTrip(t *testing.T) {
+	for _, name := range []string{"test_data.statistics.v3.dbn.zst", "test_data.definition.v3.dbn.zst"} {
+		t.Run(name, func(t *testing.T) {
+			src := filepath.Join("..", "..", "tests", "data", name)
+			origMetadata, origRecords := collectDbnRecords(t, src)
+
+			// Undefined values are written as null with pretty_px and pretty_ts
+			var prettyBuf bytes.Buffer
+			if err := WriteDbnFileAsJsonWithOptions(src, false, JsonWriteOptions{PrettyPx: true, PrettyTs: true}, &prettyBuf); err != nil {
+				t.Fatalf("WriteDbnFileAsJsonWithOptions returned error: %v", err)
+			}
+			if !bytes.Contains(prettyBuf.Bytes(), []byte(`":null`)) {
+				t.Fatalf("expected null values in JSON")
+			}
+			if bytes.Contains(prettyBuf.Bytes(), []byte("18446744073709551615")) {
+				t.Fatalf("expected no timestamp sentinels in JSON")
+			}
+
+			// And by default
+			var defaultBuf bytes.Buffer
+			if err := WriteDbnFileAsJson(src, false, &defaultBuf); err != nil {
+				t.Fatalf("WriteDbnFileAsJson returned error: %v", err)
+			}
+			if !bytes.Contains(defaultBuf.Bytes(), []byte(`"ts_ref":null`)) && !bytes.Contains(defaultBuf.Bytes(), []byte(`"expiration":null`)) {
+				t.Fatalf("expected null values in default JSON")
+			}
+			if bytes.Contains(defaultBuf.Bytes(), []byte("18446744073709551615")) {
+				t.Fatalf("expected no timestamp sentinels in default JSON")
+			}
+
+			// Otherwise as their sentinels, as Databento does
+			var jsonBuf bytes.Buffer
+			if err := WriteDbnFileAsJsonWithOptions(src, false, JsonWriteOptions{Sentinels: true}, &jsonBuf); err != nil {
+				t.Fatalf("WriteDbnFileAsJsonWithOptions returned error: %v", err)
+			}
+			if !bytes.Contains(jsonBuf.Bytes(), []byte(`"18446744073709551615"`)) {
+				t.Fatalf("expected sentinel values in JSON")
+			}
+
+			// And read back as the sentinels
+			dir := t.TempDir()
+			jsonPath, metaPath := filepath.Join(dir, "records.json"), filepath.Join(dir, "meta.json")
+			metaJson, err := json.Marshal(origMetadata)
+			if err != nil {
+				t.Fatalf("failed to marshal metadata: %v", err)
+			}
+			if err := os.WriteFile(metaPath, metaJson, 0644); err != nil {
+				t.Fatalf("failed to write metadata: %v", err)
+			}
+			if err := os.WriteFile(jsonPath, jsonBuf.Bytes(), 0644); err != nil {
+				t.Fatalf("failed to write JSON: %v", err)
+			}
+			var dbnBuf bytes.Buffer
+			if err := WriteJsonFileAsDbn(jsonPath, DbnWriteOptions{MetadataFile: metaPath}, &dbnBuf); err != nil {
+				t.Fatalf("WriteJsonFileAsDbn returned error: %v", err)
+			}
+			_, newRecords := collectDbnReader(t, &dbnBuf)
+			if !reflect.DeepEqual(newRecords, origRecords) {
+				t.Fatalf("records mismatch after JSON round trip")
+			}
+		})
+	}
+}
+
+// writeDbnTestFile writes the records to a DBN file with the metadata.
+func writeDbnTestFile(t *testing.T, filename string, metadata *dbn.Metadata, records ...dbn.RecordEncoder) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, err := dbn.NewDbnWriter(&buf, metadata)
+	if err != nil {
+		t.Fatalf("NewDbnWriter: %v", err)
+	}
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func collectDbnRecords(t *testing.T, filename string) (*dbn.Metadata, []dbn.RecordEncoder) {
+	t.Helper()
+
+	reader, closer, err := dbn.MakeCompressedReader(filename, false)
+	if err != nil {
+		t.Fatalf("MakeCompressedReader(%q): %v", filename, err)
+	}
+	defer closer.Close()
+	return collectDbnReader(t, reader)
+}
+
+func collectDbnReader(t *testing.T, reader io.Reader) (*dbn.Metadata, []dbn.RecordEncoder) {
+	t.Helper()
+
+	scanner := dbn.NewDbnScanner(reader)
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		t.Fatalf("Metadata: %v", err)
+	}
+	collector := newDbnRecordCollector()
+	for scanner.Next() {
+		if err := scanner.Visit(collector); err != nil {
+			t.Fatalf("Visit: %v", err)
+		}
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		t.Fatalf("scanner error: %v", err)
+	}
+	return metadata, collector.records
+}
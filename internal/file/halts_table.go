@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// HaltsTableOptions controls how status files are read by WriteHaltsTable.
+type HaltsTableOptions struct {
+	ForceZstdInput bool // Force input to be zstd, irrespective of filename suffix
+}
+
+// haltsTableHeader is the CSV header of WriteHaltsTable.
+var haltsTableHeader = []string{"start", "end", "duration", "instrument_id", "symbol", "action", "reason"}
+
+// WriteHaltsTable tracks the status records of DBN source files with a dbn.TradingStatusTracker
+// and writes the intervals during which instruments were halted, paused, or suspended to `destFile`
+// as CSV, sorted by start.  Timestamps are RFC 3339 in UTC and durations are in seconds; both are
+// empty for intervals which had not ended.  Actions and reasons are their snake_case names,
+// or codes if unknown.
+func WriteHaltsTable(sourceFiles []string, opts HaltsTableOptions, destFile string) error {
+	tracker := dbn.NewTradingStatusTracker()
+	var symbolMaps []*dbn.TsSymbolMap
+	for _, sourceFile := range sourceFiles {
+		tsm, err := readHaltsDbnFile(sourceFile, tracker, opts.ForceZstdInput)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+		symbolMaps = append(symbolMaps, tsm)
+	}
+
+	writer, writerCloser, err := dbn.MakeCompressedWriter(destFile, false)
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	defer writerCloser()
+	return writeHaltsTableCsv(tracker.AllHaltIntervals(), symbolMaps, writer)
+}
+
+// readHaltsDbnFile updates the tracker with the status records of a DBN file.
+// Returns the file's symbol map.
+func readHaltsDbnFile(sourceFile string, tracker *dbn.TradingStatusTracker, forceZstd bool) (*dbn.TsSymbolMap, error) {
+	reader, closer, err := dbn.MakeCompressedReader(sourceFile, forceZstd)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	scanner := dbn.NewDbnScanner(reader)
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	tsm := dbn.NewTsSymbolMap()
+	if err := tsm.FillFromMetadata(metadata); err != nil {
+		return nil, fmt.Errorf("failed to fill symbol map: %w", err)
+	}
+	return tsm, tracker.ReadDbn(scanner)
+}
+
+// writeHaltsTableCsv writes the halt intervals as CSV, with a header row.
+func writeHaltsTableCsv(halts []dbn.HaltInterval, symbolMaps []*dbn.TsSymbolMap, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(haltsTableHeader); err != nil {
+		return err
+	}
+
+	fields := make([]string, len(haltsTableHeader))
+	for _, halt := range halts {
+		start := dbn.TimestampToTime(halt.Start).UTC()
+		fields[0] = start.Format(time.RFC3339Nano)
+		fields[1], fields[2] = "", ""
+		if !halt.IsOpen() {
+			fields[1] = dbn.TimestampToTime(halt.End).UTC().Format(time.RFC3339Nano)
+			fields[2] = strconv.FormatFloat(halt.Duration().Seconds(), 'f', -1, 64)
+		}
+		fields[3] = strconv.FormatUint(uint64(halt.InstrumentID), 10)
+		fields[4] = ""
+		for _, tsm := range symbolMaps {
+			if symbol := tsm.Get(start, halt.InstrumentID); symbol != "" {
+				fields[4] = symbol
+				break
+			}
+		}
+		fields[5] = haltsTableName(halt.Action.String(), uint8(halt.Action))
+		fields[6] = haltsTableName(halt.Reason.String(), uint8(halt.Reason))
+		if err := csvWriter.Write(fields); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// haltsTableName returns the name of an action or reason, or its code if it has none.
+func haltsTableName(name string, code uint8) string {
+	if name == "" {
+		return strconv.Itoa(int(code))
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+func TestWriteHaltsTable(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "status.dbn")
+	var buf bytes.Buffer
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Status,
+		Dataset:       "XNAS.ITCH",
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Mappings: []dbn.SymbolMapping{
+			{RawSymbol: "ACME", Intervals: []dbn.MappingInterval{{StartDate: 20240102, EndDate: 20240103, Symbol: "7"}}},
+		},
+	}
+	writer, err := dbn.NewDbnWriter(&buf, metadata)
+	if err != nil {
+		t.Fatalf("NewDbnWriter: %v", err)
+	}
+	for _, status := range []struct {
+		minute int
+		action dbn.StatusAction
+		reason dbn.StatusAction
+	}{
+		{0, dbn.StatusAction_Trading, dbn.StatusReason_Scheduled},
+		{10, dbn.StatusAction_Pause, dbn.StatusReason_LuldPause},
+		{15, dbn.StatusAction_Trading, dbn.StatusReason_None},
+		{30, dbn.StatusAction_Halt, dbn.StatusReason_NewsPending},
+	} {
+		ts := uint64(time.Date(2024, 1, 2, 14, status.minute, 0, 0, time.UTC).UnixNano())
+		record := dbn.StatusMsg{
+			Header:                dbn.RHeader{RType: dbn.RType_Status, PublisherID: 1, InstrumentID: 7, TsEvent: ts},
+			TsRecv:                ts,
+			Action:                uint16(status.action),
+			Reason:                uint16(status.reason),
+			IsTrading:             uint8(dbn.TriState_NotAvailable),
+			IsQuoting:             uint8(dbn.TriState_NotAvailable),
+			IsShortSellRestricted: uint8(dbn.TriState_NotAvailable),
+		}
+		if err := writer.Write(&record); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	dst := filepath.Join(dir, "halts.csv")
+	if err := WriteHaltsTable([]string{src}, HaltsTableOptions{}, dst); err != nil {
+		t.Fatalf("WriteHaltsTable returned error: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "start,end,duration,instrument_id,symbol,action,reason\n" +
+		"2024-01-02T14:10:00Z,2024-01-02T14:15:00Z,300,7,ACME,pause,luld_pause\n" +
+		"2024-01-02T14:30:00Z,,,7,ACME,halt,news_pending\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -10,7 +10,22 @@ import (
 	"github.com/NimbleMarkets/dbn-go"
 )
 
+// JsonWriteOptions are the options of WriteDbnFileAsJsonWithOptions, selecting among Databento's JSON encodings.
+type JsonWriteOptions struct {
+	PrettyPx   bool // Write prices as decimal strings, like Databento's `pretty_px`
+	PrettyTs   bool // Write timestamps as ISO 8601 strings, like Databento's `pretty_ts`
+	MapSymbols bool // Add a "symbol" field from the file's metadata, like Databento's `map_symbols`
+	Sentinels  bool // Write undefined prices and timestamps as Databento's sentinel integers rather than null, unless pretty
+}
+
+// WriteDbnFileAsJson writes the records of a DBN file as JSON lines, in Databento's JSON encoding
+// with undefined prices and timestamps as null.
 func WriteDbnFileAsJson(sourceFile string, forceZstdInput bool, writer io.Writer) error {
+	return WriteDbnFileAsJsonWithOptions(sourceFile, forceZstdInput, JsonWriteOptions{}, writer)
+}
+
+// WriteDbnFileAsJsonWithOptions writes the records of a DBN file as JSON lines, in the Databento JSON encoding selected by `opts`.
+func WriteDbnFileAsJsonWithOptions(sourceFile string, forceZstdInput bool, opts JsonWriteOptions, writer io.Writer) error {
 	dbnFile, dbnCloser, err := dbn.MakeCompressedReader(sourceFile, forceZstdInput)
 	if err != nil {
 		return err
@@ -18,13 +33,24 @@ func WriteDbnFileAsJson(sourceFile string, forceZstdInput bool, writer io.Writer
 	defer dbnCloser.Close()
 
 	dbnScanner := dbn.NewDbnScanner(dbnFile)
-	_, err = dbnScanner.Metadata()
+	metadata, err := dbnScanner.Metadata()
 	if err != nil {
 		return fmt.Errorf("scanner failed to read metadata: %w", err)
 	}
 
-	visitor := NewJsonWriterVisitor(writer)
+	encoderOpts := dbn.JsonEncoderOptions{PrettyPx: opts.PrettyPx, PrettyTs: opts.PrettyTs, UndefSentinels: opts.Sentinels}
+	if opts.MapSymbols {
+		encoderOpts.SymbolMap = dbn.NewTsSymbolMap()
+		if err := encoderOpts.SymbolMap.FillFromMetadata(metadata); err != nil {
+			return fmt.Errorf("failed to fill symbol map: %w", err)
+		}
+	}
+
+	visitor := NewJsonWriterVisitor(writer, encoderOpts)
 	for dbnScanner.Next() {
+		if tsOut, ok := dbnScanner.GetLastTsOut(); ok {
+			visitor.SetTsOut(tsOut)
+		}
 		if err := dbnScanner.Visit(visitor); err != nil {
 			return fmt.Errorf("json print failed: %w", err)
 		}
@@ -55,70 +81,84 @@ func WriteAsJson[T any](val *T, writer io.Writer) error {
 ////////////////////////////////////////////////////////////////////////////////
 
 // JsonWriterVisitor is an implementation of all the dbn.Visitor interface.
-// It marshals all the records as JSON anout outputs it to its Writer
+// It writes all the records as JSON lines to its Writer, using a dbn.JsonEncoder.
 type JsonWriterVisitor struct {
-	writer io.Writer
+	encoder *dbn.JsonEncoder
+	tsOut   uint64 // the `ts_out` of the next record, or UNDEF_TIMESTAMP
+}
+
+// NewJsonWriterVisitor creates a new JsonWriterVisitor with the given writer and encoding options.
+func NewJsonWriterVisitor(writer io.Writer, opts dbn.JsonEncoderOptions) *JsonWriterVisitor {
+	return &JsonWriterVisitor{encoder: dbn.NewJsonEncoder(writer, opts), tsOut: dbn.UNDEF_TIMESTAMP}
+}
+
+// SetTsOut sets the gateway send timestamp of the next record, which is written as its "ts_out" field.
+// Use with DbnScanner.GetLastTsOut when the stream's Metadata has TsOut set.
+func (v *JsonWriterVisitor) SetTsOut(tsOut uint64) {
+	v.tsOut = tsOut
 }
 
-// NewJsonWriterVisitor creates a new JsonWriterVisitor with the given writer.
-func NewJsonWriterVisitor(writer io.Writer) *JsonWriterVisitor {
-	return &JsonWriterVisitor{writer: writer}
+// write writes the record with its `ts_out`, if any, and clears the `ts_out`.
+func (v *JsonWriterVisitor) write(record dbn.Record) error {
+	tsOut := v.tsOut
+	v.tsOut = dbn.UNDEF_TIMESTAMP
+	return v.encoder.EncodeWithTsOut(record, tsOut)
 }
 
 func (v *JsonWriterVisitor) OnMbp0(record *dbn.Mbp0Msg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnMbp10(record *dbn.Mbp10Msg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnMbp1(record *dbn.Mbp1Msg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnMbo(record *dbn.MboMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnOhlcv(record *dbn.OhlcvMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnCmbp1(record *dbn.Cmbp1Msg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnBbo(record *dbn.BboMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnImbalance(record *dbn.ImbalanceMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnStatMsg(record *dbn.StatMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnStatusMsg(record *dbn.StatusMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnInstrumentDefMsg(record *dbn.InstrumentDefMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnErrorMsg(record *dbn.ErrorMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnSystemMsg(record *dbn.SystemMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnSymbolMappingMsg(record *dbn.SymbolMappingMsg) error {
-	return WriteAsJson(record, v.writer)
+	return v.write(record)
 }
 
 func (v *JsonWriterVisitor) OnStreamEnd() error {
@@ -0,0 +1,213 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_microstructure "github.com/NimbleMarkets/dbn-go/microstructure"
+)
+
+// MicrostructureOptions controls the metrics of WriteMicrostructure.
+type MicrostructureOptions struct {
+	Horizons       []time.Duration // Horizons of the trades' realized spreads and price impacts
+	Interval       time.Duration   // Length of the interval rows
+	TradesFile     string          // Destination of the per-trade rows; none if empty
+	ForceZstdInput bool            // Force input to be zstd, irrespective of filename suffix
+}
+
+// microstructureIntervalHeader is the CSV header of WriteMicrostructure's interval rows.
+var microstructureIntervalHeader = []string{
+	"start", "instrument_id", "symbol", "num_trades", "volume", "buy_volume", "sell_volume",
+	"trade_imbalance", "ofi", "vwap", "twap", "effective_spread_bps",
+}
+
+// microstructureTradeHeader is the CSV header of WriteMicrostructure's trade rows,
+// followed by realized_spread_<horizon> and price_impact_<horizon> per horizon.
+var microstructureTradeHeader = []string{
+	"ts_recv", "instrument_id", "symbol", "price", "size", "side", "bid_px", "ask_px", "midpoint",
+	"tick_sign", "lee_ready_sign", "effective_spread", "effective_spread_bps",
+}
+
+// microstructureSource tracks the symbol maps of the files read by WriteMicrostructure.
+type microstructureSource struct {
+	symbolMaps []*dbn.TsSymbolMap
+}
+
+// symbol returns the symbol of an instrument at a timestamp, or empty string if unmapped.
+func (s *microstructureSource) symbol(ts uint64, instrumentID uint32) string {
+	t := dbn.TimestampToTime(ts).UTC()
+	for _, tsm := range s.symbolMaps {
+		if symbol := tsm.Get(t, instrumentID); symbol != "" {
+			return symbol
+		}
+	}
+	return ""
+}
+
+// WriteMicrostructure analyzes the TBBO, TCBBO, MBP-1, or CMBP-1 records of DBN source files,
+// in order, with a dbn_microstructure.Analyzer.  It writes a row per instrument and interval to
+// `destFile` as CSV, with its trade counts, volumes, trade imbalance, order-flow imbalance, VWAP,
+// TWAP, and volume-weighted effective spread.  If opts.TradesFile is set, it writes a row per trade
+// there, with its quote, classifications, effective spread, and realized spread and price impact
+// per horizon.  Timestamps are RFC 3339 in UTC, and metrics which are undefined are empty.
+func WriteMicrostructure(sourceFiles []string, opts MicrostructureOptions, destFile string) error {
+	if opts.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", opts.Interval)
+	}
+	// The analyzer orders each trade's horizons by duration
+	opts.Horizons = slices.Clone(opts.Horizons)
+	slices.Sort(opts.Horizons)
+
+	writer, writerCloser, err := dbn.MakeCompressedWriter(destFile, false)
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	defer writerCloser()
+	intervalWriter := csv.NewWriter(writer)
+	if err := intervalWriter.Write(microstructureIntervalHeader); err != nil {
+		return err
+	}
+
+	var tradeWriter *csv.Writer
+	if opts.TradesFile != "" {
+		writer, writerCloser, err := dbn.MakeCompressedWriter(opts.TradesFile, false)
+		if err != nil {
+			return fmt.Errorf("failed to create trades writer %w", err)
+		}
+		defer writerCloser()
+		tradeWriter = csv.NewWriter(writer)
+		header := slices.Clone(microstructureTradeHeader)
+		for _, horizon := range opts.Horizons {
+			header = append(header, "realized_spread_"+horizon.String(), "price_impact_"+horizon.String())
+		}
+		if err := tradeWriter.Write(header); err != nil {
+			return err
+		}
+	}
+
+	source := &microstructureSource{}
+	onInterval := func(interval *dbn_microstructure.IntervalMetrics) error {
+		return intervalWriter.Write(microstructureIntervalFields(source, interval))
+	}
+	var onTrade func(trade *dbn_microstructure.TradeMetrics) error
+	if tradeWriter != nil {
+		onTrade = func(trade *dbn_microstructure.TradeMetrics) error {
+			return tradeWriter.Write(microstructureTradeFields(source, trade))
+		}
+	}
+	analyzer, err := dbn_microstructure.NewAnalyzer(dbn_microstructure.Options{Horizons: opts.Horizons, Interval: opts.Interval}, onTrade, onInterval)
+	if err != nil {
+		return err
+	}
+	for _, sourceFile := range sourceFiles {
+		if err := source.readDbnFile(sourceFile, analyzer, opts.ForceZstdInput); err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+	}
+	if err := analyzer.Flush(); err != nil {
+		return err
+	}
+
+	intervalWriter.Flush()
+	if err := intervalWriter.Error(); err != nil {
+		return err
+	}
+	if tradeWriter != nil {
+		tradeWriter.Flush()
+		return tradeWriter.Error()
+	}
+	return nil
+}
+
+// readDbnFile visits the records of a DBN file with the analyzer.
+func (s *microstructureSource) readDbnFile(sourceFile string, analyzer *dbn_microstructure.Analyzer, forceZstd bool) error {
+	reader, closer, err := dbn.MakeCompressedReader(sourceFile, forceZstd)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	scanner := dbn.NewDbnScanner(reader)
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	tsm := dbn.NewTsSymbolMap()
+	if err := tsm.FillFromMetadata(metadata); err != nil {
+		return fmt.Errorf("failed to fill symbol map: %w", err)
+	}
+	s.symbolMaps = append(s.symbolMaps, tsm)
+
+	for scanner.Next() {
+		if err := scanner.Visit(analyzer); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+	return nil
+}
+
+// microstructureIntervalFields returns the CSV fields of an interval.
+func microstructureIntervalFields(source *microstructureSource, interval *dbn_microstructure.IntervalMetrics) []string {
+	return []string{
+		dbn.TimestampToTime(interval.Start).UTC().Format(time.RFC3339Nano),
+		strconv.FormatUint(uint64(interval.InstrumentID), 10),
+		source.symbol(interval.Start, interval.InstrumentID),
+		strconv.Itoa(interval.NumTrades),
+		strconv.FormatUint(interval.Volume, 10),
+		strconv.FormatUint(interval.BuyVolume, 10),
+		strconv.FormatUint(interval.SellVolume, 10),
+		microstructureFloat(interval.TradeImbalance()),
+		strconv.FormatInt(interval.OrderFlowImbalance, 10),
+		microstructureFloat(interval.Vwap),
+		microstructureFloat(interval.Twap),
+		microstructureFloat(interval.EffectiveSpreadBps),
+	}
+}
+
+// microstructureTradeFields returns the CSV fields of a trade.
+func microstructureTradeFields(source *microstructureSource, trade *dbn_microstructure.TradeMetrics) []string {
+	formatPrice := func(price int64) string {
+		if price == dbn.UNDEF_PRICE {
+			return ""
+		}
+		return dbn.Price(price).String()
+	}
+	fields := []string{
+		dbn.TimestampToTime(trade.Ts).UTC().Format(time.RFC3339Nano),
+		strconv.FormatUint(uint64(trade.InstrumentID), 10),
+		source.symbol(trade.Ts, trade.InstrumentID),
+		formatPrice(trade.Price),
+		strconv.FormatUint(uint64(trade.Size), 10),
+		string(rune(trade.Side)),
+		formatPrice(trade.BidPx),
+		formatPrice(trade.AskPx),
+		microstructureFloat(trade.Midpoint),
+		trade.TickSign.String(),
+		trade.LeeReadySign.String(),
+		microstructureFloat(trade.EffectiveSpread),
+		microstructureFloat(trade.EffectiveSpreadBps),
+	}
+	for _, horizon := range trade.Horizons {
+		fields = append(fields, microstructureFloat(horizon.RealizedSpread), microstructureFloat(horizon.PriceImpact))
+	}
+	return fields
+}
+
+// microstructureFloat formats a metric, or returns empty string if it is NaN.
+func microstructureFloat(value float64) string {
+	if math.IsNaN(value) {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
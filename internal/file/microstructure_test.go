@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+func TestWriteMicrostructure(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.tbbo.v3.dbn.zst")
+	reader, closer, err := dbn.MakeCompressedReader(src, false)
+	if err != nil {
+		t.Fatalf("MakeCompressedReader: %v", err)
+	}
+	records, _, err := dbn.ReadDBNToSlice[dbn.Mbp1Msg](reader)
+	closer.Close()
+	if err != nil {
+		t.Fatalf("ReadDBNToSlice: %v", err)
+	}
+
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "intervals.csv")
+	tradesFile := filepath.Join(dir, "trades.csv")
+	opts := MicrostructureOptions{Horizons: []time.Duration{5 * time.Second, time.Second}, Interval: time.Minute, TradesFile: tradesFile}
+	if err := WriteMicrostructure([]string{src}, opts, dst); err != nil {
+		t.Fatalf("WriteMicrostructure returned error: %v", err)
+	}
+
+	intervals, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(intervals)), "\n")
+	if lines[0] != "start,instrument_id,symbol,num_trades,volume,buy_volume,sell_volume,trade_imbalance,ofi,vwap,twap,effective_spread_bps" {
+		t.Fatalf("interval header mismatch: %s", lines[0])
+	}
+	if len(lines) < 2 {
+		t.Fatalf("expected interval rows, got none")
+	}
+
+	trades, err := os.ReadFile(tradesFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(string(trades)), "\n")
+	if !strings.HasSuffix(lines[0], ",effective_spread_bps,realized_spread_1s,price_impact_1s,realized_spread_5s,price_impact_5s") {
+		t.Fatalf("trade header mismatch: %s", lines[0])
+	}
+	if len(lines)-1 != len(records) {
+		t.Fatalf("trade row count mismatch: got %d want %d", len(lines)-1, len(records))
+	}
+	if fields := strings.Split(lines[1], ","); len(fields) != 17 || fields[2] == "" {
+		t.Fatalf("trade row mismatch: %s", lines[1])
+	}
+
+	if err := WriteMicrostructure([]string{src}, MicrostructureOptions{}, dst); err == nil {
+		t.Fatalf("expected error without an interval")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// NbboOptions controls how per-venue quote files are consolidated by WriteNbboDbn.
+type NbboOptions struct {
+	Dataset        string // Dataset of the output Metadata; the first source's if empty
+	ForceZstdInput bool   // Force input to be zstd, irrespective of filename suffix
+	Verbose        bool   // Print the number of locked and crossed updates to stderr
+}
+
+// WriteNbboDbn merges DBN source files of per-venue MBP-1 or BBO records in `ts_recv` order,
+// consolidates them into the best bid and offer across venues with a dbn.NbboBuilder, and writes
+// a CMBP-1 record per source record to `writer` as DBN.
+//
+// If every source has symbol mappings, venues are consolidated by symbol: each source's instrument
+// IDs are resolved by the mappings of its dataset's sources, and the output numbers the symbols
+// from 1, in order, with a mapping of each to its instrument ID.  Otherwise the sources must share
+// instrument IDs, and the output Metadata has the union of their symbol mappings.
+func WriteNbboDbn(sourceFiles []string, opts NbboOptions, writer io.Writer) error {
+	scanners := make([]*dbn.DbnScanner, 0, len(sourceFiles))
+	metadatas := make([]*dbn.Metadata, 0, len(sourceFiles))
+	for _, sourceFile := range sourceFiles {
+		reader, closer, err := dbn.MakeCompressedReader(sourceFile, opts.ForceZstdInput)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+		defer closer.Close()
+		scanner := dbn.NewDbnScanner(reader)
+		metadata, err := scanner.Metadata()
+		if err != nil {
+			return fmt.Errorf("failed to read metadata of '%s': %w", sourceFile, err)
+		}
+		scanners = append(scanners, scanner)
+		metadatas = append(metadatas, metadata)
+	}
+	if len(scanners) == 0 {
+		return fmt.Errorf("no source files")
+	}
+
+	var dbnWriter *dbn.DbnWriter
+	var numLocked, numCrossed, numRecords int
+	builder := dbn.NewNbboBuilder(func(record *dbn.Cmbp1Msg, condition dbn.NbboCondition) error {
+		numRecords++
+		switch condition {
+		case dbn.NbboCondition_Locked:
+			numLocked++
+		case dbn.NbboCondition_Crossed:
+			numCrossed++
+		}
+		if err := dbnWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+		return nil
+	})
+
+	metadata := nbboMetadata(metadatas, opts.Dataset)
+	bySymbol := !slices.ContainsFunc(metadatas, func(m *dbn.Metadata) bool { return len(m.Mappings) == 0 })
+	if bySymbol {
+		for i, source := range metadatas {
+			dataset, err := dbn.DatasetFromString(source.Dataset)
+			if err != nil {
+				return fmt.Errorf("failed to read dataset of '%s': %w", sourceFiles[i], err)
+			}
+			symbolMap := dbn.NewTsSymbolMap()
+			if err := symbolMap.FillFromMetadata(source); err != nil {
+				return fmt.Errorf("failed to fill symbol map of '%s': %w", sourceFiles[i], err)
+			}
+			builder.AddSymbolMap(dataset, symbolMap)
+		}
+		nbboSymbolMappings(metadata, metadatas, builder)
+	}
+	dbnWriter, err := dbn.NewDbnWriter(writer, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	merger := dbn.NewDbnMergeScanner(scanners...)
+	for merger.Next() {
+		if err := merger.Visit(builder); err != nil {
+			return err
+		}
+	}
+	if err := merger.Error(); err != nil && err != io.EOF {
+		return fmt.Errorf("scanner error: %w", err)
+	}
+	if opts.Verbose {
+		fmt.Fprintf(os.Stderr, "%d consolidated records, %d locked, %d crossed\n", numRecords, numLocked, numCrossed)
+	}
+	return nil
+}
+
+// nbboMetadata returns the CMBP-1 Metadata of the sources' consolidation, spanning them all
+// with the union of their symbols and symbol mappings.
+func nbboMetadata(metadatas []*dbn.Metadata, dataset string) *dbn.Metadata {
+	first := metadatas[0]
+	if dataset == "" {
+		dataset = first.Dataset
+	}
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Cmbp1,
+		Dataset:       dataset,
+		Start:         first.Start,
+		End:           first.End,
+		StypeIn:       first.StypeIn,
+		StypeOut:      first.StypeOut,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Partial:       []string{},
+		NotFound:      []string{},
+	}
+	mappings := make(map[string]int) // raw symbol -> index in metadata.Mappings
+	for _, source := range metadatas {
+		metadata.Start = min(metadata.Start, source.Start)
+		metadata.End = max(metadata.End, source.End)
+		for _, symbol := range source.Symbols {
+			if !slices.Contains(metadata.Symbols, symbol) {
+				metadata.Symbols = append(metadata.Symbols, symbol)
+			}
+		}
+		for _, mapping := range source.Mappings {
+			i, ok := mappings[mapping.RawSymbol]
+			if !ok {
+				mappings[mapping.RawSymbol] = len(metadata.Mappings)
+				metadata.Mappings = append(metadata.Mappings, dbn.SymbolMapping{
+					RawSymbol: mapping.RawSymbol,
+					Intervals: slices.Clone(mapping.Intervals),
+				})
+				continue
+			}
+			for _, interval := range mapping.Intervals {
+				if !slices.Contains(metadata.Mappings[i].Intervals, interval) {
+					metadata.Mappings[i].Intervals = append(metadata.Mappings[i].Intervals, interval)
+				}
+			}
+		}
+	}
+	return metadata
+}
+
+// nbboSymbolMappings sets the metadata's mappings to those of the sources' symbols to their
+// consolidated instrument IDs, numbered in symbol order, each spanning the dates the symbol is
+// mapped in any source.  The sources' mappings must be valid, as for TsSymbolMap.FillFromMetadata.
+func nbboSymbolMappings(metadata *dbn.Metadata, metadatas []*dbn.Metadata, builder *dbn.NbboBuilder) {
+	spans := make(map[string]dbn.MappingInterval)
+	for _, source := range metadatas {
+		inverse, _ := source.IsInverseMapping()
+		if inverse {
+			metadata.StypeIn = source.StypeOut
+		}
+		for _, mapping := range source.Mappings {
+			for _, interval := range mapping.Intervals {
+				if interval.Symbol == "" {
+					continue
+				}
+				symbol := mapping.RawSymbol
+				if inverse {
+					symbol = interval.Symbol
+				}
+				if span, ok := spans[symbol]; ok {
+					interval.StartDate = min(interval.StartDate, span.StartDate)
+					interval.EndDate = max(interval.EndDate, span.EndDate)
+				}
+				spans[symbol] = interval
+			}
+		}
+	}
+
+	metadata.StypeOut = dbn.SType_InstrumentId
+	metadata.Mappings = make([]dbn.SymbolMapping, 0, len(spans))
+	for _, symbol := range slices.Sorted(maps.Keys(spans)) {
+		span := spans[symbol]
+		span.Symbol = strconv.FormatUint(uint64(builder.InstrumentIDOf(symbol)), 10)
+		metadata.Mappings = append(metadata.Mappings, dbn.SymbolMapping{RawSymbol: symbol, Intervals: []dbn.MappingInterval{span}})
+	}
+}
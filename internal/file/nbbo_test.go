@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"bytes"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// venueQuote is a venue's top of book of an instrument at `ts_recv`.
+type venueQuote struct {
+	tsRecv       uint64
+	instrumentID uint32
+	bidPx, askPx int64
+}
+
+// writeVenueMbp1 writes a DBN file of a venue's MBP-1 quotes, with a mapping of each of `symbols`,
+// by instrument ID, on 1970-01-01.
+func writeVenueMbp1(t *testing.T, filename string, dataset string, publisherID dbn.Publisher, symbols map[uint32]string, quotes []venueQuote) {
+	t.Helper()
+
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Mbp1,
+		Dataset:       dataset,
+		Start:         quotes[0].tsRecv,
+		End:           quotes[len(quotes)-1].tsRecv + 1,
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+	}
+	for _, id := range slices.Sorted(maps.Keys(symbols)) {
+		metadata.Symbols = append(metadata.Symbols, symbols[id])
+		metadata.Mappings = append(metadata.Mappings, dbn.SymbolMapping{
+			RawSymbol: symbols[id],
+			Intervals: []dbn.MappingInterval{{StartDate: 19700101, EndDate: 19700102, Symbol: strconv.Itoa(int(id))}},
+		})
+	}
+	records := make([]dbn.RecordEncoder, len(quotes))
+	for i, quote := range quotes {
+		records[i] = &dbn.Mbp1Msg{
+			Header: dbn.RHeader{RType: dbn.RType_Mbp1, PublisherID: uint16(publisherID), InstrumentID: quote.instrumentID, TsEvent: quote.tsRecv},
+			Price:  quote.bidPx,
+			Size:   10,
+			Action: byte(dbn.Action_Add),
+			Side:   byte(dbn.Side_Bid),
+			TsRecv: quote.tsRecv,
+			Level:  dbn.BidAskPair{BidPx: quote.bidPx, AskPx: quote.askPx, BidSz: 10, AskSz: 10},
+		}
+	}
+	writeDbnTestFile(t, filename, metadata, records...)
+}
+
+func TestWriteNbboDbn(t *testing.T) {
+	// Nasdaq numbers ACME 7, and NYSE Arca numbers ACME 100 and WIDG 7
+	dir := t.TempDir()
+	xnas := filepath.Join(dir, "xnas.mbp-1.dbn")
+	arcx := filepath.Join(dir, "arcx.mbp-1.dbn")
+	writeVenueMbp1(t, xnas, "XNAS.ITCH", dbn.Publisher_XnasItchXnas, map[uint32]string{7: "ACME"},
+		[]venueQuote{{100, 7, 1000, 1010}, {300, 7, 1005, 1010}})
+	writeVenueMbp1(t, arcx, "ARCX.PILLAR", dbn.Publisher_ArcxPillarArcx, map[uint32]string{100: "ACME", 7: "WIDG"},
+		[]venueQuote{{200, 100, 1001, 1005}, {250, 7, 2000, 2010}, {400, 100, 1001, 1004}})
+
+	var buf bytes.Buffer
+	if err := WriteNbboDbn([]string{xnas, arcx}, NbboOptions{}, &buf); err != nil {
+		t.Fatalf("WriteNbboDbn: %v", err)
+	}
+	records, metadata, err := dbn.ReadDBNToSlice[dbn.Cmbp1Msg](&buf)
+	if err != nil {
+		t.Fatalf("ReadDBNToSlice: %v", err)
+	}
+	if metadata.Schema != dbn.Schema_Cmbp1 || metadata.Dataset != "XNAS.ITCH" || metadata.Start != 100 || metadata.End != 401 {
+		t.Fatalf("metadata mismatch: %+v", metadata)
+	}
+	wantMappings := []dbn.SymbolMapping{
+		{RawSymbol: "ACME", Intervals: []dbn.MappingInterval{{StartDate: 19700101, EndDate: 19700102, Symbol: "1"}}},
+		{RawSymbol: "WIDG", Intervals: []dbn.MappingInterval{{StartDate: 19700101, EndDate: 19700102, Symbol: "2"}}},
+	}
+	if !reflect.DeepEqual(metadata.Mappings, wantMappings) || metadata.StypeOut != dbn.SType_InstrumentId {
+		t.Fatalf("mappings mismatch: %+v", metadata.Mappings)
+	}
+
+	xnasID, arcxID := uint16(dbn.Publisher_XnasItchXnas), uint16(dbn.Publisher_ArcxPillarArcx)
+	want := []struct {
+		instrumentID uint32
+		level        dbn.ConsolidatedBidAskPair
+		flagged      bool
+	}{
+		{1, dbn.ConsolidatedBidAskPair{BidPx: 1000, AskPx: 1010, BidSz: 10, AskSz: 10, BidPb: xnasID, AskPb: xnasID}, false},
+		{1, dbn.ConsolidatedBidAskPair{BidPx: 1001, AskPx: 1005, BidSz: 10, AskSz: 10, BidPb: arcxID, AskPb: arcxID}, false},
+		{2, dbn.ConsolidatedBidAskPair{BidPx: 2000, AskPx: 2010, BidSz: 10, AskSz: 10, BidPb: arcxID, AskPb: arcxID}, false},
+		{1, dbn.ConsolidatedBidAskPair{BidPx: 1005, AskPx: 1005, BidSz: 10, AskSz: 10, BidPb: xnasID, AskPb: arcxID}, true}, // locked
+		{1, dbn.ConsolidatedBidAskPair{BidPx: 1005, AskPx: 1004, BidSz: 10, AskSz: 10, BidPb: xnasID, AskPb: arcxID}, true}, // crossed
+	}
+	if len(records) != len(want) {
+		t.Fatalf("record count mismatch: got %d want %d", len(records), len(want))
+	}
+	for i, record := range records {
+		flagged := dbn.Flags(record.Flags).Has(dbn.NbboFlag_LockedOrCrossed)
+		if record.Header.InstrumentID != want[i].instrumentID || record.Level != want[i].level || flagged != want[i].flagged {
+			t.Fatalf("record %d mismatch: got %d %+v flagged=%v, want %+v", i, record.Header.InstrumentID, record.Level, flagged, want[i])
+		}
+	}
+}
+
+func TestWriteNbboDbn_SharedInstrumentIDs(t *testing.T) {
+	// Without mappings, the venues' instrument IDs are consolidated as they are
+	dir := t.TempDir()
+	xnas := filepath.Join(dir, "xnas.mbp-1.dbn")
+	arcx := filepath.Join(dir, "arcx.mbp-1.dbn")
+	writeVenueMbp1(t, xnas, "XNAS.ITCH", dbn.Publisher_XnasItchXnas, map[uint32]string{7: "ACME"}, []venueQuote{{100, 7, 1000, 1010}})
+	writeVenueMbp1(t, arcx, "ARCX.PILLAR", dbn.Publisher_ArcxPillarArcx, nil, []venueQuote{{200, 7, 1001, 1005}})
+
+	var buf bytes.Buffer
+	if err := WriteNbboDbn([]string{xnas, arcx}, NbboOptions{}, &buf); err != nil {
+		t.Fatalf("WriteNbboDbn: %v", err)
+	}
+	records, metadata, err := dbn.ReadDBNToSlice[dbn.Cmbp1Msg](&buf)
+	if err != nil {
+		t.Fatalf("ReadDBNToSlice: %v", err)
+	}
+	if len(metadata.Mappings) != 1 || metadata.Mappings[0].Intervals[0].Symbol != "7" {
+		t.Fatalf("mappings mismatch: %+v", metadata.Mappings)
+	}
+	if len(records) != 2 || records[1].Header.InstrumentID != 7 || records[1].Level.BidPx != 1001 || records[1].Level.AskPb != uint16(dbn.Publisher_ArcxPillarArcx) {
+		t.Fatalf("records mismatch: %+v", records)
+	}
+}
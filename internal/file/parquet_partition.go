@@ -0,0 +1,307 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	pqschema "github.com/apache/arrow-go/v18/parquet/schema"
+)
+
+// Partition keys supported by ParquetPartitionWriter
+const (
+	PartitionKey_Date   = "date"   // UTC date of ts_event, as YYYY-MM-DD
+	PartitionKey_Symbol = "symbol" // symbol from the Metadata's mappings
+)
+
+// hiveDefaultPartition is Hive's directory value for a null or empty partition value
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// DefaultParquetRowGroupRows is the default number of rows of each row group of a part file.
+// Row groups are buffered in memory until they are full, so this bounds the memory of each open partition.
+const DefaultParquetRowGroupRows = 64 * 1024
+
+// ParseParquetPartitionKeys parses a comma-separated list of partition keys, such as "date,symbol".
+func ParseParquetPartitionKeys(str string) ([]string, error) {
+	keys := make([]string, 0, 2)
+	for _, key := range strings.Split(str, ",") {
+		key = strings.TrimSpace(key)
+		switch key {
+		case PartitionKey_Date, PartitionKey_Symbol:
+		default:
+			return nil, fmt.Errorf("unknown partition key '%s'", key)
+		}
+		for _, existing := range keys {
+			if existing == key {
+				return nil, fmt.Errorf("duplicate partition key '%s'", key)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParquetPartitionOptions controls the layout of a ParquetPartitionWriter.
+type ParquetPartitionOptions struct {
+	PartitionBy  []string // Partition keys, in directory order; see PartitionKey_*
+	MaxFileBytes int64    // Roll to a new part file after about this many bytes, checked per row group; 0 is unlimited
+	RowGroupRows int      // Rows of each row group; 0 is DefaultParquetRowGroupRows
+	Append       bool     // Add part files to existing partitions rather than replacing them
+	Verbose      bool     // Print each file as it is created
+}
+
+// ParquetPartitionWriter writes DBN files as a Hive-partitioned Parquet dataset, such as
+// "<dest>/schema=trades/date=2024-01-02/symbol=AAPL/part-0.parquet".
+// Query engines like Spark and DuckDB can then prune partitions.
+//
+// Without Append, the first write to a partition replaces its existing part files.
+// With Append, new part files are numbered after the existing ones, so prior days are left untouched;
+// appending the same data twice will duplicate it.
+type ParquetPartitionWriter struct {
+	destDir  string
+	opts     ParquetPartitionOptions
+	nextPart map[string]int // partition directory -> next part number
+}
+
+// NewParquetPartitionWriter returns a new ParquetPartitionWriter writing under `destDir`.
+func NewParquetPartitionWriter(destDir string, opts ParquetPartitionOptions) *ParquetPartitionWriter {
+	return &ParquetPartitionWriter{
+		destDir:  destDir,
+		opts:     opts,
+		nextPart: make(map[string]int),
+	}
+}
+
+// WriteDbnFile writes all the records of the DBN file into their partitions.
+func (pw *ParquetPartitionWriter) WriteDbnFile(sourceFile string, forceZstdInput bool) error {
+	dbnFile, dbnCloser, err := dbn.MakeCompressedReader(sourceFile, forceZstdInput)
+	if err != nil {
+		return err
+	}
+	defer dbnCloser.Close()
+
+	dbnScanner := dbn.NewDbnScanner(dbnFile)
+	metadata, err := dbnScanner.Metadata()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata %w", err)
+	}
+
+	dbnSymbolMap := dbn.NewTsSymbolMap()
+	if err := dbnSymbolMap.FillFromMetadata(metadata); err != nil {
+		return fmt.Errorf("failed to fill symbol map: %w", err)
+	}
+
+	pqGroupNode := ParquetGroupNodeForDbnMetadata(metadata)
+	writeRow := parquetRowWriterForDbnMetadata(metadata)
+	if pqGroupNode == nil || writeRow == nil {
+		return fmt.Errorf("no converter for schema %s", metadata.Schema.String())
+	}
+
+	// Open partitions for this file; closed as they age out, or at the end
+	schemaDir := filepath.Join(pw.destDir, "schema="+metadata.Schema.String())
+	partitions := make(map[string]*parquetPartFile)
+	defer func() {
+		for _, part := range partitions {
+			part.close()
+		}
+	}()
+	var latestDate time.Time
+
+	for dbnScanner.Next() {
+		rheader, err := dbnScanner.GetLastHeader()
+		if err != nil {
+			return fmt.Errorf("failed to read RHeader: %w", err)
+		}
+		recordTime := time.Unix(0, int64(rheader.TsEvent)).UTC()
+		recordDate := recordTime.Truncate(24 * time.Hour)
+		partDir := pw.partitionDir(schemaDir, recordTime, dbnSymbolMap.Get(recordTime, rheader.InstrumentID))
+
+		part, ok := partitions[partDir]
+		if !ok {
+			if part, err = pw.openPart(partDir, pqGroupNode, recordDate); err != nil {
+				return err
+			}
+			partitions[partDir] = part
+		}
+
+		if part.rgw == nil {
+			part.rgw = part.pw.AppendBufferedRowGroup()
+		}
+		if err := writeRow(dbnScanner, part.rgw, dbnSymbolMap); err != nil {
+			return err
+		}
+		part.groupRows++
+
+		// Flush full row groups, then roll once the file on disk reaches MaxFileBytes
+		if part.groupRows >= pw.rowGroupRows() {
+			if err := part.flushRowGroup(); err != nil {
+				return err
+			}
+			if pw.opts.MaxFileBytes > 0 && part.pw.TotalBytesWritten() >= pw.opts.MaxFileBytes {
+				delete(partitions, partDir)
+				if err := part.close(); err != nil {
+					return err
+				}
+			}
+		}
+
+		// DBN files are mostly in time order, so once a new date arrives we can
+		// close partitions two days stale; late records just open a new part file.
+		if recordDate.After(latestDate) {
+			latestDate = recordDate
+			if pw.isPartitionedBy(PartitionKey_Date) {
+				for dir, part := range partitions {
+					if part.date.Before(latestDate.AddDate(0, 0, -1)) {
+						delete(partitions, dir)
+						if err := part.close(); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := dbnScanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+
+	var errClose error
+	for dir, part := range partitions {
+		delete(partitions, dir)
+		errClose = errors.Join(errClose, part.close())
+	}
+	return errClose
+}
+
+// rowGroupRows returns the number of rows of each row group.
+func (pw *ParquetPartitionWriter) rowGroupRows() int {
+	if pw.opts.RowGroupRows > 0 {
+		return pw.opts.RowGroupRows
+	}
+	return DefaultParquetRowGroupRows
+}
+
+func (pw *ParquetPartitionWriter) isPartitionedBy(key string) bool {
+	for _, k := range pw.opts.PartitionBy {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionDir returns the directory for a record at `recordTime` with `symbol`.
+func (pw *ParquetPartitionWriter) partitionDir(schemaDir string, recordTime time.Time, symbol string) string {
+	dir := schemaDir
+	for _, key := range pw.opts.PartitionBy {
+		var value string
+		switch key {
+		case PartitionKey_Date:
+			value = recordTime.Format(time.DateOnly)
+		case PartitionKey_Symbol:
+			value = symbol
+		}
+		dir = filepath.Join(dir, key+"="+hiveEscapePathValue(value))
+	}
+	return dir
+}
+
+// openPart creates the next part file of the partition directory.
+// The first open of a partition either clears its old part files, or with Append, numbers after them.
+func (pw *ParquetPartitionWriter) openPart(partDir string, pqGroupNode *pqschema.GroupNode, date time.Time) (*parquetPartFile, error) {
+	partNum, seen := pw.nextPart[partDir]
+	if !seen {
+		if err := os.MkdirAll(partDir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create partition '%s': %w", partDir, err)
+		}
+		existing, err := filepath.Glob(filepath.Join(partDir, "part-*.parquet"))
+		if err != nil {
+			return nil, err
+		}
+		for _, existingFile := range existing {
+			if pw.opts.Append {
+				numStr := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(existingFile), "part-"), ".parquet")
+				if num, err := strconv.Atoi(numStr); err == nil && num >= partNum {
+					partNum = num + 1
+				}
+			} else if err := os.Remove(existingFile); err != nil {
+				return nil, fmt.Errorf("failed to replace '%s': %w", existingFile, err)
+			}
+		}
+	}
+	pw.nextPart[partDir] = partNum + 1
+
+	destFile := filepath.Join(partDir, fmt.Sprintf("part-%d.parquet", partNum))
+	outfile, err := os.Create(destFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create '%s': %w", destFile, err)
+	}
+	if pw.opts.Verbose {
+		fmt.Fprintf(os.Stderr, "writing to '%s'\n", destFile)
+	}
+
+	pwProperties := parquet.NewWriterProperties(
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithCompression(compress.Codecs.Snappy))
+	pqWriter := pqfile.NewParquetWriter(outfile, pqGroupNode, pqfile.WithWriterProps(pwProperties))
+	return &parquetPartFile{
+		pw:   pqWriter,
+		date: date,
+	}, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// parquetPartFile is an open part file of a partition.
+type parquetPartFile struct {
+	pw        *pqfile.Writer
+	rgw       pqfile.BufferedRowGroupWriter // current row group; nil until a row is written to it
+	date      time.Time                     // UTC date of the first record
+	groupRows int                           // rows in the current row group
+}
+
+// flushRowGroup writes the current row group to the file, if any.
+func (p *parquetPartFile) flushRowGroup() error {
+	if p.rgw == nil {
+		return nil
+	}
+	err := p.rgw.Close()
+	p.rgw, p.groupRows = nil, 0
+	return err
+}
+
+// close flushes the last row group and the footer and closes the file.
+func (p *parquetPartFile) close() error {
+	errClose := p.flushRowGroup()
+	errFlush := p.pw.FlushWithFooter()
+	return errors.Join(errClose, errFlush, p.pw.Close())
+}
+
+// hiveEscapePathValue escapes a partition value as Hive does, percent-encoding
+// characters which are unsafe in paths.  Empty values are Hive's default partition.
+func hiveEscapePathValue(value string) string {
+	if value == "" {
+		return hiveDefaultPartition
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 0x20 || c == 0x7F || strings.IndexByte("\"#%'*/:=?\\{[]^", c) >= 0 {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_synth "github.com/NimbleMarkets/dbn-go/synth"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+)
+
+func TestParquetPartitionWriter_Layout(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.trades.v3.dbn.zst")
+	dest := t.TempDir()
+	partDir := filepath.Join(dest, "schema=trades", "date=2020-12-28", "symbol=ESH1")
+
+	opts := ParquetPartitionOptions{PartitionBy: []string{PartitionKey_Date, PartitionKey_Symbol}}
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile returned error: %v", err)
+	}
+	wantRows := countDBNRecords(t, src, false)
+	if got := countParquetRows(t, filepath.Join(partDir, "part-0.parquet")); got != wantRows {
+		t.Fatalf("parquet row count mismatch: got %d want %d", got, wantRows)
+	}
+
+	// A new writer replaces the partition's files
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile(replace) returned error: %v", err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(partDir, "part-*.parquet")); len(parts) != 1 {
+		t.Fatalf("expected 1 part file after replace, got %v", parts)
+	}
+
+	// Append adds the next part file
+	opts.Append = true
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile(append) returned error: %v", err)
+	}
+	if got := countParquetRows(t, filepath.Join(partDir, "part-1.parquet")); got != wantRows {
+		t.Fatalf("appended parquet row count mismatch: got %d want %d", got, wantRows)
+	}
+}
+
+func TestParquetPartitionWriter_Rolling(t *testing.T) {
+	// A synthetic MBP-1 file of a single partition, with rows for several row groups
+	src := filepath.Join(t.TempDir(), "synth.mbp-1.dbn")
+	generator, err := dbn_synth.NewGenerator(dbn_synth.Options{Schema: dbn.Schema_Mbp1, Count: 2500})
+	if err != nil {
+		t.Fatalf("NewGenerator returned error: %v", err)
+	}
+	srcFile, err := os.Create(src)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if err := generator.Write(srcFile); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	srcFile.Close()
+
+	// Each part file is rolled after its first row group reaches MaxFileBytes
+	dest := t.TempDir()
+	partDir := filepath.Join(dest, "schema=mbp-1", "date=2026-01-02")
+	opts := ParquetPartitionOptions{PartitionBy: []string{PartitionKey_Date}, MaxFileBytes: 1, RowGroupRows: 1000}
+	checkParts := func(first int) {
+		t.Helper()
+		for i, wantRows := range []int64{1000, 1000, 500} {
+			partFile := filepath.Join(partDir, fmt.Sprintf("part-%d.parquet", first+i))
+			if got := countParquetRows(t, partFile); got != wantRows {
+				t.Fatalf("%s row count mismatch: got %d want %d", partFile, got, wantRows)
+			}
+		}
+	}
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile returned error: %v", err)
+	}
+	checkParts(0)
+
+	// Append numbers its part files after the existing ones
+	opts.Append = true
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile(append) returned error: %v", err)
+	}
+	checkParts(3)
+	if parts, _ := filepath.Glob(filepath.Join(partDir, "part-*.parquet")); len(parts) != 6 {
+		t.Fatalf("expected 6 part files after append, got %v", parts)
+	}
+
+	// Without MaxFileBytes, one part file has a row group per RowGroupRows
+	opts = ParquetPartitionOptions{PartitionBy: []string{PartitionKey_Date}, RowGroupRows: 1000}
+	if err := NewParquetPartitionWriter(dest, opts).WriteDbnFile(src, false); err != nil {
+		t.Fatalf("WriteDbnFile(replace) returned error: %v", err)
+	}
+	reader, err := pqfile.OpenParquetFile(filepath.Join(partDir, "part-0.parquet"), false)
+	if err != nil {
+		t.Fatalf("OpenParquetFile returned error: %v", err)
+	}
+	defer reader.Close()
+	if reader.NumRows() != 2500 || reader.NumRowGroups() != 3 {
+		t.Fatalf("got %d rows in %d row groups, want 2500 in 3", reader.NumRows(), reader.NumRowGroups())
+	}
+}
+
+func TestParseParquetPartitionKeys(t *testing.T) {
+	keys, err := ParseParquetPartitionKeys("symbol, date")
+	if err != nil {
+		t.Fatalf("ParseParquetPartitionKeys returned error: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != PartitionKey_Symbol || keys[1] != PartitionKey_Date {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	for _, bad := range []string{"", "hour", "date,date"} {
+		if _, err := ParseParquetPartitionKeys(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestHiveEscapePathValue(t *testing.T) {
+	tests := map[string]string{
+		"AAPL":    "AAPL",
+		"BRK.B":   "BRK.B",
+		"ES FUT":  "ES FUT",
+		"EUR/USD": "EUR%2FUSD",
+		"a=b:c":   "a%3Db%3Ac",
+		"":        hiveDefaultPartition,
+	}
+	for value, want := range tests {
+		if got := hiveEscapePathValue(value); got != want {
+			t.Fatalf("hiveEscapePathValue(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
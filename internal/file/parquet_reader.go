@@ -0,0 +1,295 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"fmt"
+
+	"github.com/NimbleMarkets/dbn-go"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+)
+
+// parquetColumn holds a column's values, expanded to one per row.
+// Numeric columns fill both ints and floats, so callers need not care
+// whether a notebook rewrote an integer column as a double or vice versa.
+type parquetColumn struct {
+	ints   []int64
+	floats []float64
+	strs   []string
+	valid  []bool
+}
+
+// parquetTable holds a row group's columns by name.
+type parquetTable struct {
+	numRows int
+	columns map[string]*parquetColumn
+}
+
+// readParquetRowGroup reads all the supported columns of a row group.
+// Columns of other physical types are ignored.
+func readParquetRowGroup(rgr *pqfile.RowGroupReader) (*parquetTable, error) {
+	table := &parquetTable{
+		numRows: int(rgr.NumRows()),
+		columns: make(map[string]*parquetColumn),
+	}
+	for i := 0; i < rgr.NumColumns(); i++ {
+		ccr, err := rgr.Column(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get column %d: %w", i, err)
+		}
+		descr := ccr.Descriptor()
+		col := &parquetColumn{}
+		switch reader := ccr.(type) {
+		case *pqfile.Int32ColumnChunkReader:
+			values, valid, err := readParquetColumn(reader, table.numRows, descr.MaxDefinitionLevel())
+			if err != nil {
+				return nil, fmt.Errorf("failed reading column %s: %w", descr.Name(), err)
+			}
+			col.ints, col.floats, col.valid = make([]int64, len(values)), make([]float64, len(values)), valid
+			for j, v := range values {
+				col.ints[j], col.floats[j] = int64(v), float64(v)
+			}
+		case *pqfile.Int64ColumnChunkReader:
+			values, valid, err := readParquetColumn(reader, table.numRows, descr.MaxDefinitionLevel())
+			if err != nil {
+				return nil, fmt.Errorf("failed reading column %s: %w", descr.Name(), err)
+			}
+			col.ints, col.floats, col.valid = values, make([]float64, len(values)), valid
+			for j, v := range values {
+				col.floats[j] = float64(v)
+			}
+		case *pqfile.Float64ColumnChunkReader:
+			values, valid, err := readParquetColumn(reader, table.numRows, descr.MaxDefinitionLevel())
+			if err != nil {
+				return nil, fmt.Errorf("failed reading column %s: %w", descr.Name(), err)
+			}
+			col.ints, col.floats, col.valid = make([]int64, len(values)), values, valid
+			for j, v := range values {
+				col.ints[j] = int64(v)
+			}
+		case *pqfile.ByteArrayColumnChunkReader:
+			values, valid, err := readParquetColumn(reader, table.numRows, descr.MaxDefinitionLevel())
+			if err != nil {
+				return nil, fmt.Errorf("failed reading column %s: %w", descr.Name(), err)
+			}
+			col.strs, col.valid = make([]string, len(values)), valid
+			for j, v := range values {
+				col.strs[j] = string(v)
+			}
+		default:
+			continue
+		}
+		table.columns[descr.Name()] = col
+	}
+	return table, nil
+}
+
+// parquetBatchReader is implemented by the typed pqfile.ColumnChunkReaders.
+type parquetBatchReader[T any] interface {
+	ReadBatch(batchSize int64, values []T, defLvls, repLvls []int16) (total int64, valuesRead int, err error)
+}
+
+// readParquetColumn reads `numRows` values of a flat column, spreading out nulls
+// (which ReadBatch packs away) so there is one value per row.
+func readParquetColumn[T any](reader parquetBatchReader[T], numRows int, maxDefLevel int16) ([]T, []bool, error) {
+	values := make([]T, numRows)
+	valid := make([]bool, numRows)
+	defLvls := make([]int16, numRows)
+	packed := make([]T, numRows)
+	row := 0
+	for row < numRows {
+		total, valuesRead, err := reader.ReadBatch(int64(numRows-row), packed, defLvls, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if total == 0 {
+			return nil, nil, fmt.Errorf("expected %d rows, read %d", numRows, row)
+		}
+		v := 0
+		for i := 0; i < int(total); i++ {
+			if maxDefLevel == 0 || defLvls[i] == maxDefLevel {
+				if v < valuesRead {
+					values[row+i], valid[row+i] = packed[v], true
+				}
+				v++
+			}
+		}
+		row += int(total)
+	}
+	return values, valid, nil
+}
+
+// has returns true if the column exists in the table.
+func (t *parquetTable) has(name string) bool {
+	_, ok := t.columns[name]
+	return ok
+}
+
+// int returns the row's integer value of the column, or 0 if missing or null.
+func (t *parquetTable) int(name string, row int) int64 {
+	col, ok := t.columns[name]
+	if !ok || col.ints == nil || !col.valid[row] {
+		return 0
+	}
+	return col.ints[row]
+}
+
+// intOrUndef returns the row's integer value of the column, or `undef` if missing or null.
+func (t *parquetTable) intOrUndef(name string, row int, undef int64) int64 {
+	col, ok := t.columns[name]
+	if !ok || col.ints == nil || !col.valid[row] {
+		return undef
+	}
+	return col.ints[row]
+}
+
+// timestamp returns the row's timestamp of the column, or UNDEF_TIMESTAMP if missing or null.
+func (t *parquetTable) timestamp(name string, row int) uint64 {
+	col, ok := t.columns[name]
+	if !ok || col.ints == nil || !col.valid[row] {
+		return dbn.UNDEF_TIMESTAMP
+	}
+	return uint64(col.ints[row])
+}
+
+// price returns the row's fixed-precision price of the column, or UNDEF_PRICE if missing or null.
+func (t *parquetTable) price(name string, row int) int64 {
+	col, ok := t.columns[name]
+	if !ok || col.floats == nil || !col.valid[row] {
+		return dbn.UNDEF_PRICE
+	}
+	return int64(dbn.PriceFromFloat64(col.floats[row]))
+}
+
+// char returns the first byte of the row's string value of the column, or 0 if missing, null, or empty.
+func (t *parquetTable) char(name string, row int) byte {
+	str := t.str(name, row)
+	if len(str) == 0 {
+		return 0
+	}
+	return str[0]
+}
+
+// str returns the row's string value of the column, or "" if missing or null.
+func (t *parquetTable) str(name string, row int) string {
+	col, ok := t.columns[name]
+	if !ok || col.strs == nil || !col.valid[row] {
+		return ""
+	}
+	return col.strs[row]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// parquetTableToRecords converts each row to a record by its rtype and collects it.
+// Supports the layouts of ParquetGroupNodeForDbnSchema.
+func parquetTableToRecords(table *parquetTable, collector *dbnRecordCollector) error {
+	for _, name := range []string{"rtype", "ts_event", "instrument_id"} {
+		if !table.has(name) {
+			return fmt.Errorf("missing required column %s", name)
+		}
+	}
+	for row := 0; row < table.numRows; row++ {
+		rtype := dbn.RType(table.int("rtype", row))
+		header := dbn.RHeader{
+			RType:        rtype,
+			PublisherID:  uint16(table.int("publisher_id", row)),
+			InstrumentID: uint32(table.int("instrument_id", row)),
+			TsEvent:      uint64(table.int("ts_event", row)),
+		}
+		var err error
+		switch {
+		case rtype.IsCandle():
+			err = collector.OnOhlcv(&dbn.OhlcvMsg{
+				Header: header,
+				Open:   table.price("open", row),
+				High:   table.price("high", row),
+				Low:    table.price("low", row),
+				Close:  table.price("close", row),
+				Volume: uint64(table.int("volume", row)),
+			})
+		case rtype == dbn.RType_Mbp0:
+			err = collector.OnMbp0(&dbn.Mbp0Msg{
+				Header:    header,
+				Price:     table.price("price", row),
+				Size:      uint32(table.int("size", row)),
+				Action:    table.char("action", row),
+				Side:      table.char("side", row),
+				Flags:     uint8(table.int("flags", row)),
+				Depth:     uint8(table.int("depth", row)),
+				TsRecv:    uint64(table.int("ts_recv", row)),
+				TsInDelta: int32(table.int("ts_in_delta", row)),
+				Sequence:  uint32(table.int("sequence", row)),
+			})
+		case rtype == dbn.RType_Mbp1:
+			err = collector.OnMbp1(&dbn.Mbp1Msg{
+				Header:    header,
+				Price:     table.price("price", row),
+				Size:      uint32(table.int("size", row)),
+				Action:    table.char("action", row),
+				Side:      table.char("side", row),
+				Flags:     uint8(table.int("flags", row)),
+				Depth:     uint8(table.int("depth", row)),
+				TsRecv:    uint64(table.int("ts_recv", row)),
+				TsInDelta: int32(table.int("ts_in_delta", row)),
+				Sequence:  uint32(table.int("sequence", row)),
+				Level: dbn.BidAskPair{
+					BidPx: table.price("bid_px_00", row),
+					AskPx: table.price("ask_px_00", row),
+					BidSz: uint32(table.int("bid_sz_00", row)),
+					AskSz: uint32(table.int("ask_sz_00", row)),
+					BidCt: uint32(table.int("bid_ct_00", row)),
+					AskCt: uint32(table.int("ask_ct_00", row)),
+				},
+			})
+		case rtype == dbn.RType_Imbalance:
+			err = collector.OnImbalance(&dbn.ImbalanceMsg{
+				Header:               header,
+				TsRecv:               uint64(table.int("ts_recv", row)),
+				RefPrice:             table.price("ref_price", row),
+				AuctionTime:          table.timestamp("auction_time", row),
+				ContBookClrPrice:     table.price("cont_book_clr_price", row),
+				AuctInterestClrPrice: table.price("auct_interest_clr_price", row),
+				SsrFillingPrice:      table.price("ssr_filling_price", row),
+				IndMatchPrice:        table.price("ind_match_price", row),
+				UpperCollar:          table.price("upper_collar", row),
+				LowerCollar:          table.price("lower_collar", row),
+				PairedQty:            uint32(table.int("paired_qty", row)),
+				TotalImbalanceQty:    uint32(table.int("total_imbalance_qty", row)),
+				MarketImbalanceQty:   uint32(table.int("market_imbalance_qty", row)),
+				UnpairedQty:          int32(table.int("unpaired_qty", row)),
+				AuctionType:          table.char("auction_type", row),
+				Side:                 table.char("side", row),
+				AuctionStatus:        uint8(table.int("auction_status", row)),
+				FreezeStatus:         uint8(table.int("freeze_status", row)),
+				NumExtensions:        uint8(table.int("num_extensions", row)),
+				UnpairedSide:         table.char("unpaired_side", row),
+				SignificantImbalance: table.char("significant_imbalance", row),
+			})
+		case rtype == dbn.RType_Statistics:
+			err = collector.OnStatMsg(&dbn.StatMsg{
+				Header:       header,
+				TsRecv:       uint64(table.int("ts_recv", row)),
+				TsRef:        table.timestamp("ts_ref", row),
+				Price:        table.price("price", row),
+				Quantity:     table.intOrUndef("quantity", row, dbn.StatMsgV3_UNDEF_STAT_QUANTITY),
+				Sequence:     uint32(table.int("sequence", row)),
+				TsInDelta:    int32(table.int("ts_in_delta", row)),
+				StatType:     uint16(table.int("stat_type", row)),
+				ChannelID:    uint16(table.int("channel_id", row)),
+				UpdateAction: uint8(table.int("update_action", row)),
+				StatFlags:    uint8(table.int("stat_flags", row)),
+			})
+		default:
+			return fmt.Errorf("row %d: no converter for rtype %s", row, rtype.String())
+		}
+		if err != nil {
+			return err
+		}
+		collector.observeSymbol(table.str("symbol", row))
+		if table.has("ts_out") {
+			collector.observeTsOut(table.timestamp("ts_out", row))
+		}
+	}
+	return nil
+}
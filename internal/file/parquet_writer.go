@@ -48,7 +48,7 @@ func WriteDbnFileAsParquet(sourceFile string, forceZstdInput bool, destFile stri
 		parquet.WithCompression(compress.Codecs.Snappy))
 
 	// Grab the appropriate Parquet schema
-	pqGroupNode := ParquetGroupNodeForDbnSchema(metadata.Schema)
+	pqGroupNode := ParquetGroupNodeForDbnMetadata(metadata)
 	if pqGroupNode == nil {
 		return fmt.Errorf("no converter for schema %s", metadata.Schema.String())
 	}
@@ -86,68 +86,94 @@ func ParquetGroupNodeForDbnSchema(dbnSchema dbn.Schema) *pqschema.GroupNode {
 	}
 }
 
+// ParquetGroupNodeForDbnMetadata returns a GroupNode for the metadata's schema, as ParquetGroupNodeForDbnSchema.
+// If the metadata has TsOut set, a trailing `ts_out` timestamp column holds each record's gateway send timestamp.
+func ParquetGroupNodeForDbnMetadata(metadata *dbn.Metadata) *pqschema.GroupNode {
+	node := ParquetGroupNodeForDbnSchema(metadata.Schema)
+	if node == nil || metadata.TsOut == 0 {
+		return node
+	}
+	fields := make(pqschema.FieldList, 0, node.NumFields()+1)
+	for i := 0; i < node.NumFields(); i++ {
+		fields = append(fields, node.Field(i))
+	}
+	fields = append(fields, pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("ts_out", parquet.Repetitions.Optional, pqschema.NewTimestampLogicalType(true, pqschema.TimeUnitNanos), parquet.Types.Int64, 0, -1)))
+	return pqschema.MustGroup(pqschema.NewGroupNode(node.Name(), node.RepetitionType(), fields, -1))
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 func scanAndWriteParquet(scanner *dbn.DbnScanner, rgw pqfile.BufferedRowGroupWriter, dbnSymbolMap *dbn.TsSymbolMap) error {
 	metadata, _ := scanner.Metadata() // we already validated at caller
-	switch metadata.Schema {
-	case dbn.Schema_Ohlcv1S, dbn.Schema_Ohlcv1M, dbn.Schema_Ohlcv1H, dbn.Schema_Ohlcv1D:
-		for scanner.Next() {
-			if r, err := dbn.DbnScannerDecode[dbn.OhlcvMsg](scanner); err != nil {
-				return err
-			} else {
-				if err := ParquetWriteRow_OhlcvMsg(rgw, r, dbnSymbolMap); err != nil {
-					return err
-				}
-			}
+	writeRow := parquetRowWriterForDbnMetadata(metadata)
+	if writeRow == nil {
+		return fmt.Errorf("no converter for schema %s", metadata.Schema.String())
+	}
+	for scanner.Next() {
+		if err := writeRow(scanner, rgw, dbnSymbolMap); err != nil {
+			return err
 		}
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// parquetRowWriter decodes the scanner's last record and writes it as a row.
+type parquetRowWriter func(scanner *dbn.DbnScanner, rgw pqfile.BufferedRowGroupWriter, dbnSymbolMap *dbn.TsSymbolMap) error
+
+// parquetRowWriterForDbnSchema returns the parquetRowWriter for the given dbnSchema,
+// matching ParquetGroupNodeForDbnSchema.  Returns nil if there is none.
+func parquetRowWriterForDbnSchema(dbnSchema dbn.Schema) parquetRowWriter {
+	switch dbnSchema {
+	case dbn.Schema_Ohlcv1S, dbn.Schema_Ohlcv1M, dbn.Schema_Ohlcv1H, dbn.Schema_Ohlcv1D:
+		return decodingParquetRowWriter(ParquetWriteRow_OhlcvMsg)
 	case dbn.Schema_Trades:
-		for scanner.Next() {
-			if r, err := dbn.DbnScannerDecode[dbn.Mbp0Msg](scanner); err != nil {
-				return err
-			} else {
-				if err := ParquetWriteRow_Mbp0Msg(rgw, r, dbnSymbolMap); err != nil {
-					return err
-				}
-			}
-		}
+		return decodingParquetRowWriter(ParquetWriteRow_Mbp0Msg)
 	case dbn.Schema_Mbp1, dbn.Schema_Tbbo:
-		for scanner.Next() {
-			if r, err := dbn.DbnScannerDecode[dbn.Mbp1Msg](scanner); err != nil {
-				return err
-			} else {
-				if err := ParquetWriteRow_Mbp1Msg(rgw, r, dbnSymbolMap); err != nil {
-					return err
-				}
-			}
-		}
+		return decodingParquetRowWriter(ParquetWriteRow_Mbp1Msg)
 	case dbn.Schema_Imbalance:
-		for scanner.Next() {
-			if r, err := dbn.DbnScannerDecode[dbn.ImbalanceMsg](scanner); err != nil {
-				return err
-			} else {
-				if err := ParquetWriteRow_ImbalanceMsg(rgw, r, dbnSymbolMap); err != nil {
-					return err
-				}
-			}
-		}
+		return decodingParquetRowWriter(ParquetWriteRow_ImbalanceMsg)
 	case dbn.Schema_Statistics:
-		for scanner.Next() {
-			if r, err := scanner.DecodeStatMsg(); err != nil {
+		return func(scanner *dbn.DbnScanner, rgw pqfile.BufferedRowGroupWriter, dbnSymbolMap *dbn.TsSymbolMap) error {
+			r, err := scanner.DecodeStatMsg() // upgrades V1/V2
+			if err != nil {
 				return err
-			} else {
-				if err := ParquetWriteRow_StatMsg(rgw, r, dbnSymbolMap); err != nil {
-					return err
-				}
 			}
+			return ParquetWriteRow_StatMsg(rgw, r, dbnSymbolMap)
 		}
 	default:
-		return fmt.Errorf("no converter for schema %s", metadata.Schema.String())
+		return nil
 	}
-	if err := scanner.Error(); err != nil && err != io.EOF {
-		return err
+}
+
+// parquetRowWriterForDbnMetadata returns the parquetRowWriter for the metadata's schema,
+// matching ParquetGroupNodeForDbnMetadata.  Returns nil if there is none.
+func parquetRowWriterForDbnMetadata(metadata *dbn.Metadata) parquetRowWriter {
+	writeRow := parquetRowWriterForDbnSchema(metadata.Schema)
+	if writeRow == nil || metadata.TsOut == 0 {
+		return writeRow
+	}
+	tsOutIdx := ParquetGroupNodeForDbnSchema(metadata.Schema).NumFields()
+	return func(scanner *dbn.DbnScanner, rgw pqfile.BufferedRowGroupWriter, dbnSymbolMap *dbn.TsSymbolMap) error {
+		if err := writeRow(scanner, rgw, dbnSymbolMap); err != nil {
+			return err
+		}
+		tsOut, ok := scanner.GetLastTsOut()
+		return writeNullableInt64Column(rgw, tsOutIdx, int64(tsOut), ok)
+	}
+}
+
+// decodingParquetRowWriter returns a parquetRowWriter which decodes an R and writes it with `writeRow`.
+func decodingParquetRowWriter[R dbn.Record, RP dbn.RecordPtr[R]](writeRow func(pqfile.BufferedRowGroupWriter, *R, *dbn.TsSymbolMap) error) parquetRowWriter {
+	return func(scanner *dbn.DbnScanner, rgw pqfile.BufferedRowGroupWriter, dbnSymbolMap *dbn.TsSymbolMap) error {
+		r, err := dbn.DbnScannerDecode[R, RP](scanner)
+		if err != nil {
+			return err
+		}
+		return writeRow(rgw, r, dbnSymbolMap)
 	}
-	return nil
 }
 
 func writeInt32Column(rgw pqfile.BufferedRowGroupWriter, idx int, value int32) error {
@@ -195,6 +221,49 @@ func writeFloat64Column(rgw pqfile.BufferedRowGroupWriter, idx int, value float6
 	return nil
 }
 
+// writeNullableInt64Column writes the value, or null if not `valid`.
+func writeNullableInt64Column(rgw pqfile.BufferedRowGroupWriter, idx int, value int64, valid bool) error {
+	if valid {
+		return writeInt64Column(rgw, idx, value)
+	}
+	cw, err := rgw.Column(idx)
+	if err != nil {
+		return fmt.Errorf("failed to get column %d: %w", idx, err)
+	}
+	writer, ok := cw.(*pqfile.Int64ColumnChunkWriter)
+	if !ok {
+		return fmt.Errorf("column %d has unexpected writer type %T", idx, cw)
+	}
+	if _, err := writer.WriteBatch(nil, []int16{0}, nil); err != nil {
+		return fmt.Errorf("failed writing int64 column %d: %w", idx, err)
+	}
+	return nil
+}
+
+// writeTimestampColumn writes the timestamp, or null if UNDEF_TIMESTAMP.
+func writeTimestampColumn(rgw pqfile.BufferedRowGroupWriter, idx int, timestamp uint64) error {
+	return writeNullableInt64Column(rgw, idx, int64(timestamp), timestamp != dbn.UNDEF_TIMESTAMP)
+}
+
+// writePriceColumn writes the fixed-precision price as a float64, or null if UNDEF_PRICE.
+func writePriceColumn(rgw pqfile.BufferedRowGroupWriter, idx int, price int64) error {
+	if price != dbn.UNDEF_PRICE {
+		return writeFloat64Column(rgw, idx, dbn.Fixed9ToFloat64(price))
+	}
+	cw, err := rgw.Column(idx)
+	if err != nil {
+		return fmt.Errorf("failed to get column %d: %w", idx, err)
+	}
+	writer, ok := cw.(*pqfile.Float64ColumnChunkWriter)
+	if !ok {
+		return fmt.Errorf("column %d has unexpected writer type %T", idx, cw)
+	}
+	if _, err := writer.WriteBatch(nil, []int16{0}, nil); err != nil {
+		return fmt.Errorf("failed writing float64 column %d: %w", idx, err)
+	}
+	return nil
+}
+
 func writeByteArrayColumn(rgw pqfile.BufferedRowGroupWriter, idx int, value parquet.ByteArray) error {
 	cw, err := rgw.Column(idx)
 	if err != nil {
@@ -249,16 +318,16 @@ func ParquetWriteRow_OhlcvMsg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Ohl
 	if err := writeInt32Column(rgw, 2, int32(record.Header.InstrumentID)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 3, dbn.Fixed9ToFloat64(record.Open)); err != nil {
+	if err := writePriceColumn(rgw, 3, record.Open); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 4, dbn.Fixed9ToFloat64(record.High)); err != nil {
+	if err := writePriceColumn(rgw, 4, record.High); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 5, dbn.Fixed9ToFloat64(record.Low)); err != nil {
+	if err := writePriceColumn(rgw, 5, record.Low); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 6, dbn.Fixed9ToFloat64(record.Close)); err != nil {
+	if err := writePriceColumn(rgw, 6, record.Close); err != nil {
 		return err
 	}
 	if err := writeInt64Column(rgw, 7, int64(record.Volume)); err != nil {
@@ -334,7 +403,7 @@ func ParquetWriteRow_Mbp0Msg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Mbp0
 	if err := writeInt32Column(rgw, 6, int32(record.Depth)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 7, dbn.Fixed9ToFloat64(record.Price)); err != nil {
+	if err := writePriceColumn(rgw, 7, record.Price); err != nil {
 		return err
 	}
 	if err := writeInt32Column(rgw, 8, int32(record.Size)); err != nil {
@@ -431,7 +500,7 @@ func ParquetWriteRow_Mbp1Msg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Mbp1
 	if err := writeInt32Column(rgw, 6, int32(record.Depth)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 7, dbn.Fixed9ToFloat64(record.Price)); err != nil {
+	if err := writePriceColumn(rgw, 7, record.Price); err != nil {
 		return err
 	}
 	if err := writeInt32Column(rgw, 8, int32(record.Size)); err != nil {
@@ -446,10 +515,10 @@ func ParquetWriteRow_Mbp1Msg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Mbp1
 	if err := writeInt32Column(rgw, 11, int32(record.Sequence)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 12, dbn.Fixed9ToFloat64(record.Level.BidPx)); err != nil {
+	if err := writePriceColumn(rgw, 12, record.Level.BidPx); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 13, dbn.Fixed9ToFloat64(record.Level.AskPx)); err != nil {
+	if err := writePriceColumn(rgw, 13, record.Level.AskPx); err != nil {
 		return err
 	}
 	if err := writeInt32Column(rgw, 14, int32(record.Level.BidSz)); err != nil {
@@ -547,28 +616,28 @@ func ParquetWriteRow_ImbalanceMsg(rgw pqfile.BufferedRowGroupWriter, record *dbn
 	if err := writeInt32Column(rgw, 3, int32(record.Header.InstrumentID)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 4, dbn.Fixed9ToFloat64(record.RefPrice)); err != nil {
+	if err := writePriceColumn(rgw, 4, record.RefPrice); err != nil {
 		return err
 	}
-	if err := writeInt64Column(rgw, 5, int64(record.AuctionTime)); err != nil {
+	if err := writeTimestampColumn(rgw, 5, record.AuctionTime); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 6, dbn.Fixed9ToFloat64(record.ContBookClrPrice)); err != nil {
+	if err := writePriceColumn(rgw, 6, record.ContBookClrPrice); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 7, dbn.Fixed9ToFloat64(record.AuctInterestClrPrice)); err != nil {
+	if err := writePriceColumn(rgw, 7, record.AuctInterestClrPrice); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 8, dbn.Fixed9ToFloat64(record.SsrFillingPrice)); err != nil {
+	if err := writePriceColumn(rgw, 8, record.SsrFillingPrice); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 9, dbn.Fixed9ToFloat64(record.IndMatchPrice)); err != nil {
+	if err := writePriceColumn(rgw, 9, record.IndMatchPrice); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 10, dbn.Fixed9ToFloat64(record.UpperCollar)); err != nil {
+	if err := writePriceColumn(rgw, 10, record.UpperCollar); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 11, dbn.Fixed9ToFloat64(record.LowerCollar)); err != nil {
+	if err := writePriceColumn(rgw, 11, record.LowerCollar); err != nil {
 		return err
 	}
 	if err := writeInt32Column(rgw, 12, int32(record.PairedQty)); err != nil {
@@ -667,7 +736,7 @@ func ParquetWriteRow_StatMsg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Stat
 	if err := writeInt32Column(rgw, 3, int32(record.Header.InstrumentID)); err != nil {
 		return err
 	}
-	if err := writeFloat64Column(rgw, 4, dbn.Fixed9ToFloat64(record.Price)); err != nil {
+	if err := writePriceColumn(rgw, 4, record.Price); err != nil {
 		return err
 	}
 	if err := writeInt64Column(rgw, 5, record.Quantity); err != nil {
@@ -699,7 +768,7 @@ func ParquetWriteRow_StatMsg(rgw pqfile.BufferedRowGroupWriter, record *dbn.Stat
 	if err := writeInt64Column(rgw, 13, int64(record.TsRecv)); err != nil {
 		return err
 	}
-	if err := writeInt64Column(rgw, 14, int64(record.TsRef)); err != nil {
+	if err := writeTimestampColumn(rgw, 14, record.TsRef); err != nil {
 		return err
 	}
 	return nil
@@ -3,9 +3,12 @@
 package file
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/NimbleMarkets/dbn-go"
@@ -128,3 +131,171 @@ func countParquetRows(t *testing.T, filename string) int64 {
 
 	return reader.NumRows()
 }
+
+func TestWriteDbnFileAsParquet_UndefValuesAreNull(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.statistics.v3.dbn.zst")
+	dst := filepath.Join(t.TempDir(), "out.parquet")
+	if err := WriteDbnFileAsParquet(src, false, dst); err != nil {
+		t.Fatalf("WriteDbnFileAsParquet returned error: %v", err)
+	}
+
+	reader, err := pqfile.OpenParquetFile(dst, false)
+	if err != nil {
+		t.Fatalf("failed to open parquet file: %v", err)
+	}
+	defer reader.Close()
+	table, err := readParquetRowGroup(reader.RowGroup(0))
+	if err != nil {
+		t.Fatalf("readParquetRowGroup returned error: %v", err)
+	}
+
+	_, records := collectDbnRecords(t, src)
+	for row, record := range records {
+		stat := record.(*dbn.StatMsg)
+		for _, check := range []struct {
+			column string
+			undef  bool
+		}{
+			{"price", stat.Price == dbn.UNDEF_PRICE},
+			{"quantity", false}, // only prices and timestamps are null
+			{"ts_ref", stat.TsRef == dbn.UNDEF_TIMESTAMP},
+		} {
+			if valid := table.columns[check.column].valid[row]; valid == check.undef {
+				t.Fatalf("row %d column %s: valid=%v, but undefined=%v", row, check.column, valid, check.undef)
+			}
+		}
+	}
+}
+
+// writeTsOutDbnFile rewrites a DBN file's trades with a `ts_out` of ts_recv + `delay`, returning the records.
+func writeTsOutDbnFile(t *testing.T, src string, dst string, delay uint64) []dbn.Mbp0Msg {
+	t.Helper()
+	reader, closer, err := dbn.MakeCompressedReader(src, false)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", src, err)
+	}
+	defer closer.Close()
+	records, metadata, err := dbn.ReadDBNToSlice[dbn.Mbp0Msg](reader)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", src, err)
+	}
+	metadata.TsOut = 1
+
+	writer, writerCloser, err := dbn.MakeCompressedWriter(dst, false)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", dst, err)
+	}
+	defer writerCloser()
+	dbnWriter, err := dbn.NewDbnWriter(writer, metadata)
+	if err != nil {
+		t.Fatalf("NewDbnWriter returned error: %v", err)
+	}
+	for i := range records {
+		if err := dbnWriter.WriteWithTsOut(&records[i], records[i].TsRecv+delay); err != nil {
+			t.Fatalf("WriteWithTsOut returned error: %v", err)
+		}
+	}
+	return records
+}
+
+func TestWriteDbnFile_KeepsTsOut(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "trades.ts_out.dbn")
+	records := writeTsOutDbnFile(t, filepath.Join("..", "..", "tests", "data", "test_data.trades.v3.dbn.zst"), src, 1000)
+
+	// Parquet has a trailing ts_out column
+	dst := filepath.Join(t.TempDir(), "out.parquet")
+	if err := WriteDbnFileAsParquet(src, false, dst); err != nil {
+		t.Fatalf("WriteDbnFileAsParquet returned error: %v", err)
+	}
+	reader, err := pqfile.OpenParquetFile(dst, false)
+	if err != nil {
+		t.Fatalf("failed to open parquet file: %v", err)
+	}
+	defer reader.Close()
+	table, err := readParquetRowGroup(reader.RowGroup(0))
+	if err != nil {
+		t.Fatalf("readParquetRowGroup returned error: %v", err)
+	}
+	if table.numRows != len(records) {
+		t.Fatalf("expected %d rows, got %d", len(records), table.numRows)
+	}
+	for row, record := range records {
+		if got := uint64(table.int("ts_out", row)); got != record.TsRecv+1000 {
+			t.Fatalf("row %d: expected ts_out %d, got %d", row, record.TsRecv+1000, got)
+		}
+	}
+
+	// JSON has a ts_out field, quoted as Databento does
+	var buf bytes.Buffer
+	if err := WriteDbnFileAsJson(src, false, &buf); err != nil {
+		t.Fatalf("WriteDbnFileAsJson returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("expected %d lines, got %d", len(records), len(lines))
+	}
+	for i, line := range lines {
+		var fields struct {
+			TsRecv uint64 `json:"ts_recv,string"`
+			TsOut  uint64 `json:"ts_out,string"`
+		}
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("line %d is invalid JSON: %v", i, err)
+		}
+		if fields.TsOut != fields.TsRecv+1000 {
+			t.Fatalf("line %d: expected ts_out %d, got %d", i, fields.TsRecv+1000, fields.TsOut)
+		}
+	}
+}
+
+func TestWriteDbnFile_TsOutRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "trades.ts_out.dbn")
+	records := writeTsOutDbnFile(t, filepath.Join("..", "..", "tests", "data", "test_data.trades.v3.dbn.zst"), src, 1000)
+
+	jsonPath, parquetPath := filepath.Join(dir, "out.json"), filepath.Join(dir, "out.parquet")
+	var jsonBuf bytes.Buffer
+	if err := WriteDbnFileAsJson(src, false, &jsonBuf); err != nil {
+		t.Fatalf("WriteDbnFileAsJson returned error: %v", err)
+	}
+	if err := os.WriteFile(jsonPath, jsonBuf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write JSON: %v", err)
+	}
+	if err := WriteDbnFileAsParquet(src, false, parquetPath); err != nil {
+		t.Fatalf("WriteDbnFileAsParquet returned error: %v", err)
+	}
+
+	for name, convert := range map[string]func(io.Writer) error{
+		"json":    func(w io.Writer) error { return WriteJsonFileAsDbn(jsonPath, DbnWriteOptions{}, w) },
+		"parquet": func(w io.Writer) error { return WriteParquetFileAsDbn(parquetPath, DbnWriteOptions{}, w) },
+	} {
+		var dbnBuf bytes.Buffer
+		if err := convert(&dbnBuf); err != nil {
+			t.Fatalf("%s: conversion returned error: %v", name, err)
+		}
+		scanner := dbn.NewDbnScanner(&dbnBuf)
+		for i, record := range records {
+			if !scanner.Next() {
+				t.Fatalf("%s: expected %d records, got %d", name, len(records), i)
+			}
+			if !scanner.HasTsOut() {
+				t.Fatalf("%s: expected metadata with TsOut", name)
+			}
+			tsOut, ok := scanner.GetLastTsOut()
+			if !ok || tsOut != record.TsRecv+1000 {
+				t.Fatalf("%s: record %d: expected ts_out %d, got %d", name, i, record.TsRecv+1000, tsOut)
+			}
+			trade, err := dbn.DbnScannerDecode[dbn.Mbp0Msg](scanner)
+			if err != nil {
+				t.Fatalf("%s: record %d failed to decode: %v", name, i, err)
+			}
+			trade.Header.Length -= dbn.TsOut_Size / 4 // the record's length includes its ts_out
+			if *trade != record {
+				t.Fatalf("%s: record %d mismatch: got %+v want %+v", name, i, *trade, record)
+			}
+		}
+		if scanner.Next() {
+			t.Fatalf("%s: expected %d records", name, len(records))
+		}
+	}
+}
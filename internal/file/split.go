@@ -3,21 +3,91 @@
 package file
 
 import (
+	"bufio"
+	"container/list"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/NimbleMarkets/dbn-go"
-	"github.com/neomantra/ymdflag"
+	dbn_calendar "github.com/NimbleMarkets/dbn-go/calendar"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
 )
 
-const ymdPathFormat = "2006" + string(filepath.Separator) + "01" + string(filepath.Separator) + "02"
+// DefaultSplitPathTemplate is the layout SplitFile has always used,
+// "<dataset>/<symbol>/YYYY/MM/DD/<symbol>.<ymd>.<schema>.dbn.zst" for DBN output.
+const DefaultSplitPathTemplate = "{dataset}/{symbol}/{yyyy}/{mm}/{dd}/{symbol}.{ymd}.{schema}.{ext}"
+
+// DefaultSplitMaxOpenFiles is the default bound on open split files.
+const DefaultSplitMaxOpenFiles = 256
+
+// Output formats for SplitOptions.Format
+const (
+	SplitFormat_Dbn     = "dbn"
+	SplitFormat_Json    = "json"
+	SplitFormat_Parquet = "parquet"
+)
+
+// SplitOptions controls how SplitFileWithOptions routes records to files.
+//
+// PathTemplate is a path relative to the destination directory, where these placeholders
+// are replaced per record.  The placeholders used are the split keys; records with the same
+// expanded path go to the same file.
+//
+//	{dataset}        Dataset of the source file
+//	{schema}         Schema of the source file
+//	{ext}            "dbn.zst", "json", or "parquet", per Format, with the suffix of any Compression, such as "json.gz"
+//	{symbol}         Symbol from the source's mappings, or the instrument ID if unmapped
+//	{instrument_id}  Instrument ID
+//	{publisher_id}   Publisher ID
+//	{yyyy} {mm} {dd} UTC year, month, and day of ts_event
+//	{ymd}            UTC date of ts_event as YYYYMMDD
+//	{hh}             UTC hour of ts_event
+//	{session_date}   Exchange trading day of ts_event as YYYYMMDD, per Calendar
+type SplitOptions struct {
+	PathTemplate string                 // Destination path template; DefaultSplitPathTemplate if empty
+	Format       string                 // Output format, one of SplitFormat_*; SplitFormat_Dbn if empty
+	MaxOpenFiles int                    // Bound on open files, closing the least recently used; 0 is unlimited
+	Calendar     *dbn_calendar.Calendar // Calendar for {session_date}; the source dataset's calendar in Calendars if nil
+	Calendars    *dbn_calendar.Registry // Calendars to look up the source dataset's; the built-in calendars if nil
+	Verbose      bool                   // Print each file as it is created
+	Compression  dbn.CompressionOptions // Compression of DBN and JSON files; by each path's suffix, such as ".zst", if none
+	RowGroupRows int                    // Rows of each Parquet row group; 0 is DefaultParquetRowGroupRows
+}
 
 // SplitFile splits a source file into "<feed>/<instrument_id>/Y/M/D/feed-YMD.type.dbn.zst"`
 func SplitFile(sourceFilename string, destDir string, forceZstdInput bool, verbose bool) error {
+	opts := SplitOptions{
+		MaxOpenFiles: DefaultSplitMaxOpenFiles,
+		Verbose:      verbose,
+	}
+	return SplitFileWithOptions(sourceFilename, destDir, forceZstdInput, opts)
+}
+
+// SplitFileWithOptions splits a source file into files under destDir, per the SplitOptions.
+//
+// When a file is closed to honor MaxOpenFiles and more records arrive for it, DBN and JSON files
+// are reopened and appended to, while Parquet continues in a new file with a "-N" suffix.
+func SplitFileWithOptions(sourceFilename string, destDir string, forceZstdInput bool, opts SplitOptions) error {
+	if opts.PathTemplate == "" {
+		opts.PathTemplate = DefaultSplitPathTemplate
+	}
+	if opts.Format == "" {
+		opts.Format = SplitFormat_Dbn
+	}
+	pathTemplate, err := parseSplitPathTemplate(opts.PathTemplate)
+	if err != nil {
+		return err
+	}
+
 	// Open file, possibly using decompression wrapper
 	sourceReader, sourceCloser, err := dbn.MakeCompressedReader(sourceFilename, forceZstdInput)
 	if err != nil {
@@ -33,101 +103,68 @@ func SplitFile(sourceFilename string, destDir string, forceZstdInput bool, verbo
 	}
 	dbnSymbolMap := dbn.NewTsSymbolMap()
 	dbnSymbolMap.FillFromMetadata(sourceMetadata)
+	if opts.Calendar == nil {
+		if opts.Calendars == nil {
+			opts.Calendars = dbn_calendar.NewRegistry()
+		}
+		opts.Calendar = opts.Calendars.ForDatasetName(sourceMetadata.Dataset)
+	}
 
-	singleMetadata := dbn.Metadata{
-		VersionNum:       dbn.HeaderVersion2,
-		Schema:           sourceMetadata.Schema,
-		Start:            sourceMetadata.Start,
-		End:              sourceMetadata.End,
-		Limit:            sourceMetadata.Limit,
-		StypeIn:          dbn.SType_Parent,
-		StypeOut:         dbn.SType_InstrumentId,
-		TsOut:            sourceMetadata.TsOut,
-		SymbolCstrLen:    sourceMetadata.SymbolCstrLen,
-		Dataset:          sourceMetadata.Dataset,
-		SchemaDefinition: nil,
-		Symbols:          nil,
-		Partial:          nil,
-		NotFound:         nil,
-		Mappings:         nil,
-	}
-
-	// Iterate over all the records
-	writerMap := make(map[string]io.Writer)
-	closerMap := make(map[string]func())
-	defer func() {
-		// Close all the files
-		for _, closer := range closerMap {
-			closer()
-		}
-	}()
+	var writeRow parquetRowWriter
+	switch opts.Format {
+	case SplitFormat_Dbn, SplitFormat_Json:
+	case SplitFormat_Parquet:
+		if writeRow = parquetRowWriterForDbnMetadata(sourceMetadata); writeRow == nil {
+			return fmt.Errorf("no parquet converter for schema %s", sourceMetadata.Schema.String())
+		}
+	default:
+		return fmt.Errorf("unknown split format '%s'", opts.Format)
+	}
 
+	outputs := newSplitOutputCache(opts.MaxOpenFiles)
+	defer outputs.closeAll()
+
+	pathCache := make(map[splitPathKey]string)
 	for dbnScanner.Next() {
-		// Get the RHeader
 		rheader, err := dbnScanner.GetLastHeader()
 		if err != nil {
 			return fmt.Errorf("failed to read RHeader: %w", err)
 		}
-		recordTime := time.Unix(0, int64(rheader.TsEvent)).UTC()
-		recordYMD := ymdflag.TimeToYMD(recordTime)
-		recordYMDStr := strconv.Itoa(recordYMD)
-		fileKey := fmt.Sprintf("%s-%d-%s", rheader.RType, rheader.InstrumentID, recordYMDStr)
 
-		// Get the file handle (or create)
-		writer, ok := writerMap[fileKey]
+		// Expand the path, caching as it is costly per-record
+		recordTime := time.Unix(0, int64(rheader.TsEvent)).UTC()
+		pathKey := splitPathKey{rheader.InstrumentID, rheader.PublisherID, recordTime.Unix() / 60}
+		destPath, ok := pathCache[pathKey]
 		if !ok {
-			// TODO: check denylist
-			// Create the dest file
-			// <dest>/<dataset>/<symbol>/YYYY/MM/DD/<symbol>-YYYYYMMDD.<schema>.dbn.zst
-			dbnSymbol := dbnSymbolMap.Get(recordTime, rheader.InstrumentID)
-			datePath := recordTime.Format(ymdPathFormat)
-			destPath := filepath.Join(destDir, sourceMetadata.Dataset, dbnSymbol, datePath)
-			err := os.MkdirAll(destPath, os.ModePerm)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "failed to create dest path '%s': %s\n", destPath, err.Error())
-				return err
-
-			}
-
-			destFile := fmt.Sprintf("%s.%d.%s.dbn.zst", dbnSymbol, recordYMD, sourceMetadata.Schema.String())
-			fullDestPath := filepath.Join(destPath, destFile)
-
-			destWriter, destCloser, err := dbn.MakeCompressedWriter(fullDestPath, true)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "failed to create dest file '%s': %s\n", fullDestPath, err.Error())
-				return err
-				// TODO: add to deny list
-				// we don't want to keep creating failed files
-			}
-			writerMap[fileKey] = destWriter
-			closerMap[fileKey] = destCloser
-			writer = destWriter
-
-			if verbose {
-				fmt.Fprintf(os.Stderr, "writing to '%s'\n", fullDestPath)
+			if len(pathCache) >= 1<<16 {
+				clear(pathCache)
 			}
+			destPath = filepath.Join(destDir, pathTemplate.expand(splitPathValues{
+				metadata:   sourceMetadata,
+				format:     opts.Format,
+				compress:   opts.Compression.Compression,
+				symbol:     dbnSymbolMap.Get(recordTime, rheader.InstrumentID),
+				header:     &rheader,
+				recordTime: recordTime,
+				calendar:   opts.Calendar,
+			}))
+			pathCache[pathKey] = destPath
+		}
 
-			singleMetadata.Symbols = []string{dbnSymbol}
-			singleMetadata.Mappings = []dbn.SymbolMapping{
-				{
-					RawSymbol: dbnSymbol,
-					Intervals: []dbn.MappingInterval{
-						{
-							StartDate: uint32(recordYMD),
-							EndDate:   uint32(ymdflag.TimeToYMD(recordTime.AddDate(0, 0, 1))),
-							Symbol:    strconv.Itoa(int(rheader.InstrumentID)),
-						},
-					},
-				},
+		// Get the file handle (or create)
+		out := outputs.get(destPath)
+		if out == nil {
+			var fileMetadata *dbn.Metadata
+			if opts.Format == SplitFormat_Dbn {
+				fileMetadata = splitFileMetadata(sourceMetadata, pathTemplate, rheader.InstrumentID, dbnSymbolMap.Get(recordTime, rheader.InstrumentID))
 			}
-			if err := singleMetadata.Write(writer); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to write file header '%s': %s\n", fullDestPath, err.Error())
+			if out, err = outputs.open(destPath, opts, fileMetadata, dbnSymbolMap, writeRow, sourceMetadata); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create dest file '%s': %s\n", destPath, err.Error())
 				return err
 			}
 		}
 
-		_, err = writer.Write(dbnScanner.GetLastRecord()[:dbnScanner.GetLastSize()])
-		if err != nil {
+		if err := out.write(dbnScanner); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to write record: %s\n", err.Error())
 			return err
 		}
@@ -139,5 +176,402 @@ func SplitFile(sourceFilename string, destDir string, forceZstdInput bool, verbo
 		// EOF is not propagated as an error
 		err = nil
 	}
+	return errors.Join(err, outputs.closeAll())
+}
+
+// splitFileMetadata returns the Metadata for a split DBN file, keeping the source's layout
+// and only the mappings relevant to the file's instrument or symbol, if it is split by either.
+func splitFileMetadata(source *dbn.Metadata, pathTemplate *splitPathTemplate, instrumentID uint32, symbol string) *dbn.Metadata {
+	metadata := *source
+	metadata.SchemaDefinition = nil
+	metadata.Partial = nil
+	metadata.NotFound = nil
+	metadata.Symbols = nil
+	metadata.Mappings = nil
+
+	instrumentStr := strconv.Itoa(int(instrumentID))
+	for _, mapping := range source.Mappings {
+		switch {
+		case pathTemplate.uses("instrument_id"):
+			intervals := make([]dbn.MappingInterval, 0)
+			for _, interval := range mapping.Intervals {
+				if interval.Symbol == instrumentStr {
+					intervals = append(intervals, interval)
+				}
+			}
+			if len(intervals) == 0 {
+				continue
+			}
+			mapping.Intervals = intervals
+		case pathTemplate.uses("symbol"):
+			if mapping.RawSymbol != symbol {
+				continue
+			}
+		}
+		metadata.Mappings = append(metadata.Mappings, mapping)
+		if !slices.Contains(metadata.Symbols, mapping.RawSymbol) {
+			metadata.Symbols = append(metadata.Symbols, mapping.RawSymbol)
+		}
+	}
+	return &metadata
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// splitPathKey identifies records which expand to the same path.
+// Minutes are the finest time granularity, to allow for session starts like 16:30.
+type splitPathKey struct {
+	instrumentID uint32
+	publisherID  uint16
+	unixMinute   int64
+}
+
+// splitPathValues are the values for expanding a splitPathTemplate.
+type splitPathValues struct {
+	metadata   *dbn.Metadata
+	format     string
+	compress   dbn.Compression
+	symbol     string
+	header     *dbn.RHeader
+	recordTime time.Time
+	calendar   *dbn_calendar.Calendar
+}
+
+// splitPathTemplate is a parsed SplitOptions.PathTemplate.
+// Even-indexed parts are literals; odd-indexed parts are placeholder names.
+type splitPathTemplate struct {
+	parts []string
+}
+
+var splitPathPlaceholders = []string{
+	"dataset", "schema", "ext", "symbol", "instrument_id", "publisher_id",
+	"yyyy", "mm", "dd", "ymd", "hh", "session_date",
+}
+
+func parseSplitPathTemplate(str string) (*splitPathTemplate, error) {
+	t := &splitPathTemplate{}
+	for {
+		open := strings.IndexByte(str, '{')
+		if open < 0 {
+			t.parts = append(t.parts, str)
+			return t, nil
+		}
+		end := strings.IndexByte(str[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed '{' in path template")
+		}
+		name := str[open+1 : open+end]
+		if !slices.Contains(splitPathPlaceholders, name) {
+			return nil, fmt.Errorf("unknown path template placeholder '{%s}'", name)
+		}
+		t.parts = append(t.parts, str[:open], name)
+		str = str[open+end+1:]
+	}
+}
+
+// uses returns true if the template has the placeholder.
+func (t *splitPathTemplate) uses(name string) bool {
+	for i := 1; i < len(t.parts); i += 2 {
+		if t.parts[i] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *splitPathTemplate) expand(v splitPathValues) string {
+	var sb strings.Builder
+	for i, part := range t.parts {
+		if i%2 == 0 {
+			sb.WriteString(part)
+			continue
+		}
+		switch part {
+		case "dataset":
+			sb.WriteString(v.metadata.Dataset)
+		case "schema":
+			sb.WriteString(v.metadata.Schema.String())
+		case "ext":
+			switch v.format {
+			case SplitFormat_Dbn:
+				if v.compress == dbn.Compress_None {
+					sb.WriteString("dbn.zst")
+				} else {
+					sb.WriteString("dbn" + v.compress.FileSuffix())
+				}
+			case SplitFormat_Parquet:
+				sb.WriteString(v.format)
+			default:
+				sb.WriteString(v.format + v.compress.FileSuffix())
+			}
+		case "symbol":
+			if v.symbol == "" {
+				sb.WriteString(strconv.Itoa(int(v.header.InstrumentID)))
+			} else {
+				sb.WriteString(strings.NewReplacer("/", "_", "\\", "_").Replace(v.symbol))
+			}
+		case "instrument_id":
+			sb.WriteString(strconv.Itoa(int(v.header.InstrumentID)))
+		case "publisher_id":
+			sb.WriteString(strconv.Itoa(int(v.header.PublisherID)))
+		case "yyyy":
+			sb.WriteString(v.recordTime.Format("2006"))
+		case "mm":
+			sb.WriteString(v.recordTime.Format("01"))
+		case "dd":
+			sb.WriteString(v.recordTime.Format("02"))
+		case "ymd":
+			sb.WriteString(v.recordTime.Format("20060102"))
+		case "hh":
+			sb.WriteString(v.recordTime.Format("15"))
+		case "session_date":
+			sb.WriteString(strconv.Itoa(int(v.calendar.SessionDate(v.recordTime))))
+		}
+	}
+	return sb.String()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// splitOutput is an open split file.
+type splitOutput interface {
+	// write writes the scanner's last record
+	write(scanner *dbn.DbnScanner) error
+	close() error
+}
+
+type dbnSplitOutput struct {
+	writer io.Writer
+	closer func() error
+}
+
+func (o *dbnSplitOutput) write(scanner *dbn.DbnScanner) error {
+	_, err := o.writer.Write(scanner.GetLastRecord()[:scanner.GetLastSize()])
 	return err
 }
+
+func (o *dbnSplitOutput) close() error {
+	return o.closer()
+}
+
+type jsonSplitOutput struct {
+	visitor *JsonWriterVisitor
+	closer  func() error
+}
+
+func (o *jsonSplitOutput) write(scanner *dbn.DbnScanner) error {
+	if tsOut, ok := scanner.GetLastTsOut(); ok {
+		o.visitor.SetTsOut(tsOut)
+	}
+	return scanner.Visit(o.visitor)
+}
+
+func (o *jsonSplitOutput) close() error {
+	return o.closer()
+}
+
+type parquetSplitOutput struct {
+	pw           *pqfile.Writer
+	rgw          pqfile.BufferedRowGroupWriter // nil until the next row is written
+	writeRow     parquetRowWriter
+	dbnSymbolMap *dbn.TsSymbolMap
+	rowGroupRows int // rows of each row group
+	groupRows    int // rows in the current row group
+}
+
+func (o *parquetSplitOutput) write(scanner *dbn.DbnScanner) error {
+	if o.rgw == nil {
+		o.rgw = o.pw.AppendBufferedRowGroup()
+	}
+	if err := o.writeRow(scanner, o.rgw, o.dbnSymbolMap); err != nil {
+		return err
+	}
+	o.groupRows++
+	// Flush full row groups, so each open file buffers at most one
+	if o.groupRows >= o.rowGroupRows {
+		return o.flushRowGroup()
+	}
+	return nil
+}
+
+// flushRowGroup writes the current row group to the file, if any.
+func (o *parquetSplitOutput) flushRowGroup() error {
+	if o.rgw == nil {
+		return nil
+	}
+	err := o.rgw.Close()
+	o.rgw, o.groupRows = nil, 0
+	return err
+}
+
+func (o *parquetSplitOutput) close() error {
+	errClose := o.flushRowGroup()
+	errFlush := o.pw.FlushWithFooter()
+	return errors.Join(errClose, errFlush, o.pw.Close())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// splitOutputCache holds the open splitOutputs, closing the least recently used beyond maxOpen.
+type splitOutputCache struct {
+	maxOpen int
+	lru     *list.List               // of *splitCacheEntry, most recent at front
+	entries map[string]*list.Element // path -> element in lru
+	opens   map[string]int           // path -> number of times opened
+}
+
+type splitCacheEntry struct {
+	path string
+	out  splitOutput
+}
+
+func newSplitOutputCache(maxOpen int) *splitOutputCache {
+	return &splitOutputCache{
+		maxOpen: maxOpen,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		opens:   make(map[string]int),
+	}
+}
+
+// get returns the open output for path, or nil if it is not open.
+func (c *splitOutputCache) get(path string) splitOutput {
+	elem, ok := c.entries[path]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*splitCacheEntry).out
+}
+
+// open opens the output for path, evicting the least recently used if needed.
+// The first open creates the file; later opens append to DBN and JSON, or start a new Parquet file.
+// For DBN, `metadata` is written on the first open.
+func (c *splitOutputCache) open(path string, opts SplitOptions, metadata *dbn.Metadata, dbnSymbolMap *dbn.TsSymbolMap, writeRow parquetRowWriter, sourceMetadata *dbn.Metadata) (splitOutput, error) {
+	if c.maxOpen > 0 && c.lru.Len() >= c.maxOpen {
+		if err := c.evict(); err != nil {
+			return nil, err
+		}
+	}
+
+	numOpens := c.opens[path]
+	c.opens[path] = numOpens + 1
+	appending := numOpens > 0
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var out splitOutput
+	switch opts.Format {
+	case SplitFormat_Parquet:
+		filePath := path
+		if appending {
+			filePath = parquetRollPath(path, numOpens)
+		}
+		outfile, err := os.Create(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "writing to '%s'\n", filePath)
+		}
+		pwProperties := parquet.NewWriterProperties(
+			parquet.WithVersion(parquet.V2_LATEST),
+			parquet.WithCompression(compress.Codecs.Snappy))
+		rowGroupRows := opts.RowGroupRows
+		if rowGroupRows <= 0 {
+			rowGroupRows = DefaultParquetRowGroupRows
+		}
+		out = &parquetSplitOutput{
+			pw:           pqfile.NewParquetWriter(outfile, ParquetGroupNodeForDbnMetadata(sourceMetadata), pqfile.WithWriterProps(pwProperties)),
+			writeRow:     writeRow,
+			dbnSymbolMap: dbnSymbolMap,
+			rowGroupRows: rowGroupRows,
+		}
+	default:
+		writer, closer, err := openSplitWriter(path, appending, opts.Compression)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Verbose && !appending {
+			fmt.Fprintf(os.Stderr, "writing to '%s'\n", path)
+		}
+		if opts.Format == SplitFormat_Json {
+			out = &jsonSplitOutput{visitor: NewJsonWriterVisitor(writer, dbn.JsonEncoderOptions{}), closer: closer}
+		} else {
+			if !appending {
+				if err := metadata.Write(writer); err != nil {
+					closer()
+					return nil, fmt.Errorf("failed to write file header '%s': %w", path, err)
+				}
+			}
+			out = &dbnSplitOutput{writer: writer, closer: closer}
+		}
+	}
+
+	// Registered under the template path, even if Parquet rolled to another file
+	c.entries[path] = c.lru.PushFront(&splitCacheEntry{path: path, out: out})
+	return out, nil
+}
+
+// evict closes the least recently used output.
+func (c *splitOutputCache) evict() error {
+	elem := c.lru.Back()
+	if elem == nil {
+		return nil
+	}
+	entry := c.lru.Remove(elem).(*splitCacheEntry)
+	delete(c.entries, entry.path)
+	return entry.out.close()
+}
+
+// closeAll closes all the open outputs.
+func (c *splitOutputCache) closeAll() error {
+	var err error
+	for c.lru.Len() > 0 {
+		err = errors.Join(err, c.evict())
+	}
+	return err
+}
+
+// parquetRollPath returns the path of the n'th file for a Parquet path, e.g. "x.parquet" -> "x-1.parquet".
+func parquetRollPath(path string, n int) string {
+	if stem, ok := strings.CutSuffix(path, ".parquet"); ok {
+		return fmt.Sprintf("%s-%d.parquet", stem, n)
+	}
+	return fmt.Sprintf("%s-%d", path, n)
+}
+
+// openSplitWriter creates or appends to a buffered file, compressing with the options' codec,
+// or else the codec of its suffix, such as ".zst" or ".gz".
+// Appending to a compressed file adds another frame, which readers decode as one stream.
+func openSplitWriter(path string, appending bool, compression dbn.CompressionOptions) (io.Writer, func() error, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appending {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if compression.Compression == dbn.Compress_None {
+		compression.Compression = dbn.CompressionFromFilename(path)
+	}
+	if compression.Compression != dbn.Compress_None {
+		compressedWriter, err := dbn.NewCompressedWriter(file, compression)
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		return compressedWriter, func() error {
+			return errors.Join(compressedWriter.Close(), file.Close())
+		}, nil
+	}
+
+	bufWriter := bufio.NewWriter(file)
+	return bufWriter, func() error {
+		return errors.Join(bufWriter.Flush(), file.Close())
+	}, nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_calendar "github.com/NimbleMarkets/dbn-go/calendar"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+)
+
+// writeAlternatingOhlcv writes a DBN file of `n` candles alternating between instruments 1 and 2.
+func writeAlternatingOhlcv(t *testing.T, filename string, n int) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	metadata := &dbn.Metadata{
+		VersionNum:    dbn.HeaderVersion3,
+		Schema:        dbn.Schema_Ohlcv1S,
+		Dataset:       "XNAS.ITCH",
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Mappings: []dbn.SymbolMapping{
+			{RawSymbol: "AAA", Intervals: []dbn.MappingInterval{{StartDate: 20240102, EndDate: 20240103, Symbol: "1"}}},
+			{RawSymbol: "BBB", Intervals: []dbn.MappingInterval{{StartDate: 20240102, EndDate: 20240103, Symbol: "2"}}},
+		},
+	}
+	writer, err := dbn.NewDbnWriter(&buf, metadata)
+	if err != nil {
+		t.Fatalf("NewDbnWriter: %v", err)
+	}
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		record := dbn.OhlcvMsg{
+			Header: dbn.RHeader{
+				RType:        dbn.RType_Ohlcv1S,
+				PublisherID:  2,
+				InstrumentID: uint32(1 + i%2),
+				TsEvent:      uint64(start.Add(time.Duration(i) * time.Second).UnixNano()),
+			},
+			Open: 100_000_000_000, High: 101_000_000_000, Low: 99_000_000_000, Close: 100_500_000_000,
+			Volume: uint64(i),
+		}
+		if err := writer.Write(&record); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestSplitFile_DefaultLayout(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeAlternatingOhlcv(t, src, 6)
+
+	dest := filepath.Join(dir, "split")
+	if err := SplitFile(src, dest, false, false); err != nil {
+		t.Fatalf("SplitFile returned error: %v", err)
+	}
+
+	for _, symbol := range []string{"AAA", "BBB"} {
+		splitFile := filepath.Join(dest, "XNAS.ITCH", symbol, "2024", "01", "02", symbol+".20240102.ohlcv-1s.dbn.zst")
+		if got := countDBNRecords(t, splitFile, false); got != 3 {
+			t.Fatalf("%s record count mismatch: got %d want 3", symbol, got)
+		}
+
+		reader, closer, err := dbn.MakeCompressedReader(splitFile, false)
+		if err != nil {
+			t.Fatalf("MakeCompressedReader: %v", err)
+		}
+		metadata, err := dbn.ReadMetadata(reader)
+		closer.Close()
+		if err != nil {
+			t.Fatalf("ReadMetadata: %v", err)
+		}
+		if metadata.VersionNum != dbn.HeaderVersion3 {
+			t.Fatalf("version mismatch: got %d want %d", metadata.VersionNum, dbn.HeaderVersion3)
+		}
+		if len(metadata.Mappings) != 1 || metadata.Mappings[0].RawSymbol != symbol {
+			t.Fatalf("unexpected mappings: %+v", metadata.Mappings)
+		}
+	}
+}
+
+func TestSplitFileWithOptions_MaxOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeAlternatingOhlcv(t, src, 6)
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, dest string)
+	}{
+		{
+			format: SplitFormat_Dbn,
+			check: func(t *testing.T, dest string) {
+				// Reopened zstd files append frames after the metadata
+				if got := countDBNRecords(t, filepath.Join(dest, "1.dbn.zst"), false); got != 3 {
+					t.Fatalf("record count mismatch: got %d want 3", got)
+				}
+			},
+		},
+		{
+			format: SplitFormat_Json,
+			check: func(t *testing.T, dest string) {
+				data, err := os.ReadFile(filepath.Join(dest, "1.json"))
+				if err != nil {
+					t.Fatalf("ReadFile: %v", err)
+				}
+				if got := bytes.Count(data, []byte{'\n'}); got != 3 {
+					t.Fatalf("line count mismatch: got %d want 3", got)
+				}
+			},
+		},
+		{
+			format: SplitFormat_Parquet,
+			check: func(t *testing.T, dest string) {
+				var rows int64
+				for _, name := range []string{"1.parquet", "1-1.parquet", "1-2.parquet"} {
+					rows += countParquetRows(t, filepath.Join(dest, name))
+				}
+				if rows != 3 {
+					t.Fatalf("row count mismatch: got %d want 3", rows)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			dest := filepath.Join(dir, tt.format)
+			opts := SplitOptions{
+				PathTemplate: "{instrument_id}.{ext}",
+				Format:       tt.format,
+				MaxOpenFiles: 1,
+			}
+			if err := SplitFileWithOptions(src, dest, false, opts); err != nil {
+				t.Fatalf("SplitFileWithOptions returned error: %v", err)
+			}
+			tt.check(t, dest)
+		})
+	}
+}
+
+func TestSplitFileWithOptions_ParquetRowGroups(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeAlternatingOhlcv(t, src, 10)
+
+	// Each instrument's file has a row group per RowGroupRows, rather than one buffered until close
+	dest := filepath.Join(dir, "dest")
+	opts := SplitOptions{
+		PathTemplate: "{instrument_id}.{ext}",
+		Format:       SplitFormat_Parquet,
+		RowGroupRows: 2,
+	}
+	if err := SplitFileWithOptions(src, dest, false, opts); err != nil {
+		t.Fatalf("SplitFileWithOptions returned error: %v", err)
+	}
+	reader, err := pqfile.OpenParquetFile(filepath.Join(dest, "1.parquet"), false)
+	if err != nil {
+		t.Fatalf("OpenParquetFile returned error: %v", err)
+	}
+	defer reader.Close()
+	if reader.NumRows() != 5 || reader.NumRowGroups() != 3 {
+		t.Fatalf("got %d rows in %d row groups, want 5 in 3", reader.NumRows(), reader.NumRowGroups())
+	}
+}
+
+func TestSplitFileWithOptions_Compression(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeAlternatingOhlcv(t, src, 6)
+
+	for _, compression := range []dbn.Compression{dbn.Compress_Gzip, dbn.Compress_Bzip2, dbn.Compress_Xz, dbn.Compress_Lz4} {
+		t.Run(compression.String(), func(t *testing.T) {
+			dest := filepath.Join(dir, compression.String())
+			opts := SplitOptions{
+				PathTemplate: "{instrument_id}.{ext}",
+				MaxOpenFiles: 1,
+				Compression:  dbn.CompressionOptions{Compression: compression, Level: 1},
+			}
+			if err := SplitFileWithOptions(src, dest, false, opts); err != nil {
+				t.Fatalf("SplitFileWithOptions returned error: %v", err)
+			}
+			// Reopened files append streams, which are read as one
+			if got := countDBNRecords(t, filepath.Join(dest, "1.dbn"+compression.FileSuffix()), false); got != 3 {
+				t.Fatalf("record count mismatch: got %d want 3", got)
+			}
+		})
+	}
+}
+
+func TestSplitFile_URLSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.dbn")
+	writeAlternatingOhlcv(t, src, 6)
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	dest := filepath.Join(dir, "split")
+	opts := SplitOptions{PathTemplate: "{instrument_id}.{ext}"}
+	if err := SplitFileWithOptions(server.URL+"/src.dbn", dest, false, opts); err != nil {
+		t.Fatalf("SplitFileWithOptions returned error: %v", err)
+	}
+	if got := countDBNRecords(t, filepath.Join(dest, "2.dbn.zst"), false); got != 3 {
+		t.Fatalf("record count mismatch: got %d want 3", got)
+	}
+}
+
+func TestSplitPathTemplate(t *testing.T) {
+	if _, err := parseSplitPathTemplate("{dataset}/{nope}.dbn"); err == nil {
+		t.Fatalf("expected error for unknown placeholder")
+	}
+	if _, err := parseSplitPathTemplate("{dataset"); err == nil {
+		t.Fatalf("expected error for unclosed placeholder")
+	}
+
+	tmpl, err := parseSplitPathTemplate("{publisher_id}/{symbol}/{ymd}T{hh}-{session_date}.{ext}")
+	if err != nil {
+		t.Fatalf("parseSplitPathTemplate returned error: %v", err)
+	}
+	// 23:30 UTC is 17:30 in Chicago, after the 17:00 CME session open
+	header := dbn.RHeader{PublisherID: 1, InstrumentID: 42}
+	got := tmpl.expand(splitPathValues{
+		metadata:   &dbn.Metadata{Dataset: "GLBX.MDP3", Schema: dbn.Schema_Trades},
+		format:     SplitFormat_Json,
+		symbol:     "ES/H4",
+		header:     &header,
+		recordTime: time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC),
+		calendar:   dbn_calendar.NewRegistry().Get(dbn_calendar.Calendar_CME),
+	})
+	if want := "1/ES_H4/20240102T23-20240103.json"; got != want {
+		t.Fatalf("expand = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_calendar "github.com/NimbleMarkets/dbn-go/calendar"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+	pqschema "github.com/apache/arrow-go/v18/parquet/schema"
+)
+
+const (
+	StatsTableFormat_Csv     = "csv"
+	StatsTableFormat_Parquet = "parquet"
+)
+
+// StatsTableOptions controls how statistics files are folded and written by WriteStatsTable.
+type StatsTableOptions struct {
+	Format         string                 // Output format, one of StatsTableFormat_*; by the destination's suffix if empty
+	Calendar       *dbn_calendar.Calendar // Calendar for the trading date of statistics without a `ts_ref`; the first source dataset's calendar in Calendars if nil
+	Calendars      *dbn_calendar.Registry // Calendars to look up the source dataset's; the built-in calendars if nil
+	ForceZstdInput bool                   // Force input to be zstd, irrespective of filename suffix
+}
+
+// statsTableSource is a StatsTable with the symbol maps of the files it was read from.
+type statsTableSource struct {
+	table      *dbn.StatsTable
+	symbolMaps []*dbn.TsSymbolMap
+}
+
+// symbol returns the symbol of a row's instrument on its date, or empty string if unmapped.
+func (s *statsTableSource) symbol(row *dbn.StatsRow) string {
+	date := dbn.YMDToTime(int(row.Date), time.UTC)
+	for _, tsm := range s.symbolMaps {
+		if symbol := tsm.Get(date, row.InstrumentID); symbol != "" {
+			return symbol
+		}
+	}
+	return ""
+}
+
+// statsTableColumn returns the column name of a StatType, such as "settlement_price".
+func statsTableColumn(statType dbn.StatType) string {
+	if name := statType.String(); name != "" {
+		return name
+	}
+	return "stat_type_" + strconv.Itoa(int(statType))
+}
+
+// WriteStatsTable folds the statistics records of DBN source files into a row per instrument and
+// trading date, with a column per StatType, as dbn.StatsTable, and writes them to `destFile` as CSV or Parquet.
+// Rows have `date`, `instrument_id`, and `symbol` columns, then the value of each StatType present:
+// prices as decimals and quantities as integers, empty or null if there is none.
+func WriteStatsTable(sourceFiles []string, opts StatsTableOptions, destFile string) error {
+	format := opts.Format
+	if format == "" {
+		format = StatsTableFormat_Csv
+		if strings.HasSuffix(destFile, ".parquet") {
+			format = StatsTableFormat_Parquet
+		}
+	}
+	if format != StatsTableFormat_Csv && format != StatsTableFormat_Parquet {
+		return fmt.Errorf("unknown stats-table format '%s'", format)
+	}
+
+	source := &statsTableSource{}
+	for _, sourceFile := range sourceFiles {
+		if err := source.readDbnFile(sourceFile, &opts); err != nil {
+			return fmt.Errorf("failed to read '%s': %w", sourceFile, err)
+		}
+	}
+	if source.table == nil {
+		source.table = dbn.NewStatsTable(nil)
+	}
+
+	writer, writerCloser, err := dbn.MakeCompressedWriter(destFile, false)
+	if err != nil {
+		return fmt.Errorf("failed to create writer %w", err)
+	}
+	defer writerCloser()
+	if format == StatsTableFormat_Parquet {
+		return writeStatsTableParquet(source, writer)
+	}
+	return writeStatsTableCsv(source, writer)
+}
+
+// readDbnFile folds the statistics of a DBN file into the table, creating it with the calendar
+// of the first file's dataset, unless `opts` has one.
+func (s *statsTableSource) readDbnFile(sourceFile string, opts *StatsTableOptions) error {
+	reader, closer, err := dbn.MakeCompressedReader(sourceFile, opts.ForceZstdInput)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	scanner := dbn.NewDbnScanner(reader)
+	metadata, err := scanner.Metadata()
+	if err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+	tsm := dbn.NewTsSymbolMap()
+	if err := tsm.FillFromMetadata(metadata); err != nil {
+		return fmt.Errorf("failed to fill symbol map: %w", err)
+	}
+	s.symbolMaps = append(s.symbolMaps, tsm)
+
+	if s.table == nil {
+		if opts.Calendar == nil {
+			if opts.Calendars == nil {
+				opts.Calendars = dbn_calendar.NewRegistry()
+			}
+			opts.Calendar = opts.Calendars.ForDatasetName(metadata.Dataset)
+		}
+		var sessionDate func(uint64) uint32
+		if opts.Calendar != nil {
+			sessionDate = opts.Calendar.SessionDateOf
+		}
+		s.table = dbn.NewStatsTable(sessionDate)
+	}
+	return s.table.ReadDbn(scanner)
+}
+
+// writeStatsTableCsv writes the table as CSV, with a header row.
+func writeStatsTableCsv(source *statsTableSource, writer io.Writer) error {
+	statTypes := source.table.StatTypes()
+	csvWriter := csv.NewWriter(writer)
+	header := []string{"date", "instrument_id", "symbol"}
+	for _, statType := range statTypes {
+		header = append(header, statsTableColumn(statType))
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	fields := make([]string, len(header))
+	for _, row := range source.table.Rows() {
+		fields[0] = dbn.YMDToTime(int(row.Date), time.UTC).Format(time.DateOnly)
+		fields[1] = strconv.FormatUint(uint64(row.InstrumentID), 10)
+		fields[2] = source.symbol(row)
+		for i, statType := range statTypes {
+			fields[3+i] = ""
+			if value, ok := row.Value(statType); ok {
+				if statType.IsQuantity() {
+					fields[3+i] = strconv.FormatInt(value, 10)
+				} else {
+					fields[3+i] = dbn.Price(value).String()
+				}
+			}
+		}
+		if err := csvWriter.Write(fields); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// ParquetGroupNode_StatsTable returns the Parquet Schema's Group Node for the rows of a StatsTable
+// with the given StatTypes.
+//
+// optional int32 field_id=-1 date (Date);
+// optional int32 field_id=-1 instrument_id (Int(bitWidth=32, isSigned=false));
+// optional binary field_id=-1 symbol (String);
+// optional double field_id=-1 <price stat type>;
+// optional int64 field_id=-1 <quantity stat type> (Int(bitWidth=64, isSigned=true));
+func ParquetGroupNode_StatsTable(statTypes []dbn.StatType) *pqschema.GroupNode {
+	fields := pqschema.FieldList{
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("date", parquet.Repetitions.Optional, pqschema.DateLogicalType{}, parquet.Types.Int32, 0, -1)),
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical("instrument_id", parquet.Repetitions.Optional, pqschema.NewIntLogicalType(32, false), parquet.Types.Int32, 0, -1)),
+		pqschema.MustPrimitive(pqschema.NewPrimitiveNodeConverted("symbol", parquet.Repetitions.Optional, parquet.Types.ByteArray, pqschema.ConvertedTypes.UTF8, 0, 0, 0, -1)),
+	}
+	for _, statType := range statTypes {
+		if statType.IsQuantity() {
+			fields = append(fields, pqschema.MustPrimitive(pqschema.NewPrimitiveNodeLogical(statsTableColumn(statType), parquet.Repetitions.Optional, pqschema.NewIntLogicalType(64, true), parquet.Types.Int64, 0, -1)))
+		} else {
+			fields = append(fields, pqschema.NewFloat64Node(statsTableColumn(statType), parquet.Repetitions.Optional, -1))
+		}
+	}
+	return pqschema.MustGroup(pqschema.NewGroupNode("schema", parquet.Repetitions.Required, fields, -1))
+}
+
+// writeStatsTableParquet writes the table as Parquet, per ParquetGroupNode_StatsTable.
+func writeStatsTableParquet(source *statsTableSource, writer io.Writer) error {
+	statTypes := source.table.StatTypes()
+	pwProperties := parquet.NewWriterProperties(
+		parquet.WithVersion(parquet.V2_LATEST),
+		parquet.WithCompression(compress.Codecs.Snappy))
+	pw := pqfile.NewParquetWriter(writer, ParquetGroupNode_StatsTable(statTypes), pqfile.WithWriterProps(pwProperties))
+	defer pw.Close()
+	rgw := pw.AppendBufferedRowGroup()
+
+	errWrite := func() error {
+		for _, row := range source.table.Rows() {
+			days := dbn.YMDToTime(int(row.Date), time.UTC).Unix() / 86400
+			if err := writeInt32Column(rgw, 0, int32(days)); err != nil {
+				return err
+			}
+			if err := writeInt32Column(rgw, 1, int32(row.InstrumentID)); err != nil {
+				return err
+			}
+			if err := writeByteArrayColumn(rgw, 2, parquet.ByteArray(source.symbol(row))); err != nil {
+				return err
+			}
+			for i, statType := range statTypes {
+				value, ok := row.Value(statType)
+				if statType.IsQuantity() {
+					if err := writeNullableInt64Column(rgw, 3+i, value, ok); err != nil {
+						return err
+					}
+				} else {
+					if !ok {
+						value = dbn.UNDEF_PRICE
+					}
+					if err := writePriceColumn(rgw, 3+i, value); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}()
+
+	errClose := rgw.Close()
+	errFlush := pw.FlushWithFooter()
+	return errors.Join(errWrite, errClose, errFlush)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_calendar "github.com/NimbleMarkets/dbn-go/calendar"
+	pqfile "github.com/apache/arrow-go/v18/parquet/file"
+)
+
+// writeStatsTestFile writes GLBX.MDP3 statistics of an instrument, mapped from `symbol` in January 2024,
+// as a DBN stream of the version.  Unset prices and quantities are written as undefined.
+func writeStatsTestFile(t *testing.T, filename string, version uint8, symbol string, instrumentID uint32, stats ...dbn.StatMsg) {
+	t.Helper()
+
+	metadata := &dbn.Metadata{
+		VersionNum:    version,
+		Schema:        dbn.Schema_Statistics,
+		Dataset:       "GLBX.MDP3",
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: dbn.MetadataV2_SymbolCstrLen,
+		Mappings: []dbn.SymbolMapping{{
+			RawSymbol: symbol,
+			Intervals: []dbn.MappingInterval{{StartDate: 20240101, EndDate: 20240201, Symbol: strconv.Itoa(int(instrumentID))}},
+		}},
+	}
+	records := make([]dbn.RecordEncoder, len(stats))
+	for i := range stats {
+		stats[i].Header = dbn.RHeader{RType: dbn.RType_Statistics, PublisherID: 1, InstrumentID: instrumentID, TsEvent: stats[i].TsRecv}
+		if stats[i].Price == 0 {
+			stats[i].Price = dbn.UNDEF_PRICE
+		}
+		if stats[i].Quantity == 0 {
+			stats[i].Quantity = dbn.StatMsgV3_UNDEF_STAT_QUANTITY
+		}
+		if stats[i].TsRef == 0 {
+			stats[i].TsRef = dbn.UNDEF_TIMESTAMP
+		}
+		records[i] = &stats[i]
+	}
+	writeDbnTestFile(t, filename, metadata, records...)
+}
+
+// writeStatsTestFiles writes the statistics of ESH4 and NQH4 to separate files, returning their names.
+// ESH4 has a settlement and open interest of 2024-01-02, and a session high after the 17:00 CT open,
+// so of the 2024-01-03 session.  NQH4 has only a settlement of 2024-01-02.
+func writeStatsTestFiles(t *testing.T) []string {
+	t.Helper()
+
+	ts := func(hour, minute int) uint64 {
+		return uint64(time.Date(2024, 1, 2, hour, minute, 0, 0, time.UTC).UnixNano())
+	}
+	tsRef := uint64(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano())
+	dir := t.TempDir()
+	es := filepath.Join(dir, "es.statistics.dbn")
+	writeStatsTestFile(t, es, dbn.HeaderVersion3, "ESH4", 1,
+		dbn.StatMsg{TsRecv: ts(22, 0), TsRef: tsRef, Price: 4800_250000000, StatType: uint16(dbn.StatType_SettlementPrice)},
+		dbn.StatMsg{TsRecv: ts(22, 5), TsRef: tsRef, Quantity: 2_000_000, StatType: uint16(dbn.StatType_OpenInterest)},
+		dbn.StatMsg{TsRecv: ts(23, 30), Price: 4810_500000000, StatType: uint16(dbn.StatType_TradingSessionHighPrice)},
+	)
+	nq := filepath.Join(dir, "nq.statistics.dbn")
+	writeStatsTestFile(t, nq, dbn.HeaderVersion3, "NQH4", 2,
+		dbn.StatMsg{TsRecv: ts(22, 0), TsRef: tsRef, Price: 16800_500000000, StatType: uint16(dbn.StatType_SettlementPrice)},
+	)
+	return []string{es, nq}
+}
+
+func TestWriteStatsTable(t *testing.T) {
+	src := filepath.Join("..", "..", "tests", "data", "test_data.statistics.v3.dbn.zst")
+	dst := filepath.Join(t.TempDir(), "stats.csv")
+	if err := WriteStatsTable([]string{src}, StatsTableOptions{}, dst); err != nil {
+		t.Fatalf("WriteStatsTable returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "date,instrument_id,symbol,trading_session_high_price,lowest_offer\n" +
+		"2023-04-24,146945,,100,100\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := WriteStatsTable([]string{src}, StatsTableOptions{Format: "xlsx"}, dst); err == nil {
+		t.Fatalf("expected error for unknown format, got nil")
+	}
+}
+
+func TestWriteStatsTable_SessionsAndSources(t *testing.T) {
+	sources := writeStatsTestFiles(t)
+	dst := filepath.Join(t.TempDir(), "stats.csv")
+	if err := WriteStatsTable(sources, StatsTableOptions{}, dst); err != nil {
+		t.Fatalf("WriteStatsTable returned error: %v", err)
+	}
+
+	// Each file's symbols are mapped, the session high is of the next CME session,
+	// and the missing open interest of NQH4 is empty
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "date,instrument_id,symbol,settlement_price,trading_session_high_price,open_interest\n" +
+		"2024-01-02,1,ESH4,4800.25,,2000000\n" +
+		"2024-01-02,2,NQH4,16800.5,,\n" +
+		"2024-01-03,1,ESH4,,4810.5,\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A calendar option overrides the dataset's
+	utc := dbn_calendar.NewRegistry().Get(dbn_calendar.Calendar_UTC)
+	if err := WriteStatsTable(sources, StatsTableOptions{Calendar: utc}, dst); err != nil {
+		t.Fatalf("WriteStatsTable returned error: %v", err)
+	}
+	if got, err = os.ReadFile(dst); err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want = "date,instrument_id,symbol,settlement_price,trading_session_high_price,open_interest\n" +
+		"2024-01-02,1,ESH4,4800.25,4810.5,2000000\n" +
+		"2024-01-02,2,NQH4,16800.5,,\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch with UTC calendar:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteStatsTable_V2UndefinedQuantity(t *testing.T) {
+	// V2 statistics have their own undefined quantity, which is not a value
+	tsRef := uint64(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano())
+	src := filepath.Join(t.TempDir(), "es.statistics.v2.dbn")
+	writeStatsTestFile(t, src, dbn.HeaderVersion2, "ESH4", 1,
+		dbn.StatMsg{TsRecv: tsRef + 1, TsRef: tsRef, Price: 4800_250000000, StatType: uint16(dbn.StatType_SettlementPrice)},
+		dbn.StatMsg{TsRecv: tsRef + 2, TsRef: tsRef, StatType: uint16(dbn.StatType_OpenInterest)},
+		dbn.StatMsg{TsRecv: tsRef + 3, TsRef: tsRef, Quantity: 1500, StatType: uint16(dbn.StatType_ClearedVolume)},
+	)
+	file, err := os.Open(src)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Close()
+	records, _, err := dbn.ReadDBNToSlice[dbn.StatMsgV2](file)
+	if err != nil || records[1].Quantity != dbn.StatMsgV2_UNDEF_STAT_QUANTITY {
+		t.Fatalf("expected a V2 undefined quantity, got %+v: %v", records, err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "stats.csv")
+	if err := WriteStatsTable([]string{src}, StatsTableOptions{}, dst); err != nil {
+		t.Fatalf("WriteStatsTable returned error: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "date,instrument_id,symbol,settlement_price,cleared_volume,open_interest\n" +
+		"2024-01-02,1,ESH4,4800.25,1500,\n"
+	if string(got) != want {
+		t.Fatalf("csv mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteStatsTable_ParquetNulls(t *testing.T) {
+	sources := writeStatsTestFiles(t)
+	dst := filepath.Join(t.TempDir(), "stats.parquet")
+	if err := WriteStatsTable(sources, StatsTableOptions{}, dst); err != nil {
+		t.Fatalf("WriteStatsTable returned error: %v", err)
+	}
+
+	reader, err := pqfile.OpenParquetFile(dst, false)
+	if err != nil {
+		t.Fatalf("OpenParquetFile: %v", err)
+	}
+	defer reader.Close()
+	table, err := readParquetRowGroup(reader.RowGroup(0))
+	if err != nil {
+		t.Fatalf("readParquetRowGroup returned error: %v", err)
+	}
+	if table.numRows != 3 {
+		t.Fatalf("row count mismatch: got %d want 3", table.numRows)
+	}
+	if got := table.columns["symbol"].strs; got[1] != "NQH4" {
+		t.Fatalf("symbol mismatch: got %v", got)
+	}
+	settlement := table.columns["settlement_price"]
+	if !settlement.valid[1] || settlement.floats[1] != 16800.5 || settlement.valid[2] {
+		t.Fatalf("settlement_price mismatch: %v %v", settlement.floats, settlement.valid)
+	}
+	openInterest := table.columns["open_interest"]
+	if !openInterest.valid[0] || openInterest.ints[0] != 2_000_000 || openInterest.valid[1] || openInterest.valid[2] {
+		t.Fatalf("open_interest mismatch: %v %v", openInterest.ints, openInterest.valid)
+	}
+}
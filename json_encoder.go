@@ -0,0 +1,529 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// JsonEncoderOptions selects among Databento's variants of JSON encoding, as with the
+// `pretty_px`, `pretty_ts`, and `map_symbols` parameters of a historical request.
+type JsonEncoderOptions struct {
+	// PrettyPx writes prices as decimal strings with nine places, such as "3720.250000000",
+	// rather than as fixed-point integer strings.
+	PrettyPx bool
+	// PrettyTs writes timestamps as ISO 8601 strings, such as "2020-12-28T13:00:00.000000000Z",
+	// rather than as integer strings of nanoseconds.
+	PrettyTs bool
+	// UndefSentinels writes undefined prices and timestamps which are not pretty as their
+	// sentinel integers, such as "9223372036854775807", exactly as Databento does.  Otherwise,
+	// and always when pretty, they are written as null.  Only price and timestamp fields are
+	// undefined by their sentinels; sizes and quantities are written as they are.
+	UndefSentinels bool
+	// SymbolMap, if set, adds each record's "symbol" field, mapped from its instrument ID
+	// on the UTC date of its index timestamp: `ts_recv` if it has one, otherwise `ts_event`.
+	SymbolMap *TsSymbolMap
+}
+
+// JsonEncoder writes records as JSON lines in Databento's JSON encoding: 64-bit integers,
+// prices, and timestamps are quoted strings, other integers are numbers, characters and
+// c-strings are strings, and reserved fields are omitted.  Undefined prices and timestamps
+// are null, unless UndefSentinels writes them as Databento does.
+type JsonEncoder struct {
+	writer io.Writer
+	opts   JsonEncoderOptions
+	buf    []byte
+}
+
+// NewJsonEncoder returns a JsonEncoder writing to `writer` with the given options.
+func NewJsonEncoder(writer io.Writer, opts JsonEncoderOptions) *JsonEncoder {
+	return &JsonEncoder{writer: writer, opts: opts}
+}
+
+// Encode writes the record as a line of JSON.
+func (e *JsonEncoder) Encode(record Record) error {
+	return e.EncodeWithTsOut(record, UNDEF_TIMESTAMP)
+}
+
+// EncodeWithTsOut writes the record as a line of JSON, with a "ts_out" field unless `tsOut` is UNDEF_TIMESTAMP.
+// Use with DbnScanner.GetLastTsOut when the stream's Metadata has TsOut set.
+func (e *JsonEncoder) EncodeWithTsOut(record Record, tsOut uint64) error {
+	var err error
+	e.buf, err = AppendJsonRecord(e.buf[:0], record, tsOut, e.opts)
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '\n')
+	_, err = e.writer.Write(e.buf)
+	return err
+}
+
+// AppendJsonRecord appends the JSON encoding of the record to `dst`, without a trailing newline,
+// and returns the extended buffer.  A "ts_out" field is added unless `tsOut` is UNDEF_TIMESTAMP.
+// V1/V2 records are encoded as their current types, as converted by UpgradeRecord.
+// Returns an error for record types other than those of the Visitor interface.
+func AppendJsonRecord(dst []byte, record Record, tsOut uint64, opts JsonEncoderOptions) ([]byte, error) {
+	j := jsonAppender{b: append(dst, '{'), opts: &opts}
+	indexTs, err := j.record(UpgradeRecord(record))
+	if err != nil {
+		return dst, err
+	}
+	if tsOut != UNDEF_TIMESTAMP {
+		j.ts("ts_out", tsOut)
+	}
+	if opts.SymbolMap != nil {
+		header := record.GetHeader()
+		symbol := opts.SymbolMap.Get(TimestampToTime(indexTs).UTC(), header.InstrumentID)
+		if symbol == "" {
+			j.null("symbol")
+		} else {
+			j.str("symbol", []byte(symbol))
+		}
+	}
+	return append(j.b, '}'), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// jsonAppender appends the fields of a JSON object.
+type jsonAppender struct {
+	b     []byte
+	opts  *JsonEncoderOptions
+	comma bool // whether the next field follows another
+}
+
+// key appends the field's key, preceded by a comma if needed.
+func (j *jsonAppender) key(k string) {
+	if j.comma {
+		j.b = append(j.b, ',')
+	}
+	j.b = append(j.b, '"')
+	j.b = append(j.b, k...)
+	j.b = append(j.b, '"', ':')
+	j.comma = true
+}
+
+// open starts an object field, or an array element if `k` is empty.
+func (j *jsonAppender) open(k string) {
+	if k != "" {
+		j.key(k)
+	} else if j.comma {
+		j.b = append(j.b, ',')
+	}
+	j.b = append(j.b, '{')
+	j.comma = false
+}
+
+// close ends an object.
+func (j *jsonAppender) close() {
+	j.b = append(j.b, '}')
+	j.comma = true
+}
+
+func (j *jsonAppender) null(k string) {
+	j.key(k)
+	j.b = append(j.b, "null"...)
+}
+
+// uint appends an unsigned integer of 32 bits or fewer as a number.
+func (j *jsonAppender) uint(k string, v uint64) {
+	j.key(k)
+	j.b = strconv.AppendUint(j.b, v, 10)
+}
+
+// int appends a signed integer of 32 bits or fewer as a number.
+func (j *jsonAppender) int(k string, v int64) {
+	j.key(k)
+	j.b = strconv.AppendInt(j.b, v, 10)
+}
+
+// uint64 appends a 64-bit unsigned integer as a string.
+func (j *jsonAppender) uint64(k string, v uint64) {
+	j.key(k)
+	j.b = append(j.b, '"')
+	j.b = strconv.AppendUint(j.b, v, 10)
+	j.b = append(j.b, '"')
+}
+
+// int64 appends a 64-bit signed integer as a string.
+func (j *jsonAppender) int64(k string, v int64) {
+	j.key(k)
+	j.b = append(j.b, '"')
+	j.b = strconv.AppendInt(j.b, v, 10)
+	j.b = append(j.b, '"')
+}
+
+// px appends a fixed-point price, as a decimal string if PrettyPx.
+func (j *jsonAppender) px(k string, v int64) {
+	if v == UNDEF_PRICE && (j.opts.PrettyPx || !j.opts.UndefSentinels) {
+		j.null(k)
+		return
+	}
+	if !j.opts.PrettyPx {
+		j.int64(k, v)
+		return
+	}
+	j.key(k)
+	j.b = append(j.b, '"')
+	j.b = append(j.b, Price(v).StringFixed(9)...)
+	j.b = append(j.b, '"')
+}
+
+// ts appends a timestamp, as an ISO 8601 string if PrettyTs.
+func (j *jsonAppender) ts(k string, v uint64) {
+	if v == UNDEF_TIMESTAMP && (j.opts.PrettyTs || !j.opts.UndefSentinels) {
+		j.null(k)
+		return
+	}
+	if !j.opts.PrettyTs {
+		j.uint64(k, v)
+		return
+	}
+	j.key(k)
+	j.b = append(j.b, '"')
+	j.b = TimestampToTime(v).UTC().AppendFormat(j.b, jsonPrettyTsLayout)
+	j.b = append(j.b, '"')
+}
+
+// jsonPrettyTsLayout is Databento's ISO 8601 layout, always with nine fractional digits.
+const jsonPrettyTsLayout = "2006-01-02T15:04:05.000000000Z"
+
+// char appends a c_char as a one-character string, or null if NUL.
+func (j *jsonAppender) char(k string, c byte) {
+	if c == 0 {
+		j.null(k)
+		return
+	}
+	j.str(k, []byte{c})
+}
+
+// cstr appends a NUL-terminated c-string as a string.
+func (j *jsonAppender) cstr(k string, b []byte) {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	j.str(k, b)
+}
+
+// str appends a string, escaping it as needed.
+func (j *jsonAppender) str(k string, s []byte) {
+	const hex = "0123456789abcdef"
+	j.key(k)
+	j.b = append(j.b, '"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			j.b = append(j.b, '\\', c)
+		case c == '\n':
+			j.b = append(j.b, '\\', 'n')
+		case c == '\r':
+			j.b = append(j.b, '\\', 'r')
+		case c == '\t':
+			j.b = append(j.b, '\\', 't')
+		case c < 0x20:
+			j.b = append(j.b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		default:
+			j.b = append(j.b, c)
+		}
+	}
+	j.b = append(j.b, '"')
+}
+
+func (j *jsonAppender) header(h *RHeader) {
+	j.open("hd")
+	j.ts("ts_event", h.TsEvent)
+	j.uint("rtype", uint64(h.RType))
+	j.uint("publisher_id", uint64(h.PublisherID))
+	j.uint("instrument_id", uint64(h.InstrumentID))
+	j.close()
+}
+
+// openLevels starts the "levels" array, whose elements are appended with bidAskPair or consolidatedBidAskPair.
+func (j *jsonAppender) openLevels() {
+	j.key("levels")
+	j.b = append(j.b, '[')
+	j.comma = false
+}
+
+// closeLevels ends the "levels" array.
+func (j *jsonAppender) closeLevels() {
+	j.b = append(j.b, ']')
+	j.comma = true
+}
+
+func (j *jsonAppender) bidAskPair(level *BidAskPair) {
+	j.open("")
+	j.px("bid_px", level.BidPx)
+	j.px("ask_px", level.AskPx)
+	j.uint("bid_sz", uint64(level.BidSz))
+	j.uint("ask_sz", uint64(level.AskSz))
+	j.uint("bid_ct", uint64(level.BidCt))
+	j.uint("ask_ct", uint64(level.AskCt))
+	j.close()
+}
+
+func (j *jsonAppender) consolidatedBidAskPair(level *ConsolidatedBidAskPair) {
+	j.open("")
+	j.px("bid_px", level.BidPx)
+	j.px("ask_px", level.AskPx)
+	j.uint("bid_sz", uint64(level.BidSz))
+	j.uint("ask_sz", uint64(level.AskSz))
+	j.uint("bid_pb", uint64(level.BidPb))
+	j.uint("ask_pb", uint64(level.AskPb))
+	j.close()
+}
+
+// record appends the fields of the record, in Databento's order, and returns its index timestamp.
+func (j *jsonAppender) record(record Record) (uint64, error) {
+	switch r := record.(type) {
+	case *Mbp0Msg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.char("action", r.Action)
+		j.char("side", r.Side)
+		j.uint("depth", uint64(r.Depth))
+		j.px("price", r.Price)
+		j.uint("size", uint64(r.Size))
+		j.uint("flags", uint64(r.Flags))
+		j.int("ts_in_delta", int64(r.TsInDelta))
+		j.uint("sequence", uint64(r.Sequence))
+		return r.TsRecv, nil
+	case *Mbp1Msg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.char("action", r.Action)
+		j.char("side", r.Side)
+		j.uint("depth", uint64(r.Depth))
+		j.px("price", r.Price)
+		j.uint("size", uint64(r.Size))
+		j.uint("flags", uint64(r.Flags))
+		j.int("ts_in_delta", int64(r.TsInDelta))
+		j.uint("sequence", uint64(r.Sequence))
+		j.openLevels()
+		j.bidAskPair(&r.Level)
+		j.closeLevels()
+		return r.TsRecv, nil
+	case *Mbp10Msg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.char("action", r.Action)
+		j.char("side", r.Side)
+		j.uint("depth", uint64(r.Depth))
+		j.px("price", r.Price)
+		j.uint("size", uint64(r.Size))
+		j.uint("flags", uint64(r.Flags))
+		j.int("ts_in_delta", int64(r.TsInDelta))
+		j.uint("sequence", uint64(r.Sequence))
+		j.openLevels()
+		for i := range r.Levels {
+			j.bidAskPair(&r.Levels[i])
+		}
+		j.closeLevels()
+		return r.TsRecv, nil
+	case *MboMsg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.char("action", r.Action)
+		j.char("side", r.Side)
+		j.px("price", r.Price)
+		j.uint("size", uint64(r.Size))
+		j.uint("channel_id", uint64(r.ChannelID))
+		j.uint64("order_id", r.OrderID)
+		j.uint("flags", uint64(r.Flags))
+		j.int("ts_in_delta", int64(r.TsInDelta))
+		j.uint("sequence", uint64(r.Sequence))
+		return r.TsRecv, nil
+	case *OhlcvMsg:
+		j.header(&r.Header)
+		j.px("open", r.Open)
+		j.px("high", r.High)
+		j.px("low", r.Low)
+		j.px("close", r.Close)
+		j.uint64("volume", r.Volume)
+		return r.Header.TsEvent, nil
+	case *Cmbp1Msg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		switch r.Header.RType {
+		case RType_Cbbo1S, RType_Cbbo1M:
+			// Subsampled CBBO has the layout of BBO
+			j.char("side", r.Side)
+			j.px("price", r.Price)
+			j.uint("size", uint64(r.Size))
+			j.uint("flags", uint64(r.Flags))
+			j.uint("sequence", uint64(r.Sequence))
+		default:
+			j.char("action", r.Action)
+			j.char("side", r.Side)
+			j.px("price", r.Price)
+			j.uint("size", uint64(r.Size))
+			j.uint("flags", uint64(r.Flags))
+			j.int("ts_in_delta", int64(r.TsInDelta))
+		}
+		j.openLevels()
+		j.consolidatedBidAskPair(&r.Level)
+		j.closeLevels()
+		return r.TsRecv, nil
+	case *BboMsg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.char("side", r.Side)
+		j.px("price", r.Price)
+		j.uint("size", uint64(r.Size))
+		j.uint("flags", uint64(r.Flags))
+		j.uint("sequence", uint64(r.Sequence))
+		j.openLevels()
+		j.bidAskPair(&r.Level)
+		j.closeLevels()
+		return r.TsRecv, nil
+	case *ImbalanceMsg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.px("ref_price", r.RefPrice)
+		j.ts("auction_time", r.AuctionTime)
+		j.px("cont_book_clr_price", r.ContBookClrPrice)
+		j.px("auct_interest_clr_price", r.AuctInterestClrPrice)
+		j.px("ssr_filling_price", r.SsrFillingPrice)
+		j.px("ind_match_price", r.IndMatchPrice)
+		j.px("upper_collar", r.UpperCollar)
+		j.px("lower_collar", r.LowerCollar)
+		j.uint("paired_qty", uint64(r.PairedQty))
+		j.uint("total_imbalance_qty", uint64(r.TotalImbalanceQty))
+		j.uint("market_imbalance_qty", uint64(r.MarketImbalanceQty))
+		j.int("unpaired_qty", int64(r.UnpairedQty))
+		j.char("auction_type", r.AuctionType)
+		j.char("side", r.Side)
+		j.uint("auction_status", uint64(r.AuctionStatus))
+		j.uint("freeze_status", uint64(r.FreezeStatus))
+		j.uint("num_extensions", uint64(r.NumExtensions))
+		j.char("unpaired_side", r.UnpairedSide)
+		j.char("significant_imbalance", r.SignificantImbalance)
+		return r.TsRecv, nil
+	case *StatMsg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.ts("ts_ref", r.TsRef)
+		j.px("price", r.Price)
+		j.int64("quantity", r.Quantity)
+		j.uint("sequence", uint64(r.Sequence))
+		j.int("ts_in_delta", int64(r.TsInDelta))
+		j.uint("stat_type", uint64(r.StatType))
+		j.uint("channel_id", uint64(r.ChannelID))
+		j.uint("update_action", uint64(r.UpdateAction))
+		j.uint("stat_flags", uint64(r.StatFlags))
+		return r.TsRecv, nil
+	case *StatusMsg:
+		j.ts("ts_recv", r.TsRecv)
+		j.header(&r.Header)
+		j.uint("action", uint64(r.Action))
+		j.uint("reason", uint64(r.Reason))
+		j.uint("trading_event", uint64(r.TradingEvent))
+		j.char("is_trading", r.IsTrading)
+		j.char("is_quoting", r.IsQuoting)
+		j.char("is_short_sell_restricted", r.IsShortSellRestricted)
+		return r.TsRecv, nil
+	case *InstrumentDefMsg:
+		j.instrumentDef(r)
+		return r.TsRecv, nil
+	case *ErrorMsg:
+		j.header(&r.Header)
+		j.cstr("err", r.Error[:])
+		j.uint("code", uint64(r.Code))
+		j.uint("is_last", uint64(r.IsLast))
+		return r.Header.TsEvent, nil
+	case *SystemMsg:
+		j.header(&r.Header)
+		j.cstr("msg", r.Message[:])
+		j.uint("code", uint64(r.Code))
+		return r.Header.TsEvent, nil
+	case *SymbolMappingMsg:
+		j.header(&r.Header)
+		j.uint("stype_in", uint64(r.StypeIn))
+		j.str("stype_in_symbol", []byte(r.StypeInSymbol))
+		j.uint("stype_out", uint64(r.StypeOut))
+		j.str("stype_out_symbol", []byte(r.StypeOutSymbol))
+		j.ts("start_ts", r.StartTs)
+		j.ts("end_ts", r.EndTs)
+		return r.Header.TsEvent, nil
+	default:
+		return 0, fmt.Errorf("no JSON encoding for record type %T", record)
+	}
+}
+
+func (j *jsonAppender) instrumentDef(r *InstrumentDefMsg) {
+	j.ts("ts_recv", r.TsRecv)
+	j.header(&r.Header)
+	j.cstr("raw_symbol", r.RawSymbol[:])
+	j.char("security_update_action", r.SecurityUpdateAction)
+	j.char("instrument_class", r.InstrumentClass)
+	j.px("min_price_increment", r.MinPriceIncrement)
+	j.px("display_factor", r.DisplayFactor)
+	j.ts("expiration", r.Expiration)
+	j.ts("activation", r.Activation)
+	j.px("high_limit_price", r.HighLimitPrice)
+	j.px("low_limit_price", r.LowLimitPrice)
+	j.px("max_price_variation", r.MaxPriceVariation)
+	j.px("unit_of_measure_qty", r.UnitOfMeasureQty)
+	j.px("min_price_increment_amount", r.MinPriceIncrementAmount)
+	j.px("price_ratio", r.PriceRatio)
+	j.int("inst_attrib_value", int64(r.InstAttribValue))
+	j.uint("underlying_id", uint64(r.UnderlyingID))
+	j.uint64("raw_instrument_id", r.RawInstrumentID)
+	j.int("market_depth_implied", int64(r.MarketDepthImplied))
+	j.int("market_depth", int64(r.MarketDepth))
+	j.uint("market_segment_id", uint64(r.MarketSegmentID))
+	j.uint("max_trade_vol", uint64(r.MaxTradeVol))
+	j.int("min_lot_size", int64(r.MinLotSize))
+	j.int("min_lot_size_block", int64(r.MinLotSizeBlock))
+	j.int("min_lot_size_round_lot", int64(r.MinLotSizeRoundLot))
+	j.uint("min_trade_vol", uint64(r.MinTradeVol))
+	j.int("contract_multiplier", int64(r.ContractMultiplier))
+	j.int("decay_quantity", int64(r.DecayQuantity))
+	j.int("original_contract_size", int64(r.OriginalContractSize))
+	j.int("appl_id", int64(r.ApplID))
+	j.uint("maturity_year", uint64(r.MaturityYear))
+	j.uint("decay_start_date", uint64(r.DecayStartDate))
+	j.uint("channel_id", uint64(r.ChannelID))
+	j.cstr("currency", r.Currency[:])
+	j.cstr("settl_currency", r.SettlCurrency[:])
+	j.cstr("secsubtype", r.Secsubtype[:])
+	j.cstr("group", r.Group[:])
+	j.cstr("exchange", r.Exchange[:])
+	j.cstr("asset", r.Asset[:])
+	j.cstr("cfi", r.Cfi[:])
+	j.cstr("security_type", r.SecurityType[:])
+	j.cstr("unit_of_measure", r.UnitOfMeasure[:])
+	j.cstr("underlying", r.Underlying[:])
+	j.cstr("strike_price_currency", r.StrikePriceCurrency[:])
+	j.px("strike_price", r.StrikePrice)
+	j.char("match_algorithm", r.MatchAlgorithm)
+	j.uint("main_fraction", uint64(r.MainFraction))
+	j.uint("price_display_format", uint64(r.PriceDisplayFormat))
+	j.uint("sub_fraction", uint64(r.SubFraction))
+	j.uint("underlying_product", uint64(r.UnderlyingProduct))
+	j.uint("maturity_month", uint64(r.MaturityMonth))
+	j.uint("maturity_day", uint64(r.MaturityDay))
+	j.uint("maturity_week", uint64(r.MaturityWeek))
+	j.char("user_defined_instrument", byte(r.UserDefinedInstrument))
+	j.int("contract_multiplier_unit", int64(r.ContractMultiplierUnit))
+	j.int("flow_schedule_type", int64(r.FlowScheduleType))
+	j.uint("tick_rule", uint64(r.TickRule))
+	j.uint("leg_count", uint64(r.LegCount))
+	j.uint("leg_index", uint64(r.LegIndex))
+	j.uint("leg_instrument_id", uint64(r.LegInstrumentID))
+	j.cstr("leg_raw_symbol", r.LegRawSymbol[:])
+	j.char("leg_instrument_class", r.LegInstrumentClass)
+	j.char("leg_side", r.LegSide)
+	j.px("leg_price", r.LegPrice)
+	j.px("leg_delta", r.LegDelta)
+	j.int("leg_ratio_price_numerator", int64(r.LegRatioPriceNumerator))
+	j.int("leg_ratio_price_denominator", int64(r.LegRatioPriceDenominator))
+	j.int("leg_ratio_qty_numerator", int64(r.LegRatioQtyNumerator))
+	j.int("leg_ratio_qty_denominator", int64(r.LegRatioQtyDenominator))
+	j.uint("leg_underlying_id", uint64(r.LegUnderlyingID))
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// encodeDbnFileAsJson returns the records of a DBN file encoded by a JsonEncoder.
+func encodeDbnFileAsJson(filename string, opts dbn.JsonEncoderOptions) string {
+	reader, closer, err := dbn.MakeCompressedReader(filename, false)
+	Expect(err).To(BeNil())
+	defer closer.Close()
+
+	var buf bytes.Buffer
+	encoder := dbn.NewJsonEncoder(&buf, opts)
+	scanner := dbn.NewDbnScanner(reader)
+	for scanner.Next() {
+		record, err := scanner.DecodeAny()
+		Expect(err).To(BeNil())
+		Expect(encoder.Encode(record)).To(Succeed())
+	}
+	return buf.String()
+}
+
+var _ = Describe("JsonEncoder", func() {
+	Context("golden", func() {
+		It("should match Databento's JSON of all DBN versions", func() {
+			golden, err := os.ReadFile("./tests/data/test_data.ohlcv-1s.json")
+			Expect(err).To(BeNil())
+			for _, filename := range []string{
+				"./tests/data/test_data.ohlcv-1s.v1.dbn",
+				"./tests/data/test_data.ohlcv-1s.dbn",
+				"./tests/data/test_data.ohlcv-1s.v3.dbn.zst",
+			} {
+				Expect(encodeDbnFileAsJson(filename, dbn.JsonEncoderOptions{UndefSentinels: true})).To(Equal(string(golden)), filename)
+			}
+		})
+
+		It("should match the snapshot of each record type", func() {
+			// Regression snapshots of this encoder's output, following the field order and formats of
+			// Databento's JSON. Only OHLCV-1s above has a golden file written by Databento itself.
+			for filename, want := range map[string]string{
+				"test_data.mbo.v3.dbn.zst":        `{"ts_recv":"1609160400000704060","hd":{"ts_event":"1609160400000429831","rtype":160,"publisher_id":1,"instrument_id":5482},"action":"C","side":"A","price":"3722750000000","size":1,"channel_id":0,"order_id":"647784973705","flags":128,"ts_in_delta":22993,"sequence":1170352}`,
+				"test_data.trades.v3.dbn.zst":     `{"ts_recv":"1609160400099150057","hd":{"ts_event":"1609160400098821953","rtype":0,"publisher_id":1,"instrument_id":5482},"action":"T","side":"A","depth":0,"price":"3720250000000","size":5,"flags":129,"ts_in_delta":19251,"sequence":1170380}`,
+				"test_data.mbp-1.v3.dbn.zst":      `{"ts_recv":"1609160400006136329","hd":{"ts_event":"1609160400006001487","rtype":1,"publisher_id":1,"instrument_id":5482},"action":"A","side":"A","depth":0,"price":"3720500000000","size":1,"flags":128,"ts_in_delta":17214,"sequence":1170362,"levels":[{"bid_px":"3720250000000","ask_px":"3720500000000","bid_sz":24,"ask_sz":11,"bid_ct":15,"ask_ct":9}]}`,
+				"test_data.cmbp-1.v3.dbn.zst":     `{"ts_recv":"1609160400006136329","hd":{"ts_event":"1609160400006001487","rtype":177,"publisher_id":1,"instrument_id":5482},"action":"A","side":"A","price":"3720500000000","size":1,"flags":128,"ts_in_delta":17214,"levels":[{"bid_px":"3720250000000","ask_px":"3720500000000","bid_sz":24,"ask_sz":11,"bid_pb":1,"ask_pb":1}]}`,
+				"test_data.bbo-1s.v3.dbn.zst":     `{"ts_recv":"1609113600000000000","hd":{"ts_event":"1609113599045849637","rtype":195,"publisher_id":1,"instrument_id":5482},"side":"A","price":"3702500000000","size":2,"flags":168,"sequence":145799,"levels":[{"bid_px":"3702250000000","ask_px":"3702750000000","bid_sz":18,"ask_sz":13,"bid_ct":10,"ask_ct":13}]}`,
+				"test_data.imbalance.v3.dbn.zst":  `{"ts_recv":"1633353900633864350","hd":{"ts_event":"1633353900633854579","rtype":20,"publisher_id":2,"instrument_id":9439},"ref_price":"229430000000","auction_time":"0","cont_book_clr_price":"0","auct_interest_clr_price":"0","ssr_filling_price":"0","ind_match_price":"0","upper_collar":"0","lower_collar":"0","paired_qty":0,"total_imbalance_qty":2000,"market_imbalance_qty":0,"unpaired_qty":0,"auction_type":"O","side":"B","auction_status":0,"freeze_status":0,"num_extensions":0,"unpaired_side":"N","significant_imbalance":"~"}`,
+				"test_data.status.v3.dbn.zst":     `{"ts_recv":"1609113600000000000","hd":{"ts_event":"1609110000000000000","rtype":18,"publisher_id":1,"instrument_id":5482},"action":7,"reason":1,"trading_event":0,"is_trading":"Y","is_quoting":"Y","is_short_sell_restricted":"~"}`,
+				"test_data.statistics.v3.dbn.zst": `{"ts_recv":"1682269536040124325","hd":{"ts_event":"1682269536030443135","rtype":24,"publisher_id":1,"instrument_id":146945},"ts_ref":"18446744073709551615","price":"100000000000","quantity":"9223372036854775807","sequence":2,"ts_in_delta":26961,"stat_type":7,"channel_id":13,"update_action":1,"stat_flags":255}`,
+				"test_data.definition.v3.dbn.zst": `{"ts_recv":"1633417621703120931","hd":{"ts_event":"1633417621703109854","rtype":19,"publisher_id":2,"instrument_id":6830},"raw_symbol":"MSFT","security_update_action":"A","instrument_class":"K","min_price_increment":"9223372036854775807","display_factor":"100000000000000","expiration":"18446744073709551615","activation":"18446744073709551615","high_limit_price":"9223372036854775807","low_limit_price":"9223372036854775807","max_price_variation":"9223372036854775807","unit_of_measure_qty":"9223372036854775807","min_price_increment_amount":"9223372036854775807","price_ratio":"9223372036854775807","inst_attrib_value":2147483647,"underlying_id":0,"raw_instrument_id":"2147483647","market_depth_implied":2147483647,"market_depth":2147483647,"market_segment_id":4294967295,"max_trade_vol":4294967295,"min_lot_size":2147483647,"min_lot_size_block":2147483647,"min_lot_size_round_lot":100,"min_trade_vol":4294967295,"contract_multiplier":2147483647,"decay_quantity":2147483647,"original_contract_size":2147483647,"appl_id":32767,"maturity_year":65535,"decay_start_date":65535,"channel_id":0,"currency":"","settl_currency":"","secsubtype":"Z ","group":"pxnas-1","exchange":"XNAS","asset":"","cfi":"","security_type":"","unit_of_measure":"","underlying":"","strike_price_currency":"","strike_price":"9223372036854775807","match_algorithm":"F","main_fraction":255,"price_display_format":255,"sub_fraction":255,"underlying_product":255,"maturity_month":255,"maturity_day":255,"maturity_week":255,"user_defined_instrument":"N","contract_multiplier_unit":127,"flow_schedule_type":127,"tick_rule":255,"leg_count":0,"leg_index":0,"leg_instrument_id":0,"leg_raw_symbol":"","leg_instrument_class":null,"leg_side":"N","leg_price":"9223372036854775807","leg_delta":"9223372036854775807","leg_ratio_price_numerator":0,"leg_ratio_price_denominator":0,"leg_ratio_qty_numerator":0,"leg_ratio_qty_denominator":0,"leg_underlying_id":0}`,
+			} {
+				got := encodeDbnFileAsJson("./tests/data/"+filename, dbn.JsonEncoderOptions{UndefSentinels: true})
+				Expect(strings.Split(got, "\n")).To(ContainElement(want), filename)
+			}
+		})
+	})
+
+	Context("options", func() {
+		It("should write pretty prices and timestamps", func() {
+			got := encodeDbnFileAsJson("./tests/data/test_data.ohlcv-1s.v3.dbn.zst", dbn.JsonEncoderOptions{PrettyPx: true, PrettyTs: true})
+			Expect(got).To(Equal(`{"hd":{"ts_event":"2020-12-28T13:00:00.000000000Z","rtype":32,"publisher_id":1,"instrument_id":5482},"open":"372025.000000000","high":"372050.000000000","low":"372025.000000000","close":"372050.000000000","volume":"57"}
+{"hd":{"ts_event":"2020-12-28T13:00:01.000000000Z","rtype":32,"publisher_id":1,"instrument_id":5482},"open":"372050.000000000","high":"372050.000000000","low":"372050.000000000","close":"372050.000000000","volume":"13"}
+`))
+		})
+
+		It("should write undefined pretty values as null", func() {
+			got := encodeDbnFileAsJson("./tests/data/test_data.statistics.v3.dbn.zst", dbn.JsonEncoderOptions{PrettyPx: true, PrettyTs: true})
+			line := strings.Split(got, "\n")[0]
+			Expect(line).To(HavePrefix(`{"ts_recv":"2023-04-23T17:05:36.040124325Z","hd":{"ts_event":"2023-04-23T17:05:36.030443135Z",`))
+			Expect(line).To(ContainSubstring(`"ts_ref":null,"price":"100.000000000","quantity":"9223372036854775807"`))
+
+			pxs := []int64{-1, -1_500_000_000, 0, dbn.UNDEF_PRICE}
+			var prices []string
+			for _, px := range pxs {
+				b, err := dbn.AppendJsonRecord(nil, &dbn.OhlcvMsg{Open: px, Header: dbn.RHeader{RType: dbn.RType_Ohlcv1S}}, dbn.UNDEF_TIMESTAMP, dbn.JsonEncoderOptions{PrettyPx: true})
+				Expect(err).To(BeNil())
+				prices = append(prices, string(b[strings.Index(string(b), `"open":`):strings.Index(string(b), `,"high"`)]))
+			}
+			Expect(prices).To(Equal([]string{`"open":"-0.000000001"`, `"open":"-1.500000000"`, `"open":"0.000000000"`, `"open":null`}))
+		})
+
+		It("should write undefined prices and timestamps as null by default", func() {
+			got := encodeDbnFileAsJson("./tests/data/test_data.statistics.v3.dbn.zst", dbn.JsonEncoderOptions{})
+			line := strings.Split(got, "\n")[0]
+			Expect(line).To(ContainSubstring(`"ts_ref":null,"price":"100000000000","quantity":"9223372036854775807"`))
+
+			// Only prices and timestamps are null; other fields keep their maximums
+			got = encodeDbnFileAsJson("./tests/data/test_data.definition.v3.dbn.zst", dbn.JsonEncoderOptions{})
+			line = strings.Split(got, "\n")[0]
+			Expect(line).To(ContainSubstring(`"min_price_increment":null,"display_factor":"100000000000000","expiration":null,"activation":null,`))
+			Expect(line).To(ContainSubstring(`"raw_instrument_id":"2147483647"`))
+			Expect(line).To(ContainSubstring(`"max_trade_vol":4294967295`))
+			Expect(line).NotTo(ContainSubstring(`"18446744073709551615"`))
+		})
+
+		It("should map symbols and add ts_out", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.ohlcv-1s.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			scanner := dbn.NewDbnScanner(reader)
+			metadata, err := scanner.Metadata()
+			Expect(err).To(BeNil())
+			symbolMap := dbn.NewTsSymbolMap()
+			Expect(symbolMap.FillFromMetadata(metadata)).To(Succeed())
+
+			var buf bytes.Buffer
+			encoder := dbn.NewJsonEncoder(&buf, dbn.JsonEncoderOptions{SymbolMap: symbolMap})
+			Expect(scanner.Next()).To(BeTrue())
+			record, err := scanner.DecodeAny()
+			Expect(err).To(BeNil())
+			Expect(encoder.EncodeWithTsOut(record, 1609160400000001000)).To(Succeed())
+			Expect(buf.String()).To(HaveSuffix(`"volume":"57","ts_out":"1609160400000001000","symbol":"ESH1"}` + "\n"))
+
+			// Unmapped instruments have a null symbol
+			ohlcv := *record.(*dbn.OhlcvMsg)
+			ohlcv.Header.InstrumentID = 1
+			buf.Reset()
+			Expect(encoder.Encode(&ohlcv)).To(Succeed())
+			Expect(buf.String()).To(HaveSuffix(`"volume":"57","symbol":null}` + "\n"))
+		})
+
+		It("should escape strings", func() {
+			var msg dbn.SystemMsg
+			copy(msg.Message[:], "say \"hi\"\\\n\x01")
+			b, err := dbn.AppendJsonRecord(nil, &msg, dbn.UNDEF_TIMESTAMP, dbn.JsonEncoderOptions{})
+			Expect(err).To(BeNil())
+			Expect(string(b)).To(ContainSubstring(`"msg":"say \"hi\"\\\n\u0001","code":0}`))
+		})
+
+		It("should encode older layouts as their current types", func() {
+			v2, err := dbn.AppendJsonRecord(nil, &dbn.StatMsgV2{Quantity: 5}, dbn.UNDEF_TIMESTAMP, dbn.JsonEncoderOptions{})
+			Expect(err).To(BeNil())
+			v3, err := dbn.AppendJsonRecord(nil, &dbn.StatMsgV3{Quantity: 5}, dbn.UNDEF_TIMESTAMP, dbn.JsonEncoderOptions{})
+			Expect(err).To(BeNil())
+			Expect(string(v2)).To(Equal(string(v3)))
+		})
+
+		It("should reject record types without an encoding", func() {
+			_, err := dbn.AppendJsonRecord(nil, unvisitableRecord{}, dbn.UNDEF_TIMESTAMP, dbn.JsonEncoderOptions{})
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -4,7 +4,9 @@ package dbn
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"math"
 
 	"github.com/valyala/fastjson"
 )
@@ -16,18 +18,30 @@ type JsonScanner struct {
 	scanner *bufio.Scanner
 }
 
-// NewJsonScanner creates a new dbn.JsonScanner from a byte array
+// NewJsonScanner creates a new dbn.JsonScanner from a byte array.
+// Lines may be arbitrarily long, such as InstrumentDefMsg with many legs.
 func NewJsonScanner(r io.Reader) *JsonScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, jsonScannerInitialBufferSize), math.MaxInt)
 	return &JsonScanner{
-		scanner: bufio.NewScanner(r),
+		scanner: scanner,
 	}
 }
 
+// jsonScannerInitialBufferSize is the initial line buffer size of a JsonScanner, which grows as needed.
+const jsonScannerInitialBufferSize = 64 * 1024
+
 // Next parses the next JSON value from the data.  Returns true on success.
 // Returns false either on error or on the end of data. Call Error() in order to determine the cause of the returned false.
+// Blank lines are skipped.
 // Acces the raw data with GetLastRecord, JsonScannerDecocde, or Visit.
 func (s *JsonScanner) Next() bool {
-	return s.scanner.Scan()
+	for s.scanner.Scan() {
+		if len(bytes.TrimSpace(s.scanner.Bytes())) != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Error returns the last error from Next().
@@ -67,14 +81,42 @@ func JsonScannerDecode[R Record, RP RecordPtr[R]](s *JsonScanner) (*R, error) {
 	}
 }
 
+// DecodeAny parses the Scanner's current record as the concrete type of its RType, such as *Mbp0Msg,
+// returned as a Record.  Returns ErrUnknownRType if there is no type for the RType.
+func (s *JsonScanner) DecodeAny() (Record, error) {
+	val, header, err := s.parseWithHeader()
+	if err != nil {
+		return nil, err
+	}
+	return decodeJsonRecord(val, header)
+}
+
 // Parses the current Record and passes it to the Visitor.
+// If the record has an unknown RType or fails to decode, it is passed to the Visitor's
+// OnUnknownRType or OnDecodeError, if it implements UnknownRTypeHandler or DecodeErrorHandler;
+// otherwise the error is returned.
 func (s *JsonScanner) Visit(visitor Visitor) error {
 	val, header, err := s.parseWithHeader()
 	if err != nil {
 		// TODO: EOF -> OnStreamEnd
-		return err
+		return handleVisitError(visitor, nil, s.scanner.Bytes(), err)
 	}
-	return dispatchJsonVisitor(val, header, visitor)
+	record, err := decodeJsonRecord(val, header)
+	if err != nil {
+		return handleVisitError(visitor, header, s.scanner.Bytes(), err)
+	}
+	return VisitRecord(visitor, record)
+}
+
+// GetLastTsOut returns the gateway send timestamp, `ts_out`, of the last record read, as written
+// by JsonEncoder.EncodeWithTsOut.  Returns false if the record has no "ts_out" field.
+func (s *JsonScanner) GetLastTsOut() (uint64, bool) {
+	var p fastjson.Parser
+	val, err := p.ParseBytes(s.scanner.Bytes())
+	if err != nil || val.Get("ts_out") == nil {
+		return 0, false
+	}
+	return fastjson_GetUint64Tolerant(val, "ts_out"), true
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -94,118 +136,51 @@ func (s *JsonScanner) parseWithHeader() (*fastjson.Value, *RHeader, error) {
 	return val, &header, err
 }
 
-func dispatchJsonVisitor(val *fastjson.Value, header *RHeader, visitor Visitor) error {
+// jsonRecord is a Record which decodes from JSON.
+type jsonRecord interface {
+	Record
+	Fill_Json(val *fastjson.Value, header *RHeader) error
+}
+
+// decodeJsonRecord decodes the JSON value as the concrete type of the header's RType.
+func decodeJsonRecord(val *fastjson.Value, header *RHeader) (Record, error) {
+	var record jsonRecord
 	switch header.RType {
-	// Trade
 	case RType_Mbp0:
-		record := Mbp0Msg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp0(&record)
-		}
-	// Mbp1
+		record = &Mbp0Msg{}
 	case RType_Mbp1:
-		record := Mbp1Msg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp1(&record)
-		}
-	// Mbp10
+		record = &Mbp1Msg{}
 	case RType_Mbp10:
-		record := Mbp10Msg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbp10(&record)
-		}
-	// Mbo
+		record = &Mbp10Msg{}
 	case RType_Mbo:
-		record := MboMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnMbo(&record)
-		}
-	// Candlestick schemas
+		record = &MboMsg{}
 	case RType_Ohlcv1S, RType_Ohlcv1M, RType_Ohlcv1H, RType_Ohlcv1D, RType_OhlcvEod, RType_OhlcvDeprecated:
-		record := OhlcvMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnOhlcv(&record)
-		}
-	// CBBO schemas
+		record = &OhlcvMsg{}
 	case RType_Cmbp1, RType_Cbbo1S, RType_Cbbo1M, RType_Tcbbo:
-		record := Cmbp1Msg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnCmbp1(&record)
-		}
-	// Imbalance
+		record = &Cmbp1Msg{}
+	case RType_Bbo1S, RType_Bbo1M:
+		record = &BboMsg{}
 	case RType_Imbalance:
-		record := ImbalanceMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnImbalance(&record)
-		}
-	// Statistics
+		record = &ImbalanceMsg{}
 	case RType_Statistics:
-		record := StatMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnStatMsg(&record)
-		}
-	// Status
+		record = &StatMsg{}
 	case RType_Status:
-		record := StatusMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnStatusMsg(&record)
-		}
-	// Status
+		record = &StatusMsg{}
 	case RType_InstrumentDef:
-		record := InstrumentDefMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnInstrumentDefMsg(&record)
-		}
-		// SymbolMapping
+		record = &InstrumentDefMsg{}
 	case RType_SymbolMapping:
-		record := SymbolMappingMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnSymbolMappingMsg(&record)
-		}
-	// System
+		record = &SymbolMappingMsg{}
 	case RType_System:
-		record := SystemMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnSystemMsg(&record)
-		}
-	// Error
+		record = &SystemMsg{}
 	case RType_Error:
-		record := ErrorMsg{}
-		if err := record.Fill_Json(val, header); err != nil {
-			return err // TODO: OnError()
-		} else {
-			return visitor.OnErrorMsg(&record)
-		}
-	// Unknown
+		record = &ErrorMsg{}
 	default:
-		return ErrUnknownRType
+		return nil, ErrUnknownRType
+	}
+	if err := record.Fill_Json(val, header); err != nil {
+		return nil, err
 	}
-	// RType_Status
-	// RType_InstrumentDef
+	return record, nil
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -1,7 +1,9 @@
 package dbn_test
 
 import (
+	"bytes"
 	"os"
+	"strings"
 
 	"github.com/NimbleMarkets/dbn-go"
 	. "github.com/onsi/ginkgo/v2"
@@ -15,7 +17,25 @@ var _ = Describe("JsonScanner", func() {
 			Expect(err).To(BeNil())
 			defer file.Close()
 
-			// TODO: slurp meta
+			metadata, err := dbn.ReadJsonMetadata(file)
+			Expect(err).To(BeNil())
+			Expect(metadata.VersionNum).To(Equal(uint8(2)))
+			Expect(metadata.Dataset).To(Equal("GLBX.MDP3"))
+			Expect(metadata.Schema).To(Equal(dbn.Schema_Ohlcv1S))
+			Expect(metadata.Start).To(Equal(uint64(1609160400000000000)))
+			Expect(metadata.End).To(Equal(uint64(1609200000000000000)))
+			Expect(metadata.Limit).To(Equal(uint64(2)))
+			Expect(metadata.StypeIn).To(Equal(dbn.SType_RawSymbol))
+			Expect(metadata.StypeOut).To(Equal(dbn.SType_InstrumentId))
+			Expect(metadata.TsOut).To(Equal(uint8(0)))
+			Expect(metadata.SymbolCstrLen).To(Equal(uint16(71)))
+			Expect(metadata.Symbols).To(Equal([]string{"ESH1"}))
+			Expect(metadata.Partial).To(BeEmpty())
+			Expect(metadata.NotFound).To(BeEmpty())
+			Expect(metadata.Mappings).To(Equal([]dbn.SymbolMapping{{
+				RawSymbol: "ESH1",
+				Intervals: []dbn.MappingInterval{{StartDate: 20201228, EndDate: 20201229, Symbol: "5482"}},
+			}}))
 		})
 		It("should read a JSON test file correctly", func() {
 			file, err := os.Open("./tests/data/test_data.ohlcv-1s.json")
@@ -53,4 +73,41 @@ var _ = Describe("JsonScanner", func() {
 			Expect(r1.Volume).To(Equal(uint64(13)))
 		})
 	})
+
+	Context("pretty and long lines", func() {
+		It("should read pretty prices and timestamps", func() {
+			for _, filename := range []string{
+				"test_data.mbo.v3.dbn.zst",
+				"test_data.mbp-10.v3.dbn.zst",
+				"test_data.cbbo-1s.v3.dbn.zst",
+				"test_data.imbalance.v3.dbn.zst",
+				"test_data.statistics.v3.dbn.zst",
+				"test_data.definition.v3.dbn.zst",
+			} {
+				raw := encodeDbnFileAsJson("./tests/data/"+filename, dbn.JsonEncoderOptions{})
+				pretty := encodeDbnFileAsJson("./tests/data/"+filename, dbn.JsonEncoderOptions{PrettyPx: true, PrettyTs: true})
+
+				// Decoding the pretty JSON gives back the same records
+				var buf bytes.Buffer
+				encoder := dbn.NewJsonEncoder(&buf, dbn.JsonEncoderOptions{})
+				scanner := dbn.NewJsonScanner(strings.NewReader(pretty))
+				for scanner.Next() {
+					record, err := scanner.DecodeAny()
+					Expect(err).To(BeNil(), filename)
+					Expect(encoder.Encode(record)).To(Succeed())
+				}
+				Expect(scanner.Error()).To(BeNil())
+				Expect(buf.String()).To(Equal(raw), filename)
+			}
+		})
+
+		It("should read lines longer than 64KB and skip blank lines", func() {
+			long := `{"hd":{"ts_event":"1609160400000000000","rtype":32,"publisher_id":1,"instrument_id":5482},"note":"` +
+				strings.Repeat("x", 256*1024) + `","open":"372025000000000","high":"372050000000000","low":"372025000000000","close":"372050000000000","volume":"57"}`
+			records, err := dbn.ReadJsonToSlice[dbn.OhlcvMsg](strings.NewReader("\n" + long + "\n\n" + long + "\n"))
+			Expect(err).To(BeNil())
+			Expect(records).To(HaveLen(2))
+			Expect(records[1].Volume).To(Equal(uint64(57)))
+		})
+	})
 })
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_live
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// LatencyStats accumulates the latencies of live records, measured from their timestamps
+// when the session is started with LiveConfig.SendTsOut:
+//
+//   - ExchangeToGateway is `ts_out - ts_recv`, from Databento's capture server receiving
+//     the exchange's message to the gateway sending the record.
+//   - GatewayToClient is the local receive time minus `ts_out`, which includes clock
+//     skew between the gateway and the local host.
+//
+// Every sample is kept, so percentiles are exact.  LatencyStats is not safe for concurrent use.
+type LatencyStats struct {
+	exchangeToGateway []time.Duration
+	gatewayToClient   []time.Duration
+}
+
+// LatencyPercentiles summarizes a set of latency samples.
+type LatencyPercentiles struct {
+	Count int
+	Min   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	Max   time.Duration
+}
+
+// NewLatencyStats returns an empty LatencyStats.
+func NewLatencyStats() *LatencyStats {
+	return &LatencyStats{}
+}
+
+// Observe adds the latencies of a record with the given `ts_recv` and `ts_out`, received locally at `localRecv`.
+// A `ts_recv` of 0 or UNDEF_TIMESTAMP only adds the gateway-to-client latency.
+func (ls *LatencyStats) Observe(tsRecv uint64, tsOut uint64, localRecv time.Time) {
+	if tsRecv != 0 && tsRecv != dbn.UNDEF_TIMESTAMP {
+		ls.exchangeToGateway = append(ls.exchangeToGateway, time.Duration(int64(tsOut)-int64(tsRecv)))
+	}
+	ls.gatewayToClient = append(ls.gatewayToClient, time.Duration(localRecv.UnixNano()-int64(tsOut)))
+}
+
+// ObserveScanner adds the latencies of the scanner's last record, received locally at `localRecv`.
+// Returns false if the record has no `ts_out`.
+func (ls *LatencyStats) ObserveScanner(scanner *dbn.DbnScanner, localRecv time.Time) bool {
+	tsOut, ok := scanner.GetLastTsOut()
+	if !ok {
+		return false
+	}
+	tsRecv, _ := scanner.GetLastTsRecv()
+	ls.Observe(tsRecv, tsOut, localRecv)
+	return true
+}
+
+// Count returns the number of observed records.
+func (ls *LatencyStats) Count() int {
+	return len(ls.gatewayToClient)
+}
+
+// ExchangeToGateway returns the percentiles of `ts_out - ts_recv`.
+func (ls *LatencyStats) ExchangeToGateway() LatencyPercentiles {
+	return computeLatencyPercentiles(ls.exchangeToGateway)
+}
+
+// GatewayToClient returns the percentiles of the local receive time minus `ts_out`.
+func (ls *LatencyStats) GatewayToClient() LatencyPercentiles {
+	return computeLatencyPercentiles(ls.gatewayToClient)
+}
+
+// Reset discards all the samples.
+func (ls *LatencyStats) Reset() {
+	ls.exchangeToGateway = ls.exchangeToGateway[:0]
+	ls.gatewayToClient = ls.gatewayToClient[:0]
+}
+
+// computeLatencyPercentiles returns the nearest-rank percentiles of the samples, sorting them in place.
+func computeLatencyPercentiles(samples []time.Duration) LatencyPercentiles {
+	if len(samples) == 0 {
+		return LatencyPercentiles{}
+	}
+	slices.Sort(samples)
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(samples)))) - 1
+		return samples[min(max(i, 0), len(samples)-1)]
+	}
+	return LatencyPercentiles{
+		Count: len(samples),
+		Min:   samples[0],
+		P50:   rank(0.50),
+		P90:   rank(0.90),
+		P99:   rank(0.99),
+		P999:  rank(0.999),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// String returns the percentiles on one line, such as "n=1000 min=1ms p50=2ms ...".
+func (p LatencyPercentiles) String() string {
+	return fmt.Sprintf("n=%d min=%s p50=%s p90=%s p99=%s p99.9=%s max=%s",
+		p.Count, p.Min, p.P50, p.P90, p.P99, p.P999, p.Max)
+}
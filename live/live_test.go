@@ -350,6 +350,36 @@ var _ = Describe("DbnLive", func() {
 			Expect(record.Header.InstrumentID).To(Equal(uint32(5482)))
 		})
 	})
+	Context("latency", func() {
+		It("should compute nearest-rank percentiles", func() {
+			stats := NewLatencyStats()
+			localRecv := time.Unix(0, 2_000_000_000)
+			for i := 1; i <= 100; i++ {
+				// ts_recv i ms before ts_out, which is 2i ms before localRecv
+				tsOut := uint64(localRecv.UnixNano()) - uint64(2*i)*1_000_000
+				stats.Observe(tsOut-uint64(i)*1_000_000, tsOut, localRecv)
+			}
+			stats.Observe(dbn.UNDEF_TIMESTAMP, uint64(localRecv.UnixNano()), localRecv)
+			Expect(stats.Count()).To(Equal(101))
+
+			exchange := stats.ExchangeToGateway()
+			Expect(exchange.Count).To(Equal(100))
+			Expect(exchange.Min).To(Equal(1 * time.Millisecond))
+			Expect(exchange.P50).To(Equal(50 * time.Millisecond))
+			Expect(exchange.P99).To(Equal(99 * time.Millisecond))
+			Expect(exchange.Max).To(Equal(100 * time.Millisecond))
+
+			gateway := stats.GatewayToClient()
+			Expect(gateway.Count).To(Equal(101))
+			Expect(gateway.Min).To(Equal(time.Duration(0)))
+			Expect(gateway.Max).To(Equal(200 * time.Millisecond))
+			Expect(gateway.String()).To(HavePrefix("n=101 min=0s"))
+
+			stats.Reset()
+			Expect(stats.Count()).To(Equal(0))
+			Expect(stats.GatewayToClient()).To(Equal(LatencyPercentiles{}))
+		})
+	})
 })
 
 type scriptedConn struct {
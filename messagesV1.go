@@ -40,6 +40,10 @@ func (*SymbolMappingMsgV1) RSize() uint16 {
 	return SymbolMappingMsgV1_Size
 }
 
+func (r SymbolMappingMsgV1) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *SymbolMappingMsgV1) Fill_Raw(b []byte) error {
 	rsize := r.RSize()
 	if len(b) < int(rsize) {
@@ -61,13 +65,144 @@ func (r *SymbolMappingMsgV1) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *SymbolMappingMsgV1) Encode_Raw(b []byte) error {
+	rsize := r.RSize()
+	if len(b) < int(rsize) {
+		return unexpectedBytesError(len(b), int(rsize))
+	}
+	clear(b[:rsize])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(rsize / 4)
+	body := b[RHeader_Size:]
+	// V1 has no StypeIn/StypeOut bytes, just the two symbols
+	encodeCStr(body[0:MetadataV1_SymbolCstrLen], r.StypeInSymbol)
+	encodeCStr(body[MetadataV1_SymbolCstrLen:2*MetadataV1_SymbolCstrLen], r.StypeOutSymbol)
+	pos := 2 * MetadataV1_SymbolCstrLen
+	binary.LittleEndian.PutUint64(body[pos:pos+8], r.StartTs)
+	binary.LittleEndian.PutUint64(body[pos+8:pos+16], r.EndTs)
+	return nil
+}
+
 func (r *SymbolMappingMsgV1) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
-	r.StypeIn = SType(val.GetUint("stype_in"))
+	r.StypeIn = fastjson_GetSType(val, "stype_in")
 	r.StypeInSymbol = string(val.GetStringBytes("stype_in_symbol"))
-	r.StypeOut = SType(val.GetUint("stype_out"))
+	r.StypeOut = fastjson_GetSType(val, "stype_out")
 	r.StypeOutSymbol = string(val.GetStringBytes("stype_out_symbol"))
-	r.StartTs = val.GetUint64("start_ts")
-	r.EndTs = val.GetUint64("end_ts")
+	r.StartTs = fastjson_GetUint64Tolerant(val, "start_ts")
+	r.EndTs = fastjson_GetUint64Tolerant(val, "end_ts")
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ErrorMsgV1 is the DBN version 1 layout of ErrorMsg, with a shorter message and no code.
+type ErrorMsgV1 struct {
+	Header RHeader                  `json:"hd" csv:"hd"`   // The common header.
+	Error  [ErrorMsgV1_ErrSize]byte `json:"err" csv:"err"` // The error message.
+}
+
+const ErrorMsgV1_ErrSize = 64
+const ErrorMsgV1_Size = RHeader_Size + ErrorMsgV1_ErrSize
+
+func (*ErrorMsgV1) RType() RType {
+	return RType_Error
+}
+
+func (*ErrorMsgV1) RSize() uint16 {
+	return ErrorMsgV1_Size
+}
+
+func (r ErrorMsgV1) GetHeader() RHeader {
+	return r.Header
+}
+
+func (r *ErrorMsgV1) Fill_Raw(b []byte) error {
+	if len(b) < ErrorMsgV1_Size {
+		return unexpectedBytesError(len(b), ErrorMsgV1_Size)
+	}
+	err := r.Header.Fill_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	copy(r.Error[:], b[RHeader_Size:ErrorMsgV1_Size])
+	return nil
+}
+
+func (r *ErrorMsgV1) Encode_Raw(b []byte) error {
+	if len(b) < ErrorMsgV1_Size {
+		return unexpectedBytesError(len(b), ErrorMsgV1_Size)
+	}
+	clear(b[:ErrorMsgV1_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(ErrorMsgV1_Size / 4)
+	copy(b[RHeader_Size:ErrorMsgV1_Size], r.Error[:])
+	return nil
+}
+
+func (r *ErrorMsgV1) Fill_Json(val *fastjson.Value, header *RHeader) error {
+	r.Header = *header
+	fastjson_GetCStr(val, "err", r.Error[:])
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SystemMsgV1 is the DBN version 1 layout of SystemMsg, with a shorter message and no code.
+type SystemMsgV1 struct {
+	Header  RHeader                   `json:"hd" csv:"hd"`   // The common header.
+	Message [SystemMsgV1_MsgSize]byte `json:"msg" csv:"msg"` // The message from the Databento Live Subscription Gateway (LSG).
+}
+
+const SystemMsgV1_MsgSize = 64
+const SystemMsgV1_Size = RHeader_Size + SystemMsgV1_MsgSize
+
+func (*SystemMsgV1) RType() RType {
+	return RType_System
+}
+
+func (*SystemMsgV1) RSize() uint16 {
+	return SystemMsgV1_Size
+}
+
+func (r SystemMsgV1) GetHeader() RHeader {
+	return r.Header
+}
+
+func (r *SystemMsgV1) Fill_Raw(b []byte) error {
+	if len(b) < SystemMsgV1_Size {
+		return unexpectedBytesError(len(b), SystemMsgV1_Size)
+	}
+	err := r.Header.Fill_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	copy(r.Message[:], b[RHeader_Size:SystemMsgV1_Size])
+	return nil
+}
+
+func (r *SystemMsgV1) Encode_Raw(b []byte) error {
+	if len(b) < SystemMsgV1_Size {
+		return unexpectedBytesError(len(b), SystemMsgV1_Size)
+	}
+	clear(b[:SystemMsgV1_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(SystemMsgV1_Size / 4)
+	copy(b[RHeader_Size:SystemMsgV1_Size], r.Message[:])
+	return nil
+}
+
+func (r *SystemMsgV1) Fill_Json(val *fastjson.Value, header *RHeader) error {
+	r.Header = *header
+	fastjson_GetCStr(val, "msg", r.Message[:])
 	return nil
 }
@@ -39,6 +39,10 @@ func (*SymbolMappingMsgV2) RSize() uint16 {
 	return SymbolMappingMsg_MinSize + (2 * MetadataV2_SymbolCstrLen) + 2
 }
 
+func (r SymbolMappingMsgV2) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *SymbolMappingMsgV2) Fill_Raw(b []byte) error {
 	rsize := r.RSize()
 	if len(b) < int(rsize) {
@@ -65,14 +69,40 @@ func (r *SymbolMappingMsgV2) Fill_Raw(b []byte) error {
 
 const SymbolMappingMsgV2_Size = RHeader_Size + 16 + (2 * MetadataV2_SymbolCstrLen) + 2
 
+func (r *SymbolMappingMsgV2) Encode_Raw(b []byte) error {
+	rsize := r.RSize()
+	if len(b) < int(rsize) {
+		return unexpectedBytesError(len(b), int(rsize))
+	}
+	clear(b[:rsize])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(rsize / 4)
+	body := b[RHeader_Size:]
+	pos := uint16(0)
+	body[pos] = uint8(r.StypeIn)
+	pos += 1
+	encodeCStr(body[pos:pos+MetadataV2_SymbolCstrLen], r.StypeInSymbol)
+	pos += MetadataV2_SymbolCstrLen
+	body[pos] = uint8(r.StypeOut)
+	pos += 1
+	encodeCStr(body[pos:pos+MetadataV2_SymbolCstrLen], r.StypeOutSymbol)
+	pos += MetadataV2_SymbolCstrLen
+	binary.LittleEndian.PutUint64(body[pos:pos+8], r.StartTs)
+	binary.LittleEndian.PutUint64(body[pos+8:pos+16], r.EndTs)
+	return nil
+}
+
 func (r *SymbolMappingMsgV2) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
-	r.StypeIn = SType(val.GetUint("stype_in"))
+	r.StypeIn = fastjson_GetSType(val, "stype_in")
 	r.StypeInSymbol = string(val.GetStringBytes("stype_in_symbol"))
-	r.StypeOut = SType(val.GetUint("stype_out"))
+	r.StypeOut = fastjson_GetSType(val, "stype_out")
 	r.StypeOutSymbol = string(val.GetStringBytes("stype_out_symbol"))
-	r.StartTs = val.GetUint64("start_ts")
-	r.EndTs = val.GetUint64("end_ts")
+	r.StartTs = fastjson_GetUint64Tolerant(val, "start_ts")
+	r.EndTs = fastjson_GetUint64Tolerant(val, "end_ts")
 	return nil
 }
 
@@ -106,6 +136,10 @@ func (*StatMsgV2) RSize() uint16 {
 	return StatMsgV2_Size
 }
 
+func (r StatMsgV2) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *StatMsgV2) Fill_Raw(b []byte) error {
 	if len(b) < StatMsgV2_Size {
 		return unexpectedBytesError(len(b), StatMsgV2_Size)
@@ -128,14 +162,38 @@ func (r *StatMsgV2) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *StatMsgV2) Encode_Raw(b []byte) error {
+	if len(b) < StatMsgV2_Size {
+		return unexpectedBytesError(len(b), StatMsgV2_Size)
+	}
+	clear(b[:StatMsgV2_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(StatMsgV2_Size / 4)
+	body := b[RHeader_Size:]
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint64(body[8:16], r.TsRef)
+	binary.LittleEndian.PutUint64(body[16:24], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[24:28], uint32(r.Quantity))
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	binary.LittleEndian.PutUint32(body[32:36], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint16(body[36:38], r.StatType)
+	binary.LittleEndian.PutUint16(body[38:40], r.ChannelID)
+	body[40] = r.UpdateAction
+	body[41] = r.StatFlags
+	return nil
+}
+
 func (r *StatMsgV2) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
 	r.TsRef = fastjson_GetUint64FromString(val, "ts_ref")
 	r.Price = fastjson_GetInt64FromString(val, "price")
-	r.Quantity = int32(val.GetUint("quantity"))
+	r.Quantity = int32(val.GetInt("quantity"))
 	r.Sequence = uint32(val.GetUint("sequence"))
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.StatType = uint16(val.GetUint("stat_type"))
 	r.ChannelID = uint16(val.GetUint("channel_id"))
 	r.UpdateAction = uint8(val.GetUint("update_action"))
@@ -221,6 +279,10 @@ func (*InstrumentDefMsgV2) RSize() uint16 {
 	return InstrumentDefMsgV2_Size
 }
 
+func (r InstrumentDefMsgV2) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *InstrumentDefMsgV2) Fill_Raw(b []byte) error {
 	if len(b) < InstrumentDefMsgV2_Size {
 		return unexpectedBytesError(len(b), InstrumentDefMsgV2_Size)
@@ -293,6 +355,80 @@ func (r *InstrumentDefMsgV2) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *InstrumentDefMsgV2) Encode_Raw(b []byte) error {
+	if len(b) < InstrumentDefMsgV2_Size {
+		return unexpectedBytesError(len(b), InstrumentDefMsgV2_Size)
+	}
+	clear(b[:InstrumentDefMsgV2_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(InstrumentDefMsgV2_Size / 4)
+	body := b[RHeader_Size:]
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(r.MinPriceIncrement))
+	binary.LittleEndian.PutUint64(body[16:24], uint64(r.DisplayFactor))
+	binary.LittleEndian.PutUint64(body[24:32], r.Expiration)
+	binary.LittleEndian.PutUint64(body[32:40], r.Activation)
+	binary.LittleEndian.PutUint64(body[40:48], uint64(r.HighLimitPrice))
+	binary.LittleEndian.PutUint64(body[48:56], uint64(r.LowLimitPrice))
+	binary.LittleEndian.PutUint64(body[56:64], uint64(r.MaxPriceVariation))
+	binary.LittleEndian.PutUint64(body[64:72], uint64(r.TradingReferencePrice))
+	binary.LittleEndian.PutUint64(body[72:80], uint64(r.UnitOfMeasureQty))
+	binary.LittleEndian.PutUint64(body[80:88], uint64(r.MinPriceIncrementAmount))
+	binary.LittleEndian.PutUint64(body[88:96], uint64(r.PriceRatio))
+	binary.LittleEndian.PutUint64(body[96:104], uint64(r.StrikePrice))
+	binary.LittleEndian.PutUint32(body[104:108], uint32(r.InstAttribValue))
+	binary.LittleEndian.PutUint32(body[108:112], r.UnderlyingID)
+	binary.LittleEndian.PutUint32(body[112:116], r.RawInstrumentID)
+	binary.LittleEndian.PutUint32(body[116:120], uint32(r.MarketDepthImplied))
+	binary.LittleEndian.PutUint32(body[120:124], uint32(r.MarketDepth))
+	binary.LittleEndian.PutUint32(body[124:128], r.MarketSegmentID)
+	binary.LittleEndian.PutUint32(body[128:132], r.MaxTradeVol)
+	binary.LittleEndian.PutUint32(body[132:136], uint32(r.MinLotSize))
+	binary.LittleEndian.PutUint32(body[136:140], uint32(r.MinLotSizeBlock))
+	binary.LittleEndian.PutUint32(body[140:144], uint32(r.MinLotSizeRoundLot))
+	binary.LittleEndian.PutUint32(body[144:148], r.MinTradeVol)
+	binary.LittleEndian.PutUint32(body[148:152], uint32(r.ContractMultiplier))
+	binary.LittleEndian.PutUint32(body[152:156], uint32(r.DecayQuantity))
+	binary.LittleEndian.PutUint32(body[156:160], uint32(r.OriginalContractSize))
+	binary.LittleEndian.PutUint16(body[160:162], r.TradingReferenceDate)
+	binary.LittleEndian.PutUint16(body[162:164], uint16(r.ApplID))
+	binary.LittleEndian.PutUint16(body[164:166], r.MaturityYear)
+	binary.LittleEndian.PutUint16(body[166:168], r.DecayStartDate)
+	binary.LittleEndian.PutUint16(body[168:170], r.ChannelID)
+	copy(body[170:174], r.Currency[:])
+	copy(body[174:178], r.SettlCurrency[:])
+	copy(body[178:184], r.Secsubtype[:])
+	copy(body[184:184+MetadataV2_SymbolCstrLen], r.RawSymbol[:])
+	copy(body[255:276], r.Group[:])
+	copy(body[276:281], r.Exchange[:])
+	copy(body[281:281+MetadataV2_AssetCStrLen], r.Asset[:])
+	copy(body[288:295], r.Cfi[:])
+	copy(body[295:302], r.SecurityType[:])
+	copy(body[302:333], r.UnitOfMeasure[:])
+	copy(body[333:354], r.Underlying[:])
+	copy(body[354:358], r.StrikePriceCurrency[:])
+	body[358] = r.InstrumentClass
+	body[359] = r.MatchAlgorithm
+	body[360] = r.MdSecurityTradingStatus
+	body[361] = r.MainFraction
+	body[362] = r.PriceDisplayFormat
+	body[363] = r.SettlPrice_type
+	body[364] = r.SubFraction
+	body[365] = r.UnderlyingProduct
+	body[366] = r.SecurityUpdateAction
+	body[367] = r.MaturityMonth
+	body[368] = r.MaturityDay
+	body[369] = r.MaturityWeek
+	body[370] = byte(r.UserDefinedInstrument)
+	body[371] = byte(r.ContractMultiplierUnit)
+	body[372] = byte(r.FlowScheduleType)
+	body[373] = r.TickRule
+	return nil
+}
+
 func (r *InstrumentDefMsgV2) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -327,33 +463,33 @@ func (r *InstrumentDefMsgV2) Fill_Json(val *fastjson.Value, header *RHeader) err
 	r.MaturityYear = uint16(val.GetUint("maturity_year"))
 	r.DecayStartDate = uint16(val.GetUint("decay_start_date"))
 	r.ChannelID = uint16(val.GetUint("channel_id"))
-	copy(r.Currency[:], val.GetStringBytes("currency"))
-	copy(r.SettlCurrency[:], val.GetStringBytes("settl_currency"))
-	copy(r.Secsubtype[:], val.GetStringBytes("secsubtype"))
-	copy(r.RawSymbol[:], val.GetStringBytes("raw_symbol"))
-	copy(r.Group[:], val.GetStringBytes("group"))
-	copy(r.Exchange[:], val.GetStringBytes("exchange"))
-	copy(r.Asset[:], val.GetStringBytes("asset"))
-	copy(r.Cfi[:], val.GetStringBytes("cfi"))
-	copy(r.SecurityType[:], val.GetStringBytes("security_type"))
-	copy(r.UnitOfMeasure[:], val.GetStringBytes("unit_of_measure"))
-	copy(r.Underlying[:], val.GetStringBytes("underlying"))
-	copy(r.StrikePriceCurrency[:], val.GetStringBytes("strike_price_currency"))
-	r.InstrumentClass = byte(val.GetUint("instrument_class"))
-	r.MatchAlgorithm = byte(val.GetUint("match_algorithm"))
+	fastjson_GetCStr(val, "currency", r.Currency[:])
+	fastjson_GetCStr(val, "settl_currency", r.SettlCurrency[:])
+	fastjson_GetCStr(val, "secsubtype", r.Secsubtype[:])
+	fastjson_GetCStr(val, "raw_symbol", r.RawSymbol[:])
+	fastjson_GetCStr(val, "group", r.Group[:])
+	fastjson_GetCStr(val, "exchange", r.Exchange[:])
+	fastjson_GetCStr(val, "asset", r.Asset[:])
+	fastjson_GetCStr(val, "cfi", r.Cfi[:])
+	fastjson_GetCStr(val, "security_type", r.SecurityType[:])
+	fastjson_GetCStr(val, "unit_of_measure", r.UnitOfMeasure[:])
+	fastjson_GetCStr(val, "underlying", r.Underlying[:])
+	fastjson_GetCStr(val, "strike_price_currency", r.StrikePriceCurrency[:])
+	r.InstrumentClass = fastjson_GetChar(val, "instrument_class")
+	r.MatchAlgorithm = fastjson_GetChar(val, "match_algorithm")
 	r.MdSecurityTradingStatus = uint8(val.GetUint("md_security_trading_status"))
 	r.MainFraction = uint8(val.GetUint("main_fraction"))
 	r.PriceDisplayFormat = uint8(val.GetUint("price_display_format"))
 	r.SettlPrice_type = uint8(val.GetUint("settl_price_type"))
 	r.SubFraction = uint8(val.GetUint("sub_fraction"))
 	r.UnderlyingProduct = uint8(val.GetUint("underlying_product"))
-	r.SecurityUpdateAction = byte(val.GetUint("security_update_action"))
+	r.SecurityUpdateAction = fastjson_GetChar(val, "security_update_action")
 	r.MaturityMonth = uint8(val.GetUint("maturity_month"))
 	r.MaturityDay = uint8(val.GetUint("maturity_day"))
 	r.MaturityWeek = uint8(val.GetUint("maturity_week"))
-	r.UserDefinedInstrument = UserDefinedInstrument(val.GetUint("user_defined_instrument"))
-	r.ContractMultiplierUnit = int8(val.GetUint("contract_multiplier_unit"))
-	r.FlowScheduleType = int8(val.GetUint("flow_schedule_type"))
+	r.UserDefinedInstrument = UserDefinedInstrument(fastjson_GetChar(val, "user_defined_instrument"))
+	r.ContractMultiplierUnit = int8(val.GetInt("contract_multiplier_unit"))
+	r.FlowScheduleType = int8(val.GetInt("flow_schedule_type"))
 	r.TickRule = uint8(val.GetUint("tick_rule"))
 	return nil
 }
@@ -47,6 +47,10 @@ func (*StatMsgV3) RSize() uint16 {
 	return StatMsgV3_Size
 }
 
+func (r StatMsgV3) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *StatMsgV3) Fill_Raw(b []byte) error {
 	if len(b) < StatMsgV3_Size {
 		return unexpectedBytesError(len(b), StatMsgV3_Size)
@@ -69,6 +73,30 @@ func (r *StatMsgV3) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *StatMsgV3) Encode_Raw(b []byte) error {
+	if len(b) < StatMsgV3_Size {
+		return unexpectedBytesError(len(b), StatMsgV3_Size)
+	}
+	clear(b[:StatMsgV3_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(StatMsgV3_Size / 4)
+	body := b[RHeader_Size:]
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint64(body[8:16], r.TsRef)
+	binary.LittleEndian.PutUint64(body[16:24], uint64(r.Price))
+	binary.LittleEndian.PutUint64(body[24:32], uint64(r.Quantity))
+	binary.LittleEndian.PutUint32(body[32:36], r.Sequence)
+	binary.LittleEndian.PutUint32(body[36:40], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint16(body[40:42], r.StatType)
+	binary.LittleEndian.PutUint16(body[42:44], r.ChannelID)
+	body[44] = r.UpdateAction
+	body[45] = r.StatFlags
+	return nil
+}
+
 func (r *StatMsgV3) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -76,7 +104,7 @@ func (r *StatMsgV3) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Quantity = fastjson_GetInt64Tolerant(val, "quantity") // V2=number, V3=quoted string
 	r.Sequence = uint32(val.GetUint("sequence"))
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.StatType = uint16(val.GetUint("stat_type"))
 	r.ChannelID = uint16(val.GetUint("channel_id"))
 	r.UpdateAction = uint8(val.GetUint("update_action"))
@@ -171,6 +199,10 @@ func (*InstrumentDefMsgV3) RSize() uint16 {
 	return InstrumentDefMsgV3_Size
 }
 
+func (r InstrumentDefMsgV3) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *InstrumentDefMsgV3) Fill_Raw(b []byte) error {
 	if len(b) < InstrumentDefMsgV3_Size {
 		return unexpectedBytesError(len(b), InstrumentDefMsgV3_Size)
@@ -252,6 +284,89 @@ func (r *InstrumentDefMsgV3) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *InstrumentDefMsgV3) Encode_Raw(b []byte) error {
+	if len(b) < InstrumentDefMsgV3_Size {
+		return unexpectedBytesError(len(b), InstrumentDefMsgV3_Size)
+	}
+	clear(b[:InstrumentDefMsgV3_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(InstrumentDefMsgV3_Size / 4)
+	body := b[RHeader_Size:]
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(r.MinPriceIncrement))
+	binary.LittleEndian.PutUint64(body[16:24], uint64(r.DisplayFactor))
+	binary.LittleEndian.PutUint64(body[24:32], r.Expiration)
+	binary.LittleEndian.PutUint64(body[32:40], r.Activation)
+	binary.LittleEndian.PutUint64(body[40:48], uint64(r.HighLimitPrice))
+	binary.LittleEndian.PutUint64(body[48:56], uint64(r.LowLimitPrice))
+	binary.LittleEndian.PutUint64(body[56:64], uint64(r.MaxPriceVariation))
+	binary.LittleEndian.PutUint64(body[64:72], uint64(r.UnitOfMeasureQty))
+	binary.LittleEndian.PutUint64(body[72:80], uint64(r.MinPriceIncrementAmount))
+	binary.LittleEndian.PutUint64(body[80:88], uint64(r.PriceRatio))
+	binary.LittleEndian.PutUint64(body[88:96], uint64(r.StrikePrice))
+	binary.LittleEndian.PutUint64(body[96:104], r.RawInstrumentID)
+	binary.LittleEndian.PutUint64(body[104:112], uint64(r.LegPrice))
+	binary.LittleEndian.PutUint64(body[112:120], uint64(r.LegDelta))
+	binary.LittleEndian.PutUint32(body[120:124], uint32(r.InstAttribValue))
+	binary.LittleEndian.PutUint32(body[124:128], r.UnderlyingID)
+	binary.LittleEndian.PutUint32(body[128:132], uint32(r.MarketDepthImplied))
+	binary.LittleEndian.PutUint32(body[132:136], uint32(r.MarketDepth))
+	binary.LittleEndian.PutUint32(body[136:140], r.MarketSegmentID)
+	binary.LittleEndian.PutUint32(body[140:144], r.MaxTradeVol)
+	binary.LittleEndian.PutUint32(body[144:148], uint32(r.MinLotSize))
+	binary.LittleEndian.PutUint32(body[148:152], uint32(r.MinLotSizeBlock))
+	binary.LittleEndian.PutUint32(body[152:156], uint32(r.MinLotSizeRoundLot))
+	binary.LittleEndian.PutUint32(body[156:160], r.MinTradeVol)
+	binary.LittleEndian.PutUint32(body[160:164], uint32(r.ContractMultiplier))
+	binary.LittleEndian.PutUint32(body[164:168], uint32(r.DecayQuantity))
+	binary.LittleEndian.PutUint32(body[168:172], uint32(r.OriginalContractSize))
+	binary.LittleEndian.PutUint32(body[172:176], r.LegInstrumentID)
+	binary.LittleEndian.PutUint32(body[176:180], uint32(r.LegRatioPriceNumerator))
+	binary.LittleEndian.PutUint32(body[180:184], uint32(r.LegRatioPriceDenominator))
+	binary.LittleEndian.PutUint32(body[184:188], uint32(r.LegRatioQtyNumerator))
+	binary.LittleEndian.PutUint32(body[188:192], uint32(r.LegRatioQtyDenominator))
+	binary.LittleEndian.PutUint32(body[192:196], r.LegUnderlyingID)
+	binary.LittleEndian.PutUint16(body[196:198], uint16(r.ApplID))
+	binary.LittleEndian.PutUint16(body[198:200], r.MaturityYear)
+	binary.LittleEndian.PutUint16(body[200:202], r.DecayStartDate)
+	binary.LittleEndian.PutUint16(body[202:204], r.ChannelID)
+	binary.LittleEndian.PutUint16(body[204:206], r.LegCount)
+	binary.LittleEndian.PutUint16(body[206:208], r.LegIndex)
+	copy(body[208:212], r.Currency[:])
+	copy(body[212:216], r.SettlCurrency[:])
+	copy(body[216:222], r.Secsubtype[:])
+	copy(body[222:222+MetadataV3_SymbolCstrLen], r.RawSymbol[:])
+	copy(body[293:314], r.Group[:])
+	copy(body[314:319], r.Exchange[:])
+	copy(body[319:319+MetadataV3_AssetCStrLen], r.Asset[:])
+	copy(body[330:337], r.Cfi[:])
+	copy(body[337:344], r.SecurityType[:])
+	copy(body[344:375], r.UnitOfMeasure[:])
+	copy(body[375:396], r.Underlying[:])
+	copy(body[396:400], r.StrikePriceCurrency[:])
+	copy(body[400:400+MetadataV3_SymbolCstrLen], r.LegRawSymbol[:])
+	body[471] = r.InstrumentClass
+	body[472] = r.MatchAlgorithm
+	body[473] = r.MainFraction
+	body[474] = r.PriceDisplayFormat
+	body[475] = r.SubFraction
+	body[476] = r.UnderlyingProduct
+	body[477] = r.SecurityUpdateAction
+	body[478] = r.MaturityMonth
+	body[479] = r.MaturityDay
+	body[480] = r.MaturityWeek
+	body[481] = byte(r.UserDefinedInstrument)
+	body[482] = byte(r.ContractMultiplierUnit)
+	body[483] = byte(r.FlowScheduleType)
+	body[484] = r.TickRule
+	body[485] = r.LegInstrumentClass
+	body[486] = r.LegSide
+	return nil
+}
+
 func (r *InstrumentDefMsgV3) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -294,34 +409,34 @@ func (r *InstrumentDefMsgV3) Fill_Json(val *fastjson.Value, header *RHeader) err
 	r.ChannelID = uint16(val.GetUint("channel_id"))
 	r.LegCount = uint16(val.GetUint("leg_count"))
 	r.LegIndex = uint16(val.GetUint("leg_index"))
-	copy(r.Currency[:], val.GetStringBytes("currency"))
-	copy(r.SettlCurrency[:], val.GetStringBytes("settl_currency"))
-	copy(r.Secsubtype[:], val.GetStringBytes("secsubtype"))
-	copy(r.RawSymbol[:], val.GetStringBytes("raw_symbol"))
-	copy(r.Group[:], val.GetStringBytes("group"))
-	copy(r.Exchange[:], val.GetStringBytes("exchange"))
-	copy(r.Asset[:], val.GetStringBytes("asset"))
-	copy(r.Cfi[:], val.GetStringBytes("cfi"))
-	copy(r.SecurityType[:], val.GetStringBytes("security_type"))
-	copy(r.UnitOfMeasure[:], val.GetStringBytes("unit_of_measure"))
-	copy(r.Underlying[:], val.GetStringBytes("underlying"))
-	copy(r.StrikePriceCurrency[:], val.GetStringBytes("strike_price_currency"))
-	copy(r.LegRawSymbol[:], val.GetStringBytes("leg_raw_symbol"))
-	r.InstrumentClass = byte(val.GetUint("instrument_class"))
-	r.MatchAlgorithm = byte(val.GetUint("match_algorithm"))
+	fastjson_GetCStr(val, "currency", r.Currency[:])
+	fastjson_GetCStr(val, "settl_currency", r.SettlCurrency[:])
+	fastjson_GetCStr(val, "secsubtype", r.Secsubtype[:])
+	fastjson_GetCStr(val, "raw_symbol", r.RawSymbol[:])
+	fastjson_GetCStr(val, "group", r.Group[:])
+	fastjson_GetCStr(val, "exchange", r.Exchange[:])
+	fastjson_GetCStr(val, "asset", r.Asset[:])
+	fastjson_GetCStr(val, "cfi", r.Cfi[:])
+	fastjson_GetCStr(val, "security_type", r.SecurityType[:])
+	fastjson_GetCStr(val, "unit_of_measure", r.UnitOfMeasure[:])
+	fastjson_GetCStr(val, "underlying", r.Underlying[:])
+	fastjson_GetCStr(val, "strike_price_currency", r.StrikePriceCurrency[:])
+	fastjson_GetCStr(val, "leg_raw_symbol", r.LegRawSymbol[:])
+	r.InstrumentClass = fastjson_GetChar(val, "instrument_class")
+	r.MatchAlgorithm = fastjson_GetChar(val, "match_algorithm")
 	r.MainFraction = uint8(val.GetUint("main_fraction"))
 	r.PriceDisplayFormat = uint8(val.GetUint("price_display_format"))
 	r.SubFraction = uint8(val.GetUint("sub_fraction"))
 	r.UnderlyingProduct = uint8(val.GetUint("underlying_product"))
-	r.SecurityUpdateAction = byte(val.GetUint("security_update_action"))
+	r.SecurityUpdateAction = fastjson_GetChar(val, "security_update_action")
 	r.MaturityMonth = uint8(val.GetUint("maturity_month"))
 	r.MaturityDay = uint8(val.GetUint("maturity_day"))
 	r.MaturityWeek = uint8(val.GetUint("maturity_week"))
-	r.UserDefinedInstrument = UserDefinedInstrument(val.GetUint("user_defined_instrument"))
-	r.ContractMultiplierUnit = int8(val.GetUint("contract_multiplier_unit"))
-	r.FlowScheduleType = int8(val.GetUint("flow_schedule_type"))
+	r.UserDefinedInstrument = UserDefinedInstrument(fastjson_GetChar(val, "user_defined_instrument"))
+	r.ContractMultiplierUnit = int8(val.GetInt("contract_multiplier_unit"))
+	r.FlowScheduleType = int8(val.GetInt("flow_schedule_type"))
 	r.TickRule = uint8(val.GetUint("tick_rule"))
-	r.LegInstrumentClass = uint8(val.GetUint("leg_instrument_class"))
-	r.LegSide = uint8(val.GetUint("leg_side"))
+	r.LegInstrumentClass = fastjson_GetChar(val, "leg_instrument_class")
+	r.LegSide = fastjson_GetChar(val, "leg_side")
 	return nil
 }
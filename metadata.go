@@ -7,6 +7,10 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
 )
 
 const (
@@ -176,9 +180,15 @@ func (m *Metadata) writeV2(writer io.Writer) error {
 	}
 	metaLength += (numIntervals * (4 + 4 + cstrLen)) // start + end + symbol
 
+	// V2 and V3 share the same metadata layout, so preserve the version
+	version := uint8(HeaderVersion2)
+	if m.VersionNum == HeaderVersion3 {
+		version = HeaderVersion3
+	}
+
 	// Write the MetadataPrefix
 	if err := binary.Write(writer, binary.LittleEndian, MetadataPrefix{
-		VersionRaw: [4]byte{'D', 'B', 'N', 2},
+		VersionRaw: [4]byte{'D', 'B', 'N', version},
 		Length:     uint32(metaLength),
 	}); err != nil {
 		return err
@@ -464,6 +474,124 @@ func readMetadataV3(b []byte, mp MetadataPrefix) (*Metadata, error) {
 
 ///////////////////////////////////////////////////////////////////////////////
 
+// ReadJsonMetadata reads Metadata from a Databento-style JSON document over an io.Reader,
+// such as the one emitted by `dbn --json --metadata`.
+func ReadJsonMetadata(r io.Reader) (*Metadata, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var p fastjson.Parser
+	val, err := p.ParseBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	var m Metadata
+	if err := m.Fill_Json(val); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Fill_Json fills the Metadata from a Databento-style JSON value.
+// The Go field names output by `json.Marshal(metadata)` are also accepted.
+// Timestamps may be quoted or bare numbers; dates may be YYYYMMDD numbers or "YYYY-MM-DD" strings.
+func (m *Metadata) Fill_Json(val *fastjson.Value) error {
+	var err error
+	m.VersionNum = uint8(val.GetUint(fastjson_Key(val, "version", "VersionNum")))
+	if m.VersionNum == 0 {
+		m.VersionNum = HeaderVersion3
+	}
+	m.Dataset = string(val.GetStringBytes(fastjson_Key(val, "dataset", "Dataset")))
+	if schema := val.GetStringBytes(fastjson_Key(val, "schema", "Schema")); len(schema) != 0 {
+		if m.Schema, err = SchemaFromString(string(schema)); err != nil {
+			return err
+		}
+	} else {
+		m.Schema = Schema_Mixed
+	}
+	m.Start = fastjson_GetUint64Tolerant(val, fastjson_Key(val, "start", "Start"))
+	endKey := fastjson_Key(val, "end", "End")
+	m.End = fastjson_GetUint64Tolerant(val, endKey)
+	if end := val.Get(endKey); end == nil || end.Type() == fastjson.TypeNull {
+		m.End = UNDEF_TIMESTAMP
+	}
+	m.Limit = fastjson_GetUint64Tolerant(val, fastjson_Key(val, "limit", "Limit"))
+	m.StypeIn = fastjson_GetSType(val, fastjson_Key(val, "stype_in", "StypeIn"))
+	m.StypeOut = fastjson_GetSType(val, fastjson_Key(val, "stype_out", "StypeOut"))
+	tsOutKey := fastjson_Key(val, "ts_out", "TsOut")
+	if val.GetBool(tsOutKey) || val.GetUint(tsOutKey) != 0 {
+		m.TsOut = 1
+	}
+	m.SymbolCstrLen = uint16(val.GetUint(fastjson_Key(val, "symbol_cstr_len", "SymbolCstrLen")))
+	if m.SymbolCstrLen == 0 {
+		if m.VersionNum == HeaderVersion1 {
+			m.SymbolCstrLen = MetadataV1_SymbolCstrLen
+		} else {
+			m.SymbolCstrLen = MetadataV2_SymbolCstrLen
+		}
+	}
+	m.Symbols = fastjson_GetStringArray(val, fastjson_Key(val, "symbols", "Symbols"))
+	m.Partial = fastjson_GetStringArray(val, fastjson_Key(val, "partial", "Partial"))
+	m.NotFound = fastjson_GetStringArray(val, fastjson_Key(val, "not_found", "NotFound"))
+	m.Mappings = nil
+	for _, mval := range val.GetArray(fastjson_Key(val, "mappings", "Mappings")) {
+		mapping := SymbolMapping{
+			RawSymbol: string(mval.GetStringBytes(fastjson_Key(mval, "raw_symbol", "RawSymbol"))),
+		}
+		for _, ival := range mval.GetArray(fastjson_Key(mval, "intervals", "Intervals")) {
+			interval := MappingInterval{Symbol: string(ival.GetStringBytes(fastjson_Key(ival, "symbol", "Symbol")))}
+			if interval.StartDate, err = fastjson_GetYMD(ival, fastjson_Key(ival, "start_date", "StartDate")); err != nil {
+				return err
+			}
+			if interval.EndDate, err = fastjson_GetYMD(ival, fastjson_Key(ival, "end_date", "EndDate")); err != nil {
+				return err
+			}
+			mapping.Intervals = append(mapping.Intervals, interval)
+		}
+		m.Mappings = append(m.Mappings, mapping)
+	}
+	return nil
+}
+
+// Returns `alt` if it exists in the fastjson.Value, otherwise `key`.
+func fastjson_Key(val *fastjson.Value, key string, alt string) string {
+	if val.Exists(alt) {
+		return alt
+	}
+	return key
+}
+
+// Decodes a fastjson.Value array of strings
+func fastjson_GetStringArray(val *fastjson.Value, key string) []string {
+	arr := val.GetArray(key)
+	strs := make([]string, 0, len(arr))
+	for _, v := range arr {
+		strs = append(strs, string(v.GetStringBytes()))
+	}
+	return strs
+}
+
+// Decodes a fastjson.Value date as YYYYMMDD, tolerant of numbers (20240102)
+// and strings ("20240102" or "2024-01-02").
+func fastjson_GetYMD(val *fastjson.Value, key string) (uint32, error) {
+	v := val.Get(key)
+	if v == nil {
+		return 0, nil
+	}
+	if v.Type() != fastjson.TypeString {
+		return uint32(v.GetUint()), nil
+	}
+	str := strings.ReplaceAll(string(v.GetStringBytes()), "-", "")
+	ymd, err := strconv.ParseUint(str, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad date for '%s': %w", key, err)
+	}
+	return uint32(ymd), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
 // Decode a fixed-witdth string arrays from the Reader.
 // Returns number of bytes read and any error.
 func decodeToStringArray(r io.Reader, cstrLength uint16, strArray *[]string) error {
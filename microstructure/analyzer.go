@@ -0,0 +1,351 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_microstructure
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// Options controls the metrics of an Analyzer.
+type Options struct {
+	Horizons []time.Duration // Horizons of each trade's realized spread and price impact, such as 1s and 5s
+	Interval time.Duration   // Length of IntervalMetrics, such as 1m; none if 0
+}
+
+// HorizonMetrics are a trade's realized spread and price impact at a horizon after it.
+// They are NaN if there was no quote at or after the horizon, or the trade's Sign is unknown.
+type HorizonMetrics struct {
+	Horizon        time.Duration
+	Midpoint       float64 // Midpoint of the first quote at or after the horizon
+	RealizedSpread float64 // 2 * sign * (price - Midpoint)
+	PriceImpact    float64 // 2 * sign * (Midpoint - the trade's midpoint)
+}
+
+// TradeMetrics are the classification and costs of a trade, against the best bid and offer before it.
+type TradeMetrics struct {
+	InstrumentID       uint32
+	PublisherID        uint16
+	Ts                 uint64 // The trade's `ts_recv`
+	Price              int64
+	Size               uint32
+	Side               dbn.Side // The aggressor side reported by the venue
+	BidPx              int64
+	AskPx              int64
+	BidSz              uint32
+	AskSz              uint32
+	Midpoint           float64 // Midpoint of the bid and offer, or NaN if not two-sided
+	TickSign           Sign    // Sign by the tick rule
+	LeeReadySign       Sign    // Sign by the Lee-Ready algorithm
+	EffectiveSpread    float64 // 2 * LeeReadySign * (price - Midpoint), or NaN
+	EffectiveSpreadBps float64 // EffectiveSpread relative to Midpoint, in basis points, or NaN
+	Horizons           []HorizonMetrics
+}
+
+// IntervalMetrics aggregate the trades and quotes of an instrument over an interval.
+type IntervalMetrics struct {
+	InstrumentID       uint32
+	Start              uint64 // Start of the interval, a multiple of Interval since the UNIX epoch
+	Interval           time.Duration
+	NumTrades          int
+	Volume             uint64
+	BuyVolume          uint64  // Volume of trades whose LeeReadySign is Sign_Buy
+	SellVolume         uint64  // Volume of trades whose LeeReadySign is Sign_Sell
+	Vwap               float64 // Volume-weighted average price, or NaN without trades
+	Twap               float64 // Time-weighted average of the last trade price, from the interval's start or first trade to its end, or NaN
+	OrderFlowImbalance int64   // Sum of the OrderFlowImbalance of successive quotes
+	EffectiveSpreadBps float64 // Volume-weighted average EffectiveSpreadBps, or NaN
+}
+
+// TradeImbalance returns the buy volume less the sell volume, as a fraction of their sum,
+// from -1 to 1.  Returns NaN if there are neither.
+func (m *IntervalMetrics) TradeImbalance() float64 {
+	total := m.BuyVolume + m.SellVolume
+	if total == 0 {
+		return math.NaN()
+	}
+	return (float64(m.BuyVolume) - float64(m.SellVolume)) / float64(total)
+}
+
+// instrumentState is an Analyzer's state of an instrument.
+type instrumentState struct {
+	quote     dbn.BidAskPair
+	hasQuote  bool
+	lastPrice int64 // last trade price, or UNDEF_PRICE
+	lastSign  Sign  // last tick rule Sign
+	pending   []*TradeMetrics
+
+	interval   *IntervalMetrics
+	notional   float64 // sum of price * size in the interval
+	spreadSum  float64 // sum of EffectiveSpreadBps * size in the interval
+	spreadSize uint64  // size of trades with an EffectiveSpreadBps in the interval
+	twapSum    float64 // sum of price * nanoseconds in the interval
+	twapNanos  uint64
+	twapFrom   uint64 // when the last trade price began to count toward the TWAP
+}
+
+// Analyzer computes TradeMetrics and IntervalMetrics from Mbp1Msg or Cmbp1Msg records, as in
+// the TBBO and TCBBO schemas, whose trades carry the best bid and offer before them.  Records
+// which are not trades, as in MBP-1 and CMBP-1, update the quote.  It is a Visitor, and records
+// of each instrument must be visited in `ts_recv` order.
+//
+// TradeMetrics are passed to a handler once all their horizons have passed, in order per instrument,
+// and IntervalMetrics once a later record begins the next one.  Flush passes the rest at the end.
+type Analyzer struct {
+	dbn.NullVisitor
+	opts        Options
+	onTrade     func(trade *TradeMetrics) error
+	onInterval  func(interval *IntervalMetrics) error
+	instruments map[uint32]*instrumentState
+}
+
+// NewAnalyzer returns an Analyzer which passes TradeMetrics to `onTrade` and IntervalMetrics to
+// `onInterval`, each if not nil.  Returns an error if a horizon or the interval is negative.
+func NewAnalyzer(opts Options, onTrade func(trade *TradeMetrics) error, onInterval func(interval *IntervalMetrics) error) (*Analyzer, error) {
+	opts.Horizons = slices.Clone(opts.Horizons)
+	slices.Sort(opts.Horizons)
+	if len(opts.Horizons) > 0 && opts.Horizons[0] <= 0 {
+		return nil, fmt.Errorf("horizons must be positive, got %s", opts.Horizons[0])
+	}
+	if opts.Interval < 0 {
+		return nil, fmt.Errorf("interval must not be negative, got %s", opts.Interval)
+	}
+	return &Analyzer{
+		opts:        opts,
+		onTrade:     onTrade,
+		onInterval:  onInterval,
+		instruments: make(map[uint32]*instrumentState),
+	}, nil
+}
+
+// OnMbp1 updates the quote of the record's instrument, and if it is a trade, classifies it.
+func (a *Analyzer) OnMbp1(record *dbn.Mbp1Msg) error {
+	return a.update(record.Header, record.TsRecv, record.Level, record.Action, record.Price, record.Size, record.Side)
+}
+
+// OnCmbp1 updates the consolidated quote of the record's instrument, and if it is a trade, classifies it.
+func (a *Analyzer) OnCmbp1(record *dbn.Cmbp1Msg) error {
+	level := dbn.BidAskPair{
+		BidPx: record.Level.BidPx,
+		AskPx: record.Level.AskPx,
+		BidSz: record.Level.BidSz,
+		AskSz: record.Level.AskSz,
+	}
+	return a.update(record.Header, record.TsRecv, level, record.Action, record.Price, record.Size, record.Side)
+}
+
+// OnStreamEnd flushes the metrics; see Flush.
+func (a *Analyzer) OnStreamEnd() error {
+	return a.Flush()
+}
+
+// update applies a record's quote, and trade if its action is Action_Trade.
+func (a *Analyzer) update(header dbn.RHeader, ts uint64, level dbn.BidAskPair, action byte, price int64, size uint32, side byte) error {
+	state := a.instruments[header.InstrumentID]
+	if state == nil {
+		state = &instrumentState{lastPrice: dbn.UNDEF_PRICE}
+		a.instruments[header.InstrumentID] = state
+	}
+
+	// The quote is the first at or after the horizons of pending trades
+	if err := a.resolveHorizons(state, ts, Midpoint(level.BidPx, level.AskPx)); err != nil {
+		return err
+	}
+	if err := a.advanceInterval(state, header.InstrumentID, ts); err != nil {
+		return err
+	}
+	if state.hasQuote && state.interval != nil {
+		state.interval.OrderFlowImbalance += OrderFlowImbalance(state.quote, level)
+	}
+	state.quote, state.hasQuote = level, true
+	if dbn.Action(action) != dbn.Action_Trade {
+		return nil
+	}
+
+	tickSign := TickRule(price, state.lastPrice, state.lastSign)
+	trade := &TradeMetrics{
+		InstrumentID:       header.InstrumentID,
+		PublisherID:        header.PublisherID,
+		Ts:                 ts,
+		Price:              price,
+		Size:               size,
+		Side:               dbn.Side(side),
+		BidPx:              level.BidPx,
+		AskPx:              level.AskPx,
+		BidSz:              level.BidSz,
+		AskSz:              level.AskSz,
+		Midpoint:           Midpoint(level.BidPx, level.AskPx),
+		TickSign:           tickSign,
+		LeeReadySign:       LeeReady(price, level.BidPx, level.AskPx, tickSign),
+		EffectiveSpread:    math.NaN(),
+		EffectiveSpreadBps: math.NaN(),
+	}
+	if trade.LeeReadySign != Sign_Unknown && !math.IsNaN(trade.Midpoint) {
+		trade.EffectiveSpread = 2 * float64(trade.LeeReadySign) * (dbn.Fixed9ToFloat64(price) - trade.Midpoint)
+		if trade.Midpoint != 0 {
+			trade.EffectiveSpreadBps = trade.EffectiveSpread / trade.Midpoint * 1e4
+		}
+	}
+	if len(a.opts.Horizons) > 0 {
+		trade.Horizons = make([]HorizonMetrics, 0, len(a.opts.Horizons))
+	}
+	state.lastSign = tickSign
+	a.addTrade(state, trade)
+	state.lastPrice = price
+
+	if len(a.opts.Horizons) == 0 {
+		return a.emitTrade(trade)
+	}
+	state.pending = append(state.pending, trade)
+	return nil
+}
+
+// resolveHorizons sets the midpoint at each horizon of the pending trades which is at or before
+// `ts`, and emits the trades whose horizons have all passed.
+func (a *Analyzer) resolveHorizons(state *instrumentState, ts uint64, midpoint float64) error {
+	for len(state.pending) > 0 {
+		trade := state.pending[0]
+		for len(trade.Horizons) < len(a.opts.Horizons) {
+			horizon := a.opts.Horizons[len(trade.Horizons)]
+			if trade.Ts+uint64(horizon) > ts {
+				break
+			}
+			trade.Horizons = append(trade.Horizons, newHorizonMetrics(trade, horizon, midpoint))
+		}
+		if len(trade.Horizons) < len(a.opts.Horizons) {
+			// Later trades have not reached this horizon either
+			return nil
+		}
+		state.pending = state.pending[1:]
+		if err := a.emitTrade(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHorizonMetrics returns the trade's HorizonMetrics with the midpoint at the horizon.
+func newHorizonMetrics(trade *TradeMetrics, horizon time.Duration, midpoint float64) HorizonMetrics {
+	metrics := HorizonMetrics{Horizon: horizon, Midpoint: midpoint, RealizedSpread: math.NaN(), PriceImpact: math.NaN()}
+	if trade.LeeReadySign != Sign_Unknown && !math.IsNaN(midpoint) {
+		sign := float64(trade.LeeReadySign)
+		metrics.RealizedSpread = 2 * sign * (dbn.Fixed9ToFloat64(trade.Price) - midpoint)
+		if !math.IsNaN(trade.Midpoint) {
+			metrics.PriceImpact = 2 * sign * (midpoint - trade.Midpoint)
+		}
+	}
+	return metrics
+}
+
+// advanceInterval emits the instrument's interval if `ts` is past it, and begins the one of `ts`.
+func (a *Analyzer) advanceInterval(state *instrumentState, instrumentID uint32, ts uint64) error {
+	if a.opts.Interval == 0 {
+		return nil
+	}
+	interval := uint64(a.opts.Interval)
+	if state.interval != nil && ts >= state.interval.Start+interval {
+		if err := a.closeInterval(state); err != nil {
+			return err
+		}
+	}
+	if state.interval == nil {
+		start := ts - ts%interval
+		state.interval = &IntervalMetrics{InstrumentID: instrumentID, Start: start, Interval: a.opts.Interval}
+		state.notional, state.spreadSum, state.spreadSize = 0, 0, 0
+		state.twapSum, state.twapNanos, state.twapFrom = 0, 0, start
+	}
+	return nil
+}
+
+// addTrade adds the trade to the instrument's interval.
+func (a *Analyzer) addTrade(state *instrumentState, trade *TradeMetrics) {
+	metrics := state.interval
+	if metrics == nil {
+		return
+	}
+	if state.lastPrice != dbn.UNDEF_PRICE {
+		state.twapSum += dbn.Fixed9ToFloat64(state.lastPrice) * float64(trade.Ts-state.twapFrom)
+		state.twapNanos += trade.Ts - state.twapFrom
+	}
+	state.twapFrom = trade.Ts
+
+	metrics.NumTrades++
+	metrics.Volume += uint64(trade.Size)
+	switch trade.LeeReadySign {
+	case Sign_Buy:
+		metrics.BuyVolume += uint64(trade.Size)
+	case Sign_Sell:
+		metrics.SellVolume += uint64(trade.Size)
+	}
+	state.notional += dbn.Fixed9ToFloat64(trade.Price) * float64(trade.Size)
+	if !math.IsNaN(trade.EffectiveSpreadBps) {
+		state.spreadSum += trade.EffectiveSpreadBps * float64(trade.Size)
+		state.spreadSize += uint64(trade.Size)
+	}
+}
+
+// closeInterval completes the instrument's interval and emits it.
+func (a *Analyzer) closeInterval(state *instrumentState) error {
+	metrics := state.interval
+	state.interval = nil
+	end := metrics.Start + uint64(metrics.Interval)
+	if state.lastPrice != dbn.UNDEF_PRICE && end > state.twapFrom {
+		state.twapSum += dbn.Fixed9ToFloat64(state.lastPrice) * float64(end-state.twapFrom)
+		state.twapNanos += end - state.twapFrom
+	}
+	metrics.Vwap, metrics.Twap, metrics.EffectiveSpreadBps = math.NaN(), math.NaN(), math.NaN()
+	if metrics.Volume > 0 {
+		metrics.Vwap = state.notional / float64(metrics.Volume)
+	}
+	if state.twapNanos > 0 {
+		metrics.Twap = state.twapSum / float64(state.twapNanos)
+	}
+	if state.spreadSize > 0 {
+		metrics.EffectiveSpreadBps = state.spreadSum / float64(state.spreadSize)
+	}
+	if a.onInterval == nil {
+		return nil
+	}
+	return a.onInterval(metrics)
+}
+
+// emitTrade passes the trade to the handler.
+func (a *Analyzer) emitTrade(trade *TradeMetrics) error {
+	if a.onTrade == nil {
+		return nil
+	}
+	return a.onTrade(trade)
+}
+
+// Flush emits the pending trades, whose horizons after the last record have NaN metrics,
+// and the current intervals, by instrument ID.
+func (a *Analyzer) Flush() error {
+	ids := make([]uint32, 0, len(a.instruments))
+	for id := range a.instruments {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, cmp.Compare[uint32])
+	for _, id := range ids {
+		state := a.instruments[id]
+		for _, trade := range state.pending {
+			for len(trade.Horizons) < len(a.opts.Horizons) {
+				trade.Horizons = append(trade.Horizons, newHorizonMetrics(trade, a.opts.Horizons[len(trade.Horizons)], math.NaN()))
+			}
+			if err := a.emitTrade(trade); err != nil {
+				return err
+			}
+		}
+		state.pending = nil
+		if state.interval != nil {
+			if err := a.closeInterval(state); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Neomantra Corp
+
+// Package dbn_microstructure computes market microstructure metrics from trades paired with the
+// best bid and offer before them, as in the TBBO and TCBBO schemas: trade classification by the
+// tick rule and the Lee-Ready algorithm, effective and realized spreads, price impact at horizons,
+// order-flow imbalance, and per-interval VWAP and TWAP.
+package dbn_microstructure
+
+import (
+	"math"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Sign is the direction of a trade's initiator: Buy, Sell, or Unknown.
+type Sign int8
+
+const (
+	Sign_Sell    Sign = -1
+	Sign_Unknown Sign = 0
+	Sign_Buy     Sign = 1
+)
+
+// String returns the name of the Sign, such as "buy".
+func (s Sign) String() string {
+	switch s {
+	case Sign_Buy:
+		return "buy"
+	case Sign_Sell:
+		return "sell"
+	default:
+		return "unknown"
+	}
+}
+
+// TickRule classifies a trade by its price against the last trade at a different price:
+// a buy on an uptick and a sell on a downtick.  On a zero tick, it is the Sign of that last
+// price change, `lastSign`.  `lastPrice` is UNDEF_PRICE if there is no earlier trade.
+func TickRule(price int64, lastPrice int64, lastSign Sign) Sign {
+	if lastPrice == dbn.UNDEF_PRICE {
+		return Sign_Unknown
+	}
+	if price > lastPrice {
+		return Sign_Buy
+	}
+	if price < lastPrice {
+		return Sign_Sell
+	}
+	return lastSign
+}
+
+// LeeReady classifies a trade by the quote rule of Lee and Ready (1991): a buy if its price
+// is above the midpoint of the bid and offer before it and a sell if below.  At the midpoint,
+// or without a two-sided quote, it is the Sign of the tick rule, `tickSign`.
+func LeeReady(price int64, bidPx int64, askPx int64, tickSign Sign) Sign {
+	if bidPx == dbn.UNDEF_PRICE || askPx == dbn.UNDEF_PRICE {
+		return tickSign
+	}
+	// Compare 2*price to bid+ask, to stay in integers
+	twice, sum := 2*price, bidPx+askPx
+	if twice > sum {
+		return Sign_Buy
+	}
+	if twice < sum {
+		return Sign_Sell
+	}
+	return tickSign
+}
+
+// Midpoint returns the midpoint of a bid and offer as a float64 price, or NaN if either is undefined.
+func Midpoint(bidPx int64, askPx int64) float64 {
+	if bidPx == dbn.UNDEF_PRICE || askPx == dbn.UNDEF_PRICE {
+		return math.NaN()
+	}
+	return (dbn.Fixed9ToFloat64(bidPx) + dbn.Fixed9ToFloat64(askPx)) / 2
+}
+
+// OrderFlowImbalance returns the order-flow imbalance of Cont, Kukanov, and Stoikov (2014) between
+// two successive best bids and offers: the size added to the bid, less the size added to the offer.
+// A bid at a higher price adds its size and one at a lower price removes the prior size, and
+// conversely for the offer.  Returns 0 if either is not two-sided.
+func OrderFlowImbalance(prev dbn.BidAskPair, curr dbn.BidAskPair) int64 {
+	if prev.BidPx == dbn.UNDEF_PRICE || prev.AskPx == dbn.UNDEF_PRICE || curr.BidPx == dbn.UNDEF_PRICE || curr.AskPx == dbn.UNDEF_PRICE {
+		return 0
+	}
+	var ofi int64
+	if curr.BidPx >= prev.BidPx {
+		ofi += int64(curr.BidSz)
+	}
+	if curr.BidPx <= prev.BidPx {
+		ofi -= int64(prev.BidSz)
+	}
+	if curr.AskPx <= prev.AskPx {
+		ofi -= int64(curr.AskSz)
+	}
+	if curr.AskPx >= prev.AskPx {
+		ofi += int64(prev.AskSz)
+	}
+	return ofi
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_microstructure
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Test Launcher
+func TestDbnMicrostructure(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dbn-go microstructure suite")
+}
+
+// px returns a fixed-precision price in hundredths, such as px(10010) for 100.10.
+func px(hundredths int64) int64 {
+	return hundredths * 10_000_000
+}
+
+// newTestMbp1 returns an MBP-1 record of instrument 1 at `ts`, a trade if `price` is not UNDEF_PRICE.
+func newTestMbp1(ts time.Duration, price int64, size uint32, bidPx, askPx int64) *dbn.Mbp1Msg {
+	record := &dbn.Mbp1Msg{
+		Header: dbn.RHeader{RType: dbn.RType_Mbp1, PublisherID: 2, InstrumentID: 1, TsEvent: uint64(ts)},
+		Price:  bidPx,
+		Size:   10,
+		Action: uint8(dbn.Action_Add),
+		Side:   uint8(dbn.Side_Bid),
+		TsRecv: uint64(ts),
+		Level:  dbn.BidAskPair{BidPx: bidPx, AskPx: askPx, BidSz: 10, AskSz: 10},
+	}
+	if price != dbn.UNDEF_PRICE {
+		record.Price, record.Size, record.Action, record.Side = price, size, uint8(dbn.Action_Trade), uint8(dbn.Side_None)
+	}
+	return record
+}
+
+var _ = Describe("Classification", func() {
+	It("should classify by the tick rule", func() {
+		Expect(TickRule(px(100), dbn.UNDEF_PRICE, Sign_Unknown)).To(Equal(Sign_Unknown))
+		Expect(TickRule(px(101), px(100), Sign_Unknown)).To(Equal(Sign_Buy))
+		Expect(TickRule(px(99), px(100), Sign_Buy)).To(Equal(Sign_Sell))
+		Expect(TickRule(px(100), px(100), Sign_Sell)).To(Equal(Sign_Sell))
+		Expect(Sign_Buy.String()).To(Equal("buy"))
+	})
+
+	It("should classify by the Lee-Ready algorithm", func() {
+		Expect(LeeReady(px(101), px(100), px(102), Sign_Sell)).To(Equal(Sign_Sell)) // midpoint
+		Expect(LeeReady(px(102), px(100), px(102), Sign_Sell)).To(Equal(Sign_Buy))
+		Expect(LeeReady(px(100), px(100), px(102), Sign_Buy)).To(Equal(Sign_Sell))
+		Expect(LeeReady(px(100), dbn.UNDEF_PRICE, px(102), Sign_Buy)).To(Equal(Sign_Buy))
+		Expect(math.IsNaN(Midpoint(dbn.UNDEF_PRICE, px(102)))).To(BeTrue())
+		Expect(Midpoint(px(100), px(102))).To(BeNumerically("~", 1.01, 1e-12))
+	})
+
+	It("should compute order-flow imbalance", func() {
+		prev := dbn.BidAskPair{BidPx: px(100), AskPx: px(102), BidSz: 10, AskSz: 20}
+		Expect(OrderFlowImbalance(prev, prev)).To(Equal(int64(0)))
+		// Bid up and ask unchanged at a smaller size
+		Expect(OrderFlowImbalance(prev, dbn.BidAskPair{BidPx: px(101), AskPx: px(102), BidSz: 5, AskSz: 15})).To(Equal(int64(5 + 5)))
+		// Bid down and ask down
+		Expect(OrderFlowImbalance(prev, dbn.BidAskPair{BidPx: px(99), AskPx: px(101), BidSz: 5, AskSz: 7})).To(Equal(int64(-10 - 7)))
+		Expect(OrderFlowImbalance(prev, dbn.BidAskPair{BidPx: dbn.UNDEF_PRICE, AskPx: px(101)})).To(Equal(int64(0)))
+	})
+})
+
+var _ = Describe("Analyzer", func() {
+	It("should compute spreads, horizons, and intervals", func() {
+		var trades []*TradeMetrics
+		var intervals []*IntervalMetrics
+		analyzer, err := NewAnalyzer(Options{Horizons: []time.Duration{5 * time.Second, time.Second}, Interval: time.Minute},
+			func(trade *TradeMetrics) error {
+				trades = append(trades, trade)
+				return nil
+			},
+			func(interval *IntervalMetrics) error {
+				intervals = append(intervals, interval)
+				return nil
+			})
+		Expect(err).To(BeNil())
+
+		Expect(analyzer.OnMbp1(newTestMbp1(0, dbn.UNDEF_PRICE, 0, px(10000), px(10010)))).To(Succeed())
+		Expect(analyzer.OnMbp1(newTestMbp1(time.Second, px(10010), 5, px(10000), px(10010)))).To(Succeed())
+		Expect(analyzer.OnMbp1(newTestMbp1(1500*time.Millisecond, dbn.UNDEF_PRICE, 0, px(10005), px(10015)))).To(Succeed())
+		Expect(analyzer.OnMbp1(newTestMbp1(3*time.Second, px(10005), 3, px(10005), px(10015)))).To(Succeed())
+		Expect(trades).To(BeEmpty()) // awaiting the 5s horizon
+		Expect(analyzer.OnMbp1(newTestMbp1(61*time.Second, dbn.UNDEF_PRICE, 0, px(10005), px(10015)))).To(Succeed())
+		Expect(trades).To(HaveLen(2))
+		Expect(intervals).To(HaveLen(1))
+
+		buy := trades[0]
+		Expect(buy.LeeReadySign).To(Equal(Sign_Buy))
+		Expect(buy.TickSign).To(Equal(Sign_Unknown))
+		Expect(buy.Midpoint).To(BeNumerically("~", 100.05, 1e-9))
+		Expect(buy.EffectiveSpread).To(BeNumerically("~", 0.10, 1e-9))
+		Expect(buy.EffectiveSpreadBps).To(BeNumerically("~", 0.10/100.05*1e4, 1e-9))
+		Expect(buy.Horizons).To(HaveLen(2))
+		Expect(buy.Horizons[0].Horizon).To(Equal(time.Second))
+		Expect(buy.Horizons[0].Midpoint).To(BeNumerically("~", 100.10, 1e-9))
+		Expect(buy.Horizons[0].RealizedSpread).To(BeNumerically("~", 0, 1e-9))
+		Expect(buy.Horizons[0].PriceImpact).To(BeNumerically("~", 0.10, 1e-9))
+
+		sell := trades[1]
+		Expect(sell.LeeReadySign).To(Equal(Sign_Sell))
+		Expect(sell.TickSign).To(Equal(Sign_Sell))
+		Expect(sell.EffectiveSpread).To(BeNumerically("~", 0.10, 1e-9))
+
+		interval := intervals[0]
+		Expect(interval.Start).To(Equal(uint64(0)))
+		Expect(interval.NumTrades).To(Equal(2))
+		Expect([]uint64{interval.Volume, interval.BuyVolume, interval.SellVolume}).To(Equal([]uint64{8, 5, 3}))
+		Expect(interval.TradeImbalance()).To(BeNumerically("~", 2.0/8.0, 1e-12))
+		Expect(interval.OrderFlowImbalance).To(Equal(int64(20)))
+		Expect(interval.Vwap).To(BeNumerically("~", (100.10*5+100.05*3)/8, 1e-9))
+		Expect(interval.Twap).To(BeNumerically("~", (100.10*2+100.05*57)/59, 1e-9))
+
+		// The next interval has no trades but carries the last price
+		Expect(analyzer.Flush()).To(Succeed())
+		Expect(intervals).To(HaveLen(2))
+		Expect(intervals[1].Start).To(Equal(uint64(time.Minute)))
+		Expect(math.IsNaN(intervals[1].Vwap)).To(BeTrue())
+		Expect(intervals[1].Twap).To(BeNumerically("~", 100.05, 1e-9))
+	})
+
+	It("should flush trades awaiting their horizons", func() {
+		var trades []*TradeMetrics
+		analyzer, err := NewAnalyzer(Options{Horizons: []time.Duration{time.Second}}, func(trade *TradeMetrics) error {
+			trades = append(trades, trade)
+			return nil
+		}, nil)
+		Expect(err).To(BeNil())
+		Expect(analyzer.OnMbp1(newTestMbp1(0, px(10010), 1, px(10000), px(10010)))).To(Succeed())
+		Expect(trades).To(BeEmpty())
+		Expect(analyzer.Flush()).To(Succeed())
+		Expect(trades).To(HaveLen(1))
+		Expect(math.IsNaN(trades[0].Horizons[0].RealizedSpread)).To(BeTrue())
+
+		_, err = NewAnalyzer(Options{Horizons: []time.Duration{0}}, nil, nil)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should analyze the tbbo test data", func() {
+		reader, closer, err := dbn.MakeCompressedReader("../tests/data/test_data.tbbo.v3.dbn.zst", false)
+		Expect(err).To(BeNil())
+		defer closer.Close()
+		records, _, err := dbn.ReadDBNToSlice[dbn.Mbp1Msg](reader)
+		Expect(err).To(BeNil())
+		Expect(records).NotTo(BeEmpty())
+
+		var numTrades int
+		var volume uint64
+		analyzer, err := NewAnalyzer(Options{Horizons: []time.Duration{time.Second}, Interval: time.Minute},
+			func(trade *TradeMetrics) error {
+				numTrades++
+				return nil
+			},
+			func(interval *IntervalMetrics) error {
+				volume += interval.Volume
+				return nil
+			})
+		Expect(err).To(BeNil())
+		var wantVolume uint64
+		for i := range records {
+			wantVolume += uint64(records[i].Size)
+			Expect(analyzer.OnMbp1(&records[i])).To(Succeed())
+		}
+		Expect(analyzer.Flush()).To(Succeed())
+		Expect(numTrades).To(Equal(len(records)))
+		Expect(volume).To(Equal(wantVolume))
+	})
+})
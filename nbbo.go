@@ -0,0 +1,292 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NbboCondition is the state of a consolidated best bid and offer.
+type NbboCondition uint8
+
+const (
+	NbboCondition_Normal   NbboCondition = 0 // The best bid is below the best offer
+	NbboCondition_OneSided NbboCondition = 1 // The bid or the offer, or both, is missing
+	NbboCondition_Locked   NbboCondition = 2 // The best bid equals the best offer
+	NbboCondition_Crossed  NbboCondition = 3 // The best bid is above the best offer
+)
+
+// String returns the name of the NbboCondition, such as "locked".
+func (c NbboCondition) String() string {
+	switch c {
+	case NbboCondition_Normal:
+		return "normal"
+	case NbboCondition_OneSided:
+		return "one_sided"
+	case NbboCondition_Locked:
+		return "locked"
+	case NbboCondition_Crossed:
+		return "crossed"
+	default:
+		return ""
+	}
+}
+
+// NbboFlag_LockedOrCrossed is set in the `flags` of the Cmbp1Msg records of an NbboBuilder whose
+// consolidated market is locked or crossed; NbboConditionOf tells which.  It is the publisher-specific
+// bit, as the builder is the publisher of the consolidated records, so a venue's use of it is cleared.
+const NbboFlag_LockedOrCrossed = Flags(RFlag_PUBLISHER_SPECIFIC)
+
+// NbboConditionOf returns the NbboCondition of a consolidated bid and offer.
+func NbboConditionOf(level ConsolidatedBidAskPair) NbboCondition {
+	if level.BidPx == UNDEF_PRICE || level.AskPx == UNDEF_PRICE {
+		return NbboCondition_OneSided
+	}
+	if level.BidPx == level.AskPx {
+		return NbboCondition_Locked
+	}
+	if level.BidPx > level.AskPx {
+		return NbboCondition_Crossed
+	}
+	return NbboCondition_Normal
+}
+
+// nbboVenue is the top of a venue's book, with when each side last changed price, for time priority.
+type nbboVenue struct {
+	publisherID uint16
+	bidPx       int64
+	askPx       int64
+	bidSz       uint32
+	askSz       uint32
+	bidSince    uint64
+	askSince    uint64
+}
+
+// nbboBook is the tops of the venues' books of an instrument.
+type nbboBook struct {
+	venues []*nbboVenue // sorted by publisher ID
+	level  ConsolidatedBidAskPair
+}
+
+// venue returns the book's venue of the publisher, adding it if needed.
+func (b *nbboBook) venue(publisherID uint16) *nbboVenue {
+	i := sort.Search(len(b.venues), func(i int) bool { return b.venues[i].publisherID >= publisherID })
+	if i < len(b.venues) && b.venues[i].publisherID == publisherID {
+		return b.venues[i]
+	}
+	venue := &nbboVenue{publisherID: publisherID, bidPx: UNDEF_PRICE, askPx: UNDEF_PRICE}
+	b.venues = append(b.venues, nil)
+	copy(b.venues[i+1:], b.venues[i:])
+	b.venues[i] = venue
+	return venue
+}
+
+// consolidate sets the book's level to the best bid and offer of its venues.
+// Ties in price are won by the venue which has been at it longest.
+func (b *nbboBook) consolidate() {
+	level := ConsolidatedBidAskPair{BidPx: UNDEF_PRICE, AskPx: UNDEF_PRICE}
+	var bidSince, askSince uint64
+	for _, venue := range b.venues {
+		if venue.bidPx != UNDEF_PRICE && venue.bidSz > 0 {
+			if level.BidPx == UNDEF_PRICE || venue.bidPx > level.BidPx || (venue.bidPx == level.BidPx && venue.bidSince < bidSince) {
+				level.BidPx, level.BidSz, level.BidPb, bidSince = venue.bidPx, venue.bidSz, venue.publisherID, venue.bidSince
+			}
+		}
+		if venue.askPx != UNDEF_PRICE && venue.askSz > 0 {
+			if level.AskPx == UNDEF_PRICE || venue.askPx < level.AskPx || (venue.askPx == level.AskPx && venue.askSince < askSince) {
+				level.AskPx, level.AskSz, level.AskPb, askSince = venue.askPx, venue.askSz, venue.publisherID, venue.askSince
+			}
+		}
+	}
+	b.level = level
+}
+
+// NbboBuilder consolidates the top of book of an instrument across venues into its best bid and
+// offer, from per-publisher Mbp1Msg or BboMsg records, such as from the per-venue datasets of US
+// equities merged in `ts_recv` order by a DbnMergeScanner.
+//
+// Each dataset numbers its instruments differently, so books are keyed by symbol once symbol maps
+// are added with AddSymbolMap: a record's instrument ID is resolved to its symbol at its `ts_event`
+// by the maps of its publisher's dataset, and each symbol has a consolidated instrument ID of its
+// own, per InstrumentIDOf.  Without symbol maps, books are keyed by instrument ID, so the venues'
+// records must share instrument IDs.
+//
+// For each record, it emits a Cmbp1Msg with the record's header, action, side, price, size,
+// flags, and timestamps, and the consolidated level after it, whose `bid_pb` and `ask_pb` are the
+// publishers of the best bid and offer, and whether that market is locked or crossed, also set in
+// its flags as NbboFlag_LockedOrCrossed.  A venue without a bid or offer, whose price is
+// UNDEF_PRICE or size is 0, is not part of that side.  At equal prices, the venue which has been
+// at the price longest is the best.
+type NbboBuilder struct {
+	NullVisitor
+	books         map[uint32]*nbboBook // by consolidated instrument ID
+	symbolMaps    map[Dataset][]*TsSymbolMap
+	instrumentIDs map[string]uint32 // consolidated instrument IDs by symbol
+	handler       func(record *Cmbp1Msg, condition NbboCondition) error
+	record        Cmbp1Msg
+}
+
+// NewNbboBuilder returns an empty NbboBuilder, which passes each consolidated record and its
+// NbboCondition to `handler`, if not nil.  The record is reused between calls.
+func NewNbboBuilder(handler func(record *Cmbp1Msg, condition NbboCondition) error) *NbboBuilder {
+	return &NbboBuilder{
+		books:         make(map[uint32]*nbboBook),
+		symbolMaps:    make(map[Dataset][]*TsSymbolMap),
+		instrumentIDs: make(map[string]uint32),
+		handler:       handler,
+	}
+}
+
+// AddSymbolMap adds a symbol map of the instrument IDs of a dataset's publishers, such as one
+// filled from the metadata of a file of it, so their records are consolidated by symbol.
+// A dataset may have several maps, such as of its files of different days.
+func (b *NbboBuilder) AddSymbolMap(dataset Dataset, symbolMap *TsSymbolMap) {
+	b.symbolMaps[dataset] = append(b.symbolMaps[dataset], symbolMap)
+}
+
+// InstrumentIDOf returns the consolidated instrument ID of a symbol, numbering new symbols from 1
+// in the order they are first seen.  Symbols may be numbered before consolidating, such as to
+// write the symbol mappings of the consolidated records first.
+func (b *NbboBuilder) InstrumentIDOf(symbol string) uint32 {
+	id, ok := b.instrumentIDs[symbol]
+	if !ok {
+		id = uint32(len(b.instrumentIDs) + 1)
+		b.instrumentIDs[symbol] = id
+	}
+	return id
+}
+
+// instrumentID returns the consolidated instrument ID of a record, per its symbol if there are
+// symbol maps, otherwise its own.
+func (b *NbboBuilder) instrumentID(header RHeader) (uint32, error) {
+	if len(b.symbolMaps) == 0 {
+		return header.InstrumentID, nil
+	}
+	dataset := Publisher(header.PublisherID).Dataset()
+	for _, symbolMap := range b.symbolMaps[dataset] {
+		if symbol := symbolMap.GetAt(header.TsEvent, header.InstrumentID); symbol != "" {
+			return b.InstrumentIDOf(symbol), nil
+		}
+	}
+	return 0, fmt.Errorf("no symbol of instrument %d of publisher %d at %d", header.InstrumentID, header.PublisherID, header.TsEvent)
+}
+
+// update sets the top of book of the header's publisher and instrument to the level.
+// Returns the instrument's book and its consolidated instrument ID.
+func (b *NbboBuilder) update(header RHeader, tsRecv uint64, level BidAskPair) (*nbboBook, uint32, error) {
+	id, err := b.instrumentID(header)
+	if err != nil {
+		return nil, 0, err
+	}
+	book := b.books[id]
+	if book == nil {
+		book = &nbboBook{}
+		b.books[id] = book
+	}
+	venue := book.venue(header.PublisherID)
+	if level.BidPx != venue.bidPx {
+		venue.bidSince = tsRecv
+	}
+	if level.AskPx != venue.askPx {
+		venue.askSince = tsRecv
+	}
+	venue.bidPx, venue.bidSz = level.BidPx, level.BidSz
+	venue.askPx, venue.askSz = level.AskPx, level.AskSz
+	book.consolidate()
+	return book, id, nil
+}
+
+// emit passes the record, with the book's consolidated instrument ID and level, to the handler.
+func (b *NbboBuilder) emit(book *nbboBook, instrumentID uint32) error {
+	if b.handler == nil {
+		return nil
+	}
+	condition := NbboConditionOf(book.level)
+	b.record.Header.Length = uint8(Cmbp1Msg_Size / 4)
+	b.record.Header.RType = RType_Cmbp1
+	b.record.Header.InstrumentID = instrumentID
+	b.record.Level = book.level
+	b.record.Flags &^= uint8(NbboFlag_LockedOrCrossed)
+	if condition == NbboCondition_Locked || condition == NbboCondition_Crossed {
+		b.record.Flags |= uint8(NbboFlag_LockedOrCrossed)
+	}
+	return b.handler(&b.record, condition)
+}
+
+// OnMbp1 sets the top of book of the record's publisher and emits the consolidated record.
+// Returns an error if there are symbol maps but none maps the record's instrument.
+func (b *NbboBuilder) OnMbp1(record *Mbp1Msg) error {
+	book, id, err := b.update(record.Header, record.TsRecv, record.Level)
+	if err != nil {
+		return err
+	}
+	b.record = Cmbp1Msg{
+		Header:    record.Header,
+		Price:     record.Price,
+		Size:      record.Size,
+		Action:    record.Action,
+		Side:      record.Side,
+		Flags:     record.Flags,
+		TsRecv:    record.TsRecv,
+		TsInDelta: record.TsInDelta,
+		Sequence:  record.Sequence,
+	}
+	return b.emit(book, id)
+}
+
+// OnBbo sets the top of book of the record's publisher and emits the consolidated record,
+// with Action_None and the BBO's last trade.
+// Returns an error if there are symbol maps but none maps the record's instrument.
+func (b *NbboBuilder) OnBbo(record *BboMsg) error {
+	book, id, err := b.update(record.Header, record.TsRecv, record.Level)
+	if err != nil {
+		return err
+	}
+	b.record = Cmbp1Msg{
+		Header:   record.Header,
+		Price:    record.Price,
+		Size:     record.Size,
+		Action:   byte(Action_None),
+		Side:     record.Side,
+		Flags:    record.Flags,
+		TsRecv:   record.TsRecv,
+		Sequence: record.Sequence,
+	}
+	return b.emit(book, id)
+}
+
+// Nbbo returns the consolidated best bid and offer of the instrument, by its consolidated instrument ID.
+// Returns false if no venue has quoted it.
+func (b *NbboBuilder) Nbbo(instrumentID uint32) (ConsolidatedBidAskPair, bool) {
+	book := b.books[instrumentID]
+	if book == nil {
+		return ConsolidatedBidAskPair{}, false
+	}
+	return book.level, true
+}
+
+// Condition returns the NbboCondition of the instrument's consolidated best bid and offer, by its
+// consolidated instrument ID.
+func (b *NbboBuilder) Condition(instrumentID uint32) NbboCondition {
+	level, ok := b.Nbbo(instrumentID)
+	if !ok {
+		return NbboCondition_OneSided
+	}
+	return NbboConditionOf(level)
+}
+
+// VenueQuote returns the top of book of the instrument, by its consolidated instrument ID, at the publisher's venue.
+// Returns false if the venue has not quoted it.
+func (b *NbboBuilder) VenueQuote(instrumentID uint32, publisherID uint16) (BidAskPair, bool) {
+	book := b.books[instrumentID]
+	if book == nil {
+		return BidAskPair{}, false
+	}
+	for _, venue := range book.venues {
+		if venue.publisherID == publisherID {
+			return BidAskPair{BidPx: venue.bidPx, AskPx: venue.askPx, BidSz: venue.bidSz, AskSz: venue.askSz}, true
+		}
+	}
+	return BidAskPair{}, false
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"io"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestQuote returns a venue's top of book of an instrument received at `tsRecv`.
+func newTestQuote(instrumentID uint32, publisherID uint16, tsRecv uint64, bidPx, askPx int64, bidSz, askSz uint32) *dbn.Mbp1Msg {
+	return &dbn.Mbp1Msg{
+		Header: dbn.RHeader{
+			Length:       uint8(dbn.Mbp1Msg_Size / 4),
+			RType:        dbn.RType_Mbp1,
+			PublisherID:  publisherID,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		Price:  bidPx,
+		Size:   bidSz,
+		Action: uint8(dbn.Action_Add),
+		Side:   uint8(dbn.Side_Bid),
+		TsRecv: tsRecv,
+		Level:  dbn.BidAskPair{BidPx: bidPx, AskPx: askPx, BidSz: bidSz, AskSz: askSz},
+	}
+}
+
+// openTestScanner returns a DbnScanner of a test data file, closed at the end of the spec.
+func openTestScanner(filename string) *dbn.DbnScanner {
+	reader, closer, err := dbn.MakeCompressedReader("./tests/data/"+filename, false)
+	Expect(err).To(BeNil())
+	DeferCleanup(closer.Close)
+	return dbn.NewDbnScanner(reader)
+}
+
+var _ = Describe("NbboBuilder", func() {
+	It("should consolidate venues and flag locked and crossed markets", func() {
+		var records []dbn.Cmbp1Msg
+		var conditions []dbn.NbboCondition
+		builder := dbn.NewNbboBuilder(func(record *dbn.Cmbp1Msg, condition dbn.NbboCondition) error {
+			records = append(records, *record)
+			conditions = append(conditions, condition)
+			return nil
+		})
+
+		Expect(builder.OnMbp1(newTestQuote(1, 2, 100, 1000, 1010, 5, 6))).To(Succeed())
+		Expect(builder.OnMbp1(newTestQuote(1, 3, 200, 1000, 1005, 7, 8))).To(Succeed())
+		// Venue 2 was at the bid of 1000 first
+		Expect(records[1].Level).To(Equal(dbn.ConsolidatedBidAskPair{BidPx: 1000, AskPx: 1005, BidSz: 5, AskSz: 8, BidPb: 2, AskPb: 3}))
+		Expect(records[1].Header.RType).To(Equal(dbn.RType_Cmbp1))
+		Expect(records[1].Header.PublisherID).To(Equal(uint16(3)))
+		Expect(records[1].TsRecv).To(Equal(uint64(200)))
+		Expect(conditions[1]).To(Equal(dbn.NbboCondition_Normal))
+
+		Expect(dbn.Flags(records[1].Flags).Has(dbn.NbboFlag_LockedOrCrossed)).To(BeFalse())
+
+		Expect(builder.OnMbp1(newTestQuote(1, 2, 300, 1005, 1010, 5, 6))).To(Succeed())
+		Expect(conditions[2]).To(Equal(dbn.NbboCondition_Locked))
+		Expect(dbn.Flags(records[2].Flags).Has(dbn.NbboFlag_LockedOrCrossed)).To(BeTrue())
+		Expect(builder.OnMbp1(newTestQuote(1, 2, 400, 1007, 1010, 5, 6))).To(Succeed())
+		Expect(conditions[3]).To(Equal(dbn.NbboCondition_Crossed))
+		Expect(dbn.Flags(records[3].Flags).Has(dbn.NbboFlag_LockedOrCrossed)).To(BeTrue())
+		Expect(builder.Condition(1)).To(Equal(dbn.NbboCondition_Crossed))
+		Expect(dbn.NbboCondition_Crossed.String()).To(Equal("crossed"))
+
+		// Venue 3 pulls its quotes
+		Expect(builder.OnMbp1(newTestQuote(1, 3, 500, dbn.UNDEF_PRICE, dbn.UNDEF_PRICE, 0, 0))).To(Succeed())
+		nbbo, ok := builder.Nbbo(1)
+		Expect(ok).To(BeTrue())
+		Expect(nbbo).To(Equal(dbn.ConsolidatedBidAskPair{BidPx: 1007, AskPx: 1010, BidSz: 5, AskSz: 6, BidPb: 2, AskPb: 2}))
+		venueQuote, ok := builder.VenueQuote(1, 3)
+		Expect(ok).To(BeTrue())
+		Expect(venueQuote.BidPx).To(Equal(int64(dbn.UNDEF_PRICE)))
+
+		// A venue's publisher-specific flag is not taken for the consolidated market's
+		quote := newTestQuote(1, 2, 600, dbn.UNDEF_PRICE, 1010, 0, 6)
+		quote.Flags = dbn.RFlag_LAST | dbn.RFlag_PUBLISHER_SPECIFIC
+		Expect(builder.OnMbp1(quote)).To(Succeed())
+		Expect(conditions[5]).To(Equal(dbn.NbboCondition_OneSided))
+		Expect(records[5].Flags).To(Equal(dbn.RFlag_LAST))
+		_, ok = builder.Nbbo(2)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should consolidate venues with their own instrument IDs by symbol", func() {
+		var records []dbn.Cmbp1Msg
+		builder := dbn.NewNbboBuilder(func(record *dbn.Cmbp1Msg, condition dbn.NbboCondition) error {
+			records = append(records, *record)
+			return nil
+		})
+		// Nasdaq numbers ACME 7 and WIDG 8, and NYSE Arca numbers ACME 100 and WIDG 7
+		xnas, arcx := dbn.NewTsSymbolMap(), dbn.NewTsSymbolMap()
+		Expect(xnas.Insert(7, 19700101, 19700102, "ACME")).To(Succeed())
+		Expect(xnas.Insert(8, 19700101, 19700102, "WIDG")).To(Succeed())
+		Expect(arcx.Insert(100, 19700101, 19700102, "ACME")).To(Succeed())
+		Expect(arcx.Insert(7, 19700101, 19700102, "WIDG")).To(Succeed())
+		builder.AddSymbolMap(dbn.Dataset_XnasItch, xnas)
+		builder.AddSymbolMap(dbn.Dataset_ArcxPillar, arcx)
+		Expect(builder.InstrumentIDOf("WIDG")).To(Equal(uint32(1)))
+
+		xnasID, arcxID := uint16(dbn.Publisher_XnasItchXnas), uint16(dbn.Publisher_ArcxPillarArcx)
+		Expect(builder.OnMbp1(newTestQuote(7, xnasID, 100, 1000, 1010, 5, 6))).To(Succeed())
+		Expect(builder.OnMbp1(newTestQuote(7, arcxID, 200, 2000, 2010, 5, 6))).To(Succeed())
+		Expect(builder.OnMbp1(newTestQuote(100, arcxID, 300, 1001, 1009, 7, 8))).To(Succeed())
+		Expect(builder.OnMbp1(newTestQuote(8, xnasID, 400, 2001, 2009, 7, 8))).To(Succeed())
+
+		acme := builder.InstrumentIDOf("ACME")
+		Expect(acme).To(Equal(uint32(2)))
+		Expect([]uint32{records[0].Header.InstrumentID, records[1].Header.InstrumentID, records[2].Header.InstrumentID, records[3].Header.InstrumentID}).
+			To(Equal([]uint32{acme, 1, acme, 1}))
+		Expect(records[2].Level).To(Equal(dbn.ConsolidatedBidAskPair{BidPx: 1001, AskPx: 1009, BidSz: 7, AskSz: 8, BidPb: arcxID, AskPb: arcxID}))
+		Expect(records[3].Level).To(Equal(dbn.ConsolidatedBidAskPair{BidPx: 2001, AskPx: 2009, BidSz: 7, AskSz: 8, BidPb: xnasID, AskPb: xnasID}))
+		quote, ok := builder.VenueQuote(acme, xnasID)
+		Expect(ok).To(BeTrue())
+		Expect(quote.BidPx).To(Equal(int64(1000)))
+
+		// Instruments without a symbol can't be consolidated
+		Expect(builder.OnMbp1(newTestQuote(9, xnasID, 500, 1000, 1010, 5, 6))).NotTo(Succeed())
+		Expect(builder.OnMbp1(newTestQuote(7, 1, 500, 1000, 1010, 5, 6))).NotTo(Succeed())
+	})
+
+	It("should match the cmbp-1 test data of a single venue", func() {
+		reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.cmbp-1.v3.dbn.zst", false)
+		Expect(err).To(BeNil())
+		defer closer.Close()
+		want, _, err := dbn.ReadDBNToSlice[dbn.Cmbp1Msg](reader)
+		Expect(err).To(BeNil())
+		Expect(want).NotTo(BeEmpty())
+
+		var got []dbn.Cmbp1Msg
+		builder := dbn.NewNbboBuilder(func(record *dbn.Cmbp1Msg, condition dbn.NbboCondition) error {
+			got = append(got, *record)
+			return nil
+		})
+		scanner := openTestScanner("test_data.mbp-1.v3.dbn.zst")
+		for scanner.Next() {
+			Expect(scanner.Visit(builder)).To(Succeed())
+		}
+		Expect(got).To(HaveLen(len(want)))
+		for i := range want {
+			Expect(got[i].Level).To(Equal(want[i].Level))
+			Expect(got[i].Header.InstrumentID).To(Equal(want[i].Header.InstrumentID))
+			Expect(got[i].TsRecv).To(Equal(want[i].TsRecv))
+			Expect([]any{got[i].Action, got[i].Side, got[i].Price, got[i].Size}).To(Equal([]any{want[i].Action, want[i].Side, want[i].Price, want[i].Size}))
+		}
+	})
+})
+
+var _ = Describe("DbnMergeScanner", func() {
+	It("should merge files in ts_recv order", func() {
+		var counts [2]int
+		for i, filename := range []string{"test_data.mbp-1.v3.dbn.zst", "test_data.trades.v3.dbn.zst"} {
+			scanner := openTestScanner(filename)
+			for scanner.Next() {
+				counts[i]++
+			}
+		}
+
+		merger := dbn.NewDbnMergeScanner(openTestScanner("test_data.mbp-1.v3.dbn.zst"), openTestScanner("test_data.trades.v3.dbn.zst"))
+		var merged [2]int
+		var lastTs uint64
+		for merger.Next() {
+			_, i := merger.Current()
+			merged[i]++
+			record, err := merger.DecodeAny()
+			Expect(err).To(BeNil())
+			ts := dbn.RecordIndexTs(record)
+			Expect(ts).To(BeNumerically(">=", lastTs))
+			lastTs = ts
+		}
+		Expect(merger.Error()).To(Equal(io.EOF))
+		Expect(merged).To(Equal(counts))
+		scanner, i := merger.Current()
+		Expect(scanner).To(BeNil())
+		Expect(i).To(Equal(-1))
+	})
+})
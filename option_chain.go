@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"cmp"
+	"slices"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// OptionKey identifies a strike of an option chain.
+type OptionKey struct {
+	Underlying string // Underlying symbol, such as "AAPL"
+	Expiration uint64 // Expiration as UNIX nanoseconds
+	Strike     Price  // Strike price
+}
+
+// OptionPair is the call and put of an OptionKey.  Either may be nil.
+type OptionPair struct {
+	OptionKey
+	Call *InstrumentDefMsgV3
+	Put  *InstrumentDefMsgV3
+}
+
+// OptionContract is one option of an OptionChain, as looked up by instrument ID.
+type OptionContract struct {
+	OptionKey
+	Class      InstrumentClass     // InstrumentClass_Call or InstrumentClass_Put
+	Definition *InstrumentDefMsgV3 // Definition of the option
+}
+
+// OptionChain groups option definitions by underlying, expiration, and strike, pairing
+// calls with puts.  Its options may be looked up by instrument ID with Contract, to join
+// quote and trade records to their chain.
+//
+// Definitions missing a strike, expiration, or class, such as some OPRA.PILLAR records,
+// are completed from their OSI raw symbol.  The underlying is the definition's `underlying`,
+// else its `asset`, else the root of its OSI symbol.  Option spreads are not included.
+type OptionChain struct {
+	pairs map[OptionKey]*OptionPair
+	byID  map[uint32]OptionContract
+}
+
+// NewOptionChain returns an empty OptionChain.
+func NewOptionChain() *OptionChain {
+	return &OptionChain{
+		pairs: make(map[OptionKey]*OptionPair),
+		byID:  make(map[uint32]OptionContract),
+	}
+}
+
+// NewOptionChainFromMaster returns an OptionChain of the options of an InstrumentMaster in effect at `timestamp`.
+func NewOptionChainFromMaster(im *InstrumentMaster, timestamp uint64) *OptionChain {
+	chain := NewOptionChain()
+	for _, def := range im.Select(timestamp, func(def *InstrumentDefMsgV3) bool { return true }) {
+		chain.Insert(def)
+	}
+	return chain
+}
+
+// IsEmpty returns true if there are no options.
+func (c *OptionChain) IsEmpty() bool {
+	return len(c.byID) == 0
+}
+
+// Len returns the number of options.
+func (c *OptionChain) Len() int {
+	return len(c.byID)
+}
+
+// Insert adds or replaces the option of a definition, or removes it if its SecurityUpdateAction is Delete.
+// Returns false if the definition is not a call or put, or deletes an unknown option.
+func (c *OptionChain) Insert(def *InstrumentDefMsgV3) bool {
+	if SecurityUpdateAction(def.SecurityUpdateAction) == Delete {
+		return c.Remove(def.Header.InstrumentID)
+	}
+	contract, ok := newOptionContract(def)
+	if !ok {
+		return false
+	}
+	c.Remove(def.Header.InstrumentID)
+	pair := c.pairs[contract.OptionKey]
+	if pair == nil {
+		pair = &OptionPair{OptionKey: contract.OptionKey}
+		c.pairs[contract.OptionKey] = pair
+	}
+	if contract.Class == InstrumentClass_Call {
+		pair.Call = def
+	} else {
+		pair.Put = def
+	}
+	c.byID[def.Header.InstrumentID] = contract
+	return true
+}
+
+// OnInstrumentDefMsg inserts the definition, so an OptionChain may be fed by a Visitor.
+func (c *OptionChain) OnInstrumentDefMsg(def *InstrumentDefMsg) error {
+	c.Insert(def)
+	return nil
+}
+
+// Remove removes the option with the instrument ID.  Returns false if there is none.
+func (c *OptionChain) Remove(instrumentID uint32) bool {
+	contract, ok := c.byID[instrumentID]
+	if !ok {
+		return false
+	}
+	delete(c.byID, instrumentID)
+	pair := c.pairs[contract.OptionKey]
+	if pair.Call != nil && pair.Call.Header.InstrumentID == instrumentID {
+		pair.Call = nil
+	}
+	if pair.Put != nil && pair.Put.Header.InstrumentID == instrumentID {
+		pair.Put = nil
+	}
+	if pair.Call == nil && pair.Put == nil {
+		delete(c.pairs, contract.OptionKey)
+	}
+	return true
+}
+
+// Contract returns the option with the instrument ID, such as that of a quote or trade record.
+// Returns false if there is none.
+func (c *OptionChain) Contract(instrumentID uint32) (OptionContract, bool) {
+	contract, ok := c.byID[instrumentID]
+	return contract, ok
+}
+
+// Pair returns the call and put of the key, or nil if there are neither.
+func (c *OptionChain) Pair(key OptionKey) *OptionPair {
+	return c.pairs[key]
+}
+
+// PairOf returns the call and put of the option with the instrument ID, or nil if there is none.
+func (c *OptionChain) PairOf(instrumentID uint32) *OptionPair {
+	contract, ok := c.byID[instrumentID]
+	if !ok {
+		return nil
+	}
+	return c.pairs[contract.OptionKey]
+}
+
+// Underlyings returns the sorted underlyings of the options.
+func (c *OptionChain) Underlyings() []string {
+	underlyings := make([]string, 0)
+	for key := range c.pairs {
+		underlyings = append(underlyings, key.Underlying)
+	}
+	slices.Sort(underlyings)
+	return slices.Compact(underlyings)
+}
+
+// Expirations returns the sorted expirations of the options on the underlying.
+func (c *OptionChain) Expirations(underlying string) []uint64 {
+	expirations := make([]uint64, 0)
+	for key := range c.pairs {
+		if key.Underlying == underlying {
+			expirations = append(expirations, key.Expiration)
+		}
+	}
+	slices.Sort(expirations)
+	return slices.Compact(expirations)
+}
+
+// Strikes returns the pairs of the underlying and expiration, sorted by strike.
+func (c *OptionChain) Strikes(underlying string, expiration uint64) []*OptionPair {
+	pairs := make([]*OptionPair, 0)
+	for key, pair := range c.pairs {
+		if key.Underlying == underlying && key.Expiration == expiration {
+			pairs = append(pairs, pair)
+		}
+	}
+	slices.SortFunc(pairs, func(a, b *OptionPair) int { return cmp.Compare(a.Strike, b.Strike) })
+	return pairs
+}
+
+// newOptionContract returns the OptionContract of a call or put definition,
+// completing it from its OSI raw symbol if needed.
+func newOptionContract(def *InstrumentDefMsgV3) (OptionContract, bool) {
+	osi, osiErr := ParseOsiSymbol(TrimNullBytes(def.RawSymbol[:]))
+	class := InstrumentClass(def.InstrumentClass)
+	if class != InstrumentClass_Call && class != InstrumentClass_Put {
+		if class != 0 || osiErr != nil {
+			return OptionContract{}, false
+		}
+		class = osi.Class
+	}
+
+	contract := OptionContract{
+		OptionKey: OptionKey{
+			Underlying: TrimNullBytes(def.Underlying[:]),
+			Expiration: def.Expiration,
+			Strike:     Price(def.StrikePrice),
+		},
+		Class:      class,
+		Definition: def,
+	}
+	if contract.Underlying == "" {
+		contract.Underlying = TrimNullBytes(def.Asset[:])
+	}
+	if osiErr == nil {
+		if contract.Underlying == "" {
+			contract.Underlying = osi.Root
+		}
+		if contract.Expiration == UNDEF_TIMESTAMP {
+			contract.Expiration = ymdToTimestamp(osi.Expiration)
+		}
+		if contract.Strike == UNDEF_PRICE {
+			contract.Strike = osi.Strike
+		}
+	}
+	return contract, true
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestOption returns an OPRA-style definition of an OSI option symbol at `tsRecv`.
+func newTestOption(instrumentID uint32, tsRecv uint64, osiSymbol string, underlying string) *dbn.InstrumentDefMsgV3 {
+	osi, err := dbn.ParseOsiSymbol(osiSymbol)
+	Expect(err).To(BeNil())
+	def := newTestDefinition(instrumentID, tsRecv, osiSymbol, dbn.Add)
+	def.InstrumentClass = byte(osi.Class)
+	def.StrikePrice = int64(osi.Strike)
+	def.Expiration = uint64(dbn.YMDToTime(int(osi.Expiration), time.UTC).Add(21 * time.Hour).UnixNano())
+	copy(def.Underlying[:], underlying)
+	return def
+}
+
+var _ = Describe("OptionChain", func() {
+	var expiration uint64
+	BeforeEach(func() {
+		expiration = uint64(dbn.YMDToTime(20240119, time.UTC).Add(21 * time.Hour).UnixNano())
+	})
+
+	It("should group options by underlying, expiration, and strike", func() {
+		chain := dbn.NewOptionChain()
+		Expect(chain.Insert(newTestOption(1, 100, "AAPL  240119C00190000", "AAPL"))).To(BeTrue())
+		Expect(chain.Insert(newTestOption(2, 100, "AAPL  240119P00190000", "AAPL"))).To(BeTrue())
+		Expect(chain.Insert(newTestOption(3, 100, "AAPL  240119C00185000", "AAPL"))).To(BeTrue())
+		Expect(chain.Insert(newTestOption(4, 100, "AAPL  240216C00190000", "AAPL"))).To(BeTrue())
+		Expect(chain.Insert(newTestOption(5, 100, "MSFT  240119C00400000", "MSFT"))).To(BeTrue())
+		Expect(chain.Insert(newTestDefinition(6, 100, "ESH4", dbn.Add))).To(BeFalse())
+		Expect(chain.Len()).To(Equal(5))
+
+		Expect(chain.Underlyings()).To(Equal([]string{"AAPL", "MSFT"}))
+		expirations := chain.Expirations("AAPL")
+		Expect(expirations).To(HaveLen(2))
+		Expect(expirations[0]).To(Equal(expiration))
+
+		strikes := chain.Strikes("AAPL", expiration)
+		Expect(strikes).To(HaveLen(2))
+		Expect(strikes[0].Strike.String()).To(Equal("185"))
+		Expect(strikes[0].Put).To(BeNil())
+		Expect(strikes[1].Call.Header.InstrumentID).To(Equal(uint32(1)))
+		Expect(strikes[1].Put.Header.InstrumentID).To(Equal(uint32(2)))
+
+		// Join a trade to its chain by instrument ID
+		trade := dbn.Mbp0Msg{Header: dbn.RHeader{InstrumentID: 2}}
+		contract, ok := chain.Contract(trade.Header.InstrumentID)
+		Expect(ok).To(BeTrue())
+		Expect(contract.Class).To(Equal(dbn.InstrumentClass_Put))
+		Expect(contract.Underlying).To(Equal("AAPL"))
+		Expect(chain.PairOf(2).Call.Header.InstrumentID).To(Equal(uint32(1)))
+		Expect(chain.Pair(contract.OptionKey)).To(Equal(chain.PairOf(2)))
+		_, ok = chain.Contract(6)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should complete definitions from their OSI symbol", func() {
+		def := newTestDefinition(7, 100, "SPXW  240119P04700000", dbn.Add)
+		def.StrikePrice = dbn.UNDEF_PRICE
+		chain := dbn.NewOptionChain()
+		Expect(chain.Insert(def)).To(BeTrue())
+		contract, ok := chain.Contract(7)
+		Expect(ok).To(BeTrue())
+		Expect(contract.OptionKey).To(Equal(dbn.OptionKey{
+			Underlying: "SPXW",
+			Expiration: uint64(dbn.YMDToTime(20240119, time.UTC).UnixNano()),
+			Strike:     dbn.Price(4_700_000_000_000),
+		}))
+		Expect(contract.Class).To(Equal(dbn.InstrumentClass_Put))
+	})
+
+	It("should apply modifications and deletions", func() {
+		chain := dbn.NewOptionChain()
+		chain.Insert(newTestOption(1, 100, "AAPL  240119C00190000", "AAPL"))
+		chain.Insert(newTestOption(2, 100, "AAPL  240119P00190000", "AAPL"))
+
+		// An instrument re-defined at another strike moves
+		var visitor dbn.Visitor = &dbn.VisitorFuncs{InstrumentDefMsg: chain.OnInstrumentDefMsg}
+		Expect(dbn.VisitRecord(visitor, newTestOption(1, 200, "AAPL  240119C00195000", "AAPL"))).To(Succeed())
+		Expect(chain.Strikes("AAPL", expiration)).To(HaveLen(2))
+
+		deleted := newTestOption(2, 300, "AAPL  240119P00190000", "AAPL")
+		deleted.SecurityUpdateAction = byte(dbn.Delete)
+		Expect(chain.Insert(deleted)).To(BeTrue())
+		Expect(chain.Len()).To(Equal(1))
+		Expect(chain.Strikes("AAPL", expiration)).To(HaveLen(1))
+		Expect(chain.Insert(deleted)).To(BeFalse())
+	})
+
+	It("should build from an InstrumentMaster at a timestamp", func() {
+		im := dbn.NewInstrumentMaster()
+		im.Insert(newTestOption(1, 100, "AAPL  240119C00190000", "AAPL"))
+		im.Insert(newTestOption(2, 200, "AAPL  240119P00190000", "AAPL"))
+		Expect(dbn.NewOptionChainFromMaster(im, 150).Len()).To(Equal(1))
+		Expect(dbn.NewOptionChainFromMaster(im, dbn.UNDEF_TIMESTAMP).Len()).To(Equal(2))
+	})
+})
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// OsiRootMaxLen is the maximum length of the root of an OSI option symbol.
+const OsiRootMaxLen = 6
+
+// OsiSymbolLen is the length of a padded OSI option symbol.
+const OsiSymbolLen = OsiRootMaxLen + 15
+
+// osiStrikeScale is the Price of one unit of an OSI strike, which is in thousandths.
+const osiStrikeScale Price = priceScale / 1000
+
+// osiStrikeMax is the largest OSI strike, in thousandths.
+const osiStrikeMax = 99_999_999
+
+// OsiSymbol is an option symbol of the OCC's Options Symbology Initiative, the raw symbols
+// of OPRA.PILLAR: a root of up to 6 characters padded with spaces, the expiration date as YYMMDD,
+// C or P, and the strike price in thousandths as 8 digits, such as "AAPL  240119C00190000".
+type OsiSymbol struct {
+	Root       string          // Option root, such as "AAPL" or "SPXW"
+	Expiration uint32          // Expiration date as YYYYMMDD
+	Class      InstrumentClass // InstrumentClass_Call or InstrumentClass_Put
+	Strike     Price           // Strike price, a multiple of 0.001
+}
+
+// NewOsiSymbol returns the OsiSymbol of the fields, validated as ParseOsiSymbol would.
+func NewOsiSymbol(root string, expiration uint32, class InstrumentClass, strike Price) (OsiSymbol, error) {
+	o := OsiSymbol{Root: root, Expiration: expiration, Class: class, Strike: strike}
+	return o, o.Validate()
+}
+
+// ParseOsiSymbol parses an OSI option symbol, such as "AAPL  240119C00190000".
+// The root's padding is optional, so the compact "AAPL240119C00190000" is also accepted.
+// Two-digit years are in the 2000s.
+func ParseOsiSymbol(symbol string) (OsiSymbol, error) {
+	n := len(symbol)
+	if n < 16 || n > OsiSymbolLen {
+		return OsiSymbol{}, fmt.Errorf("invalid OSI symbol '%s': wrong length", symbol)
+	}
+	root := strings.TrimRight(symbol[:n-15], " ")
+	date, class, strike := symbol[n-15:n-9], symbol[n-9], symbol[n-8:]
+	if !isCsvInteger(date) || !isCsvInteger(strike) || date[0] == '-' || strike[0] == '-' {
+		return OsiSymbol{}, fmt.Errorf("invalid OSI symbol '%s': expected digits", symbol)
+	}
+	yymmdd, _ := strconv.Atoi(date)
+	thousandths, _ := strconv.ParseInt(strike, 10, 64)
+	o := OsiSymbol{
+		Root:       root,
+		Expiration: uint32(20_000_000 + yymmdd),
+		Class:      InstrumentClass(class),
+		Strike:     Price(thousandths) * osiStrikeScale,
+	}
+	if err := o.Validate(); err != nil {
+		return OsiSymbol{}, fmt.Errorf("invalid OSI symbol '%s': %w", symbol, err)
+	}
+	return o, nil
+}
+
+// Validate returns an error if the OsiSymbol cannot be formatted.
+func (o OsiSymbol) Validate() error {
+	if o.Root == "" || len(o.Root) > OsiRootMaxLen || strings.ContainsRune(o.Root, ' ') {
+		return fmt.Errorf("root must be 1 to %d characters without spaces", OsiRootMaxLen)
+	}
+	if o.Class != InstrumentClass_Call && o.Class != InstrumentClass_Put {
+		return fmt.Errorf("class must be C or P")
+	}
+	year, month, day := o.Expiration/10000, (o.Expiration/100)%100, o.Expiration%100
+	if year < 2000 || year > 2099 || month < 1 || month > 12 || day < 1 || day > 31 {
+		return fmt.Errorf("expiration %d is not a YYYYMMDD date of the 2000s", o.Expiration)
+	}
+	if o.Strike < 0 || o.Strike%osiStrikeScale != 0 || o.Strike/osiStrikeScale > osiStrikeMax {
+		return fmt.Errorf("strike %s is not a non-negative multiple of 0.001 below 100000", o.Strike)
+	}
+	return nil
+}
+
+// IsCall returns true if the option is a call.
+func (o OsiSymbol) IsCall() bool {
+	return o.Class == InstrumentClass_Call
+}
+
+// IsPut returns true if the option is a put.
+func (o OsiSymbol) IsPut() bool {
+	return o.Class == InstrumentClass_Put
+}
+
+// String returns the padded OSI symbol, such as "AAPL  240119C00190000".
+// The OsiSymbol should be valid; see Validate.
+func (o OsiSymbol) String() string {
+	return fmt.Sprintf("%-6s%06d%c%08d", o.Root, o.Expiration%1_000_000, byte(o.Class), int64(o.Strike/osiStrikeScale))
+}
+
+// CompactString returns the OSI symbol without the root's padding, such as "AAPL240119C00190000".
+func (o OsiSymbol) CompactString() string {
+	return fmt.Sprintf("%s%06d%c%08d", o.Root, o.Expiration%1_000_000, byte(o.Class), int64(o.Strike/osiStrikeScale))
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OsiSymbol", func() {
+	It("should parse padded and compact symbols", func() {
+		for _, symbol := range []string{"AAPL  240119C00190000", "AAPL240119C00190000"} {
+			osi, err := dbn.ParseOsiSymbol(symbol)
+			Expect(err).To(BeNil())
+			Expect(osi).To(Equal(dbn.OsiSymbol{
+				Root:       "AAPL",
+				Expiration: 20240119,
+				Class:      dbn.InstrumentClass_Call,
+				Strike:     dbn.Price(190_000_000_000),
+			}))
+			Expect(osi.IsCall()).To(BeTrue())
+			Expect(osi.String()).To(Equal("AAPL  240119C00190000"))
+			Expect(osi.CompactString()).To(Equal("AAPL240119C00190000"))
+		}
+	})
+
+	It("should parse fractional strikes and six-character roots", func() {
+		osi, err := dbn.ParseOsiSymbol("SPXW1 251219P05912500")
+		Expect(err).To(BeNil())
+		Expect(osi.Root).To(Equal("SPXW1"))
+		Expect(osi.IsPut()).To(BeTrue())
+		Expect(osi.Strike.String()).To(Equal("5912.5"))
+
+		osi, err = dbn.ParseOsiSymbol("BRKB1A260116C00000500")
+		Expect(err).To(BeNil())
+		Expect(osi.Root).To(Equal("BRKB1A"))
+		Expect(osi.Strike.String()).To(Equal("0.5"))
+	})
+
+	It("should reject invalid symbols", func() {
+		for _, symbol := range []string{
+			"",
+			"ESH4",
+			"AAPL  240119X00190000",
+			"AAPL  241319C00190000",
+			"AAPL  2401a9C00190000",
+			"AAPL  240119C-0190000",
+			"TOOLONG240119C00190000",
+			"       240119C00190000",
+		} {
+			_, err := dbn.ParseOsiSymbol(symbol)
+			Expect(err).NotTo(BeNil(), symbol)
+		}
+	})
+
+	It("should format validated symbols", func() {
+		osi, err := dbn.NewOsiSymbol("QQQ", 20250321, dbn.InstrumentClass_Put, dbn.Price(480_500_000_000))
+		Expect(err).To(BeNil())
+		Expect(osi.String()).To(Equal("QQQ   250321P00480500"))
+
+		_, err = dbn.NewOsiSymbol("QQQ", 20250321, dbn.InstrumentClass_Put, dbn.Price(480_500_100_000))
+		Expect(err).NotTo(BeNil())
+		_, err = dbn.NewOsiSymbol("QQQ", 20250321, dbn.InstrumentClass_Future, dbn.Price(1_000_000_000))
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,291 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Price is a fixed-point price in units of 1e-9, as stored in DBN's price fields,
+// where UNDEF_PRICE is null.  Convert a record's field with `dbn.Price(record.Price)`.
+//
+// Arithmetic on an undefined Price yields an undefined Price.  Overflow is not checked.
+type Price int64
+
+// priceScale is the denominator of Price, as an integer.
+const priceScale = 1_000_000_000
+
+// IsUndef returns true if the Price is UNDEF_PRICE.
+func (p Price) IsUndef() bool {
+	return p == UNDEF_PRICE
+}
+
+// Float64 returns the Price as a float64, or NaN if undefined.
+// The whole and fractional parts are converted separately to limit rounding error.
+func (p Price) Float64() float64 {
+	if p.IsUndef() {
+		return math.NaN()
+	}
+	return float64(p/priceScale) + float64(p%priceScale)/FIXED_PRICE_SCALE
+}
+
+// PriceFromFloat64 returns the Price nearest to `f`.  NaN, infinite, and out-of-range values return UNDEF_PRICE.
+func PriceFromFloat64(f float64) Price {
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= float64(math.MaxInt64)/FIXED_PRICE_SCALE {
+		return UNDEF_PRICE
+	}
+	// Formatting rounds correctly to nine decimals, avoiding the error of f*1e9
+	p, err := ParsePrice(strconv.FormatFloat(f, 'f', 9, 64))
+	if err != nil {
+		return UNDEF_PRICE
+	}
+	return p
+}
+
+// String returns the exact decimal value of the Price, without trailing zeros, such as "3720.25".
+// Returns "UNDEF" if undefined.
+func (p Price) String() string {
+	if p.IsUndef() {
+		return "UNDEF"
+	}
+	neg, whole, frac := p.parts()
+	var sb strings.Builder
+	if neg {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(strconv.FormatUint(whole, 10))
+	if frac != 0 {
+		sb.WriteByte('.')
+		sb.WriteString(strings.TrimRight(fmt.Sprintf("%09d", frac), "0"))
+	}
+	return sb.String()
+}
+
+// StringFixed returns the decimal value of the Price with exactly `places` decimal places (0 to 9),
+// rounding half away from zero, such as "3720.250000000" for 9 places.  Returns "UNDEF" if undefined.
+func (p Price) StringFixed(places int) string {
+	if p.IsUndef() {
+		return "UNDEF"
+	}
+	places = min(max(places, 0), 9)
+	neg, whole, frac := p.parts()
+	unit := uint64(math.Pow10(9 - places))
+	frac = (frac + unit/2) / unit
+	if frac >= uint64(math.Pow10(places)) {
+		whole, frac = whole+1, 0
+	}
+	var sb strings.Builder
+	if neg && (whole != 0 || frac != 0) {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(strconv.FormatUint(whole, 10))
+	if places > 0 {
+		sb.WriteByte('.')
+		sb.WriteString(fmt.Sprintf("%0*d", places, frac))
+	}
+	return sb.String()
+}
+
+// parts returns the sign, whole units, and nanounits of the Price's magnitude.
+func (p Price) parts() (bool, uint64, uint64) {
+	neg := p < 0
+	mag := uint64(p)
+	if neg {
+		mag = uint64(-(p + 1)) + 1 // avoids overflow for MinInt64
+	}
+	return neg, mag / priceScale, mag % priceScale
+}
+
+// ParsePrice parses a decimal price with up to nine decimal places, such as "-3720.25".
+// "UNDEF", "null", and "NaN" parse as UNDEF_PRICE.
+func ParsePrice(str string) (Price, error) {
+	str = strings.TrimSpace(str)
+	switch strings.ToLower(str) {
+	case "undef", "null", "nan":
+		return UNDEF_PRICE, nil
+	}
+	digits, neg := str, false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		neg, digits = digits[0] == '-', digits[1:]
+	}
+	wholeStr, fracStr, _ := strings.Cut(digits, ".")
+	if wholeStr == "" && fracStr == "" {
+		return 0, fmt.Errorf("invalid price '%s'", str)
+	}
+	if len(fracStr) > 9 {
+		return 0, fmt.Errorf("invalid price '%s', more than 9 decimal places", str)
+	}
+	var whole, frac uint64
+	var err error
+	if wholeStr != "" {
+		if whole, err = strconv.ParseUint(wholeStr, 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid price '%s'", str)
+		}
+	}
+	if fracStr != "" {
+		if frac, err = strconv.ParseUint(fracStr, 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid price '%s'", str)
+		}
+		frac *= uint64(math.Pow10(9 - len(fracStr)))
+	}
+	limit := uint64(math.MaxInt64)
+	if neg {
+		limit++
+	}
+	if whole > (limit-frac)/priceScale {
+		return 0, fmt.Errorf("price '%s' is out of range", str)
+	}
+	mag := whole*priceScale + frac
+	if neg {
+		return Price(-int64(mag-1) - 1), nil
+	}
+	return Price(mag), nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Add returns p + o.
+func (p Price) Add(o Price) Price {
+	if p.IsUndef() || o.IsUndef() {
+		return UNDEF_PRICE
+	}
+	return p + o
+}
+
+// Sub returns p - o.
+func (p Price) Sub(o Price) Price {
+	if p.IsUndef() || o.IsUndef() {
+		return UNDEF_PRICE
+	}
+	return p - o
+}
+
+// Neg returns -p.
+func (p Price) Neg() Price {
+	if p.IsUndef() {
+		return UNDEF_PRICE
+	}
+	return -p
+}
+
+// Mul returns p multiplied by `n`, such as a price times a quantity.
+func (p Price) Mul(n int64) Price {
+	if p.IsUndef() {
+		return UNDEF_PRICE
+	}
+	return p * Price(n)
+}
+
+// Div returns p divided by `n`, rounding half away from zero.  Division by zero is undefined.
+func (p Price) Div(n int64) Price {
+	if p.IsUndef() || n == 0 {
+		return UNDEF_PRICE
+	}
+	return Price(divRound(int64(p), n))
+}
+
+// Cmp returns -1, 0, or 1 as p is less than, equal to, or greater than o.
+// An undefined Price orders after all others, as it is the maximum int64.
+func (p Price) Cmp(o Price) int {
+	switch {
+	case p < o:
+		return -1
+	case p > o:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// RoundToTick returns p rounded to the nearest multiple of `tick`, with halves away from zero.
+// Returns p if it is undefined or `tick` is not positive.
+func (p Price) RoundToTick(tick Price) Price {
+	if p.IsUndef() || tick <= 0 || tick.IsUndef() {
+		return p
+	}
+	return Price(divRound(int64(p), int64(tick))) * tick
+}
+
+// FloorToTick returns the greatest multiple of `tick` at most p, such as for a bid.
+// Returns p if it is undefined or `tick` is not positive.
+func (p Price) FloorToTick(tick Price) Price {
+	if p.IsUndef() || tick <= 0 || tick.IsUndef() {
+		return p
+	}
+	q := p / tick
+	if p%tick != 0 && p < 0 {
+		q--
+	}
+	return q * tick
+}
+
+// CeilToTick returns the least multiple of `tick` at least p, such as for an ask.
+// Returns p if it is undefined or `tick` is not positive.
+func (p Price) CeilToTick(tick Price) Price {
+	if p.IsUndef() || tick <= 0 || tick.IsUndef() {
+		return p
+	}
+	q := p / tick
+	if p%tick != 0 && p > 0 {
+		q++
+	}
+	return q * tick
+}
+
+// divRound returns a / b, rounding half away from zero.
+func divRound(a int64, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*uint64(r) >= uint64(max(b, -b)) {
+		if (a < 0) != (b < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// MarshalJSON encodes the Price as its fixed-point integer, or null if undefined.
+func (p Price) MarshalJSON() ([]byte, error) {
+	if p.IsUndef() {
+		return []byte("null"), nil
+	}
+	return strconv.AppendInt(nil, int64(p), 10), nil
+}
+
+// UnmarshalJSON decodes null, a fixed-point integer, or a decimal, either bare or quoted.
+func (p *Price) UnmarshalJSON(b []byte) error {
+	str := string(bytes.Trim(b, `"`))
+	if str == "null" {
+		*p = UNDEF_PRICE
+		return nil
+	}
+	if strings.ContainsAny(str, ".eE") {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return err
+		}
+		if !strings.ContainsAny(str, "eE") {
+			parsed, err := ParsePrice(str)
+			*p = parsed
+			return err
+		}
+		*p = PriceFromFloat64(f)
+		return nil
+	}
+	fixed, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price %s", string(b))
+	}
+	*p = Price(fixed)
+	return nil
+}
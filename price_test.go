@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Price", func() {
+	Context("formatting", func() {
+		It("should format exact decimals", func() {
+			Expect(dbn.Price(3720250000000).String()).To(Equal("3720.25"))
+			Expect(dbn.Price(-1).String()).To(Equal("-0.000000001"))
+			Expect(dbn.Price(5_000_000_000).String()).To(Equal("5"))
+			Expect(dbn.Price(math.MinInt64).String()).To(Equal("-9223372036.854775808"))
+			Expect(dbn.Price(dbn.UNDEF_PRICE).String()).To(Equal("UNDEF"))
+		})
+		It("should format fixed decimal places", func() {
+			Expect(dbn.Price(3720250000000).StringFixed(9)).To(Equal("3720.250000000"))
+			Expect(dbn.Price(3720250000000).StringFixed(1)).To(Equal("3720.3"))
+			Expect(dbn.Price(999_999_999).StringFixed(2)).To(Equal("1.00"))
+			Expect(dbn.Price(-1).StringFixed(2)).To(Equal("0.00"))
+			Expect(dbn.Price(-1_500_000_000).StringFixed(0)).To(Equal("-2"))
+		})
+	})
+
+	Context("parsing", func() {
+		It("should parse decimals exactly", func() {
+			for str, want := range map[string]dbn.Price{
+				"3720.25":               3720250000000,
+				"-0.000000001":          -1,
+				"+.5":                   500_000_000,
+				"7":                     7_000_000_000,
+				"-9223372036.854775808": math.MinInt64,
+				"null":                  dbn.UNDEF_PRICE,
+				"UNDEF":                 dbn.UNDEF_PRICE,
+			} {
+				p, err := dbn.ParsePrice(str)
+				Expect(err).To(BeNil(), str)
+				Expect(p).To(Equal(want), str)
+			}
+		})
+		It("should reject invalid prices", func() {
+			for _, str := range []string{"", "-", "1.0000000001", "abc", "1.2.3", "9223372037"} {
+				_, err := dbn.ParsePrice(str)
+				Expect(err).NotTo(BeNil(), str)
+			}
+		})
+		It("should convert floats", func() {
+			Expect(dbn.PriceFromFloat64(0.1)).To(Equal(dbn.Price(100_000_000)))
+			Expect(dbn.PriceFromFloat64(-4500.75)).To(Equal(dbn.Price(-4500_750_000_000)))
+			Expect(dbn.PriceFromFloat64(math.NaN())).To(Equal(dbn.Price(dbn.UNDEF_PRICE)))
+			Expect(dbn.PriceFromFloat64(1e12)).To(Equal(dbn.Price(dbn.UNDEF_PRICE)))
+			Expect(math.IsNaN(dbn.Price(dbn.UNDEF_PRICE).Float64())).To(BeTrue())
+			Expect(math.IsNaN(dbn.Fixed9ToFloat64(dbn.UNDEF_PRICE))).To(BeTrue())
+		})
+	})
+
+	Context("arithmetic", func() {
+		It("should propagate undefined", func() {
+			undef := dbn.Price(dbn.UNDEF_PRICE)
+			Expect(dbn.Price(1).Add(undef).IsUndef()).To(BeTrue())
+			Expect(undef.Sub(1).IsUndef()).To(BeTrue())
+			Expect(undef.Mul(2).IsUndef()).To(BeTrue())
+			Expect(dbn.Price(1).Div(0).IsUndef()).To(BeTrue())
+		})
+		It("should compute", func() {
+			Expect(dbn.Price(250).Add(750)).To(Equal(dbn.Price(1000)))
+			Expect(dbn.Price(250).Sub(750)).To(Equal(dbn.Price(-500)))
+			Expect(dbn.Price(250).Mul(4)).To(Equal(dbn.Price(1000)))
+			Expect(dbn.Price(10).Div(4)).To(Equal(dbn.Price(3)))
+			Expect(dbn.Price(-10).Div(4)).To(Equal(dbn.Price(-3)))
+			Expect(dbn.Price(9).Div(4)).To(Equal(dbn.Price(2)))
+			Expect(dbn.Price(1).Cmp(2)).To(Equal(-1))
+		})
+		It("should round to ticks", func() {
+			tick := dbn.Price(250_000_000) // 0.25
+			Expect(dbn.Price(5000_130_000_000).RoundToTick(tick)).To(Equal(dbn.Price(5000_250_000_000)))
+			Expect(dbn.Price(5000_120_000_000).RoundToTick(tick)).To(Equal(dbn.Price(5000_000_000_000)))
+			Expect(dbn.Price(5000_125_000_000).RoundToTick(tick)).To(Equal(dbn.Price(5000_250_000_000)))
+			Expect(dbn.Price(-125_000_000).RoundToTick(tick)).To(Equal(dbn.Price(-250_000_000)))
+			Expect(dbn.Price(5000_130_000_000).FloorToTick(tick)).To(Equal(dbn.Price(5000_000_000_000)))
+			Expect(dbn.Price(5000_130_000_000).CeilToTick(tick)).To(Equal(dbn.Price(5000_250_000_000)))
+			Expect(dbn.Price(-100_000_000).FloorToTick(tick)).To(Equal(dbn.Price(-250_000_000)))
+			Expect(dbn.Price(-100_000_000).CeilToTick(tick)).To(Equal(dbn.Price(0)))
+			Expect(dbn.Price(dbn.UNDEF_PRICE).RoundToTick(tick).IsUndef()).To(BeTrue())
+		})
+	})
+
+	Context("JSON", func() {
+		It("should marshal undefined as null", func() {
+			b, err := json.Marshal([]dbn.Price{3720250000000, dbn.UNDEF_PRICE})
+			Expect(err).To(BeNil())
+			Expect(string(b)).To(Equal("[3720250000000,null]"))
+
+			var prices []dbn.Price
+			Expect(json.Unmarshal([]byte(`[3720250000000, null, "3720250000000", "3720.25", 3720.25]`), &prices)).To(Succeed())
+			Expect(prices).To(Equal([]dbn.Price{3720250000000, dbn.UNDEF_PRICE, 3720250000000, 3720250000000, 3720250000000}))
+		})
+	})
+})
+
+var _ = Describe("Flags", func() {
+	It("should test bits", func() {
+		flags := dbn.Flags(128 | 32)
+		Expect(flags.IsLast()).To(BeTrue())
+		Expect(flags.IsSnapshot()).To(BeTrue())
+		Expect(flags.IsTob()).To(BeFalse())
+		Expect(flags.Has(dbn.F_LAST | dbn.F_SNAPSHOT)).To(BeTrue())
+		Expect(flags.Has(dbn.F_LAST | dbn.F_MBP)).To(BeFalse())
+		Expect(dbn.Flags(0).String()).To(Equal("0"))
+		Expect(flags.String()).To(Equal("LAST|SNAPSHOT"))
+		Expect((dbn.F_MAYBE_BAD_BOOK | 1).String()).To(Equal("MAYBE_BAD_BOOK|0x01"))
+	})
+	It("should read from records", func() {
+		file, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.trades.v3.dbn.zst", false)
+		Expect(err).To(BeNil())
+		defer closer.Close()
+		records, _, err := dbn.ReadDBNToSlice[dbn.Mbp0Msg](file)
+		Expect(err).To(BeNil())
+		Expect(dbn.Flags(records[0].Flags).IsLast()).To(BeTrue())
+	})
+})
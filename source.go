@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Neomantra Corp
+// Sources of DBN data: local files, stdin, and remote URLs
+
+package dbn
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SourceOpener opens a named source of data for reading, in the manner of fs.FS's Open.
+// The name is the full name passed to OpenSource, including any scheme such as "https://".
+type SourceOpener interface {
+	OpenSource(name string) (io.ReadCloser, error)
+}
+
+// SourceOpenerFunc is a function which implements SourceOpener.
+type SourceOpenerFunc func(name string) (io.ReadCloser, error)
+
+// OpenSource calls f(name).
+func (f SourceOpenerFunc) OpenSource(name string) (io.ReadCloser, error) {
+	return f(name)
+}
+
+var (
+	sourceOpenersMutex sync.RWMutex
+	sourceOpeners      = map[string]SourceOpener{
+		"http":  &HttpSource{},
+		"https": &HttpSource{},
+		"s3":    SourceOpenerFunc(openS3SourceFromEnv),
+	}
+)
+
+// RegisterSourceOpener sets the SourceOpener of names with the given URL scheme, such as "s3".
+// This replaces any opener of the scheme, including the built-in "http", "https", and "s3".
+// A nil opener unregisters the scheme.
+func RegisterSourceOpener(scheme string, opener SourceOpener) {
+	sourceOpenersMutex.Lock()
+	defer sourceOpenersMutex.Unlock()
+	scheme = strings.ToLower(scheme)
+	if opener == nil {
+		delete(sourceOpeners, scheme)
+	} else {
+		sourceOpeners[scheme] = opener
+	}
+}
+
+// SourceScheme returns the lower-cased URL scheme of a source name, such as "https",
+// or "" if it is a local filename.
+func SourceScheme(name string) string {
+	scheme, _, found := strings.Cut(name, "://")
+	if !found || scheme == "" {
+		return ""
+	}
+	for i := 0; i < len(scheme); i++ {
+		c := scheme[i]
+		isAlpha := ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+		if !isAlpha && (i == 0 || !(isDigit(c) || c == '+' || c == '-' || c == '.')) {
+			return ""
+		}
+	}
+	return strings.ToLower(scheme)
+}
+
+// OpenSource opens a source of data for reading: os.Stdin if name is "-", a URL such as
+// "https://host/file.dbn.zst" or "s3://bucket/file.dbn.zst" with its scheme's SourceOpener,
+// or else a local file.  The data is returned as-is, without decompression; see OpenCompressedReader.
+func OpenSource(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	scheme := SourceScheme(name)
+	if scheme == "" {
+		return os.Open(name)
+	}
+	sourceOpenersMutex.RLock()
+	opener, ok := sourceOpeners[scheme]
+	sourceOpenersMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no source opener for scheme '%s'", scheme)
+	}
+	return opener.OpenSource(name)
+}
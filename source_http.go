@@ -0,0 +1,264 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// DefaultSourceChunkSize is the size of each Range request of remote sources.
+const DefaultSourceChunkSize = 16 * 1024 * 1024
+
+// DefaultSourceRetries is the number of times a remote source resumes a failed read.
+const DefaultSourceRetries = 3
+
+// DefaultSourceRetryDelay is the delay before a remote source's first retry, doubling with each retry in a row.
+const DefaultSourceRetryDelay = 100 * time.Millisecond
+
+// HttpSource is a SourceOpener of "http://" and "https://" URLs.
+// The data is streamed with a series of Range requests of ChunkSize bytes, so a
+// failed transfer resumes where it left off.  Servers which ignore Range are read in one response.
+type HttpSource struct {
+	Context    context.Context // Context of each request and retry delay; context.Background() if nil
+	Client     *http.Client    // Client to make requests with; http.DefaultClient if nil
+	Header     http.Header     // Headers added to each request, such as Authorization
+	ChunkSize  int64           // Bytes per Range request; DefaultSourceChunkSize if 0
+	Retries    int             // Times to resume a failed read; DefaultSourceRetries if 0, none if negative
+	RetryDelay time.Duration   // Delay before the first retry, doubling with each retry in a row; DefaultSourceRetryDelay if 0
+}
+
+// OpenSource opens the URL for reading.  The first request is made before returning, so that
+// missing or forbidden URLs are reported immediately.
+func (s *HttpSource) OpenSource(url string) (io.ReadCloser, error) {
+	return newRangeReader(s.Context, s.Client, s.ChunkSize, s.Retries, s.RetryDelay, url, func(ctx context.Context, start, end int64) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range s.Header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		return req, nil
+	})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// rangeReader streams an HTTP resource with a series of Range requests.
+type rangeReader struct {
+	ctx        context.Context
+	client     *http.Client
+	newRequest func(ctx context.Context, start, end int64) (*http.Request, error)
+	name       string        // name of the resource for errors
+	chunkSize  int64         // bytes per request
+	retries    int           // resumes left
+	retryDelay time.Duration // delay before the first retry in a row
+	delay      time.Duration // delay before the next retry
+	offset     int64         // offset of the next byte to read
+	size       int64         // size of the resource, or -1 if unknown
+	chunkEnd   int64         // last offset requested of the current body
+	body       io.ReadCloser // current response body, or nil
+	done       bool          // true once the whole resource is read
+	resuming   bool          // true while the next fetch resumes a failed read
+}
+
+func newRangeReader(ctx context.Context, client *http.Client, chunkSize int64, retries int, retryDelay time.Duration, name string, newRequest func(ctx context.Context, start, end int64) (*http.Request, error)) (*rangeReader, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultSourceChunkSize
+	}
+	if retries == 0 {
+		retries = DefaultSourceRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+	if retryDelay <= 0 {
+		retryDelay = DefaultSourceRetryDelay
+	}
+	r := &rangeReader{
+		ctx:        ctx,
+		client:     client,
+		newRequest: newRequest,
+		name:       name,
+		chunkSize:  chunkSize,
+		retries:    retries,
+		retryDelay: retryDelay,
+		delay:      retryDelay,
+		size:       -1,
+	}
+	if err := r.fetch(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Read implements io.Reader, requesting the next range as each body ends.
+// A failed read is resumed at its offset, and each failure while resuming takes
+// one of the retries.  Each retry waits a delay which doubles with each retry in a row,
+// unless the context is cancelled first.  A whole-resource body, from a server ignoring
+// the Range, cannot be resumed, so its failure is returned.
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.done || (r.size >= 0 && r.offset >= r.size) {
+				return 0, io.EOF
+			}
+			if r.resuming {
+				if err := r.backoff(); err != nil {
+					return 0, err
+				}
+			}
+			if err := r.fetch(); err != nil {
+				if !r.resuming || r.retries <= 0 {
+					return 0, err
+				}
+				r.retries--
+				continue
+			}
+			r.resuming, r.delay = false, r.retryDelay
+			continue
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		switch {
+		case err == io.EOF:
+			r.body.Close()
+			r.body = nil
+			if r.offset <= r.chunkEnd && r.size < 0 {
+				r.done = true // a short range of an unknown size is the end
+			}
+		case err != nil:
+			r.body.Close()
+			r.body = nil
+			if r.chunkEnd < 0 || r.retries <= 0 {
+				return n, err
+			}
+			r.retries-- // resume from r.offset with the next fetch
+			r.resuming = true
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close implements io.Closer.
+func (r *rangeReader) Close() error {
+	r.done = true
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// backoff waits the delay before a retry and doubles it, returning the context's error if it is cancelled first.
+func (r *rangeReader) backoff() error {
+	timer := time.NewTimer(r.delay)
+	defer timer.Stop()
+	r.delay *= 2
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// fetch requests the range starting at r.offset, setting r.body, or r.done if there is no more data.
+func (r *rangeReader) fetch() error {
+	end := r.offset + r.chunkSize - 1
+	if r.size >= 0 && end >= r.size {
+		end = r.size - 1
+	}
+	req, err := r.newRequest(r.ctx, r.offset, end)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if size, ok := parseContentRangeSize(resp.Header.Get("Content-Range")); ok {
+			r.size = size
+		}
+		r.body, r.chunkEnd = resp.Body, end
+		return nil
+	case http.StatusOK:
+		// The server ignored the Range, so the body is the whole resource
+		if r.offset != 0 {
+			resp.Body.Close()
+			return fmt.Errorf("failed to resume '%s' at offset %d: server does not support Range requests", r.name, r.offset)
+		}
+		r.body, r.chunkEnd = resp.Body, -1
+		r.done = true
+		r.size = -1
+		return nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The offset is at the end, such as for an empty resource
+		resp.Body.Close()
+		r.done = true
+		return nil
+	default:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return &SourceStatusError{Name: r.name, StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(message))}
+	}
+}
+
+// parseContentRangeSize returns the complete length of a Content-Range header, "bytes 0-99/1234".
+func parseContentRangeSize(contentRange string) (int64, bool) {
+	_, sizeStr, found := strings.Cut(contentRange, "/")
+	if !found || sizeStr == "*" {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	return size, err == nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// SourceStatusError is returned when a remote source responds with an HTTP error status.
+type SourceStatusError struct {
+	Name       string // Name of the source
+	StatusCode int    // HTTP status code, such as 404
+	Status     string // HTTP status, such as "404 Not Found"
+	Message    string // Start of the response body, if any
+}
+
+func (e *SourceStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("failed to open '%s': %s", e.Name, e.Status)
+	}
+	return fmt.Sprintf("failed to open '%s': %s: %s", e.Name, e.Status, e.Message)
+}
+
+// IsSourceNotFound returns true if err is a missing local file or a remote 404.
+func IsSourceNotFound(err error) bool {
+	var statusErr *SourceStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusNotFound
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
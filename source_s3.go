@@ -0,0 +1,222 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// S3Source is a SourceOpener of "s3://bucket/key" URLs, over the S3 API of AWS or of an
+// S3-compatible store such as MinIO.  Requests are signed with AWS Signature Version 4,
+// and objects are streamed with Range requests, as with HttpSource.
+type S3Source struct {
+	Endpoint        string          // Endpoint URL, such as "http://localhost:9000"; AWS's regional endpoint if empty
+	Region          string          // Region to sign for; "us-east-1" if empty
+	AccessKeyID     string          // Access key; requests are unsigned if empty
+	SecretAccessKey string          // Secret key
+	SessionToken    string          // Session token of temporary credentials, if any
+	PathStyle       bool            // Address buckets as "endpoint/bucket/key" rather than "bucket.endpoint/key"; always with Endpoint
+	Context         context.Context // Context of each request and retry delay; context.Background() if nil
+	Client          *http.Client    // Client to make requests with; http.DefaultClient if nil
+	ChunkSize       int64           // Bytes per Range request; DefaultSourceChunkSize if 0
+	Retries         int             // Times to resume a failed read; DefaultSourceRetries if 0, none if negative
+	RetryDelay      time.Duration   // Delay before the first retry, doubling with each retry in a row; DefaultSourceRetryDelay if 0
+}
+
+// NewS3SourceFromEnv returns an S3Source configured from the standard AWS environment variables:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION or AWS_DEFAULT_REGION,
+// and AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL for S3-compatible stores.
+func NewS3SourceFromEnv() *S3Source {
+	s := &S3Source{
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+		Region:          os.Getenv("AWS_REGION"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if s.Endpoint == "" {
+		s.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if s.Region == "" {
+		s.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return s
+}
+
+// openS3SourceFromEnv is the built-in "s3" SourceOpener, reading the environment on each open.
+func openS3SourceFromEnv(name string) (io.ReadCloser, error) {
+	return NewS3SourceFromEnv().OpenSource(name)
+}
+
+// ParseS3URL splits an "s3://bucket/key" URL into its bucket and key.
+func ParseS3URL(s3URL string) (bucket string, key string, err error) {
+	rest, ok := strings.CutPrefix(s3URL, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("not an s3:// URL: '%s'", s3URL)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("s3 URL needs a bucket and key: '%s'", s3URL)
+	}
+	return bucket, key, nil
+}
+
+// ObjectURL returns the HTTP URL of an object.
+func (s *S3Source) ObjectURL(bucket string, key string) string {
+	path := s3EscapePath(key)
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + bucket + "/" + path
+	}
+	if s.PathStyle {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", s.region(), bucket, path)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, s.region(), path)
+}
+
+// OpenSource opens the "s3://bucket/key" object for reading.
+func (s *S3Source) OpenSource(name string) (io.ReadCloser, error) {
+	bucket, key, err := ParseS3URL(name)
+	if err != nil {
+		return nil, err
+	}
+	objectURL := s.ObjectURL(bucket, key)
+	return newRangeReader(s.Context, s.Client, s.ChunkSize, s.Retries, s.RetryDelay, name, func(ctx context.Context, start, end int64) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		s.SignRequest(req, time.Now())
+		return req, nil
+	})
+}
+
+func (s *S3Source) region() string {
+	if s.Region == "" {
+		return "us-east-1"
+	}
+	return s.Region
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// s3EmptyPayloadHash is the SHA-256 of an empty body, as signed for GET requests.
+const s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// SignRequest signs a bodiless request with AWS Signature Version 4 for the S3 service, as of now.
+// It sets the X-Amz-Date, X-Amz-Content-Sha256, X-Amz-Security-Token, and Authorization headers.
+// Nothing is signed without an AccessKeyID, for public buckets.
+func (s *S3Source) SignRequest(req *http.Request, now time.Time) {
+	if s.AccessKeyID == "" {
+		return
+	}
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3EmptyPayloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// Canonical headers are the host and all signed headers, lower-cased and sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lower := strings.ToLower(key)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3EmptyPayloadHash,
+	}, "\n")
+	scope := date + "/" + s.region() + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.region())
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery returns the query sorted by key, with values escaped as SigV4 requires.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath escapes an object key for its URL path, keeping its slashes.
+func s3EscapePath(key string) string {
+	return s3Escape(key, false)
+}
+
+// s3Escape percent-encodes all but the unreserved characters of RFC 3986, and slashes unless escapeSlash.
+func s3Escape(str string, escapeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || isDigit(c) ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// StatValue is the latest statistic of a StatType for an instrument and trading date.
+type StatValue struct {
+	Price    int64  // The value of price statistics, or UNDEF_PRICE
+	Quantity int64  // The value of non-price statistics, or StatMsgV3_UNDEF_STAT_QUANTITY
+	Flags    uint8  // The StatMsg's `stat_flags`, such as whether a settlement is final
+	TsEvent  uint64 // The StatMsg's `ts_event`
+	TsRecv   uint64 // The StatMsg's `ts_recv`
+	TsRef    uint64 // The StatMsg's `ts_ref`, or UNDEF_TIMESTAMP
+}
+
+// Value returns the statistic's value: its Quantity if the StatType IsQuantity, otherwise its Price.
+// Returns false if that is undefined.
+func (v StatValue) Value(statType StatType) (int64, bool) {
+	if statType.IsQuantity() {
+		return v.Quantity, v.Quantity != StatMsgV3_UNDEF_STAT_QUANTITY
+	}
+	return v.Price, v.Price != UNDEF_PRICE
+}
+
+// StatsRow is the statistics of an instrument on a trading date, one per StatType.
+type StatsRow struct {
+	InstrumentID uint32
+	Date         uint32 // Trading date, as YYYYMMDD
+	Stats        map[StatType]StatValue
+}
+
+// Get returns the row's statistic of the StatType.  Returns false if there is none.
+func (r *StatsRow) Get(statType StatType) (StatValue, bool) {
+	v, ok := r.Stats[statType]
+	return v, ok
+}
+
+// Value returns the value of the row's statistic of the StatType, as StatValue.Value.
+// Returns false if there is none.
+func (r *StatsRow) Value(statType StatType) (int64, bool) {
+	v, ok := r.Stats[statType]
+	if !ok {
+		return 0, false
+	}
+	return v.Value(statType)
+}
+
+// statsKey identifies a StatsRow.
+type statsKey struct {
+	instrumentID uint32
+	date         uint32
+}
+
+// StatsTable folds a stream of StatMsg records, which interleave many StatTypes, into a row per
+// instrument and trading date with the latest statistic of each StatType.
+//
+// A statistic's trading date is that of its `ts_ref` when set, as for settlements, open interest,
+// and cleared volume, and otherwise the session date of its `ts_recv`, or `ts_event` if unset.
+// Statistics replace earlier ones of the same row and StatType, unless they were received before
+// them.  StatUpdateAction_Delete removes the row's statistic of that StatType.
+type StatsTable struct {
+	rows        map[statsKey]*StatsRow
+	statTypes   map[StatType]int // StatType -> number of rows with it
+	sessionDate func(timestamp uint64) uint32
+}
+
+// NewStatsTable returns an empty StatsTable.  `sessionDate` returns the trading date, as YYYYMMDD,
+// of statistics without a `ts_ref`, such as a calendar's SessionDateOf; it is the UTC date if nil.
+func NewStatsTable(sessionDate func(timestamp uint64) uint32) *StatsTable {
+	if sessionDate == nil {
+		sessionDate = func(timestamp uint64) uint32 { return TimeToYMD(TimestampToTime(timestamp)) }
+	}
+	return &StatsTable{
+		rows:        make(map[statsKey]*StatsRow),
+		statTypes:   make(map[StatType]int),
+		sessionDate: sessionDate,
+	}
+}
+
+// IsEmpty returns true if there are no rows.
+func (st *StatsTable) IsEmpty() bool {
+	return len(st.rows) == 0
+}
+
+// Len returns the number of rows.
+func (st *StatsTable) Len() int {
+	return len(st.rows)
+}
+
+// StatDate returns the trading date, as YYYYMMDD, of a statistic's row.
+func (st *StatsTable) StatDate(stat *StatMsg) uint32 {
+	if stat.TsRef != 0 && stat.TsRef != UNDEF_TIMESTAMP {
+		return TimeToYMD(TimestampToTime(stat.TsRef))
+	}
+	ts := stat.TsRecv
+	if ts == 0 || ts == UNDEF_TIMESTAMP {
+		ts = stat.Header.TsEvent
+	}
+	return st.sessionDate(ts)
+}
+
+// Insert folds a statistic into its row, per its StatUpdateAction.
+func (st *StatsTable) Insert(stat *StatMsg) {
+	key := statsKey{stat.Header.InstrumentID, st.StatDate(stat)}
+	statType := StatType(stat.StatType)
+	row := st.rows[key]
+	if StatUpdateAction(stat.UpdateAction) == StatUpdateAction_Delete {
+		if row == nil {
+			return
+		}
+		if _, ok := row.Stats[statType]; ok {
+			delete(row.Stats, statType)
+			st.removeStatType(statType)
+		}
+		if len(row.Stats) == 0 {
+			delete(st.rows, key)
+		}
+		return
+	}
+
+	if row == nil {
+		row = &StatsRow{InstrumentID: key.instrumentID, Date: key.date, Stats: make(map[StatType]StatValue)}
+		st.rows[key] = row
+	}
+	prev, ok := row.Stats[statType]
+	if ok && stat.TsRecv < prev.TsRecv {
+		return
+	}
+	if !ok {
+		st.statTypes[statType]++
+	}
+	row.Stats[statType] = StatValue{
+		Price:    stat.Price,
+		Quantity: stat.Quantity,
+		Flags:    stat.StatFlags,
+		TsEvent:  stat.Header.TsEvent,
+		TsRecv:   stat.TsRecv,
+		TsRef:    stat.TsRef,
+	}
+}
+
+func (st *StatsTable) removeStatType(statType StatType) {
+	if st.statTypes[statType]--; st.statTypes[statType] <= 0 {
+		delete(st.statTypes, statType)
+	}
+}
+
+// OnStatMsg inserts the statistic, so a StatsTable may be fed by a Visitor.
+func (st *StatsTable) OnStatMsg(stat *StatMsg) error {
+	st.Insert(stat)
+	return nil
+}
+
+// ReadDbn inserts all the statistics records of a DBN stream, skipping other records.
+// V1 and V2 statistics are upgraded to V3.
+func (st *StatsTable) ReadDbn(scanner *DbnScanner) error {
+	if _, err := scanner.Metadata(); err != nil {
+		return err
+	}
+	for scanner.Next() {
+		if RType(scanner.GetLastRecord()[1]) != RType_Statistics {
+			continue
+		}
+		stat, err := scanner.DecodeStatMsg()
+		if err != nil {
+			return err
+		}
+		st.Insert(stat)
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// ReadDbnFile inserts all the statistics records of a DBN file; see ReadDbn.
+// Compression is detected from the file's contents; if useZstd is true, the file is always zstd-decompressed.
+func (st *StatsTable) ReadDbnFile(filename string, useZstd bool) error {
+	reader, closer, err := MakeCompressedReader(filename, useZstd)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	if err := st.ReadDbn(NewDbnScanner(reader)); err != nil {
+		return fmt.Errorf("failed to read statistics from '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// Get returns the row of the instrument on the trading date, as YYYYMMDD, or nil if there is none.
+func (st *StatsTable) Get(instrumentID uint32, date uint32) *StatsRow {
+	return st.rows[statsKey{instrumentID, date}]
+}
+
+// Rows returns all the rows, sorted by trading date and then instrument ID.
+func (st *StatsTable) Rows() []*StatsRow {
+	rows := make([]*StatsRow, 0, len(st.rows))
+	for _, row := range st.rows {
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b *StatsRow) int {
+		if c := cmp.Compare(a.Date, b.Date); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.InstrumentID, b.InstrumentID)
+	})
+	return rows
+}
+
+// StatTypes returns the sorted StatTypes present in any row, the columns of a table of the rows.
+func (st *StatsTable) StatTypes() []StatType {
+	statTypes := make([]StatType, 0, len(st.statTypes))
+	for statType := range st.statTypes {
+		statTypes = append(statTypes, statType)
+	}
+	slices.Sort(statTypes)
+	return statTypes
+}
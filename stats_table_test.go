@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestStat returns a statistic of an instrument received at `tsRecv`.
+func newTestStat(instrumentID uint32, tsRecv uint64, statType dbn.StatType, price int64, quantity int64) *dbn.StatMsg {
+	return &dbn.StatMsg{
+		Header: dbn.RHeader{
+			Length:       dbn.StatMsgV3_Size / 4,
+			RType:        dbn.RType_Statistics,
+			PublisherID:  1,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		TsRecv:       tsRecv,
+		TsRef:        dbn.UNDEF_TIMESTAMP,
+		Price:        price,
+		Quantity:     quantity,
+		StatType:     uint16(statType),
+		UpdateAction: uint8(dbn.StatUpdateAction_New),
+	}
+}
+
+// statsRowValue returns the row's value of the StatType, which must be defined.
+func statsRowValue(row *dbn.StatsRow, statType dbn.StatType) int64 {
+	value, ok := row.Value(statType)
+	Expect(ok).To(BeTrue())
+	return value
+}
+
+func statsTestTs(ymd int, hour int) uint64 {
+	return uint64(dbn.YMDToTime(ymd, time.UTC).Add(time.Duration(hour) * time.Hour).UnixNano())
+}
+
+var _ = Describe("StatsTable", func() {
+	const undefQty = dbn.StatMsgV3_UNDEF_STAT_QUANTITY
+
+	It("should name and classify stat types", func() {
+		Expect(dbn.StatType_SettlementPrice.String()).To(Equal("settlement_price"))
+		Expect(dbn.StatType_OpenInterest.String()).To(Equal("open_interest"))
+		Expect(dbn.StatType(999).String()).To(Equal(""))
+		Expect(dbn.StatType_OpenInterest.IsQuantity()).To(BeTrue())
+		Expect(dbn.StatType_ClearedVolume.IsQuantity()).To(BeTrue())
+		Expect(dbn.StatType_SettlementPrice.IsQuantity()).To(BeFalse())
+	})
+
+	It("should fold statistics into rows by instrument and date", func() {
+		st := dbn.NewStatsTable(nil)
+		Expect(st.IsEmpty()).To(BeTrue())
+		st.Insert(newTestStat(1, statsTestTs(20240102, 14), dbn.StatType_OpeningPrice, 100, undefQty))
+		st.Insert(newTestStat(1, statsTestTs(20240102, 15), dbn.StatType_TradingSessionHighPrice, 105, undefQty))
+		st.Insert(newTestStat(1, statsTestTs(20240102, 16), dbn.StatType_TradingSessionHighPrice, 107, undefQty))
+		st.Insert(newTestStat(2, statsTestTs(20240102, 14), dbn.StatType_OpeningPrice, 200, undefQty))
+		st.Insert(newTestStat(1, statsTestTs(20240103, 14), dbn.StatType_OpeningPrice, 110, undefQty))
+		Expect(st.Len()).To(Equal(3))
+
+		row := st.Get(1, 20240102)
+		Expect(row).NotTo(BeNil())
+		Expect(statsRowValue(row, dbn.StatType_TradingSessionHighPrice)).To(Equal(int64(107)))
+		Expect(statsRowValue(row, dbn.StatType_OpeningPrice)).To(Equal(int64(100)))
+		_, ok := row.Value(dbn.StatType_SettlementPrice)
+		Expect(ok).To(BeFalse())
+
+		rows := st.Rows()
+		Expect(rows).To(HaveLen(3))
+		Expect([]uint32{rows[0].InstrumentID, rows[1].InstrumentID, rows[2].InstrumentID}).To(Equal([]uint32{1, 2, 1}))
+		Expect(rows[2].Date).To(Equal(uint32(20240103)))
+		Expect(st.StatTypes()).To(Equal([]dbn.StatType{dbn.StatType_OpeningPrice, dbn.StatType_TradingSessionHighPrice}))
+	})
+
+	It("should date statistics by ts_ref", func() {
+		st := dbn.NewStatsTable(nil)
+		settlement := newTestStat(1, statsTestTs(20240103, 1), dbn.StatType_SettlementPrice, 4750, undefQty)
+		settlement.TsRef = statsTestTs(20240102, 0)
+		openInterest := newTestStat(1, statsTestTs(20240103, 9), dbn.StatType_OpenInterest, dbn.UNDEF_PRICE, 12345)
+		openInterest.TsRef = statsTestTs(20240102, 0)
+		st.Insert(settlement)
+		st.Insert(openInterest)
+
+		Expect(st.Len()).To(Equal(1))
+		row := st.Get(1, 20240102)
+		Expect(statsRowValue(row, dbn.StatType_SettlementPrice)).To(Equal(int64(4750)))
+		Expect(statsRowValue(row, dbn.StatType_OpenInterest)).To(Equal(int64(12345)))
+		value, ok := row.Get(dbn.StatType_OpenInterest)
+		Expect(ok).To(BeTrue())
+		Expect(value.TsRef).To(Equal(statsTestTs(20240102, 0)))
+	})
+
+	It("should date other statistics by session", func() {
+		// Sessions starting at 22:00 UTC belong to the next day
+		sessionDate := func(ts uint64) uint32 {
+			return dbn.TimeToYMD(dbn.TimestampToTime(ts).Add(2 * time.Hour))
+		}
+		st := dbn.NewStatsTable(sessionDate)
+		st.Insert(newTestStat(1, statsTestTs(20240102, 23), dbn.StatType_OpeningPrice, 100, undefQty))
+		Expect(st.Get(1, 20240103)).NotTo(BeNil())
+		Expect(st.Get(1, 20240102)).To(BeNil())
+	})
+
+	It("should honor deletes and keep the latest statistic", func() {
+		st := dbn.NewStatsTable(nil)
+		st.Insert(newTestStat(1, statsTestTs(20240102, 14), dbn.StatType_ClearedVolume, dbn.UNDEF_PRICE, 500))
+		st.Insert(newTestStat(1, statsTestTs(20240102, 15), dbn.StatType_OpenInterest, dbn.UNDEF_PRICE, 900))
+		// Received earlier, so it is stale
+		st.Insert(newTestStat(1, statsTestTs(20240102, 13), dbn.StatType_OpenInterest, dbn.UNDEF_PRICE, 800))
+		Expect(statsRowValue(st.Get(1, 20240102), dbn.StatType_OpenInterest)).To(Equal(int64(900)))
+
+		deleted := newTestStat(1, statsTestTs(20240102, 16), dbn.StatType_ClearedVolume, dbn.UNDEF_PRICE, undefQty)
+		deleted.UpdateAction = uint8(dbn.StatUpdateAction_Delete)
+		st.Insert(deleted)
+		_, ok := st.Get(1, 20240102).Get(dbn.StatType_ClearedVolume)
+		Expect(ok).To(BeFalse())
+		Expect(st.StatTypes()).To(Equal([]dbn.StatType{dbn.StatType_OpenInterest}))
+
+		deleted.StatType = uint16(dbn.StatType_OpenInterest)
+		Expect(st.OnStatMsg(deleted)).To(Succeed())
+		Expect(st.IsEmpty()).To(BeTrue())
+		Expect(st.StatTypes()).To(BeEmpty())
+	})
+
+	It("should read statistics files of every version", func() {
+		for _, filename := range []string{"test_data.statistics.v1.dbn.zst", "test_data.statistics.v2.dbn.zst", "test_data.statistics.v3.dbn.zst"} {
+			st := dbn.NewStatsTable(nil)
+			Expect(st.ReadDbnFile("./tests/data/"+filename, false)).To(Succeed())
+			Expect(st.IsEmpty()).To(BeFalse(), filename)
+			Expect(st.StatTypes()).NotTo(BeEmpty(), filename)
+		}
+	})
+})
@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"time"
 
 	"github.com/valyala/fastjson"
 	"github.com/valyala/fastjson/fastfloat"
@@ -29,8 +30,11 @@ import (
 
 ///////////////////////////////////////////////////////////////////////////////
 
-// Interface Type for Record Decoding
+// Record is a decoded DBN record, such as *Mbp0Msg, with access to its common header.
+// DbnScanner.DecodeAny and JsonScanner.DecodeAny return the concrete record as a Record;
+// use a type switch, or VisitRecord, to handle it.
 type Record interface {
+	GetHeader() RHeader
 }
 
 type RecordPtr[T any] interface {
@@ -43,40 +47,139 @@ type RecordPtr[T any] interface {
 	Fill_Json(val *fastjson.Value, header *RHeader) error
 }
 
-// Decodes a fastjson.Value string as an int64
+// Decodes a fastjson.Value string as an int64.
+// Bare numbers are also accepted, as emitted by encoding/json.
 func fastjson_GetInt64FromString(val *fastjson.Value, key string) int64 {
-	return fastfloat.ParseInt64BestEffort(string(val.GetStringBytes(key)))
+	return fastjson_GetInt64Tolerant(val, key)
 }
 
 // Decodes a fastjson.Value string as an uint64
+// Bare numbers are also accepted, as emitted by encoding/json.
 func fastjson_GetUint64FromString(val *fastjson.Value, key string) uint64 {
-	return fastfloat.ParseUint64BestEffort(string(val.GetStringBytes(key)))
+	return fastjson_GetUint64Tolerant(val, key)
 }
 
 // Decodes a fastjson.Value as int64, tolerant of both quoted strings (V3) and bare numbers (V2).
+// Decimal strings, as written with `pretty_px`, decode as a Price.
+// A null value decodes as UNDEF_PRICE, the sentinel of all int64 fields.
 func fastjson_GetInt64Tolerant(val *fastjson.Value, key string) int64 {
 	v := val.Get(key)
 	if v == nil {
 		return 0
 	}
+	if v.Type() == fastjson.TypeNull {
+		return UNDEF_PRICE
+	}
 	if v.Type() == fastjson.TypeString {
-		return fastfloat.ParseInt64BestEffort(string(v.GetStringBytes()))
+		return parseInt64OrPrice(v.GetStringBytes())
 	}
 	return v.GetInt64()
 }
 
 // Decodes a fastjson.Value as uint64, tolerant of both quoted strings (V3) and bare numbers (V2).
+// ISO 8601 strings, as written with `pretty_ts`, decode as a timestamp.
+// A null value decodes as UNDEF_TIMESTAMP, the sentinel of all uint64 fields.
 func fastjson_GetUint64Tolerant(val *fastjson.Value, key string) uint64 {
 	v := val.Get(key)
 	if v == nil {
 		return 0
 	}
+	if v.Type() == fastjson.TypeNull {
+		return UNDEF_TIMESTAMP
+	}
 	if v.Type() == fastjson.TypeString {
-		return fastfloat.ParseUint64BestEffort(string(v.GetStringBytes()))
+		return parseUint64OrTimestamp(v.GetStringBytes())
 	}
 	return v.GetUint64()
 }
 
+// parseInt64OrPrice parses an integer string, or a decimal string as a Price.
+func parseInt64OrPrice(str []byte) int64 {
+	if bytes.IndexByte(str, '.') >= 0 {
+		if price, err := ParsePrice(string(str)); err == nil {
+			return int64(price)
+		}
+	}
+	return fastfloat.ParseInt64BestEffort(string(str))
+}
+
+// parseUint64OrTimestamp parses an integer string, or an ISO 8601 string as a UNIX nanosecond timestamp.
+func parseUint64OrTimestamp(str []byte) uint64 {
+	if bytes.IndexByte(str, 'T') >= 0 {
+		if t, err := time.Parse(time.RFC3339Nano, string(str)); err == nil {
+			return uint64(t.UnixNano())
+		}
+	}
+	return fastfloat.ParseUint64BestEffort(string(str))
+}
+
+// Decodes a fastjson.Value c_char, tolerant of both strings ("A") and numbers (65).
+func fastjson_GetChar(val *fastjson.Value, key string) byte {
+	v := val.Get(key)
+	if v == nil {
+		return 0
+	}
+	if v.Type() == fastjson.TypeString {
+		if str := v.GetStringBytes(); len(str) > 0 {
+			return str[0]
+		}
+		return 0
+	}
+	return byte(v.GetUint())
+}
+
+// Decodes a fastjson.Value into the fixed-length c-string dst, tolerant of
+// both strings and arrays of bytes (as encoding/json marshals byte arrays).
+func fastjson_GetCStr(val *fastjson.Value, key string, dst []byte) {
+	v := val.Get(key)
+	if v == nil {
+		return
+	}
+	if v.Type() == fastjson.TypeString {
+		copy(dst, v.GetStringBytes())
+		return
+	}
+	for i, b := range v.GetArray() {
+		if i >= len(dst) {
+			break
+		}
+		dst[i] = byte(b.GetUint())
+	}
+}
+
+// Decodes a fastjson.Value SType, tolerant of both names ("raw_symbol") and numbers.
+func fastjson_GetSType(val *fastjson.Value, key string) SType {
+	v := val.Get(key)
+	if v == nil {
+		return 0
+	}
+	if v.Type() == fastjson.TypeString {
+		stype, _ := STypeFromString(string(v.GetStringBytes()))
+		return stype
+	}
+	return SType(v.GetUint())
+}
+
+// Returns the i'th BidAskPair fastjson.Value of "levels", tolerant of
+// both arrays and a single object (as encoding/json marshals one level).
+func fastjson_GetLevel(val *fastjson.Value, i int) *fastjson.Value {
+	levels := val.Get("levels")
+	if levels == nil {
+		return nil
+	}
+	if levels.Type() == fastjson.TypeObject {
+		if i == 0 {
+			return levels
+		}
+		return nil
+	}
+	arr := levels.GetArray()
+	if i >= len(arr) {
+		return nil
+	}
+	return arr[i]
+}
+
 func (rtype RType) IsCompatibleWith(rtype2 RType) bool {
 	// If they are equal, they are compatible
 	if rtype == rtype2 {
@@ -104,6 +207,53 @@ func (rtype RType) IsBbo() bool {
 	}
 }
 
+// Schema returns the Schema which produces records of this RType, or Schema_Mixed if there is none.
+// RType_Mbp1 is shared by Mbp1 and Tbbo; Schema_Mbp1 is returned.
+func (rtype RType) Schema() Schema {
+	switch rtype {
+	case RType_Mbp0:
+		return Schema_Trades
+	case RType_Mbp1:
+		return Schema_Mbp1
+	case RType_Mbp10:
+		return Schema_Mbp10
+	case RType_Mbo:
+		return Schema_Mbo
+	case RType_Ohlcv1S:
+		return Schema_Ohlcv1S
+	case RType_Ohlcv1M:
+		return Schema_Ohlcv1M
+	case RType_Ohlcv1H:
+		return Schema_Ohlcv1H
+	case RType_Ohlcv1D:
+		return Schema_Ohlcv1D
+	case RType_OhlcvEod:
+		return Schema_OhlcvEod
+	case RType_Status:
+		return Schema_Status
+	case RType_InstrumentDef:
+		return Schema_Definition
+	case RType_Imbalance:
+		return Schema_Imbalance
+	case RType_Statistics:
+		return Schema_Statistics
+	case RType_Cmbp1:
+		return Schema_Cmbp1
+	case RType_Cbbo1S:
+		return Schema_Cbbo1S
+	case RType_Cbbo1M:
+		return Schema_Cbbo1M
+	case RType_Tcbbo:
+		return Schema_Tcbbo
+	case RType_Bbo1S:
+		return Schema_Bbo1S
+	case RType_Bbo1M:
+		return Schema_Bbo1M
+	default:
+		return Schema_Mixed
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 // Databento Normalized Record Header
@@ -138,6 +288,20 @@ func (h *RHeader) Fill_Raw(b []byte) error {
 	return nil
 }
 
+// Encode_Raw writes the RHeader to b, the inverse of Fill_Raw.
+// Records' Encode_Raw overwrite Length with their own size.
+func (h *RHeader) Encode_Raw(b []byte) error {
+	if len(b) < RHeader_Size {
+		return unexpectedBytesError(len(b), RHeader_Size)
+	}
+	b[0] = h.Length
+	b[1] = uint8(h.RType)
+	binary.LittleEndian.PutUint16(b[2:4], h.PublisherID)
+	binary.LittleEndian.PutUint32(b[4:8], h.InstrumentID)
+	binary.LittleEndian.PutUint64(b[8:16], h.TsEvent)
+	return nil
+}
+
 func (h *RHeader) Fill_Json(val *fastjson.Value) error {
 	h.TsEvent = fastjson_GetUint64FromString(val, "ts_event")
 	h.PublisherID = uint16(val.GetUint("publisher_id"))
@@ -169,6 +333,16 @@ func (p *BidAskPair) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (p *BidAskPair) Encode_Raw(b []byte) error {
+	binary.LittleEndian.PutUint64(b[0:8], uint64(p.BidPx))
+	binary.LittleEndian.PutUint64(b[8:16], uint64(p.AskPx))
+	binary.LittleEndian.PutUint32(b[16:20], p.BidSz)
+	binary.LittleEndian.PutUint32(b[20:24], p.AskSz)
+	binary.LittleEndian.PutUint32(b[24:28], p.BidCt)
+	binary.LittleEndian.PutUint32(b[28:32], p.AskCt)
+	return nil
+}
+
 func (p *BidAskPair) Fill_Json(val *fastjson.Value) error {
 	p.BidPx = fastjson_GetInt64FromString(val, "bid_px")
 	p.AskPx = fastjson_GetInt64FromString(val, "ask_px")
@@ -207,6 +381,18 @@ func (p *ConsolidatedBidAskPair) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (p *ConsolidatedBidAskPair) Encode_Raw(b []byte) error {
+	binary.LittleEndian.PutUint64(b[0:8], uint64(p.BidPx))
+	binary.LittleEndian.PutUint64(b[8:16], uint64(p.AskPx))
+	binary.LittleEndian.PutUint32(b[16:20], p.BidSz)
+	binary.LittleEndian.PutUint32(b[20:24], p.AskSz)
+	binary.LittleEndian.PutUint16(b[24:26], p.BidPb)
+	// Reserved1 26:28
+	binary.LittleEndian.PutUint16(b[28:30], p.AskPb)
+	// Reserved2 30:32
+	return nil
+}
+
 func (p *ConsolidatedBidAskPair) Fill_Json(val *fastjson.Value) error {
 	p.BidPx = fastjson_GetInt64FromString(val, "bid_px")
 	p.AskPx = fastjson_GetInt64FromString(val, "ask_px")
@@ -244,6 +430,10 @@ func (*Mbp0Msg) RSize() uint16 {
 	return Mbp0Msg_Size
 }
 
+func (r Mbp0Msg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *Mbp0Msg) Fill_Raw(b []byte) error {
 	if len(b) < Mbp0Msg_Size {
 		return unexpectedBytesError(len(b), Mbp0Msg_Size)
@@ -265,12 +455,35 @@ func (r *Mbp0Msg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *Mbp0Msg) Encode_Raw(b []byte) error {
+	if len(b) < Mbp0Msg_Size {
+		return unexpectedBytesError(len(b), Mbp0Msg_Size)
+	}
+	clear(b[:Mbp0Msg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(Mbp0Msg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[8:12], r.Size)
+	body[12] = r.Action
+	body[13] = r.Side
+	body[14] = r.Flags
+	body[15] = r.Depth
+	binary.LittleEndian.PutUint64(body[16:24], r.TsRecv)
+	binary.LittleEndian.PutUint32(body[24:28], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	return nil
+}
+
 func (r *Mbp0Msg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Size = uint32(val.GetUint("size"))
-	r.Action = uint8(val.GetUint("action"))
-	r.Side = uint8(val.GetUint("side"))
+	r.Action = fastjson_GetChar(val, "action")
+	r.Side = fastjson_GetChar(val, "side")
 	r.Flags = uint8(val.GetUint("flags"))
 	r.Depth = uint8(val.GetUint("depth"))
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -307,6 +520,10 @@ func (*MboMsg) RSize() uint16 {
 	return MboMsg_Size
 }
 
+func (r MboMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *MboMsg) Fill_Raw(b []byte) error {
 	if len(b) < MboMsg_Size {
 		return unexpectedBytesError(len(b), MboMsg_Size)
@@ -329,6 +546,30 @@ func (r *MboMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *MboMsg) Encode_Raw(b []byte) error {
+	if len(b) < MboMsg_Size {
+		return unexpectedBytesError(len(b), MboMsg_Size)
+	}
+	clear(b[:MboMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(MboMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], r.OrderID)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[16:20], r.Size)
+	body[20] = r.Flags
+	body[21] = r.ChannelID
+	body[22] = r.Action
+	body[23] = r.Side
+	binary.LittleEndian.PutUint64(body[24:32], r.TsRecv)
+	binary.LittleEndian.PutUint32(body[32:36], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint32(body[36:40], r.Sequence)
+	return nil
+}
+
 func (r *MboMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.OrderID = fastjson_GetUint64FromString(val, "order_id")
@@ -336,10 +577,10 @@ func (r *MboMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Size = uint32(val.GetUint("size"))
 	r.Flags = uint8(val.GetUint("flags"))
 	r.ChannelID = uint8(val.GetUint("channel_id"))
-	r.Action = byte(val.GetUint("action"))
-	r.Side = byte(val.GetUint("side"))
+	r.Action = fastjson_GetChar(val, "action")
+	r.Side = fastjson_GetChar(val, "side")
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.Sequence = uint32(val.GetUint("sequence"))
 	return nil
 }
@@ -371,6 +612,10 @@ func (*Mbp1Msg) RSize() uint16 {
 	return Mbp1Msg_Size
 }
 
+func (r Mbp1Msg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *Mbp1Msg) Fill_Raw(b []byte) error {
 	if len(b) < Mbp1Msg_Size {
 		return unexpectedBytesError(len(b), Mbp1Msg_Size)
@@ -393,22 +638,46 @@ func (r *Mbp1Msg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *Mbp1Msg) Encode_Raw(b []byte) error {
+	if len(b) < Mbp1Msg_Size {
+		return unexpectedBytesError(len(b), Mbp1Msg_Size)
+	}
+	clear(b[:Mbp1Msg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(Mbp1Msg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[8:12], r.Size)
+	body[12] = r.Action
+	body[13] = r.Side
+	body[14] = r.Flags
+	body[15] = r.Depth
+	binary.LittleEndian.PutUint64(body[16:24], r.TsRecv)
+	binary.LittleEndian.PutUint32(body[24:28], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	r.Level.Encode_Raw(body[32 : 32+BidAskPair_Size])
+	return nil
+}
+
 func (r *Mbp1Msg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Size = uint32(val.GetUint("size"))
-	r.Action = byte(val.GetUint("action"))
-	r.Side = byte(val.GetUint("side"))
+	r.Action = fastjson_GetChar(val, "action")
+	r.Side = fastjson_GetChar(val, "side")
 	r.Flags = uint8(val.GetUint("flags"))
 	r.Depth = uint8(val.GetUint("depth"))
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.Sequence = uint32(val.GetUint("sequence"))
-	levelsArr := val.GetArray("levels")
-	if len(levelsArr) == 0 {
+	level := fastjson_GetLevel(val, 0)
+	if level == nil {
 		return errors.New("levels array is empty")
 	}
-	r.Level.Fill_Json(levelsArr[0])
+	r.Level.Fill_Json(level)
 	return nil
 }
 
@@ -440,6 +709,10 @@ func (*Cmbp1Msg) RSize() uint16 {
 	return Cmbp1Msg_Size
 }
 
+func (r Cmbp1Msg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *Cmbp1Msg) Fill_Raw(b []byte) error {
 	if len(b) < Cmbp1Msg_Size {
 		return unexpectedBytesError(len(b), Cmbp1Msg_Size)
@@ -461,21 +734,44 @@ func (r *Cmbp1Msg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *Cmbp1Msg) Encode_Raw(b []byte) error {
+	if len(b) < Cmbp1Msg_Size {
+		return unexpectedBytesError(len(b), Cmbp1Msg_Size)
+	}
+	clear(b[:Cmbp1Msg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(Cmbp1Msg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[8:12], r.Size)
+	body[12] = r.Action
+	body[13] = r.Side
+	body[14] = r.Flags
+	binary.LittleEndian.PutUint64(body[16:24], r.TsRecv)
+	binary.LittleEndian.PutUint32(body[24:28], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	r.Level.Encode_Raw(body[32 : 32+ConsolidatedBidAskPair_Size])
+	return nil
+}
+
 func (r *Cmbp1Msg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Size = uint32(val.GetUint("size"))
-	r.Action = byte(val.GetUint("action"))
-	r.Side = byte(val.GetUint("side"))
+	r.Action = fastjson_GetChar(val, "action")
+	r.Side = fastjson_GetChar(val, "side")
 	r.Flags = uint8(val.GetUint("flags"))
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.Sequence = uint32(val.GetUint("sequence"))
-	levelsArr := val.GetArray("levels")
-	if len(levelsArr) == 0 {
+	level := fastjson_GetLevel(val, 0)
+	if level == nil {
 		return errors.New("levels array is empty")
 	}
-	r.Level.Fill_Json(levelsArr[0])
+	r.Level.Fill_Json(level)
 	return nil
 }
 
@@ -506,6 +802,10 @@ func (*Mbp10Msg) RSize() uint16 {
 	return Mbp10Msg_Size
 }
 
+func (r Mbp10Msg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *Mbp10Msg) Fill_Raw(b []byte) error {
 	if len(b) < Mbp10Msg_Size {
 		return unexpectedBytesError(len(b), Mbp10Msg_Size)
@@ -531,23 +831,49 @@ func (r *Mbp10Msg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *Mbp10Msg) Encode_Raw(b []byte) error {
+	if len(b) < Mbp10Msg_Size {
+		return unexpectedBytesError(len(b), Mbp10Msg_Size)
+	}
+	clear(b[:Mbp10Msg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(Mbp10Msg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[8:12], r.Size)
+	body[12] = r.Action
+	body[13] = r.Side
+	body[14] = r.Flags
+	body[15] = r.Depth
+	binary.LittleEndian.PutUint64(body[16:24], r.TsRecv)
+	binary.LittleEndian.PutUint32(body[24:28], uint32(r.TsInDelta))
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	for i := 0; i < 10; i++ {
+		offset := 32 + i*BidAskPair_Size
+		r.Levels[i].Encode_Raw(body[offset : offset+BidAskPair_Size])
+	}
+	return nil
+}
+
 func (r *Mbp10Msg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Size = uint32(val.GetUint("size"))
-	r.Action = byte(val.GetUint("action"))
-	r.Side = byte(val.GetUint("side"))
+	r.Action = fastjson_GetChar(val, "action")
+	r.Side = fastjson_GetChar(val, "side")
 	r.Flags = uint8(val.GetUint("flags"))
 	r.Depth = uint8(val.GetUint("depth"))
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
-	r.TsInDelta = int32(val.GetUint("ts_in_delta"))
+	r.TsInDelta = int32(val.GetInt("ts_in_delta"))
 	r.Sequence = uint32(val.GetUint("sequence"))
-	levelsArr := val.GetArray("levels")
-	if len(levelsArr) < 10 {
+	if len(val.GetArray("levels")) < 10 {
 		return errors.New("levels array is less than 10")
 	}
 	for i := 0; i < 10; i++ {
-		r.Levels[i].Fill_Json(levelsArr[i])
+		r.Levels[i].Fill_Json(fastjson_GetLevel(val, i))
 	}
 	return nil
 }
@@ -576,6 +902,10 @@ func (*OhlcvMsg) RSize() uint16 {
 	return OhlcvMsg_Size
 }
 
+func (r OhlcvMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *OhlcvMsg) Fill_Raw(b []byte) error {
 	if len(b) < OhlcvMsg_Size {
 		return unexpectedBytesError(len(b), OhlcvMsg_Size)
@@ -593,6 +923,25 @@ func (r *OhlcvMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *OhlcvMsg) Encode_Raw(b []byte) error {
+	if len(b) < OhlcvMsg_Size {
+		return unexpectedBytesError(len(b), OhlcvMsg_Size)
+	}
+	clear(b[:OhlcvMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(OhlcvMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Open))
+	binary.LittleEndian.PutUint64(body[8:16], uint64(r.High))
+	binary.LittleEndian.PutUint64(body[16:24], uint64(r.Low))
+	binary.LittleEndian.PutUint64(body[24:32], uint64(r.Close))
+	binary.LittleEndian.PutUint64(body[32:40], r.Volume)
+	return nil
+}
+
 func (r *OhlcvMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Open = fastjson_GetInt64FromString(val, "open")
@@ -642,6 +991,10 @@ func (*ImbalanceMsg) RSize() uint16 {
 	return ImbalanceMsg_Size
 }
 
+func (r ImbalanceMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *ImbalanceMsg) Fill_Raw(b []byte) error {
 	if len(b) < ImbalanceMsg_Size {
 		return unexpectedBytesError(len(b), ImbalanceMsg_Size)
@@ -675,6 +1028,41 @@ func (r *ImbalanceMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *ImbalanceMsg) Encode_Raw(b []byte) error {
+	if len(b) < ImbalanceMsg_Size {
+		return unexpectedBytesError(len(b), ImbalanceMsg_Size)
+	}
+	clear(b[:ImbalanceMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(ImbalanceMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint64(body[8:16], uint64(r.RefPrice))
+	binary.LittleEndian.PutUint64(body[16:24], r.AuctionTime)
+	binary.LittleEndian.PutUint64(body[24:32], uint64(r.ContBookClrPrice))
+	binary.LittleEndian.PutUint64(body[32:40], uint64(r.AuctInterestClrPrice))
+	binary.LittleEndian.PutUint64(body[40:48], uint64(r.SsrFillingPrice))
+	binary.LittleEndian.PutUint64(body[48:56], uint64(r.IndMatchPrice))
+	binary.LittleEndian.PutUint64(body[56:64], uint64(r.UpperCollar))
+	binary.LittleEndian.PutUint64(body[64:72], uint64(r.LowerCollar))
+	binary.LittleEndian.PutUint32(body[72:76], r.PairedQty)
+	binary.LittleEndian.PutUint32(body[76:80], r.TotalImbalanceQty)
+	binary.LittleEndian.PutUint32(body[80:84], r.MarketImbalanceQty)
+	binary.LittleEndian.PutUint32(body[84:88], uint32(r.UnpairedQty))
+	body[88] = r.AuctionType
+	body[89] = r.Side
+	body[90] = r.AuctionStatus
+	body[91] = r.FreezeStatus
+	body[92] = r.NumExtensions
+	body[93] = r.UnpairedSide
+	body[94] = r.SignificantImbalance
+	body[95] = r.Reserved
+	return nil
+}
+
 func (r *ImbalanceMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -689,14 +1077,14 @@ func (r *ImbalanceMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.PairedQty = uint32(val.GetUint("paired_qty"))
 	r.TotalImbalanceQty = uint32(val.GetUint("total_imbalance_qty"))
 	r.MarketImbalanceQty = uint32(val.GetUint("market_imbalance_qty"))
-	r.UnpairedQty = int32(val.GetUint("unpaired_qty"))
-	r.AuctionType = uint8(val.GetUint("auction_type"))
-	r.Side = uint8(val.GetUint("side"))
+	r.UnpairedQty = int32(val.GetInt("unpaired_qty"))
+	r.AuctionType = fastjson_GetChar(val, "auction_type")
+	r.Side = fastjson_GetChar(val, "side")
 	r.AuctionStatus = uint8(val.GetUint("auction_status"))
 	r.FreezeStatus = uint8(val.GetUint("freeze_status"))
 	r.NumExtensions = uint8(val.GetUint("num_extensions"))
-	r.UnpairedSide = uint8(val.GetUint("unpaired_side"))
-	r.SignificantImbalance = uint8(val.GetUint("significant_imbalance"))
+	r.UnpairedSide = fastjson_GetChar(val, "unpaired_side")
+	r.SignificantImbalance = fastjson_GetChar(val, "significant_imbalance")
 	r.Reserved = uint8(val.GetUint("reserved"))
 	return nil
 }
@@ -719,6 +1107,10 @@ func (*ErrorMsg) RSize() uint16 {
 	return ErrorMsg_Size
 }
 
+func (r ErrorMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *ErrorMsg) Fill_Raw(b []byte) error {
 	if len(b) < ErrorMsg_Size {
 		return unexpectedBytesError(len(b), ErrorMsg_Size)
@@ -734,10 +1126,31 @@ func (r *ErrorMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *ErrorMsg) Encode_Raw(b []byte) error {
+	if len(b) < ErrorMsg_Size {
+		return unexpectedBytesError(len(b), ErrorMsg_Size)
+	}
+	clear(b[:ErrorMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(ErrorMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	copy(body[:ErrorMsg_ErrSize], r.Error[:])
+	body[ErrorMsg_ErrSize] = byte(r.Code)
+	body[ErrorMsg_ErrSize+1] = r.IsLast
+	return nil
+}
+
 func (r *ErrorMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
-	copy(r.Error[:], val.GetStringBytes("err"))
-	r.Code = ErrorCode(uint8(val.GetUint("code")))
+	fastjson_GetCStr(val, "err", r.Error[:])
+	if code := val.Get("code"); code != nil && code.Type() == fastjson.TypeString {
+		r.Code, _ = ErrorCodeFromString(string(code.GetStringBytes()))
+	} else {
+		r.Code = ErrorCode(uint8(val.GetUint("code")))
+	}
 	r.IsLast = uint8(val.GetUint("is_last"))
 	return nil
 }
@@ -761,6 +1174,10 @@ func (*SystemMsg) RSize() uint16 {
 	return SystemMsg_Size
 }
 
+func (r SystemMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *SystemMsg) Fill_Raw(b []byte) error {
 	if len(b) < SystemMsg_Size {
 		return unexpectedBytesError(len(b), SystemMsg_Size)
@@ -775,10 +1192,30 @@ func (r *SystemMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *SystemMsg) Encode_Raw(b []byte) error {
+	if len(b) < SystemMsg_Size {
+		return unexpectedBytesError(len(b), SystemMsg_Size)
+	}
+	clear(b[:SystemMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(SystemMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	copy(body[:SystemMsg_MsgSize], r.Message[:])
+	body[SystemMsg_MsgSize] = byte(r.Code)
+	return nil
+}
+
 func (r *SystemMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
-	copy(r.Message[:], val.GetStringBytes("msg"))
-	r.Code = SystemCode(uint8(val.GetUint("code")))
+	fastjson_GetCStr(val, "msg", r.Message[:])
+	if code := val.Get("code"); code != nil && code.Type() == fastjson.TypeString {
+		r.Code, _ = SystemCodeFromString(string(code.GetStringBytes()))
+	} else {
+		r.Code = SystemCode(uint8(val.GetUint("code")))
+	}
 	return nil
 }
 
@@ -820,6 +1257,10 @@ func (*StatusMsg) RSize() uint16 {
 	return StatusMsg_Size
 }
 
+func (r StatusMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *StatusMsg) Fill_Raw(b []byte) error {
 	if len(b) < StatusMsg_Size {
 		return unexpectedBytesError(len(b), StatusMsg_Size)
@@ -839,6 +1280,27 @@ func (r *StatusMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *StatusMsg) Encode_Raw(b []byte) error {
+	if len(b) < StatusMsg_Size {
+		return unexpectedBytesError(len(b), StatusMsg_Size)
+	}
+	clear(b[:StatusMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(StatusMsg_Size / 4)
+	body := b[RHeader_Size:] // slice of just the body
+	binary.LittleEndian.PutUint64(body[0:8], r.TsRecv)
+	binary.LittleEndian.PutUint16(body[8:10], r.Action)
+	binary.LittleEndian.PutUint16(body[10:12], r.Reason)
+	binary.LittleEndian.PutUint16(body[12:14], r.TradingEvent)
+	body[14] = r.IsTrading
+	body[15] = r.IsQuoting
+	body[16] = r.IsShortSellRestricted
+	return nil
+}
+
 func (r *StatusMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
@@ -880,6 +1342,10 @@ func (*BboMsg) RSize() uint16 {
 	return BboMsg_Size
 }
 
+func (r BboMsg) GetHeader() RHeader {
+	return r.Header
+}
+
 func (r *BboMsg) Fill_Raw(b []byte) error {
 	if len(b) < BboMsg_Size {
 		return unexpectedBytesError(len(b), BboMsg_Size)
@@ -903,16 +1369,41 @@ func (r *BboMsg) Fill_Raw(b []byte) error {
 	return nil
 }
 
+func (r *BboMsg) Encode_Raw(b []byte) error {
+	if len(b) < BboMsg_Size {
+		return unexpectedBytesError(len(b), BboMsg_Size)
+	}
+	clear(b[:BboMsg_Size])
+	err := r.Header.Encode_Raw(b[0:RHeader_Size])
+	if err != nil {
+		return err
+	}
+	b[0] = uint8(BboMsg_Size / 4)
+	body := b[RHeader_Size:]
+
+	binary.LittleEndian.PutUint64(body[0:8], uint64(r.Price))
+	binary.LittleEndian.PutUint32(body[8:12], r.Size)
+	// Reserved1 12
+	body[13] = r.Side
+	body[14] = r.Flags
+	// Reserved2 15
+	binary.LittleEndian.PutUint64(body[16:24], r.TsRecv)
+	// Reserved3 24:28
+	binary.LittleEndian.PutUint32(body[28:32], r.Sequence)
+	r.Level.Encode_Raw(body[32 : 32+BidAskPair_Size])
+	return nil
+}
+
 func (r *BboMsg) Fill_Json(val *fastjson.Value, header *RHeader) error {
 	r.Header = *header
 	r.Price = fastjson_GetInt64FromString(val, "price")
 	r.Size = uint32(val.GetUint("size"))
-	r.Side = byte(val.Get("side").String()[1]) // Get the first character from the JSON string
+	r.Side = fastjson_GetChar(val, "side")
 	r.Flags = uint8(val.GetUint("flags"))
 	r.TsRecv = fastjson_GetUint64FromString(val, "ts_recv")
 	r.Sequence = uint32(val.GetUint("sequence"))
 
-	levels := val.Get("levels")
+	levels := fastjson_GetLevel(val, 0)
 	if levels != nil {
 		r.Level.BidPx = fastjson_GetInt64FromString(levels, "bid_px")
 		r.Level.AskPx = fastjson_GetInt64FromString(levels, "ask_px")
@@ -979,3 +1470,31 @@ func SymbolMappingMsgFillRaw(r *SymbolMappingMsgV2, b []byte, cstrLength uint16)
 	}
 
 }
+
+// SymbolMappingMsgEncodeRaw writes a SymbolMappingMsg to raw bytes based on DBN version.
+// It is the inverse of SymbolMappingMsgFillRaw.  Returns the number of bytes written.
+func SymbolMappingMsgEncodeRaw(r *SymbolMappingMsgV2, b []byte, cstrLength uint16) (int, error) {
+	if cstrLength == MetadataV1_SymbolCstrLen {
+		v1 := SymbolMappingMsgV1{
+			Header:         r.Header,
+			StypeIn:        r.StypeIn,
+			StypeInSymbol:  r.StypeInSymbol,
+			StypeOut:       r.StypeOut,
+			StypeOutSymbol: r.StypeOutSymbol,
+			StartTs:        r.StartTs,
+			EndTs:          r.EndTs,
+		}
+		return SymbolMappingMsgV1_Size, v1.Encode_Raw(b)
+	} else if cstrLength == MetadataV2_SymbolCstrLen {
+		return SymbolMappingMsgV2_Size, r.Encode_Raw(b)
+	} else {
+		return 0, unexpectedCStrLenError(cstrLength)
+	}
+}
+
+// encodeCStr copies str into the fixed-length c-string b, truncating
+// as needed to leave room for the null terminator.
+func encodeCStr(b []byte, str string) {
+	n := copy(b[:len(b)-1], str)
+	clear(b[n:])
+}
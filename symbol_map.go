@@ -5,54 +5,132 @@
 package dbn
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"time"
 )
 
-type tsSymbolKey struct {
-	Date uint32 // YMD date
-	ID   uint32
+// TsSymbolInterval is the symbol of an instrument over the half-open interval [Start, End)
+// of UNIX nanosecond timestamps.
+type TsSymbolInterval struct {
+	Start  uint64 `json:"start_ts"` // Start of the interval (inclusive)
+	End    uint64 `json:"end_ts"`   // End of the interval (exclusive)
+	Symbol string `json:"symbol"`   // Symbol of the instrument over the interval
+}
+
+// Contains returns true if the UNIX nanosecond timestamp is within the interval.
+func (iv TsSymbolInterval) Contains(ts uint64) bool {
+	return iv.Start <= ts && ts < iv.End
+}
+
+// tsInstrumentInterval is an entry of the reverse index, from a symbol to its instrument over an interval.
+type tsInstrumentInterval struct {
+	Start uint64
+	End   uint64
+	ID    uint32
 }
 
 // TsSymbolMap is a timeseries symbol map. Generally useful for working with historical data
-// and is commonly built from a Metadata object.
+// and is commonly built from a Metadata object or a symbology resolution.
+// Each instrument's symbols are stored as sorted, non-overlapping intervals of nanosecond
+// timestamps, so long date ranges take no more memory than a single day.
+// Symbols may also be resolved back to instrument IDs with InstrumentID and InstrumentIDAt.
 type TsSymbolMap struct {
-	symbolMap map[tsSymbolKey]string
+	byID     map[uint32][]TsSymbolInterval     // instrument ID -> intervals, sorted by Start
+	bySymbol map[string][]tsInstrumentInterval // symbol -> instrument intervals, sorted by Start
+	count    int                               // total number of intervals in byID
 }
 
 func NewTsSymbolMap() *TsSymbolMap {
 	return &TsSymbolMap{
-		symbolMap: make(map[tsSymbolKey]string),
+		byID:     make(map[uint32][]TsSymbolInterval),
+		bySymbol: make(map[string][]tsInstrumentInterval),
 	}
 }
 
 // IsEmpty returns true if there are no mappings.
 func (tsm *TsSymbolMap) IsEmpty() bool {
-	return len(tsm.symbolMap) == 0
+	return tsm.count == 0
 }
 
-// Len returns the number of symbol mappings in the map.
+// Len returns the number of symbol intervals in the map.
 func (tsm *TsSymbolMap) Len() int {
-	return len(tsm.symbolMap)
+	return tsm.count
+}
+
+// Clear removes all the mappings.
+func (tsm *TsSymbolMap) Clear() {
+	tsm.byID = make(map[uint32][]TsSymbolInterval)
+	tsm.bySymbol = make(map[string][]tsInstrumentInterval)
+	tsm.count = 0
 }
 
-// Get returns the symbol mapping for the given date and instrument ID.
+// Get returns the symbol mapping for the given time and instrument ID.
 // Returns empty string if no mapping exists.
 func (tsm *TsSymbolMap) Get(dt time.Time, instrID uint32) string {
-	ymd := TimeToYMD(dt)
-	key := tsSymbolKey{Date: ymd, ID: instrID}
-	symbol, ok := tsm.symbolMap[key]
-	if !ok {
+	if dt.IsZero() || dt.Before(time.Unix(0, 0)) {
+		return ""
+	}
+	return tsm.GetAt(uint64(dt.UnixNano()), instrID)
+}
+
+// GetAt returns the symbol mapping for the given UNIX nanosecond timestamp and instrument ID,
+// such as a record's ts_event.  Returns empty string if no mapping exists.
+func (tsm *TsSymbolMap) GetAt(ts uint64, instrID uint32) string {
+	intervals := tsm.byID[instrID]
+	// find the last interval starting at or before ts
+	i := sort.Search(len(intervals), func(i int) bool { return intervals[i].Start > ts }) - 1
+	if i < 0 || !intervals[i].Contains(ts) {
 		return ""
 	}
-	return symbol
+	return intervals[i].Symbol
+}
+
+// InstrumentID returns the instrument ID which `symbol` mapped to on the given time.
+// Returns false if no mapping exists.
+func (tsm *TsSymbolMap) InstrumentID(symbol string, dt time.Time) (uint32, bool) {
+	if dt.IsZero() || dt.Before(time.Unix(0, 0)) {
+		return 0, false
+	}
+	return tsm.InstrumentIDAt(symbol, uint64(dt.UnixNano()))
+}
+
+// InstrumentIDAt returns the instrument ID which `symbol` mapped to at the given UNIX nanosecond timestamp.
+// If several instruments mapped to the symbol at that time, the one whose interval started last is returned.
+// Returns false if no mapping exists.
+func (tsm *TsSymbolMap) InstrumentIDAt(symbol string, ts uint64) (uint32, bool) {
+	intervals := tsm.bySymbol[symbol]
+	i := sort.Search(len(intervals), func(i int) bool { return intervals[i].Start > ts }) - 1
+	for ; i >= 0; i-- {
+		if ts < intervals[i].End {
+			return intervals[i].ID, true
+		}
+	}
+	return 0, false
+}
+
+// InstrumentIDs returns the sorted instrument IDs with mappings.
+func (tsm *TsSymbolMap) InstrumentIDs() []uint32 {
+	ids := make([]uint32, 0, len(tsm.byID))
+	for id := range tsm.byID {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Intervals returns a copy of the symbol intervals of an instrument, sorted by time.
+func (tsm *TsSymbolMap) Intervals(instrID uint32) []TsSymbolInterval {
+	return slices.Clone(tsm.byID[instrID])
 }
 
-// FillFromMetadata fills the TsSymbolMap with mappings from `metadata`.
+// FillFromMetadata fills the TsSymbolMap with mappings from `metadata`, clearing any original contents.
 func (tsm *TsSymbolMap) FillFromMetadata(metadata *Metadata) error {
-	// clear existing mappings
-	tsm.symbolMap = make(map[tsSymbolKey]string)
+	tsm.Clear()
 
 	// handle inverse mappings distinctly
 	invMapping, err := metadata.IsInverseMapping()
@@ -71,7 +149,9 @@ func (tsm *TsSymbolMap) FillFromMetadata(metadata *Metadata) error {
 				if interval.Symbol == "" {
 					continue
 				}
-				tsm.Insert(uint32(instrID), interval.StartDate, interval.EndDate, interval.Symbol)
+				if err := tsm.Insert(uint32(instrID), interval.StartDate, interval.EndDate, interval.Symbol); err != nil {
+					return err
+				}
 			}
 		}
 	} else {
@@ -86,7 +166,9 @@ func (tsm *TsSymbolMap) FillFromMetadata(metadata *Metadata) error {
 				if err != nil {
 					return err // really?
 				}
-				tsm.Insert(uint32(instrID), interval.StartDate, interval.EndDate, mapping.RawSymbol)
+				if err := tsm.Insert(uint32(instrID), interval.StartDate, interval.EndDate, mapping.RawSymbol); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -94,25 +176,139 @@ func (tsm *TsSymbolMap) FillFromMetadata(metadata *Metadata) error {
 	return nil
 }
 
-// Insert adds mappings for a date range.
-// dates are YYYYMMDD ints
+// Insert adds a mapping for the UTC date range [startDate, endDate), as in Metadata's MappingIntervals.
+// Dates are YYYYMMDD ints.  An empty range is ignored.
 func (tsm *TsSymbolMap) Insert(instrID uint32, startDate uint32, endDate uint32, ticker string) error {
-	// convert dates to time.Time
-	startTime := YMDToTime(int(startDate), time.UTC)
-	endTime := YMDToTime(int(endDate), time.UTC)
-	if startTime.After(endTime) {
+	if startDate > endDate {
 		return fmt.Errorf("startDate is after endDate")
 	}
+	return tsm.InsertTs(instrID, ymdToTimestamp(startDate), ymdToTimestamp(endDate), ticker)
+}
+
+// InsertTs adds a mapping for the UNIX nanosecond interval [startTs, endTs).  Any existing mappings
+// of the instrument over the interval are replaced.  An empty interval is ignored.
+func (tsm *TsSymbolMap) InsertTs(instrID uint32, startTs uint64, endTs uint64, symbol string) error {
+	if startTs > endTs {
+		return fmt.Errorf("startTs is after endTs")
+	}
+	if startTs == endTs {
+		return nil
+	}
 
-	// Iterate calendar days from startDate to endDate
-	for d := startTime; d.Before(endTime) || d.Equal(endTime); d = d.AddDate(0, 0, 1) {
-		ymd := TimeToYMD(d)
-		key := tsSymbolKey{Date: ymd, ID: instrID}
-		tsm.symbolMap[key] = ticker
+	// Trim the existing intervals around the new one
+	old := tsm.byID[instrID]
+	intervals := make([]TsSymbolInterval, 0, len(old)+2)
+	for _, iv := range old {
+		if iv.End <= startTs || iv.Start >= endTs {
+			intervals = append(intervals, iv)
+			continue
+		}
+		if iv.Start < startTs {
+			intervals = append(intervals, TsSymbolInterval{Start: iv.Start, End: startTs, Symbol: iv.Symbol})
+		}
+		if iv.End > endTs {
+			intervals = append(intervals, TsSymbolInterval{Start: endTs, End: iv.End, Symbol: iv.Symbol})
+		}
+	}
+	intervals = append(intervals, TsSymbolInterval{Start: startTs, End: endTs, Symbol: symbol})
+	slices.SortFunc(intervals, func(a, b TsSymbolInterval) int { return cmp.Compare(a.Start, b.Start) })
+
+	// Merge adjacent intervals of the same symbol
+	merged := intervals[:1]
+	for _, iv := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if last.End == iv.Start && last.Symbol == iv.Symbol {
+			last.End = iv.End
+		} else {
+			merged = append(merged, iv)
+		}
+	}
+
+	tsm.count += len(merged) - len(old)
+	tsm.byID[instrID] = merged
+
+	// Update the reverse index of the instrument's old and new symbols
+	symbols := []string{symbol}
+	for _, iv := range old {
+		symbols = append(symbols, iv.Symbol)
+	}
+	slices.Sort(symbols)
+	for _, sym := range slices.Compact(symbols) {
+		tsm.reindexSymbol(sym, instrID)
 	}
 	return nil
 }
 
+// reindexSymbol replaces the reverse index entries of `symbol` for the instrument.
+func (tsm *TsSymbolMap) reindexSymbol(symbol string, instrID uint32) {
+	entries := slices.DeleteFunc(tsm.bySymbol[symbol], func(e tsInstrumentInterval) bool { return e.ID == instrID })
+	for _, iv := range tsm.byID[instrID] {
+		if iv.Symbol == symbol {
+			entries = append(entries, tsInstrumentInterval{Start: iv.Start, End: iv.End, ID: instrID})
+		}
+	}
+	if len(entries) == 0 {
+		delete(tsm.bySymbol, symbol)
+		return
+	}
+	slices.SortFunc(entries, func(a, b tsInstrumentInterval) int { return cmp.Compare(a.Start, b.Start) })
+	tsm.bySymbol[symbol] = entries
+}
+
+// OnSymbolMappingMsg adds the mapping of a SymbolMappingMsg record, from the header's instrument ID
+// to its stype_out symbol over [StartTs, EndTs).  An undefined StartTs maps from the beginning of time,
+// and an undefined or invalid EndTs maps forever, so that the latest mapping of a live session applies.
+func (tsm *TsSymbolMap) OnSymbolMappingMsg(symbolMapping *SymbolMappingMsg) error {
+	startTs, endTs := symbolMapping.StartTs, symbolMapping.EndTs
+	if startTs == UNDEF_TIMESTAMP {
+		startTs = 0
+	}
+	if endTs <= startTs {
+		endTs = UNDEF_TIMESTAMP
+	}
+	return tsm.InsertTs(symbolMapping.Header.InstrumentID, startTs, endTs, symbolMapping.StypeOutSymbol)
+}
+
+// MarshalJSON implements json.Marshaler, writing an object of instrument IDs to their symbol intervals:
+//
+//	{"5482":[{"start_ts":1609113600000000000,"end_ts":1609200000000000000,"symbol":"ESH1"}]}
+func (tsm *TsSymbolMap) MarshalJSON() ([]byte, error) {
+	byID := make(map[string][]TsSymbolInterval, len(tsm.byID))
+	for id, intervals := range tsm.byID {
+		byID[strconv.FormatUint(uint64(id), 10)] = intervals
+	}
+	return json.Marshal(byID)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the output of MarshalJSON and replacing any original contents.
+func (tsm *TsSymbolMap) UnmarshalJSON(data []byte) error {
+	var byID map[string][]TsSymbolInterval
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return err
+	}
+	tsm.Clear()
+	for idStr, intervals := range byID {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid instrument ID '%s': %w", idStr, err)
+		}
+		for _, iv := range intervals {
+			if err := tsm.InsertTs(uint32(id), iv.Start, iv.End, iv.Symbol); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ymdToTimestamp returns the UNIX nanosecond timestamp of midnight UTC of a YYYYMMDD date.
+func ymdToTimestamp(ymd uint32) uint64 {
+	if ymd == 0 {
+		return 0
+	}
+	return uint64(YMDToTime(int(ymd), time.UTC).UnixNano())
+}
+
 //////////////////////////////////////////////////////////////////////////////
 
 // PitSymbolMap is a point-in-time symbol map. Useful for working with live symbology or a
@@ -206,3 +402,82 @@ func (p *PitSymbolMap) FillFromMetadata(metadata *Metadata, timestamp uint64) er
 	}
 	return nil
 }
+
+//////////////////////////////////////////////////////////////////////////////
+
+// SymbolMappingBuilder reconstructs Metadata symbol mappings from observed
+// (timestamp, instrument ID, symbol) triples, such as the `symbol` column of a
+// Parquet file or the `symbol` field of symbol-mapped JSON.
+// The resulting mappings go from raw symbol to instrument ID.
+type SymbolMappingBuilder struct {
+	dates map[string]map[uint32]uint32 // symbol -> YMD -> instrID
+}
+
+func NewSymbolMappingBuilder() *SymbolMappingBuilder {
+	return &SymbolMappingBuilder{
+		dates: make(map[string]map[uint32]uint32),
+	}
+}
+
+// IsEmpty returns true if nothing has been observed.
+func (b *SymbolMappingBuilder) IsEmpty() bool {
+	return len(b.dates) == 0
+}
+
+// Observe records that `symbol` mapped to `instrumentID` at UNIX nanosecond timestamp `ts`.
+// Empty symbols are ignored.
+func (b *SymbolMappingBuilder) Observe(ts uint64, instrumentID uint32, symbol string) {
+	if symbol == "" {
+		return
+	}
+	ymd := TimeToYMD(TimestampToTime(ts).UTC())
+	byDate, ok := b.dates[symbol]
+	if !ok {
+		byDate = make(map[uint32]uint32)
+		b.dates[symbol] = byDate
+	}
+	byDate[ymd] = instrumentID
+}
+
+// Symbols returns the sorted observed symbols.
+func (b *SymbolMappingBuilder) Symbols() []string {
+	symbols := make([]string, 0, len(b.dates))
+	for symbol := range b.dates {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// Mappings returns the SymbolMappings for the observations, sorted by symbol.
+// Observations of the same instrument ID on consecutive days are merged into one interval,
+// which ends (exclusive) the day after its last observation.  Days without observations,
+// such as weekends, are not mapped, so they start a new interval.
+func (b *SymbolMappingBuilder) Mappings() []SymbolMapping {
+	mappings := make([]SymbolMapping, 0, len(b.dates))
+	for _, symbol := range b.Symbols() {
+		byDate := b.dates[symbol]
+		ymds := make([]uint32, 0, len(byDate))
+		for ymd := range byDate {
+			ymds = append(ymds, ymd)
+		}
+		slices.Sort(ymds)
+
+		mapping := SymbolMapping{RawSymbol: symbol}
+		for _, ymd := range ymds {
+			instrID := strconv.FormatUint(uint64(byDate[ymd]), 10)
+			nextDay := TimeToYMD(YMDToTime(int(ymd), time.UTC).AddDate(0, 0, 1))
+			if n := len(mapping.Intervals); n > 0 && mapping.Intervals[n-1].Symbol == instrID && mapping.Intervals[n-1].EndDate == ymd {
+				mapping.Intervals[n-1].EndDate = nextDay
+				continue
+			}
+			mapping.Intervals = append(mapping.Intervals, MappingInterval{
+				StartDate: ymd,
+				EndDate:   nextDay,
+				Symbol:    instrID,
+			})
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_hist "github.com/NimbleMarkets/dbn-go/hist"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// ymdTime returns midnight UTC of a YYYYMMDD date.
+func ymdTime(ymd int) time.Time {
+	return dbn.YMDToTime(ymd, time.UTC)
+}
+
+var _ = Describe("TsSymbolMap", func() {
+	Context("intervals", func() {
+		It("should map a date range end-exclusive", func() {
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.IsEmpty()).To(BeTrue())
+			Expect(tsm.Insert(5482, 20201228, 20201231, "ESH1")).To(Succeed())
+			Expect(tsm.Len()).To(Equal(1))
+
+			Expect(tsm.Get(ymdTime(20201227), 5482)).To(Equal(""))
+			Expect(tsm.Get(ymdTime(20201228), 5482)).To(Equal("ESH1"))
+			Expect(tsm.Get(ymdTime(20201230).Add(23*time.Hour), 5482)).To(Equal("ESH1"))
+			Expect(tsm.Get(ymdTime(20201231), 5482)).To(Equal(""))
+			Expect(tsm.Get(ymdTime(20201229), 1)).To(Equal(""))
+
+			ts := uint64(ymdTime(20201229).UnixNano())
+			Expect(tsm.GetAt(ts, 5482)).To(Equal("ESH1"))
+			Expect(tsm.GetAt(0, 5482)).To(Equal(""))
+		})
+
+		It("should store a multi-year range as one interval", func() {
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.Insert(1, 20100101, 20300101, "AAPL")).To(Succeed())
+			Expect(tsm.Len()).To(Equal(1))
+			Expect(tsm.Get(ymdTime(20250615), 1)).To(Equal("AAPL"))
+		})
+
+		It("should replace overlapping mappings and merge adjacent ones", func() {
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.Insert(7, 20240101, 20240201, "OLD")).To(Succeed())
+			Expect(tsm.Insert(7, 20240110, 20240120, "NEW")).To(Succeed())
+			Expect(tsm.Intervals(7)).To(Equal([]dbn.TsSymbolInterval{
+				{Start: uint64(ymdTime(20240101).UnixNano()), End: uint64(ymdTime(20240110).UnixNano()), Symbol: "OLD"},
+				{Start: uint64(ymdTime(20240110).UnixNano()), End: uint64(ymdTime(20240120).UnixNano()), Symbol: "NEW"},
+				{Start: uint64(ymdTime(20240120).UnixNano()), End: uint64(ymdTime(20240201).UnixNano()), Symbol: "OLD"},
+			}))
+			Expect(tsm.Len()).To(Equal(3))
+
+			Expect(tsm.Insert(7, 20240110, 20240120, "OLD")).To(Succeed())
+			Expect(tsm.Len()).To(Equal(1))
+			Expect(tsm.Intervals(7)[0].End).To(Equal(uint64(ymdTime(20240201).UnixNano())))
+			id, ok := tsm.InstrumentID("NEW", ymdTime(20240115))
+			Expect(ok).To(BeFalse())
+			Expect(id).To(BeZero())
+		})
+
+		It("should reject reversed ranges and ignore empty ones", func() {
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.Insert(1, 20240102, 20240101, "X")).NotTo(Succeed())
+			Expect(tsm.Insert(1, 20240101, 20240101, "X")).To(Succeed())
+			Expect(tsm.IsEmpty()).To(BeTrue())
+		})
+
+		It("should map nanosecond intervals of SymbolMappingMsg", func() {
+			tsm := dbn.NewTsSymbolMap()
+			msg := dbn.SymbolMappingMsg{StartTs: 1000, EndTs: 2000, StypeOutSymbol: "MSFT"}
+			msg.Header.InstrumentID = 42
+			Expect(tsm.OnSymbolMappingMsg(&msg)).To(Succeed())
+			Expect(tsm.GetAt(999, 42)).To(Equal(""))
+			Expect(tsm.GetAt(1000, 42)).To(Equal("MSFT"))
+			Expect(tsm.GetAt(1999, 42)).To(Equal("MSFT"))
+			Expect(tsm.GetAt(2000, 42)).To(Equal(""))
+		})
+	})
+
+	Context("reverse lookup", func() {
+		It("should find the instrument of a symbol on a date", func() {
+			// ESH4 rolls to a new instrument ID mid-range
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.Insert(100, 20240101, 20240115, "ESH4")).To(Succeed())
+			Expect(tsm.Insert(200, 20240115, 20240201, "ESH4")).To(Succeed())
+			Expect(tsm.Insert(300, 20240101, 20240201, "NQH4")).To(Succeed())
+
+			id, ok := tsm.InstrumentID("ESH4", ymdTime(20240110))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint32(100)))
+			id, ok = tsm.InstrumentIDAt("ESH4", uint64(ymdTime(20240115).UnixNano()))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint32(200)))
+			_, ok = tsm.InstrumentID("ESH4", ymdTime(20240201))
+			Expect(ok).To(BeFalse())
+			_, ok = tsm.InstrumentID("ZZZ", ymdTime(20240110))
+			Expect(ok).To(BeFalse())
+			Expect(tsm.InstrumentIDs()).To(Equal([]uint32{100, 200, 300}))
+		})
+
+		It("should resolve symbols of file metadata", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.ohlcv-1s.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			metadata, err := dbn.NewDbnScanner(reader).Metadata()
+			Expect(err).To(BeNil())
+
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.FillFromMetadata(metadata)).To(Succeed())
+			Expect(tsm.Get(dbn.TimestampToTime(metadata.Start).UTC(), 5482)).To(Equal("ESH1"))
+			id, ok := tsm.InstrumentIDAt("ESH1", metadata.Start)
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint32(5482)))
+		})
+	})
+
+	Context("Resolution", func() {
+		It("should load a symbology resolution to instrument IDs", func() {
+			resolution := dbn_hist.Resolution{
+				Mappings: map[string][]dbn_hist.MappingInterval{
+					"ES.c.0": {
+						{StartDate: "2024-01-01", EndDate: "2024-03-15", Symbol: "100"},
+						{StartDate: "2024-03-15", EndDate: "2024-06-21", Symbol: "200"},
+					},
+				},
+				StypeIn:  dbn.SType_Continuous,
+				StypeOut: dbn.SType_InstrumentId,
+			}
+			tsm, err := resolution.TsSymbolMap()
+			Expect(err).To(BeNil())
+			Expect(tsm.Len()).To(Equal(2))
+			Expect(tsm.Get(ymdTime(20240314), 100)).To(Equal("ES.c.0"))
+			Expect(tsm.Get(ymdTime(20240315), 100)).To(Equal(""))
+			id, ok := tsm.InstrumentID("ES.c.0", ymdTime(20240401))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint32(200)))
+		})
+
+		It("should load an inverse resolution from instrument IDs", func() {
+			resolution := dbn_hist.Resolution{
+				Mappings: map[string][]dbn_hist.MappingInterval{
+					"5482": {{StartDate: "2020-12-28", EndDate: "2020-12-29", Symbol: "ESH1"}},
+				},
+				StypeIn:  dbn.SType_InstrumentId,
+				StypeOut: dbn.SType_RawSymbol,
+			}
+			tsm, err := resolution.TsSymbolMap()
+			Expect(err).To(BeNil())
+			Expect(tsm.Get(ymdTime(20201228), 5482)).To(Equal("ESH1"))
+		})
+
+		It("should reject a resolution without instrument IDs", func() {
+			resolution := dbn_hist.Resolution{StypeIn: dbn.SType_RawSymbol, StypeOut: dbn.SType_Continuous}
+			_, err := resolution.TsSymbolMap()
+			Expect(err).To(Equal(dbn.ErrWrongStypesForMapping))
+		})
+	})
+
+	Context("JSON", func() {
+		It("should round trip", func() {
+			tsm := dbn.NewTsSymbolMap()
+			Expect(tsm.Insert(5482, 20201228, 20201229, "ESH1")).To(Succeed())
+			Expect(tsm.Insert(7, 20240101, 20240201, "OLD")).To(Succeed())
+			Expect(tsm.Insert(7, 20240110, 20240120, "NEW")).To(Succeed())
+
+			data, err := json.Marshal(tsm)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring(`"5482":[{"start_ts":1609113600000000000,"end_ts":1609200000000000000,"symbol":"ESH1"}]`))
+
+			loaded := dbn.NewTsSymbolMap()
+			Expect(json.Unmarshal(data, loaded)).To(Succeed())
+			Expect(loaded.Len()).To(Equal(4))
+			Expect(loaded.Intervals(7)).To(Equal(tsm.Intervals(7)))
+			id, ok := loaded.InstrumentID("NEW", ymdTime(20240115))
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(uint32(7)))
+		})
+
+		It("should reject invalid instrument IDs", func() {
+			Expect(json.Unmarshal([]byte(`{"abc":[]}`), dbn.NewTsSymbolMap())).NotTo(Succeed())
+		})
+	})
+})
+
+var _ = Describe("SymbolMappingBuilder", func() {
+	It("should merge consecutive days and not bridge gaps", func() {
+		builder := dbn.NewSymbolMappingBuilder()
+		for _, observation := range []struct {
+			ymd          int
+			instrumentID uint32
+		}{{20240102, 7}, {20240103, 7}, {20240105, 7}, {20240108, 9}, {20240109, 9}} {
+			builder.Observe(uint64(ymdTime(observation.ymd).UnixNano()), observation.instrumentID, "ACME")
+		}
+		Expect(builder.Mappings()).To(Equal([]dbn.SymbolMapping{{
+			RawSymbol: "ACME",
+			Intervals: []dbn.MappingInterval{
+				{StartDate: 20240102, EndDate: 20240104, Symbol: "7"},
+				{StartDate: 20240105, EndDate: 20240106, Symbol: "7"},
+				{StartDate: 20240108, EndDate: 20240110, Symbol: "9"},
+			},
+		}}))
+	})
+})
@@ -0,0 +1,219 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+///////////////////////////////////////////////////////////////////////////////
+
+// ResolvedSymbol is the symbol and publisher of the record being visited by a SymbolResolver.
+type ResolvedSymbol struct {
+	Symbol       string    // Symbol of the instrument at the record's index timestamp, or "" if unmapped
+	Publisher    Publisher // Publisher of the record, from its header
+	InstrumentID uint32    // Instrument ID of the record, from its header
+	IndexTs      uint64    // Index timestamp of the record, per RecordIndexTs
+}
+
+// SymbolResolver is a Visitor which resolves the symbol and publisher of each record and then
+// passes the record on, either to a wrapped Visitor or to a function.  Its TsSymbolMap is filled from
+// Metadata with FillFromMetadata and updated by the stream's SymbolMappingMsg records, so the same
+// handlers work for historical files and live sessions.
+//
+// A wrapped Visitor's methods may call Current, Symbol, or Publisher for the record being visited.
+// SymbolResolver passes unknown and malformed records to the wrapped Visitor's UnknownRTypeHandler
+// and DecodeErrorHandler, if it implements them.
+//
+// Example:
+//
+//	resolver := dbn.NewSymbolResolverFunc(func(record dbn.Record, resolved dbn.ResolvedSymbol) error {
+//		fmt.Println(resolved.Symbol, resolved.Publisher, record.GetHeader().TsEvent)
+//		return nil
+//	})
+//	metadata, err := dbnScanner.Metadata()
+//	err = resolver.FillFromMetadata(metadata)
+//	for dbnScanner.Next() {
+//		err = dbnScanner.Visit(resolver)
+//	}
+type SymbolResolver struct {
+	visitor   Visitor                                            // wrapped Visitor, or a NullVisitor
+	handler   func(record Record, resolved ResolvedSymbol) error // handler function, or nil
+	symbolMap *TsSymbolMap
+	current   ResolvedSymbol
+}
+
+// NewSymbolResolver returns a SymbolResolver wrapping a Visitor, whose methods may call Current.
+func NewSymbolResolver(visitor Visitor) *SymbolResolver {
+	return &SymbolResolver{
+		visitor:   visitor,
+		symbolMap: NewTsSymbolMap(),
+	}
+}
+
+// NewSymbolResolverFunc returns a SymbolResolver which passes every record, including
+// SymbolMappingMsg records, to `handler` along with its ResolvedSymbol.
+func NewSymbolResolverFunc(handler func(record Record, resolved ResolvedSymbol) error) *SymbolResolver {
+	return &SymbolResolver{
+		visitor:   &NullVisitor{},
+		handler:   handler,
+		symbolMap: NewTsSymbolMap(),
+	}
+}
+
+// SymbolMap returns the resolver's TsSymbolMap.
+func (r *SymbolResolver) SymbolMap() *TsSymbolMap {
+	return r.symbolMap
+}
+
+// FillFromMetadata fills the resolver's TsSymbolMap with the mappings of `metadata`,
+// clearing any original contents.  Live metadata has no mappings, and only clears it.
+func (r *SymbolResolver) FillFromMetadata(metadata *Metadata) error {
+	if len(metadata.Mappings) == 0 {
+		r.symbolMap.Clear()
+		return nil
+	}
+	return r.symbolMap.FillFromMetadata(metadata)
+}
+
+// Resolve returns the ResolvedSymbol of a record, without visiting it.
+func (r *SymbolResolver) Resolve(record Record) ResolvedSymbol {
+	header := record.GetHeader()
+	indexTs := RecordIndexTs(record)
+	return ResolvedSymbol{
+		Symbol:       r.symbolMap.GetAt(indexTs, header.InstrumentID),
+		Publisher:    Publisher(header.PublisherID),
+		InstrumentID: header.InstrumentID,
+		IndexTs:      indexTs,
+	}
+}
+
+// Current returns the ResolvedSymbol of the record being visited.
+func (r *SymbolResolver) Current() ResolvedSymbol {
+	return r.current
+}
+
+// Symbol returns the symbol of the record being visited, or "" if unmapped.
+func (r *SymbolResolver) Symbol() string {
+	return r.current.Symbol
+}
+
+// Publisher returns the publisher of the record being visited.
+func (r *SymbolResolver) Publisher() Publisher {
+	return r.current.Publisher
+}
+
+// RecordIndexTs returns the timestamp by which a record is indexed and its symbol is resolved:
+// its ts_recv if it has one, otherwise its ts_event.
+func RecordIndexTs(record Record) uint64 {
+	switch r := record.(type) {
+	case *Mbp0Msg:
+		return r.TsRecv
+	case *Mbp1Msg:
+		return r.TsRecv
+	case *Mbp10Msg:
+		return r.TsRecv
+	case *MboMsg:
+		return r.TsRecv
+	case *Cmbp1Msg:
+		return r.TsRecv
+	case *BboMsg:
+		return r.TsRecv
+	case *ImbalanceMsg:
+		return r.TsRecv
+	case *StatMsg:
+		return r.TsRecv
+	case *StatusMsg:
+		return r.TsRecv
+	case *InstrumentDefMsg:
+		return r.TsRecv
+	default:
+		return record.GetHeader().TsEvent
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// visitResolved resolves the record and passes it to the handler, or to the wrapped Visitor's method.
+func visitResolved[R Record](r *SymbolResolver, record R, method func(record R) error) error {
+	r.current = r.Resolve(record)
+	if r.handler != nil {
+		return r.handler(record, r.current)
+	}
+	return method(record)
+}
+
+func (r *SymbolResolver) OnMbp0(record *Mbp0Msg) error {
+	return visitResolved(r, record, r.visitor.OnMbp0)
+}
+
+func (r *SymbolResolver) OnMbp1(record *Mbp1Msg) error {
+	return visitResolved(r, record, r.visitor.OnMbp1)
+}
+
+func (r *SymbolResolver) OnMbp10(record *Mbp10Msg) error {
+	return visitResolved(r, record, r.visitor.OnMbp10)
+}
+
+func (r *SymbolResolver) OnMbo(record *MboMsg) error {
+	return visitResolved(r, record, r.visitor.OnMbo)
+}
+
+func (r *SymbolResolver) OnOhlcv(record *OhlcvMsg) error {
+	return visitResolved(r, record, r.visitor.OnOhlcv)
+}
+
+func (r *SymbolResolver) OnCmbp1(record *Cmbp1Msg) error {
+	return visitResolved(r, record, r.visitor.OnCmbp1)
+}
+
+func (r *SymbolResolver) OnBbo(record *BboMsg) error {
+	return visitResolved(r, record, r.visitor.OnBbo)
+}
+
+func (r *SymbolResolver) OnImbalance(record *ImbalanceMsg) error {
+	return visitResolved(r, record, r.visitor.OnImbalance)
+}
+
+func (r *SymbolResolver) OnStatMsg(record *StatMsg) error {
+	return visitResolved(r, record, r.visitor.OnStatMsg)
+}
+
+func (r *SymbolResolver) OnStatusMsg(record *StatusMsg) error {
+	return visitResolved(r, record, r.visitor.OnStatusMsg)
+}
+
+func (r *SymbolResolver) OnInstrumentDefMsg(record *InstrumentDefMsg) error {
+	return visitResolved(r, record, r.visitor.OnInstrumentDefMsg)
+}
+
+func (r *SymbolResolver) OnErrorMsg(record *ErrorMsg) error {
+	return visitResolved(r, record, r.visitor.OnErrorMsg)
+}
+
+func (r *SymbolResolver) OnSystemMsg(record *SystemMsg) error {
+	return visitResolved(r, record, r.visitor.OnSystemMsg)
+}
+
+// OnSymbolMappingMsg updates the TsSymbolMap with the mapping, and then passes the record on.
+func (r *SymbolResolver) OnSymbolMappingMsg(record *SymbolMappingMsg) error {
+	if err := r.symbolMap.OnSymbolMappingMsg(record); err != nil {
+		return err
+	}
+	return visitResolved(r, record, r.visitor.OnSymbolMappingMsg)
+}
+
+func (r *SymbolResolver) OnStreamEnd() error {
+	r.current = ResolvedSymbol{}
+	return r.visitor.OnStreamEnd()
+}
+
+func (r *SymbolResolver) OnUnknownRType(header *RHeader, raw []byte) error {
+	if handler, ok := r.visitor.(UnknownRTypeHandler); ok {
+		return handler.OnUnknownRType(header, raw)
+	}
+	return ErrUnknownRType
+}
+
+func (r *SymbolResolver) OnDecodeError(header *RHeader, raw []byte, err error) error {
+	if handler, ok := r.visitor.(DecodeErrorHandler); ok {
+		return handler.OnDecodeError(header, raw, err)
+	}
+	return err
+}
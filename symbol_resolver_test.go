@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// symbolCollector is a Visitor which records the symbols of its SymbolResolver's OHLCV records.
+type symbolCollector struct {
+	dbn.NullVisitor
+	resolver *dbn.SymbolResolver
+	symbols  []string
+	ended    bool
+}
+
+func (c *symbolCollector) OnOhlcv(record *dbn.OhlcvMsg) error {
+	c.symbols = append(c.symbols, c.resolver.Symbol())
+	return nil
+}
+
+func (c *symbolCollector) OnStreamEnd() error {
+	c.ended = true
+	return nil
+}
+
+var _ = Describe("SymbolResolver", func() {
+	It("should implement dbn.Visitor and its handlers", func() {
+		r := dbn.NewSymbolResolver(&dbn.NullVisitor{})
+		var _ dbn.Visitor = r
+		var _ dbn.UnknownRTypeHandler = r
+		var _ dbn.DecodeErrorHandler = r
+	})
+
+	It("should resolve the symbols of a historical file from its metadata", func() {
+		reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.ohlcv-1s.v3.dbn.zst", false)
+		Expect(err).To(BeNil())
+		defer closer.Close()
+		scanner := dbn.NewDbnScanner(reader)
+		metadata, err := scanner.Metadata()
+		Expect(err).To(BeNil())
+
+		var resolved []dbn.ResolvedSymbol
+		resolver := dbn.NewSymbolResolverFunc(func(record dbn.Record, r dbn.ResolvedSymbol) error {
+			Expect(r.InstrumentID).To(Equal(record.GetHeader().InstrumentID))
+			resolved = append(resolved, r)
+			return nil
+		})
+		Expect(resolver.FillFromMetadata(metadata)).To(Succeed())
+		for scanner.Next() {
+			Expect(scanner.Visit(resolver)).To(Succeed())
+		}
+		Expect(resolved).To(HaveLen(2))
+		for _, r := range resolved {
+			Expect(r.Symbol).To(Equal("ESH1"))
+			Expect(r.Publisher).To(Equal(dbn.Publisher_GlbxMdp3Glbx))
+			Expect(r.Publisher.Dataset()).To(Equal(dbn.Dataset_GlbxMdp3))
+		}
+	})
+
+	It("should resolve the symbols of a live stream from its SymbolMappingMsg records", func() {
+		collector := &symbolCollector{}
+		resolver := dbn.NewSymbolResolver(collector)
+		collector.resolver = resolver
+		Expect(resolver.FillFromMetadata(&dbn.Metadata{StypeIn: dbn.SType_RawSymbol, StypeOut: dbn.SType_InstrumentId})).To(Succeed())
+
+		ohlcv := &dbn.OhlcvMsg{Header: dbn.RHeader{RType: dbn.RType_Ohlcv1S, PublisherID: 1, InstrumentID: 42, TsEvent: 2000}}
+		mapping := &dbn.SymbolMappingMsg{
+			Header:         dbn.RHeader{RType: dbn.RType_SymbolMapping, InstrumentID: 42, TsEvent: 1000},
+			StypeOutSymbol: "MSFT",
+			StartTs:        dbn.UNDEF_TIMESTAMP,
+			EndTs:          dbn.UNDEF_TIMESTAMP,
+		}
+		Expect(dbn.VisitRecord(resolver, ohlcv)).To(Succeed()) // before its mapping
+		Expect(dbn.VisitRecord(resolver, mapping)).To(Succeed())
+		Expect(dbn.VisitRecord(resolver, ohlcv)).To(Succeed())
+		Expect(resolver.Publisher()).To(Equal(dbn.Publisher(1)))
+
+		// A remapping applies to later records
+		remapping := *mapping
+		remapping.StypeOutSymbol = "MSFT.NEW"
+		Expect(dbn.VisitRecord(resolver, &remapping)).To(Succeed())
+		Expect(dbn.VisitRecord(resolver, ohlcv)).To(Succeed())
+		Expect(resolver.OnStreamEnd()).To(Succeed())
+
+		Expect(collector.symbols).To(Equal([]string{"", "MSFT", "MSFT.NEW"}))
+		Expect(collector.ended).To(BeTrue())
+		Expect(resolver.Current()).To(Equal(dbn.ResolvedSymbol{}))
+	})
+
+	It("should pass unknown records to the wrapped Visitor's handlers", func() {
+		skipped := 0
+		resolver := dbn.NewSymbolResolver(&dbn.VisitorFuncs{
+			UnknownRType: func(header *dbn.RHeader, raw []byte) error {
+				skipped++
+				return nil
+			},
+		})
+		Expect(resolver.OnUnknownRType(&dbn.RHeader{}, nil)).To(Succeed())
+		Expect(skipped).To(Equal(1))
+		Expect(dbn.NewSymbolResolver(&dbn.NullVisitor{}).OnUnknownRType(&dbn.RHeader{}, nil)).To(Equal(dbn.ErrUnknownRType))
+	})
+
+	It("should index records by ts_recv", func() {
+		Expect(dbn.RecordIndexTs(&dbn.Mbp0Msg{Header: dbn.RHeader{TsEvent: 1}, TsRecv: 2})).To(Equal(uint64(2)))
+		Expect(dbn.RecordIndexTs(&dbn.OhlcvMsg{Header: dbn.RHeader{TsEvent: 1}})).To(Equal(uint64(1)))
+	})
+})
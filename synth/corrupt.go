@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_synth
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+// errTruncated ends a stream after a record is truncated.
+var errTruncated = errors.New("stream truncated")
+
+// corruptWriter writes a DBN stream's records, each encoded by a DbnWriter in a single Write,
+// corrupting some of them.  It passes through writes until armed, such as of the Metadata.
+type corruptWriter struct {
+	writer       io.Writer
+	rng          *rand.Rand
+	rate         float64
+	corruptions  []Corruption
+	armed        bool
+	numCorrupted int
+}
+
+func (w *corruptWriter) Write(b []byte) (int, error) {
+	if !w.armed || len(b) <= dbn.RHeader_Size || w.rng.Float64() >= w.rate {
+		return w.writer.Write(b)
+	}
+	w.numCorrupted++
+	b = slices.Clone(b)
+	switch w.corruptions[w.rng.IntN(len(w.corruptions))] {
+	case Corruption_Truncate:
+		if _, err := w.writer.Write(b[:1+w.rng.IntN(len(b)-1)]); err != nil {
+			return 0, err
+		}
+		return 0, errTruncated
+	case Corruption_Length:
+		length := b[0]
+		for b[0] == length {
+			b[0] = uint8(w.rng.IntN(256))
+		}
+	case Corruption_RType:
+		// 0xD0 to 0xEF are not defined
+		b[1] = uint8(0xD0 + w.rng.IntN(0x20))
+	case Corruption_Bytes:
+		for range 1 + w.rng.IntN(4) {
+			b[dbn.RHeader_Size+w.rng.IntN(len(b)-dbn.RHeader_Size)] ^= uint8(1 + w.rng.IntN(255))
+		}
+	case Corruption_Timestamp:
+		tsEvent := binary.LittleEndian.Uint64(b[8:16])
+		binary.LittleEndian.PutUint64(b[8:16], tsEvent-uint64(1+w.rng.Int64N(int64(time.Second))))
+	}
+	return w.writer.Write(b)
+}
+
+// Write writes the stream to `writer` as DBN: its Metadata, then its records, each of which is
+// corrupted with the probability of Options.CorruptionRate.  Corruption does not change the
+// records generated, so the same Options give the same stream apart from the corrupted records.
+// A truncated record ends the stream.  Returns any error generating or writing the stream.
+func (g *Generator) Write(writer io.Writer) error {
+	corrupter := &corruptWriter{
+		writer:      writer,
+		rng:         rand.New(rand.NewPCG(g.opts.Seed, 2)),
+		rate:        g.opts.CorruptionRate,
+		corruptions: g.opts.Corruptions,
+	}
+	dbnWriter, err := dbn.NewDbnWriter(corrupter, g.metadata)
+	if err != nil {
+		return err
+	}
+	corrupter.armed = true
+	defer func() { g.numCorrupted += corrupter.numCorrupted }()
+	for {
+		record, ok := g.Next()
+		if !ok {
+			return nil
+		}
+		if err := dbnWriter.Write(record); err != nil {
+			if errors.Is(err, errTruncated) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// NumCorrupted returns the number of records corrupted by Write.
+func (g *Generator) NumCorrupted() int {
+	return g.numCorrupted
+}
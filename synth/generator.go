@@ -0,0 +1,454 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_synth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_backtest "github.com/NimbleMarkets/dbn-go/backtest"
+)
+
+// Generator generates a synthetic DBN stream per its Options.  Next returns its records one at
+// a time; Write writes the whole stream, with its Metadata, injecting any corruption.
+type Generator struct {
+	opts        Options
+	metadata    *dbn.Metadata
+	rng         *rand.Rand // of the simulation
+	publisherID uint16
+	tickSize    int64 // Options.TickSize in fixed-point
+	instruments []*instrument
+	nextOrderID uint64
+
+	start       uint64
+	end         uint64
+	now         uint64 // ts_event of the current event
+	tsRecv      uint64 // ts_recv of the current event
+	tsInDelta   int32  // ts_in_delta of the current event
+	interval    uint64 // of a subsampled or aggregated schema; 0 if none
+	intervalEnd uint64 // end of the current interval
+
+	pending      []dbn.RecordEncoder // records generated, but not yet returned by Next
+	started      bool
+	done         bool
+	numRecords   int
+	numCorrupted int
+}
+
+// NewGenerator returns a Generator of the stream of `opts`, filling in their defaults.
+// Returns an error if the options are invalid.
+func NewGenerator(opts Options) (*Generator, error) {
+	if opts.Schema == dbn.Schema_Mixed || opts.Schema.String() == "" {
+		return nil, fmt.Errorf("unsupported schema %d", opts.Schema)
+	}
+	if opts.Version == 0 {
+		opts.Version = dbn.HeaderVersion3
+	}
+	if opts.Version > dbn.HeaderVersion3 {
+		return nil, fmt.Errorf("unsupported DBN version %d", opts.Version)
+	}
+	if opts.Version == dbn.HeaderVersion1 && opts.Schema == dbn.Schema_Definition {
+		return nil, errors.New("definitions require DBN version 2 or later")
+	}
+	if opts.Dataset == 0 {
+		opts.Dataset = dbn.Dataset_XnasItch
+	}
+	if opts.Start.IsZero() {
+		opts.Start = DefaultStart
+	}
+	if opts.Duration == 0 {
+		opts.Duration = time.Minute
+	}
+	if opts.Duration < 0 || opts.Count < 0 {
+		return nil, errors.New("duration and count must not be negative")
+	}
+	if len(opts.Symbols) == 0 {
+		if opts.NumInstruments == 0 {
+			opts.NumInstruments = 1
+		}
+		for i := range opts.NumInstruments {
+			opts.Symbols = append(opts.Symbols, fmt.Sprintf("SYN%d", i+1))
+		}
+	} else if opts.NumInstruments != 0 && opts.NumInstruments != len(opts.Symbols) {
+		return nil, fmt.Errorf("%d symbols given for %d instruments", len(opts.Symbols), opts.NumInstruments)
+	}
+	if len(opts.Symbols) == 0 {
+		return nil, errors.New("there must be at least one instrument")
+	}
+	if opts.FirstInstrumentID == 0 {
+		opts.FirstInstrumentID = 1
+	}
+	if opts.Rate == 0 {
+		opts.Rate = 100
+	}
+	if opts.Price == 0 {
+		opts.Price = 100
+	}
+	if opts.TickSize == 0 {
+		opts.TickSize = 0.01
+	}
+	if opts.Volatility == 0 {
+		opts.Volatility = 0.3
+	}
+	if opts.Depth == 0 {
+		opts.Depth = 10
+	}
+	if opts.LotSize == 0 {
+		opts.LotSize = 100
+	}
+	if opts.MaxLots == 0 {
+		opts.MaxLots = 10
+	}
+	if opts.Rate < 0 || opts.Price < 0 || opts.TickSize < 0 || opts.Volatility < 0 || opts.Depth < 0 || opts.MaxLots < 0 {
+		return nil, errors.New("rate, price, tick size, volatility, depth, and max lots must not be negative")
+	}
+	if opts.CorruptionRate < 0 || opts.CorruptionRate > 1 {
+		return nil, fmt.Errorf("corruption rate %g is not between 0 and 1", opts.CorruptionRate)
+	}
+	if len(opts.Corruptions) == 0 {
+		opts.Corruptions = AllCorruptions
+	}
+
+	g := &Generator{
+		opts:        opts,
+		rng:         rand.New(rand.NewPCG(opts.Seed, 1)),
+		tickSize:    int64(math.Round(opts.TickSize * dbn.FIXED_PRICE_SCALE)),
+		nextOrderID: 1,
+		start:       uint64(opts.Start.UnixNano()),
+	}
+	if g.tickSize == 0 {
+		return nil, fmt.Errorf("tick size %g is too small", opts.TickSize)
+	}
+	g.end = g.start + uint64(opts.Duration)
+	if publishers := opts.Dataset.Publishers(); len(publishers) > 0 {
+		g.publisherID = uint16(publishers[0])
+	}
+	if interval := schemaInterval(opts.Schema); interval > 0 {
+		g.interval = uint64(interval)
+		g.intervalEnd = (g.start/g.interval + 1) * g.interval
+	}
+	for i, symbol := range opts.Symbols {
+		g.instruments = append(g.instruments, &instrument{
+			id:     opts.FirstInstrumentID + uint32(i),
+			symbol: symbol,
+			fair:   opts.Price / opts.TickSize,
+			book:   dbn_backtest.NewBook(),
+			index:  make(map[uint64]int),
+			lastPx: dbn.UNDEF_PRICE,
+		})
+	}
+	g.metadata = g.newMetadata()
+	return g, nil
+}
+
+// newMetadata returns the Metadata of the stream, which maps each raw symbol to its instrument ID.
+func (g *Generator) newMetadata() *dbn.Metadata {
+	symbolCstrLen := uint16(dbn.MetadataV2_SymbolCstrLen)
+	if g.opts.Version == dbn.HeaderVersion1 {
+		symbolCstrLen = dbn.MetadataV1_SymbolCstrLen
+	}
+	metadata := &dbn.Metadata{
+		VersionNum:    g.opts.Version,
+		Schema:        g.opts.Schema,
+		Start:         g.start,
+		End:           g.end,
+		Limit:         uint64(g.opts.Count),
+		StypeIn:       dbn.SType_RawSymbol,
+		StypeOut:      dbn.SType_InstrumentId,
+		SymbolCstrLen: symbolCstrLen,
+		Dataset:       g.opts.Dataset.String(),
+	}
+	startDate := dbn.TimeToYMD(g.opts.Start.UTC())
+	endDate := dbn.TimeToYMD(time.Unix(0, int64(g.end-1)).UTC().AddDate(0, 0, 1))
+	for _, ins := range g.instruments {
+		metadata.Symbols = append(metadata.Symbols, ins.symbol)
+		metadata.Mappings = append(metadata.Mappings, dbn.SymbolMapping{
+			RawSymbol: ins.symbol,
+			Intervals: []dbn.MappingInterval{{StartDate: startDate, EndDate: endDate, Symbol: strconv.FormatUint(uint64(ins.id), 10)}},
+		})
+	}
+	return metadata
+}
+
+// Metadata returns the Metadata of the stream.
+func (g *Generator) Metadata() *dbn.Metadata {
+	return g.metadata
+}
+
+// NumRecords returns the number of records returned by Next so far.
+func (g *Generator) NumRecords() int {
+	return g.numRecords
+}
+
+// Next returns the next record of the stream.  Returns false at the end of the stream,
+// after Options.Duration of simulated time or Options.Count records.
+func (g *Generator) Next() (dbn.RecordEncoder, bool) {
+	if g.opts.Count > 0 && g.numRecords >= g.opts.Count {
+		return nil, false
+	}
+	for len(g.pending) == 0 {
+		if g.done {
+			return nil, false
+		}
+		g.step()
+	}
+	record := g.pending[0]
+	g.pending[0] = nil
+	g.pending = g.pending[1:]
+	g.numRecords++
+	return record, true
+}
+
+// step advances the simulation by an event, generating its records into pending.
+func (g *Generator) step() {
+	if !g.started {
+		g.started = true
+		g.now = g.start
+		g.newEventTimes()
+		g.begin()
+		return
+	}
+	rate := g.opts.Rate * float64(len(g.instruments))
+	next := g.now + 1 + uint64(g.rng.ExpFloat64()/rate*float64(time.Second))
+	if g.interval > 0 {
+		for g.intervalEnd <= next && g.intervalEnd < g.end {
+			g.closeInterval()
+		}
+	}
+	if next >= g.end {
+		g.finish()
+		g.done = true
+		return
+	}
+	g.now = next
+	g.newEventTimes()
+	g.simulate(g.instruments[g.rng.IntN(len(g.instruments))])
+}
+
+// newEventTimes sets the ts_recv and ts_in_delta of the current event; ts_recv never goes backwards.
+func (g *Generator) newEventTimes() {
+	g.tsInDelta = int32(1_000 + g.rng.IntN(20_000))
+	g.tsRecv = max(g.tsRecv, g.now+uint64(g.tsInDelta)+uint64(g.rng.IntN(5_000)))
+}
+
+// begin generates the records at the start of the stream: symbol mappings, definitions, and
+// statuses, then each instrument's starting book.
+func (g *Generator) begin() {
+	for _, ins := range g.instruments {
+		if g.opts.SymbolMappings {
+			g.pending = append(g.pending, &dbn.SymbolMappingMsg{
+				Header:         g.newHeader(dbn.RType_SymbolMapping, ins),
+				StypeIn:        dbn.SType_RawSymbol,
+				StypeInSymbol:  ins.symbol,
+				StypeOut:       dbn.SType_InstrumentId,
+				StypeOutSymbol: strconv.FormatUint(uint64(ins.id), 10),
+				StartTs:        g.start,
+				EndTs:          g.end,
+			})
+		}
+	}
+	for _, ins := range g.instruments {
+		switch g.opts.Schema {
+		case dbn.Schema_Definition:
+			g.pending = append(g.pending, g.newDefinition(ins))
+		case dbn.Schema_Status:
+			g.pending = append(g.pending, g.newStatus(ins, dbn.StatusAction_Trading, dbn.TriState_Yes))
+		}
+	}
+	for _, ins := range g.instruments {
+		g.apply(ins, g.snapshot(ins))
+	}
+}
+
+// finish generates the records at the end of the stream: those of the last interval, and
+// closing statistics and statuses.
+func (g *Generator) finish() {
+	g.now = g.end
+	g.newEventTimes()
+	if g.interval > 0 {
+		g.closeInterval()
+	}
+	for _, ins := range g.instruments {
+		switch g.opts.Schema {
+		case dbn.Schema_Statistics:
+			if ins.lastPx != dbn.UNDEF_PRICE {
+				g.pending = append(g.pending,
+					g.newStat(ins, dbn.StatType_ClosePrice, ins.lastPx, dbn.StatMsgV3_UNDEF_STAT_QUANTITY),
+					g.newStat(ins, dbn.StatType_ClearedVolume, dbn.UNDEF_PRICE, int64(ins.volume)))
+			}
+		case dbn.Schema_Status:
+			g.pending = append(g.pending, g.newStatus(ins, dbn.StatusAction_Close, dbn.TriState_No))
+		}
+	}
+}
+
+// closeInterval generates the records of the subsampled or aggregated schema for the current
+// interval of each instrument with events in it, and starts the next interval.
+func (g *Generator) closeInterval() {
+	intervalStart := g.intervalEnd - g.interval
+	rtype := schemaRType(g.opts.Schema)
+	for _, ins := range g.instruments {
+		if !ins.active {
+			continue
+		}
+		switch g.opts.Schema {
+		case dbn.Schema_Ohlcv1S, dbn.Schema_Ohlcv1M, dbn.Schema_Ohlcv1H, dbn.Schema_Ohlcv1D, dbn.Schema_OhlcvEod:
+			if ins.bar.Volume > 0 {
+				bar := ins.bar
+				bar.Header = dbn.RHeader{RType: rtype, PublisherID: g.publisherID, InstrumentID: ins.id, TsEvent: intervalStart}
+				g.pending = append(g.pending, &bar)
+			}
+		case dbn.Schema_Bbo1S, dbn.Schema_Bbo1M:
+			g.pending = append(g.pending, &dbn.BboMsg{
+				Header:   dbn.RHeader{RType: rtype, PublisherID: g.publisherID, InstrumentID: ins.id, TsEvent: ins.lastTsEvent},
+				Price:    ins.lastPx,
+				Size:     ins.lastSz,
+				Side:     byte(ins.lastSide),
+				Flags:    uint8(dbn.F_LAST),
+				TsRecv:   g.intervalEnd,
+				Sequence: ins.sequence,
+				Level:    ins.pairs(1)[0],
+			})
+		case dbn.Schema_Cbbo1S, dbn.Schema_Cbbo1M:
+			g.pending = append(g.pending, &dbn.Cmbp1Msg{
+				Header:   dbn.RHeader{RType: rtype, PublisherID: g.publisherID, InstrumentID: ins.id, TsEvent: ins.lastTsEvent},
+				Price:    ins.lastPx,
+				Size:     ins.lastSz,
+				Side:     byte(ins.lastSide),
+				Flags:    uint8(dbn.F_LAST),
+				TsRecv:   g.intervalEnd,
+				Sequence: ins.sequence,
+				Level:    g.consolidated(ins.pairs(1)[0]),
+			})
+		case dbn.Schema_Imbalance:
+			g.pending = append(g.pending, g.newImbalance(ins))
+		}
+		ins.active = false
+		ins.bar = dbn.OhlcvMsg{}
+	}
+	g.intervalEnd += g.interval
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// newHeader returns the header of a record of an instrument at the current time.
+func (g *Generator) newHeader(rtype dbn.RType, ins *instrument) dbn.RHeader {
+	return dbn.RHeader{RType: rtype, PublisherID: g.publisherID, InstrumentID: ins.id, TsEvent: g.now}
+}
+
+// consolidated returns a level with the publisher of each side, as in CMBP-1 and CBBO.
+func (g *Generator) consolidated(pair dbn.BidAskPair) dbn.ConsolidatedBidAskPair {
+	level := dbn.ConsolidatedBidAskPair{BidPx: pair.BidPx, AskPx: pair.AskPx, BidSz: pair.BidSz, AskSz: pair.AskSz}
+	if pair.BidPx != dbn.UNDEF_PRICE {
+		level.BidPb = g.publisherID
+	}
+	if pair.AskPx != dbn.UNDEF_PRICE {
+		level.AskPb = g.publisherID
+	}
+	return level
+}
+
+// newDefinition returns the definition of an instrument, an equity of the dataset's venue.
+func (g *Generator) newDefinition(ins *instrument) *dbn.InstrumentDefMsg {
+	def := &dbn.InstrumentDefMsg{
+		Header:                  g.newHeader(dbn.RType_InstrumentDef, ins),
+		TsRecv:                  g.tsRecv,
+		MinPriceIncrement:       g.tickSize,
+		DisplayFactor:           int64(dbn.FIXED_PRICE_SCALE),
+		Expiration:              dbn.UNDEF_TIMESTAMP,
+		Activation:              dbn.UNDEF_TIMESTAMP,
+		HighLimitPrice:          dbn.UNDEF_PRICE,
+		LowLimitPrice:           dbn.UNDEF_PRICE,
+		MaxPriceVariation:       dbn.UNDEF_PRICE,
+		UnitOfMeasureQty:        dbn.UNDEF_PRICE,
+		MinPriceIncrementAmount: dbn.UNDEF_PRICE,
+		PriceRatio:              dbn.UNDEF_PRICE,
+		StrikePrice:             dbn.UNDEF_PRICE,
+		RawInstrumentID:         uint64(ins.id),
+		LegPrice:                dbn.UNDEF_PRICE,
+		LegDelta:                dbn.UNDEF_PRICE,
+		MinLotSizeRoundLot:      int32(g.opts.LotSize),
+		InstrumentClass:         byte(dbn.InstrumentClass_Stock),
+		MatchAlgorithm:          byte(dbn.MatchAlgorithm_Fifo),
+		SecurityUpdateAction:    byte(dbn.Add),
+		UserDefinedInstrument:   dbn.UserDefinedInstrument_No,
+	}
+	copyCStr(def.RawSymbol[:], ins.symbol)
+	copyCStr(def.Asset[:], ins.symbol)
+	copyCStr(def.Currency[:], "USD")
+	copyCStr(def.SecurityType[:], "EQ")
+	if publisher := dbn.Publisher(g.publisherID); publisher != 0 {
+		copyCStr(def.Exchange[:], publisher.Venue().String())
+	}
+	return def
+}
+
+// copyCStr copies `str` into the fixed-length c-string `b`, truncating it to leave its null terminator.
+func copyCStr(b []byte, str string) {
+	copy(b[:len(b)-1], str)
+}
+
+// newStatus returns a status record of an instrument at the current time.
+func (g *Generator) newStatus(ins *instrument, action dbn.StatusAction, isTrading dbn.TradingEvent) *dbn.StatusMsg {
+	return &dbn.StatusMsg{
+		Header:                g.newHeader(dbn.RType_Status, ins),
+		TsRecv:                g.tsRecv,
+		Action:                uint16(action),
+		Reason:                uint16(dbn.StatusReason_Scheduled),
+		IsTrading:             uint8(isTrading),
+		IsQuoting:             uint8(isTrading),
+		IsShortSellRestricted: uint8(dbn.TriState_No),
+	}
+}
+
+// newStat returns a statistics record of an instrument at the current time.
+func (g *Generator) newStat(ins *instrument, statType dbn.StatType, price int64, quantity int64) *dbn.StatMsg {
+	return &dbn.StatMsg{
+		Header:       g.newHeader(dbn.RType_Statistics, ins),
+		TsRecv:       g.tsRecv,
+		TsRef:        dbn.UNDEF_TIMESTAMP,
+		Price:        price,
+		Quantity:     quantity,
+		Sequence:     ins.sequence,
+		TsInDelta:    g.tsInDelta,
+		StatType:     uint16(statType),
+		UpdateAction: uint8(dbn.StatUpdateAction_New),
+	}
+}
+
+// newImbalance returns a closing auction's imbalance of an instrument at the end of the current
+// interval, referenced to the midpoint of its book.
+func (g *Generator) newImbalance(ins *instrument) *dbn.ImbalanceMsg {
+	refPrice := ins.lastPx
+	if bid, ok := ins.book.BestBid(); ok {
+		if ask, ok := ins.book.BestAsk(); ok {
+			refPrice = (bid.Price + ask.Price) / 2 / g.tickSize * g.tickSize
+		}
+	}
+	side := dbn.Side_Bid
+	if g.rng.IntN(2) == 0 {
+		side = dbn.Side_Ask
+	}
+	return &dbn.ImbalanceMsg{
+		Header:               dbn.RHeader{RType: dbn.RType_Imbalance, PublisherID: g.publisherID, InstrumentID: ins.id, TsEvent: g.intervalEnd},
+		TsRecv:               g.intervalEnd,
+		RefPrice:             refPrice,
+		ContBookClrPrice:     refPrice,
+		AuctInterestClrPrice: refPrice,
+		SsrFillingPrice:      dbn.UNDEF_PRICE,
+		IndMatchPrice:        dbn.UNDEF_PRICE,
+		UpperCollar:          dbn.UNDEF_PRICE,
+		LowerCollar:          dbn.UNDEF_PRICE,
+		PairedQty:            g.newSize() * 10,
+		TotalImbalanceQty:    g.newSize(),
+		AuctionType:          'C',
+		Side:                 byte(side),
+		UnpairedSide:         byte(dbn.Side_None),
+		SignificantImbalance: '~',
+	}
+}
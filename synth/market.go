@@ -0,0 +1,408 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_synth
+
+import (
+	"math"
+	"slices"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_backtest "github.com/NimbleMarkets/dbn-go/backtest"
+)
+
+// Weights of the kinds of events, from a random number in [0,1).
+const (
+	addWeight    = 0.5  // an order is added
+	cancelWeight = 0.3  // an order is cancelled, fully or partly
+	modifyWeight = 0.05 // an order is reduced or moved away from the spread
+	// the rest, a market order trades
+)
+
+// meanLevelsAway is the mean number of levels from the spread at which orders are added.
+const meanLevelsAway = 2.0
+
+// ordersPerLevel bounds the resting orders of a book to this many per level of Options.Depth;
+// a book at the bound has orders cancelled rather than added.
+const ordersPerLevel = 4
+
+// instrument is the simulated market of an instrument: its MBO book around a random-walk price.
+type instrument struct {
+	id       uint32
+	symbol   string
+	fair     float64 // random-walk price, in ticks
+	book     *dbn_backtest.Book
+	orderIDs []uint64       // resting orders, to choose from
+	index    map[uint64]int // of each resting order in orderIDs
+	sequence uint32
+
+	lastTsEvent uint64       // of its latest event
+	active      bool         // had events in the current interval
+	lastPx      int64        // of its latest trade, or UNDEF_PRICE
+	lastSz      uint32       // of its latest trade
+	lastSide    dbn.Side     // aggressor of its latest trade
+	high        int64        // of its trades
+	low         int64        // of its trades
+	volume      uint64       // of its trades
+	bar         dbn.OhlcvMsg // of its trades in the current interval, if its Volume is positive
+}
+
+// track updates the resting orders to choose from after a record of an order was applied to the book.
+func (ins *instrument) track(orderID uint64) {
+	_, resting := ins.book.Order(orderID)
+	i, tracked := ins.index[orderID]
+	switch {
+	case resting && !tracked:
+		ins.index[orderID] = len(ins.orderIDs)
+		ins.orderIDs = append(ins.orderIDs, orderID)
+	case !resting && tracked:
+		last := ins.orderIDs[len(ins.orderIDs)-1]
+		ins.orderIDs[i] = last
+		ins.index[last] = i
+		ins.orderIDs = ins.orderIDs[:len(ins.orderIDs)-1]
+		delete(ins.index, orderID)
+	}
+}
+
+// pairs returns the top `n` levels of the book; missing levels have undefined prices.
+func (ins *instrument) pairs(n int) []dbn.BidAskPair {
+	bids := ins.book.Levels(dbn.Side_Bid, n)
+	asks := ins.book.Levels(dbn.Side_Ask, n)
+	pairs := make([]dbn.BidAskPair, n)
+	for i := range pairs {
+		pairs[i] = dbn.BidAskPair{BidPx: dbn.UNDEF_PRICE, AskPx: dbn.UNDEF_PRICE}
+		if i < len(bids) {
+			pairs[i].BidPx, pairs[i].BidSz, pairs[i].BidCt = bids[i].Price, uint32(bids[i].Size), uint32(bids[i].Count)
+		}
+		if i < len(asks) {
+			pairs[i].AskPx, pairs[i].AskSz, pairs[i].AskCt = asks[i].Price, uint32(asks[i].Size), uint32(asks[i].Count)
+		}
+	}
+	return pairs
+}
+
+// opposite returns the other side of the book from `side`.
+func opposite(side dbn.Side) dbn.Side {
+	if side == dbn.Side_Bid {
+		return dbn.Side_Ask
+	}
+	return dbn.Side_Bid
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// newSize returns the size of a new order: 1 to MaxLots lots.
+func (g *Generator) newSize() uint32 {
+	return g.opts.LotSize * uint32(1+g.rng.IntN(g.opts.MaxLots))
+}
+
+// newMbo returns an MBO record of an instrument's event at the current time, with its next sequence number.
+func (g *Generator) newMbo(ins *instrument, action dbn.Action, side dbn.Side, orderID uint64, price int64, size uint32) *dbn.MboMsg {
+	ins.sequence++
+	return &dbn.MboMsg{
+		Header:    g.newHeader(dbn.RType_Mbo, ins),
+		OrderID:   orderID,
+		Price:     price,
+		Size:      size,
+		Action:    uint8(action),
+		Side:      uint8(side),
+		TsRecv:    g.tsRecv,
+		TsInDelta: g.tsInDelta,
+		Sequence:  ins.sequence,
+	}
+}
+
+// snapshot returns the records of an instrument's starting book: a clear, then Options.Depth
+// levels of an order on each side of its price.
+func (g *Generator) snapshot(ins *instrument) []*dbn.MboMsg {
+	records := []*dbn.MboMsg{g.newMbo(ins, dbn.Action_Clear, dbn.Side_None, 0, dbn.UNDEF_PRICE, 0)}
+	for k := range g.opts.Depth {
+		for _, side := range []dbn.Side{dbn.Side_Bid, dbn.Side_Ask} {
+			records = append(records, g.newMbo(ins, dbn.Action_Add, side, g.nextOrderID, g.priceAway(ins, side, k), g.newSize()))
+			g.nextOrderID++
+		}
+	}
+	for _, record := range records {
+		record.Flags |= uint8(dbn.F_SNAPSHOT)
+	}
+	return records
+}
+
+// priceAway returns the price `k` levels away from the inside of a side around the instrument's
+// random-walk price, clamped to at least one tick.
+func (g *Generator) priceAway(ins *instrument, side dbn.Side, k int) int64 {
+	var ticks int64
+	if side == dbn.Side_Bid {
+		ticks = int64(math.Ceil(ins.fair)) - 1 - int64(k)
+	} else {
+		ticks = int64(math.Floor(ins.fair)) + 1 + int64(k)
+	}
+	return max(ticks, 1) * g.tickSize
+}
+
+// simulate generates an event of an instrument at the current time and applies it.  The random
+// walk moves first; then an order is added, cancelled, or modified, or a market order trades.
+// Added orders which cross the book trade first, so the random walk moves the book.  Books keep
+// both sides: events which would empty a side add an order instead, and orders trading against
+// a side leave its deepest level.
+func (g *Generator) simulate(ins *instrument) {
+	ins.fair = max(ins.fair+g.rng.NormFloat64()*g.opts.Volatility, 1)
+	_, hasBid := ins.book.BestBid()
+	_, hasAsk := ins.book.BestAsk()
+	side := dbn.Side_Bid
+	if g.rng.IntN(2) == 0 {
+		side = dbn.Side_Ask
+	}
+
+	r := g.rng.Float64()
+	if r < addWeight && len(ins.orderIDs) >= ordersPerLevel*2*g.opts.Depth {
+		r = addWeight
+	}
+	var order dbn_backtest.BookOrder
+	var marketSize uint32
+	switch {
+	case !hasBid || !hasAsk || len(ins.orderIDs) == 0 || r < addWeight:
+	case r < addWeight+cancelWeight+modifyWeight:
+		// an order whose cancel would empty its side is added to instead
+		order, _ = ins.book.Order(ins.orderIDs[g.rng.IntN(len(ins.orderIDs))])
+		if levels := ins.book.Levels(order.Side, 2); len(levels) == 1 && levels[0].Count == 1 {
+			r = 0
+		}
+	default:
+		// a market order leaves the deepest level of the side it trades against
+		levels := ins.book.Levels(opposite(side), -1)
+		var available uint64
+		for _, level := range levels[:len(levels)-1] {
+			available += level.Size
+		}
+		if marketSize = uint32(min(uint64(g.newSize()), available)); marketSize == 0 {
+			r = 0
+		}
+	}
+
+	var records []*dbn.MboMsg
+	switch {
+	case !hasBid || !hasAsk || len(ins.orderIDs) == 0 || r < addWeight:
+		if !hasBid {
+			side = dbn.Side_Bid
+		} else if !hasAsk {
+			side = dbn.Side_Ask
+		}
+		price := g.priceAway(ins, side, int(g.rng.ExpFloat64()*meanLevelsAway))
+		if levels := ins.book.Levels(opposite(side), -1); len(levels) > 0 {
+			// an order crossing the book leaves the deepest level of the other side
+			if deepest := levels[len(levels)-1].Price; side == dbn.Side_Bid {
+				price = min(price, deepest-g.tickSize)
+			} else {
+				price = max(price, deepest+g.tickSize)
+			}
+		}
+		size := g.newSize()
+		records, size = g.match(ins, side, price, size)
+		if size > 0 {
+			records = append(records, g.newMbo(ins, dbn.Action_Add, side, g.nextOrderID, price, size))
+			g.nextOrderID++
+		}
+	case r < addWeight+cancelWeight:
+		size := order.Size
+		if lots := int(order.Size / g.opts.LotSize); lots > 1 && g.rng.IntN(5) == 0 {
+			size = g.opts.LotSize * uint32(1+g.rng.IntN(lots-1))
+		}
+		records = append(records, g.newMbo(ins, dbn.Action_Cancel, order.Side, order.OrderID, order.Price, size))
+	case r < addWeight+cancelWeight+modifyWeight:
+		price, size := order.Price, order.Size
+		if size > g.opts.LotSize && g.rng.IntN(2) == 0 {
+			size -= g.opts.LotSize
+		} else if order.Side == dbn.Side_Bid {
+			price = max(price-g.tickSize, g.tickSize)
+		} else {
+			price += g.tickSize
+		}
+		records = append(records, g.newMbo(ins, dbn.Action_Modify, order.Side, order.OrderID, price, size))
+	default:
+		limit := int64(math.MaxInt64)
+		if side == dbn.Side_Ask {
+			limit = math.MinInt64
+		}
+		records, _ = g.match(ins, side, limit, marketSize)
+	}
+	if len(records) > 0 {
+		g.apply(ins, records)
+	}
+}
+
+// match returns the records of an order of the aggressor `side` trading against the other side
+// of the book, up to `size` and through the `limit` price, and its size left.  Each resting order
+// filled is a trade, a fill, and then a cancel of the size filled, as venues report them.
+func (g *Generator) match(ins *instrument, side dbn.Side, limit int64, size uint32) ([]*dbn.MboMsg, uint32) {
+	other := opposite(side)
+	var records []*dbn.MboMsg
+	for _, level := range ins.book.Levels(other, -1) {
+		if size == 0 || (side == dbn.Side_Bid && level.Price > limit) || (side == dbn.Side_Ask && level.Price < limit) {
+			break
+		}
+		for _, orderID := range ins.book.QueueOrderIDs(other, level.Price) {
+			if size == 0 {
+				break
+			}
+			order, _ := ins.book.Order(orderID)
+			filled := min(order.Size, size)
+			size -= filled
+			records = append(records,
+				g.newMbo(ins, dbn.Action_Trade, side, 0, level.Price, filled),
+				g.newMbo(ins, dbn.Action_Fill, other, orderID, level.Price, filled),
+				g.newMbo(ins, dbn.Action_Cancel, other, orderID, level.Price, filled))
+		}
+	}
+	return records, size
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// apply applies the MBO records of an event to the instrument's book, generating the records
+// of the schema derived from them.  The last record of the event has F_LAST set.
+func (g *Generator) apply(ins *instrument, records []*dbn.MboMsg) {
+	records[len(records)-1].Flags |= uint8(dbn.F_LAST)
+	numPending := len(g.pending)
+	depth := 0
+	switch g.opts.Schema {
+	case dbn.Schema_Mbp1, dbn.Schema_Cmbp1:
+		depth = 1
+	case dbn.Schema_Mbp10:
+		depth = 10
+	}
+	for _, record := range records {
+		var before []dbn.BidAskPair
+		if depth > 0 {
+			before = ins.pairs(depth)
+		}
+		ins.book.ApplyMbo(record)
+		ins.track(record.OrderID)
+		ins.active, ins.lastTsEvent = true, record.Header.TsEvent
+		if g.opts.Schema == dbn.Schema_Mbo {
+			g.pending = append(g.pending, record)
+		}
+		switch dbn.Action(record.Action) {
+		case dbn.Action_Trade:
+			g.trade(ins, record)
+		case dbn.Action_Add, dbn.Action_Cancel, dbn.Action_Modify:
+			if depth == 0 {
+				continue
+			}
+			after := ins.pairs(depth)
+			if !slices.Equal(before, after) {
+				g.appendMbp(ins, record, levelDepth(after, before, dbn.Side(record.Side), record.Price), after)
+			}
+		}
+	}
+	if len(g.pending) > numPending {
+		setLast(g.pending[len(g.pending)-1])
+	}
+}
+
+// trade updates an instrument's trades with a trade record, generating the records of the schema derived from it.
+func (g *Generator) trade(ins *instrument, record *dbn.MboMsg) {
+	price, size := record.Price, record.Size
+	if ins.bar.Volume == 0 {
+		ins.bar.Open, ins.bar.High, ins.bar.Low = price, price, price
+	}
+	ins.bar.High, ins.bar.Low = max(ins.bar.High, price), min(ins.bar.Low, price)
+	ins.bar.Close = price
+	ins.bar.Volume += uint64(size)
+
+	opening := ins.lastPx == dbn.UNDEF_PRICE
+	newHigh, newLow := opening || price > ins.high, opening || price < ins.low
+	if newHigh {
+		ins.high = price
+	}
+	if newLow {
+		ins.low = price
+	}
+	ins.lastPx, ins.lastSz, ins.lastSide = price, size, dbn.Side(record.Side)
+	ins.volume += uint64(size)
+
+	switch g.opts.Schema {
+	case dbn.Schema_Trades:
+		g.pending = append(g.pending, &dbn.Mbp0Msg{
+			Header:    g.newHeader(dbn.RType_Mbp0, ins),
+			Price:     price,
+			Size:      size,
+			Action:    uint8(dbn.Action_Trade),
+			Side:      record.Side,
+			Flags:     record.Flags &^ uint8(dbn.F_LAST),
+			TsRecv:    record.TsRecv,
+			TsInDelta: record.TsInDelta,
+			Sequence:  record.Sequence,
+		})
+	case dbn.Schema_Mbp1, dbn.Schema_Tbbo, dbn.Schema_Cmbp1, dbn.Schema_Tcbbo:
+		g.appendMbp(ins, record, 0, ins.pairs(1))
+	case dbn.Schema_Mbp10:
+		g.appendMbp(ins, record, 0, ins.pairs(10))
+	case dbn.Schema_Statistics:
+		if opening {
+			g.pending = append(g.pending, g.newStat(ins, dbn.StatType_OpeningPrice, price, dbn.StatMsgV3_UNDEF_STAT_QUANTITY))
+		}
+		if newHigh {
+			g.pending = append(g.pending, g.newStat(ins, dbn.StatType_TradingSessionHighPrice, price, dbn.StatMsgV3_UNDEF_STAT_QUANTITY))
+		}
+		if newLow {
+			g.pending = append(g.pending, g.newStat(ins, dbn.StatType_TradingSessionLowPrice, price, dbn.StatMsgV3_UNDEF_STAT_QUANTITY))
+		}
+	}
+}
+
+// appendMbp appends the MBP record of the schema for an MBO record, with the book's levels after it.
+func (g *Generator) appendMbp(ins *instrument, record *dbn.MboMsg, depth uint8, levels []dbn.BidAskPair) {
+	header := g.newHeader(dbn.RType_Mbp1, ins)
+	flags := record.Flags &^ uint8(dbn.F_LAST)
+	switch g.opts.Schema {
+	case dbn.Schema_Mbp1, dbn.Schema_Tbbo:
+		g.pending = append(g.pending, &dbn.Mbp1Msg{
+			Header: header, Price: record.Price, Size: record.Size, Action: record.Action, Side: record.Side,
+			Flags: flags, Depth: depth, TsRecv: record.TsRecv, TsInDelta: record.TsInDelta, Sequence: record.Sequence,
+			Level: levels[0],
+		})
+	case dbn.Schema_Mbp10:
+		mbp10 := &dbn.Mbp10Msg{
+			Header: header, Price: record.Price, Size: record.Size, Action: record.Action, Side: record.Side,
+			Flags: flags, Depth: depth, TsRecv: record.TsRecv, TsInDelta: record.TsInDelta, Sequence: record.Sequence,
+		}
+		mbp10.Header.RType = dbn.RType_Mbp10
+		copy(mbp10.Levels[:], levels)
+		g.pending = append(g.pending, mbp10)
+	case dbn.Schema_Cmbp1, dbn.Schema_Tcbbo:
+		header.RType = schemaRType(g.opts.Schema)
+		g.pending = append(g.pending, &dbn.Cmbp1Msg{
+			Header: header, Price: record.Price, Size: record.Size, Action: record.Action, Side: record.Side,
+			Flags: flags, TsRecv: record.TsRecv, TsInDelta: record.TsInDelta, Sequence: record.Sequence,
+			Level: g.consolidated(levels[0]),
+		})
+	}
+}
+
+// levelDepth returns the index of the level at a price of a side, after a change or else before it.
+func levelDepth(after, before []dbn.BidAskPair, side dbn.Side, price int64) uint8 {
+	for _, levels := range [][]dbn.BidAskPair{after, before} {
+		for i, pair := range levels {
+			if (side == dbn.Side_Bid && pair.BidPx == price) || (side == dbn.Side_Ask && pair.AskPx == price) {
+				return uint8(i)
+			}
+		}
+	}
+	return 0
+}
+
+// setLast sets F_LAST on a record derived from MBO, marking the end of its event.
+func setLast(record dbn.RecordEncoder) {
+	switch r := record.(type) {
+	case *dbn.MboMsg:
+		r.Flags |= uint8(dbn.F_LAST)
+	case *dbn.Mbp0Msg:
+		r.Flags |= uint8(dbn.F_LAST)
+	case *dbn.Mbp1Msg:
+		r.Flags |= uint8(dbn.F_LAST)
+	case *dbn.Mbp10Msg:
+		r.Flags |= uint8(dbn.F_LAST)
+	case *dbn.Cmbp1Msg:
+		r.Flags |= uint8(dbn.F_LAST)
+	}
+}
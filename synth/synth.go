@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Neomantra Corp
+
+// Package dbn_synth generates synthetic DBN streams of any schema and version, as reproducible
+// inputs for tests, benchmarks, and fuzzing.  Each instrument's market is simulated as MBO events
+// on an internally consistent order book around a random-walk price, and the other schemas are
+// derived from it, such as MBP-10 from the book's levels and OHLCV from its trades.  Streams are
+// determined by their Options, including a Seed, and may have corruption injected as written.
+package dbn_synth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+
+// DefaultStart is the start of a stream whose Options have no Start: 9:30 New York time on a Friday.
+var DefaultStart = time.Date(2026, time.January, 2, 14, 30, 0, 0, time.UTC)
+
+// Options are the options of a Generator.  Zero values are replaced by the defaults noted.
+type Options struct {
+	Schema            dbn.Schema    // Schema of the records; any but dbn.Schema_Mixed
+	Version           uint8         // DBN version, 1 to 3; 0 is dbn.HeaderVersion3
+	Dataset           dbn.Dataset   // Dataset of the metadata, whose first publisher is the records'; 0 is XNAS.ITCH
+	Seed              uint64        // Seed of the random walks and events; the same Options generate the same stream
+	Start             time.Time     // Time of the first records; zero is DefaultStart
+	Duration          time.Duration // Length of simulated time; 0 is one minute
+	Count             int           // Maximum number of records; 0 is unlimited
+	Symbols           []string      // Raw symbols of the instruments; empty is SYN1, SYN2, and so on
+	NumInstruments    int           // Number of instruments, if there are no Symbols; 0 is 1
+	FirstInstrumentID uint32        // Instrument ID of the first instrument, incrementing for the others; 0 is 1
+	SymbolMappings    bool          // Starts the stream with each instrument's SymbolMappingMsg, as live streams do
+	Rate              float64       // Mean MBO events per second per instrument; 0 is 100
+	Price             float64       // Starting price of each instrument; 0 is 100
+	TickSize          float64       // Minimum price increment; 0 is 0.01
+	Volatility        float64       // Standard deviation of the random walk per event, in ticks; 0 is 0.3
+	Depth             int           // Price levels on each side of the starting book; 0 is 10
+	LotSize           uint32        // Order sizes are multiples of it; 0 is 100
+	MaxLots           int           // Orders are of 1 to MaxLots lots; 0 is 10
+	CorruptionRate    float64       // Probability that each record written is corrupted, from 0 to 1
+	Corruptions       []Corruption  // Kinds of corruption to choose from; empty is all of them
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// Corruption is a kind of corruption injected into a record as it is written.
+type Corruption uint8
+
+const (
+	// Corruption_Truncate writes part of the record and ends the stream.
+	Corruption_Truncate Corruption = iota
+	// Corruption_Length changes the record's length, so the records after it are misframed.
+	Corruption_Length
+	// Corruption_RType changes the record's rtype to an undefined one.
+	Corruption_RType
+	// Corruption_Bytes flips bits of the record's body, leaving its header intact.
+	Corruption_Bytes
+	// Corruption_Timestamp moves the record's ts_event before those of the records preceding it.
+	Corruption_Timestamp
+)
+
+// AllCorruptions are all the kinds of Corruption.
+var AllCorruptions = []Corruption{
+	Corruption_Truncate, Corruption_Length, Corruption_RType, Corruption_Bytes, Corruption_Timestamp,
+}
+
+// String returns the name of the Corruption, such as "truncate".
+func (c Corruption) String() string {
+	switch c {
+	case Corruption_Truncate:
+		return "truncate"
+	case Corruption_Length:
+		return "length"
+	case Corruption_RType:
+		return "rtype"
+	case Corruption_Bytes:
+		return "bytes"
+	case Corruption_Timestamp:
+		return "timestamp"
+	default:
+		return "unknown"
+	}
+}
+
+// CorruptionFromString converts a name, such as "truncate", to a Corruption.
+// Returns an error if the name is unknown.
+func CorruptionFromString(str string) (Corruption, error) {
+	for _, c := range AllCorruptions {
+		if strings.EqualFold(str, c.String()) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown corruption '%s'", str)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// schemaInterval returns the interval of a subsampled or aggregated schema, or 0 for others.
+func schemaInterval(schema dbn.Schema) time.Duration {
+	switch schema {
+	case dbn.Schema_Ohlcv1S, dbn.Schema_Bbo1S, dbn.Schema_Cbbo1S, dbn.Schema_Imbalance:
+		return time.Second
+	case dbn.Schema_Ohlcv1M, dbn.Schema_Bbo1M, dbn.Schema_Cbbo1M:
+		return time.Minute
+	case dbn.Schema_Ohlcv1H:
+		return time.Hour
+	case dbn.Schema_Ohlcv1D, dbn.Schema_OhlcvEod:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// schemaRType returns the RType of the records of a schema whose records have several.
+func schemaRType(schema dbn.Schema) dbn.RType {
+	switch schema {
+	case dbn.Schema_Ohlcv1S:
+		return dbn.RType_Ohlcv1S
+	case dbn.Schema_Ohlcv1M:
+		return dbn.RType_Ohlcv1M
+	case dbn.Schema_Ohlcv1H:
+		return dbn.RType_Ohlcv1H
+	case dbn.Schema_Ohlcv1D:
+		return dbn.RType_Ohlcv1D
+	case dbn.Schema_OhlcvEod:
+		return dbn.RType_OhlcvEod
+	case dbn.Schema_Bbo1S:
+		return dbn.RType_Bbo1S
+	case dbn.Schema_Bbo1M:
+		return dbn.RType_Bbo1M
+	case dbn.Schema_Cbbo1S:
+		return dbn.RType_Cbbo1S
+	case dbn.Schema_Cbbo1M:
+		return dbn.RType_Cbbo1M
+	case dbn.Schema_Tcbbo:
+		return dbn.RType_Tcbbo
+	case dbn.Schema_Cmbp1:
+		return dbn.RType_Cmbp1
+	default:
+		return dbn.RType_Unknown
+	}
+}
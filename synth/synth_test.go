@@ -0,0 +1,274 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_synth
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	dbn_backtest "github.com/NimbleMarkets/dbn-go/backtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Test Launcher
+func TestDbnSynth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "dbn-go synth suite")
+}
+
+// allSchemas are the schemas a Generator supports.
+var allSchemas = []dbn.Schema{
+	dbn.Schema_Mbo, dbn.Schema_Mbp1, dbn.Schema_Mbp10, dbn.Schema_Tbbo, dbn.Schema_Trades,
+	dbn.Schema_Ohlcv1S, dbn.Schema_Ohlcv1M, dbn.Schema_Ohlcv1H, dbn.Schema_Ohlcv1D,
+	dbn.Schema_Definition, dbn.Schema_Statistics, dbn.Schema_Status, dbn.Schema_Imbalance,
+	dbn.Schema_OhlcvEod, dbn.Schema_Cmbp1, dbn.Schema_Cbbo1S, dbn.Schema_Cbbo1M,
+	dbn.Schema_Tcbbo, dbn.Schema_Bbo1S, dbn.Schema_Bbo1M,
+}
+
+// expectedRType returns the RType of the records of a schema.
+func expectedRType(schema dbn.Schema) dbn.RType {
+	switch schema {
+	case dbn.Schema_Mbo:
+		return dbn.RType_Mbo
+	case dbn.Schema_Mbp1, dbn.Schema_Tbbo:
+		return dbn.RType_Mbp1
+	case dbn.Schema_Mbp10:
+		return dbn.RType_Mbp10
+	case dbn.Schema_Trades:
+		return dbn.RType_Mbp0
+	case dbn.Schema_Definition:
+		return dbn.RType_InstrumentDef
+	case dbn.Schema_Statistics:
+		return dbn.RType_Statistics
+	case dbn.Schema_Status:
+		return dbn.RType_Status
+	case dbn.Schema_Imbalance:
+		return dbn.RType_Imbalance
+	default:
+		return schemaRType(schema)
+	}
+}
+
+// writeTestStream returns the stream of `opts` written as DBN, and its Generator.
+func writeTestStream(opts Options) ([]byte, *Generator) {
+	generator, err := NewGenerator(opts)
+	Expect(err).To(BeNil())
+	var buf bytes.Buffer
+	Expect(generator.Write(&buf)).To(Succeed())
+	return buf.Bytes(), generator
+}
+
+// scanTestStream scans a stream to its end, returning the headers of its records and the scanner's error.
+// Records which fail to decode are skipped.
+func scanTestStream(data []byte) ([]dbn.RHeader, error) {
+	scanner := dbn.NewDbnScanner(bytes.NewReader(data))
+	var headers []dbn.RHeader
+	for scanner.Next() {
+		header, err := scanner.GetLastHeader()
+		Expect(err).To(BeNil())
+		headers = append(headers, header)
+		_ = scanner.Visit(&dbn.NullVisitor{})
+	}
+	return headers, scanner.Error()
+}
+
+var _ = Describe("Generator", func() {
+	It("should generate the same stream from the same seed", func() {
+		opts := Options{Schema: dbn.Schema_Mbp10, NumInstruments: 2, Duration: 10 * time.Second, Seed: 7}
+		first, _ := writeTestStream(opts)
+		second, _ := writeTestStream(opts)
+		Expect(first).To(Equal(second))
+
+		opts.Seed = 8
+		third, _ := writeTestStream(opts)
+		Expect(third).NotTo(Equal(first))
+	})
+
+	It("should generate every schema and version", func() {
+		for _, schema := range allSchemas {
+			for version := uint8(1); version <= dbn.HeaderVersion3; version++ {
+				opts := Options{Schema: schema, Version: version, NumInstruments: 2, Duration: 5 * time.Minute, Rate: 10, SymbolMappings: true}
+				if schema == dbn.Schema_Definition && version == dbn.HeaderVersion1 {
+					_, err := NewGenerator(opts)
+					Expect(err).NotTo(BeNil())
+					continue
+				}
+				data, generator := writeTestStream(opts)
+
+				scanner := dbn.NewDbnScanner(bytes.NewReader(data))
+				metadata, err := scanner.Metadata()
+				Expect(err).To(BeNil())
+				Expect(metadata.VersionNum).To(Equal(version))
+				Expect(metadata.Schema).To(Equal(schema))
+
+				numRecords, numMappings := 0, 0
+				for scanner.Next() {
+					numRecords++
+					header, err := scanner.GetLastHeader()
+					Expect(err).To(BeNil())
+					_, err = scanner.DecodeAny()
+					Expect(err).To(BeNil(), "%s v%d", schema, version)
+					if header.RType == dbn.RType_SymbolMapping {
+						numMappings++
+						continue
+					}
+					Expect(header.RType).To(Equal(expectedRType(schema)), "%s v%d", schema, version)
+					Expect(header.PublisherID).To(Equal(uint16(dbn.Dataset_XnasItch.Publishers()[0])))
+					Expect(header.InstrumentID).To(BeElementOf(uint32(1), uint32(2)))
+				}
+				Expect(scanner.Error()).To(Equal(io.EOF))
+				Expect(numMappings).To(Equal(2))
+				Expect(numRecords).To(BeNumerically(">", numMappings), "%s v%d", schema, version)
+				Expect(numRecords).To(Equal(generator.NumRecords()))
+			}
+		}
+	})
+
+	It("should generate internally consistent MBO books", func() {
+		generator, err := NewGenerator(Options{Schema: dbn.Schema_Mbo, Symbols: []string{"AAA", "BBB"}, Duration: time.Minute})
+		Expect(err).To(BeNil())
+		books := map[uint32]*dbn_backtest.Book{}
+		sequences := map[uint32]uint32{}
+		var tsRecv uint64
+		numTrades := 0
+		for {
+			record, ok := generator.Next()
+			if !ok {
+				break
+			}
+			mbo := record.(*dbn.MboMsg)
+			book := books[mbo.Header.InstrumentID]
+			if book == nil {
+				book = dbn_backtest.NewBook()
+				books[mbo.Header.InstrumentID] = book
+			}
+			Expect(mbo.Sequence).To(BeNumerically(">", sequences[mbo.Header.InstrumentID]))
+			sequences[mbo.Header.InstrumentID] = mbo.Sequence
+			Expect(mbo.TsRecv).To(BeNumerically(">=", tsRecv))
+			tsRecv = mbo.TsRecv
+
+			switch dbn.Action(mbo.Action) {
+			case dbn.Action_Cancel, dbn.Action_Modify, dbn.Action_Fill:
+				order, ok := book.Order(mbo.OrderID)
+				Expect(ok).To(BeTrue(), "order %d", mbo.OrderID)
+				Expect(order.Size).To(BeNumerically(">=", mbo.Size))
+			case dbn.Action_Add:
+				_, ok := book.Order(mbo.OrderID)
+				Expect(ok).To(BeFalse(), "order %d", mbo.OrderID)
+			case dbn.Action_Trade:
+				numTrades++
+			}
+			book.ApplyMbo(mbo)
+			if mbo.Flags&uint8(dbn.F_LAST) != 0 {
+				bid, hasBid := book.BestBid()
+				ask, hasAsk := book.BestAsk()
+				Expect(hasBid && hasAsk).To(BeTrue())
+				Expect(bid.Price).To(BeNumerically("<", ask.Price))
+			}
+		}
+		Expect(books).To(HaveLen(2))
+		Expect(numTrades).To(BeNumerically(">", 0))
+	})
+
+	It("should stop at the count of records", func() {
+		data, generator := writeTestStream(Options{Schema: dbn.Schema_Mbo, Count: 100})
+		Expect(generator.NumRecords()).To(Equal(100))
+		headers, err := scanTestStream(data)
+		Expect(err).To(Equal(io.EOF))
+		Expect(headers).To(HaveLen(100))
+	})
+
+	It("should map symbols in the metadata", func() {
+		_, err := NewGenerator(Options{Symbols: []string{"AAA"}, NumInstruments: 2})
+		Expect(err).NotTo(BeNil())
+		_, err = NewGenerator(Options{CorruptionRate: 2})
+		Expect(err).NotTo(BeNil())
+		_, err = NewGenerator(Options{Schema: dbn.Schema_Mixed})
+		Expect(err).NotTo(BeNil())
+
+		generator, err := NewGenerator(Options{Symbols: []string{"AAA", "BBB"}, FirstInstrumentID: 42, Duration: 36 * time.Hour})
+		Expect(err).To(BeNil())
+		metadata := generator.Metadata()
+		Expect(metadata.Symbols).To(Equal([]string{"AAA", "BBB"}))
+		Expect(metadata.Mappings).To(HaveLen(2))
+		Expect(metadata.Mappings[1].RawSymbol).To(Equal("BBB"))
+		Expect(metadata.Mappings[1].Intervals).To(Equal([]dbn.MappingInterval{{StartDate: 20260102, EndDate: 20260105, Symbol: "43"}}))
+	})
+
+	Context("corruption", func() {
+		It("should corrupt records without changing the others", func() {
+			opts := Options{Schema: dbn.Schema_Trades, Duration: 10 * time.Second}
+			clean, _ := writeTestStream(opts)
+			opts.CorruptionRate = 0.1
+			opts.Corruptions = []Corruption{Corruption_Bytes}
+			corrupted, generator := writeTestStream(opts)
+			Expect(generator.NumCorrupted()).To(BeNumerically(">", 0))
+			Expect(corrupted).To(HaveLen(len(clean)))
+			Expect(corrupted).NotTo(Equal(clean))
+		})
+
+		It("should scan every kind of corruption without panicking", func() {
+			for _, schema := range []dbn.Schema{dbn.Schema_Mbo, dbn.Schema_Mbp10, dbn.Schema_Definition, dbn.Schema_Statistics} {
+				for _, corruption := range AllCorruptions {
+					numCorrupted := 0
+					for seed := range uint64(5) {
+						data, generator := writeTestStream(Options{
+							Schema: schema, Seed: seed, NumInstruments: 3, Duration: 10 * time.Second,
+							CorruptionRate: 0.2, Corruptions: []Corruption{corruption},
+						})
+						numCorrupted += generator.NumCorrupted()
+						Expect(func() { scanTestStream(data) }).NotTo(Panic(), "%s %s %d", schema, corruption, seed)
+					}
+					Expect(numCorrupted).To(BeNumerically(">", 0), "%s %s", schema, corruption)
+				}
+			}
+		})
+
+		It("should end the stream at a truncated record", func() {
+			opts := Options{Schema: dbn.Schema_Mbo, Duration: 10 * time.Second}
+			clean, _ := writeTestStream(opts)
+			opts.CorruptionRate = 0.01
+			opts.Corruptions = []Corruption{Corruption_Truncate}
+			truncated, generator := writeTestStream(opts)
+			Expect(generator.NumCorrupted()).To(Equal(1))
+			Expect(len(truncated)).To(BeNumerically("<", len(clean)))
+			Expect(clean[:len(truncated)]).To(Equal(truncated))
+			_, err := scanTestStream(truncated)
+			Expect(err).To(Equal(io.ErrUnexpectedEOF))
+		})
+
+		It("should move timestamps backwards", func() {
+			data, generator := writeTestStream(Options{
+				Schema: dbn.Schema_Trades, Duration: 10 * time.Second,
+				CorruptionRate: 0.1, Corruptions: []Corruption{Corruption_Timestamp},
+			})
+			Expect(generator.NumCorrupted()).To(BeNumerically(">", 0))
+			headers, err := scanTestStream(data)
+			Expect(err).To(Equal(io.EOF))
+			numBackwards := 0
+			for i := 1; i < len(headers); i++ {
+				if headers[i].TsEvent < headers[i-1].TsEvent {
+					numBackwards++
+				}
+			}
+			Expect(numBackwards).To(BeNumerically(">", 0))
+		})
+
+		It("should convert corruptions from strings", func() {
+			for _, corruption := range AllCorruptions {
+				c, err := CorruptionFromString(corruption.String())
+				Expect(err).To(BeNil())
+				Expect(c).To(Equal(corruption))
+			}
+			c, err := CorruptionFromString("RTYPE")
+			Expect(err).To(BeNil())
+			Expect(c).To(Equal(Corruption_RType))
+			_, err = CorruptionFromString("bogus")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,271 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// TradingState is the trading status of an instrument, as of its latest StatusMsg.
+type TradingState struct {
+	InstrumentID          uint32
+	Ts                    uint64 // The latest StatusMsg's `ts_recv`, or `ts_event` if unset
+	Action                StatusAction
+	Reason                StatusReason
+	TradingEvent          TradingEvent
+	IsTrading             TriState
+	IsQuoting             TriState
+	IsShortSellRestricted TriState
+}
+
+// IsHalted returns true if trading is halted, paused, or suspended.
+func (s TradingState) IsHalted() bool {
+	switch s.Action {
+	case StatusAction_Halt, StatusAction_Pause, StatusAction_Suspend:
+		return true
+	}
+	return false
+}
+
+// IsLuldPause returns true if trading is paused by a limit up-limit down band.
+func (s TradingState) IsLuldPause() bool {
+	return s.IsHalted() && StatusAction(s.Reason) == StatusReason_LuldPause
+}
+
+// CanTrade returns true if the instrument is trading: per its `is_trading` flag if available,
+// otherwise if its Action is StatusAction_Trading.
+func (s TradingState) CanTrade() bool {
+	switch TradingEvent(s.IsTrading) {
+	case TriState_Yes:
+		return true
+	case TriState_No:
+		return false
+	}
+	return s.Action == StatusAction_Trading
+}
+
+// HaltInterval is a span of time an instrument's trading was halted, paused, or suspended.
+type HaltInterval struct {
+	InstrumentID uint32
+	Start        uint64       // Timestamp of the halt
+	End          uint64       // Timestamp of the resumption, or UNDEF_TIMESTAMP while halted
+	Action       StatusAction // Action of the halt's first StatusMsg
+	Reason       StatusReason // Reason of the halt's first StatusMsg
+}
+
+// IsOpen returns true if the instrument has not resumed trading.
+func (h HaltInterval) IsOpen() bool {
+	return h.End == UNDEF_TIMESTAMP
+}
+
+// Contains returns true if the timestamp is within the interval, inclusive of its start.
+func (h HaltInterval) Contains(ts uint64) bool {
+	return ts >= h.Start && (h.IsOpen() || ts < h.End)
+}
+
+// Duration returns the length of the interval, or 0 if it is open.
+func (h HaltInterval) Duration() time.Duration {
+	if h.IsOpen() {
+		return 0
+	}
+	return time.Duration(h.End - h.Start)
+}
+
+// TradingStatusTracker maintains the TradingState of instruments from a stream of StatusMsg records,
+// and the HaltIntervals during which they were halted, so it can answer whether an instrument was
+// halted at a time.  It may be fed by a Visitor, in replay or live sessions.
+//
+// StatusMsg records with StatusAction_None or StatusAction_SsrChange only update the flags that
+// are available; others replace the instrument's state.  A halt lasts until trading resumes, with
+// StatusAction_Trading or `is_trading`, so quoting before a reopening does not end it.
+// Records are timestamped by `ts_recv`, or `ts_event` if unset, and are expected in that order.
+type TradingStatusTracker struct {
+	states      map[uint32]*TradingState
+	halts       map[uint32][]HaltInterval
+	haltHandler func(interval HaltInterval)
+}
+
+// NewTradingStatusTracker returns an empty TradingStatusTracker.
+func NewTradingStatusTracker() *TradingStatusTracker {
+	return &TradingStatusTracker{
+		states: make(map[uint32]*TradingState),
+		halts:  make(map[uint32][]HaltInterval),
+	}
+}
+
+// SetHaltHandler sets a function called when an instrument is halted, with an open HaltInterval,
+// and when it resumes, with the closed HaltInterval.  It is nil by default.
+func (t *TradingStatusTracker) SetHaltHandler(handler func(interval HaltInterval)) {
+	t.haltHandler = handler
+}
+
+// IsEmpty returns true if no instruments have a status.
+func (t *TradingStatusTracker) IsEmpty() bool {
+	return len(t.states) == 0
+}
+
+// Len returns the number of instruments with a status.
+func (t *TradingStatusTracker) Len() int {
+	return len(t.states)
+}
+
+// Update applies a StatusMsg to its instrument's TradingState, opening or closing a HaltInterval
+// if it halted or resumed trading.  Returns the updated TradingState.
+func (t *TradingStatusTracker) Update(status *StatusMsg) TradingState {
+	ts := status.TsRecv
+	if ts == 0 || ts == UNDEF_TIMESTAMP {
+		ts = status.Header.TsEvent
+	}
+	id := status.Header.InstrumentID
+	state := t.states[id]
+	if state == nil {
+		state = &TradingState{
+			InstrumentID:          id,
+			IsTrading:             TriState(TriState_NotAvailable),
+			IsQuoting:             TriState(TriState_NotAvailable),
+			IsShortSellRestricted: TriState(TriState_NotAvailable),
+		}
+		t.states[id] = state
+	}
+	wasHalted := t.IsHalted(id)
+
+	state.Ts = ts
+	action := StatusAction(status.Action)
+	if action == StatusAction_None || action == StatusAction_SsrChange {
+		updateTriState(&state.IsTrading, status.IsTrading)
+		updateTriState(&state.IsQuoting, status.IsQuoting)
+		updateTriState(&state.IsShortSellRestricted, status.IsShortSellRestricted)
+	} else {
+		state.Action = action
+		state.Reason = StatusReason(status.Reason)
+		state.TradingEvent = TradingEvent(status.TradingEvent)
+		state.IsTrading = TriState(status.IsTrading)
+		state.IsQuoting = TriState(status.IsQuoting)
+		state.IsShortSellRestricted = TriState(status.IsShortSellRestricted)
+	}
+
+	resumed := state.Action == StatusAction_Trading || TradingEvent(state.IsTrading) == TriState_Yes
+	if !wasHalted && state.IsHalted() {
+		interval := HaltInterval{InstrumentID: id, Start: ts, End: UNDEF_TIMESTAMP, Action: state.Action, Reason: state.Reason}
+		t.halts[id] = append(t.halts[id], interval)
+		if t.haltHandler != nil {
+			t.haltHandler(interval)
+		}
+	} else if wasHalted && resumed {
+		halts := t.halts[id]
+		halts[len(halts)-1].End = ts
+		if t.haltHandler != nil {
+			t.haltHandler(halts[len(halts)-1])
+		}
+	}
+	return *state
+}
+
+// updateTriState sets the TriState to the value, unless it is not available.
+func updateTriState(tri *TriState, value uint8) {
+	if TradingEvent(value) != TriState_NotAvailable && value != 0 {
+		*tri = TriState(value)
+	}
+}
+
+// OnStatusMsg updates the tracker with the status, so a TradingStatusTracker may be fed by a Visitor.
+func (t *TradingStatusTracker) OnStatusMsg(status *StatusMsg) error {
+	t.Update(status)
+	return nil
+}
+
+// ReadDbn updates the tracker with all the status records of a DBN stream, skipping other records.
+func (t *TradingStatusTracker) ReadDbn(scanner *DbnScanner) error {
+	if _, err := scanner.Metadata(); err != nil {
+		return err
+	}
+	for scanner.Next() {
+		if RType(scanner.GetLastRecord()[1]) != RType_Status {
+			continue
+		}
+		status, err := DbnScannerDecode[StatusMsg](scanner)
+		if err != nil {
+			return err
+		}
+		t.Update(status)
+	}
+	if err := scanner.Error(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// ReadDbnFile updates the tracker with all the status records of a DBN file; see ReadDbn.
+// Compression is detected from the file's contents; if useZstd is true, the file is always zstd-decompressed.
+func (t *TradingStatusTracker) ReadDbnFile(filename string, useZstd bool) error {
+	reader, closer, err := MakeCompressedReader(filename, useZstd)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	if err := t.ReadDbn(NewDbnScanner(reader)); err != nil {
+		return fmt.Errorf("failed to read status from '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// State returns the current TradingState of the instrument.  Returns false if it has no status.
+func (t *TradingStatusTracker) State(instrumentID uint32) (TradingState, bool) {
+	state := t.states[instrumentID]
+	if state == nil {
+		return TradingState{}, false
+	}
+	return *state, true
+}
+
+// IsHalted returns true if the instrument is currently halted, paused, or suspended,
+// and has not resumed trading.
+func (t *TradingStatusTracker) IsHalted(instrumentID uint32) bool {
+	halts := t.halts[instrumentID]
+	return len(halts) != 0 && halts[len(halts)-1].IsOpen()
+}
+
+// HaltAt returns the instrument's HaltInterval containing the timestamp.
+// Returns false if it was not halted at that time.
+func (t *TradingStatusTracker) HaltAt(instrumentID uint32, ts uint64) (HaltInterval, bool) {
+	halts := t.halts[instrumentID]
+	// The last interval starting at or before ts
+	i := sort.Search(len(halts), func(i int) bool { return halts[i].Start > ts }) - 1
+	if i < 0 || !halts[i].Contains(ts) {
+		return HaltInterval{}, false
+	}
+	return halts[i], true
+}
+
+// IsHaltedAt returns true if the instrument was halted, paused, or suspended at the timestamp.
+func (t *TradingStatusTracker) IsHaltedAt(instrumentID uint32, ts uint64) bool {
+	_, ok := t.HaltAt(instrumentID, ts)
+	return ok
+}
+
+// HaltIntervals returns the instrument's HaltIntervals, in order.
+func (t *TradingStatusTracker) HaltIntervals(instrumentID uint32) []HaltInterval {
+	return slices.Clone(t.halts[instrumentID])
+}
+
+// AllHaltIntervals returns the HaltIntervals of all instruments, sorted by start and then instrument ID.
+func (t *TradingStatusTracker) AllHaltIntervals() []HaltInterval {
+	var all []HaltInterval
+	for _, halts := range t.halts {
+		all = append(all, halts...)
+	}
+	slices.SortFunc(all, func(a, b HaltInterval) int {
+		if c := cmp.Compare(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.InstrumentID, b.InstrumentID)
+	})
+	return all
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn_test
+
+import (
+	"time"
+
+	"github.com/NimbleMarkets/dbn-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTestStatus returns a status of an instrument received at `tsRecv`.
+func newTestStatus(instrumentID uint32, tsRecv uint64, action dbn.StatusAction, reason dbn.StatusAction, isTrading dbn.TradingEvent) *dbn.StatusMsg {
+	return &dbn.StatusMsg{
+		Header: dbn.RHeader{
+			Length:       uint8(dbn.StatusMsg_Size / 4),
+			RType:        dbn.RType_Status,
+			PublisherID:  1,
+			InstrumentID: instrumentID,
+			TsEvent:      tsRecv,
+		},
+		TsRecv:                tsRecv,
+		Action:                uint16(action),
+		Reason:                uint16(reason),
+		IsTrading:             uint8(isTrading),
+		IsQuoting:             uint8(dbn.TriState_Yes),
+		IsShortSellRestricted: uint8(dbn.TriState_NotAvailable),
+	}
+}
+
+// haltAt returns the instrument's HaltInterval at the timestamp, which must exist.
+func haltAt(tracker *dbn.TradingStatusTracker, instrumentID uint32, ts uint64) dbn.HaltInterval {
+	interval, ok := tracker.HaltAt(instrumentID, ts)
+	Expect(ok).To(BeTrue())
+	return interval
+}
+
+var _ = Describe("TradingStatusTracker", func() {
+	ts := func(minute int) uint64 {
+		return uint64(time.Date(2024, 1, 2, 14, minute, 0, 0, time.UTC).UnixNano())
+	}
+
+	It("should name status actions and reasons", func() {
+		Expect(dbn.StatusAction_Halt.String()).To(Equal("halt"))
+		Expect(dbn.StatusAction_NotAvailableForTrading.String()).To(Equal("not_available_for_trading"))
+		Expect(dbn.StatusReason(dbn.StatusReason_LuldPause).String()).To(Equal("luld_pause"))
+		Expect(dbn.StatusReason(dbn.StatusReason_MarketWideHaltLevel1).String()).To(Equal("market_wide_halt_level1"))
+		Expect(dbn.StatusReason(99).String()).To(Equal(""))
+	})
+
+	It("should track halt and resume intervals", func() {
+		tracker := dbn.NewTradingStatusTracker()
+		var events []dbn.HaltInterval
+		tracker.SetHaltHandler(func(interval dbn.HaltInterval) { events = append(events, interval) })
+
+		tracker.Update(newTestStatus(1, ts(0), dbn.StatusAction_Trading, dbn.StatusReason_Scheduled, dbn.TriState_Yes))
+		tracker.Update(newTestStatus(1, ts(10), dbn.StatusAction_Pause, dbn.StatusReason_LuldPause, dbn.TriState_No))
+		Expect(tracker.IsHalted(1)).To(BeTrue())
+		state, ok := tracker.State(1)
+		Expect(ok).To(BeTrue())
+		Expect(state.IsLuldPause()).To(BeTrue())
+		Expect(state.CanTrade()).To(BeFalse())
+
+		// Short-sale restriction changes do not resume trading
+		ssr := newTestStatus(1, ts(12), dbn.StatusAction_SsrChange, dbn.StatusReason_None, dbn.TriState_NotAvailable)
+		ssr.IsShortSellRestricted = uint8(dbn.TriState_Yes)
+		state = tracker.Update(ssr)
+		Expect(state.IsHalted()).To(BeTrue())
+		Expect(state.IsShortSellRestricted).To(Equal(dbn.TriState(dbn.TriState_Yes)))
+		Expect(state.IsTrading).To(Equal(dbn.TriState(dbn.TriState_No)))
+
+		tracker.Update(newTestStatus(1, ts(15), dbn.StatusAction_Trading, dbn.StatusReason_NewsReleased, dbn.TriState_Yes))
+		tracker.Update(newTestStatus(1, ts(30), dbn.StatusAction_Halt, dbn.StatusReason_NewsPending, dbn.TriState_No))
+		Expect(tracker.IsHalted(1)).To(BeTrue())
+
+		Expect(tracker.IsHaltedAt(1, ts(5))).To(BeFalse())
+		Expect(tracker.IsHaltedAt(1, ts(10))).To(BeTrue())
+		Expect(tracker.IsHaltedAt(1, ts(14))).To(BeTrue())
+		Expect(tracker.IsHaltedAt(1, ts(15))).To(BeFalse())
+		Expect(tracker.IsHaltedAt(1, ts(45))).To(BeTrue())
+		Expect(tracker.IsHaltedAt(2, ts(45))).To(BeFalse())
+		Expect(haltAt(tracker, 1, ts(11)).Duration()).To(Equal(5 * time.Minute))
+		Expect(haltAt(tracker, 1, ts(31)).IsOpen()).To(BeTrue())
+
+		halts := tracker.HaltIntervals(1)
+		Expect(halts).To(HaveLen(2))
+		Expect(halts[0].Reason).To(Equal(dbn.StatusReason(dbn.StatusReason_LuldPause)))
+		Expect(halts[1].Action).To(Equal(dbn.StatusAction_Halt))
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].IsOpen()).To(BeTrue())
+		Expect(events[1].End).To(Equal(ts(15)))
+	})
+
+	It("should keep a halt open through quoting until trading resumes", func() {
+		tracker := dbn.NewTradingStatusTracker()
+		var events []dbn.HaltInterval
+		tracker.SetHaltHandler(func(interval dbn.HaltInterval) { events = append(events, interval) })
+
+		tracker.Update(newTestStatus(1, ts(0), dbn.StatusAction_Halt, dbn.StatusReason_NewsPending, dbn.TriState_No))
+		state := tracker.Update(newTestStatus(1, ts(25), dbn.StatusAction_Quoting, dbn.StatusReason_NewsReleased, dbn.TriState_No))
+		Expect(state.IsHalted()).To(BeFalse())
+		Expect(tracker.IsHalted(1)).To(BeTrue())
+		Expect(tracker.IsHaltedAt(1, ts(28))).To(BeTrue())
+		Expect(events).To(HaveLen(1))
+
+		tracker.Update(newTestStatus(1, ts(30), dbn.StatusAction_Trading, dbn.StatusReason_NewsReleased, dbn.TriState_Yes))
+		Expect(tracker.IsHalted(1)).To(BeFalse())
+		Expect(tracker.IsHaltedAt(1, ts(30))).To(BeFalse())
+		halts := tracker.HaltIntervals(1)
+		Expect(halts).To(HaveLen(1))
+		Expect(halts[0].Duration()).To(Equal(30 * time.Minute))
+		Expect(events).To(HaveLen(2))
+
+		// `is_trading` alone also resumes trading
+		tracker.Update(newTestStatus(2, ts(0), dbn.StatusAction_Pause, dbn.StatusReason_LuldPause, dbn.TriState_No))
+		tracker.Update(newTestStatus(2, ts(5), dbn.StatusAction_None, dbn.StatusReason_None, dbn.TriState_Yes))
+		Expect(tracker.IsHalted(2)).To(BeFalse())
+		Expect(tracker.HaltIntervals(2)[0].End).To(Equal(ts(5)))
+	})
+
+	It("should sort halts of all instruments", func() {
+		tracker := dbn.NewTradingStatusTracker()
+		Expect(tracker.IsEmpty()).To(BeTrue())
+		Expect(tracker.OnStatusMsg(newTestStatus(2, ts(5), dbn.StatusAction_Halt, dbn.StatusReason_Regulatory, dbn.TriState_No))).To(Succeed())
+		Expect(tracker.OnStatusMsg(newTestStatus(1, ts(5), dbn.StatusAction_Suspend, dbn.StatusReason_Administrative, dbn.TriState_No))).To(Succeed())
+		Expect(tracker.OnStatusMsg(newTestStatus(3, ts(1), dbn.StatusAction_Halt, dbn.StatusReason_Regulatory, dbn.TriState_No))).To(Succeed())
+		Expect(tracker.Len()).To(Equal(3))
+		all := tracker.AllHaltIntervals()
+		Expect([]uint32{all[0].InstrumentID, all[1].InstrumentID, all[2].InstrumentID}).To(Equal([]uint32{3, 1, 2}))
+	})
+
+	It("should read status files", func() {
+		for _, filename := range []string{"test_data.status.v2.dbn.zst", "test_data.status.v3.dbn.zst"} {
+			tracker := dbn.NewTradingStatusTracker()
+			Expect(tracker.ReadDbnFile("./tests/data/"+filename, false)).To(Succeed())
+			state, ok := tracker.State(5482)
+			Expect(ok).To(BeTrue(), filename)
+			Expect(state.Action).To(Equal(dbn.StatusAction_NewPriceIndication), filename)
+			Expect(state.CanTrade()).To(BeTrue(), filename)
+			Expect(tracker.AllHaltIntervals()).To(BeEmpty(), filename)
+		}
+	})
+})
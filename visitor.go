@@ -2,6 +2,8 @@
 
 package dbn
 
+import "fmt"
+
 type Visitor interface {
 	OnMbp0(record *Mbp0Msg) error
 	OnMbp1(record *Mbp1Msg) error
@@ -23,3 +25,79 @@ type Visitor interface {
 
 	OnStreamEnd() error
 }
+
+///////////////////////////////////////////////////////////////////////////////
+
+// UnknownRTypeHandler may be implemented by a Visitor to handle records whose RType has no
+// record type, such as those of future DBN versions.  `raw` is the record's DBN bytes or JSON text,
+// valid only during the call.  Returning nil skips the record rather than stopping the stream.
+type UnknownRTypeHandler interface {
+	OnUnknownRType(header *RHeader, raw []byte) error
+}
+
+// DecodeErrorHandler may be implemented by a Visitor to handle records which fail to decode.
+// `header` is nil if the header itself failed to decode.  `raw` is the record's DBN bytes or JSON text,
+// valid only during the call.  Returning nil skips the record rather than stopping the stream.
+type DecodeErrorHandler interface {
+	OnDecodeError(header *RHeader, raw []byte, err error) error
+}
+
+// handleVisitError passes a Visit's decoding error to the Visitor's handlers, if it has them.
+// Otherwise returns the error.
+func handleVisitError(visitor Visitor, header *RHeader, raw []byte, err error) error {
+	if err == ErrUnknownRType {
+		if handler, ok := visitor.(UnknownRTypeHandler); ok {
+			return handler.OnUnknownRType(header, raw)
+		}
+		return err
+	}
+	if handler, ok := visitor.(DecodeErrorHandler); ok {
+		return handler.OnDecodeError(header, raw, err)
+	}
+	return err
+}
+
+// VisitRecord passes a decoded Record, such as from DecodeAny, to the Visitor method for its type.
+// V1/V2 records are first converted to their current types with UpgradeRecord.
+// Returns an error if the Visitor has no method for the type.
+func VisitRecord(visitor Visitor, record Record) error {
+	switch r := UpgradeRecord(record).(type) {
+	case *Mbp0Msg:
+		return visitor.OnMbp0(r)
+	case *Mbp1Msg:
+		return visitor.OnMbp1(r)
+	case *Mbp10Msg:
+		return visitor.OnMbp10(r)
+	case *MboMsg:
+		return visitor.OnMbo(r)
+	case *OhlcvMsg:
+		return visitor.OnOhlcv(r)
+	case *Cmbp1Msg:
+		return visitor.OnCmbp1(r)
+	case *BboMsg:
+		return visitor.OnBbo(r)
+	case *ImbalanceMsg:
+		return visitor.OnImbalance(r)
+	case *StatMsg:
+		return visitor.OnStatMsg(r)
+	case *StatusMsg:
+		return visitor.OnStatusMsg(r)
+	case *InstrumentDefMsg:
+		return visitor.OnInstrumentDefMsg(r)
+	case *ErrorMsg:
+		return visitor.OnErrorMsg(r)
+	case *SystemMsg:
+		return visitor.OnSystemMsg(r)
+	case *SymbolMappingMsg:
+		return visitor.OnSymbolMappingMsg(r)
+	default:
+		return fmt.Errorf("no Visitor method for record type %T", record)
+	}
+}
+
+// rawRecord is a Record which decodes from fixed-size DBN bytes.
+type rawRecord interface {
+	Record
+	RSize() uint16
+	Fill_Raw([]byte) error
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2026 Neomantra Corp
+
+package dbn
+
+// VisitorFuncs is a Visitor of optional callbacks, for handling a few record types without
+// implementing the whole Visitor interface.  Each callback is named for its Visitor method, less "On".
+// A record without a callback for its type is passed to Default, if set, and is otherwise ignored.
+//
+// VisitorFuncs also implements UnknownRTypeHandler and DecodeErrorHandler.  If UnknownRType or
+// DecodeError is not set, such records return their error and stop the stream.
+//
+// Example:
+//
+//	visitor := &dbn.VisitorFuncs{
+//		Mbp0: func(r *dbn.Mbp0Msg) error {
+//			fmt.Println(r.Price)
+//			return nil
+//		},
+//		UnknownRType: func(header *dbn.RHeader, raw []byte) error {
+//			return nil // skip records of future versions
+//		},
+//	}
+//	err := dbnScanner.Visit(visitor)
+type VisitorFuncs struct {
+	Mbp0  func(record *Mbp0Msg) error
+	Mbp1  func(record *Mbp1Msg) error
+	Mbp10 func(record *Mbp10Msg) error
+	Mbo   func(record *MboMsg) error
+
+	Ohlcv func(record *OhlcvMsg) error
+	Cmbp1 func(record *Cmbp1Msg) error
+	Bbo   func(record *BboMsg) error
+
+	Imbalance        func(record *ImbalanceMsg) error
+	StatMsg          func(record *StatMsg) error
+	StatusMsg        func(record *StatusMsg) error
+	InstrumentDefMsg func(record *InstrumentDefMsg) error
+
+	ErrorMsg         func(record *ErrorMsg) error
+	SystemMsg        func(record *SystemMsg) error
+	SymbolMappingMsg func(record *SymbolMappingMsg) error
+
+	StreamEnd func() error
+
+	// Default is called for records without a callback for their type.
+	Default func(record Record) error
+	// UnknownRType is called for records whose RType has no record type; see UnknownRTypeHandler.
+	UnknownRType func(header *RHeader, raw []byte) error
+	// DecodeError is called for records which fail to decode; see DecodeErrorHandler.
+	DecodeError func(header *RHeader, raw []byte, err error) error
+}
+
+// visitFunc calls `fn` with the record if set, otherwise `v.Default`, if set.
+func visitFunc[R Record](v *VisitorFuncs, fn func(record R) error, record R) error {
+	if fn != nil {
+		return fn(record)
+	}
+	if v.Default != nil {
+		return v.Default(record)
+	}
+	return nil
+}
+
+func (v *VisitorFuncs) OnMbp0(record *Mbp0Msg) error {
+	return visitFunc(v, v.Mbp0, record)
+}
+
+func (v *VisitorFuncs) OnMbp1(record *Mbp1Msg) error {
+	return visitFunc(v, v.Mbp1, record)
+}
+
+func (v *VisitorFuncs) OnMbp10(record *Mbp10Msg) error {
+	return visitFunc(v, v.Mbp10, record)
+}
+
+func (v *VisitorFuncs) OnMbo(record *MboMsg) error {
+	return visitFunc(v, v.Mbo, record)
+}
+
+func (v *VisitorFuncs) OnOhlcv(record *OhlcvMsg) error {
+	return visitFunc(v, v.Ohlcv, record)
+}
+
+func (v *VisitorFuncs) OnCmbp1(record *Cmbp1Msg) error {
+	return visitFunc(v, v.Cmbp1, record)
+}
+
+func (v *VisitorFuncs) OnBbo(record *BboMsg) error {
+	return visitFunc(v, v.Bbo, record)
+}
+
+func (v *VisitorFuncs) OnImbalance(record *ImbalanceMsg) error {
+	return visitFunc(v, v.Imbalance, record)
+}
+
+func (v *VisitorFuncs) OnStatMsg(record *StatMsg) error {
+	return visitFunc(v, v.StatMsg, record)
+}
+
+func (v *VisitorFuncs) OnStatusMsg(record *StatusMsg) error {
+	return visitFunc(v, v.StatusMsg, record)
+}
+
+func (v *VisitorFuncs) OnInstrumentDefMsg(record *InstrumentDefMsg) error {
+	return visitFunc(v, v.InstrumentDefMsg, record)
+}
+
+func (v *VisitorFuncs) OnErrorMsg(record *ErrorMsg) error {
+	return visitFunc(v, v.ErrorMsg, record)
+}
+
+func (v *VisitorFuncs) OnSystemMsg(record *SystemMsg) error {
+	return visitFunc(v, v.SystemMsg, record)
+}
+
+func (v *VisitorFuncs) OnSymbolMappingMsg(record *SymbolMappingMsg) error {
+	return visitFunc(v, v.SymbolMappingMsg, record)
+}
+
+func (v *VisitorFuncs) OnStreamEnd() error {
+	if v.StreamEnd != nil {
+		return v.StreamEnd()
+	}
+	return nil
+}
+
+func (v *VisitorFuncs) OnUnknownRType(header *RHeader, raw []byte) error {
+	if v.UnknownRType != nil {
+		return v.UnknownRType(header, raw)
+	}
+	return ErrUnknownRType
+}
+
+func (v *VisitorFuncs) OnDecodeError(header *RHeader, raw []byte, err error) error {
+	if v.DecodeError != nil {
+		return v.DecodeError(header, raw, err)
+	}
+	return err
+}
@@ -3,15 +3,309 @@
 package dbn_test
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
 	"github.com/NimbleMarkets/dbn-go"
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
 )
 
+// unvisitableRecord is a Record without a Visitor method.
+type unvisitableRecord struct{}
+
+func (unvisitableRecord) GetHeader() dbn.RHeader { return dbn.RHeader{} }
+
+// withUnknownRecord returns the DBN stream of a file, with a copy of its first record
+// given an unknown RType and inserted after it.
+func withUnknownRecord(filename string) []byte {
+	reader, closer, err := dbn.MakeCompressedReader(filename, false)
+	Expect(err).To(BeNil())
+	defer closer.Close()
+	raw, err := io.ReadAll(reader)
+	Expect(err).To(BeNil())
+
+	scanner := dbn.NewDbnScanner(bytes.NewReader(raw))
+	Expect(scanner.Next()).To(BeTrue())
+	size := scanner.GetLastSize()
+	unknown := bytes.Clone(scanner.GetLastRecord()[:size])
+	unknown[1] = 0x99
+	// Records follow the metadata, which ends where the rest of the stream begins
+	var rest bytes.Buffer
+	for scanner.Next() {
+		rest.Write(scanner.GetLastRecord()[:scanner.GetLastSize()])
+	}
+	metadataLen := len(raw) - rest.Len() - size
+	var out bytes.Buffer
+	out.Write(raw[:metadataLen+size])
+	out.Write(unknown)
+	out.Write(rest.Bytes())
+	return out.Bytes()
+}
+
 var _ = Describe("Visitor", func() {
 	Context("interfaces", func() {
 		It("NullVisitor should implement dbn.Visitor", func() {
 			v := dbn.NullVisitor{}
 			var _ dbn.Visitor = &v
 		})
+		It("VisitorFuncs should implement dbn.Visitor and its handlers", func() {
+			v := dbn.VisitorFuncs{}
+			var _ dbn.Visitor = &v
+			var _ dbn.UnknownRTypeHandler = &v
+			var _ dbn.DecodeErrorHandler = &v
+		})
+	})
+
+	Context("DecodeAny", func() {
+		It("should decode the concrete type of each RType", func() {
+			for filename, want := range map[string]string{
+				"test_data.trades.v3.dbn.zst":     "*dbn.Mbp0Msg",
+				"test_data.mbo.v3.dbn.zst":        "*dbn.MboMsg",
+				"test_data.mbp-10.v3.dbn.zst":     "*dbn.Mbp10Msg",
+				"test_data.ohlcv-1s.v3.dbn.zst":   "*dbn.OhlcvMsg",
+				"test_data.bbo-1s.v3.dbn.zst":     "*dbn.BboMsg",
+				"test_data.cbbo-1s.v3.dbn.zst":    "*dbn.Cmbp1Msg",
+				"test_data.statistics.v1.dbn.zst": "*dbn.StatMsgV2",
+				"test_data.statistics.v3.dbn.zst": "*dbn.StatMsgV3",
+				"test_data.definition.v2.dbn.zst": "*dbn.InstrumentDefMsgV2",
+				"test_data.definition.v3.dbn.zst": "*dbn.InstrumentDefMsgV3",
+				"test_data.status.v3.dbn.zst":     "*dbn.StatusMsg",
+			} {
+				reader, closer, err := dbn.MakeCompressedReader("./tests/data/"+filename, false)
+				Expect(err).To(BeNil())
+				scanner := dbn.NewDbnScanner(reader)
+				Expect(scanner.Next()).To(BeTrue(), filename)
+				record, err := scanner.DecodeAny()
+				Expect(err).To(BeNil(), filename)
+				Expect(fmt.Sprintf("%T", record)).To(Equal(want), filename)
+				header, _ := scanner.GetLastHeader()
+				Expect(record.GetHeader()).To(Equal(header), filename)
+				closer.Close()
+			}
+		})
+
+		It("should decode the layout of each DBN version, and upgrade it as Visit does", func() {
+			filenames, err := filepath.Glob("./tests/data/*.v[12].dbn*")
+			Expect(err).To(BeNil())
+			Expect(filenames).NotTo(BeEmpty())
+			for _, filename := range filenames {
+				reader, closer, err := dbn.MakeCompressedReader(filename, false)
+				Expect(err).To(BeNil())
+				scanner := dbn.NewDbnScanner(reader)
+				metadata, err := scanner.Metadata()
+				Expect(err).To(BeNil())
+				numRecords := 0
+				for scanner.Next() {
+					numRecords++
+					record, err := scanner.DecodeAny()
+					header, _ := scanner.GetLastHeader()
+					if header.RType == dbn.RType_InstrumentDef && metadata.VersionNum == dbn.HeaderVersion1 {
+						Expect(err).NotTo(BeNil(), filename)
+						continue
+					}
+					Expect(err).To(BeNil(), filename)
+					Expect(record.GetHeader()).To(Equal(header), filename)
+
+					var visited dbn.Record
+					Expect(scanner.Visit(&dbn.VisitorFuncs{Default: func(r dbn.Record) error {
+						visited = r
+						return nil
+					}})).To(Succeed())
+					Expect(dbn.UpgradeRecord(record)).To(Equal(visited), filename)
+
+					switch header.RType {
+					case dbn.RType_Statistics:
+						Expect(record).To(BeAssignableToTypeOf(&dbn.StatMsgV2{}), filename)
+						stat, err := scanner.DecodeStatMsg()
+						Expect(err).To(BeNil())
+						Expect(visited).To(Equal(stat), filename)
+					case dbn.RType_InstrumentDef:
+						Expect(record).To(BeAssignableToTypeOf(&dbn.InstrumentDefMsgV2{}), filename)
+						def, err := scanner.DecodeInstrumentDefMsg()
+						Expect(err).To(BeNil())
+						Expect(visited).To(Equal(def), filename)
+					default:
+						Expect(visited).To(Equal(record), filename)
+					}
+				}
+				Expect(scanner.Error()).To(Equal(io.EOF), filename)
+				Expect(numRecords).To(BeNumerically(">", 0), filename)
+				closer.Close()
+			}
+		})
+
+		It("should decode V1 error, system, and symbol mapping records", func() {
+			var buf bytes.Buffer
+			writer, err := dbn.NewDbnWriter(&buf, &dbn.Metadata{VersionNum: dbn.HeaderVersion1, SymbolCstrLen: dbn.MetadataV1_SymbolCstrLen})
+			Expect(err).To(BeNil())
+			header := dbn.RHeader{PublisherID: 1, InstrumentID: 7, TsEvent: 1000}
+			errorMsg := dbn.ErrorMsg{Header: header, Code: dbn.ErrorCode(1)}
+			errorMsg.Header.RType = dbn.RType_Error
+			copy(errorMsg.Error[:], "auth failed")
+			systemMsg := dbn.SystemMsg{Header: header}
+			systemMsg.Header.RType = dbn.RType_System
+			copy(systemMsg.Message[:], "Heartbeat")
+			mapping := dbn.SymbolMappingMsg{Header: header, StypeIn: dbn.SType_RawSymbol, StypeInSymbol: "AAPL", StypeOut: dbn.SType_RawSymbol, StypeOutSymbol: "7"}
+			mapping.Header.RType = dbn.RType_SymbolMapping
+			Expect(writer.Write(&errorMsg)).To(Succeed())
+			Expect(writer.Write(&systemMsg)).To(Succeed())
+			Expect(writer.Write(&mapping)).To(Succeed())
+
+			scanner := dbn.NewDbnScanner(&buf)
+			var records []dbn.Record
+			for scanner.Next() {
+				record, err := scanner.DecodeAny()
+				Expect(err).To(BeNil())
+				records = append(records, record)
+			}
+			Expect(scanner.Error()).To(Equal(io.EOF))
+			Expect(records).To(HaveLen(3))
+
+			errorV1, ok := records[0].(*dbn.ErrorMsgV1)
+			Expect(ok).To(BeTrue())
+			Expect(dbn.TrimNullBytes(errorV1.Error[:])).To(Equal("auth failed"))
+			systemV1, ok := records[1].(*dbn.SystemMsgV1)
+			Expect(ok).To(BeTrue())
+			Expect(dbn.TrimNullBytes(systemV1.Message[:])).To(Equal("Heartbeat"))
+			mappingV1, ok := records[2].(*dbn.SymbolMappingMsgV1)
+			Expect(ok).To(BeTrue())
+			Expect(mappingV1.StypeInSymbol).To(Equal("AAPL"))
+
+			// Upgrading loses the V2 codes, which V1 does not have
+			errorMsg.Header.Length = dbn.ErrorMsgV1_Size / 4
+			errorMsg.Code = 0
+			Expect(dbn.UpgradeRecord(errorV1)).To(Equal(&errorMsg))
+			systemMsg.Header.Length = dbn.SystemMsgV1_Size / 4
+			Expect(dbn.UpgradeRecord(systemV1)).To(Equal(&systemMsg))
+			Expect(dbn.UpgradeRecord(mappingV1).(*dbn.SymbolMappingMsg).StypeOutSymbol).To(Equal("7"))
+		})
+
+		It("should decode JSON records", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.ohlcv-1s.json", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+			scanner := dbn.NewJsonScanner(reader)
+			Expect(scanner.Next()).To(BeTrue())
+			record, err := scanner.DecodeAny()
+			Expect(err).To(BeNil())
+			ohlcv, ok := record.(*dbn.OhlcvMsg)
+			Expect(ok).To(BeTrue())
+			Expect(ohlcv.GetHeader().InstrumentID).To(Equal(uint32(5482)))
+		})
+
+		It("should return ErrUnknownRType for unknown records", func() {
+			scanner := dbn.NewDbnScanner(bytes.NewReader(withUnknownRecord("./tests/data/test_data.trades.v3.dbn.zst")))
+			Expect(scanner.Next()).To(BeTrue())
+			Expect(scanner.Next()).To(BeTrue())
+			_, err := scanner.DecodeAny()
+			Expect(err).To(Equal(dbn.ErrUnknownRType))
+		})
+	})
+
+	Context("VisitorFuncs", func() {
+		It("should call only the set callbacks, or Default", func() {
+			reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.trades.v3.dbn.zst", false)
+			Expect(err).To(BeNil())
+			defer closer.Close()
+
+			var trades, others int
+			visitor := &dbn.VisitorFuncs{
+				Mbp0: func(r *dbn.Mbp0Msg) error {
+					trades++
+					return nil
+				},
+			}
+			scanner := dbn.NewDbnScanner(reader)
+			Expect(visitAll(scanner, visitor)).To(Succeed())
+			Expect(trades).To(BeNumerically(">", 0))
+
+			visitor = &dbn.VisitorFuncs{
+				Default: func(r dbn.Record) error {
+					others++
+					return nil
+				},
+			}
+			Expect(dbn.VisitRecord(visitor, &dbn.Mbp0Msg{})).To(Succeed())
+			Expect(dbn.VisitRecord(visitor, &dbn.StatMsg{})).To(Succeed())
+			Expect(others).To(Equal(2))
+
+			// Older layouts are upgraded, and other types have no Visitor method
+			var upgraded dbn.Record
+			visitor.Default = func(r dbn.Record) error {
+				upgraded = r
+				return nil
+			}
+			Expect(dbn.VisitRecord(visitor, &dbn.StatMsgV2{Quantity: 5})).To(Succeed())
+			Expect(upgraded).To(Equal(&dbn.StatMsgV3{Quantity: 5}))
+			Expect(dbn.VisitRecord(visitor, unvisitableRecord{})).NotTo(Succeed())
+		})
+
+		It("should skip unknown records with an UnknownRType callback", func() {
+			stream := withUnknownRecord("./tests/data/test_data.trades.v3.dbn.zst")
+
+			// Without a handler, the stream stops
+			scanner := dbn.NewDbnScanner(bytes.NewReader(stream))
+			Expect(visitAll(scanner, &dbn.NullVisitor{})).To(Equal(dbn.ErrUnknownRType))
+			scanner = dbn.NewDbnScanner(bytes.NewReader(stream))
+			Expect(visitAll(scanner, &dbn.VisitorFuncs{})).To(Equal(dbn.ErrUnknownRType))
+
+			// With a handler, the stream continues
+			var trades, unknowns int
+			scanner = dbn.NewDbnScanner(bytes.NewReader(stream))
+			Expect(visitAll(scanner, &dbn.VisitorFuncs{
+				Mbp0: func(r *dbn.Mbp0Msg) error {
+					trades++
+					return nil
+				},
+				UnknownRType: func(header *dbn.RHeader, raw []byte) error {
+					Expect(header.RType).To(Equal(dbn.RType(0x99)))
+					Expect(raw).To(HaveLen(dbn.Mbp0Msg_Size))
+					unknowns++
+					return nil
+				},
+			})).To(Succeed())
+			Expect(unknowns).To(Equal(1))
+			Expect(trades).To(Equal(countTrades()))
+		})
+
+		It("should skip undecodable records with a DecodeError callback", func() {
+			jsonl := `{"hd":{"rtype":32,"publisher_id":1,"instrument_id":5482,"ts_event":"1609160400000000000"},"open":"3720250000000","high":"3721000000000","low":"3720000000000","close":"3720500000000","volume":"10"}
+not json
+{"hd":{"rtype":32,"publisher_id":1,"instrument_id":5482,"ts_event":"1609160401000000000"},"open":"3720250000000","high":"3721000000000","low":"3720000000000","close":"3720500000000","volume":"10"}
+`
+			scanner := dbn.NewJsonScanner(strings.NewReader(jsonl))
+			var ohlcvs, errs int
+			visitor := &dbn.VisitorFuncs{
+				Ohlcv: func(r *dbn.OhlcvMsg) error {
+					ohlcvs++
+					return nil
+				},
+				DecodeError: func(header *dbn.RHeader, raw []byte, err error) error {
+					Expect(header).To(BeNil())
+					Expect(string(raw)).To(Equal("not json"))
+					errs++
+					return nil
+				},
+			}
+			for scanner.Next() {
+				Expect(scanner.Visit(visitor)).To(Succeed())
+			}
+			Expect(ohlcvs).To(Equal(2))
+			Expect(errs).To(Equal(1))
+		})
 	})
 })
+
+// countTrades returns the number of records of the v3 trades test file.
+func countTrades() int {
+	reader, closer, err := dbn.MakeCompressedReader("./tests/data/test_data.trades.v3.dbn.zst", false)
+	Expect(err).To(BeNil())
+	defer closer.Close()
+	records, _, err := dbn.ReadDBNToSlice[dbn.Mbp0Msg](reader)
+	Expect(err).To(BeNil())
+	return len(records)
+}